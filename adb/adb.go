@@ -0,0 +1,148 @@
+// Package adb 封装对 adb 命令的调用，支持通过 -s 指定设备（包括 WiFi 调试的 host:port）。
+package adb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"goboardsync/platform"
+)
+
+// DefaultTimeout NewClient 创建的客户端每条 adb 命令的超时
+const DefaultTimeout = 30 * time.Second
+
+// Client adb 客户端
+type Client struct {
+	Path   string // adb 可执行文件路径，为空时自动查找
+	Serial string // 设备序列号或 host:port，为空时使用唯一连接的设备
+	// Timeout 每条 Run/Output 命令的超时，超时后结束 adb 进程；为 0 时不限（track-devices 等持续运行的命令不受影响）
+	Timeout time.Duration
+	// Runner 不为空时代替 adb 可执行文件执行 Run/Output（参数不含 -s），测试中用来模拟手机
+	Runner func(ctx context.Context, args ...string) ([]byte, error)
+	// Touch 不为空时 Tap 通过 sendevent 向触摸屏写入事件（见 FindTouchDevice），代替 input tap
+	Touch *TouchDevice
+	// Tapper 不为空时 Tap 交给它执行（如 companion.Client），优先于 Touch
+	Tapper interface {
+		Tap(ctx context.Context, x, y int) error
+	}
+}
+
+func NewClient(serial string) *Client {
+	return &Client{Serial: serial, Timeout: DefaultTimeout}
+}
+
+// Command 构造一条 adb 命令，自动带上 -s 参数
+func (c *Client) Command(args ...string) (*exec.Cmd, error) {
+	path, err := c.path()
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(path, c.args(args...)...), nil
+}
+
+func (c *Client) path() (string, error) {
+	if c.Path == "" {
+		path, err := platform.FindTool("adb")
+		if err != nil {
+			return "", err
+		}
+		c.Path = path
+	}
+	return c.Path, nil
+}
+
+func (c *Client) args(args ...string) []string {
+	if c.Serial == "" {
+		return args
+	}
+	return append([]string{"-s", c.Serial}, args...)
+}
+
+// Run 执行 adb 命令，失败时错误信息包含命令输出；ctx 取消时结束 adb 进程
+func (c *Client) Run(ctx context.Context, args ...string) error {
+	_, err := c.Output(ctx, args...)
+	return err
+}
+
+// Output 执行 adb 命令并返回标准输出，ctx 取消或超过 Timeout 时结束 adb 进程（手机卡住、WiFi 断开时 adb 可能一直不返回）。
+// 使用 Runner 时由 Runner 处理 ctx，执行前先检查一次
+func (c *Client) Output(ctx context.Context, args ...string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("adb %s: %v", strings.Join(args, " "), err)
+	}
+	if c.Runner != nil {
+		return c.Runner(ctx, args...)
+	}
+
+	path, err := c.path()
+	if err != nil {
+		return nil, err
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, path, c.args(args...)...)
+
+	out, err := cmd.Output()
+	if err != nil && ctx.Err() != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && c.Timeout > 0 {
+			return out, fmt.Errorf("adb %s: 超过 %v 没有返回", strings.Join(args, " "), c.Timeout)
+		}
+		return out, fmt.Errorf("adb %s: %v", strings.Join(args, " "), ctx.Err())
+	}
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return out, fmt.Errorf("adb %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return out, fmt.Errorf("adb %s: %v", strings.Join(args, " "), err)
+	}
+	return out, nil
+}
+
+// IsNetwork 序列号是否为 WiFi 调试地址（host:port）
+func (c *Client) IsNetwork() bool {
+	return strings.Contains(c.Serial, ":")
+}
+
+// Connect WiFi 调试时执行 adb connect，USB 连接时什么也不做
+func (c *Client) Connect(ctx context.Context) error {
+	if !c.IsNetwork() {
+		return nil
+	}
+
+	path, err := c.path()
+	if err != nil {
+		return err
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	out, err := exec.CommandContext(ctx, path, "connect", c.Serial).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("adb connect %s 失败: %v", c.Serial, err)
+	}
+	// adb connect 连接失败时退出码仍为 0，需要检查输出
+	if text := string(out); !strings.Contains(text, "connected to") {
+		return fmt.Errorf("adb connect %s 失败: %s", c.Serial, strings.TrimSpace(text))
+	}
+	return nil
+}
+
+// Tap 在屏幕坐标 (x, y) 处点击一次
+func (c *Client) Tap(ctx context.Context, x, y int) error {
+	if c.Tapper != nil {
+		return c.Tapper.Tap(ctx, x, y)
+	}
+	if c.Touch != nil {
+		return c.Run(ctx, "shell", c.Touch.tapScript(x, y))
+	}
+	return c.Run(ctx, "shell", "input", "tap", fmt.Sprintf("%d", x), fmt.Sprintf("%d", y))
+}
@@ -0,0 +1,314 @@
+package adb
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandArgs(t *testing.T) {
+	tests := []struct {
+		serial    string
+		args      []string
+		want      string
+		isNetwork bool
+	}{
+		{"", []string{"shell", "input", "tap", "1", "2"}, "shell input tap 1 2", false},
+		{"emulator-5554", []string{"pull", "/sdcard/a.png", "a.png"}, "-s emulator-5554 pull /sdcard/a.png a.png", false},
+		{"192.168.1.23:5555", []string{"shell", "screencap", "-p"}, "-s 192.168.1.23:5555 shell screencap -p", true},
+	}
+
+	for _, tt := range tests {
+		c := &Client{Path: "/usr/bin/adb", Serial: tt.serial}
+		cmd, err := c.Command(tt.args...)
+		if err != nil {
+			t.Fatalf("Command() unexpected error: %v", err)
+		}
+		if got := strings.Join(cmd.Args[1:], " "); got != tt.want {
+			t.Errorf("Command(%v) serial=%q = %q, want %q", tt.args, tt.serial, got, tt.want)
+		}
+		if got := c.IsNetwork(); got != tt.isNetwork {
+			t.Errorf("IsNetwork() serial=%q = %v, want %v", tt.serial, got, tt.isNetwork)
+		}
+	}
+}
+
+func TestOutputTimeout(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("没有 sleep 命令")
+	}
+	// 用 sleep 代替卡住的 adb：Output(ctx, "5") 执行 sleep 5
+	c := &Client{Path: sleep, Timeout: 50 * time.Millisecond}
+	start := time.Now()
+	if _, err := c.Output(context.Background(), "5"); err == nil || !strings.Contains(err.Error(), "没有返回") {
+		t.Errorf("Output() error = %v, want 超时", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Output() 用时 %v，未按 Timeout 结束进程", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Run(ctx, "5"); err == nil {
+		t.Error("ctx 已取消时 Run() 应返回错误")
+	}
+}
+
+func TestParseScreenState(t *testing.T) {
+	tests := []struct {
+		name     string
+		power    string
+		window   string
+		on       bool
+		locked   bool
+		focused  string
+		activity string
+	}{
+		{
+			name:     "前台运行",
+			power:    "  mWakefulness=Awake\n  mWakefulnessChanging=false",
+			window:   "  mCurrentFocus=Window{5e1f2a1 u0 com.tencent.tmgp.go/com.tencent.go.MainActivity}\n  mShowingLockscreen=false",
+			on:       true,
+			focused:  "com.tencent.tmgp.go",
+			activity: "com.tencent.tmgp.go/com.tencent.go.MainActivity",
+		},
+		{
+			name:   "熄屏",
+			power:  "  mWakefulness=Asleep",
+			window: "  mCurrentFocus=Window{1a2b3c u0 NotificationShade}\n  isStatusBarKeyguard=true",
+			locked: true,
+		},
+		{
+			name:     "旧版本输出",
+			power:    "Display Power: state=ON",
+			window:   "  mCurrentFocus=Window{9f8e7d u0 com.android.launcher3/com.android.launcher3.Launcher}",
+			on:       true,
+			focused:  "com.android.launcher3",
+			activity: "com.android.launcher3/com.android.launcher3.Launcher",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseScreenOn(tt.power); got != tt.on {
+				t.Errorf("parseScreenOn() = %v, want %v", got, tt.on)
+			}
+			if got := parseLocked(tt.window); got != tt.locked {
+				t.Errorf("parseLocked() = %v, want %v", got, tt.locked)
+			}
+			if got := parseFocusedPackage(tt.window); got != tt.focused {
+				t.Errorf("parseFocusedPackage() = %q, want %q", got, tt.focused)
+			}
+			if got := parseFocusedActivity(tt.window); got != tt.activity {
+				t.Errorf("parseFocusedActivity() = %q, want %q", got, tt.activity)
+			}
+		})
+	}
+}
+
+func TestParseBattery(t *testing.T) {
+	out := `Current Battery Service state:
+  AC powered: false
+  USB powered: true
+  Wireless powered: false
+  status: 2
+  health: 2
+  present: true
+  level: 18
+  scale: 100
+  voltage: 3812
+  temperature: 415
+  technology: Li-ion
+`
+	got, err := parseBattery(out)
+	if err != nil {
+		t.Fatalf("parseBattery() error = %v", err)
+	}
+	if want := (Battery{Level: 18, Temperature: 41.5, Charging: true}); got != want {
+		t.Errorf("parseBattery() = %+v, want %+v", got, want)
+	}
+
+	if _, err := parseBattery("error: device offline"); err == nil {
+		t.Error("parseBattery() 应对无效输出返回错误")
+	}
+}
+
+func TestParseUptime(t *testing.T) {
+	got, err := ParseUptime("12345.67 45678.90\n")
+	if err != nil || got != 12345670*time.Millisecond {
+		t.Errorf("ParseUptime() = %v, %v, want 3h25m45.67s", got, err)
+	}
+	for _, s := range []string{"", "abc 1.0", "0.00 0.00"} {
+		if _, err := ParseUptime(s); err == nil {
+			t.Errorf("ParseUptime(%q) 应返回错误", s)
+		}
+	}
+}
+
+func TestParseFlow(t *testing.T) {
+	flow, err := ParseFlow("start com.example.go/.MainActivity; wait 3s; tap 600, 2300;;back; text Q 16; key enter")
+	if err != nil {
+		t.Fatalf("ParseFlow() error = %v", err)
+	}
+
+	want := Flow{
+		{Action: "start", Target: "com.example.go/.MainActivity"},
+		{Action: "wait", Wait: 3 * time.Second},
+		{Action: "tap", X: 600, Y: 2300},
+		{Action: "back"},
+		{Action: "text", Text: "Q 16"},
+		{Action: "key", Text: "KEYCODE_ENTER"},
+	}
+	if len(flow) != len(want) {
+		t.Fatalf("ParseFlow() = %+v, want %+v", flow, want)
+	}
+	for i := range want {
+		if flow[i] != want[i] {
+			t.Errorf("第 %d 步 = %+v, want %+v", i+1, flow[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{"tap 600", "wait soon", "swipe 1,2", "back now", "text", "key A B"} {
+		if _, err := ParseFlow(bad); err == nil {
+			t.Errorf("ParseFlow(%q) 应返回错误", bad)
+		}
+	}
+}
+
+func TestRunFlowInput(t *testing.T) {
+	var commands []string
+	c := &Client{Runner: func(ctx context.Context, args ...string) ([]byte, error) {
+		commands = append(commands, strings.Join(args, " "))
+		return nil, nil
+	}}
+	flow, err := ParseFlow("text Q16 (B); key ENTER")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RunFlow(context.Background(), flow); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{`shell input text Q16%s\(B\)`, "shell input keyevent KEYCODE_ENTER"}
+	if !reflect.DeepEqual(commands, want) {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+}
+
+const geteventOutput = `add device 1: /dev/input/event0
+  name:     "gpio-keys"
+  events:
+    KEY (0001): KEY_VOLUMEDOWN        KEY_VOLUMEUP
+  input props:
+    <none>
+add device 2: /dev/input/event3
+  name:     "fts_ts"
+  events:
+    KEY (0001): BTN_TOUCH
+    ABS (0003): ABS_MT_SLOT           : value 0, min 0, max 9, fuzz 0, flat 0, resolution 0
+                ABS_MT_TOUCH_MAJOR    : value 0, min 0, max 255, fuzz 0, flat 0, resolution 0
+                ABS_MT_POSITION_X     : value 0, min 0, max 4319, fuzz 0, flat 0, resolution 0
+                ABS_MT_POSITION_Y     : value 0, min 0, max 9599, fuzz 0, flat 0, resolution 0
+                ABS_MT_TRACKING_ID    : value 0, min 0, max 65535, fuzz 0, flat 0, resolution 0
+  input props:
+    INPUT_PROP_DIRECT
+`
+
+func TestTouchDevice(t *testing.T) {
+	var commands []string
+	rotation := "0"
+	c := &Client{Runner: func(ctx context.Context, args ...string) ([]byte, error) {
+		cmd := strings.Join(args, " ")
+		switch cmd {
+		case "shell getevent -pl":
+			return []byte(geteventOutput), nil
+		case "shell wm size":
+			return []byte("Physical size: 1080x2400\n"), nil
+		case "shell dumpsys input":
+			return []byte("    SurfaceOrientation: " + rotation + "\n"), nil
+		}
+		commands = append(commands, cmd)
+		return nil, nil
+	}}
+
+	if _, err := c.FindTouchDevice(context.Background(), "/dev/input/event0"); err == nil {
+		t.Error("FindTouchDevice(event0) 应返回错误，按键设备不是触摸屏")
+	}
+	dev, err := c.FindTouchDevice(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := TouchDevice{Path: "/dev/input/event3", Name: "fts_ts", MaxX: 4319, MaxY: 9599, Width: 1080, Height: 2400, TouchMajor: true}
+	if *dev != want {
+		t.Errorf("FindTouchDevice() = %+v, want %+v", *dev, want)
+	}
+
+	c.Touch = dev
+	if err := c.Tap(context.Background(), 540, 1200); err != nil {
+		t.Fatal(err)
+	}
+	script := "shell sendevent /dev/input/event3 3 57 1;sendevent /dev/input/event3 3 53 2160;sendevent /dev/input/event3 3 54 4800;" +
+		"sendevent /dev/input/event3 3 48 5;sendevent /dev/input/event3 1 330 1;sendevent /dev/input/event3 0 0 0;" +
+		"sendevent /dev/input/event3 3 57 -1;sendevent /dev/input/event3 1 330 0;sendevent /dev/input/event3 0 0 0"
+	if len(commands) != 1 || commands[0] != script {
+		t.Errorf("Tap() commands = %q, want %q", commands, script)
+	}
+
+	// 横屏（逆时针转 90°）时截图左上角对应自然方向的右上角
+	rotation = "1"
+	if dev, err = c.FindTouchDevice(context.Background(), ""); err != nil {
+		t.Fatal(err)
+	}
+	if x, y := dev.raw(0, 0); x != 4316 || y != 0 {
+		t.Errorf("raw(0, 0) = (%d, %d), want (4316, 0)", x, y)
+	}
+}
+
+func TestReadDeviceLists(t *testing.T) {
+	// 开始时一台 USB 设备，随后 WiFi 设备连上，再断开 USB 设备
+	out := "0010R58M1234\tdevice\n" +
+		"002fR58M1234\tdevice\n192.168.1.23:5555\tunauthorized\n" +
+		"0000"
+
+	var got [][]Device
+	if err := readDeviceLists(strings.NewReader(out), func(d []Device) { got = append(got, d) }); err != nil {
+		t.Fatalf("readDeviceLists() error = %v", err)
+	}
+	want := [][]Device{
+		{{"R58M1234", "device"}},
+		{{"R58M1234", "device"}, {"192.168.1.23:5555", "unauthorized"}},
+		nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readDeviceLists() = %v, want %v", got, want)
+	}
+
+	if err := readDeviceLists(strings.NewReader("zzzz"), func([]Device) {}); err == nil {
+		t.Error("长度不是十六进制时应返回错误")
+	}
+}
+
+func TestFindDevice(t *testing.T) {
+	devices := []Device{{"R58M1234", "device"}, {"192.168.1.23:5555", "offline"}}
+	tests := []struct {
+		serial  string
+		devices []Device
+		want    Device
+		ok      bool
+	}{
+		{"192.168.1.23:5555", devices, devices[1], true},
+		{"emulator-5554", devices, Device{}, false},
+		{"", devices, Device{}, false},
+		{"", devices[:1], devices[0], true},
+		{"", nil, Device{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := NewClient(tt.serial).Find(tt.devices)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Find(%q, %v) = %v, %v, want %v, %v", tt.serial, tt.devices, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package adb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Battery 手机电量与电池温度
+type Battery struct {
+	Level       int     // 剩余电量百分比
+	Temperature float64 // 摄氏度
+	Charging    bool
+}
+
+// State 通过 adb get-state 返回设备状态：device 表示已连接可用，其他如 offline、unauthorized；
+// 找不到设备时返回错误
+func (c *Client) State(ctx context.Context) (string, error) {
+	out, err := c.Output(ctx, "get-state")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ScreenOn 通过 dumpsys power 判断屏幕是否点亮
+func (c *Client) ScreenOn(ctx context.Context) (bool, error) {
+	out, err := c.Output(ctx, "shell", "dumpsys", "power")
+	if err != nil {
+		return false, err
+	}
+	return parseScreenOn(string(out)), nil
+}
+
+// Locked 通过 dumpsys window 判断是否停留在锁屏界面
+func (c *Client) Locked(ctx context.Context) (bool, error) {
+	out, err := c.Output(ctx, "shell", "dumpsys", "window")
+	if err != nil {
+		return false, err
+	}
+	return parseLocked(string(out)), nil
+}
+
+// ForegroundPackage 返回当前获得焦点的窗口所属的应用包名，读不到时返回空字符串
+func (c *Client) ForegroundPackage(ctx context.Context) (string, error) {
+	out, err := c.Output(ctx, "shell", "dumpsys", "window")
+	if err != nil {
+		return "", err
+	}
+	return parseFocusedPackage(string(out)), nil
+}
+
+// FocusedActivity 返回当前获得焦点的窗口所属的界面（包名/类名，如 com.tencent.weiqi/.MainActivity），
+// 读不到或焦点不在界面上（如状态栏）时返回空字符串
+func (c *Client) FocusedActivity(ctx context.Context) (string, error) {
+	out, err := c.Output(ctx, "shell", "dumpsys", "window")
+	if err != nil {
+		return "", err
+	}
+	return parseFocusedActivity(string(out)), nil
+}
+
+// Uptime 读取手机的开机时长（/proc/uptime），可作为截图等操作在手机上的时间戳
+func (c *Client) Uptime(ctx context.Context) (time.Duration, error) {
+	out, err := c.Output(ctx, "shell", "cat", "/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+	return ParseUptime(string(out))
+}
+
+// ParseUptime 解析 /proc/uptime 的第一个数（开机秒数，如 "12345.67 45678.90"）
+func ParseUptime(s string) (time.Duration, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("无法解析开机时长: %q", s)
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("无法解析开机时长: %q", s)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// Battery 通过 dumpsys battery 读取电量与电池温度
+func (c *Client) Battery(ctx context.Context) (Battery, error) {
+	out, err := c.Output(ctx, "shell", "dumpsys", "battery")
+	if err != nil {
+		return Battery{}, err
+	}
+	return parseBattery(string(out))
+}
+
+// Wake 点亮屏幕并尝试解除无密码的锁屏（有密码时仍需手动解锁）
+func (c *Client) Wake(ctx context.Context) error {
+	if err := c.Run(ctx, "shell", "input", "keyevent", "KEYCODE_WAKEUP"); err != nil {
+		return err
+	}
+	return c.Run(ctx, "shell", "wm", "dismiss-keyguard")
+}
+
+// StartApp 启动应用的主界面，已在运行时切换到前台
+func (c *Client) StartApp(ctx context.Context, pkg string) error {
+	return c.Run(ctx, "shell", "monkey", "-p", pkg, "-c", "android.intent.category.LAUNCHER", "1")
+}
+
+var wakefulnessRe = regexp.MustCompile(`mWakefulness=(\w+)`)
+
+func parseScreenOn(dumpsys string) bool {
+	// 新版本输出 mWakefulness=Awake，旧版本输出 Display Power: state=ON
+	if m := wakefulnessRe.FindStringSubmatch(dumpsys); m != nil {
+		return m[1] == "Awake"
+	}
+	return strings.Contains(dumpsys, "Display Power: state=ON")
+}
+
+func parseLocked(dumpsys string) bool {
+	for _, key := range []string{"mDreamingLockscreen=true", "mShowingLockscreen=true", "isStatusBarKeyguard=true"} {
+		if strings.Contains(dumpsys, key) {
+			return true
+		}
+	}
+	return false
+}
+
+var focusedWindowRe = regexp.MustCompile(`mCurrentFocus=Window\{\S+ \S+ ([\w.]+)/`)
+
+func parseFocusedPackage(dumpsys string) string {
+	if m := focusedWindowRe.FindStringSubmatch(dumpsys); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+var focusedActivityRe = regexp.MustCompile(`mCurrentFocus=Window\{\S+ \S+ ([\w.]+/[\w.$]+)\}`)
+
+func parseFocusedActivity(dumpsys string) string {
+	if m := focusedActivityRe.FindStringSubmatch(dumpsys); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+var batteryFieldRe = regexp.MustCompile(`(?m)^\s*(level|temperature|AC powered|USB powered|Wireless powered): (\w+)`)
+
+func parseBattery(dumpsys string) (Battery, error) {
+	var b Battery
+	found := map[string]bool{}
+	for _, m := range batteryFieldRe.FindAllStringSubmatch(dumpsys, -1) {
+		found[m[1]] = true
+		switch m[1] {
+		case "level":
+			b.Level, _ = strconv.Atoi(m[2])
+		case "temperature":
+			// 单位为 0.1 摄氏度
+			tenths, _ := strconv.Atoi(m[2])
+			b.Temperature = float64(tenths) / 10
+		default:
+			b.Charging = b.Charging || m[2] == "true"
+		}
+	}
+	if !found["level"] || !found["temperature"] {
+		return Battery{}, fmt.Errorf("无法解析 dumpsys battery 输出")
+	}
+	return b, nil
+}
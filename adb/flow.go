@@ -0,0 +1,125 @@
+package adb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Step 操作流程中的一步
+type Step struct {
+	Action string        // tap、wait、back、start、text 或 key
+	X, Y   int           // tap 的屏幕坐标
+	Wait   time.Duration // wait 的时长
+	Target string        // start 的包名或 包名/Activity
+	Text   string        // text 输入的文字，key 的按键码（如 KEYCODE_ENTER）
+}
+
+// Flow 一串按顺序执行的操作，例如从 App 首页点回正在进行的对局
+type Flow []Step
+
+// ParseFlow 解析以分号分隔的操作，如 "start com.example.go; wait 3s; tap 600,2300; wait 1s; back"。
+// "text Q16" 在当前输入框中输入文字，"key ENTER" 按下一个键（可省略 KEYCODE_ 前缀）
+func ParseFlow(s string) (Flow, error) {
+	var flow Flow
+	for _, part := range strings.Split(s, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+
+		step := Step{Action: fields[0]}
+		if step.Action == "tap" && len(fields) > 2 {
+			// 允许坐标中逗号后带空格，如 "tap 600, 2300"
+			fields = []string{"tap", strings.Join(fields[1:], "")}
+		}
+		switch {
+		case step.Action == "back" && len(fields) == 1:
+		case step.Action == "tap" && len(fields) == 2:
+			xy := strings.Split(fields[1], ",")
+			if len(xy) != 2 {
+				return nil, fmt.Errorf("无效的点击坐标: %q", part)
+			}
+			x, errX := strconv.Atoi(strings.TrimSpace(xy[0]))
+			y, errY := strconv.Atoi(strings.TrimSpace(xy[1]))
+			if errX != nil || errY != nil {
+				return nil, fmt.Errorf("无效的点击坐标: %q", part)
+			}
+			step.X, step.Y = x, y
+		case step.Action == "wait" && len(fields) == 2:
+			d, err := time.ParseDuration(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("无效的等待时间: %q", part)
+			}
+			step.Wait = d
+		case step.Action == "start" && len(fields) == 2:
+			step.Target = fields[1]
+		case step.Action == "text" && len(fields) > 1:
+			step.Text = strings.Join(fields[1:], " ")
+		case step.Action == "key" && len(fields) == 2:
+			step.Text = strings.ToUpper(fields[1])
+			if !strings.HasPrefix(step.Text, "KEYCODE_") {
+				step.Text = "KEYCODE_" + step.Text
+			}
+		default:
+			return nil, fmt.Errorf("无法识别的操作: %q", strings.TrimSpace(part))
+		}
+		flow = append(flow, step)
+	}
+	return flow, nil
+}
+
+// RunFlow 依次执行 flow 中的操作，任一步失败即停止
+func (c *Client) RunFlow(ctx context.Context, flow Flow) error {
+	for i, step := range flow {
+		var err error
+		switch step.Action {
+		case "tap":
+			err = c.Tap(ctx, step.X, step.Y)
+		case "wait":
+			select {
+			case <-time.After(step.Wait):
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+		case "back":
+			err = c.KeyEvent(ctx, "KEYCODE_BACK")
+		case "start":
+			err = c.Launch(ctx, step.Target)
+		case "text":
+			err = c.InputText(ctx, step.Text)
+		case "key":
+			err = c.KeyEvent(ctx, step.Text)
+		}
+		if err != nil {
+			return fmt.Errorf("第 %d 步 %s 失败: %v", i+1, step.Action, err)
+		}
+	}
+	return nil
+}
+
+// Launch 启动应用：target 为 包名/Activity 时用 am start 打开指定界面，只有包名时打开主界面
+func (c *Client) Launch(ctx context.Context, target string) error {
+	if strings.Contains(target, "/") {
+		return c.Run(ctx, "shell", "am", "start", "-n", target)
+	}
+	return c.StartApp(ctx, target)
+}
+
+// inputTextEscaper input text 经过设备上的 shell 解析：空格写作 %s，shell 的特殊字符加反斜杠
+var inputTextEscaper = strings.NewReplacer(
+	" ", "%s", `\`, `\\`, `"`, `\"`, "'", `\'`, "`", "\\`", "$", `\$`,
+	"&", `\&`, "|", `\|`, ";", `\;`, "<", `\<`, ">", `\>`, "(", `\(`, ")", `\)`, "*", `\*`, "~", `\~`,
+)
+
+// InputText 在当前获得焦点的输入框中输入文字（只支持 ASCII，adb 的限制）
+func (c *Client) InputText(ctx context.Context, text string) error {
+	return c.Run(ctx, "shell", "input", "text", inputTextEscaper.Replace(text))
+}
+
+// KeyEvent 按下一个键，code 为按键码，如 KEYCODE_ENTER
+func (c *Client) KeyEvent(ctx context.Context, code string) error {
+	return c.Run(ctx, "shell", "input", "keyevent", code)
+}
@@ -0,0 +1,186 @@
+package adb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Linux 输入事件的类型与编码，见 linux/input-event-codes.h
+const (
+	evSyn         = 0
+	evKey         = 1
+	evAbs         = 3
+	btnTouch      = 330
+	absMTTouch    = 48 // ABS_MT_TOUCH_MAJOR
+	absMTX        = 53 // ABS_MT_POSITION_X
+	absMTY        = 54 // ABS_MT_POSITION_Y
+	absMTTracking = 57 // ABS_MT_TRACKING_ID
+	absMTPressure = 58 // ABS_MT_PRESSURE
+)
+
+// TouchDevice 触摸屏的输入设备，Tap 通过 sendevent 直接向它写入触摸事件，
+// 绕过 input tap 启动 Java 进程的开销，也不会在系统繁忙时丢失点击
+type TouchDevice struct {
+	Path string // 设备节点，如 /dev/input/event2
+	Name string
+	// MinX/MaxX、MinY/MaxY 触摸坐标的范围（屏幕自然方向，通常为竖屏）
+	MinX, MaxX, MinY, MaxY int
+	// Width、Height 屏幕自然方向的分辨率，Rotation 为查找设备时的屏幕方向（0-3，每级 90°）
+	Width, Height int
+	Rotation      int
+	// Pressure、TouchMajor 设备是否上报压力与触点大小，部分驱动缺了它们会忽略触摸
+	Pressure, TouchMajor bool
+}
+
+// FindTouchDevice 通过 getevent -pl 查找触摸屏，并读取屏幕分辨率与方向。path 不为空时只使用该设备节点。
+// 截图坐标按查找时的屏幕方向换算，App 之后旋转屏幕需要重新查找
+func (c *Client) FindTouchDevice(ctx context.Context, path string) (*TouchDevice, error) {
+	out, err := c.Output(ctx, "shell", "getevent", "-pl")
+	if err != nil {
+		return nil, err
+	}
+	devices := parseTouchDevices(string(out))
+	var dev *TouchDevice
+	for i := range devices {
+		if path == "" || devices[i].Path == path {
+			dev = &devices[i]
+			break
+		}
+	}
+	if dev == nil {
+		if path != "" {
+			return nil, fmt.Errorf("%s 不是触摸屏或不存在", path)
+		}
+		return nil, fmt.Errorf("没有找到触摸屏（getevent -pl 中没有带 ABS_MT_POSITION_X/Y 的设备）")
+	}
+
+	out, err = c.Output(ctx, "shell", "wm", "size")
+	if err != nil {
+		return nil, err
+	}
+	if dev.Width, dev.Height, err = parseScreenSize(string(out)); err != nil {
+		return nil, err
+	}
+	// 读不到方向时按竖屏处理
+	if out, err := c.Output(ctx, "shell", "dumpsys", "input"); err == nil {
+		dev.Rotation = parseRotation(string(out))
+	}
+	return dev, nil
+}
+
+// parseTouchDevices 从 getevent -pl 的输出中找出多点触摸设备，直接触摸（INPUT_PROP_DIRECT）的排在前面
+func parseTouchDevices(getevent string) []TouchDevice {
+	var direct, others []TouchDevice
+	for _, block := range strings.Split(getevent, "add device ")[1:] {
+		var dev TouchDevice
+		var hasX, hasY, isDirect bool
+		for i, line := range strings.Split(block, "\n") {
+			line = strings.TrimSpace(line)
+			switch {
+			case i == 0:
+				if _, p, ok := strings.Cut(line, ": "); ok {
+					dev.Path = strings.TrimSpace(p)
+				}
+			case strings.HasPrefix(line, "name:"):
+				dev.Name = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "name:")), `"`)
+			case strings.Contains(line, "ABS_MT_POSITION_X"):
+				dev.MinX, dev.MaxX, hasX = parseAbsRange(line)
+			case strings.Contains(line, "ABS_MT_POSITION_Y"):
+				dev.MinY, dev.MaxY, hasY = parseAbsRange(line)
+			case strings.Contains(line, "ABS_MT_PRESSURE"):
+				dev.Pressure = true
+			case strings.Contains(line, "ABS_MT_TOUCH_MAJOR"):
+				dev.TouchMajor = true
+			case line == "INPUT_PROP_DIRECT":
+				isDirect = true
+			}
+		}
+		if !hasX || !hasY || dev.Path == "" {
+			continue
+		}
+		if isDirect {
+			direct = append(direct, dev)
+		} else {
+			others = append(others, dev)
+		}
+	}
+	return append(direct, others...)
+}
+
+var absRangeRe = regexp.MustCompile(`min (-?\d+), max (-?\d+)`)
+
+func parseAbsRange(line string) (min, max int, ok bool) {
+	m := absRangeRe.FindStringSubmatch(line)
+	if m == nil {
+		return 0, 0, false
+	}
+	min, _ = strconv.Atoi(m[1])
+	max, _ = strconv.Atoi(m[2])
+	return min, max, max > min
+}
+
+var screenSizeRe = regexp.MustCompile(`(Physical|Override) size: (\d+)x(\d+)`)
+
+// parseScreenSize 解析 wm size 的输出，设置了 Override size 时以它为准（截图也是这个分辨率）
+func parseScreenSize(wm string) (int, int, error) {
+	var w, h int
+	for _, m := range screenSizeRe.FindAllStringSubmatch(wm, -1) {
+		w, _ = strconv.Atoi(m[2])
+		h, _ = strconv.Atoi(m[3])
+	}
+	if w == 0 || h == 0 {
+		return 0, 0, fmt.Errorf("无法解析屏幕分辨率: %q", strings.TrimSpace(wm))
+	}
+	return w, h, nil
+}
+
+var rotationRe = regexp.MustCompile(`SurfaceOrientation: (\d)`)
+
+func parseRotation(dumpsys string) int {
+	if m := rotationRe.FindStringSubmatch(dumpsys); m != nil {
+		r, _ := strconv.Atoi(m[1])
+		return r % 4
+	}
+	return 0
+}
+
+// raw 把截图坐标换算成触摸设备的坐标：先按屏幕方向转回自然方向，再缩放到触摸坐标的范围
+func (d *TouchDevice) raw(x, y int) (int, int) {
+	switch d.Rotation {
+	case 1:
+		x, y = d.Width-1-y, x
+	case 2:
+		x, y = d.Width-1-x, d.Height-1-y
+	case 3:
+		x, y = y, d.Height-1-x
+	}
+	rx := d.MinX + x*(d.MaxX-d.MinX+1)/d.Width
+	ry := d.MinY + y*(d.MaxY-d.MinY+1)/d.Height
+	return min(max(rx, d.MinX), d.MaxX), min(max(ry, d.MinY), d.MaxY)
+}
+
+// tapScript 一次点击的 sendevent 命令（多点触摸协议 B：按下、抬起各一帧），用分号连成一条 shell 命令执行
+func (d *TouchDevice) tapScript(x, y int) string {
+	rx, ry := d.raw(x, y)
+	var events [][3]int
+	events = append(events, [3]int{evAbs, absMTTracking, 1}, [3]int{evAbs, absMTX, rx}, [3]int{evAbs, absMTY, ry})
+	if d.Pressure {
+		events = append(events, [3]int{evAbs, absMTPressure, 50})
+	}
+	if d.TouchMajor {
+		events = append(events, [3]int{evAbs, absMTTouch, 5})
+	}
+	events = append(events,
+		[3]int{evKey, btnTouch, 1}, [3]int{evSyn, 0, 0},
+		[3]int{evAbs, absMTTracking, -1}, [3]int{evKey, btnTouch, 0}, [3]int{evSyn, 0, 0},
+	)
+
+	cmds := make([]string, len(events))
+	for i, e := range events {
+		cmds[i] = fmt.Sprintf("sendevent %s %d %d %d", d.Path, e[0], e[1], e[2])
+	}
+	return strings.Join(cmds, ";")
+}
@@ -0,0 +1,99 @@
+package adb
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Device adb 列出的一台设备，State 为 device（可用）、offline、unauthorized 等
+type Device struct {
+	Serial string
+	State  string
+}
+
+// TrackDevices 运行 adb track-devices，开始时与每次设备插拔、状态变化时以完整的设备列表调用 fn，
+// 直到 ctx 取消或 adb 退出（如 adb server 重启）。fn 在读取输出的 goroutine 中同步调用
+func (c *Client) TrackDevices(ctx context.Context, fn func([]Device)) error {
+	path, err := c.path()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, path, "track-devices")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("adb track-devices: %v", err)
+	}
+
+	err = readDeviceLists(out, fn)
+	if waitErr := cmd.Wait(); err == nil && ctx.Err() == nil {
+		err = waitErr
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("adb track-devices 已退出: %v", err)
+}
+
+// Find 在设备列表中找到本客户端使用的设备：指定了 Serial 时按序列号查找，否则为唯一的一台
+func (c *Client) Find(devices []Device) (Device, bool) {
+	if c.Serial == "" {
+		if len(devices) == 1 {
+			return devices[0], true
+		}
+		return Device{}, false
+	}
+	for _, d := range devices {
+		if d.Serial == c.Serial {
+			return d, true
+		}
+	}
+	return Device{}, false
+}
+
+// readDeviceLists 读取 track-devices 的输出直到结束：每条消息为 4 位十六进制的长度加设备列表
+func readDeviceLists(r io.Reader, fn func([]Device)) error {
+	br := bufio.NewReader(r)
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(br, header[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		n, err := strconv.ParseUint(string(header[:]), 16, 16)
+		if err != nil {
+			return fmt.Errorf("无法解析 track-devices 输出: %q", header)
+		}
+		body := make([]byte, n)
+		if _, err := io.ReadFull(br, body); err != nil {
+			return err
+		}
+		fn(parseDevices(string(body)))
+	}
+}
+
+// parseDevices 解析每行 "序列号<Tab>状态" 的设备列表（adb devices 的输出去掉标题行后格式相同）
+func parseDevices(text string) []Device {
+	var devices []Device
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		devices = append(devices, Device{Serial: fields[0], State: fields[1]})
+	}
+	return devices
+}
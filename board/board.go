@@ -0,0 +1,93 @@
+// Package board 描述棋盘局面，坐标统一使用 KaTrain 坐标（x 从左到右，y 从下到上，0 起）。
+package board
+
+import (
+	"goboardsync/coords"
+)
+
+// Color 交叉点状态
+type Color int
+
+const (
+	Empty Color = iota
+	Black
+	White
+)
+
+// String 返回 "B"、"W"，空点返回 ""
+func (c Color) String() string {
+	switch c {
+	case Black:
+		return "B"
+	case White:
+		return "W"
+	}
+	return ""
+}
+
+// Opponent 返回对方颜色
+func (c Color) Opponent() Color {
+	switch c {
+	case Black:
+		return White
+	case White:
+		return Black
+	}
+	return Empty
+}
+
+// ParseColor 解析 "B"/"W"（大小写均可），其它返回 Empty
+func ParseColor(s string) Color {
+	switch s {
+	case "B", "b":
+		return Black
+	case "W", "w":
+		return White
+	}
+	return Empty
+}
+
+// Board 19 路棋盘局面
+type Board struct {
+	grid [coords.Size][coords.Size]Color
+}
+
+// At 返回 (x, y) 的状态，超出棋盘返回 Empty
+func (b *Board) At(x, y int) Color {
+	if !coords.Valid(x, y) {
+		return Empty
+	}
+	return b.grid[x][y]
+}
+
+// Set 设置 (x, y) 的状态，超出棋盘时忽略
+func (b *Board) Set(x, y int, c Color) {
+	if coords.Valid(x, y) {
+		b.grid[x][y] = c
+	}
+}
+
+// Count 统计某种状态的交叉点数量
+func (b *Board) Count(c Color) int {
+	n := 0
+	for x := range b.grid {
+		for y := range b.grid[x] {
+			if b.grid[x][y] == c {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Hash 局面的 64 位哈希（FNV-1a），相同局面的哈希相同，用于快速判断两个局面是否一致
+func (b *Board) Hash() uint64 {
+	h := uint64(14695981039346656037)
+	for x := range b.grid {
+		for y := range b.grid[x] {
+			h ^= uint64(b.grid[x][y])
+			h *= 1099511628211
+		}
+	}
+	return h
+}
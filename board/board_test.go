@@ -0,0 +1,73 @@
+package board
+
+import "testing"
+
+func TestSetAndCount(t *testing.T) {
+	var b Board
+	b.Set(3, 15, Black)
+	b.Set(15, 3, White)
+	b.Set(16, 3, White)
+	b.Set(19, 0, Black) // 超出棋盘，忽略
+
+	if got := b.At(3, 15); got != Black {
+		t.Errorf("At(3, 15) = %v, want Black", got)
+	}
+	if got := b.At(-1, 0); got != Empty {
+		t.Errorf("At(-1, 0) = %v, want Empty", got)
+	}
+	if got := b.Count(Black); got != 1 {
+		t.Errorf("Count(Black) = %d, want 1", got)
+	}
+	if got := b.Count(White); got != 2 {
+		t.Errorf("Count(White) = %d, want 2", got)
+	}
+	if got := b.Count(Empty); got != 361-3 {
+		t.Errorf("Count(Empty) = %d, want %d", got, 361-3)
+	}
+}
+
+func TestColor(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Color
+		str      string
+	}{
+		{"B", Black, "B"},
+		{"w", White, "W"},
+		{"", Empty, ""},
+		{"X", Empty, ""},
+	}
+
+	for _, tt := range tests {
+		c := ParseColor(tt.input)
+		if c != tt.expected {
+			t.Errorf("ParseColor(%q) = %v, want %v", tt.input, c, tt.expected)
+		}
+		if c.String() != tt.str {
+			t.Errorf("ParseColor(%q).String() = %q, want %q", tt.input, c.String(), tt.str)
+		}
+	}
+
+	if Black.Opponent() != White || White.Opponent() != Black || Empty.Opponent() != Empty {
+		t.Errorf("Opponent() 结果不正确")
+	}
+}
+
+func TestHash(t *testing.T) {
+	var a, b Board
+	if a.Hash() != b.Hash() {
+		t.Fatal("空盘的哈希应相同")
+	}
+	a.Set(3, 3, Black)
+	if a.Hash() == b.Hash() {
+		t.Error("不同局面的哈希相同")
+	}
+	b.Set(3, 3, White)
+	if a.Hash() == b.Hash() {
+		t.Error("同一点黑白不同时哈希相同")
+	}
+	b.Set(3, 3, Black)
+	if a.Hash() != b.Hash() {
+		t.Error("相同局面的哈希不同")
+	}
+}
@@ -0,0 +1,101 @@
+package board
+
+import (
+	"goboardsync/coords"
+)
+
+// Change 两个局面之间单个交叉点的变化
+type Change struct {
+	X, Y int
+	From Color
+	To   Color
+}
+
+// Diff 返回从 prev 到 cur 发生变化的交叉点
+func Diff(prev, cur *Board) []Change {
+	var changes []Change
+	for x := 0; x < coords.Size; x++ {
+		for y := 0; y < coords.Size; y++ {
+			if from, to := prev.At(x, y), cur.At(x, y); from != to {
+				changes = append(changes, Change{X: x, Y: y, From: from, To: to})
+			}
+		}
+	}
+	return changes
+}
+
+// Added 筛选出新落下的棋子（空点变为有子）
+func Added(changes []Change) []Change {
+	var added []Change
+	for _, c := range changes {
+		if c.From == Empty && c.To != Empty {
+			added = append(added, c)
+		}
+	}
+	return added
+}
+
+// Tracker 跟踪摄像头识别出的局面。手在棋盘上方、光线闪烁时单帧结果不可靠，
+// 同一局面连续出现 Stable 次才认为稳定，再与上一个稳定局面比较得出新的一手
+type Tracker struct {
+	Stable int
+
+	committed Board
+	pending   Board
+	count     int
+	moves     int
+}
+
+func NewTracker(stable int) *Tracker {
+	if stable < 1 {
+		stable = 1
+	}
+	return &Tracker{Stable: stable}
+}
+
+// Observe 输入一帧识别出的局面。局面稳定且恰好多了一颗棋子时返回这手棋；
+// 提子等其它变化只更新局面，不返回新手
+func (t *Tracker) Observe(b Board) (Change, bool) {
+	added, ok := t.settle(b)
+	if !ok || len(added) != 1 {
+		return Change{}, false
+	}
+	t.moves++
+	return added[0], true
+}
+
+// ObserveAll 同 Observe，但局面稳定后返回新增的全部棋子，两帧之间下了多手时也不丢弃。
+// 返回顺序按交叉点排列，不代表落子顺序
+func (t *Tracker) ObserveAll(b Board) []Change {
+	added, _ := t.settle(b)
+	t.moves += len(added)
+	return added
+}
+
+// settle 记录一帧局面，局面稳定且与上一个稳定局面不同时更新并返回新增的棋子
+func (t *Tracker) settle(b Board) ([]Change, bool) {
+	if b == t.pending {
+		t.count++
+	} else {
+		t.pending = b
+		t.count = 1
+	}
+
+	if t.count < t.Stable || b == t.committed {
+		return nil, false
+	}
+
+	added := Added(Diff(&t.committed, &b))
+	t.committed = b
+	return added, true
+}
+
+// Moves 返回已识别出的手数
+func (t *Tracker) Moves() int {
+	return t.moves
+}
+
+// Board 返回最近一个稳定局面
+func (t *Tracker) Board() Board {
+	return t.committed
+}
@@ -0,0 +1,117 @@
+package board
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	var prev, cur Board
+	prev.Set(3, 3, Black)
+	prev.Set(4, 3, White)
+
+	cur.Set(3, 3, Black)
+	cur.Set(15, 15, Black)
+	cur.Set(4, 4, White)
+
+	changes := Diff(&prev, &cur)
+	if len(changes) != 3 {
+		t.Fatalf("Diff() 返回 %d 处变化, want 3: %v", len(changes), changes)
+	}
+
+	added := Added(changes)
+	if len(added) != 2 {
+		t.Fatalf("Added() 返回 %d 手, want 2: %v", len(added), added)
+	}
+	for _, c := range added {
+		if c.From != Empty || c.To == Empty {
+			t.Errorf("Added() 包含非落子变化: %+v", c)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker(2)
+
+	var b1 Board
+	b1.Set(3, 15, Black)
+
+	if _, ok := tracker.Observe(b1); ok {
+		t.Fatalf("第一帧不应判定为新手")
+	}
+	move, ok := tracker.Observe(b1)
+	if !ok {
+		t.Fatalf("连续两帧相同局面应判定为新手")
+	}
+	if want := (Change{X: 3, Y: 15, From: Empty, To: Black}); move != want {
+		t.Errorf("Observe() = %+v, want %+v", move, want)
+	}
+	if _, ok := tracker.Observe(b1); ok {
+		t.Errorf("局面未变化时不应重复返回")
+	}
+
+	// 手遮挡：一帧噪声后恢复，不产生新手
+	var noisy Board
+	noisy.Set(3, 15, Black)
+	noisy.Set(10, 10, White)
+	noisy.Set(10, 11, White)
+	if _, ok := tracker.Observe(noisy); ok {
+		t.Errorf("单帧噪声不应判定为新手")
+	}
+	if _, ok := tracker.Observe(b1); ok {
+		t.Errorf("恢复原局面不应判定为新手")
+	}
+
+	// 白棋落子
+	b2 := b1
+	b2.Set(15, 3, White)
+	tracker.Observe(b2)
+	move, ok = tracker.Observe(b2)
+	if !ok || move.X != 15 || move.Y != 3 || move.To != White {
+		t.Errorf("Observe() = %+v, %v, want 白棋 (15, 3)", move, ok)
+	}
+
+	// 一次出现两颗新子（漏掉了一手），只更新局面
+	b3 := b2
+	b3.Set(16, 3, Black)
+	b3.Set(16, 4, White)
+	tracker.Observe(b3)
+	if _, ok := tracker.Observe(b3); ok {
+		t.Errorf("同时多出两颗棋子不应判定为单手")
+	}
+	if got := tracker.Moves(); got != 2 {
+		t.Errorf("Moves() = %d, want 2", got)
+	}
+	if got := tracker.Board(); got != b3 {
+		t.Errorf("Board() 未更新为最新稳定局面")
+	}
+}
+
+func TestTrackerObserveAll(t *testing.T) {
+	tracker := NewTracker(2)
+
+	var b Board
+	b.Set(3, 15, Black)
+	b.Set(15, 3, White)
+	b.Set(16, 3, Black)
+
+	if added := tracker.ObserveAll(b); len(added) != 0 {
+		t.Fatalf("第一帧不应返回棋子: %+v", added)
+	}
+	if added := tracker.ObserveAll(b); len(added) != 3 {
+		t.Errorf("ObserveAll() 返回 %d 颗, want 3", len(added))
+	}
+	if added := tracker.ObserveAll(b); len(added) != 0 {
+		t.Errorf("局面未变化时不应重复返回: %+v", added)
+	}
+
+	// 提子：只更新局面
+	captured := b
+	captured.Set(15, 3, Empty)
+	captured.Set(15, 4, Black)
+	tracker.ObserveAll(captured)
+	added := tracker.ObserveAll(captured)
+	if want := []Change{{X: 15, Y: 4, From: Empty, To: Black}}; len(added) != 1 || added[0] != want[0] {
+		t.Errorf("ObserveAll() = %+v, want %+v", added, want)
+	}
+	if got := tracker.Moves(); got != 4 {
+		t.Errorf("Moves() = %d, want 4", got)
+	}
+}
@@ -0,0 +1,47 @@
+package board
+
+import "goboardsync/coords"
+
+// Phase 对局阶段
+type Phase int
+
+const (
+	// Opening 布局：盘面大多为空，棋子少，识别最容易
+	Opening Phase = iota
+	// Middlegame 中盘
+	Middlegame
+	// Endgame 官子：盘面拥挤，提子多，误识别的风险最高
+	Endgame
+)
+
+// 判断阶段的界限：手数与盘上棋子占交叉点的比例两者任一达到即进入下一阶段
+const (
+	openingMoves    = 40
+	openingOccupied = 0.12
+	endgameMoves    = 180
+	endgameOccupied = 0.45
+)
+
+func (p Phase) String() string {
+	switch p {
+	case Opening:
+		return "布局"
+	case Middlegame:
+		return "中盘"
+	case Endgame:
+		return "官子"
+	}
+	return "未知"
+}
+
+// ClassifyPhase 按手数与盘上的棋子数判断对局阶段。中途开始同步时手数可能为 0，按棋子数判断
+func ClassifyPhase(move int, b *Board) Phase {
+	occupied := float64(b.Count(Black)+b.Count(White)) / float64(coords.Size*coords.Size)
+	switch {
+	case move >= endgameMoves || occupied >= endgameOccupied:
+		return Endgame
+	case move >= openingMoves || occupied >= openingOccupied:
+		return Middlegame
+	}
+	return Opening
+}
@@ -0,0 +1,31 @@
+package board
+
+import "testing"
+
+func TestClassifyPhase(t *testing.T) {
+	filled := func(n int) *Board {
+		var b Board
+		for i := 0; i < n; i++ {
+			b.Set(i%19, i/19, Color(1+i%2))
+		}
+		return &b
+	}
+
+	tests := []struct {
+		name   string
+		move   int
+		stones int
+		want   Phase
+	}{
+		{"开局", 12, 12, Opening},
+		{"手数进入中盘", 40, 38, Middlegame},
+		{"中途开始同步，按棋子数判断", 0, 60, Middlegame},
+		{"提子多，棋子少于手数", 190, 120, Endgame},
+		{"盘面拥挤", 150, 170, Endgame},
+	}
+	for _, tt := range tests {
+		if got := ClassifyPhase(tt.move, filled(tt.stones)); got != tt.want {
+			t.Errorf("%s: ClassifyPhase(%d, %d 子) = %v, want %v", tt.name, tt.move, tt.stones, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package board
+
+import (
+	"fmt"
+	"image"
+	"sync"
+
+	"goboardsync/coords"
+)
+
+var neighbors = []image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+// Group 返回与 (x, y) 相连的同色棋子及其气数，空点返回 nil
+func (b *Board) Group(x, y int) ([]image.Point, int) {
+	c := b.At(x, y)
+	if c == Empty {
+		return nil, 0
+	}
+
+	var visited, counted [coords.Size][coords.Size]bool
+	stones := []image.Point{{x, y}}
+	visited[x][y] = true
+	liberties := 0
+	for i := 0; i < len(stones); i++ {
+		for _, d := range neighbors {
+			n := stones[i].Add(d)
+			if !coords.Valid(n.X, n.Y) || visited[n.X][n.Y] {
+				continue
+			}
+			switch b.At(n.X, n.Y) {
+			case c:
+				visited[n.X][n.Y] = true
+				stones = append(stones, n)
+			case Empty:
+				if !counted[n.X][n.Y] {
+					counted[n.X][n.Y] = true
+					liberties++
+				}
+			}
+		}
+	}
+	return stones, liberties
+}
+
+// Play 按规则在 (x, y) 落下 c 的棋子，提掉因此没有气的对方棋子，返回提子数。
+// 落在棋盘外、已有棋子的点或自杀时返回错误，局面不变；不判断打劫
+func (b *Board) Play(x, y int, c Color) (int, error) {
+	if c == Empty {
+		return 0, fmt.Errorf("无效的颜色")
+	}
+	if !coords.Valid(x, y) {
+		return 0, fmt.Errorf("坐标超出棋盘: %d,%d", x, y)
+	}
+	if b.At(x, y) != Empty {
+		return 0, fmt.Errorf("%s 已有棋子", coords.Format(x, y, coords.GTP))
+	}
+
+	next := *b
+	next.Set(x, y, c)
+	captured := 0
+	for _, d := range neighbors {
+		n := image.Pt(x, y).Add(d)
+		if next.At(n.X, n.Y) != c.Opponent() {
+			continue
+		}
+		if stones, liberties := next.Group(n.X, n.Y); liberties == 0 {
+			for _, p := range stones {
+				next.Set(p.X, p.Y, Empty)
+			}
+			captured += len(stones)
+		}
+	}
+	if _, liberties := next.Group(x, y); liberties == 0 {
+		return 0, fmt.Errorf("%s 是自杀", coords.Format(x, y, coords.GTP))
+	}
+
+	*b = next
+	return captured, nil
+}
+
+// Game 按规则记录一局棋并累计双方被提的子数，用于在没有 OCR 时从盘面推断手数。
+// 同步过程中多个协程会落子与读取，方法可并发调用
+type Game struct {
+	mu       sync.Mutex
+	board    Board
+	captured [3]int // 按被提棋子的颜色
+	// uncounted Setup 摆上的局面之前被提的子数，不知道是哪方的，只计入 InferMoveNumber
+	uncounted int
+}
+
+func NewGame() *Game {
+	return &Game{}
+}
+
+// Play 按规则落子，返回提子数
+func (g *Game) Play(x, y int, c Color) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	n, err := g.board.Play(x, y, c)
+	if err == nil {
+		g.captured[c.Opponent()] += n
+	}
+	return n, err
+}
+
+// Board 返回当前局面
+func (g *Game) Board() Board {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.board
+}
+
+// Captured 返回 c 方被提的子数
+func (g *Game) Captured(c Color) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.captured[c]
+}
+
+// Reset 清空局面与提子数，开始新对局时调用
+func (g *Game) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.board = Board{}
+	g.captured = [3]int{}
+	g.uncounted = 0
+}
+
+// Setup 从对局中途的局面 b 开始记录，moves 为 b 对应的手数（未知时为 0）。
+// 手数多于盘上棋子数的部分视为此前被提的子，使 InferMoveNumber 从这里接着数
+func (g *Game) Setup(b Board, moves int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.board = b
+	g.captured = [3]int{}
+	g.uncounted = max(moves-b.Count(Black)-b.Count(White), 0)
+}
+
+// InferMoveNumber 按盘面 b 上的棋子数加上对局中被提的子数推断已下的手数：
+// 每手棋恰好在盘上留下一颗子，被提的子离开了棋盘但仍计过手数。
+// 不计停一手与让子，b 为空盘时返回 0
+func (g *Game) InferMoveNumber(b *Board) int {
+	stones := b.Count(Black) + b.Count(White)
+	if stones == 0 {
+		return 0
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return stones + g.captured[Black] + g.captured[White] + g.uncounted
+}
+
+// SideToMove 推断局面 b 轮到哪方落子。moves 为已下的手数（未知时为 0），handicap 为让子数。
+// 手数已知时按奇偶判断（让子时白先下）；未知时比较双方棋子数，不考虑提子，黑子比白子多出
+// 让子数（不让子时为 1）时轮到白
+func SideToMove(b *Board, moves, handicap int) Color {
+	first, second := Black, White
+	if handicap >= 2 {
+		first, second = White, Black
+	}
+	if moves > 0 {
+		if moves%2 == 0 {
+			return first
+		}
+		return second
+	}
+	if b.Count(Black)-b.Count(White) >= max(handicap, 1) {
+		return White
+	}
+	return Black
+}
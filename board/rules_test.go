@@ -0,0 +1,193 @@
+package board
+
+import (
+	"image"
+	"testing"
+)
+
+func TestPlay(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    []Change
+		at       image.Point
+		color    Color
+		captured int
+		wantErr  bool
+	}{
+		{
+			name:  "空点落子",
+			at:    image.Pt(3, 3),
+			color: Black,
+		},
+		{
+			name:    "已有棋子",
+			setup:   []Change{{X: 3, Y: 3, To: White}},
+			at:      image.Pt(3, 3),
+			color:   Black,
+			wantErr: true,
+		},
+		{
+			name:     "角上提一子",
+			setup:    []Change{{X: 0, Y: 0, To: White}, {X: 1, Y: 0, To: Black}},
+			at:       image.Pt(0, 1),
+			color:    Black,
+			captured: 1,
+		},
+		{
+			name: "边上提两子",
+			setup: []Change{
+				{X: 3, Y: 0, To: White}, {X: 4, Y: 0, To: White},
+				{X: 2, Y: 0, To: Black}, {X: 3, Y: 1, To: Black}, {X: 4, Y: 1, To: Black},
+			},
+			at:       image.Pt(5, 0),
+			color:    Black,
+			captured: 2,
+		},
+		{
+			name:    "自杀",
+			setup:   []Change{{X: 1, Y: 0, To: White}, {X: 0, Y: 1, To: White}},
+			at:      image.Pt(0, 0),
+			color:   Black,
+			wantErr: true,
+		},
+		{
+			name: "看似自杀但能提子",
+			setup: []Change{
+				{X: 1, Y: 0, To: White}, {X: 0, Y: 1, To: White},
+				{X: 2, Y: 0, To: Black}, {X: 1, Y: 1, To: Black},
+			},
+			at:       image.Pt(0, 0),
+			color:    Black,
+			captured: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b Board
+			for _, s := range tt.setup {
+				b.Set(s.X, s.Y, s.To)
+			}
+			before := b
+
+			captured, err := b.Play(tt.at.X, tt.at.Y, tt.color)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Play() expected error")
+				}
+				if b != before {
+					t.Errorf("Play() 出错时不应改变局面")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Play() unexpected error: %v", err)
+			}
+			if captured != tt.captured {
+				t.Errorf("Play() 提子 %d, want %d", captured, tt.captured)
+			}
+			if b.At(tt.at.X, tt.at.Y) != tt.color {
+				t.Errorf("落子点 = %v, want %v", b.At(tt.at.X, tt.at.Y), tt.color)
+			}
+		})
+	}
+}
+
+func TestInferMoveNumber(t *testing.T) {
+	g := NewGame()
+	if got := g.InferMoveNumber(&Board{}); got != 0 {
+		t.Errorf("空盘 InferMoveNumber() = %d, want 0", got)
+	}
+
+	// 白 A1 被黑 B1、A2 提掉，盘上剩 4 子，共下了 5 手
+	moves := []struct {
+		x, y int
+		c    Color
+	}{
+		{1, 0, Black}, {0, 0, White}, {0, 1, Black}, {10, 10, White},
+	}
+	for _, m := range moves {
+		if _, err := g.Play(m.x, m.y, m.c); err != nil {
+			t.Fatalf("Play(%d, %d) error = %v", m.x, m.y, err)
+		}
+	}
+	if g.Captured(White) != 1 || g.Captured(Black) != 0 {
+		t.Fatalf("Captured() = 黑 %d 白 %d, want 0 1", g.Captured(Black), g.Captured(White))
+	}
+
+	// 屏幕上又多了黑棋一手，推断为第 5 手
+	screen := g.Board()
+	screen.Set(15, 15, Black)
+	if got := g.InferMoveNumber(&screen); got != 5 {
+		t.Errorf("InferMoveNumber() = %d, want 5", got)
+	}
+
+	g.Reset()
+	if g.Captured(White) != 0 || g.Board() != (Board{}) {
+		t.Errorf("Reset() 后应清空局面与提子数")
+	}
+}
+
+func TestGameSetup(t *testing.T) {
+	var b Board
+	b.Set(3, 3, Black)
+	b.Set(15, 15, Black)
+	b.Set(15, 3, White)
+
+	// 已下 5 手、盘上 3 子：此前提掉了 2 子
+	g := NewGame()
+	g.Setup(b, 5)
+	if g.Board() != b {
+		t.Fatal("Setup() 后局面应为 b")
+	}
+	if _, err := g.Play(3, 15, White); err != nil {
+		t.Fatal(err)
+	}
+	screen := g.Board()
+	if got := g.InferMoveNumber(&screen); got != 6 {
+		t.Errorf("InferMoveNumber() = %d, want 6", got)
+	}
+
+	// 手数未知时按盘上棋子数
+	g.Setup(b, 0)
+	if got := g.InferMoveNumber(&b); got != 3 {
+		t.Errorf("手数未知时 InferMoveNumber() = %d, want 3", got)
+	}
+	g.Reset()
+	if got := g.InferMoveNumber(&b); got != 3 {
+		t.Errorf("Reset() 后 InferMoveNumber() = %d, want 3", got)
+	}
+}
+
+func TestSideToMove(t *testing.T) {
+	var one, two, handicap, answered Board
+	one.Set(3, 3, Black)
+	two.Set(3, 3, Black)
+	two.Set(15, 15, White)
+	handicap.Set(3, 3, Black)
+	handicap.Set(15, 15, Black)
+	answered = handicap
+	answered.Set(15, 3, White)
+
+	tests := []struct {
+		name     string
+		b        *Board
+		moves    int
+		handicap int
+		want     Color
+	}{
+		{"空盘", &Board{}, 0, 0, Black},
+		{"黑下了一手", &one, 0, 0, White},
+		{"双方各一手", &two, 0, 0, Black},
+		{"按手数", &two, 7, 0, White},
+		{"按手数偶数", &one, 8, 0, Black},
+		{"让两子", &handicap, 0, 2, White},
+		{"让两子白下了一手", &answered, 0, 2, Black},
+		{"让子时按手数", &handicap, 1, 2, Black},
+	}
+	for _, tt := range tests {
+		if got := SideToMove(tt.b, tt.moves, tt.handicap); got != tt.want {
+			t.Errorf("%s: SideToMove() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
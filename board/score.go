@@ -0,0 +1,104 @@
+package board
+
+import (
+	"fmt"
+	"image"
+
+	"goboardsync/coords"
+)
+
+// Estimate 按数子法的粗略形势判断：盘上的子加上只被一方包围的空点，不判断死活，
+// 中盘时大块未定的空点两边都不计
+type Estimate struct {
+	BlackStones, WhiteStones       int
+	BlackTerritory, WhiteTerritory int
+	Komi                           float64
+}
+
+// Black 黑方的子数加地
+func (e Estimate) Black() int {
+	return e.BlackStones + e.BlackTerritory
+}
+
+// White 白方的子数加地
+func (e Estimate) White() int {
+	return e.WhiteStones + e.WhiteTerritory
+}
+
+// Lead 黑方领先的子数（已扣除贴目），负数表示白方领先
+func (e Estimate) Lead() float64 {
+	return float64(e.Black()-e.White()) - e.Komi
+}
+
+func (e Estimate) String() string {
+	lead := e.Lead()
+	leader := "黑"
+	if lead < 0 {
+		leader, lead = "白", -lead
+	}
+	return fmt.Sprintf("黑 %d，白 %d，贴 %g，%s领先 %g", e.Black(), e.White(), e.Komi, leader, lead)
+}
+
+// Score 估算局面 b 的形势。空点连成的区域只与一方棋子相邻时算作该方的地
+func Score(b *Board, komi float64) Estimate {
+	e := Estimate{
+		BlackStones: b.Count(Black),
+		WhiteStones: b.Count(White),
+		Komi:        komi,
+	}
+
+	var visited [coords.Size][coords.Size]bool
+	for x := 0; x < coords.Size; x++ {
+		for y := 0; y < coords.Size; y++ {
+			if visited[x][y] || b.At(x, y) != Empty {
+				continue
+			}
+
+			size, borders := fillRegion(b, x, y, &visited)
+			switch borders {
+			case 1 << Black:
+				e.BlackTerritory += size
+			case 1 << White:
+				e.WhiteTerritory += size
+			}
+		}
+	}
+	return e
+}
+
+// ScoreDead 去掉 dead 中的死子后估算形势：死子所在的点按空点计，与周围的空点一起归围住它的一方。
+// 数子阶段双方确认死子后，结果与 App 的数子结果一致
+func ScoreDead(b *Board, dead []image.Point, komi float64) Estimate {
+	cleaned := *b
+	for _, p := range dead {
+		cleaned.Set(p.X, p.Y, Empty)
+	}
+	return Score(&cleaned, komi)
+}
+
+// fillRegion 从 (x, y) 出发标记相连的空点，返回区域大小与相邻棋子颜色的位集合
+func fillRegion(b *Board, x, y int, visited *[coords.Size][coords.Size]bool) (int, int) {
+	size, borders := 0, 0
+	stack := []image.Point{{x, y}}
+	visited[x][y] = true
+
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		size++
+
+		for _, d := range []image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			n := p.Add(d)
+			if !coords.Valid(n.X, n.Y) {
+				continue
+			}
+			if c := b.At(n.X, n.Y); c != Empty {
+				borders |= 1 << c
+			} else if !visited[n.X][n.Y] {
+				visited[n.X][n.Y] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	return size, borders
+}
@@ -0,0 +1,67 @@
+package board
+
+import (
+	"image"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	// 黑棋占据左边 x=0..8（以 x=9 为墙），白棋占据右边（以 x=10 为墙），中间 x=9、10 两列为子
+	var b Board
+	for y := 0; y < 19; y++ {
+		b.Set(9, y, Black)
+		b.Set(10, y, White)
+	}
+
+	e := Score(&b, 7.5)
+	if e.BlackStones != 19 || e.WhiteStones != 19 {
+		t.Errorf("子数 = %d/%d, want 19/19", e.BlackStones, e.WhiteStones)
+	}
+	if e.BlackTerritory != 9*19 || e.WhiteTerritory != 8*19 {
+		t.Errorf("地 = %d/%d, want %d/%d", e.BlackTerritory, e.WhiteTerritory, 9*19, 8*19)
+	}
+	if got := e.Lead(); got != 19-7.5 {
+		t.Errorf("Lead() = %v, want %v", got, 19-7.5)
+	}
+	if got, want := e.String(), "黑 190，白 171，贴 7.5，黑领先 11.5"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestScoreSharedRegion(t *testing.T) {
+	// 双方棋子共同包围的空点不算任何一方的地
+	var b Board
+	b.Set(3, 3, Black)
+	b.Set(15, 15, White)
+
+	e := Score(&b, 0)
+	if e.BlackTerritory != 0 || e.WhiteTerritory != 0 {
+		t.Errorf("地 = %d/%d, want 0/0", e.BlackTerritory, e.WhiteTerritory)
+	}
+
+	var empty Board
+	if got := Score(&empty, 7.5).String(); got != "黑 0，白 0，贴 7.5，白领先 7.5" {
+		t.Errorf("空棋盘 String() = %q", got)
+	}
+}
+
+func TestScoreDead(t *testing.T) {
+	// 黑地里的一颗白死子：去掉后整个左边都是黑地，白子不计
+	var b Board
+	for y := 0; y < 19; y++ {
+		b.Set(9, y, Black)
+		b.Set(10, y, White)
+	}
+	b.Set(3, 3, White)
+
+	if e := Score(&b, 7.5); e.BlackTerritory != 0 || e.WhiteStones != 20 {
+		t.Errorf("不去死子时 地/白子数 = %d/%d, want 0/20", e.BlackTerritory, e.WhiteStones)
+	}
+	e := ScoreDead(&b, []image.Point{{3, 3}}, 7.5)
+	if e.BlackTerritory != 9*19 || e.WhiteStones != 19 {
+		t.Errorf("ScoreDead() 地/白子数 = %d/%d, want %d/19", e.BlackTerritory, e.WhiteStones, 9*19)
+	}
+	if b.At(3, 3) != White {
+		t.Error("ScoreDead() 修改了传入的局面")
+	}
+}
@@ -0,0 +1,283 @@
+// Package bugreport 把最近的调试帧、识别详情、当前配置与日志打包成一个 zip，供用户附在 issue 上。
+// 截图中的名字、头像由调用方传入的 Anonymize 遮挡；配置与日志中 URL 的认证信息、对局者名字被替换为 ***。
+package bugreport
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"goboardsync/debugsink"
+)
+
+// Options 报告的内容
+type Options struct {
+	// DebugDir 调试目录（见 debugsink），取其中最近一次运行的最后 Frames 帧
+	DebugDir string
+	Frames   int
+	// Anonymize 遮挡截图中的个人信息并返回处理后的图片，返回错误的帧不打包
+	Anonymize func(data []byte) ([]byte, error)
+	// Config 当前生效的配置，KEY=value 格式（见 config.Dump）
+	Config []string
+	// ConfigFile 参数文件，为空时不打包
+	ConfigFile string
+	// LogDir 服务模式的日志目录，打包其中的 goboardsync.log 与轮转出的旧日志
+	LogDir string
+}
+
+// Summary 报告打包了哪些内容
+type Summary struct {
+	// Run 帧所在的调试运行目录，没有调试帧时为空
+	Run string
+	// Frames 打包的帧数，Skipped 无法遮挡个人信息而略去的帧数
+	Frames  int
+	Skipped int
+	Logs    []string
+}
+
+// hiddenKeys 值可能带有密钥的配置项，只记录是否设置
+var hiddenKeys = map[string]bool{
+	"HOOK_COMMAND": true,
+}
+
+// Write 把报告写成 zip 到 w。没有调试帧或日志时只打包其余内容
+func Write(w io.Writer, opts Options) (Summary, error) {
+	var summary Summary
+	zw := zip.NewWriter(w)
+
+	if opts.DebugDir != "" && opts.Frames > 0 {
+		if err := writeFrames(zw, opts, &summary); err != nil {
+			return summary, err
+		}
+	}
+
+	var config strings.Builder
+	for _, line := range opts.Config {
+		config.WriteString(redactLine(line) + "\n")
+	}
+	if err := writeFile(zw, "config.txt", []byte(config.String())); err != nil {
+		return summary, err
+	}
+	if opts.ConfigFile != "" {
+		data, err := os.ReadFile(opts.ConfigFile)
+		if err != nil {
+			return summary, fmt.Errorf("读取参数文件失败: %v", err)
+		}
+		lines := strings.Split(string(data), "\n")
+		for i := range lines {
+			lines[i] = redactLine(lines[i])
+		}
+		if err := writeFile(zw, "config-file.txt", []byte(strings.Join(lines, "\n"))); err != nil {
+			return summary, err
+		}
+	}
+
+	if opts.LogDir != "" {
+		if err := writeLogs(zw, opts.LogDir, &summary); err != nil {
+			return summary, err
+		}
+	}
+
+	info, _ := json.MarshalIndent(map[string]any{
+		"created": time.Now().Format(time.RFC3339),
+		"os":      runtime.GOOS,
+		"arch":    runtime.GOARCH,
+		"go":      runtime.Version(),
+		"run":     filepath.Base(summary.Run),
+		"frames":  summary.Frames,
+		"skipped": summary.Skipped,
+	}, "", "  ")
+	if err := writeFile(zw, "report.json", info); err != nil {
+		return summary, err
+	}
+	return summary, zw.Close()
+}
+
+// writeFrames 打包最近一次调试运行的最后 opts.Frames 帧及其识别详情（frames/index.json）
+func writeFrames(zw *zip.Writer, opts Options, summary *Summary) error {
+	dir, index, err := debugsink.LatestRun(opts.DebugDir)
+	if err != nil {
+		return fmt.Errorf("读取调试目录失败: %v", err)
+	}
+	summary.Run = dir
+	if len(index) > opts.Frames {
+		index = index[len(index)-opts.Frames:]
+	}
+
+	var kept []debugsink.Entry
+	for _, entry := range index {
+		files := make(map[string][]byte, len(entry.Files))
+		ok := true
+		for _, name := range entry.Files {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				ok = false
+				break
+			}
+			if isImage(name) {
+				if opts.Anonymize == nil {
+					ok = false
+					break
+				}
+				if data, err = opts.Anonymize(data); err != nil {
+					ok = false
+					break
+				}
+			}
+			files[name] = data
+		}
+		if !ok {
+			summary.Skipped++
+			continue
+		}
+
+		for _, name := range entry.Files {
+			if err := writeFile(zw, "frames/"+name, files[name]); err != nil {
+				return err
+			}
+		}
+		kept = append(kept, entry)
+		summary.Frames++
+	}
+
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(zw, "frames/index.json", data)
+}
+
+func isImage(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg", ".png":
+		return true
+	}
+	return false
+}
+
+// writeLogs 打包 goboardsync.log 及其轮转文件，先从全部日志中收集对局者名字再逐个替换
+func writeLogs(zw *zip.Writer, dir string, summary *Summary) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "goboardsync.log*"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	logs := make([]string, len(paths))
+	names := map[string]bool{}
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("读取日志失败: %v", err)
+		}
+		logs[i] = string(data)
+		for _, name := range playerNames(logs[i]) {
+			names[name] = true
+		}
+	}
+
+	for i, path := range paths {
+		name := filepath.Base(path)
+		if err := writeFile(zw, "logs/"+name, []byte(redactLog(logs[i], names))); err != nil {
+			return err
+		}
+		summary.Logs = append(summary.Logs, name)
+	}
+	return nil
+}
+
+// gamePattern 匹配开始新对局时的日志（黑 "名字" 白 "名字"）
+var gamePattern = regexp.MustCompile(`黑 ("(?:[^"\\]|\\.)*") 白 ("(?:[^"\\]|\\.)*")`)
+
+// playerNames 从日志中找出对局者的名字
+func playerNames(log string) []string {
+	var names []string
+	for _, m := range gamePattern.FindAllStringSubmatch(log, -1) {
+		for _, quoted := range m[1:] {
+			if name, err := strconv.Unquote(quoted); err == nil && name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// userinfoPattern 匹配 URL 中的认证信息（scheme://user:password@）
+var userinfoPattern = regexp.MustCompile(`://[^/\s@]+@`)
+
+// redactLog 把日志中的对局者名字与 URL 认证信息替换为 ***
+func redactLog(log string, names map[string]bool) string {
+	log = userinfoPattern.ReplaceAllString(log, "://***@")
+	// 先替换长的名字，避免一个名字是另一个的一部分时替换不完整
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, name := range sorted {
+		log = strings.ReplaceAll(log, strconv.Quote(name), `"***"`)
+		log = strings.ReplaceAll(log, name, "***")
+	}
+	return log
+}
+
+// redactLine 隐去一行 KEY=value 配置中的敏感内容：hiddenKeys 只记录是否设置，URL 去掉认证信息与查询参数的值
+func redactLine(line string) string {
+	key, value, ok := strings.Cut(line, "=")
+	if !ok || strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return line
+	}
+	switch {
+	case hiddenKeys[strings.TrimSpace(key)] && strings.TrimSpace(value) != "":
+		return key + "=***"
+	case strings.Contains(value, "://"):
+		// 值可能是逗号或 | 分隔的多个地址（如 KATRAIN_MIRRORS、TABLE_KATRAINS），逐个处理
+		fields := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '|' || r == ' ' })
+		for _, field := range fields {
+			if strings.Contains(field, "://") {
+				value = strings.Replace(value, field, redactURL(field), 1)
+			}
+		}
+		return key + "=" + value
+	}
+	return line
+}
+
+func redactURL(value string) string {
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil || u.Host == "" {
+		return userinfoPattern.ReplaceAllString(value, "://***@")
+	}
+	hadUser := u.User != nil
+	u.User = nil
+	var query []string
+	for key := range u.Query() {
+		query = append(query, key+"=***")
+	}
+	sort.Strings(query)
+	u.RawQuery = strings.Join(query, "&")
+	if hadUser {
+		// url.User 会把 * 转义，直接拼接
+		return strings.Replace(u.String(), "://", "://***@", 1)
+	}
+	return u.String()
+}
+
+func writeFile(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
@@ -0,0 +1,188 @@
+package capture
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"goboardsync/adb"
+
+	"github.com/nfnt/resize"
+	"gocv.io/x/gocv"
+)
+
+// ADBSource 通过 adb screencap 截取手机屏幕，并缩放到统一分辨率。可以并发调用，
+// 各次截图依次进行（都经过同一个 ImagePath）
+type ADBSource struct {
+	ADB       *adb.Client
+	TempDir   string // 临时 PNG 的存放目录
+	ImagePath string // 转换后的 JPG 截图路径
+	Width     int
+	Height    int
+
+	// mu 从截屏到读回 ImagePath 期间持有，避免并发的截图互相覆盖、删除对方的文件
+	mu sync.Mutex
+}
+
+func NewADBSource(client *adb.Client, tempDir, imagePath string, width, height int) *ADBSource {
+	return &ADBSource{
+		ADB:       client,
+		TempDir:   tempDir,
+		ImagePath: imagePath,
+		Width:     width,
+		Height:    height,
+	}
+}
+
+func (s *ADBSource) Grab(ctx context.Context) (gocv.Mat, error) {
+	img, _, err := s.GrabStamped(ctx)
+	return img, err
+}
+
+// GrabStamped 截屏并返回截屏前一刻手机的开机时长，读不到时为 0
+func (s *ADBSource) GrabStamped(ctx context.Context) (gocv.Mat, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path, stamp, err := s.capture(ctx)
+	if err != nil {
+		return gocv.Mat{}, 0, err
+	}
+	defer os.Remove(path)
+
+	if err := resizeImage(path, s.Width, s.Height); err != nil {
+		fmt.Printf("[%s] 图片缩放失败: %v\n", time.Now().Format("15:04:05"), err)
+	}
+
+	img := gocv.IMRead(path, gocv.IMReadColor)
+	if img.Empty() {
+		return gocv.Mat{}, 0, fmt.Errorf("无法读取图片")
+	}
+	return img, stamp, nil
+}
+
+func (s *ADBSource) Close() error {
+	return nil
+}
+
+// Capture 截屏并保存为 ImagePath，返回截图路径。调用方读取文件期间不持有锁，需要自行避免与其他截图并发
+func (s *ADBSource) Capture(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path, _, err := s.capture(ctx)
+	return path, err
+}
+
+// capture 同 Capture，另外返回截屏前一刻手机的开机时长（与 screencap 在同一条 shell 命令中读取，不多一次 adb 调用）
+func (s *ADBSource) capture(ctx context.Context) (string, time.Duration, error) {
+	timestamp := time.Now().UnixNano()
+	remotePath := fmt.Sprintf("/sdcard/go_screenshot_%d.png", timestamp)
+	tempPNGPath := filepath.Join(s.TempDir, fmt.Sprintf("temp_%d.png", timestamp))
+
+	// 无论成功与否都删除手机与本地的中间文件，拉取中断（含 ctx 取消）时不留下残缺的 PNG
+	defer os.Remove(tempPNGPath)
+	defer s.ADB.Run(context.WithoutCancel(ctx), "shell", "rm", "-f", remotePath)
+
+	out, err := s.ADB.Output(ctx, "shell", "cat", "/proc/uptime", "&&", "screencap", "-p", remotePath)
+	if err != nil {
+		return "", 0, fmt.Errorf("ADB 截图失败: %v", err)
+	}
+	// 读不到开机时长时只是没有时间戳，截图照常使用
+	stamp, _ := adb.ParseUptime(string(out))
+
+	if err := s.ADB.Run(ctx, "pull", remotePath, tempPNGPath); err != nil {
+		return "", 0, fmt.Errorf("拉取截图失败: %v", err)
+	}
+
+	if _, err := os.Stat(tempPNGPath); os.IsNotExist(err) {
+		return "", 0, fmt.Errorf("截图文件未生成")
+	}
+
+	if err := convertPNGtoJPG(tempPNGPath, s.ImagePath); err != nil {
+		return "", 0, fmt.Errorf("转换格式失败: %v", err)
+	}
+
+	return s.ImagePath, stamp, nil
+}
+
+func convertPNGtoJPG(pngPath, jpgPath string) error {
+	file, err := os.Open(pngPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(jpgPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	return jpeg.Encode(out, img, &jpeg.Options{Quality: 90})
+}
+
+func resizeImage(imagePath string, targetW, targetH int) error {
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return err
+	}
+
+	bounds := img.Bounds()
+	targetW, targetH = Fit(bounds.Dx(), bounds.Dy(), targetW, targetH)
+	newImg := resize.Resize(uint(targetW), uint(targetH), img, resize.Lanczos3)
+
+	out, err := os.Create(imagePath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	return png.Encode(out, newImg)
+}
+
+// JPEG 截屏并缩放到统一分辨率，返回按 quality（1-100）压缩的 JPEG 数据，不依赖 OpenCV，
+// 供远程采集端把画面发给分析端
+func (s *ADBSource) JPEG(ctx context.Context, quality int) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path, _, err := s.capture(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	w, h := Fit(bounds.Dx(), bounds.Dy(), s.Width, s.Height)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, resize.Resize(uint(w), uint(h), img, resize.Lanczos3), &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,45 @@
+package capture
+
+import (
+	"context"
+	"fmt"
+
+	"gocv.io/x/gocv"
+)
+
+// CameraSource 从摄像头读取画面，用于同步实体棋盘
+type CameraSource struct {
+	Device int
+	webcam *gocv.VideoCapture
+}
+
+// NewCameraSource 打开编号为 device 的摄像头，width/height 为 0 时使用摄像头默认分辨率
+func NewCameraSource(device, width, height int) (*CameraSource, error) {
+	webcam, err := gocv.VideoCaptureDevice(device)
+	if err != nil {
+		return nil, fmt.Errorf("打开摄像头失败: %v", err)
+	}
+
+	if width > 0 && height > 0 {
+		webcam.Set(gocv.VideoCaptureFrameWidth, float64(width))
+		webcam.Set(gocv.VideoCaptureFrameHeight, float64(height))
+	}
+
+	return &CameraSource{Device: device, webcam: webcam}, nil
+}
+
+func (s *CameraSource) Grab(ctx context.Context) (gocv.Mat, error) {
+	if err := ctx.Err(); err != nil {
+		return gocv.Mat{}, err
+	}
+	img := gocv.NewMat()
+	if ok := s.webcam.Read(&img); !ok || img.Empty() {
+		img.Close()
+		return gocv.Mat{}, fmt.Errorf("摄像头 %d 读取失败", s.Device)
+	}
+	return img, nil
+}
+
+func (s *CameraSource) Close() error {
+	return s.webcam.Close()
+}
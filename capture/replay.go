@@ -0,0 +1,56 @@
+package capture
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"gocv.io/x/gocv"
+)
+
+// ReplaySource 按顺序回放录制好的截图，用于在没有手机的环境下重现一段对局。
+// 每次 Grab 前进一帧，放完后一直返回最后一帧（局面不再变化）
+type ReplaySource struct {
+	Paths []string
+
+	mu   sync.Mutex
+	next int
+}
+
+func NewReplaySource(paths []string) *ReplaySource {
+	return &ReplaySource{Paths: paths}
+}
+
+func (s *ReplaySource) Grab(ctx context.Context) (gocv.Mat, error) {
+	if err := ctx.Err(); err != nil {
+		return gocv.Mat{}, err
+	}
+	s.mu.Lock()
+	if len(s.Paths) == 0 {
+		s.mu.Unlock()
+		return gocv.Mat{}, fmt.Errorf("没有可回放的截图")
+	}
+	path := s.Paths[len(s.Paths)-1]
+	if s.next < len(s.Paths) {
+		path = s.Paths[s.next]
+		s.next++
+	}
+	s.mu.Unlock()
+
+	img := gocv.IMRead(path, gocv.IMReadColor)
+	if img.Empty() {
+		return gocv.Mat{}, fmt.Errorf("无法读取图片: %s", path)
+	}
+	return img, nil
+}
+
+// Done 是否已回放完全部截图
+func (s *ReplaySource) Done() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.next >= len(s.Paths)
+}
+
+func (s *ReplaySource) Close() error {
+	return nil
+}
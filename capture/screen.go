@@ -0,0 +1,115 @@
+package capture
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+// ScreenSource 截取本机桌面的一块区域（如 scrcpy 投屏窗口、桌面围棋客户端），
+// 只投屏不需要 ADB。截屏调用系统工具：macOS 使用 screencapture，Linux 使用 grim（Wayland）或 ImageMagick import（X11），
+// Windows 使用 PowerShell（System.Drawing）
+type ScreenSource struct {
+	Region  image.Rectangle // 桌面坐标中的截取区域，为空时截取整个屏幕
+	TempDir string
+	// Width、Height 缩放目标尺寸（scrcpy 投屏时为手机分辨率），为 0 时保持截图原尺寸（桌面客户端）
+	Width  int
+	Height int
+}
+
+func NewScreenSource(region image.Rectangle, tempDir string, width, height int) *ScreenSource {
+	return &ScreenSource{
+		Region:  region,
+		TempDir: tempDir,
+		Width:   width,
+		Height:  height,
+	}
+}
+
+func (s *ScreenSource) Grab(ctx context.Context) (gocv.Mat, error) {
+	path := filepath.Join(s.TempDir, fmt.Sprintf("screen_%d.png", time.Now().UnixNano()))
+	defer os.Remove(path)
+
+	cmd, err := screenCommand(ctx, s.Region, path)
+	if err != nil {
+		return gocv.Mat{}, err
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return gocv.Mat{}, fmt.Errorf("桌面截图失败: %v %s", err, out)
+	}
+
+	img := gocv.IMRead(path, gocv.IMReadColor)
+	if img.Empty() {
+		return gocv.Mat{}, fmt.Errorf("无法读取桌面截图")
+	}
+
+	// 投屏时 Retina 等高分屏上截图尺寸是逻辑区域的整数倍，统一缩放到手机分辨率（横屏时宽高互换）
+	width, height := Fit(img.Cols(), img.Rows(), s.Width, s.Height)
+	if width > 0 && height > 0 && (img.Cols() != width || img.Rows() != height) {
+		resized := gocv.NewMat()
+		gocv.Resize(img, &resized, image.Pt(width, height), 0, 0, gocv.InterpolationArea)
+		img.Close()
+		img = resized
+	}
+	return img, nil
+}
+
+func (s *ScreenSource) Close() error {
+	return nil
+}
+
+// screenCommand 返回把 region 截图保存为 PNG 的系统命令
+func screenCommand(ctx context.Context, region image.Rectangle, path string) (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		args := []string{"-x"}
+		if !region.Empty() {
+			args = append(args, "-R", fmt.Sprintf("%d,%d,%d,%d", region.Min.X, region.Min.Y, region.Dx(), region.Dy()))
+		}
+		return exec.CommandContext(ctx, "screencapture", append(args, path)...), nil
+
+	case "linux":
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			if grim, err := exec.LookPath("grim"); err == nil {
+				args := []string{}
+				if !region.Empty() {
+					args = append(args, "-g", fmt.Sprintf("%d,%d %dx%d", region.Min.X, region.Min.Y, region.Dx(), region.Dy()))
+				}
+				return exec.CommandContext(ctx, grim, append(args, path)...), nil
+			}
+		}
+		importPath, err := exec.LookPath("import")
+		if err != nil {
+			return nil, fmt.Errorf("未找到截图工具（grim 或 ImageMagick import）: %v", err)
+		}
+		args := []string{"-window", "root"}
+		if !region.Empty() {
+			args = append(args, "-crop", fmt.Sprintf("%dx%d+%d+%d", region.Dx(), region.Dy(), region.Min.X, region.Min.Y))
+		}
+		return exec.CommandContext(ctx, importPath, append(args, path)...), nil
+
+	case "windows":
+		bounds := "[System.Windows.Forms.SystemInformation]::VirtualScreen"
+		if !region.Empty() {
+			bounds = fmt.Sprintf("New-Object System.Drawing.Rectangle(%d, %d, %d, %d)", region.Min.X, region.Min.Y, region.Dx(), region.Dy())
+		}
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms, System.Drawing
+$r = %s
+$bmp = New-Object System.Drawing.Bitmap($r.Width, $r.Height)
+$g = [System.Drawing.Graphics]::FromImage($bmp)
+$g.CopyFromScreen($r.Location, [System.Drawing.Point]::Empty, $r.Size)
+$bmp.Save('%s', [System.Drawing.Imaging.ImageFormat]::Png)
+$g.Dispose()
+$bmp.Dispose()`, bounds, strings.ReplaceAll(path, "'", "''"))
+		return exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", script), nil
+	}
+	return nil, fmt.Errorf("当前系统不支持桌面截图: %s", runtime.GOOS)
+}
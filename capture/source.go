@@ -0,0 +1,42 @@
+// Package capture 提供同步所用的画面来源：手机 ADB 截屏、摄像头等。
+package capture
+
+import (
+	"context"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+// Source 画面来源
+type Source interface {
+	// Grab 获取一帧 BGR 图像，调用方负责 Close；ctx 取消时尽快返回错误
+	Grab(ctx context.Context) (gocv.Mat, error)
+	// Close 释放来源占用的资源
+	Close() error
+}
+
+// Stamped 能给出每帧截取时刻的来源。时间戳为截取时手机的开机时长，与电脑的时钟无关，
+// 截图乱序到达（如多个截图请求并行）时可据此判断先后
+type Stamped interface {
+	// GrabStamped 与 Grab 相同，另外返回该帧的时间戳，读不到时为 0
+	GrabStamped(ctx context.Context) (gocv.Mat, time.Duration, error)
+}
+
+// GrabStamped 从 src 获取一帧；src 不提供时间戳时返回 0
+func GrabStamped(ctx context.Context, src Source) (gocv.Mat, time.Duration, error) {
+	if s, ok := src.(Stamped); ok {
+		return s.GrabStamped(ctx)
+	}
+	img, err := src.Grab(ctx)
+	return img, 0, err
+}
+
+// Fit 按画面的横竖方向调整缩放目标：横屏画面（宽大于高）配竖屏目标分辨率时交换宽高，
+// 反之亦然，避免横屏或平板横放时把画面压扁
+func Fit(w, h, targetW, targetH int) (int, int) {
+	if (w > h) != (targetW > targetH) && w != h {
+		return targetH, targetW
+	}
+	return targetW, targetH
+}
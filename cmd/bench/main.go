@@ -0,0 +1,60 @@
+// bench 批量识别带标注的截图样本，打印识别准确率并导出报告，用于跨提交比较识别效果。
+//
+//	bench [-images DIR] [-report DIR] [-skin NAME] [-templates DIR] [-fusion] [-min-rate N]
+//	    识别 DIR 中文件名形如 37-Q4-black.jpg 的样本（images/ 或 synthboard 的输出），打印每张的结果与统计。
+//	    -report 指定时在该目录写出 report.csv、report.json 与内嵌叠加图的 report.html。
+//	    成功率低于 -min-rate（百分比）时退出码为 1，可用于持续集成。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"goboardsync/vision"
+	"goboardsync/vision/bench"
+)
+
+func main() {
+	imagesDir := flag.String("images", "images", "样本目录")
+	reportDir := flag.String("report", "", "报告输出目录，为空时只打印到终端")
+	skin := flag.String("skin", "", "棋盘皮肤（classic/dark/green），为空时自动识别")
+	templates := flag.String("templates", "", "交叉点分类模板目录，为空时使用亮度规则")
+	fusion := flag.Bool("fusion", false, "融合角标、手数奇偶、局面变化与分类判断最后一手")
+	minRate := flag.Float64("min-rate", 0, "成功率下限（百分比），低于时退出码为 1")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "用法: bench [-images DIR] [-report DIR] [-skin NAME] [-templates DIR] [-fusion] [-min-rate N]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	opts := []vision.Option{vision.WithSkin(*skin), vision.WithFusion(*fusion), vision.WithDebugDetail(true)}
+	if *templates != "" {
+		classifier, err := vision.LoadTemplateClassifier(*templates)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(2)
+		}
+		opts = append(opts, vision.WithClassifier(classifier))
+	}
+
+	stats, details, err := bench.Run(*imagesDir, vision.NewDetector(opts...))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(2)
+	}
+	bench.Print(os.Stdout, stats, details)
+
+	if *reportDir != "" {
+		if err := bench.WriteReports(*reportDir, stats, details); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Printf("报告已写入 %s\n", *reportDir)
+	}
+
+	if stats.SuccessRate < *minRate {
+		fmt.Fprintf(os.Stderr, "❌ 成功率 %.2f%% 低于 %.2f%%\n", stats.SuccessRate, *minRate)
+		os.Exit(1)
+	}
+}
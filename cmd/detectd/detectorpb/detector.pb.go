@@ -0,0 +1,426 @@
+// detectd 识别服务的接口定义，供其他语言生成客户端。
+//
+// detectd 同时提供 gRPC 服务 Detector（-grpc-addr）与 HTTP/JSON 接口（-addr），两者识别结果相同：
+//
+//	Detector.Detect     / POST /v1/detect   Frame → Result      识别截图中的最后一手（vision.Detector.DetectLastMoveCoord）
+//	Detector.ReadBoard  / POST /v1/board    Frame → BoardState  重建整盘局面（vision.Detector.ReadScreenBoard），截图需为支持的分辨率
+//
+// 设置了令牌时，gRPC 请求需带元数据 authorization: Bearer <token>，HTTP 请求带同名请求头。
+// gRPC：令牌错误时返回 UNAUTHENTICATED；截图无法解码时返回 INVALID_ARGUMENT；ReadBoard 识别失败时返回 FAILED_PRECONDITION。
+// HTTP：请求与响应的 JSON 按 proto3 的 JSON 映射编码，bytes 字段为 base64；令牌错误时响应 401，
+// 请求体不是 Frame 或截图无法解码时响应 400，/v1/board 识别失败时响应 422，均以 Result.error 说明原因。
+// Detect 未找到最后一手时不算失败，x、y 为 0，error 说明原因
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.35.2
+// 	protoc        (unknown)
+// source: detector.proto
+
+package detectorpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Frame 一张手机截图
+type Frame struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// image JPEG 或 PNG 编码的截图
+	Image []byte `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	// move 已知的手数，为 0 时由服务通过 OCR 读取（未配置 OCR 时不读取）
+	Move int32 `protobuf:"varint,2,opt,name=move,proto3" json:"move,omitempty"`
+}
+
+func (x *Frame) Reset() {
+	*x = Frame{}
+	mi := &file_detector_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Frame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Frame) ProtoMessage() {}
+
+func (x *Frame) ProtoReflect() protoreflect.Message {
+	mi := &file_detector_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Frame.ProtoReflect.Descriptor instead.
+func (*Frame) Descriptor() ([]byte, []int) {
+	return file_detector_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Frame) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *Frame) GetMove() int32 {
+	if x != nil {
+		return x.Move
+	}
+	return 0
+}
+
+// Result 最后一手的识别结果。未找到时 x、y 为 0，error 说明原因
+type Result struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Move int32 `protobuf:"varint,1,opt,name=move,proto3" json:"move,omitempty"`
+	// color 为 B 或 W
+	Color string `protobuf:"bytes,2,opt,name=color,proto3" json:"color,omitempty"`
+	// x、y 为手机坐标：1-19，y 从上往下数
+	X          int32   `protobuf:"varint,3,opt,name=x,proto3" json:"x,omitempty"`
+	Y          int32   `protobuf:"varint,4,opt,name=y,proto3" json:"y,omitempty"`
+	Confidence float64 `protobuf:"fixed64,5,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	// coord 为 GTP 写法（如 Q16）
+	Coord string `protobuf:"bytes,6,opt,name=coord,proto3" json:"coord,omitempty"`
+	Error string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	// candidates 按得分从高到低排列的候选交叉点，第一个即 x、y；开启融合或没找到角标时为空
+	Candidates []*Candidate `protobuf:"bytes,8,rep,name=candidates,proto3" json:"candidates,omitempty"`
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_detector_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_detector_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_detector_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Result) GetMove() int32 {
+	if x != nil {
+		return x.Move
+	}
+	return 0
+}
+
+func (x *Result) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *Result) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Result) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *Result) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *Result) GetCoord() string {
+	if x != nil {
+		return x.Coord
+	}
+	return ""
+}
+
+func (x *Result) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Result) GetCandidates() []*Candidate {
+	if x != nil {
+		return x.Candidates
+	}
+	return nil
+}
+
+// Candidate 最后一手的一个候选交叉点，score 为该点角标面积占全部角标的比例
+type Candidate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X     int32   `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y     int32   `protobuf:"varint,2,opt,name=y,proto3" json:"y,omitempty"`
+	Score float64 `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`
+}
+
+func (x *Candidate) Reset() {
+	*x = Candidate{}
+	mi := &file_detector_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candidate) ProtoMessage() {}
+
+func (x *Candidate) ProtoReflect() protoreflect.Message {
+	mi := &file_detector_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candidate.ProtoReflect.Descriptor instead.
+func (*Candidate) Descriptor() ([]byte, []int) {
+	return file_detector_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Candidate) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Candidate) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *Candidate) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+// BoardState 整盘局面
+type BoardState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// rows 从上到下的 19 行，每行 19 个字符：. 空、B 黑、W 白
+	Rows  []string `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`
+	Black int32    `protobuf:"varint,2,opt,name=black,proto3" json:"black,omitempty"`
+	White int32    `protobuf:"varint,3,opt,name=white,proto3" json:"white,omitempty"`
+}
+
+func (x *BoardState) Reset() {
+	*x = BoardState{}
+	mi := &file_detector_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BoardState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BoardState) ProtoMessage() {}
+
+func (x *BoardState) ProtoReflect() protoreflect.Message {
+	mi := &file_detector_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BoardState.ProtoReflect.Descriptor instead.
+func (*BoardState) Descriptor() ([]byte, []int) {
+	return file_detector_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BoardState) GetRows() []string {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+func (x *BoardState) GetBlack() int32 {
+	if x != nil {
+		return x.Black
+	}
+	return 0
+}
+
+func (x *BoardState) GetWhite() int32 {
+	if x != nil {
+		return x.White
+	}
+	return 0
+}
+
+var File_detector_proto protoreflect.FileDescriptor
+
+var file_detector_proto_rawDesc = []byte{
+	0x0a, 0x0e, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x17, 0x67, 0x6f, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x73, 0x79, 0x6e, 0x63, 0x2e, 0x64, 0x65,
+	0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x22, 0x31, 0x0a, 0x05, 0x46, 0x72, 0x61,
+	0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x76, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6d, 0x6f, 0x76, 0x65, 0x22, 0xde, 0x01, 0x0a,
+	0x06, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x76, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6d, 0x6f, 0x76, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63,
+	0x6f, 0x6c, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x6f, 0x6c, 0x6f,
+	0x72, 0x12, 0x0c, 0x0a, 0x01, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x78, 0x12,
+	0x0c, 0x0a, 0x01, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x79, 0x12, 0x1e, 0x0a,
+	0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x6f,
+	0x6f, 0x72, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x42, 0x0a, 0x0a, 0x63, 0x61, 0x6e,
+	0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e,
+	0x67, 0x6f, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x73, 0x79, 0x6e, 0x63, 0x2e, 0x64, 0x65, 0x74, 0x65,
+	0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74,
+	0x65, 0x52, 0x0a, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x22, 0x3d, 0x0a,
+	0x09, 0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x12, 0x0c, 0x0a, 0x01, 0x78, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x78, 0x12, 0x0c, 0x0a, 0x01, 0x79, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x01, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x22, 0x4c, 0x0a, 0x0a,
+	0x42, 0x6f, 0x61, 0x72, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f,
+	0x77, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x62,
+	0x6c, 0x61, 0x63, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x68, 0x69, 0x74, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x77, 0x68, 0x69, 0x74, 0x65, 0x32, 0xa7, 0x01, 0x0a, 0x08, 0x44,
+	0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x49, 0x0a, 0x06, 0x44, 0x65, 0x74, 0x65, 0x63,
+	0x74, 0x12, 0x1e, 0x2e, 0x67, 0x6f, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x73, 0x79, 0x6e, 0x63, 0x2e,
+	0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x72, 0x61, 0x6d,
+	0x65, 0x1a, 0x1f, 0x2e, 0x67, 0x6f, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x73, 0x79, 0x6e, 0x63, 0x2e,
+	0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x12, 0x50, 0x0a, 0x09, 0x52, 0x65, 0x61, 0x64, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x12,
+	0x1e, 0x2e, 0x67, 0x6f, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x73, 0x79, 0x6e, 0x63, 0x2e, 0x64, 0x65,
+	0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x1a,
+	0x23, 0x2e, 0x67, 0x6f, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x73, 0x79, 0x6e, 0x63, 0x2e, 0x64, 0x65,
+	0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x42, 0x24, 0x5a, 0x22, 0x67, 0x6f, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x73,
+	0x79, 0x6e, 0x63, 0x2f, 0x63, 0x6d, 0x64, 0x2f, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x64, 0x2f,
+	0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_detector_proto_rawDescOnce sync.Once
+	file_detector_proto_rawDescData = file_detector_proto_rawDesc
+)
+
+func file_detector_proto_rawDescGZIP() []byte {
+	file_detector_proto_rawDescOnce.Do(func() {
+		file_detector_proto_rawDescData = protoimpl.X.CompressGZIP(file_detector_proto_rawDescData)
+	})
+	return file_detector_proto_rawDescData
+}
+
+var file_detector_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_detector_proto_goTypes = []any{
+	(*Frame)(nil),      // 0: goboardsync.detector.v1.Frame
+	(*Result)(nil),     // 1: goboardsync.detector.v1.Result
+	(*Candidate)(nil),  // 2: goboardsync.detector.v1.Candidate
+	(*BoardState)(nil), // 3: goboardsync.detector.v1.BoardState
+}
+var file_detector_proto_depIdxs = []int32{
+	2, // 0: goboardsync.detector.v1.Result.candidates:type_name -> goboardsync.detector.v1.Candidate
+	0, // 1: goboardsync.detector.v1.Detector.Detect:input_type -> goboardsync.detector.v1.Frame
+	0, // 2: goboardsync.detector.v1.Detector.ReadBoard:input_type -> goboardsync.detector.v1.Frame
+	1, // 3: goboardsync.detector.v1.Detector.Detect:output_type -> goboardsync.detector.v1.Result
+	3, // 4: goboardsync.detector.v1.Detector.ReadBoard:output_type -> goboardsync.detector.v1.BoardState
+	3, // [3:5] is the sub-list for method output_type
+	1, // [1:3] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_detector_proto_init() }
+func file_detector_proto_init() {
+	if File_detector_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_detector_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_detector_proto_goTypes,
+		DependencyIndexes: file_detector_proto_depIdxs,
+		MessageInfos:      file_detector_proto_msgTypes,
+	}.Build()
+	File_detector_proto = out.File
+	file_detector_proto_rawDesc = nil
+	file_detector_proto_goTypes = nil
+	file_detector_proto_depIdxs = nil
+}
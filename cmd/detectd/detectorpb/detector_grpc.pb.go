@@ -0,0 +1,176 @@
+// detectd 识别服务的接口定义，供其他语言生成客户端。
+//
+// detectd 同时提供 gRPC 服务 Detector（-grpc-addr）与 HTTP/JSON 接口（-addr），两者识别结果相同：
+//
+//	Detector.Detect     / POST /v1/detect   Frame → Result      识别截图中的最后一手（vision.Detector.DetectLastMoveCoord）
+//	Detector.ReadBoard  / POST /v1/board    Frame → BoardState  重建整盘局面（vision.Detector.ReadScreenBoard），截图需为支持的分辨率
+//
+// 设置了令牌时，gRPC 请求需带元数据 authorization: Bearer <token>，HTTP 请求带同名请求头。
+// gRPC：令牌错误时返回 UNAUTHENTICATED；截图无法解码时返回 INVALID_ARGUMENT；ReadBoard 识别失败时返回 FAILED_PRECONDITION。
+// HTTP：请求与响应的 JSON 按 proto3 的 JSON 映射编码，bytes 字段为 base64；令牌错误时响应 401，
+// 请求体不是 Frame 或截图无法解码时响应 400，/v1/board 识别失败时响应 422，均以 Result.error 说明原因。
+// Detect 未找到最后一手时不算失败，x、y 为 0，error 说明原因
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: detector.proto
+
+package detectorpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Detector_Detect_FullMethodName    = "/goboardsync.detector.v1.Detector/Detect"
+	Detector_ReadBoard_FullMethodName = "/goboardsync.detector.v1.Detector/ReadBoard"
+)
+
+// DetectorClient is the client API for Detector service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Detector 识别服务，识别请求依次处理
+type DetectorClient interface {
+	Detect(ctx context.Context, in *Frame, opts ...grpc.CallOption) (*Result, error)
+	ReadBoard(ctx context.Context, in *Frame, opts ...grpc.CallOption) (*BoardState, error)
+}
+
+type detectorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDetectorClient(cc grpc.ClientConnInterface) DetectorClient {
+	return &detectorClient{cc}
+}
+
+func (c *detectorClient) Detect(ctx context.Context, in *Frame, opts ...grpc.CallOption) (*Result, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Result)
+	err := c.cc.Invoke(ctx, Detector_Detect_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *detectorClient) ReadBoard(ctx context.Context, in *Frame, opts ...grpc.CallOption) (*BoardState, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BoardState)
+	err := c.cc.Invoke(ctx, Detector_ReadBoard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DetectorServer is the server API for Detector service.
+// All implementations must embed UnimplementedDetectorServer
+// for forward compatibility.
+//
+// Detector 识别服务，识别请求依次处理
+type DetectorServer interface {
+	Detect(context.Context, *Frame) (*Result, error)
+	ReadBoard(context.Context, *Frame) (*BoardState, error)
+	mustEmbedUnimplementedDetectorServer()
+}
+
+// UnimplementedDetectorServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDetectorServer struct{}
+
+func (UnimplementedDetectorServer) Detect(context.Context, *Frame) (*Result, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Detect not implemented")
+}
+func (UnimplementedDetectorServer) ReadBoard(context.Context, *Frame) (*BoardState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadBoard not implemented")
+}
+func (UnimplementedDetectorServer) mustEmbedUnimplementedDetectorServer() {}
+func (UnimplementedDetectorServer) testEmbeddedByValue()                  {}
+
+// UnsafeDetectorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DetectorServer will
+// result in compilation errors.
+type UnsafeDetectorServer interface {
+	mustEmbedUnimplementedDetectorServer()
+}
+
+func RegisterDetectorServer(s grpc.ServiceRegistrar, srv DetectorServer) {
+	// If the following call pancis, it indicates UnimplementedDetectorServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Detector_ServiceDesc, srv)
+}
+
+func _Detector_Detect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Frame)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DetectorServer).Detect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Detector_Detect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DetectorServer).Detect(ctx, req.(*Frame))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Detector_ReadBoard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Frame)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DetectorServer).ReadBoard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Detector_ReadBoard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DetectorServer).ReadBoard(ctx, req.(*Frame))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Detector_ServiceDesc is the grpc.ServiceDesc for Detector service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Detector_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "goboardsync.detector.v1.Detector",
+	HandlerType: (*DetectorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Detect",
+			Handler:    _Detector_Detect_Handler,
+		},
+		{
+			MethodName: "ReadBoard",
+			Handler:    _Detector_ReadBoard_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "detector.proto",
+}
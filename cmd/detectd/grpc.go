@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"goboardsync/cmd/detectd/detectorpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcDetector 按 detector.proto 中的 Detector 服务提供与 HTTP 接口相同的识别
+type grpcDetector struct {
+	detectorpb.UnimplementedDetectorServer
+	s *server
+}
+
+// grpcServer 创建 gRPC 服务：Detector 与标准的健康检查服务，设置了令牌时 Detector 的请求要带 authorization 元数据
+func (s *server) grpcServer() *grpc.Server {
+	g := grpc.NewServer(grpc.MaxRecvMsgSize(maxImageSize), grpc.UnaryInterceptor(s.authorizeRPC))
+	detectorpb.RegisterDetectorServer(g, grpcDetector{s: s})
+	healthpb.RegisterHealthServer(g, health.NewServer())
+	return g
+}
+
+// authorizeRPC 检查 authorization 元数据，健康检查与 HTTP 的 /healthz 一样不需要令牌
+func (s *server) authorizeRPC(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !strings.HasPrefix(info.FullMethod, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+		var authorization string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if v := md.Get("authorization"); len(v) > 0 {
+				authorization = v[0]
+			}
+		}
+		if !s.authorized(authorization) {
+			return nil, status.Error(codes.Unauthenticated, "unauthorized")
+		}
+	}
+	return handler(ctx, req)
+}
+
+func (g grpcDetector) Detect(ctx context.Context, f *detectorpb.Frame) (*detectorpb.Result, error) {
+	out, err := g.s.run(frame{Image: f.GetImage(), Move: int(f.GetMove())}, g.s.detect)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	r := out.(result)
+	pb := &detectorpb.Result{
+		Move:       int32(r.Move),
+		Color:      r.Color,
+		X:          int32(r.X),
+		Y:          int32(r.Y),
+		Confidence: r.Confidence,
+		Coord:      r.Coord,
+		Error:      r.Error,
+	}
+	for _, c := range r.Candidates {
+		pb.Candidates = append(pb.Candidates, &detectorpb.Candidate{X: int32(c.X), Y: int32(c.Y), Score: c.Score})
+	}
+	return pb, nil
+}
+
+func (g grpcDetector) ReadBoard(ctx context.Context, f *detectorpb.Frame) (*detectorpb.BoardState, error) {
+	out, err := g.s.run(frame{Image: f.GetImage(), Move: int(f.GetMove())}, g.s.readBoard)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	b := out.(boardState)
+	return &detectorpb.BoardState{Rows: b.Rows, Black: int32(b.Black), White: int32(b.White)}, nil
+}
+
+// rpcError 截图无法解码时返回 INVALID_ARGUMENT，识别失败时返回 FAILED_PRECONDITION，对应 HTTP 接口的 400 与 422
+func rpcError(err error) error {
+	if errors.Is(err, errBadImage) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.FailedPrecondition, err.Error())
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"goboardsync/cmd/detectd/detectorpb"
+	"goboardsync/vision"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialGRPC 在内存连接上启动 srv 的 gRPC 服务并返回客户端连接
+func dialGRPC(t *testing.T, srv *server) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	g := srv.grpcServer()
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///detectd",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGRPCAuth(t *testing.T) {
+	conn := dialGRPC(t, &server{detector: vision.NewDetector(), token: "secret"})
+	client := detectorpb.NewDetectorClient(conn)
+
+	for _, token := range []string{"", "wrong"} {
+		ctx := context.Background()
+		if token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+		}
+		if _, err := client.Detect(ctx, &detectorpb.Frame{}); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("Detect 令牌 %q: error = %v, want UNAUTHENTICATED", token, err)
+		}
+		if _, err := client.ReadBoard(ctx, &detectorpb.Frame{}); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("ReadBoard 令牌 %q: error = %v, want UNAUTHENTICATED", token, err)
+		}
+	}
+
+	// 令牌正确时进入截图检查
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	if _, err := client.Detect(ctx, &detectorpb.Frame{Move: 37}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("令牌正确: error = %v, want INVALID_ARGUMENT", err)
+	}
+	// 健康检查不需要令牌
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("健康检查 = %v, %v, want SERVING", resp.GetStatus(), err)
+	}
+}
+
+func TestGRPCBadImage(t *testing.T) {
+	client := detectorpb.NewDetectorClient(dialGRPC(t, &server{detector: vision.NewDetector()}))
+	for _, image := range [][]byte{nil, []byte("not an image")} {
+		if _, err := client.Detect(context.Background(), &detectorpb.Frame{Image: image}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Detect(%q): error = %v, want INVALID_ARGUMENT", image, err)
+		}
+		_, err := client.ReadBoard(context.Background(), &detectorpb.Frame{Image: image})
+		if status.Code(err) != codes.InvalidArgument || status.Convert(err).Message() != errBadImage.Error() {
+			t.Errorf("ReadBoard(%q): error = %v, want INVALID_ARGUMENT %s", image, err, errBadImage)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// detectd 把识别引擎作为网络服务运行，Python 脚本、手机 App 等非 Go 程序发送截图即可得到识别结果。
+//
+//	detectd [-addr ADDR] [-grpc-addr ADDR] [-ocr URL] [-ocr-backend NAME] [-skin NAME] [-templates DIR] [-token TOKEN]
+//	    -grpc-addr 上提供 gRPC 服务 Detector（Detect、ReadBoard）与标准的 grpc.health.v1.Health 健康检查，
+//	    定义见 detector.proto，其他语言由它生成客户端；-addr 上提供同样的 HTTP/JSON 接口：POST /v1/detect 识别最后一手，
+//	    POST /v1/board 重建整盘局面，GET /healthz 检查服务，请求与响应按 proto3 的 JSON 映射编码。
+//	    -token（或环境变量 DETECTD_TOKEN）不为空时要求请求带 Authorization: Bearer <token>（gRPC 为同名元数据）。
+package main
+
+//go:generate protoc --go_out=. --go_opt=module=goboardsync --go-grpc_out=. --go-grpc_opt=module=goboardsync detector.proto
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+	"goboardsync/ocr"
+	"goboardsync/vision"
+
+	"gocv.io/x/gocv"
+)
+
+// maxImageSize 单张截图的上限
+const maxImageSize = 32 << 20
+
+// errBadImage 请求中的截图无法解码，HTTP 响应 400，gRPC 返回 INVALID_ARGUMENT
+var errBadImage = errors.New("无法解码截图")
+
+// frame、result、boardState 对应 detector.proto 中的 Frame、Result、BoardState
+type frame struct {
+	Image []byte `json:"image"`
+	Move  int    `json:"move"`
+}
+
+type result struct {
+	Move       int                `json:"move"`
+	Color      string             `json:"color,omitempty"`
+	X          int                `json:"x"`
+	Y          int                `json:"y"`
+	Confidence float64            `json:"confidence"`
+	Coord      string             `json:"coord,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	Candidates []vision.Candidate `json:"candidates,omitempty"`
+}
+
+type boardState struct {
+	Rows  []string `json:"rows"`
+	Black int      `json:"black"`
+	White int      `json:"white"`
+}
+
+func main() {
+	addr := flag.String("addr", ":8093", "HTTP 接口的监听地址")
+	grpcAddr := flag.String("grpc-addr", ":8094", "gRPC 服务的监听地址，为空时不启动 gRPC 服务")
+	ocrEndpoint := flag.String("ocr", vision.NewDetector().OCREndpoint, "OCR 服务地址，为空时不使用 OCR（请求需带 move）")
+	ocrBackend := flag.String("ocr-backend", "multipart", "OCR 服务格式：multipart、paddleocr、umi-ocr、baidu")
+	skin := flag.String("skin", "", "棋盘皮肤（classic/dark/green），为空时自动识别")
+	templates := flag.String("templates", "", "交叉点分类模板目录，为空时使用亮度规则")
+	token := flag.String("token", os.Getenv("DETECTD_TOKEN"), "访问令牌，为空时不检查")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "用法: detectd [-addr ADDR] [-grpc-addr ADDR] [-ocr URL] [-ocr-backend NAME] [-skin NAME] [-templates DIR] [-token TOKEN]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	backend, err := ocr.BackendByName(*ocrBackend)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(2)
+	}
+	opts := []vision.Option{
+		vision.WithSkin(*skin),
+		vision.WithOCREndpoint(*ocrEndpoint),
+		vision.WithOCRBackend(backend, ""),
+	}
+	if *templates != "" {
+		classifier, err := vision.LoadTemplateClassifier(*templates)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer classifier.Close()
+		opts = append(opts, vision.WithClassifier(classifier))
+	}
+
+	srv := &server{detector: vision.NewDetector(opts...), token: *token}
+	errc := make(chan error, 2)
+	go func() { errc <- http.ListenAndServe(*addr, srv.handler()) }()
+	fmt.Printf("🔎 识别服务已启动: HTTP %s\n", *addr)
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		go func() { errc <- srv.grpcServer().Serve(lis) }()
+		fmt.Printf("🔎 识别服务已启动: gRPC %s\n", *grpcAddr)
+	}
+	if err := <-errc; err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// server 识别请求依次处理，Detector 不是并发安全的。HTTP 与 gRPC 接口共用同一个 server
+type server struct {
+	mu       sync.Mutex
+	detector *vision.Detector
+	token    string
+}
+
+// authorized 检查 Authorization 请求头（gRPC 为同名元数据），未设置令牌时总是通过
+func (s *server) authorized(authorization string) bool {
+	token, _ := strings.CutPrefix(authorization, "Bearer ")
+	return s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// run 解码 Frame 中的截图并在锁内调用 fn。截图无法解码时返回 errBadImage，其余错误来自 fn
+func (s *server) run(f frame, fn func(img gocv.Mat, f frame) (any, error)) (any, error) {
+	img, err := gocv.IMDecode(f.Image, gocv.IMReadColor)
+	if err != nil || img.Empty() {
+		return nil, errBadImage
+	}
+	defer img.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(img, f)
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/v1/detect", s.withFrame(s.detect))
+	mux.HandleFunc("/v1/board", s.withFrame(s.readBoard))
+	return mux
+}
+
+// withFrame 检查令牌，解码请求中的 Frame 与截图，把 fn 的返回值编码为 JSON。
+// fn 返回错误时响应 422，错误信息放在 error 字段
+func (s *server) withFrame(fn func(img gocv.Mat, f frame) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r.Header.Get("Authorization")) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var f frame
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxImageSize*2)).Decode(&f); err != nil {
+			writeJSON(w, http.StatusBadRequest, result{Error: fmt.Sprintf("请求格式错误: %v", err)})
+			return
+		}
+		out, err := s.run(f, fn)
+		if errors.Is(err, errBadImage) {
+			writeJSON(w, http.StatusBadRequest, result{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, result{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+func (s *server) detect(img gocv.Mat, f frame) (any, error) {
+	move := f.Move
+	if move == 0 && s.detector.OCREndpoint != "" {
+		move, _ = s.detector.FetchMoveNumberFromOCR(img)
+	}
+
+	r, err := s.detector.DetectLastMoveCoord(img, move)
+	out := result{Move: r.Move, Color: r.Color, X: r.X, Y: r.Y, Confidence: r.Confidence, Candidates: r.Candidates}
+	switch {
+	case err != nil:
+		out.Error = err.Error()
+	case r.X == 0:
+		out.Error = "未找到最后一手"
+	default:
+		x, y := coords.FromPhone(r.X, r.Y)
+		out.Coord = coords.Format(x, y, coords.GTP)
+	}
+	return out, nil
+}
+
+func (s *server) readBoard(img gocv.Mat, f frame) (any, error) {
+	b, err := s.detector.ReadScreenBoard(img)
+	if err != nil {
+		return nil, err
+	}
+
+	return boardState{Rows: boardRows(&b), Black: b.Count(board.Black), White: b.Count(board.White)}, nil
+}
+
+// boardRows 把局面按屏幕方向排成从上到下的 19 行（BoardState.rows）：第一行为 KaTrain 的 y=18，每行从 A 列开始
+func boardRows(b *board.Board) []string {
+	rows := make([]string, 0, coords.Size)
+	for row := range coords.Size {
+		line := make([]byte, coords.Size)
+		for x := range coords.Size {
+			switch b.At(x, coords.Size-1-row) {
+			case board.Black:
+				line[x] = 'B'
+			case board.White:
+				line[x] = 'W'
+			default:
+				line[x] = '.'
+			}
+		}
+		rows = append(rows, string(line))
+	}
+	return rows
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"goboardsync/board"
+	"goboardsync/vision"
+)
+
+func serve(t *testing.T, srv *server, method, path, token, body string) (*httptest.ResponseRecorder, result) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	srv.handler().ServeHTTP(rec, req)
+
+	var out result
+	if strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+			t.Fatalf("%s %s 响应不是 JSON: %v\n%s", method, path, err, rec.Body.String())
+		}
+	}
+	return rec, out
+}
+
+func TestHandlerAuth(t *testing.T) {
+	srv := &server{detector: vision.NewDetector(), token: "secret"}
+	for _, path := range []string{"/v1/detect", "/v1/board"} {
+		for _, token := range []string{"", "wrong"} {
+			if rec, _ := serve(t, srv, http.MethodPost, path, token, `{}`); rec.Code != http.StatusUnauthorized {
+				t.Errorf("%s 令牌 %q: 状态码 = %d, want 401", path, token, rec.Code)
+			}
+		}
+	}
+
+	// 令牌正确时进入请求体检查
+	if rec, _ := serve(t, srv, http.MethodPost, "/v1/detect", "secret", `{`); rec.Code != http.StatusBadRequest {
+		t.Errorf("令牌正确: 状态码 = %d, want 400", rec.Code)
+	}
+	// 健康检查不需要令牌
+	if rec, _ := serve(t, srv, http.MethodGet, "/healthz", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("/healthz 状态码 = %d, want 200", rec.Code)
+	}
+}
+
+func TestHandlerBadRequest(t *testing.T) {
+	srv := &server{detector: vision.NewDetector()}
+	tests := []struct {
+		name, method, body string
+		want               int
+		wantError          string
+	}{
+		{"GET", http.MethodGet, "", http.StatusMethodNotAllowed, ""},
+		{"不是 JSON", http.MethodPost, `not json`, http.StatusBadRequest, "请求格式错误"},
+		{"image 不是 base64", http.MethodPost, `{"image": "@@@"}`, http.StatusBadRequest, "请求格式错误"},
+		{"没有截图", http.MethodPost, `{"move": 37}`, http.StatusBadRequest, "无法解码截图"},
+		{"截图无法解码", http.MethodPost, `{"image": "bm90IGFuIGltYWdl"}`, http.StatusBadRequest, "无法解码截图"},
+	}
+	for _, tt := range tests {
+		for _, path := range []string{"/v1/detect", "/v1/board"} {
+			rec, out := serve(t, srv, tt.method, path, "", tt.body)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s: 状态码 = %d, want %d", tt.name, path, rec.Code, tt.want)
+			}
+			if !strings.Contains(out.Error, tt.wantError) {
+				t.Errorf("%s %s: error = %q, want 包含 %q", tt.name, path, out.Error, tt.wantError)
+			}
+		}
+	}
+}
+
+// TestBoardRows rows 从上到下排列：第一行是 19 线（KaTrain y=18），每行从 A 列开始
+func TestBoardRows(t *testing.T) {
+	var b board.Board
+	b.Set(0, 18, board.Black)  // A19，左上角
+	b.Set(18, 18, board.White) // T19，右上角
+	b.Set(3, 3, board.Black)   // D4
+	b.Set(18, 0, board.White)  // T1，右下角
+
+	rows := boardRows(&b)
+	if len(rows) != 19 {
+		t.Fatalf("len(rows) = %d, want 19", len(rows))
+	}
+	for i, row := range rows {
+		if len(row) != 19 {
+			t.Errorf("rows[%d] 长度 = %d, want 19", i, len(row))
+		}
+	}
+	want := map[int]string{
+		0:  "B.................W",
+		15: "...B...............",
+		18: "..................W",
+	}
+	for i, row := range rows {
+		w, ok := want[i]
+		if !ok {
+			w = strings.Repeat(".", 19)
+		}
+		if row != w {
+			t.Errorf("rows[%d] = %q, want %q", i, row, w)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// fake-katrain 在内存中模拟打过补丁的 KaTrain HTTP 服务，不安装 KaTrain 也能开发与演示完整的同步流程。
+//
+//	fake-katrain [-addr ADDR] [-quiet]
+//	    提供 check-position、make-move、last-move 等接口（与 katrain/katraintest 相同），按规则落子与提子。
+//	    每收到一手打印终端棋盘。标准输入每行一条命令，模拟在 KaTrain 中落子，供测试 KaTrain → 手机方向：
+//	        D4     轮到的一方在 D4 落子（第一手为黑）
+//	        W Q16  白在 Q16 落子
+//	        reset  清空棋盘
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"goboardsync/coords"
+	"goboardsync/katrain"
+	"goboardsync/katrain/katraintest"
+	"goboardsync/overlay"
+	"goboardsync/tui"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "监听地址，与 KATRAIN_URL 对应")
+	quiet := flag.Bool("quiet", false, "落子后不打印棋盘")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "用法: fake-katrain [-addr ADDR] [-quiet]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	srv := katraintest.New()
+	printBoard := func() {
+		if !*quiet {
+			fmt.Print(tui.Board(frame(srv), false))
+		}
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		before := len(srv.Moves())
+		srv.ServeHTTP(w, r)
+		if r.URL.Path == katrain.PathMakeMove && len(srv.Moves()) > before {
+			m := srv.Moves()[before]
+			fmt.Printf("[%s] 📥 第 %d 手 %s %s\n", time.Now().Format("15:04:05"), before+1, m.Player, coords.Format(m.X, m.Y, coords.GTP))
+			printBoard()
+		}
+	})
+	go readCommands(srv, printBoard)
+
+	fmt.Printf("[%s] 🚀 假 KaTrain 运行在 http://%s\n", time.Now().Format("15:04:05"), *addr)
+	if err := http.ListenAndServe(*addr, handler); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// readCommands 从标准输入读取落子命令，标准输入结束时返回（服务继续运行）
+func readCommands(srv *katraintest.Server, printBoard func()) {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := runCommand(srv, line); err != nil {
+			fmt.Printf("[%s] ❌ %v\n", time.Now().Format("15:04:05"), err)
+			continue
+		}
+		printBoard()
+	}
+}
+
+// runCommand 执行一行命令：reset、坐标（轮到的一方落子）或颜色加坐标
+func runCommand(srv *katraintest.Server, line string) error {
+	if strings.EqualFold(line, "reset") {
+		srv.Reset()
+		return nil
+	}
+
+	fields := strings.Fields(line)
+	var player, vertex string
+	switch len(fields) {
+	case 1:
+		player, vertex = nextPlayer(srv), fields[0]
+	case 2:
+		player, vertex = strings.ToUpper(fields[0]), fields[1]
+	default:
+		return errors.New("命令格式: D4、W Q16 或 reset")
+	}
+	x, y, err := coords.Parse(vertex, coords.GTP)
+	if err != nil {
+		return err
+	}
+	return srv.Play(x, y, player)
+}
+
+// nextPlayer 上一手的对方，还没有棋步时为摆上的局面轮到的一方，都没有时为黑
+func nextPlayer(srv *katraintest.Server) string {
+	moves := srv.Moves()
+	if len(moves) == 0 {
+		if p, ok := srv.Position(); ok {
+			return p.Next
+		}
+		return "B"
+	}
+	if moves[len(moves)-1].Player == "B" {
+		return "W"
+	}
+	return "B"
+}
+
+// frame 当前局面，最后一手加括号
+func frame(srv *katraintest.Server) overlay.Frame {
+	f := overlay.Frame{Board: srv.Board()}
+	if moves := srv.Moves(); len(moves) > 0 {
+		m := moves[len(moves)-1]
+		f.Last = &image.Point{X: m.X, Y: m.Y}
+		f.Move = len(moves)
+	}
+	return f
+}
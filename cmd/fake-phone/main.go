@@ -0,0 +1,274 @@
+// fake-phone 模拟装有腾讯围棋的手机，不需要手机与 adb 也能演示完整的同步流程。
+//
+//	fake-phone [-addr ADDR] [-resolution WxH] [-skin NAME] [-noise N] [-interval D] [-quality N] [-token TOKEN]
+//	    用 vision/synth 画出当前局面，按采集端（goboardsync -capture-node）的协议提供截图流与 adb 命令，
+//	    分析端设置 CAPTURE_SOURCE=remote、REMOTE_CAPTURE_URL 指向它即可连接。
+//	    点击棋盘上的交叉点时显示落子指示标，再点击棋盘外（确认按钮）时落子，与 App 的两次点击相同；
+//	    dumpsys、get-state 等设备检查按亮屏、电量充足回答。标准输入每行一条命令，模拟对手在手机上落子：
+//	        D4     轮到的一方在 D4 落子（第一手为黑）
+//	        W Q16  白在 Q16 落子
+//	        dead D4 Q16  数子时把这些棋子标为死子（再次输入取消），用于演示数子交接
+//	        reset  清空棋盘
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"goboardsync/adb"
+	"goboardsync/board"
+	"goboardsync/coords"
+	"goboardsync/remote"
+	"goboardsync/vision"
+	"goboardsync/vision/synth"
+)
+
+func main() {
+	addr := flag.String("addr", ":8091", "监听地址，与分析端的 REMOTE_CAPTURE_URL 对应")
+	resolution := flag.String("resolution", "1200x2670", "截图分辨率（需在 vision.FixedBoardCorners 中配置）")
+	skin := flag.String("skin", "", "棋盘皮肤（classic/dark/green），为空时为 classic")
+	noise := flag.Int("noise", 0, "每个像素随机加减的最大值（0-255）")
+	interval := flag.Duration("interval", 200*time.Millisecond, "截图流中两帧之间的最短间隔")
+	quality := flag.Int("quality", 90, "JPEG 质量（1-100）")
+	token := flag.String("token", os.Getenv("REMOTE_TOKEN"), "访问令牌，与分析端的 REMOTE_TOKEN 相同，为空时不检查")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "用法: fake-phone [-addr ADDR] [-resolution WxH] [-skin NAME] [-noise N] [-interval D] [-quality N] [-token TOKEN]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	p, err := newPhone(*resolution, *skin, *noise, *quality)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+	go p.readCommands()
+
+	srv := remote.NewServer(p.frame, &adb.Client{Runner: p.run}, *interval, *token)
+	fmt.Printf("[%s] 📱 假手机运行在 %s，分析端设置 CAPTURE_SOURCE=remote 与 REMOTE_CAPTURE_URL 连接\n", time.Now().Format("15:04:05"), *addr)
+	if err := srv.ListenAndServe(*addr); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// move 手机上的一手，坐标为 KaTrain 坐标
+type move struct {
+	x, y  int
+	color board.Color
+}
+
+// phone 手机上的对局与界面状态
+type phone struct {
+	style   synth.Style
+	quality int
+	started time.Time
+
+	mu    sync.Mutex
+	game  *board.Game
+	moves []move
+	// pending 点击了交叉点、还没点确认时指示标的位置
+	pending *image.Point
+	// dead 标为死子的棋子
+	dead []image.Point
+}
+
+func newPhone(resolution, skinName string, noise, quality int) (*phone, error) {
+	style, err := synth.StyleFor(resolution)
+	if err != nil {
+		return nil, err
+	}
+	if skinName != "" {
+		skin, ok := vision.SkinByName(skinName)
+		if !ok {
+			return nil, fmt.Errorf("未知的皮肤: %s", skinName)
+		}
+		style.SetSkin(skin)
+	}
+	style.Noise = noise
+	return &phone{style: style, quality: quality, started: time.Now(), game: board.NewGame()}, nil
+}
+
+// frame 画出当前界面并压缩为 JPEG
+func (p *phone) frame(ctx context.Context) ([]byte, error) {
+	p.mu.Lock()
+	scene := synth.Scene{Board: p.game.Board(), MoveNumber: len(p.moves), Indicator: p.pending, Dead: p.dead}
+	if n := len(p.moves); n > 0 {
+		scene.Last = &image.Point{X: p.moves[n-1].x, Y: p.moves[n-1].y}
+	}
+	p.mu.Unlock()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, synth.Render(scene, p.style), &jpeg.Options{Quality: p.quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// run 代替 adb 可执行文件执行分析端发来的命令：处理点击，设备检查按亮屏、未锁屏、电量充足回答，其余命令直接成功
+func (p *phone) run(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := strings.Join(args, " ")
+	var x, y int
+	if _, err := fmt.Sscanf(cmd, "shell input tap %d %d", &x, &y); err == nil {
+		p.tap(image.Pt(x, y))
+		return nil, nil
+	}
+
+	switch cmd {
+	case "get-state":
+		return []byte("device\n"), nil
+	case "shell dumpsys power":
+		return []byte("mWakefulness=Awake\n"), nil
+	case "shell dumpsys window":
+		return []byte("mCurrentFocus=Window{fake u0 com.tencent.tmgp.go/.GameActivity}\nmDreamingLockscreen=false\n"), nil
+	case "shell dumpsys battery":
+		return []byte("  AC powered: true\n  level: 100\n  temperature: 300\n"), nil
+	case "shell cat /proc/uptime":
+		uptime := time.Since(p.started).Seconds() + 1
+		return fmt.Appendf(nil, "%.2f %.2f\n", uptime, uptime), nil
+	}
+	return nil, nil
+}
+
+// tap 点击屏幕上的 pt：点在交叉点上时移动指示标，点在棋盘外且有指示标时确认落子
+func (p *phone) tap(pt image.Point) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if x, y, ok := p.intersection(pt); ok {
+		p.pending = &image.Point{X: x, Y: y}
+		return
+	}
+	if p.pending == nil {
+		return
+	}
+	x, y := p.pending.X, p.pending.Y
+	p.pending = nil
+	if err := p.play(x, y, p.nextColor()); err != nil {
+		fmt.Printf("[%s] ❌ %v\n", time.Now().Format("15:04:05"), err)
+	}
+}
+
+// intersection 返回离 pt 最近的交叉点，距离超过半格（点在棋盘外）时 ok 为 false
+func (p *phone) intersection(pt image.Point) (x, y int, ok bool) {
+	cw, ch := p.style.CellSize()
+	best := -1
+	for i := range coords.Size {
+		for j := range coords.Size {
+			c := p.style.Center(i, j)
+			dx, dy := float64(pt.X-c.X), float64(pt.Y-c.Y)
+			if 2*math.Abs(dx) > cw || 2*math.Abs(dy) > ch {
+				continue
+			}
+			if d := int(dx*dx + dy*dy); best < 0 || d < best {
+				x, y, best = i, j, d
+			}
+		}
+	}
+	return x, y, best >= 0
+}
+
+// play 按规则落子，调用方持有 mu
+func (p *phone) play(x, y int, c board.Color) error {
+	if _, err := p.game.Play(x, y, c); err != nil {
+		return err
+	}
+	p.moves = append(p.moves, move{x: x, y: y, color: c})
+	// 被提掉的棋子不再有死子标记
+	b := p.game.Board()
+	p.dead = slices.DeleteFunc(p.dead, func(pt image.Point) bool { return b.At(pt.X, pt.Y) == board.Empty })
+	fmt.Printf("[%s] 📱 第 %d 手 %s %s\n", time.Now().Format("15:04:05"), len(p.moves), c, coords.Format(x, y, coords.GTP))
+	return nil
+}
+
+// nextColor 上一手的对方，还没有棋步时为黑，调用方持有 mu
+func (p *phone) nextColor() board.Color {
+	if n := len(p.moves); n > 0 {
+		return p.moves[n-1].color.Opponent()
+	}
+	return board.Black
+}
+
+// readCommands 从标准输入读取落子命令，标准输入结束时返回（服务继续运行）
+func (p *phone) readCommands() {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := p.runCommand(line); err != nil {
+			fmt.Printf("[%s] ❌ %v\n", time.Now().Format("15:04:05"), err)
+		}
+	}
+}
+
+// runCommand 执行一行命令：reset、dead 加坐标、坐标（轮到的一方落子）或颜色加坐标
+func (p *phone) runCommand(line string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if strings.EqualFold(line, "reset") {
+		p.game.Reset()
+		p.moves = nil
+		p.pending = nil
+		p.dead = nil
+		return nil
+	}
+
+	fields := strings.Fields(line)
+	if strings.EqualFold(fields[0], "dead") {
+		return p.toggleDead(fields[1:])
+	}
+	var c board.Color
+	var vertex string
+	switch len(fields) {
+	case 1:
+		c, vertex = p.nextColor(), fields[0]
+	case 2:
+		c, vertex = board.ParseColor(fields[0]), fields[1]
+		if c == board.Empty {
+			return fmt.Errorf("无效的颜色: %q", fields[0])
+		}
+	default:
+		return errors.New("命令格式: D4、W Q16、dead D4 或 reset")
+	}
+	x, y, err := coords.Parse(vertex, coords.GTP)
+	if err != nil {
+		return err
+	}
+	return p.play(x, y, c)
+}
+
+// toggleDead 切换 vertices 处棋子的死子标记，调用方持有 mu
+func (p *phone) toggleDead(vertices []string) error {
+	b := p.game.Board()
+	for _, v := range vertices {
+		x, y, err := coords.Parse(v, coords.GTP)
+		if err != nil {
+			return err
+		}
+		if b.At(x, y) == board.Empty {
+			return fmt.Errorf("%s 没有棋子", coords.Format(x, y, coords.GTP))
+		}
+		pt := image.Pt(x, y)
+		if i := slices.Index(p.dead, pt); i >= 0 {
+			p.dead = slices.Delete(p.dead, i, i+1)
+		} else {
+			p.dead = append(p.dead, pt)
+		}
+	}
+	return nil
+}
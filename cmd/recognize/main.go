@@ -0,0 +1,174 @@
+// recognize 识别截图中的最后一手，供外部脚本调用而不必链接 Go 代码。
+//
+//	recognize [-json] [-move N] [-ocr URL] [-ocr-backend NAME] [-ocr-lang LANG] [-move-pattern RE] [-ocr-profile NAME] [-skin NAME] [-templates DIR] [-min-area N] IMAGE...
+//	    逐张识别截图（JPG、PNG、WebP、HEIC 等，见 vision.ReadImage）。-json 时每张图输出一行 JSON（含 vision.Result 与 Debug 信息），否则输出可读文本。
+//	    未指定 -move 时通过 OCR 服务读取手数，-ocr "" 表示不使用 OCR；-ocr-backend 为服务格式（见 ocr.Backends）。
+//	ls images/*.jpg | recognize -json -
+//	    IMAGE 为 - 时从标准输入逐行读取图片路径，每识别一张立即输出，适合流式处理。
+//
+// 任一图片读取或识别失败时退出码为 1，失败原因写在对应的输出行中。
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"goboardsync/coords"
+	"goboardsync/ocr"
+	"goboardsync/vision"
+)
+
+// output 一张图片的识别结果，-json 时编码为一行
+type output struct {
+	File   string         `json:"file"`
+	Result *vision.Result `json:"result,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+func main() {
+	jsonOut := flag.Bool("json", false, "每张图输出一行 JSON")
+	move := flag.Int("move", 0, "已知的手数，为 0 时通过 OCR 读取")
+	ocrEndpoint := flag.String("ocr", vision.NewDetector().OCREndpoint, "OCR 服务地址，为空时不使用 OCR")
+	ocrBackend := flag.String("ocr-backend", "multipart", "OCR 服务格式：multipart、paddleocr、umi-ocr、baidu")
+	ocrLang := flag.String("ocr-lang", "", "OCR 识别语言，含义取决于服务")
+	movePattern := flag.String("move-pattern", "", "提取手数的正则（含捕获分组），为空时使用内置规则")
+	ocrProfile := flag.String("ocr-profile", "", "界面语言（ja、ko、en、zh），按该语言的写法提取手数")
+	skin := flag.String("skin", "", "棋盘皮肤（classic/dark/green），为空时自动识别")
+	templates := flag.String("templates", "", "交叉点分类模板目录，为空时使用亮度规则")
+	minArea := flag.Float64("min-area", 0, "角标轮廓的最小面积（像素），更小的视为噪点")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "用法: recognize [-json] [-move N] [-ocr URL] [-ocr-backend NAME] [-ocr-lang LANG] [-move-pattern RE] [-ocr-profile NAME] [-skin NAME] [-templates DIR] [-min-area N] IMAGE... | -")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	backend, err := ocr.BackendByName(*ocrBackend)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(2)
+	}
+	var patterns []string
+	if *movePattern != "" {
+		patterns = []string{*movePattern}
+	}
+	if *ocrProfile != "" {
+		profile, err := ocr.ProfilePatterns(*ocrProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(2)
+		}
+		patterns = append(patterns, profile...)
+	}
+	movePatterns, err := ocr.CompileMoveNumberPatterns(patterns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(2)
+	}
+
+	opts := []vision.Option{
+		vision.WithSkin(*skin),
+		vision.WithOCREndpoint(*ocrEndpoint),
+		vision.WithOCRBackend(backend, *ocrLang),
+		vision.WithMoveNumberPatterns(movePatterns),
+		vision.WithThreshold(*minArea),
+		vision.WithDebugDetail(*jsonOut),
+	}
+	if *templates != "" {
+		classifier, err := vision.LoadTemplateClassifier(*templates)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer classifier.Close()
+		opts = append(opts, vision.WithClassifier(classifier))
+	}
+
+	r := &recognizer{move: *move, detector: vision.NewDetector(opts...), json: *jsonOut, out: json.NewEncoder(os.Stdout)}
+
+	if flag.NArg() == 1 && flag.Arg(0) == "-" {
+		r.stream(os.Stdin)
+	} else {
+		for _, path := range flag.Args() {
+			r.run(path)
+		}
+	}
+
+	if r.failed {
+		os.Exit(1)
+	}
+}
+
+type recognizer struct {
+	move int
+	// detector.OCREndpoint 为空时不使用 OCR
+	detector *vision.Detector
+	json     bool
+	out      *json.Encoder
+	failed   bool
+}
+
+// stream 从 in 逐行读取图片路径并识别，忽略空行
+func (r *recognizer) stream(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if path := strings.TrimSpace(scanner.Text()); path != "" {
+			r.run(path)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ 读取标准输入失败: %v\n", err)
+		r.failed = true
+	}
+}
+
+// run 识别一张图片并输出结果
+func (r *recognizer) run(path string) {
+	o := r.recognize(path)
+	if o.Error != "" {
+		r.failed = true
+	}
+
+	if r.json {
+		r.out.Encode(o)
+		return
+	}
+
+	if o.Result == nil || o.Result.X == 0 {
+		fmt.Printf("%s: ❌ %s\n", path, o.Error)
+		return
+	}
+	x, y := coords.FromPhone(o.Result.X, o.Result.Y)
+	fmt.Printf("%s: 第 %d 手 %s %s（置信度 %.2f）\n",
+		path, o.Result.Move, o.Result.Color, coords.Format(x, y, coords.Tencent), o.Result.Confidence)
+}
+
+func (r *recognizer) recognize(path string) output {
+	img, err := vision.ReadImage(path)
+	if err != nil {
+		return output{File: path, Error: err.Error()}
+	}
+	defer img.Close()
+
+	move := r.move
+	if move == 0 && r.detector.OCREndpoint != "" {
+		move, _ = r.detector.FetchMoveNumberFromOCR(img)
+	}
+
+	result, err := r.detector.DetectLastMoveCoord(img, move)
+	o := output{File: path, Result: &result}
+	if err != nil {
+		o.Error = err.Error()
+	} else if result.X == 0 {
+		o.Error = "未找到最后一手"
+	}
+	return o
+}
@@ -0,0 +1,138 @@
+// stonetrain 为交叉点分类器准备样本并训练模板。
+//
+//	stonetrain crops -images ./images -out ./crops
+//	    从测试截图导出所有交叉点。最后一手按文件名标注到 black/ 或 white/，
+//	    其余交叉点按亮度规则预分类到 empty/、black/、white/，人工校对后用于训练。
+//	stonetrain train -crops ./crops -out ./templates
+//	    对每类样本逐像素平均，生成 empty.png、black.png、white.png 模板。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"goboardsync/board"
+	"goboardsync/vision"
+
+	"gocv.io/x/gocv"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "crops":
+		fs := flag.NewFlagSet("crops", flag.ExitOnError)
+		imagesDir := fs.String("images", "images", "测试截图目录")
+		outDir := fs.String("out", "crops", "样本输出目录")
+		fs.Parse(os.Args[2:])
+		err = exportCrops(*imagesDir, *outDir)
+	case "train":
+		fs := flag.NewFlagSet("train", flag.ExitOnError)
+		cropsDir := fs.String("crops", "crops", "标注样本目录")
+		outDir := fs.String("out", "templates", "模板输出目录")
+		fs.Parse(os.Args[2:])
+		err = train(*cropsDir, *outDir)
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "用法: stonetrain crops -images DIR -out DIR | stonetrain train -crops DIR -out DIR")
+	os.Exit(2)
+}
+
+func exportCrops(imagesDir, outDir string) error {
+	for _, name := range vision.StoneClasses {
+		if err := os.MkdirAll(filepath.Join(outDir, name), 0755); err != nil {
+			return err
+		}
+	}
+
+	files, err := os.ReadDir(imagesDir)
+	if err != nil {
+		return fmt.Errorf("读取图像目录失败: %v", err)
+	}
+
+	total := 0
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".jpg") {
+			continue
+		}
+
+		_, color, expX, expY, err := vision.ParseSampleFilename(file.Name())
+		if err != nil {
+			fmt.Printf("跳过 %s: %v\n", file.Name(), err)
+			continue
+		}
+
+		img := gocv.IMRead(filepath.Join(imagesDir, file.Name()), gocv.IMReadColor)
+		if img.Empty() {
+			continue
+		}
+
+		corners, ok := vision.FixedBoardCorners[fmt.Sprintf("%dx%d", img.Cols(), img.Rows())]
+		if !ok {
+			img.Close()
+			fmt.Printf("跳过 %s: 不支持的分辨率\n", file.Name())
+			continue
+		}
+
+		warped, err := vision.WarpBoard(img, corners)
+		img.Close()
+		if err != nil {
+			return err
+		}
+
+		base := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		for gx := 0; gx < 19; gx++ {
+			for gy := 0; gy < 19; gy++ {
+				label, _ := vision.ClassifyAt(warped, gx, gy, vision.BrightnessClassifier{})
+				if gx+1 == expX && gy+1 == expY {
+					label = board.ParseColor(color)
+				}
+
+				cell := warped.Region(vision.CellRect(warped, gx, gy))
+				path := filepath.Join(outDir, vision.StoneClasses[label], fmt.Sprintf("%s_%02d_%02d.png", base, gx+1, gy+1))
+				gocv.IMWrite(path, cell)
+				cell.Close()
+				total++
+			}
+		}
+		warped.Close()
+	}
+
+	fmt.Printf("✅ 已导出 %d 个样本到 %s，请人工校对分类后再训练\n", total, outDir)
+	return nil
+}
+
+func train(cropsDir, outDir string) error {
+	templates, err := vision.TrainTemplates(cropsDir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, tmpl := range templates {
+			tmpl.Close()
+		}
+	}()
+
+	if err := vision.SaveTemplates(templates, outDir); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ 模板已保存到 %s\n", outDir)
+	return nil
+}
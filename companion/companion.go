@@ -0,0 +1,188 @@
+// Package companion 连接手机上的伴侣 App（无障碍服务），通过它点击屏幕、读取对弈 App 的界面结构
+// （手数、弹出的对话框），不依赖 input tap 与 OCR。
+//
+// 伴侣 App 在手机上监听名为 SocketName 的本地抽象套接字，电脑端用 adb forward 转发到本机端口后连接。
+// 协议为逐行的 JSON：每行一个 Request，伴侣 App 按顺序每行回复一个 Response，id 与请求相同。
+//
+//	→ {"id":1,"cmd":"ping"}
+//	← {"id":1,"ok":true,"version":"1.0"}
+//	→ {"id":2,"cmd":"tap","x":600,"y":2150}
+//	← {"id":2,"ok":true}
+//	→ {"id":3,"cmd":"tree"}
+//	← {"id":3,"ok":true,"tree":{"class":"android.widget.FrameLayout","children":[...]}}
+//
+// 出错时 ok 为 false，error 为原因（如无障碍服务未开启）。tap 的坐标与 input tap 相同，为截图中的像素位置；
+// tree 返回当前活动窗口的界面结构，只包含可见的节点。
+package companion
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"goboardsync/adb"
+	"goboardsync/uistate"
+)
+
+// SocketName 伴侣 App 监听的本地抽象套接字名
+const SocketName = "goboardsync"
+
+// DefaultPort adb forward 转发到的本机端口
+const DefaultPort = 27190
+
+// Request 发给伴侣 App 的一条命令
+type Request struct {
+	ID  int    `json:"id"`
+	Cmd string `json:"cmd"`
+	X   int    `json:"x,omitempty"`
+	Y   int    `json:"y,omitempty"`
+}
+
+// Response 伴侣 App 对一条命令的回复
+type Response struct {
+	ID      int           `json:"id"`
+	OK      bool          `json:"ok"`
+	Error   string        `json:"error,omitempty"`
+	Version string        `json:"version,omitempty"`
+	Tree    *uistate.Node `json:"tree,omitempty"`
+}
+
+// Client 伴侣 App 客户端，可在多个协程中使用；连接断开后下一条命令自动重连
+type Client struct {
+	Addr    string        // 本机转发端口的地址，如 127.0.0.1:27190
+	Timeout time.Duration // 每条命令的超时
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	nextID int
+}
+
+func NewClient(addr string) *Client {
+	return &Client{Addr: addr, Timeout: 5 * time.Second}
+}
+
+// Dial 用 adb forward 把伴侣 App 的套接字转发到本机 port 端口并确认伴侣 App 在运行，返回其版本
+func Dial(ctx context.Context, phone *adb.Client, port int) (*Client, string, error) {
+	if err := phone.Run(ctx, "forward", fmt.Sprintf("tcp:%d", port), "localabstract:"+SocketName); err != nil {
+		return nil, "", fmt.Errorf("转发伴侣 App 端口失败: %v", err)
+	}
+	c := NewClient(fmt.Sprintf("127.0.0.1:%d", port))
+	version, err := c.Ping(ctx)
+	if err != nil {
+		c.Close()
+		return nil, "", err
+	}
+	return c, version, nil
+}
+
+// Ping 确认伴侣 App 在运行且无障碍服务已开启，返回其版本
+func (c *Client) Ping(ctx context.Context) (string, error) {
+	resp, err := c.call(ctx, Request{Cmd: "ping"})
+	if err != nil {
+		return "", err
+	}
+	return resp.Version, nil
+}
+
+// Tap 通过无障碍服务在屏幕坐标 (x, y) 处点击一次，可用作 adb.Client.Tapper
+func (c *Client) Tap(ctx context.Context, x, y int) error {
+	_, err := c.call(ctx, Request{Cmd: "tap", X: x, Y: y})
+	return err
+}
+
+// Tree 读取当前活动窗口的界面结构
+func (c *Client) Tree(ctx context.Context) (*uistate.Node, error) {
+	resp, err := c.call(ctx, Request{Cmd: "tree"})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Tree == nil {
+		return nil, fmt.Errorf("伴侣 App 没有返回界面结构")
+	}
+	return resp.Tree, nil
+}
+
+// Close 断开连接
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reset()
+}
+
+func (c *Client) reset() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.reader = nil, nil
+	return err
+}
+
+// call 发送一条命令并等待回复，ctx 取消时立即中断。网络出错时断开连接，下一条命令重新连接
+func (c *Client) call(ctx context.Context, req Request) (*Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		dialer := net.Dialer{Timeout: c.Timeout}
+		conn, err := dialer.DialContext(ctx, "tcp", c.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("连接伴侣 App 失败: %v", err)
+		}
+		c.conn, c.reader = conn, bufio.NewReader(conn)
+	}
+	c.nextID++
+	req.ID = c.nextID
+
+	if err := c.conn.SetDeadline(time.Now().Add(c.Timeout)); err != nil {
+		c.reset()
+		return nil, fmt.Errorf("伴侣 App %s: %v", req.Cmd, err)
+	}
+	conn := c.conn
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	resp, err := c.roundTrip(req)
+	if !stop() {
+		// ctx 已取消，连接上留下了过去的截止时间，断开后下一条命令重新连接
+		c.reset()
+		if err != nil {
+			return nil, fmt.Errorf("伴侣 App %s: %v", req.Cmd, ctx.Err())
+		}
+	}
+	if err != nil {
+		c.reset()
+		return nil, fmt.Errorf("伴侣 App %s: %v", req.Cmd, err)
+	}
+	if !resp.OK {
+		return nil, fmt.Errorf("伴侣 App %s 失败: %s", req.Cmd, resp.Error)
+	}
+	return resp, nil
+}
+
+func (c *Client) roundTrip(req Request) (*Response, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.conn.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+	// 跳过此前超时的命令迟到的回复
+	for {
+		line, err := c.reader.ReadBytes('\n')
+		if err != nil {
+			return nil, err
+		}
+		var resp Response
+		if err := json.Unmarshal(line, &resp); err != nil {
+			return nil, fmt.Errorf("无法解析回复: %v", err)
+		}
+		if resp.ID == req.ID {
+			return &resp, nil
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package companion
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"goboardsync/adb"
+	"goboardsync/uistate"
+)
+
+// fakeCompanion 模拟伴侣 App：记录点击，tree 返回 tree
+type fakeCompanion struct {
+	ln   net.Listener
+	tree *uistate.Node
+	taps chan [2]int
+}
+
+func newFakeCompanion(t *testing.T, tree *uistate.Node) *fakeCompanion {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeCompanion{ln: ln, tree: tree, taps: make(chan [2]int, 10)}
+	t.Cleanup(func() { ln.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeCompanion) serve() {
+	for {
+		conn, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			scanner := bufio.NewScanner(conn)
+			for scanner.Scan() {
+				var req Request
+				if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+					return
+				}
+				resp := Response{ID: req.ID, OK: true}
+				switch req.Cmd {
+				case "ping":
+					resp.Version = "1.0"
+				case "tap":
+					f.taps <- [2]int{req.X, req.Y}
+				case "tree":
+					resp.Tree = f.tree
+				default:
+					resp.OK, resp.Error = false, "未知命令"
+				}
+				data, _ := json.Marshal(resp)
+				conn.Write(append(data, '\n'))
+			}
+		}()
+	}
+}
+
+func TestClient(t *testing.T) {
+	tree := &uistate.Node{Class: "android.widget.FrameLayout", Children: []*uistate.Node{
+		{Class: "android.widget.TextView", Text: "第 57 手", ID: "com.example:id/move_count"},
+	}}
+	f := newFakeCompanion(t, tree)
+
+	port := f.ln.Addr().(*net.TCPAddr).Port
+	var commands []string
+	phone := &adb.Client{Runner: func(ctx context.Context, args ...string) ([]byte, error) {
+		commands = append(commands, strings.Join(args, " "))
+		return nil, nil
+	}}
+	ctx := context.Background()
+	c, version, err := Dial(ctx, phone, port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if version != "1.0" {
+		t.Errorf("version = %q, want 1.0", version)
+	}
+	if want := fmt.Sprintf("forward tcp:%d localabstract:goboardsync", port); len(commands) != 1 || commands[0] != want {
+		t.Errorf("adb commands = %q, want %q", commands, want)
+	}
+
+	if err := c.Tap(ctx, 600, 2150); err != nil {
+		t.Fatal(err)
+	}
+	if tap := <-f.taps; tap != [2]int{600, 2150} {
+		t.Errorf("tap = %v, want [600 2150]", tap)
+	}
+	got, err := c.Tree(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Texts(), []string{"第 57 手"}) {
+		t.Errorf("Texts() = %q", got.Texts())
+	}
+	if _, err := c.call(ctx, Request{Cmd: "unknown"}); err == nil || !strings.Contains(err.Error(), "未知命令") {
+		t.Errorf("未知命令 error = %v", err)
+	}
+
+	// 连接断开后下一条命令重新连接
+	c.conn.Close()
+	if _, err := c.Ping(ctx); err == nil {
+		t.Error("连接已关闭时 Ping() 应返回错误")
+	}
+	if _, err := c.Ping(ctx); err != nil {
+		t.Errorf("重连后 Ping() error = %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+// Package config 从环境变量读取运行配置，容器部署时不必修改源码重新编译。
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Prefix 环境变量名前缀，如 KATRAIN_URL 对应 GOBOARDSYNC_KATRAIN_URL
+const Prefix = "GOBOARDSYNC_"
+
+// ApplyEnv 用环境变量覆盖 fields 中的配置项。fields 的键为去掉 Prefix 的变量名，
+// 值为指向配置的指针，支持 *string、*int、*float64、*bool 与 *time.Duration。
+// 返回被覆盖的变量名（按字母排序），任一变量格式错误时返回错误且不修改该项
+func ApplyEnv(fields map[string]any) ([]string, error) {
+	return apply(fields, os.LookupEnv)
+}
+
+func apply(fields map[string]any, lookup func(string) (string, bool)) ([]string, error) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var applied []string
+	for _, name := range names {
+		value, ok := lookup(Prefix + name)
+		if !ok {
+			continue
+		}
+		if err := set(fields[name], strings.TrimSpace(value)); err != nil {
+			return applied, fmt.Errorf("环境变量 %s%s 格式错误: %v", Prefix, name, err)
+		}
+		applied = append(applied, name)
+	}
+	return applied, nil
+}
+
+// Dump 按 KEY=value 格式（与 LoadFile 相同，键名按字母排序）输出 fields 中各项的当前值
+func Dump(fields map[string]any) []string {
+	lines := make([]string, 0, len(fields))
+	for name, dst := range fields {
+		lines = append(lines, fmt.Sprintf("%s=%v", name, reflect.ValueOf(dst).Elem()))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// set 把字符串解析后写入 dst
+func set(dst any, value string) error {
+	switch p := dst.(type) {
+	case *string:
+		*p = value
+	case *int:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*p = v
+	case *float64:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		*p = v
+	case *bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*p = v
+	case *time.Duration:
+		v, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*p = v
+	default:
+		return fmt.Errorf("不支持的配置类型 %T", dst)
+	}
+	return nil
+}
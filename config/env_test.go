@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApply(t *testing.T) {
+	var (
+		url      = "http://localhost:8080"
+		width    = 1200
+		scrcpy   = true
+		interval = 100 * time.Millisecond
+		ratio    = 0.5
+	)
+	fields := map[string]any{
+		"KATRAIN_URL": &url,
+		"TARGET_W":    &width,
+		"SCRCPY":      &scrcpy,
+		"INTERVAL":    &interval,
+		"RATIO":       &ratio,
+	}
+	env := map[string]string{
+		"GOBOARDSYNC_KATRAIN_URL": "http://katrain:8080",
+		"GOBOARDSYNC_TARGET_W":    " 1080 ",
+		"GOBOARDSYNC_SCRCPY":      "false",
+		"GOBOARDSYNC_INTERVAL":    "250ms",
+		"KATRAIN_URL":             "http://ignored",
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	applied, err := apply(fields, lookup)
+	if err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if want := []string{"INTERVAL", "KATRAIN_URL", "SCRCPY", "TARGET_W"}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied = %v, want %v", applied, want)
+	}
+	if url != "http://katrain:8080" || width != 1080 || scrcpy || interval != 250*time.Millisecond || ratio != 0.5 {
+		t.Errorf("配置 = %s %d %v %v %v, 与环境变量不符", url, width, scrcpy, interval, ratio)
+	}
+}
+
+func TestApplyInvalid(t *testing.T) {
+	tests := []struct {
+		dst   any
+		value string
+	}{
+		{new(int), "abc"},
+		{new(bool), "maybe"},
+		{new(time.Duration), "10"},
+		{new(float64), "x"},
+		{new(uint8), "1"},
+	}
+
+	for _, tt := range tests {
+		lookup := func(string) (string, bool) { return tt.value, true }
+		if _, err := apply(map[string]any{"X": tt.dst}, lookup); err == nil {
+			t.Errorf("apply(%T, %q) error = nil, want error", tt.dst, tt.value)
+		}
+	}
+}
+
+func TestDump(t *testing.T) {
+	var (
+		url      = "http://localhost:8080"
+		width    = 1200
+		interval = 250 * time.Millisecond
+	)
+	got := Dump(map[string]any{"KATRAIN_URL": &url, "TARGET_W": &width, "INTERVAL": &interval})
+	want := []string{"INTERVAL=250ms", "KATRAIN_URL=http://localhost:8080", "TARGET_W=1200"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Dump() = %v, want %v", got, want)
+	}
+}
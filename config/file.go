@@ -0,0 +1,114 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Change 一个配置项的变化
+type Change struct {
+	Name string
+	Old  any
+	New  any
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %v → %v", c.Name, c.Old, c.New)
+}
+
+// LoadFile 读取 KEY=value 格式的配置文件（# 开头为注释，键名与环境变量去掉 Prefix 后相同），
+// 写入 fields 并返回值有变化的项。文件中有未知键或任一值格式错误时返回错误且不修改任何配置项
+func LoadFile(path string, fields map[string]any) ([]Change, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	defer f.Close()
+
+	staged := make(map[string]reflect.Value)
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s 第 %d 行缺少 '='", path, lineNo)
+		}
+		name = strings.TrimPrefix(strings.TrimSpace(name), Prefix)
+		dst, known := fields[name]
+		if !known {
+			return nil, fmt.Errorf("%s 第 %d 行: 未知配置项 %s", path, lineNo, name)
+		}
+
+		v := reflect.New(reflect.TypeOf(dst).Elem())
+		if err := set(v.Interface(), strings.TrimSpace(value)); err != nil {
+			return nil, fmt.Errorf("%s 第 %d 行 %s 格式错误: %v", path, lineNo, name, err)
+		}
+		staged[name] = v.Elem()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	names := make([]string, 0, len(staged))
+	for name := range staged {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var changes []Change
+	for _, name := range names {
+		current := reflect.ValueOf(fields[name]).Elem()
+		if current.Interface() == staged[name].Interface() {
+			continue
+		}
+		changes = append(changes, Change{Name: name, Old: current.Interface(), New: staged[name].Interface()})
+		current.Set(staged[name])
+	}
+	return changes, nil
+}
+
+// Watch 每隔 interval 检查 path 的修改时间与大小，有变化时调用 onChange，直到 ctx 取消。
+// 用轮询而不是文件系统通知，编辑器先写临时文件再改名的保存方式也能检测到
+func Watch(ctx context.Context, path string, interval time.Duration, onChange func()) {
+	last, _ := stat(path)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := stat(path)
+		if err != nil || current == last {
+			continue
+		}
+		last = current
+		onChange()
+	}
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func stat(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{info.ModTime(), info.Size()}, nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadFile(t *testing.T) {
+	var (
+		interval = 100 * time.Millisecond
+		gap      = 60.0
+		confirmX = 600
+	)
+	fields := map[string]any{
+		"INTERVAL":  &interval,
+		"BOARD_GAP": &gap,
+		"CONFIRM_X": &confirmX,
+	}
+
+	path := filepath.Join(t.TempDir(), "goboardsync.conf")
+	os.WriteFile(path, []byte("# 调整轮询与棋盘间距\nINTERVAL = 200ms\nGOBOARDSYNC_BOARD_GAP=61.5\nCONFIRM_X=600\n"), 0o644)
+
+	changes, err := LoadFile(path, fields)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if len(changes) != 2 || changes[0].Name != "BOARD_GAP" || changes[1].String() != "INTERVAL: 100ms → 200ms" {
+		t.Errorf("changes = %v, want BOARD_GAP 与 INTERVAL", changes)
+	}
+	if interval != 200*time.Millisecond || gap != 61.5 || confirmX != 600 {
+		t.Errorf("配置 = %v %v %v, 与文件不符", interval, gap, confirmX)
+	}
+}
+
+func TestLoadFileRejectsWholeFile(t *testing.T) {
+	tests := []string{
+		"INTERVAL=300ms\nBOARD_GAP=abc\n",
+		"INTERVAL=300ms\nUNKNOWN=1\n",
+		"INTERVAL=300ms\nBOARD_GAP\n",
+	}
+
+	for _, content := range tests {
+		interval, gap := 100*time.Millisecond, 60.0
+		fields := map[string]any{"INTERVAL": &interval, "BOARD_GAP": &gap}
+
+		path := filepath.Join(t.TempDir(), "goboardsync.conf")
+		os.WriteFile(path, []byte(content), 0o644)
+
+		if _, err := LoadFile(path, fields); err == nil {
+			t.Errorf("LoadFile(%q) error = nil, want error", content)
+		}
+		if interval != 100*time.Millisecond || gap != 60 {
+			t.Errorf("LoadFile(%q) 出错后配置被修改: %v %v", content, interval, gap)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "goboardsync.conf")
+	os.WriteFile(path, []byte("INTERVAL=100ms\n"), 0o644)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+	go Watch(ctx, path, 10*time.Millisecond, func() { changed <- struct{}{} })
+
+	time.Sleep(30 * time.Millisecond)
+	os.WriteFile(path, []byte("INTERVAL=200ms\n"), 0o644)
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("修改配置文件后未触发 onChange")
+	}
+}
@@ -0,0 +1,111 @@
+// Package coords 统一处理棋盘坐标的换算与显示。
+//
+// 程序内部统一使用 KaTrain 坐标：x 为 0-18（从左到右），y 为 0-18（从下到上）。
+// 手机端（vision.Result）使用 1-19 的坐标，行号从上往下数。
+package coords
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Size 棋盘路数
+const Size = 19
+
+// Policy 坐标显示规则
+type Policy struct {
+	// SkipI 列字母是否跳过 I（GTP/KaTrain 跳过，腾讯围棋不跳过）
+	SkipI bool
+	// TopOrigin 行号是否从上往下数（腾讯围棋从上往下，GTP 从下往上）
+	TopOrigin bool
+}
+
+var (
+	// GTP GTP/KaTrain 的显示规则：A-T 跳过 I，1 线在最下方
+	GTP = Policy{SkipI: true}
+	// Tencent 腾讯围棋的显示规则：A-S 不跳过 I，1 线在最上方
+	Tencent = Policy{SkipI: false, TopOrigin: true}
+)
+
+// Valid 判断 KaTrain 坐标是否在棋盘内
+func Valid(x, y int) bool {
+	return x >= 0 && x < Size && y >= 0 && y < Size
+}
+
+// ColumnLetter 返回第 x 列（0 起）的字母
+func ColumnLetter(x int, skipI bool) string {
+	if skipI && x >= 'I'-'A' {
+		x++
+	}
+	return string(rune('A' + x))
+}
+
+// Format 把 KaTrain 坐标格式化为 "D16" 这样的字符串
+func Format(x, y int, p Policy) string {
+	if !Valid(x, y) {
+		return fmt.Sprintf("(%d,%d)", x, y)
+	}
+	row := y + 1
+	if p.TopOrigin {
+		row = Size - y
+	}
+	return ColumnLetter(x, p.SkipI) + strconv.Itoa(row)
+}
+
+// Parse 解析 "D16" 这样的坐标字符串，返回 KaTrain 坐标
+func Parse(s string, p Policy) (int, int, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return 0, 0, fmt.Errorf("坐标格式不正确: %q", s)
+	}
+
+	letter := s[0]
+	if letter < 'A' || letter > 'Z' || (p.SkipI && letter == 'I') {
+		return 0, 0, fmt.Errorf("列字母不正确: %q", s)
+	}
+	x := int(letter - 'A')
+	if p.SkipI && letter > 'I' {
+		x--
+	}
+
+	row, err := strconv.Atoi(s[1:])
+	if err != nil {
+		return 0, 0, fmt.Errorf("行号解析失败: %q", s)
+	}
+	y := row - 1
+	if p.TopOrigin {
+		y = Size - row
+	}
+
+	if !Valid(x, y) {
+		return 0, 0, fmt.Errorf("坐标超出棋盘: %q", s)
+	}
+	return x, y, nil
+}
+
+// FromPhone 把手机识别结果的坐标（1-19，行号从上往下）转换为 KaTrain 坐标
+func FromPhone(x, y int) (int, int) {
+	return x - 1, Size - y
+}
+
+// ToPhone 把 KaTrain 坐标转换为手机识别结果的坐标
+func ToPhone(x, y int) (int, int) {
+	return x + 1, Size - y
+}
+
+// FormatPhone 按腾讯围棋的显示规则格式化手机坐标（1-19，行号从上往下），如 P4
+func FormatPhone(x, y int) string {
+	kx, ky := FromPhone(x, y)
+	return Format(kx, ky, Tencent)
+}
+
+// ParsePhone 是 FormatPhone 的逆操作，返回手机坐标
+func ParsePhone(s string) (int, int, error) {
+	x, y, err := Parse(s, Tencent)
+	if err != nil {
+		return 0, 0, err
+	}
+	px, py := ToPhone(x, y)
+	return px, py, nil
+}
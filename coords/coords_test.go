@@ -0,0 +1,111 @@
+package coords
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		x        int
+		y        int
+		policy   Policy
+		expected string
+	}{
+		{"GTP 左下角", 0, 0, GTP, "A1"},
+		{"GTP 右上角", 18, 18, GTP, "T19"},
+		{"GTP 跳过 I", 8, 3, GTP, "J4"},
+		{"GTP 星位", 3, 15, GTP, "D16"},
+		{"腾讯 左上角", 0, 18, Tencent, "A1"},
+		{"腾讯 右下角", 18, 0, Tencent, "S19"},
+		{"腾讯 保留 I", 8, 4, Tencent, "I15"},
+		{"腾讯 星位", 15, 15, Tencent, "P4"},
+		{"超出棋盘", 19, 0, GTP, "(19,0)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Format(tt.x, tt.y, tt.policy)
+			if got != tt.expected {
+				t.Errorf("Format(%d, %d) = %s, want %s", tt.x, tt.y, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		policy Policy
+	}{
+		{"空字符串", "", GTP},
+		{"GTP 不允许 I", "I5", GTP},
+		{"腾讯不允许 T", "T5", Tencent},
+		{"行号为 0", "A0", GTP},
+		{"行号超出", "A20", Tencent},
+		{"行号不是数字", "Ax", GTP},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Parse(tt.input, tt.policy); err == nil {
+				t.Errorf("Parse(%q) expected error, got nil", tt.input)
+			}
+		})
+	}
+}
+
+func TestRoundTripAllPoints(t *testing.T) {
+	for _, p := range []Policy{GTP, Tencent, {SkipI: true, TopOrigin: true}, {}} {
+		seen := make(map[string]bool)
+		for x := 0; x < Size; x++ {
+			for y := 0; y < Size; y++ {
+				s := Format(x, y, p)
+				if seen[s] {
+					t.Errorf("policy %+v: Format(%d, %d) = %s 重复", p, x, y, s)
+				}
+				seen[s] = true
+
+				gotX, gotY, err := Parse(s, p)
+				if err != nil {
+					t.Errorf("policy %+v: Parse(%q) unexpected error: %v", p, s, err)
+					continue
+				}
+				if gotX != x || gotY != y {
+					t.Errorf("policy %+v: Parse(%q) = (%d, %d), want (%d, %d)", p, s, gotX, gotY, x, y)
+				}
+			}
+		}
+		if len(seen) != Size*Size {
+			t.Errorf("policy %+v: 共 %d 个坐标, want %d", p, len(seen), Size*Size)
+		}
+	}
+}
+
+func TestPhoneConversion(t *testing.T) {
+	for px := 1; px <= Size; px++ {
+		for py := 1; py <= Size; py++ {
+			kx, ky := FromPhone(px, py)
+			if !Valid(kx, ky) {
+				t.Errorf("FromPhone(%d, %d) = (%d, %d) 超出棋盘", px, py, kx, ky)
+			}
+			if gx, gy := ToPhone(kx, ky); gx != px || gy != py {
+				t.Errorf("ToPhone(FromPhone(%d, %d)) = (%d, %d)", px, py, gx, gy)
+			}
+			// 手机坐标的行号与腾讯显示规则一致
+			want := ColumnLetter(px-1, false)
+			if got := Format(kx, ky, Tencent); got != want+strconv.Itoa(py) {
+				t.Errorf("Format(FromPhone(%d, %d), Tencent) = %s", px, py, got)
+			}
+			got := FormatPhone(px, py)
+			if got != want+strconv.Itoa(py) {
+				t.Errorf("FormatPhone(%d, %d) = %s", px, py, got)
+			}
+			if x, y, err := ParsePhone(got); err != nil || x != px || y != py {
+				t.Errorf("ParsePhone(%q) = (%d, %d), %v", got, x, y, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package coords
+
+import "fmt"
+
+// Orientation 手机上显示的棋盘相对于标准方向（黑方视角，A1 在左下角）顺时针旋转的角度。
+// 腾讯围棋执白时可能把棋盘转 180° 显示，摄像头也可能从棋盘的任意一边拍摄
+type Orientation int
+
+const (
+	Rotate0 Orientation = iota
+	Rotate90
+	Rotate180
+	Rotate270
+)
+
+// OrientationFromDegrees 把顺时针角度（0、90、180、270，可为负数或大于 360）转换为 Orientation
+func OrientationFromDegrees(deg int) (Orientation, error) {
+	if deg%90 != 0 {
+		return Rotate0, fmt.Errorf("棋盘旋转角度必须是 90 的倍数: %d", deg)
+	}
+	return Orientation(((deg/90)%4 + 4) % 4), nil
+}
+
+// Degrees 返回顺时针旋转的角度
+func (o Orientation) Degrees() int {
+	return int(o) * 90
+}
+
+func (o Orientation) String() string {
+	return fmt.Sprintf("%d°", o.Degrees())
+}
+
+// ToScreen 把标准方向的 KaTrain 坐标转换为旋转后棋盘上显示的位置（仍按 KaTrain 坐标系表示）
+func (o Orientation) ToScreen(x, y int) (int, int) {
+	switch o {
+	case Rotate90:
+		return y, Size - 1 - x
+	case Rotate180:
+		return Size - 1 - x, Size - 1 - y
+	case Rotate270:
+		return Size - 1 - y, x
+	}
+	return x, y
+}
+
+// FromScreen 是 ToScreen 的逆变换：把屏幕上看到的位置转换回标准方向的 KaTrain 坐标
+func (o Orientation) FromScreen(x, y int) (int, int) {
+	return ((4 - o) % 4).ToScreen(x, y)
+}
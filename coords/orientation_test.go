@@ -0,0 +1,61 @@
+package coords
+
+import "testing"
+
+func TestOrientationToScreen(t *testing.T) {
+	// 标准方向的 D4（左下角星位附近）在各方向下显示的位置
+	tests := []struct {
+		o    Orientation
+		want string
+	}{
+		{Rotate0, "D4"},
+		{Rotate90, "D16"},
+		{Rotate180, "Q16"},
+		{Rotate270, "Q4"},
+	}
+
+	x, y, _ := Parse("D4", GTP)
+	for _, tt := range tests {
+		sx, sy := tt.o.ToScreen(x, y)
+		if got := Format(sx, sy, GTP); got != tt.want {
+			t.Errorf("%v.ToScreen(D4) = %s, want %s", tt.o, got, tt.want)
+		}
+	}
+}
+
+func TestOrientationRoundTrip(t *testing.T) {
+	for o := Rotate0; o <= Rotate270; o++ {
+		for x := 0; x < Size; x++ {
+			for y := 0; y < Size; y++ {
+				sx, sy := o.ToScreen(x, y)
+				if !Valid(sx, sy) {
+					t.Fatalf("%v.ToScreen(%d, %d) = (%d, %d) 超出棋盘", o, x, y, sx, sy)
+				}
+				if bx, by := o.FromScreen(sx, sy); bx != x || by != y {
+					t.Fatalf("%v.FromScreen(ToScreen(%d, %d)) = (%d, %d)", o, x, y, bx, by)
+				}
+			}
+		}
+	}
+}
+
+func TestOrientationFromDegrees(t *testing.T) {
+	tests := []struct {
+		deg     int
+		want    Orientation
+		wantErr bool
+	}{
+		{0, Rotate0, false},
+		{180, Rotate180, false},
+		{-90, Rotate270, false},
+		{450, Rotate90, false},
+		{45, Rotate0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := OrientationFromDegrees(tt.deg)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("OrientationFromDegrees(%d) = %v, %v, want %v (err %v)", tt.deg, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,395 @@
+// Package dashboard 通过 HTTP 展示同步状态，供浏览器或脚本查看。
+package dashboard
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Clock 一方的剩余时间
+type Clock struct {
+	RemainingSeconds int  `json:"remaining_seconds"`
+	Periods          int  `json:"periods"`
+	ByoYomi          bool `json:"byo_yomi"`
+}
+
+// Status 看板展示的同步状态
+type Status struct {
+	UpdatedAt    time.Time `json:"updated_at"`
+	PhoneMove    int       `json:"phone_move"`
+	PhoneCoord   string    `json:"phone_coord"`
+	KatrainMove  int       `json:"katrain_move"`
+	KatrainCoord string    `json:"katrain_coord"`
+	BlackClock   *Clock    `json:"black_clock,omitempty"`
+	WhiteClock   *Clock    `json:"white_clock,omitempty"`
+	Paused       bool      `json:"paused"`
+	Reviewing    bool      `json:"reviewing"`
+	Table        string    `json:"table,omitempty"`
+	Device       string    `json:"device,omitempty"`
+	Throttle     string    `json:"throttle,omitempty"`
+	Suggestion   string    `json:"suggestion,omitempty"`
+	Score        *Score    `json:"score,omitempty"`
+	Timing       *Timing   `json:"timing,omitempty"`
+	Scrcpy       *Process  `json:"scrcpy,omitempty"`
+	LastError    *Failure  `json:"last_error,omitempty"`
+	// Phase 对局阶段（布局/中盘/官子）
+	Phase string `json:"phase,omitempty"`
+	// Blunder 最近一次胜率损失超过阈值的一手
+	Blunder string `json:"blunder,omitempty"`
+	// Opening 当前局面在定式书中的名称与后续下法，布局阶段之后为空
+	Opening []Opening `json:"opening,omitempty"`
+	// Activity 手机的前台界面，Dialog 为 App 弹出的对话框内容（见 UIDumpInterval、Companion）
+	Activity string `json:"activity,omitempty"`
+	Dialog   string `json:"dialog,omitempty"`
+	// LoopRestarts 各同步循环（手机 → KaTrain、KaTrain → 手机）崩溃后自动重启的次数，没有重启过时为空
+	LoopRestarts map[string]int `json:"loop_restarts,omitempty"`
+	// Alert 需要立即处理的问题（如识别持续偏离），页面上以横幅显示，问题消失后为空
+	Alert string `json:"alert,omitempty"`
+}
+
+// Failure 最近一次出错的环节、原因与时间
+type Failure struct {
+	Stage   string    `json:"stage"`
+	Message string    `json:"message"`
+	At      time.Time `json:"at"`
+}
+
+// Opening 定式书中查到的局面：Corner 为空时整盘是书中的布局，否则为该角的定式；
+// Next 为书中的后续下法，如 "B F3 小飞守角"
+type Opening struct {
+	Corner string   `json:"corner,omitempty"`
+	Name   string   `json:"name"`
+	Next   []string `json:"next,omitempty"`
+}
+
+// Timing 按同步到每手棋的时刻估算的双方累计用时与最近一手的用时（秒）。真正的计时在手机 App 上，
+// 这里包含截图识别与同步的延迟
+type Timing struct {
+	BlackSeconds    int       `json:"black_seconds"`
+	WhiteSeconds    int       `json:"white_seconds"`
+	LastMoveSeconds int       `json:"last_move_seconds"`
+	LastMoveAt      time.Time `json:"last_move_at"`
+}
+
+// Score 根据识别出的局面做的粗略形势判断（数子法，不判断死活），Lead 为黑方领先的子数，负数表示白方领先
+type Score struct {
+	Black int     `json:"black"`
+	White int     `json:"white"`
+	Komi  float64 `json:"komi"`
+	Lead  float64 `json:"lead"`
+}
+
+// Process 受监管子进程（如 scrcpy）的状态
+type Process struct {
+	Running   bool   `json:"running"`
+	Restarts  int    `json:"restarts"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Heartbeat /healthz 返回的运行状况，供 systemd watchdog、可用性监控等程序读取
+type Heartbeat struct {
+	// LastCapture 最近一次成功截图的时间，LastDetection 最近一次识别出棋步的时间（还没有时为空）
+	LastCapture   time.Time  `json:"last_capture"`
+	LastDetection *time.Time `json:"last_detection,omitempty"`
+	// Katrain 最近一次访问 KaTrain 的结果：ok、error（原因见 KatrainError）或还没访问过时为 unknown
+	Katrain      string `json:"katrain"`
+	KatrainError string `json:"katrain_error,omitempty"`
+	// Device adb get-state 报告的设备状态（device 表示正常），不使用 ADB 时为空；DeviceError 为读取失败的原因
+	Device      string `json:"device,omitempty"`
+	DeviceError string `json:"device_error,omitempty"`
+	PhoneMove   int    `json:"phone_move"`
+	KatrainMove int    `json:"katrain_move"`
+	Paused      bool   `json:"paused"`
+}
+
+// Command 看板页面上的操作按钮
+type Command struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	run   func(ctx context.Context) error
+}
+
+// Dashboard 保存最新状态并提供 HTTP 接口
+type Dashboard struct {
+	mu       sync.RWMutex
+	status   Status
+	commands []Command
+	health   func() error
+	beat     func(ctx context.Context) Heartbeat
+	routes   map[string]http.Handler
+	auth     Auth
+}
+
+// Auth 看板的访问控制，在局域网或 tailnet 上开放看板时使用。Token 放在 Authorization: Bearer 头或 ?token= 参数中
+// （OBS 浏览器源只能用参数），或用 Username、Password 做 HTTP Basic 认证。都为空时不认证；/healthz 总是可以访问
+type Auth struct {
+	Token    string
+	Username string
+	Password string
+}
+
+func (a Auth) enabled() bool {
+	return a.Token != "" || a.Username != ""
+}
+
+// allows 请求是否带有正确的凭据
+func (a Auth) allows(r *http.Request) bool {
+	if a.Token != "" {
+		token := r.URL.Query().Get("token")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			token = bearer
+		}
+		if token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) == 1 {
+			return true
+		}
+	}
+	if a.Username != "" {
+		user, pass, ok := r.BasicAuth()
+		if ok && subtle.ConstantTimeCompare([]byte(user), []byte(a.Username)) == 1 &&
+			subtle.ConstantTimeCompare([]byte(pass), []byte(a.Password)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func New() *Dashboard {
+	return &Dashboard{}
+}
+
+// Update 在锁内修改状态
+func (d *Dashboard) Update(fn func(s *Status)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fn(&d.status)
+	d.status.UpdatedAt = time.Now()
+}
+
+// Snapshot 返回当前状态的副本
+func (d *Dashboard) Snapshot() Status {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.status
+}
+
+// HandleCommand 注册一个操作，页面上显示为按钮，也可以 POST /api/command/<name> 调用。通过 HTTP 调用时 ctx 随请求取消
+func (d *Dashboard) HandleCommand(name, label string, fn func(ctx context.Context) error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.commands = append(d.commands, Command{Name: name, Label: label, run: fn})
+}
+
+// Handle 在看板的 HTTP 服务上挂载额外的页面（如直播叠加画面），需在 Handler 之前调用
+func (d *Dashboard) Handle(pattern string, h http.Handler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.routes == nil {
+		d.routes = make(map[string]http.Handler)
+	}
+	d.routes[pattern] = h
+}
+
+// SetAuth 设置访问控制，需在 Handler 之前调用
+func (d *Dashboard) SetAuth(a Auth) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.auth = a
+}
+
+// Commands 返回已注册的操作
+func (d *Dashboard) Commands() []Command {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return append([]Command(nil), d.commands...)
+}
+
+// Run 执行名为 name 的操作
+func (d *Dashboard) Run(ctx context.Context, name string) error {
+	for _, c := range d.Commands() {
+		if c.Name == name {
+			return c.run(ctx)
+		}
+	}
+	return fmt.Errorf("未知操作: %s", name)
+}
+
+// SetHealthCheck 设置 /healthz 使用的检查函数，返回错误时 /healthz 响应 503
+func (d *Dashboard) SetHealthCheck(fn func() error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.health = fn
+}
+
+// SetHeartbeat 设置 /healthz 响应中运行状况的来源，未设置时只返回是否健康
+func (d *Dashboard) SetHeartbeat(fn func(ctx context.Context) Heartbeat) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.beat = fn
+}
+
+// Health /healthz 的响应：是否健康、不健康的原因与运行状况
+type Health struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+	*Heartbeat
+}
+
+// Health 执行健康检查并读取运行状况
+func (d *Dashboard) Health(ctx context.Context) Health {
+	h := Health{Healthy: true}
+	if err := d.Healthy(); err != nil {
+		h.Healthy, h.Error = false, err.Error()
+	}
+
+	d.mu.RLock()
+	beat := d.beat
+	d.mu.RUnlock()
+	if beat != nil {
+		b := beat(ctx)
+		h.Heartbeat = &b
+	}
+	return h
+}
+
+// Healthy 执行健康检查，未设置检查函数时总是健康
+func (d *Dashboard) Healthy() error {
+	d.mu.RLock()
+	fn := d.health
+	d.mu.RUnlock()
+	if fn == nil {
+		return nil
+	}
+	return fn()
+}
+
+// Handler 返回看板的 HTTP 路由：/ 为页面，/api/status 为 JSON，/api/commands 与 /api/command/<name> 为操作，
+// /healthz 供容器健康检查与监控：不健康时状态码为 503，响应体为 JSON 格式的 Health；另有 Handle 挂载的页面。
+// 设置了 SetAuth 时，除 /healthz 外都需要认证
+func (d *Dashboard) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		h := d.Health(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		if !h.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(h)
+	})
+	mux.HandleFunc("/api/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(d.Snapshot())
+	})
+	mux.HandleFunc("/api/commands", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(d.Commands())
+	})
+	mux.HandleFunc("/api/command/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "只支持 POST", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := d.Run(r.Context(), strings.TrimPrefix(r.URL.Path, "/api/command/")); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]any{"success": false, "error": err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"success": true})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(indexHTML))
+	})
+
+	d.mu.RLock()
+	for pattern, h := range d.routes {
+		mux.Handle(pattern, h)
+	}
+	auth := d.auth
+	d.mu.RUnlock()
+
+	if !auth.enabled() {
+		return mux
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" && !auth.allows(r) {
+			if auth.Username != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="goboardsync"`)
+			}
+			http.Error(w, "需要认证", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// ListenAndServe 启动看板服务，阻塞直到出错
+func (d *Dashboard) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, d.Handler())
+}
+
+// ListenAndServeTLS 以 HTTPS 启动看板服务，certFile、keyFile 为 PEM 格式的证书与私钥
+func (d *Dashboard) ListenAndServeTLS(addr, certFile, keyFile string) error {
+	return http.ListenAndServeTLS(addr, certFile, keyFile, d.Handler())
+}
+
+const indexHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>goboardsync</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+pre { background: #f4f4f4; padding: 1em; }
+#alert { display: none; background: #c62828; color: #fff; padding: 1em; font-weight: bold; margin-bottom: 1em; }
+</style>
+</head>
+<body>
+<h2>goboardsync 同步状态</h2>
+<div id="alert"></div>
+<div id="commands"></div>
+<pre id="status">加载中...</pre>
+<script>
+async function loadCommands() {
+  const commands = await (await fetch("/api/commands" + location.search)).json();
+  const box = document.getElementById("commands");
+  for (const c of commands || []) {
+    const button = document.createElement("button");
+    button.textContent = c.label;
+    button.onclick = async () => {
+      const result = await (await fetch("/api/command/" + c.name + location.search, {method: "POST"})).json();
+      if (!result.success) alert(result.error);
+      refresh();
+    };
+    box.appendChild(button);
+  }
+}
+async function refresh() {
+  try {
+    const status = await (await fetch("/api/status" + location.search)).json();
+    const alert = document.getElementById("alert");
+    alert.textContent = status.alert || "";
+    alert.style.display = status.alert ? "block" : "none";
+    document.getElementById("status").textContent = JSON.stringify(status, null, 2);
+  } catch (e) {
+    document.getElementById("status").textContent = "连接失败: " + e;
+  }
+}
+loadCommands();
+refresh();
+setInterval(refresh, 1000);
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,241 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatusEndpoint(t *testing.T) {
+	d := New()
+	d.Update(func(s *Status) {
+		s.PhoneMove = 37
+		s.PhoneCoord = "K10"
+		s.BlackClock = &Clock{RemainingSeconds: 25, Periods: 3, ByoYomi: true}
+	})
+
+	server := httptest.NewServer(d.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/status")
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var status Status
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+
+	if status.PhoneMove != 37 || status.PhoneCoord != "K10" {
+		t.Errorf("status = %+v, want phone move 37 K10", status)
+	}
+	if status.BlackClock == nil || status.BlackClock.Periods != 3 {
+		t.Errorf("BlackClock = %+v, want 3 periods", status.BlackClock)
+	}
+	if status.WhiteClock != nil {
+		t.Errorf("WhiteClock = %+v, want nil", status.WhiteClock)
+	}
+	if status.UpdatedAt.IsZero() {
+		t.Errorf("UpdatedAt 未设置")
+	}
+}
+
+func TestIndexPage(t *testing.T) {
+	server := httptest.NewServer(New().Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("Content-Type = %s, want text/html", resp.Header.Get("Content-Type"))
+	}
+
+	resp, err = http.Get(server.URL + "/missing")
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("未知路径状态码 = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	d := New()
+	paused := false
+	d.HandleCommand("pause", "暂停/继续", func(context.Context) error {
+		paused = !paused
+		return nil
+	})
+	d.HandleCommand("fail", "出错", func(context.Context) error {
+		return errors.New("操作失败")
+	})
+
+	server := httptest.NewServer(d.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/commands")
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	var commands []Command
+	json.NewDecoder(resp.Body).Decode(&commands)
+	resp.Body.Close()
+	if len(commands) != 2 || commands[0].Name != "pause" || commands[0].Label != "暂停/继续" {
+		t.Errorf("commands = %+v, want pause 与 fail", commands)
+	}
+
+	tests := []struct {
+		method     string
+		name       string
+		statusCode int
+	}{
+		{http.MethodPost, "pause", http.StatusOK},
+		{http.MethodGet, "pause", http.StatusMethodNotAllowed},
+		{http.MethodPost, "fail", http.StatusBadRequest},
+		{http.MethodPost, "missing", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, server.URL+"/api/command/"+tt.name, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.statusCode {
+			t.Errorf("%s /api/command/%s 状态码 = %d, want %d", tt.method, tt.name, resp.StatusCode, tt.statusCode)
+		}
+	}
+
+	if !paused {
+		t.Errorf("pause 操作未执行")
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	d := New()
+	server := httptest.NewServer(d.Handler())
+	defer server.Close()
+
+	var healthErr error
+	tests := []struct {
+		check      func() error
+		statusCode int
+		wantError  string
+	}{
+		{nil, http.StatusOK, ""},
+		{func() error { return healthErr }, http.StatusOK, ""},
+		{func() error { return errors.New("截图已超过 30s 未成功") }, http.StatusServiceUnavailable, "截图已超过 30s 未成功"},
+	}
+
+	for i, tt := range tests {
+		d.SetHealthCheck(tt.check)
+		resp, err := http.Get(server.URL + "/healthz")
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		var h Health
+		err = json.NewDecoder(resp.Body).Decode(&h)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("case %d: 响应不是 JSON: %v", i, err)
+		}
+		if resp.StatusCode != tt.statusCode || h.Healthy != (tt.statusCode == http.StatusOK) || h.Error != tt.wantError {
+			t.Errorf("case %d: /healthz = %d %+v, want %d", i, resp.StatusCode, h, tt.statusCode)
+		}
+		if h.Heartbeat != nil {
+			t.Errorf("case %d: 未设置 SetHeartbeat 时不应返回运行状况", i)
+		}
+	}
+}
+
+func TestHealthzHeartbeat(t *testing.T) {
+	d := New()
+	server := httptest.NewServer(d.Handler())
+	defer server.Close()
+
+	captured := time.Date(2024, 6, 1, 20, 30, 0, 0, time.UTC)
+	d.SetHeartbeat(func(context.Context) Heartbeat {
+		return Heartbeat{LastCapture: captured, Katrain: "error", KatrainError: "connection refused", Device: "device", PhoneMove: 37, KatrainMove: 36}
+	})
+
+	resp, err := http.Get(server.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"healthy":       true,
+		"last_capture":  "2024-06-01T20:30:00Z",
+		"katrain":       "error",
+		"katrain_error": "connection refused",
+		"device":        "device",
+		"phone_move":    float64(37),
+		"katrain_move":  float64(36),
+	}
+	for k, v := range want {
+		if body[k] != v {
+			t.Errorf("/healthz %s = %v, want %v", k, body[k], v)
+		}
+	}
+	if _, ok := body["last_detection"]; ok {
+		t.Errorf("还没识别出棋步时不应返回 last_detection")
+	}
+}
+
+func TestAuth(t *testing.T) {
+	d := New()
+	d.SetAuth(Auth{Token: "secret", Username: "go", Password: "board"})
+	srv := httptest.NewServer(d.Handler())
+	defer srv.Close()
+
+	get := func(path string, setup func(r *http.Request)) int {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		if setup != nil {
+			setup(req)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	tests := []struct {
+		name  string
+		path  string
+		setup func(r *http.Request)
+		want  int
+	}{
+		{"没有凭据", "/api/status", nil, http.StatusUnauthorized},
+		{"错误的 token", "/api/status?token=wrong", nil, http.StatusUnauthorized},
+		{"token 参数", "/api/status?token=secret", nil, http.StatusOK},
+		{"Bearer", "/api/status", func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") }, http.StatusOK},
+		{"Basic", "/", func(r *http.Request) { r.SetBasicAuth("go", "board") }, http.StatusOK},
+		{"Basic 密码错误", "/", func(r *http.Request) { r.SetBasicAuth("go", "wrong") }, http.StatusUnauthorized},
+		{"healthz 不需要认证", "/healthz", nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := get(tt.path, tt.setup); got != tt.want {
+			t.Errorf("%s: GET %s = %d, want %d", tt.name, tt.path, got, tt.want)
+		}
+	}
+}
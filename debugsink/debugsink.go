@@ -0,0 +1,222 @@
+// Package debugsink 保存识别过程的调试文件（截图、识别详情）以便事后排查：按级别决定保存哪些帧，
+// 每次运行一个子目录并写 index.json 把文件与帧对应起来，按运行次数与总大小清理旧的运行。
+package debugsink
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level 保存级别
+type Level int
+
+const (
+	// Off 不保存
+	Off Level = iota
+	// Failures 只保存识别失败的帧
+	Failures
+	// All 保存每一帧
+	All
+)
+
+// ParseLevel 解析 "off"、"failures"、"all"，空字符串视为 off
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(s) {
+	case "", "off":
+		return Off, nil
+	case "failures":
+		return Failures, nil
+	case "all":
+		return All, nil
+	}
+	return Off, fmt.Errorf("未知的调试保存级别: %s（可选 off、failures、all）", s)
+}
+
+// runPrefix 运行目录名前缀，清理时只处理带此前缀的目录
+const runPrefix = "run-"
+
+// Entry index.json 中的一帧
+type Entry struct {
+	Frame int       `json:"frame"`
+	Time  time.Time `json:"time"`
+	OK    bool      `json:"ok"`
+	Files []string  `json:"files"`
+	// Info 识别详情（如 vision.DebugInfo），保存时即编码，索引中不保留原始的值
+	Info json.RawMessage `json:"info,omitempty"`
+}
+
+// Sink 一次运行的调试文件目录
+type Sink struct {
+	Level Level
+	// Dir 本次运行的目录，位于 root 下
+	Dir string
+	// MaxBytes 本次运行最多写入的字节数，0 表示不限；写满后不再保存并打印一次提示
+	MaxBytes int64
+
+	mu      sync.Mutex
+	index   []Entry
+	written int64
+	full    bool
+}
+
+// New 在 root 下为本次运行创建目录。创建前清理旧的运行：只保留最近 maxRuns-1 次（0 表示不限），
+// 并删除最旧的运行直到总大小不超过 maxMB（0 表示不限）；maxMB 同时限制本次运行的大小。
+// level 为 Off 时不创建目录，返回的 Sink 不保存任何文件
+func New(root string, level Level, maxRuns, maxMB int) (*Sink, error) {
+	s := &Sink{Level: level, MaxBytes: int64(maxMB) << 20}
+	if level == Off {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("创建调试目录失败: %v", err)
+	}
+	if err := prune(root, maxRuns-1, s.MaxBytes); err != nil {
+		return nil, fmt.Errorf("清理旧的调试文件失败: %v", err)
+	}
+
+	s.Dir = filepath.Join(root, runPrefix+time.Now().Format("20060102-150405.000"))
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建调试目录失败: %v", err)
+	}
+	return s, nil
+}
+
+// Wants 按级别判断是否保存识别结果为 ok 的帧，调用方据此决定是否准备文件内容（如编码截图）
+func (s *Sink) Wants(ok bool) bool {
+	if s == nil {
+		return false
+	}
+	return s.Level == All || s.Level == Failures && !ok
+}
+
+// Save 保存一帧的文件（文件名 → 内容），文件名前加上帧号，并更新 index.json；info 为 nil 时不记录识别详情。
+// 级别不需要保存或本次运行已写满时直接返回
+func (s *Sink) Save(frame int, ok bool, files map[string][]byte, info any) error {
+	if !s.Wants(ok) {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.full {
+		return nil
+	}
+
+	var size int64
+	for _, data := range files {
+		size += int64(len(data))
+	}
+	if s.MaxBytes > 0 && s.written+size > s.MaxBytes {
+		s.full = true
+		fmt.Printf("[%s] ⚠️  调试文件已达 %d MB 上限，本次运行不再保存\n", time.Now().Format("15:04:05"), s.MaxBytes>>20)
+		return nil
+	}
+
+	entry := Entry{Frame: frame, Time: time.Now(), OK: ok}
+	if info != nil {
+		data, err := json.Marshal(info)
+		if err != nil {
+			return fmt.Errorf("编码识别详情失败: %v", err)
+		}
+		entry.Info = data
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		file := fmt.Sprintf("%06d-%s", frame, name)
+		if err := os.WriteFile(filepath.Join(s.Dir, file), files[name], 0o644); err != nil {
+			return fmt.Errorf("保存调试文件失败: %v", err)
+		}
+		entry.Files = append(entry.Files, file)
+	}
+	s.written += size
+	s.index = append(s.index, entry)
+
+	data, err := json.MarshalIndent(s.index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("编码调试索引失败: %v", err)
+	}
+	return os.WriteFile(filepath.Join(s.Dir, "index.json"), data, 0o644)
+}
+
+// LatestRun 返回 root 下最近一次保存过帧的运行目录及其 index.json 中的帧（按保存顺序），
+// 没有这样的运行时目录为空字符串
+func LatestRun(root string) (string, []Entry, error) {
+	runs, err := filepath.Glob(filepath.Join(root, runPrefix+"*"))
+	if err != nil {
+		return "", nil, err
+	}
+	// 目录名以时间开头，按名称排序即按时间排序
+	sort.Strings(runs)
+
+	for i := len(runs) - 1; i >= 0; i-- {
+		data, err := os.ReadFile(filepath.Join(runs[i], "index.json"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		var index []Entry
+		if err := json.Unmarshal(data, &index); err != nil {
+			return "", nil, fmt.Errorf("解析 %s 失败: %v", filepath.Join(runs[i], "index.json"), err)
+		}
+		return runs[i], index, nil
+	}
+	return "", nil, nil
+}
+
+// prune 删除 root 下最旧的运行目录，使其不超过 keep 个（keep < 0 表示不限）且总大小不超过 maxBytes（0 表示不限）
+func prune(root string, keep int, maxBytes int64) error {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+
+	var runs []string
+	sizes := map[string]int64{}
+	var total int64
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), runPrefix) {
+			continue
+		}
+		path := filepath.Join(root, e.Name())
+		runs = append(runs, path)
+		sizes[path] = dirSize(path)
+		total += sizes[path]
+	}
+	// 目录名以时间开头，按名称排序即按时间排序
+	sort.Strings(runs)
+
+	for len(runs) > 0 && (keep >= 0 && len(runs) > keep || maxBytes > 0 && total > maxBytes) {
+		if err := os.RemoveAll(runs[0]); err != nil {
+			return err
+		}
+		total -= sizes[runs[0]]
+		runs = runs[1:]
+	}
+	return nil
+}
+
+func dirSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
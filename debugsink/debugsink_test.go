@@ -0,0 +1,142 @@
+package debugsink
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Level
+		wantErr bool
+	}{
+		{"", Off, false},
+		{"off", Off, false},
+		{"Failures", Failures, false},
+		{"all", All, false},
+		{"verbose", Off, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.input)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSave(t *testing.T) {
+	tests := []struct {
+		level Level
+		saved []int
+	}{
+		{Off, nil},
+		{Failures, []int{2}},
+		{All, []int{1, 2}},
+	}
+
+	for _, tt := range tests {
+		root := t.TempDir()
+		s, err := New(root, tt.level, 0, 0)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		s.Save(1, true, map[string][]byte{"frame.jpg": []byte("ok")}, nil)
+		s.Save(2, false, map[string][]byte{"frame.jpg": []byte("bad"), "result.json": []byte("{}")}, map[string]any{"final_status": "failed_at_detection"})
+
+		if tt.level == Off {
+			if entries, _ := os.ReadDir(root); len(entries) != 0 {
+				t.Errorf("Off 时不应创建目录")
+			}
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.Dir, "index.json"))
+		if err != nil {
+			t.Fatalf("读取 index.json 失败: %v", err)
+		}
+		var index []Entry
+		json.Unmarshal(data, &index)
+
+		var frames []int
+		for _, e := range index {
+			frames = append(frames, e.Frame)
+			for _, f := range e.Files {
+				if _, err := os.Stat(filepath.Join(s.Dir, f)); err != nil {
+					t.Errorf("索引中的 %s 不存在", f)
+				}
+			}
+		}
+		if len(frames) != len(tt.saved) || frames[len(frames)-1] != 2 {
+			t.Errorf("级别 %v 保存的帧 = %v, want %v", tt.level, frames, tt.saved)
+		}
+		last := index[len(index)-1]
+		var info map[string]any
+		json.Unmarshal(last.Info, &info)
+		if strings.Join(last.Files, ",") != "000002-frame.jpg,000002-result.json" || info["final_status"] != "failed_at_detection" {
+			t.Errorf("最后一帧索引 = %+v", last)
+		}
+	}
+}
+
+func TestRetention(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"run-20240101-000000.000", "run-20240102-000000.000", "run-20240103-000000.000", "notes"} {
+		os.MkdirAll(filepath.Join(root, name), 0o755)
+	}
+	os.WriteFile(filepath.Join(root, "run-20240103-000000.000", "big.jpg"), make([]byte, 2<<20), 0o644)
+
+	// 保留最近 3 次（含本次），旧的运行只能留 2 个
+	s, err := New(root, All, 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "run-20240101-000000.000")); !os.IsNotExist(err) {
+		t.Errorf("最旧的运行应被删除")
+	}
+	if _, err := os.Stat(filepath.Join(root, "notes")); err != nil {
+		t.Errorf("不应删除其他目录")
+	}
+
+	// 总大小上限 1 MB：2 MB 的运行被删除；本次运行写满后不再保存
+	s, err = New(root, All, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "run-20240103-000000.000")); !os.IsNotExist(err) {
+		t.Errorf("超出大小上限的旧运行应被删除")
+	}
+	s.Save(1, false, map[string][]byte{"a.jpg": make([]byte, 600<<10)}, nil)
+	s.Save(2, false, map[string][]byte{"b.jpg": make([]byte, 600<<10)}, nil)
+	if _, err := os.Stat(filepath.Join(s.Dir, "000002-b.jpg")); !os.IsNotExist(err) {
+		t.Errorf("超出本次运行的大小上限后不应再保存")
+	}
+}
+
+func TestLatestRun(t *testing.T) {
+	root := t.TempDir()
+	if dir, _, err := LatestRun(root); dir != "" || err != nil {
+		t.Errorf("没有运行时 LatestRun() = %q, %v", dir, err)
+	}
+
+	s, err := New(root, All, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Save(1, true, map[string][]byte{"frame.jpg": []byte("a")}, nil)
+	s.Save(2, false, map[string][]byte{"frame.jpg": []byte("b")}, nil)
+	// 之后的运行还没保存过帧，跳过
+	os.MkdirAll(filepath.Join(root, "run-99991231-000000.000"), 0o755)
+
+	dir, index, err := LatestRun(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != s.Dir || len(index) != 2 || index[1].Frame != 2 || index[1].OK {
+		t.Errorf("LatestRun() = %q, %+v, want %q 的两帧", dir, index, s.Dir)
+	}
+}
@@ -5,4 +5,13 @@ go 1.25.6
 require (
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
 	gocv.io/x/gocv v0.43.0
+	google.golang.org/grpc v1.66.0
+	google.golang.org/protobuf v1.35.2
+)
+
+require (
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240604185151-ef581f913117 // indirect
 )
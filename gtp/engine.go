@@ -0,0 +1,214 @@
+// Package gtp 把同步工具包装成 GTP 引擎：GUI 发来的 play 在手机上落子，
+// genmove 等待手机上对方的下一手并返回，Sabaki、LizGoban 等任何支持 GTP 的界面都可以作为分析前端。
+package gtp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"goboardsync/coords"
+)
+
+// Backend 引擎背后的手机，坐标使用 KaTrain 坐标
+type Backend interface {
+	// Play 在手机上以 color 落子
+	Play(ctx context.Context, x, y int, color string) error
+	// NextMove 阻塞等待手机上 color 方的下一手，pass 为 true 表示停一手；ctx 取消时返回错误
+	NextMove(ctx context.Context, color string) (x, y int, pass bool, err error)
+	// Clear 开始新的一局
+	Clear() error
+}
+
+const (
+	Name    = "goboardsync"
+	Version = "1.0"
+)
+
+var commands = []string{
+	"protocol_version",
+	"name",
+	"version",
+	"known_command",
+	"list_commands",
+	"quit",
+	"boardsize",
+	"clear_board",
+	"komi",
+	"play",
+	"genmove",
+}
+
+// Engine GTP 引擎
+type Engine struct {
+	backend Backend
+}
+
+func NewEngine(backend Backend) *Engine {
+	return &Engine{backend: backend}
+}
+
+// Run 从 in 逐行读取 GTP 命令并把响应写到 out，收到 quit、输入结束或 ctx 取消时返回。
+// ctx 取消时正在等待的 genmove 以错误响应
+func (e *Engine) Run(ctx context.Context, in io.Reader, out io.Writer) error {
+	lines := make(chan string)
+	scanErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		scanErr <- scanner.Err()
+	}()
+
+	for {
+		var text string
+		select {
+		case <-ctx.Done():
+			return nil
+		case t, ok := <-lines:
+			if !ok {
+				select {
+				case err := <-scanErr:
+					return err
+				default:
+					return nil
+				}
+			}
+			text = t
+		}
+		line := strings.TrimSpace(stripComment(text))
+		if line == "" {
+			continue
+		}
+
+		id, command, args := parseCommand(line)
+		result, err := e.Handle(ctx, command, args)
+		if err != nil {
+			fmt.Fprintf(out, "?%s %s\n\n", id, err)
+		} else {
+			fmt.Fprintf(out, "=%s %s\n\n", id, result)
+		}
+
+		if command == "quit" {
+			return nil
+		}
+	}
+}
+
+// Handle 执行一条命令，返回响应内容
+func (e *Engine) Handle(ctx context.Context, command string, args []string) (string, error) {
+	switch command {
+	case "protocol_version":
+		return "2", nil
+	case "name":
+		return Name, nil
+	case "version":
+		return Version, nil
+	case "known_command":
+		if len(args) == 1 {
+			for _, c := range commands {
+				if c == args[0] {
+					return "true", nil
+				}
+			}
+		}
+		return "false", nil
+	case "list_commands":
+		return strings.Join(commands, "\n"), nil
+	case "quit", "komi":
+		return "", nil
+	case "boardsize":
+		if len(args) != 1 || args[0] != strconv.Itoa(coords.Size) {
+			return "", fmt.Errorf("unacceptable size")
+		}
+		return "", nil
+	case "clear_board":
+		return "", e.backend.Clear()
+	case "play":
+		return "", e.play(ctx, args)
+	case "genmove":
+		return e.genmove(ctx, args)
+	}
+	return "", fmt.Errorf("unknown command")
+}
+
+func (e *Engine) play(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("syntax error")
+	}
+	color, err := parseColor(args[0])
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(args[1], "pass") {
+		return nil
+	}
+
+	x, y, err := coords.Parse(args[1], coords.GTP)
+	if err != nil {
+		return fmt.Errorf("illegal move")
+	}
+	if err := e.backend.Play(ctx, x, y, color); err != nil {
+		return fmt.Errorf("illegal move: %v", err)
+	}
+	return nil
+}
+
+func (e *Engine) genmove(ctx context.Context, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("syntax error")
+	}
+	color, err := parseColor(args[0])
+	if err != nil {
+		return "", err
+	}
+
+	x, y, pass, err := e.backend.NextMove(ctx, color)
+	if err != nil {
+		return "", err
+	}
+	if pass {
+		return "pass", nil
+	}
+	return coords.Format(x, y, coords.GTP), nil
+}
+
+// parseCommand 拆出可选的命令编号、命令名与参数
+func parseCommand(line string) (string, string, []string) {
+	fields := strings.Fields(line)
+	id := ""
+	if _, err := strconv.Atoi(fields[0]); err == nil {
+		id = fields[0]
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return id, "", nil
+	}
+	return id, strings.ToLower(fields[0]), fields[1:]
+}
+
+func parseColor(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "b", "black":
+		return "B", nil
+	case "w", "white":
+		return "W", nil
+	}
+	return "", fmt.Errorf("invalid color")
+}
+
+func stripComment(line string) string {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
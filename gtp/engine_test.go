@@ -0,0 +1,147 @@
+package gtp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeBackend struct {
+	played []string
+	next   []string // 手机上依次出现的棋步，如 "B D16"、"W pass"
+	clears int
+}
+
+func (f *fakeBackend) Play(ctx context.Context, x, y int, color string) error {
+	if x == 0 && y == 0 {
+		return fmt.Errorf("点击失败")
+	}
+	f.played = append(f.played, fmt.Sprintf("%s %d,%d", color, x, y))
+	return nil
+}
+
+func (f *fakeBackend) NextMove(ctx context.Context, color string) (int, int, bool, error) {
+	for len(f.next) > 0 {
+		move := strings.Fields(f.next[0])
+		f.next = f.next[1:]
+		if move[0] != color {
+			continue
+		}
+		if move[1] == "pass" {
+			return 0, 0, true, nil
+		}
+		var x, y int
+		fmt.Sscanf(move[1], "%d,%d", &x, &y)
+		return x, y, false, nil
+	}
+	return 0, 0, false, fmt.Errorf("没有更多棋步")
+}
+
+func (f *fakeBackend) Clear() error {
+	f.clears++
+	return nil
+}
+
+func TestEngineRun(t *testing.T) {
+	backend := &fakeBackend{next: []string{"B 15,3", "W 2,16", "W pass"}}
+	engine := NewEngine(backend)
+
+	input := strings.Join([]string{
+		"1 protocol_version",
+		"name # 注释",
+		"boardsize 19",
+		"boardsize 13",
+		"clear_board",
+		"play b D16",
+		"play w A1",
+		"genmove white",
+		"10 genmove w",
+		"genmove b",
+		"known_command genmove",
+		"known_command undo",
+		"foo",
+		"quit",
+		"name",
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := engine.Run(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+
+	want := []string{
+		"=1 2",
+		"= goboardsync",
+		"= ",
+		"? unacceptable size",
+		"= ",
+		"= ",
+		"? illegal move: 点击失败",
+		"= C17",
+		"=10 pass",
+		"? 没有更多棋步",
+		"= true",
+		"= false",
+		"? unknown command",
+		"= ",
+	}
+	got := strings.Split(strings.TrimSuffix(out.String(), "\n\n"), "\n\n")
+	if len(got) != len(want) {
+		t.Fatalf("Run() 返回 %d 条响应, want %d:\n%s", len(got), len(want), out.String())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("响应 %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if backend.clears != 1 {
+		t.Errorf("clear_board 调用 Clear() %d 次, want 1", backend.clears)
+	}
+	if len(backend.played) != 1 || backend.played[0] != "B 3,15" {
+		t.Errorf("played = %v, want [B 3,15]", backend.played)
+	}
+}
+
+// blockingBackend 的 NextMove 一直等到 ctx 取消
+type blockingBackend struct {
+	fakeBackend
+	waiting chan struct{}
+}
+
+func (b *blockingBackend) NextMove(ctx context.Context, color string) (int, int, bool, error) {
+	close(b.waiting)
+	<-ctx.Done()
+	return 0, 0, false, ctx.Err()
+}
+
+// TestEngineRunCancel ctx 取消时正在等待的 genmove 以错误响应，Run 随即返回，不等输入结束
+func TestEngineRunCancel(t *testing.T) {
+	backend := &blockingBackend{waiting: make(chan struct{})}
+	in, w := io.Pipe()
+	defer w.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var out bytes.Buffer
+	done := make(chan error, 1)
+	go func() { done <- NewEngine(backend).Run(ctx, in, &out) }()
+	go w.Write([]byte("genmove b\n"))
+
+	<-backend.waiting
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run() unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ctx 取消后 Run() 没有返回")
+	}
+	if !strings.HasPrefix(out.String(), "? ") {
+		t.Errorf("genmove 响应 = %q, want 错误响应", out.String())
+	}
+}
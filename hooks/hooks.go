@@ -0,0 +1,217 @@
+// Package hooks 在识别到新棋步、棋步同步完成与对局结束时调用扩展：在 Go 中实现 Hook 接口注册，
+// 或配置外部 webhook、命令，不需要修改本项目就能接入自定义日志、LED 棋盘、OBS 场景切换等。
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Kind 事件类型
+type Kind string
+
+const (
+	// MoveDetected 手机上识别到新的一手（无论是否同步到 KaTrain）
+	MoveDetected Kind = "move_detected"
+	// MoveSynced 一手棋已同步到另一端（手机 → KaTrain 或 KaTrain → 手机）
+	MoveSynced Kind = "move_synced"
+	// GameEnded 对局结束，棋谱已保存
+	GameEnded Kind = "game_ended"
+	// Blunder KaTrain 分析认为一手棋的胜率损失超过 BlunderThreshold，Message 为损失的说明
+	Blunder Kind = "blunder"
+)
+
+// 棋步同步的方向
+const (
+	PhoneToKatrain = "phone_to_katrain"
+	KatrainToPhone = "katrain_to_phone"
+)
+
+// Event 传给扩展的事件。X、Y 为 KaTrain 坐标（0-18，y 从下往上），Coord 为 GTP 写法（如 Q16）
+type Event struct {
+	Kind      Kind      `json:"kind"`
+	Time      time.Time `json:"time"`
+	Move      int       `json:"move,omitempty"`
+	Color     string    `json:"color,omitempty"`
+	X         int       `json:"x"`
+	Y         int       `json:"y"`
+	Coord     string    `json:"coord,omitempty"`
+	Direction string    `json:"direction,omitempty"`
+	// Record 对局结束时保存的棋谱路径，Message 为对局结束的摘要
+	Record  string `json:"record,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Hook 扩展。Handle 在后台按事件顺序调用，返回的错误只打印，不影响同步
+type Hook interface {
+	Handle(e Event) error
+}
+
+// Func 把普通函数用作 Hook
+type Func func(e Event) error
+
+func (f Func) Handle(e Event) error { return f(e) }
+
+// Named 带名字的扩展，名字用于日志
+type Named struct {
+	Name string
+	Hook Hook
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []Named
+)
+
+// Register 注册全局扩展，此后创建的会话都会调用它。通常在扩展包的 init 中调用，
+// 主程序以 import _ 引入即可
+func Register(name string, h Hook) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, Named{Name: name, Hook: h})
+}
+
+// Registered 返回已注册的全局扩展
+func Registered() []Named {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return append([]Named(nil), registry...)
+}
+
+// Webhook 把事件以 JSON POST 到 URL
+type Webhook struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+func NewWebhook(url string) *Webhook {
+	return &Webhook{URL: url, HTTPClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *Webhook) Handle(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	resp, err := w.HTTPClient.Post(w.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("HTTP %d %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// Exec 每个事件运行一次外部命令（不经过 shell）：事件 JSON 写到标准输入，
+// 并设置环境变量 GOBOARDSYNC_EVENT、GOBOARDSYNC_MOVE、GOBOARDSYNC_COLOR、GOBOARDSYNC_COORD、GOBOARDSYNC_RECORD。
+// 超过 Timeout 未退出时结束进程
+type Exec struct {
+	Path    string
+	Args    []string
+	Timeout time.Duration
+}
+
+func NewExec(path string, args ...string) *Exec {
+	return &Exec{Path: path, Args: args, Timeout: 10 * time.Second}
+}
+
+func (x *Exec) Handle(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), x.Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, x.Path, x.Args...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(),
+		"GOBOARDSYNC_EVENT="+string(e.Kind),
+		"GOBOARDSYNC_MOVE="+strconv.Itoa(e.Move),
+		"GOBOARDSYNC_COLOR="+e.Color,
+		"GOBOARDSYNC_COORD="+e.Coord,
+		"GOBOARDSYNC_RECORD="+e.Record,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// Dispatcher 在一个后台协程中按顺序把事件交给各扩展，不阻塞同步。队列满或已关闭时丢弃事件
+type Dispatcher struct {
+	hooks  []Named
+	events chan Event
+	done   chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewDispatcher 启动分发协程；hooks 为空时返回 nil，nil 的 Dispatcher 可以直接调用 Fire 与 Close
+func NewDispatcher(hooks []Named) *Dispatcher {
+	if len(hooks) == 0 {
+		return nil
+	}
+	d := &Dispatcher{hooks: hooks, events: make(chan Event, 64), done: make(chan struct{})}
+	go d.run()
+	return d
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	for e := range d.events {
+		for _, h := range d.hooks {
+			if err := h.Hook.Handle(e); err != nil {
+				fmt.Printf("[%s] ⚠️  扩展 %s 处理 %s 失败: %v\n", time.Now().Format("15:04:05"), h.Name, e.Kind, err)
+			}
+		}
+	}
+}
+
+// Fire 把事件放入队列，Time 为空时记为当前时间
+func (d *Dispatcher) Fire(e Event) {
+	if d == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	select {
+	case d.events <- e:
+	default:
+		fmt.Printf("[%s] ⚠️  扩展处理不过来，丢弃事件 %s\n", time.Now().Format("15:04:05"), e.Kind)
+	}
+}
+
+// Close 等队列中的事件处理完后停止分发，之后的 Fire 不再生效。可以重复调用
+func (d *Dispatcher) Close() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.events)
+	}
+	d.mu.Unlock()
+	<-d.done
+}
@@ -0,0 +1,77 @@
+package hooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDispatcher(t *testing.T) {
+	var got []string
+	record := Func(func(e Event) error {
+		got = append(got, string(e.Kind)+" "+e.Coord)
+		return nil
+	})
+
+	d := NewDispatcher([]Named{{Name: "record", Hook: record}})
+	d.Fire(Event{Kind: MoveDetected, Coord: "Q16"})
+	d.Fire(Event{Kind: MoveSynced, Coord: "Q16", Direction: PhoneToKatrain})
+	d.Fire(Event{Kind: GameEnded})
+	d.Close()
+
+	want := []string{"move_detected Q16", "move_synced Q16", "game_ended "}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("事件 = %q, want %q", got, want)
+	}
+
+	var none *Dispatcher
+	none.Fire(Event{Kind: GameEnded})
+	none.Close()
+	if NewDispatcher(nil) != nil {
+		t.Error("没有扩展时 NewDispatcher() 应返回 nil")
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var got Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	if err := NewWebhook(srv.URL).Handle(Event{Kind: MoveSynced, Move: 12, Color: "W", X: 3, Y: 3, Coord: "D4"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Kind != MoveSynced || got.Move != 12 || got.Coord != "D4" {
+		t.Errorf("webhook 收到 %+v", got)
+	}
+}
+
+func TestExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("需要 sh")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("需要 sh")
+	}
+	out := filepath.Join(t.TempDir(), "event")
+
+	x := NewExec("sh", "-c", `printf '%s %s ' "$GOBOARDSYNC_EVENT" "$GOBOARDSYNC_COORD" > "$0"; cat >> "$0"`, out)
+	if err := x.Handle(Event{Kind: MoveDetected, Coord: "Q16"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(out)
+	if !strings.HasPrefix(string(data), `move_detected Q16 {"kind":"move_detected"`) {
+		t.Errorf("命令收到 %q", data)
+	}
+
+	if err := NewExec("sh", "-c", "echo boom; exit 3").Handle(Event{Kind: GameEnded}); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("命令失败时 error = %v, 应包含输出", err)
+	}
+}
@@ -0,0 +1,258 @@
+// Package joseki 在布局与定式棋谱（SGF 变化树）中查找当前局面，给出局面的名称与书中的后续下法。
+package joseki
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"slices"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+	"goboardsync/sgf"
+)
+
+//go:embed book.sgf
+var builtin []byte
+
+// cornerMin 角上至少有这么多颗棋子才按定式查找，只有一颗时只是占角
+const cornerMin = 2
+
+// Move 书中的一手后续下法，X/Y 为 KaTrain 坐标，Name 为下完后局面的名称（可能为空）
+type Move struct {
+	Color string
+	X, Y  int
+	Name  string
+}
+
+// String 格式为 "B Q16 名称"
+func (m Move) String() string {
+	s := m.Color + " " + coords.Format(m.X, m.Y, coords.GTP)
+	if m.Name != "" {
+		s += " " + m.Name
+	}
+	return s
+}
+
+// Match 查到的局面：整盘匹配布局时 Corner 为空，否则为匹配定式的角（如 "右上"）。
+// Name 为局面或书中最近一个有名称的前序局面的名称，Next 为书中的后续下法
+type Match struct {
+	Corner string
+	Name   string
+	Next   []Move
+}
+
+// Book 布局与定式书，按局面（而非着手顺序）查找，不同次序下出的同一局面都能查到
+type Book struct {
+	positions map[uint64]*entry
+}
+
+type entry struct {
+	name string
+	next []Move
+	// corner 局面中的棋子都在右上角（见 inCorner），可以作为定式按角查找
+	corner bool
+}
+
+// Load 读取 SGF 格式的布局与定式书，path 为空时使用内置的书（见 book.sgf）。
+// 书中的定式应画在右上角，每个节点的 N 属性为局面的名称
+func Load(path string) (*Book, error) {
+	data := builtin
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("读取定式书失败: %v", err)
+		}
+	}
+	tree, err := sgf.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析定式书失败: %v", err)
+	}
+	b := &Book{positions: make(map[uint64]*entry)}
+	b.add(tree, board.Board{}, name(tree, ""))
+	return b, nil
+}
+
+// add 把 node 之后的各个变化加入书中，pos 为 node 的局面，inherited 为 node 局面的名称
+func (b *Book) add(node *sgf.Tree, pos board.Board, inherited string) {
+	e := b.entry(pos, inherited)
+	for _, child := range node.Children {
+		next, childName := pos, name(child, inherited)
+		if child.Color != "" {
+			if _, err := next.Play(child.X, child.Y, board.ParseColor(child.Color)); err != nil {
+				continue
+			}
+			m := Move{Color: child.Color, X: child.X, Y: child.Y, Name: name(child, "")}
+			if !slices.ContainsFunc(e.next, func(n Move) bool { return n.Color == m.Color && n.X == m.X && n.Y == m.Y }) {
+				e.next = append(e.next, m)
+			}
+		}
+		b.add(child, next, childName)
+	}
+}
+
+// entry 返回局面 pos 的条目，还没有时以 name 为名称新建。同一局面在书中出现多次时保留第一次的名称
+func (b *Book) entry(pos board.Board, name string) *entry {
+	key := pos.Hash()
+	if e, ok := b.positions[key]; ok {
+		return e
+	}
+	e := &entry{name: name, corner: inCorner(&pos)}
+	b.positions[key] = e
+	return e
+}
+
+// name 返回节点的名称（N），没有时返回 inherited
+func name(node *sgf.Tree, inherited string) string {
+	if n := node.Get("N"); len(n) > 0 && n[0] != "" {
+		return n[0]
+	}
+	return inherited
+}
+
+// Lookup 查找局面 pos：整盘局面在书中时只返回这一项，否则按角查找定式，依次返回各角匹配的结果。
+// 都查不到时返回 nil
+func (b *Book) Lookup(pos *board.Board) []Match {
+	if pos.Count(board.Empty) == coords.Size*coords.Size {
+		return nil
+	}
+
+	var corners []Match
+	seen := make(map[string]bool)
+	for t := 0; t < 8; t++ {
+		view := transformBoard(pos, t)
+		if e, ok := b.positions[view.Hash()]; ok {
+			return []Match{{Name: e.name, Next: untransform(e.next, t, false, false)}}
+		}
+
+		corner := cornerName(t)
+		region := cornerRegion(&view)
+		if seen[corner] || coords.Size*coords.Size-region.Count(board.Empty) < cornerMin {
+			continue
+		}
+		// 定式不分黑白，书中黑棋的下法也按白棋查找
+		for _, swap := range []bool{false, true} {
+			if swap {
+				region = swapColors(&region)
+			}
+			if e, ok := b.positions[region.Hash()]; ok && e.corner {
+				seen[corner] = true
+				corners = append(corners, Match{Corner: corner, Name: e.name, Next: untransform(e.next, t, true, swap)})
+				break
+			}
+		}
+	}
+	// 按右上、左上、左下、右下的次序返回，结果不随查找顺序变化
+	slices.SortFunc(corners, func(a, b Match) int { return cornerOrder[a.Corner] - cornerOrder[b.Corner] })
+	return corners
+}
+
+var cornerOrder = map[string]int{"右上": 0, "左上": 1, "左下": 2, "右下": 3}
+
+// transform 对称变换 t（0-7）：第 0 位交换 x、y，第 1 位左右翻转，第 2 位上下翻转
+func transform(t, x, y int) (int, int) {
+	if t&1 != 0 {
+		x, y = y, x
+	}
+	if t&2 != 0 {
+		x = coords.Size - 1 - x
+	}
+	if t&4 != 0 {
+		y = coords.Size - 1 - y
+	}
+	return x, y
+}
+
+// inverse transform 的逆变换
+func inverse(t, x, y int) (int, int) {
+	if t&4 != 0 {
+		y = coords.Size - 1 - y
+	}
+	if t&2 != 0 {
+		x = coords.Size - 1 - x
+	}
+	if t&1 != 0 {
+		x, y = y, x
+	}
+	return x, y
+}
+
+func transformBoard(pos *board.Board, t int) board.Board {
+	var out board.Board
+	for x := 0; x < coords.Size; x++ {
+		for y := 0; y < coords.Size; y++ {
+			if c := pos.At(x, y); c != board.Empty {
+				tx, ty := transform(t, x, y)
+				out.Set(tx, ty, c)
+			}
+		}
+	}
+	return out
+}
+
+// cornerLine 右上角区域的下界：x、y 都不小于它的交叉点算右上角，包括天元所在的两条中线
+const cornerLine = coords.Size / 2
+
+// inCorner 局面中的棋子是否都在右上角
+func inCorner(pos *board.Board) bool {
+	for x := 0; x < coords.Size; x++ {
+		for y := 0; y < coords.Size; y++ {
+			if pos.At(x, y) != board.Empty && (x < cornerLine || y < cornerLine) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// cornerRegion 只保留右上角的棋子
+func cornerRegion(pos *board.Board) board.Board {
+	var out board.Board
+	for x := cornerLine; x < coords.Size; x++ {
+		for y := cornerLine; y < coords.Size; y++ {
+			out.Set(x, y, pos.At(x, y))
+		}
+	}
+	return out
+}
+
+// swapColors 交换局面中的黑白
+func swapColors(pos *board.Board) board.Board {
+	var out board.Board
+	for x := 0; x < coords.Size; x++ {
+		for y := 0; y < coords.Size; y++ {
+			out.Set(x, y, pos.At(x, y).Opponent())
+		}
+	}
+	return out
+}
+
+// cornerName 变换 t 后位于右上角的是原棋盘的哪个角
+func cornerName(t int) string {
+	x, y := inverse(t, coords.Size-1, coords.Size-1)
+	switch {
+	case x > cornerLine && y > cornerLine:
+		return "右上"
+	case y > cornerLine:
+		return "左上"
+	case x > cornerLine:
+		return "右下"
+	}
+	return "左下"
+}
+
+// untransform 把书中的后续下法变换回原棋盘，corner 为真时只保留右上角之内的下法，swap 为真时交换黑白
+func untransform(moves []Move, t int, corner, swap bool) []Move {
+	var out []Move
+	for _, m := range moves {
+		if corner && (m.X < cornerLine || m.Y < cornerLine) {
+			continue
+		}
+		m.X, m.Y = inverse(t, m.X, m.Y)
+		if swap {
+			m.Color = board.ParseColor(m.Color).Opponent().String()
+		}
+		out = append(out, m)
+	}
+	return out
+}
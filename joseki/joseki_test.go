@@ -0,0 +1,88 @@
+package joseki
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"goboardsync/board"
+)
+
+// play 按 KaTrain 坐标依次落子，黑先
+func play(moves ...[2]int) *board.Board {
+	var b board.Board
+	c := board.Black
+	for _, m := range moves {
+		b.Play(m[0], m[1], c)
+		c = c.Opponent()
+	}
+	return &b
+}
+
+func TestLookup(t *testing.T) {
+	book, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := book.Lookup(&board.Board{}); got != nil {
+		t.Errorf("空棋盘 Lookup() = %v, want nil", got)
+	}
+
+	// 书中为 Q16、D4、Q4，这里左右翻转为 D16、Q4、D4
+	got := book.Lookup(play([2]int{3, 15}, [2]int{15, 3}, [2]int{3, 3}))
+	if len(got) != 1 || got[0].Corner != "" || got[0].Name != "二连星" {
+		t.Fatalf("二连星 Lookup() = %+v", got)
+	}
+	if next := got[0].Next; len(next) != 1 || next[0].String() != "W Q16" {
+		t.Errorf("二连星的后续 = %v, want [W Q16]", next)
+	}
+
+	// 左下角星位被小飞挂（书中 Q16、R14 的对称位置 D4、C6），其他角另有棋子
+	got = book.Lookup(play([2]int{3, 3}, [2]int{2, 5}, [2]int{15, 15}, [2]int{3, 15}))
+	if len(got) != 1 || got[0].Corner != "左下" || got[0].Name != "星位 小飞挂" {
+		t.Fatalf("左下角 Lookup() = %+v", got)
+	}
+	want := map[string]bool{"B F3 星位 小飞挂 小飞守角": true, "B C8 星位 小飞挂 一间低夹": true}
+	found := 0
+	for _, m := range got[0].Next {
+		if want[m.String()] {
+			found++
+		}
+	}
+	if found != len(want) || len(got[0].Next) != 5 {
+		t.Errorf("左下角的后续 = %v", got[0].Next)
+	}
+
+	// 两个角同时在书中：无忧角不论先后次序都能查到，左下角是黑白互换的星位小飞挂
+	got = book.Lookup(play([2]int{16, 15}, [2]int{3, 3}, [2]int{2, 5}, [2]int{12, 4}, [2]int{14, 16}))
+	if len(got) != 2 || got[0].Corner != "右上" || got[0].Name != "无忧角" || got[1].Corner != "左下" || got[1].Name != "星位 小飞挂" {
+		t.Fatalf("两个角 Lookup() = %+v", got)
+	}
+	if next := got[1].Next; len(next) == 0 || next[0].Color != "W" {
+		t.Errorf("黑白互换后的后续 = %v, want 白棋", next)
+	}
+
+	// 书中没有的局面
+	if got := book.Lookup(play([2]int{9, 9}, [2]int{0, 0})); got != nil {
+		t.Errorf("书外的局面 Lookup() = %+v, want nil", got)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.sgf")
+	if err := os.WriteFile(path, []byte("(;SZ[19];B[jj]N[天元])"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	book, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := book.Lookup(play([2]int{9, 9})); len(got) != 1 || got[0].Name != "天元" {
+		t.Errorf("Lookup() = %+v", got)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.sgf")); err == nil {
+		t.Errorf("文件不存在时应返回错误")
+	}
+}
@@ -0,0 +1,60 @@
+package katrain
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Auth 访问 KaTrain（或它前面的反向代理）的认证设置，在局域网或 tailnet 上跨机器访问时使用。
+// 字段都为空时与 NewClient 相同
+type Auth struct {
+	// APIKey 以 Authorization: Bearer 发送
+	APIKey string
+	// Username、Password HTTP Basic 认证，与 APIKey 二选一
+	Username string
+	Password string
+	// CAFile HTTPS 使用自签名证书时信任的 CA 证书（PEM），为空时使用系统证书
+	CAFile string
+}
+
+// NewClientWithAuth 创建带认证的客户端，CAFile 无法读取或不是 PEM 证书时返回错误
+func NewClientWithAuth(baseURL string, auth Auth) (*Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if auth.CAFile != "" {
+		pem, err := os.ReadFile(auth.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 KaTrain CA 证书失败: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("KaTrain CA 证书 %s 中没有 PEM 证书", auth.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	c := NewClient(baseURL)
+	c.HTTPClient = &http.Client{Transport: &authTransport{auth: auth, next: transport}}
+	return c, nil
+}
+
+// authTransport 给每个请求加上认证头
+type authTransport struct {
+	auth Auth
+	next http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.auth.APIKey == "" && t.auth.Username == "" {
+		return t.next.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	if t.auth.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.auth.APIKey)
+	} else {
+		req.SetBasicAuth(t.auth.Username, t.auth.Password)
+	}
+	return t.next.RoundTrip(req)
+}
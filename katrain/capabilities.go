@@ -0,0 +1,113 @@
+package katrain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// 各接口的路径。不同版本的补丁提供的接口不同，CheckPosition 与 MakeMove 是同步必需的，其余为可选功能
+const (
+	PathVersion       = "/api/version"
+	PathCheckPosition = "/api/check-position"
+	PathMakeMove      = "/api/make-move"
+	PathLastMove      = "/api/last-move"
+	PathResetBoard    = "/api/reset-board"
+	PathNewGame       = "/api/new-game"
+	PathAnalysis      = "/api/analysis"
+	// PathAnalysisSettings 设置每一手的分析计算量
+	PathAnalysisSettings = "/api/analysis-settings"
+	// PathDeadStones 数子时标记死子并返回 KaTrain 的数子结果
+	PathDeadStones = "/api/dead-stones"
+	// PathSetupPosition 清空棋盘并摆上对局中途的局面（中途接入时使用）
+	PathSetupPosition = "/api/setup-position"
+)
+
+// ErrUnsupported KaTrain 没有该接口（HTTP 404），通常是补丁版本较旧。返回过该错误的接口之后 Supports 为 false
+var ErrUnsupported = errors.New("KaTrain 补丁不支持该接口")
+
+// Capabilities Probe 探测到的接口情况
+type Capabilities struct {
+	// Version /api/version 报告的补丁版本，旧版补丁没有该接口时为空
+	Version string
+	// Missing 不支持的接口路径
+	Missing []string
+}
+
+// Probe 探测 KaTrain 提供的接口：有 /api/version 时按其列出的 endpoints 判断，旧版补丁没有该接口时
+// 逐个请求只读接口，返回 404 的记为不支持（会修改棋盘的接口只在第一次调用返回 404 时记录）。
+// 无法连接 KaTrain，或缺少同步必需的 check-position 接口时返回错误
+func (c *Client) Probe(ctx context.Context) (Capabilities, error) {
+	var caps Capabilities
+	resp, body, err := c.send(ctx, http.MethodGet, c.BaseURL+PathVersion, nil)
+	if err != nil {
+		return caps, err
+	}
+
+	var version struct {
+		Success   bool     `json:"success"`
+		Version   string   `json:"version"`
+		Endpoints []string `json:"endpoints"`
+	}
+	if resp.StatusCode == http.StatusOK && json.Unmarshal(body, &version) == nil && version.Success {
+		caps.Version = version.Version
+		for _, path := range []string{PathCheckPosition, PathMakeMove, PathLastMove, PathResetBoard, PathNewGame, PathAnalysis, PathAnalysisSettings, PathDeadStones, PathSetupPosition} {
+			if !slices.Contains(version.Endpoints, path) {
+				c.markUnsupported(path)
+			}
+		}
+	} else {
+		for path, query := range map[string]string{PathCheckPosition: "?x=0&y=0", PathLastMove: "", PathAnalysis: "?move=0"} {
+			resp, _, err := c.send(ctx, http.MethodGet, c.BaseURL+path+query, nil)
+			if err != nil {
+				return caps, err
+			}
+			c.checkStatus(resp, path)
+		}
+	}
+
+	caps.Missing = c.missing()
+	if !c.Supports(PathCheckPosition) {
+		return caps, fmt.Errorf("KaTrain 没有 %s 接口，请确认已安装同步补丁", PathCheckPosition)
+	}
+	return caps, nil
+}
+
+// Supports 接口是否可用：Probe 或实际调用发现不支持之前都视为可用
+func (c *Client) Supports(path string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.unsupported[path]
+}
+
+func (c *Client) markUnsupported(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unsupported == nil {
+		c.unsupported = make(map[string]bool)
+	}
+	c.unsupported[path] = true
+}
+
+func (c *Client) missing() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var paths []string
+	for path := range c.unsupported {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// checkStatus 接口 path 返回 404 时记为不支持并返回 ErrUnsupported
+func (c *Client) checkStatus(resp *http.Response, path string) error {
+	if resp.StatusCode != http.StatusNotFound {
+		return nil
+	}
+	c.markUnsupported(path)
+	return fmt.Errorf("%w: %s", ErrUnsupported, path)
+}
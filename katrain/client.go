@@ -0,0 +1,271 @@
+// Package katrain 是打过补丁的 KaTrain HTTP API 的客户端，接口说明见 API_DOCUMENTATION.md。
+package katrain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout Client.Timeout 为 0 时每个请求的超时
+const DefaultTimeout = 5 * time.Second
+
+// Client KaTrain HTTP API 客户端
+type Client struct {
+	BaseURL string
+	// Timeout 每个请求（含读取响应）的超时，为 0 时使用 DefaultTimeout；调用方的 ctx 可以更早取消
+	Timeout    time.Duration
+	HTTPClient *http.Client
+
+	mu          sync.Mutex
+	unsupported map[string]bool
+}
+
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    baseURL,
+		HTTPClient: &http.Client{},
+	}
+}
+
+func (c *Client) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultTimeout
+}
+
+// send 发送一个请求并读出响应体，超过 Timeout 或 ctx 取消时中止。body 不为 nil 时以 JSON 发送
+func (c *Client) send(ctx context.Context, method, url string, body []byte) (*http.Response, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+	return resp, data, nil
+}
+
+// CheckPosition 查询 (x, y) 是否有棋子，返回是否有子及棋子颜色
+func (c *Client) CheckPosition(ctx context.Context, x, y int) (bool, string, error) {
+	var result checkPositionResponse
+	if err := c.call(ctx, http.MethodGet, PathCheckPosition, fmt.Sprintf("?x=%d&y=%d", x, y), nil, &result); err != nil {
+		return false, "", err
+	}
+	return result.HasStone, result.Player, nil
+}
+
+type checkPositionResponse struct {
+	HasStone bool   `json:"has_stone"`
+	Player   string `json:"player"`
+}
+
+func (r *checkPositionResponse) validate() error {
+	if r.HasStone && r.Player != "B" && r.Player != "W" {
+		return fmt.Errorf("有棋子时 player 应为 B 或 W，实际为 %q", r.Player)
+	}
+	return nil
+}
+
+// MakeMove 以 player（B/W）在 (x, y) 落子
+func (c *Client) MakeMove(ctx context.Context, x, y int, player string) error {
+	data := fmt.Sprintf(`{"x": %d, "y": %d, "player": "%s"}`, x, y, player)
+	fmt.Printf("[%s] 发送请求: %s\n", time.Now().Format("15:04:05"), data)
+
+	if err := c.call(ctx, http.MethodPost, PathMakeMove, "", []byte(data), nil); err != nil {
+		return fmt.Errorf("落子失败: %w", err)
+	}
+	return nil
+}
+
+// LastMove 返回最后一手的坐标、颜色与手数，棋盘为空时坐标与手数均为 0
+func (c *Client) LastMove(ctx context.Context) (int, int, string, int, error) {
+	var result lastMoveResponse
+	if err := c.call(ctx, http.MethodGet, PathLastMove, "", nil, &result); err != nil {
+		return 0, 0, "", 0, err
+	}
+	if result.LastMove == nil {
+		return 0, 0, "", 0, nil
+	}
+	last := result.LastMove
+	return last.Coords[0], last.Coords[1], last.Player, last.MoveNumber, nil
+}
+
+type lastMoveResponse struct {
+	MoveNumber int `json:"move_number"`
+	LastMove   *struct {
+		Player     string `json:"player"`
+		MoveNumber int    `json:"move_number"`
+		Coords     []int  `json:"coords"`
+	} `json:"last_move"`
+}
+
+func (r *lastMoveResponse) validate() error {
+	if r.LastMove == nil {
+		return nil
+	}
+	// coords 为 null 时与没有最后一手相同
+	if r.LastMove.Coords == nil {
+		r.LastMove = nil
+		return nil
+	}
+	if len(r.LastMove.Coords) != 2 {
+		return fmt.Errorf("last_move.coords 应为两个数，实际为 %v", r.LastMove.Coords)
+	}
+	if r.LastMove.Player != "B" && r.LastMove.Player != "W" {
+		return fmt.Errorf("last_move.player 应为 B 或 W，实际为 %q", r.LastMove.Player)
+	}
+	return nil
+}
+
+// Reset 清空棋盘
+func (c *Client) Reset(ctx context.Context) error {
+	if err := c.call(ctx, http.MethodGet, PathResetBoard, "", nil, nil); err != nil {
+		return fmt.Errorf("重置棋盘失败: %w", err)
+	}
+	return nil
+}
+
+// GameSetup 新对局的设置，对应 /api/new-game 的请求体
+type GameSetup struct {
+	Size      int     `json:"size"`
+	Komi      float64 `json:"komi"`
+	Handicap  int     `json:"handicap"`
+	BlackName string  `json:"black_name,omitempty"`
+	WhiteName string  `json:"white_name,omitempty"`
+	// Ruleset KaTrain 的规则名称，如 chinese、japanese、korean
+	Ruleset string `json:"ruleset,omitempty"`
+}
+
+// NewGame 按 setup 开始新对局（清空棋盘并设置路数、贴目、让子、对局者与规则）
+func (c *Client) NewGame(ctx context.Context, setup GameSetup) error {
+	data, err := json.Marshal(setup)
+	if err != nil {
+		return err
+	}
+	if err := c.call(ctx, http.MethodPost, PathNewGame, "", data, nil); err != nil {
+		return fmt.Errorf("设置对局失败: %w", err)
+	}
+	return nil
+}
+
+// AnalysisBudget 每一手的分析计算量，对应 /api/analysis-settings 的请求体，为 0 的项沿用 KaTrain 自己的设置
+type AnalysisBudget struct {
+	MaxVisits int `json:"max_visits,omitempty"`
+	// MaxTime 每一手最多分析的秒数
+	MaxTime float64 `json:"max_time,omitempty"`
+}
+
+// SetAnalysisBudget 限制 KaTrain 之后每一手的分析计算量，快棋落子快时分析不会越积越多
+func (c *Client) SetAnalysisBudget(ctx context.Context, budget AnalysisBudget) error {
+	data, err := json.Marshal(budget)
+	if err != nil {
+		return err
+	}
+	if err := c.call(ctx, http.MethodPost, PathAnalysisSettings, "", data, nil); err != nil {
+		return fmt.Errorf("设置分析计算量失败: %w", err)
+	}
+	return nil
+}
+
+// deadStonesRequest /api/dead-stones 的请求体，坐标为 KaTrain 坐标
+type deadStonesRequest struct {
+	Stones [][2]int `json:"stones"`
+}
+
+type deadStonesResponse struct {
+	Result string `json:"result"`
+}
+
+// MarkDead 把数子时确认的死子交给 KaTrain（stones 为 KaTrain 坐标，可以为空），返回 KaTrain 按此数子的结果（如 "B+3.5"）
+func (c *Client) MarkDead(ctx context.Context, stones []image.Point) (string, error) {
+	req := deadStonesRequest{Stones: [][2]int{}}
+	for _, p := range stones {
+		req.Stones = append(req.Stones, [2]int{p.X, p.Y})
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	var resp deadStonesResponse
+	if err := c.call(ctx, http.MethodPost, PathDeadStones, "", data, &resp); err != nil {
+		return "", fmt.Errorf("标记死子失败: %w", err)
+	}
+	return resp.Result, nil
+}
+
+// Position 对局中途的局面，对应 /api/setup-position 的请求体，坐标为 KaTrain 坐标
+type Position struct {
+	Black [][2]int `json:"black"`
+	White [][2]int `json:"white"`
+	// Next 轮到落子的一方（B/W）
+	Next string `json:"next"`
+	// MoveNumber 局面对应的手数，之后 /api/last-move 的手数从这里接着数，0 表示未知
+	MoveNumber int `json:"move_number,omitempty"`
+}
+
+// SetupPosition 清空棋盘，把 p 作为摆子局面（SGF 的 AB/AW/PL）放上棋盘，之后的落子接在这个局面后面。
+// 摆上的棋子不算棋步，摆好后没有最后一手，直到有人落子
+func (c *Client) SetupPosition(ctx context.Context, p Position) error {
+	if p.Black == nil {
+		p.Black = [][2]int{}
+	}
+	if p.White == nil {
+		p.White = [][2]int{}
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := c.call(ctx, http.MethodPost, PathSetupPosition, "", data, nil); err != nil {
+		return fmt.Errorf("摆放局面失败: %w", err)
+	}
+	return nil
+}
+
+// Candidate 分析给出的一个候选点，胜率与目差均为黑方视角
+type Candidate struct {
+	Coords    []int   `json:"coords"`
+	Winrate   float64 `json:"winrate"`
+	ScoreLead float64 `json:"score_lead"`
+	Visits    int     `json:"visits"`
+}
+
+// Analysis 第 move 手之后局面的分析结果，胜率（0-1）与目差均为黑方视角，Candidates 按推荐顺序排列
+type Analysis struct {
+	Winrate    float64     `json:"winrate"`
+	ScoreLead  float64     `json:"score_lead"`
+	Candidates []Candidate `json:"candidates"`
+}
+
+// Analysis 读取第 move 手之后局面的分析结果，KaTrain 尚未分析到该手时返回错误
+func (c *Client) Analysis(ctx context.Context, move int) (Analysis, error) {
+	var result Analysis
+	if err := c.call(ctx, http.MethodGet, PathAnalysis, fmt.Sprintf("?move=%d", move), nil, &result); err != nil {
+		return Analysis{}, err
+	}
+	return result, nil
+}
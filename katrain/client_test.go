@@ -0,0 +1,591 @@
+package katrain
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"image"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestCheckPosition(t *testing.T) {
+	tests := []struct {
+		name             string
+		x                int
+		y                int
+		mockResponse     string
+		expectedHasStone bool
+		expectedPlayer   string
+		shouldError      bool
+	}{
+		{
+			name:             "空位置",
+			x:                3,
+			y:                15,
+			mockResponse:     `{"success": true, "has_stone": false, "player": null}`,
+			expectedHasStone: false,
+			expectedPlayer:   "",
+			shouldError:      false,
+		},
+		{
+			name:             "黑棋位置",
+			x:                3,
+			y:                15,
+			mockResponse:     `{"success": true, "has_stone": true, "player": "B"}`,
+			expectedHasStone: true,
+			expectedPlayer:   "B",
+			shouldError:      false,
+		},
+		{
+			name:             "白棋位置",
+			x:                15,
+			y:                3,
+			mockResponse:     `{"success": true, "has_stone": true, "player": "W"}`,
+			expectedHasStone: true,
+			expectedPlayer:   "W",
+			shouldError:      false,
+		},
+		{
+			name:             "服务器错误",
+			x:                3,
+			y:                15,
+			mockResponse:     `{"success": false, "error": "internal error"}`,
+			expectedHasStone: false,
+			expectedPlayer:   "",
+			shouldError:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "/api/check-position") {
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(tt.mockResponse))
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL)
+
+			hasStone, player, err := client.CheckPosition(context.Background(), tt.x, tt.y)
+
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("CheckPosition(%d, %d) expected error, got nil", tt.x, tt.y)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("CheckPosition(%d, %d) unexpected error: %v", tt.x, tt.y, err)
+				return
+			}
+
+			if hasStone != tt.expectedHasStone {
+				t.Errorf("CheckPosition(%d, %d) hasStone = %v, want %v", tt.x, tt.y, hasStone, tt.expectedHasStone)
+			}
+
+			if player != tt.expectedPlayer {
+				t.Errorf("CheckPosition(%d, %d) player = %s, want %s", tt.x, tt.y, player, tt.expectedPlayer)
+			}
+		})
+	}
+}
+
+func TestMakeMove(t *testing.T) {
+	tests := []struct {
+		name         string
+		x            int
+		y            int
+		player       string
+		mockResponse string
+		shouldError  bool
+	}{
+		{
+			name:         "成功落子",
+			x:            3,
+			y:            15,
+			player:       "B",
+			mockResponse: `{"success": true}`,
+			shouldError:  false,
+		},
+		{
+			name:         "位置已有棋子",
+			x:            3,
+			y:            15,
+			player:       "B",
+			mockResponse: `{"success": false, "error": "该坐标已有棋子"}`,
+			shouldError:  true,
+		},
+		{
+			name:         "无效玩家",
+			x:            3,
+			y:            15,
+			player:       "X",
+			mockResponse: `{"success": false, "error": "玩家颜色必须是 B 或 W"}`,
+			shouldError:  true,
+		},
+		{
+			name:         "服务器错误",
+			x:            3,
+			y:            15,
+			player:       "B",
+			mockResponse: `{"success": false, "error": "internal server error"}`,
+			shouldError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "/api/make-move") {
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(tt.mockResponse))
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL)
+
+			err := client.MakeMove(context.Background(), tt.x, tt.y, tt.player)
+
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("MakeMove(%d, %d, %s) expected error, got nil", tt.x, tt.y, tt.player)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("MakeMove(%d, %d, %s) unexpected error: %v", tt.x, tt.y, tt.player, err)
+			}
+		})
+	}
+}
+
+func TestGetLastMove(t *testing.T) {
+	tests := []struct {
+		name            string
+		mockResponse    string
+		expectedX       int
+		expectedY       int
+		expectedPlayer  string
+		expectedMoveNum int
+		shouldError     bool
+	}{
+		{
+			name:            "有最后一手",
+			mockResponse:    `{"success": true, "move_number": 5, "last_move": {"player": "W", "move_number": 5, "coords": [3, 15]}}`,
+			expectedX:       3,
+			expectedY:       15,
+			expectedPlayer:  "W",
+			expectedMoveNum: 5,
+			shouldError:     false,
+		},
+		{
+			name:            "无落子",
+			mockResponse:    `{"success": true, "move_number": 0, "last_move": null}`,
+			expectedX:       0,
+			expectedY:       0,
+			expectedPlayer:  "",
+			expectedMoveNum: 0,
+			shouldError:     false,
+		},
+		{
+			name:            "服务器错误",
+			mockResponse:    `{"success": false, "error": "cannot get board info"}`,
+			expectedX:       0,
+			expectedY:       0,
+			expectedPlayer:  "",
+			expectedMoveNum: 0,
+			shouldError:     true,
+		},
+		{
+			name:            "黑棋落子",
+			mockResponse:    `{"success": true, "move_number": 1, "last_move": {"player": "B", "move_number": 1, "coords": [9, 9]}}`,
+			expectedX:       9,
+			expectedY:       9,
+			expectedPlayer:  "B",
+			expectedMoveNum: 1,
+			shouldError:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "/api/last-move") {
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(tt.mockResponse))
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL)
+
+			x, y, player, moveNum, err := client.LastMove(context.Background())
+
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("LastMove() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("LastMove() unexpected error: %v", err)
+				return
+			}
+
+			if x != tt.expectedX {
+				t.Errorf("LastMove() x = %d, want %d", x, tt.expectedX)
+			}
+
+			if y != tt.expectedY {
+				t.Errorf("LastMove() y = %d, want %d", y, tt.expectedY)
+			}
+
+			if player != tt.expectedPlayer {
+				t.Errorf("LastMove() player = %s, want %s", player, tt.expectedPlayer)
+			}
+
+			if moveNum != tt.expectedMoveNum {
+				t.Errorf("LastMove() moveNum = %d, want %d", moveNum, tt.expectedMoveNum)
+			}
+		})
+	}
+}
+
+func TestNewGame(t *testing.T) {
+	var got GameSetup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/new-game" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		if got.Size != 19 {
+			w.Write([]byte(`{"success": false, "error": "不支持的路数"}`))
+			return
+		}
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	setup := GameSetup{Size: 19, Komi: 7.5, BlackName: "柯洁", WhiteName: "申真谞", Ruleset: "chinese"}
+	if err := client.NewGame(context.Background(), setup); err != nil {
+		t.Fatalf("NewGame() error = %v", err)
+	}
+	if got != setup {
+		t.Errorf("请求体 = %+v, want %+v", got, setup)
+	}
+
+	if err := client.NewGame(context.Background(), GameSetup{Size: 13}); err == nil {
+		t.Error("服务器返回失败时 NewGame() 应返回错误")
+	}
+}
+
+func TestAnalysis(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/analysis" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("move") != "12" {
+			w.Write([]byte(`{"success": false, "error": "尚未分析"}`))
+			return
+		}
+		w.Write([]byte(`{"success": true, "winrate": 0.56, "score_lead": 1.5,
+			"candidates": [{"coords": [3, 3], "winrate": 0.57, "score_lead": 1.8, "visits": 500}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	a, err := client.Analysis(context.Background(), 12)
+	if err != nil {
+		t.Fatalf("Analysis() error = %v", err)
+	}
+	if a.Winrate != 0.56 || a.ScoreLead != 1.5 || len(a.Candidates) != 1 || a.Candidates[0].Visits != 500 {
+		t.Errorf("Analysis() = %+v", a)
+	}
+
+	if _, err := client.Analysis(context.Background(), 13); err == nil {
+		t.Error("尚未分析时 Analysis() 应返回错误")
+	}
+}
+
+func TestSetAnalysisBudget(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PathAnalysisSettings || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	if err := client.SetAnalysisBudget(context.Background(), AnalysisBudget{MaxVisits: 200}); err != nil {
+		t.Fatalf("SetAnalysisBudget() error = %v", err)
+	}
+	// 为 0 的项不发送，沿用 KaTrain 的设置
+	if got != `{"max_visits":200}` {
+		t.Errorf("请求体 = %s", got)
+	}
+}
+
+func TestProbe(t *testing.T) {
+	// 新版补丁：按 /api/version 列出的接口判断
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PathVersion {
+			t.Errorf("有 /api/version 时不应请求 %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"success": true, "version": "1.3", "endpoints": ["/api/check-position", "/api/make-move", "/api/last-move", "/api/reset-board"]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	caps, err := client.Probe(context.Background())
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+	if caps.Version != "1.3" || !slices.Equal(caps.Missing, []string{PathAnalysis, PathAnalysisSettings, PathDeadStones, PathNewGame, PathSetupPosition}) {
+		t.Errorf("Probe() = %+v", caps)
+	}
+	if !client.Supports(PathLastMove) || client.Supports(PathAnalysis) {
+		t.Error("Supports() 与 /api/version 列出的接口不一致")
+	}
+}
+
+func TestProbeLegacy(t *testing.T) {
+	// 旧版补丁：没有 /api/version 与 /api/analysis，new-game 在调用时才发现不支持
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case PathCheckPosition:
+			w.Write([]byte(`{"success": true, "has_stone": false}`))
+		case PathLastMove:
+			w.Write([]byte(`{"success": true, "move_number": 0, "last_move": null}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	caps, err := client.Probe(context.Background())
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+	if caps.Version != "" || !slices.Equal(caps.Missing, []string{PathAnalysis}) {
+		t.Errorf("Probe() = %+v", caps)
+	}
+
+	if err := client.NewGame(context.Background(), GameSetup{Size: 19}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("NewGame() error = %v, want ErrUnsupported", err)
+	}
+	if client.Supports(PathNewGame) {
+		t.Error("返回 404 后 Supports(new-game) 应为 false")
+	}
+
+	// 没有同步必需的接口时报错
+	empty := httptest.NewServer(http.NotFoundHandler())
+	defer empty.Close()
+	if _, err := NewClient(empty.URL).Probe(context.Background()); err == nil {
+		t.Error("没有 check-position 时 Probe() 应返回错误")
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantMsg string
+	}{
+		{"反向代理错误页", http.StatusBadGateway, "<html>502 Bad Gateway</html>", "Bad Gateway"},
+		{"KaTrain 报错", http.StatusInternalServerError, `{"success": false, "error": "engine not ready"}`, "engine not ready"},
+		{"不是 JSON", http.StatusOK, "OK", "无法解析响应"},
+		{"没有 success 字段", http.StatusOK, `{"has_stone": true}`, "没有 success 字段"},
+		{"字段类型不对", http.StatusOK, `{"success": true, "last_move": {"coords": "D4"}}`, "无法解析响应"},
+		{"坐标不是两个数", http.StatusOK, `{"success": true, "last_move": {"player": "B", "coords": [3]}}`, "两个数"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, _, _, _, err := NewClient(server.URL).LastMove(context.Background())
+			var respErr *ResponseError
+			if !errors.As(err, &respErr) {
+				t.Fatalf("LastMove() error = %v, want *ResponseError", err)
+			}
+			if respErr.Status != tt.status || respErr.Path != PathLastMove || !strings.Contains(respErr.Message, tt.wantMsg) {
+				t.Errorf("ResponseError = %+v, want 状态 %d、原因含 %q", respErr, tt.status, tt.wantMsg)
+			}
+			if !strings.Contains(err.Error(), tt.body) {
+				t.Errorf("错误信息应包含响应体: %v", err)
+			}
+		})
+	}
+
+	// 多出的字段忽略，过长的响应体截断
+	extra := `{"success": true, "has_stone": false, "player": null, "komi": 7.5, "padding": "` + strings.Repeat("围", 200) + `"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(extra))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL)
+	if _, _, err := client.CheckPosition(context.Background(), 3, 3); err != nil {
+		t.Errorf("响应带有多余字段时 CheckPosition() error = %v", err)
+	}
+	if got := snippet([]byte(extra)); len(got) > maxSnippet+len("…") || !strings.HasSuffix(got, "…") || !utf8.ValidString(got) {
+		t.Errorf("snippet() = %q", got)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(server.URL)
+	client.Timeout = 50 * time.Millisecond
+	start := time.Now()
+	if _, _, _, _, err := client.LastMove(context.Background()); err == nil {
+		t.Error("KaTrain 无响应时 LastMove() 应返回错误")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("LastMove() 用时 %v，未按 Timeout 中止", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := client.CheckPosition(ctx, 3, 3); !errors.Is(err, context.Canceled) {
+		t.Errorf("ctx 已取消时 CheckPosition() error = %v", err)
+	}
+}
+
+func TestClientWithAuth(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"success": true, "has_stone": true, "player": "B"}`))
+	}))
+	defer server.Close()
+
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(ca, cert, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 不信任自签名证书时连接失败
+	if _, _, err := NewClient(server.URL).CheckPosition(context.Background(), 3, 3); err == nil {
+		t.Error("未配置 CA 时应拒绝自签名证书")
+	}
+
+	client, err := NewClientWithAuth(server.URL, Auth{APIKey: "secret", CAFile: ca})
+	if err != nil {
+		t.Fatalf("NewClientWithAuth() error = %v", err)
+	}
+	if has, _, err := client.CheckPosition(context.Background(), 3, 3); err != nil || !has {
+		t.Fatalf("CheckPosition() = %v, %v", has, err)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want Bearer secret", gotAuth)
+	}
+
+	client, _ = NewClientWithAuth(server.URL, Auth{Username: "go", Password: "board", CAFile: ca})
+	client.CheckPosition(context.Background(), 3, 3)
+	if want := "Basic " + base64.StdEncoding.EncodeToString([]byte("go:board")); gotAuth != want {
+		t.Errorf("Authorization = %q, want %q", gotAuth, want)
+	}
+
+	if _, err := NewClientWithAuth(server.URL, Auth{CAFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("CA 文件不存在时应返回错误")
+	}
+}
+
+func TestMarkDead(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PathDeadStones || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+		w.Write([]byte(`{"success": true, "result": "B+3.5"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	result, err := client.MarkDead(context.Background(), []image.Point{{3, 15}, {16, 2}})
+	if err != nil || result != "B+3.5" {
+		t.Fatalf("MarkDead() = %q, %v, want B+3.5", result, err)
+	}
+	if got != `{"stones":[[3,15],[16,2]]}` {
+		t.Errorf("请求体 = %s", got)
+	}
+
+	// 没有死子时也要发送空列表，KaTrain 据此直接数子
+	if _, err := client.MarkDead(context.Background(), nil); err != nil {
+		t.Fatalf("MarkDead(nil) error = %v", err)
+	}
+	if got != `{"stones":[]}` {
+		t.Errorf("没有死子时请求体 = %s", got)
+	}
+}
+
+func TestSetupPosition(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PathSetupPosition || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	p := Position{Black: [][2]int{{3, 3}, {15, 15}}, White: [][2]int{{15, 3}}, Next: "W", MoveNumber: 4}
+	if err := client.SetupPosition(context.Background(), p); err != nil {
+		t.Fatalf("SetupPosition() error = %v", err)
+	}
+	if got != `{"black":[[3,3],[15,15]],"white":[[15,3]],"next":"W","move_number":4}` {
+		t.Errorf("请求体 = %s", got)
+	}
+
+	// 一方没有棋子时发送空列表而不是 null
+	if err := client.SetupPosition(context.Background(), Position{Black: [][2]int{{3, 3}}, Next: "W"}); err != nil {
+		t.Fatalf("SetupPosition() error = %v", err)
+	}
+	if got != `{"black":[[3,3]],"white":[],"next":"W"}` {
+		t.Errorf("白方没有棋子时请求体 = %s", got)
+	}
+}
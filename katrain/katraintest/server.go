@@ -0,0 +1,353 @@
+// Package katraintest 提供内存中的 KaTrain HTTP API，用于在没有 KaTrain 的环境下测试同步流程，
+// cmd/fake-katrain 用它作为开发与演示用的独立服务。
+package katraintest
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+	"goboardsync/katrain"
+)
+
+// Move KaTrain 棋盘上的一手，坐标为 KaTrain 坐标
+type Move struct {
+	X, Y   int
+	Player string
+}
+
+// Server 模拟打过补丁的 KaTrain，实现 katrain.Client 用到的全部接口。
+// 收到的落子按顺序记录并按规则在棋盘上落子（提子后的交叉点可以再下），Play 模拟用户或 AI 在 KaTrain 中落子
+type Server struct {
+	URL string
+
+	srv     *httptest.Server
+	handler http.Handler
+	mu      sync.Mutex
+	moves   []Move
+	game    *board.Game
+	setup   *katrain.GameSetup
+	// budget 最近一次 /api/analysis-settings 的设置
+	budget *katrain.AnalysisBudget
+	// dead 最近一次 /api/dead-stones 标记的死子
+	dead []image.Point
+	// position 最近一次 /api/setup-position 摆上的局面，base 为它的手数，/api/last-move 的手数从这里接着数
+	position *katrain.Position
+	base     int
+	// disabled 模拟旧版补丁没有的接口，返回 404
+	disabled map[string]bool
+}
+
+// NewServer 在本机随机端口启动服务，用完后调用 Close
+func NewServer() *Server {
+	s := New()
+	s.srv = httptest.NewServer(s)
+	s.URL = s.srv.URL
+	return s
+}
+
+// New 返回未启动的服务，由调用方用 http.Server 等在指定地址上提供（见 cmd/fake-katrain）
+func New() *Server {
+	s := &Server{game: board.NewGame(), disabled: make(map[string]bool)}
+	mux := http.NewServeMux()
+	mux.HandleFunc(katrain.PathVersion, s.version)
+	mux.HandleFunc(katrain.PathCheckPosition, s.checkPosition)
+	mux.HandleFunc(katrain.PathMakeMove, s.makeMove)
+	mux.HandleFunc(katrain.PathLastMove, s.lastMove)
+	mux.HandleFunc(katrain.PathResetBoard, s.resetBoard)
+	mux.HandleFunc(katrain.PathNewGame, s.newGame)
+	mux.HandleFunc(katrain.PathAnalysis, s.analysis)
+	mux.HandleFunc(katrain.PathAnalysisSettings, s.analysisSettings)
+	mux.HandleFunc(katrain.PathDeadStones, s.deadStones)
+	mux.HandleFunc(katrain.PathSetupPosition, s.setupPosition)
+	s.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		disabled := s.disabled[r.URL.Path]
+		s.mu.Unlock()
+		if disabled {
+			http.NotFound(w, r)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// Close 关闭 NewServer 启动的服务
+func (s *Server) Close() {
+	if s.srv != nil {
+		s.srv.Close()
+	}
+}
+
+// Disable 模拟旧版补丁：之后 paths 对应的接口返回 404（/api/version 也不再列出）
+func (s *Server) Disable(paths ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, path := range paths {
+		s.disabled[path] = true
+	}
+}
+
+// Moves 返回目前为止的全部棋步
+func (s *Server) Moves() []Move {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Move(nil), s.moves...)
+}
+
+// Setup 返回最近一次 /api/new-game 的设置，没有调用过时 ok 为 false
+func (s *Server) Setup() (setup katrain.GameSetup, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.setup == nil {
+		return katrain.GameSetup{}, false
+	}
+	return *s.setup, true
+}
+
+// Budget 返回最近一次 /api/analysis-settings 的设置，没有调用过时 ok 为 false
+func (s *Server) Budget() (budget katrain.AnalysisBudget, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.budget == nil {
+		return katrain.AnalysisBudget{}, false
+	}
+	return *s.budget, true
+}
+
+// Board 返回当前局面
+func (s *Server) Board() board.Board {
+	return s.game.Board()
+}
+
+// Dead 返回最近一次 /api/dead-stones 标记的死子，没有调用过时 ok 为 false
+func (s *Server) Dead() (dead []image.Point, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]image.Point(nil), s.dead...), s.dead != nil
+}
+
+// Position 返回最近一次 /api/setup-position 摆上的局面，没有调用过时 ok 为 false
+func (s *Server) Position() (p katrain.Position, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.position == nil {
+		return katrain.Position{}, false
+	}
+	return *s.position, true
+}
+
+// Play 在 (x, y) 落子，与通过 API 落子的规则相同：落在已有棋子的点或自杀时返回错误
+func (s *Server) Play(x, y int, player string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if player != "B" && player != "W" {
+		return fmt.Errorf("无效的颜色: %q", player)
+	}
+	if _, err := s.game.Play(x, y, board.ParseColor(player)); err != nil {
+		return err
+	}
+	s.moves = append(s.moves, Move{X: x, Y: y, Player: player})
+	return nil
+}
+
+// Reset 清空棋步与局面，与 /api/reset-board 相同
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reset()
+}
+
+// reset 调用方持有 mu
+func (s *Server) reset() {
+	s.moves = nil
+	s.dead = nil
+	s.base = 0
+	s.game.Reset()
+}
+
+// version 列出未被 Disable 的接口
+func (s *Server) version(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var endpoints []string
+	for _, path := range []string{katrain.PathCheckPosition, katrain.PathMakeMove, katrain.PathLastMove, katrain.PathResetBoard, katrain.PathNewGame, katrain.PathAnalysis, katrain.PathAnalysisSettings, katrain.PathDeadStones, katrain.PathSetupPosition} {
+		if !s.disabled[path] {
+			endpoints = append(endpoints, path)
+		}
+	}
+	writeJSON(w, map[string]any{"success": true, "version": "katraintest", "endpoints": endpoints})
+}
+
+func (s *Server) checkPosition(w http.ResponseWriter, r *http.Request) {
+	var x, y int
+	if _, err := fmt.Sscan(r.URL.Query().Get("x"), &x); err != nil {
+		writeError(w, "缺少 x")
+		return
+	}
+	if _, err := fmt.Sscan(r.URL.Query().Get("y"), &y); err != nil {
+		writeError(w, "缺少 y")
+		return
+	}
+
+	b := s.game.Board()
+	player := b.At(x, y).String()
+	writeJSON(w, map[string]any{"success": true, "has_stone": player != "", "player": player})
+}
+
+func (s *Server) makeMove(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		X      int    `json:"x"`
+		Y      int    `json:"y"`
+		Player string `json:"player"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, err.Error())
+		return
+	}
+	if err := s.Play(req.X, req.Y, req.Player); err != nil {
+		writeError(w, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"success": true})
+}
+
+func (s *Server) lastMove(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.moves) == 0 {
+		writeJSON(w, map[string]any{"success": true, "move_number": s.base, "last_move": nil})
+		return
+	}
+	n := s.base + len(s.moves)
+	m := s.moves[len(s.moves)-1]
+	writeJSON(w, map[string]any{
+		"success":     true,
+		"move_number": n,
+		"last_move":   map[string]any{"player": m.Player, "move_number": n, "coords": []int{m.X, m.Y}},
+	})
+}
+
+func (s *Server) resetBoard(w http.ResponseWriter, r *http.Request) {
+	s.Reset()
+	writeJSON(w, map[string]any{"success": true})
+}
+
+func (s *Server) newGame(w http.ResponseWriter, r *http.Request) {
+	var setup katrain.GameSetup
+	if err := json.NewDecoder(r.Body).Decode(&setup); err != nil {
+		writeError(w, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.reset()
+	s.setup = &setup
+	s.mu.Unlock()
+	writeJSON(w, map[string]any{"success": true})
+}
+
+func (s *Server) analysisSettings(w http.ResponseWriter, r *http.Request) {
+	var budget katrain.AnalysisBudget
+	if err := json.NewDecoder(r.Body).Decode(&budget); err != nil {
+		writeError(w, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.budget = &budget
+	s.mu.Unlock()
+	writeJSON(w, map[string]any{"success": true})
+}
+
+// deadStones 记下死子，按去掉死子后的局面数子（贴目取 /api/new-game 的设置，没有时为 7.5）
+func (s *Server) deadStones(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Stones [][2]int `json:"stones"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.dead = []image.Point{}
+	for _, p := range req.Stones {
+		s.dead = append(s.dead, image.Pt(p[0], p[1]))
+	}
+	komi := 7.5
+	if s.setup != nil {
+		komi = s.setup.Komi
+	}
+	b := s.game.Board()
+	lead := board.ScoreDead(&b, s.dead, komi).Lead()
+	s.mu.Unlock()
+
+	result := fmt.Sprintf("B+%g", lead)
+	if lead < 0 {
+		result = fmt.Sprintf("W+%g", -lead)
+	}
+	writeJSON(w, map[string]any{"success": true, "result": result})
+}
+
+// setupPosition 清空棋盘并摆上局面，摆上的棋子不算棋步
+func (s *Server) setupPosition(w http.ResponseWriter, r *http.Request) {
+	var p katrain.Position
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		writeError(w, err.Error())
+		return
+	}
+	if p.Next != "B" && p.Next != "W" {
+		writeError(w, fmt.Sprintf("无效的 next: %q", p.Next))
+		return
+	}
+
+	var b board.Board
+	for _, stones := range []struct {
+		coords [][2]int
+		color  board.Color
+	}{{p.Black, board.Black}, {p.White, board.White}} {
+		for _, c := range stones.coords {
+			if !coords.Valid(c[0], c[1]) || b.At(c[0], c[1]) != board.Empty {
+				writeError(w, fmt.Sprintf("无效的棋子: %v", c))
+				return
+			}
+			b.Set(c[0], c[1], stones.color)
+		}
+	}
+
+	s.mu.Lock()
+	s.reset()
+	s.game.Setup(b, p.MoveNumber)
+	s.base = p.MoveNumber
+	s.position = &p
+	s.mu.Unlock()
+	writeJSON(w, map[string]any{"success": true})
+}
+
+// analysis 没有分析引擎，总是返回尚未分析
+func (s *Server) analysis(w http.ResponseWriter, r *http.Request) {
+	writeError(w, "尚未分析")
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, msg string) {
+	writeJSON(w, map[string]any{"success": false, "error": msg})
+}
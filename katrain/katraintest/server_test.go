@@ -0,0 +1,109 @@
+package katraintest
+
+import (
+	"context"
+	"testing"
+
+	"goboardsync/board"
+	"goboardsync/katrain"
+)
+
+func TestServer(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := katrain.NewClient(srv.URL)
+
+	if err := client.NewGame(context.Background(), katrain.GameSetup{Size: 19, Komi: 7.5, Ruleset: "chinese"}); err != nil {
+		t.Fatalf("NewGame() error = %v", err)
+	}
+	if setup, ok := srv.Setup(); !ok || setup.Komi != 7.5 {
+		t.Errorf("Setup() = %+v, %v, want komi 7.5", setup, ok)
+	}
+
+	if err := client.MakeMove(context.Background(), 3, 15, "B"); err != nil {
+		t.Fatalf("MakeMove(3, 15, B) error = %v", err)
+	}
+	if err := client.MakeMove(context.Background(), 3, 15, "W"); err == nil {
+		t.Error("MakeMove() 在已有棋子处落子应返回错误")
+	}
+	if err := srv.Play(15, 3, "W"); err != nil {
+		t.Fatalf("Play(15, 3, W) error = %v", err)
+	}
+
+	hasStone, player, err := client.CheckPosition(context.Background(), 3, 15)
+	if err != nil || !hasStone || player != "B" {
+		t.Errorf("CheckPosition(3, 15) = %v, %q, %v, want true, B", hasStone, player, err)
+	}
+
+	x, y, player, number, err := client.LastMove(context.Background())
+	if err != nil || x != 15 || y != 3 || player != "W" || number != 2 {
+		t.Errorf("LastMove() = %d, %d, %q, %d, %v, want 15, 3, W, 2", x, y, player, number, err)
+	}
+
+	if err := client.Reset(context.Background()); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if moves := srv.Moves(); len(moves) != 0 {
+		t.Errorf("Reset() 后 Moves() = %v, want 空", moves)
+	}
+}
+
+func TestServerCapture(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := katrain.NewClient(srv.URL)
+
+	// 角上的黑子被白提掉后，交叉点为空，可以再落子
+	for _, m := range []Move{{0, 0, "B"}, {1, 0, "W"}, {18, 18, "B"}, {0, 1, "W"}} {
+		if err := client.MakeMove(context.Background(), m.X, m.Y, m.Player); err != nil {
+			t.Fatalf("MakeMove(%d, %d, %s) error = %v", m.X, m.Y, m.Player, err)
+		}
+	}
+	hasStone, _, err := client.CheckPosition(context.Background(), 0, 0)
+	if err != nil || hasStone {
+		t.Errorf("提子后 CheckPosition(0, 0) = %v, %v, want false", hasStone, err)
+	}
+	if err := client.MakeMove(context.Background(), 0, 0, "B"); err == nil {
+		t.Error("MakeMove() 自杀应返回错误")
+	}
+	if err := client.MakeMove(context.Background(), 0, 0, "W"); err != nil {
+		t.Errorf("MakeMove() 在提子后的交叉点落子 error = %v", err)
+	}
+
+	if err := client.Reset(context.Background()); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if b := srv.Board(); b.Count(board.White) != 0 {
+		t.Errorf("Reset() 后棋盘上还有 %d 颗白子", b.Count(board.White))
+	}
+}
+
+func TestServerSetupPosition(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := katrain.NewClient(srv.URL)
+
+	p := katrain.Position{Black: [][2]int{{3, 3}, {15, 15}}, White: [][2]int{{15, 3}}, Next: "W", MoveNumber: 40}
+	if err := client.SetupPosition(context.Background(), p); err != nil {
+		t.Fatalf("SetupPosition() error = %v", err)
+	}
+	if b := srv.Board(); b.Count(board.Black) != 2 || b.Count(board.White) != 1 {
+		t.Errorf("摆子后棋盘 = 黑 %d 白 %d, want 2 1", b.Count(board.Black), b.Count(board.White))
+	}
+
+	// 摆上的棋子不算棋步，落子后手数接着局面的手数
+	if _, _, _, number, err := client.LastMove(context.Background()); err != nil || number != 0 {
+		t.Errorf("摆子后 LastMove() 手数 = %d, %v, want 0", number, err)
+	}
+	if err := client.MakeMove(context.Background(), 3, 15, "W"); err != nil {
+		t.Fatal(err)
+	}
+	x, y, player, number, err := client.LastMove(context.Background())
+	if err != nil || x != 3 || y != 15 || player != "W" || number != 41 {
+		t.Errorf("LastMove() = %d, %d, %q, %d, %v, want 3, 15, W, 41", x, y, player, number, err)
+	}
+
+	if err := client.SetupPosition(context.Background(), katrain.Position{Black: [][2]int{{3, 3}, {3, 3}}, Next: "B"}); err == nil {
+		t.Error("重复的棋子应返回错误")
+	}
+}
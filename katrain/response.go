@@ -0,0 +1,98 @@
+package katrain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+)
+
+// maxSnippet ResponseError 中保留的响应体长度（字节）
+const maxSnippet = 200
+
+// ResponseError KaTrain 返回了出错或不符合约定的响应：HTTP 状态不是 200、响应不是带 success 字段的 JSON、
+// 字段取值不对，或 success 为 false。Body 为响应体的开头，日志中据此判断是补丁版本不符、
+// 反向代理返回的错误页还是 KaTrain 自己报的错
+type ResponseError struct {
+	Path   string
+	Status int
+	// Message success 为 false 时 KaTrain 给出的 error，或响应不符合约定的原因
+	Message string
+	Body    string
+}
+
+func (e *ResponseError) Error() string {
+	msg := fmt.Sprintf("KaTrain %s 响应错误（HTTP %d）: %s", e.Path, e.Status, e.Message)
+	if e.Body != "" {
+		msg += "，响应: " + e.Body
+	}
+	return msg
+}
+
+// call 请求接口 path（query 为带 ? 的查询参数）并把响应解码到 result（可为 nil）。
+// 响应必须是带 success 字段的 JSON 对象，result 中没有的字段忽略（新版补丁可能增加字段）；
+// 404 时返回 ErrUnsupported（见 checkStatus），其余问题返回 *ResponseError
+func (c *Client) call(ctx context.Context, method, path, query string, reqBody []byte, result any) error {
+	resp, body, err := c.send(ctx, method, c.BaseURL+path+query, reqBody)
+	if err != nil {
+		return err
+	}
+	if err := c.checkStatus(resp, path); err != nil {
+		return err
+	}
+
+	fail := func(format string, args ...any) error {
+		return &ResponseError{Path: path, Status: resp.StatusCode, Message: fmt.Sprintf(format, args...), Body: snippet(body)}
+	}
+	var envelope struct {
+		Success *bool  `json:"success"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fail("%s", http.StatusText(resp.StatusCode))
+		}
+		return fail("无法解析响应: %v", err)
+	}
+	switch {
+	case envelope.Success != nil && !*envelope.Success:
+		if envelope.Error == "" {
+			return fail("success 为 false")
+		}
+		return fail("%s", envelope.Error)
+	case resp.StatusCode != http.StatusOK:
+		return fail("%s", http.StatusText(resp.StatusCode))
+	case envelope.Success == nil:
+		return fail("响应中没有 success 字段")
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fail("无法解析响应: %v", err)
+	}
+	if v, ok := result.(validator); ok {
+		if err := v.validate(); err != nil {
+			return fail("%v", err)
+		}
+	}
+	return nil
+}
+
+// validator 解码后还要检查字段取值的响应（如坐标必须是两个数）
+type validator interface {
+	validate() error
+}
+
+// snippet 响应体的开头，过长时截断（不截断多字节字符）
+func snippet(body []byte) string {
+	if len(body) <= maxSnippet {
+		return string(body)
+	}
+	n := maxSnippet
+	for n > 0 && !utf8.RuneStart(body[n]) {
+		n--
+	}
+	return string(body[:n]) + "…"
+}
@@ -1,559 +1,994 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"image"
-	"image/jpeg"
-	"image/png"
-	"io"
-	"net/http"
 	"os"
-	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
 	"strings"
-	"sync"
+	"syscall"
 	"time"
 
+	"goboardsync/adb"
+	"goboardsync/bugreport"
+	"goboardsync/capture"
+	"goboardsync/config"
+	"goboardsync/dashboard"
+	"goboardsync/hooks"
+	"goboardsync/katrain"
+	"goboardsync/notify"
+	"goboardsync/ocr"
+	"goboardsync/platform"
+	"goboardsync/remote"
+	"goboardsync/service"
+	"goboardsync/stats"
+	"goboardsync/syncer"
+	"goboardsync/tui"
 	"goboardsync/vision"
-
-	"github.com/nfnt/resize"
-	"gocv.io/x/gocv"
+	"goboardsync/workdir"
 )
 
-const (
-	WindowTitle   = "my_phone"
-	Interval      = 100 * time.Millisecond
-	ImageDir      = "/Users/chengjiahua/project/my-app"
-	TempImage     = "/Users/chengjiahua/project/my-app/screenshot.jpg"
+// 以下配置都可以用 GOBOARDSYNC_ 前缀的环境变量覆盖（见 loadEnv），容器部署时无需重新编译
+var (
+	WindowTitle = "my_phone"
+	Interval    = 100 * time.Millisecond
+	// 棋谱保存目录，为空时使用系统默认数据目录（macOS/Windows 为 ~/Documents/goboardsync，
+	// Linux 为 ~/.local/share/goboardsync）；截图等临时文件写在每次运行单独的临时目录中，退出时删除
+	ImageDir = ""
+	// 手机序列号，WiFi 调试时填 host:port（如 192.168.1.23:5555），启动时自动 adb connect；
+	// 为空时使用唯一连接的设备
+	ADBSerial     = ""
 	TargetW       = 1200
 	TargetH       = 2670
 	POLL_INTERVAL = 300 * time.Millisecond
+	// 识别双方计时，写入看板与棋谱（BL/WL）
+	EnableClockOCR = false
+	// OCR 服务地址（为空时使用 http://127.0.0.1:5001/ocr）与格式：multipart（附带的本地服务）、paddleocr、umi-ocr，
+	// 或云端的 baidu、google、azure，可带请求上限，如 "baidu:30/500" 表示每分钟 30 次、每天 500 次；
+	// OCRLanguage 为服务的识别语言参数。MoveNumberPattern 为提取手数的正则（含捕获手数的分组），
+	// 适配其他语言的界面，如 "(\d+)手目"，为空时使用内置规则
+	OCREndpoint       = ""
+	OCRBackend        = "multipart"
+	OCRLanguage       = ""
+	MoveNumberPattern = ""
+	// App 的界面语言（ja、ko、en、zh），按该语言的写法（如 "42手目"、"57수"、"Move 17"）提取手数，
+	// 全角与汉字数字统一转换；与 MoveNumberPattern 同时设置时先用 MoveNumberPattern
+	OCRProfile = ""
+	// OCRBackend 失败或达到上限时依次尝试的云端服务，逗号分隔，格式同 OCRBackend，如 "baidu:30/500,google:/1000"。
+	// 云端服务的密钥只从环境变量 BAIDU_OCR_API_KEY、BAIDU_OCR_SECRET_KEY、GOOGLE_VISION_API_KEY、
+	// AZURE_VISION_KEY、AZURE_VISION_ENDPOINT 读取
+	OCRFallback = ""
+	// 角标识别失败时，OCR 读取棋谱面板确定最后一手
+	EnableMoveListFallback = false
+	MoveListPanelDelay     = 500 * time.Millisecond
+	// 观战模式（也可用 -spectate 开启）：只同步手机 → KaTrain，从不点击手机，并按整盘局面补上漏掉的棋步
+	SpectatorMode = false
+	// 观战模式下轮换观看的多桌对局，以 | 分隔的 "名字=切换流程"（如 "A=tap 100,300; wait 2s | B=tap 400,300; wait 2s"），
+	// 每桌停留 TableDwell；为空时只看当前一桌
+	Tables     = ""
+	TableDwell = 30 * time.Second
+	// 多桌轮换时各桌使用的 KaTrain 实例，以 | 分隔的 "名字=地址"；没有配置的桌使用 KATRAIN_URL
+	TableKatrains = ""
+	// 两个方向各自同步的颜色（B、W，为空时双方都同步）。执黑用引擎对弈时 SyncToPhoneColors 设为 "B"，
+	// 对手的白棋从手机同步到 KaTrain 后不会再被点回手机
+	SyncToKatrainColors = ""
+	SyncToPhoneColors   = ""
+	// 开始同步前在 KaTrain 开始新对局：对局者名字 OCR 读取，贴目、让子与规则取以下配置（KaTrain 不支持时只清空棋盘）
+	SetupKatrainGame = true
+	Komi             = 7.5
+	Handicap         = 0
+	Ruleset          = "chinese"
+	// 启动时手机上已有棋子（对局中途才启动程序）时，先把整盘局面摆到 KaTrain 并推断轮到哪方，再一手一手增量同步
+	AttachMidGame = true
+	// 识别到新手时按盘面做粗略的形势判断（看板显示，对局结束时记录），不依赖 KaTrain
+	EstimateScore = true
+	// 数子时识别 App 标在死子上的记号，把死子交给 KaTrain 数子并按去掉死子的局面更新形势判断（对局结束时自动进行，也可手动触发）；
+	// 死子记号为棋子中央边长 DeadMarkSize 格、与棋子外圈灰度相差 DeadMarkContrast 以上的反色方块
+	DeadStoneScoring = false
+	DeadMarkSize     = 0.3
+	DeadMarkContrast = 60.0
+	// 对局结束时把 KaTrain 的胜率、目差写入棋谱注释，并标出前几个推荐点（A、B、C），为 0 时不写
+	AnalysisCandidates = 3
+	// 开始同步时让 KaTrain 把每一手的分析限制在这么多次访问、这么长时间内（快棋转播时分析不会越积越多），为 0 时沿用 KaTrain 的设置
+	AnalysisVisits = 0
+	AnalysisTime   = time.Duration(0)
+	// KaTrain 分析认为一手棋让落子方损失的胜率超过这么多个百分点时报警（日志、推送、扩展事件），0 为不检查；
+	// BlunderColors 为检查的颜色（B、W，为空时双方都检查），BlunderSound 为报警时播放的声音文件（WAV）
+	BlunderThreshold = 0.0
+	BlunderColors    = ""
+	BlunderSound     = ""
+	// 每隔 DeviceCheckInterval 检查手机是否熄屏、锁屏或对弈 App（AppPackage，为空时不检查）退到后台，期间暂停同步；
+	// WakeDevice 开启时自动点亮屏幕并把 App 切回前台
+	DeviceCheckInterval = 2 * time.Second
+	AppPackage          = ""
+	WakeDevice          = false
+	// App 被切走后用 am start 打开的界面（AppActivity，为空时打开主界面），以及从首页点回对局的操作流程，
+	// 如 "wait 3s; tap 600,2300; wait 1s; tap 600,1200"，为空时只切回 App
+	AppActivity = ""
+	ResumeFlow  = ""
+	// 通过 App 的坐标输入框落子的操作流程，{coord} 等占位符换成坐标，如 "tap 1100,2400; text {coord}; key ENTER"；
+	// 为空时按像素位置点击棋盘
+	CoordinateFlow = ""
+	// 通过 adb track-devices 监听手机插拔：断开时暂停同步并通知，重新连上后重新比较局面再继续
+	TrackDevices = true
+	// 电量低于 ThrottleBatteryBelow%（未充电时）或电池温度高于 ThrottleTemperatureAbove°C 时，
+	// 截图间隔放慢到 ThrottleInterval，减少发热与耗电；阈值为 0 时不检查该项
+	ThrottleBatteryBelow     = 20
+	ThrottleTemperatureAbove = 42.0
+	ThrottleInterval         = 1 * time.Second
+	// KaTrain 的新一手只作为建议显示，输入 a 回车（或看板上确认）后才在手机上落子，r 回车放弃
+	ApproveMoves = false
+	// 手机进入复盘/变化图（手数倒退或棋子数多于手数）时暂停同步，回到实战局面后继续
+	DetectReview = true
+	// 融合角标、手数奇偶、与上一帧相比新出现的棋子和交叉点分类，取最一致的结果，角标滞后或误检时更稳
+	FuseSignals = false
+	// 看板监听地址，默认只允许本机访问；容器模式下默认监听所有网卡（":8090"）供端口映射。
+	// 在局域网中开放看板时请同时设置 DASHBOARD_TOKEN 等认证，否则任何人都能暂停同步、在手机上落子
+	DashboardAddr = syncer.DefaultDashboardAddr
+	// 看板以 HTTPS 提供时的证书与私钥（PEM），都为空时使用 HTTP。访问控制见环境变量 DASHBOARD_TOKEN 等（README）
+	DashboardCertFile = ""
+	DashboardKeyFile  = ""
+	// 打开实时预览窗口，显示校正后的棋盘、网格、角标、选中的交叉点与置信度（也可用 -live 开启）
+	LiveView = false
+	// 每记下一手棋后把当前局面画成文本打印到日志，通过 SSH 运行时不用看板也能核对局面（-tui 时不打印）
+	PrintBoard = false
+	// 把每帧的识别叠加图录成棋谱目录下的 game_时间.mp4（按 VideoFPS 播放），便于回看整局的识别过程、附在问题报告中
+	RecordVideo = false
+	VideoFPS    = 2.0
+	// 直播叠加画面：看板的 /overlay/ 为透明背景的网页（OBS 浏览器源），OverlayFile 不为空时
+	// 每手后把叠加画面写成该 PNG（OBS 图像源），边长 OverlaySize 像素
+	OverlayFile = ""
+	OverlaySize = 600
+	// 保存调试截图与识别详情：off 不保存，failures 只保存没识别出新手的帧，all 保存每一帧。
+	// 每次运行在 DebugDir 下建一个子目录（含 index.json），启动时只保留最近 DebugMaxRuns 次、总共不超过 DebugMaxMB
+	DebugLevel   = "off"
+	DebugDir     = "debug"
+	DebugMaxRuns = 10
+	DebugMaxMB   = 200
+	// 识别统计文件：跨多次运行按棋盘皮肤、分辨率与光线累计识别成功与失败的帧数（只写本地），-stats 查看汇总。为空时不统计
+	StatsFile = ""
+	// 每识别到一手新棋时把该帧截图以样本文件名（如 037-J10-B.jpg）保存到此目录下本局的子目录，
+	// 真实对局自动积累为 bench 与批量识别测试的标注样本。为空时不保存
+	SampleDir = ""
+	// 交叉点分类模板目录（stonetrain train 的输出），为空时使用亮度规则
+	StoneTemplateDir = ""
+	// 棋盘皮肤（classic/dark/green），为空时按棋盘底色自动识别
+	BoardSkin = ""
+	// 找角标时忽略的区域（校正棋盘坐标，棋盘为 1024 见方），以 ; 分隔，4 个数为矩形 x1,y1,x2,y2，更多为多边形各顶点，
+	// 用于遮住压在棋盘上的横幅、聊天气泡等含红蓝色的界面元素
+	MarkerExclusions = ""
+	// 对最近几帧识别出的交叉点做多数表决，抑制角标在相邻交叉点之间闪烁；新的一手相应地晚一两帧报告，0 为不表决
+	SmoothFrames = 0
+	// 新识别到的一手要等落子点周围连续几帧没有变化（提子时棋子淡出、落子高亮圈结束）才同步，0 为不等待
+	SettleFrames = 0
+	// 按对局阶段调整：布局阶段（棋子少，识别容易）截图间隔与 SettleFrames 减半，官子阶段（盘面拥挤）多等一帧再同步
+	AdaptToPhase = false
+	// 布局阶段在定式书中查找当前局面，日志与看板显示名称与书中的后续下法
+	OpeningBook = false
+	// 定式书（SGF，定式画在右上角，N 属性为名称），为空时使用内置的书
+	OpeningBookFile = ""
+	// “确定/确认”按钮截图（目标分辨率下裁出），设置后每次确认前在截图中查找按钮，为空时点击 ConfirmX/ConfirmY
+	ConfirmTemplate = ""
+	// 棋盘相对黑方视角顺时针旋转的角度（0/90/180/270），执白时 App 把棋盘倒过来显示应设为 180
+	BoardRotation = 0
+	// 开始同步时 OCR 读取棋盘边上的坐标标签（需在 App 中打开坐标显示），核对棋盘角点与 BoardRotation
+	CheckLabels = false
+	// 画面来源：adb（手机截屏）、screen（截取桌面区域，如 scrcpy 窗口）、camera（摄像头拍摄实体棋盘）
+	// 或 remote（手机接在另一台设备上，见 RemoteCaptureURL）
+	CaptureSource = "adb"
+	CameraDevice  = 0
+	// 摄像头模式下同一局面连续出现的帧数，达到后才认为落子完成
+	CameraStableFrames = 3
+	// remote 模式下采集端的地址，如 http://192.168.1.50:8091。采集端是手机旁的设备（如树莓派）以 -capture-node 运行的本程序，
+	// 监听 CaptureNodeAddr，截图按 CaptureNodeQuality（1-100，越低越省带宽）压缩成 JPEG、每隔 Interval 推送一帧。
+	// 两端的令牌只从环境变量 REMOTE_TOKEN 读取，设置相同的值。采集端未设置令牌时拒绝启动，除非加 -capture-node-insecure
+	RemoteCaptureURL   = ""
+	CaptureNodeAddr    = ":8091"
+	CaptureNodeQuality = 80
+	// KaTrain 接入方式：http（打过补丁的 KaTrain API）或 gui（键盘输入到 KaTrain 窗口，仅手机 → KaTrain）
+	KatrainBackend     = "http"
+	KatrainWindowTitle = "KaTrain"
+	// 转播对局到围棋服务器：igs（教学棋盘）或 kgs（演示棋盘），为空时不转播
+	RelayBackend = ""
+	RelayAddr    = "" // 为空时使用服务器默认地址
+	KGSRoomID    = 0
+	// 某个环节持续出错超过该时长时推送提醒（通知渠道见 README）
+	NotifyErrorAfter = 30 * time.Second
+	// KaTrain 与手机的手数相差超过该值时推送局面不一致提醒
+	DivergenceMoves = 2
+	// 每帧额外识别整盘局面，棋子数加提子数与 KaTrain 手数连续 DriftFrames 帧相差超过 DriftThreshold 时报警
+	// （推送提醒并在看板显示横幅），适合无人值守时发现识别失准
+	MonitorDrift   = false
+	DriftThreshold = 2
+	DriftFrames    = 5
+	// 识别到新棋步、棋步同步完成与对局结束时调用的扩展：把事件 JSON POST 到 HookURL，
+	// 或运行 HookCommand（按空白分隔参数，事件 JSON 写到标准输入），为空时不调用
+	HookURL     = ""
+	HookCommand = ""
+	// scrcpy 只用于投屏显示，同步本身不依赖它
+	EnableScrcpy       = true
+	ScrcpyReadyTimeout = 10 * time.Second
+	// 容器模式：不启动 scrcpy，棋谱默认写到 DockerDataDir（挂载的数据卷）
+	DockerMode    = false
+	DockerDataDir = "/data"
+	// 超过该时长没有成功截图时 /healthz 返回 503
+	HealthTimeout = 30 * time.Second
+	// 每次访问 KaTrain、执行 adb 命令、请求 OCR 服务的超时，手机或服务卡住时同步不会一直停在这一步
+	KatrainTimeout = 5 * time.Second
+	ADBTimeout     = 30 * time.Second
+	OCRTimeout     = 10 * time.Second
+	// 服务模式（-service）的 PID 文件与日志目录，为空时放在数据目录下；
+	// 日志文件超过 LogMaxMB 时轮转，保留 LogMaxFiles 个旧文件
+	PIDFile     = ""
+	LogDir      = ""
+	LogMaxMB    = 10
+	LogMaxFiles = 5
+	// 手机棋盘 A 线、第 1 线交叉点中心的屏幕坐标与线间距（1200x2670 的腾讯围棋 App）
+	BoardStartX = 60.0
+	BoardStartY = 560.0
+	BoardGap    = 60.0
+	// 落子后“确认”按钮的屏幕坐标，两次点击之间的等待时间
+	ConfirmX = 600
+	ConfirmY = 2150
+	TapDelay = 300 * time.Millisecond
+	// 点击落子点后截图等待落子指示标出现的最长时间（为 0 时固定等待 TapDelay），未出现时重新点击的次数
+	ConfirmTimeout = 1500 * time.Millisecond
+	TapRetries     = 1
+	// 点击方式：input（adb shell input tap）或 sendevent（直接向触摸屏写入事件，更快且不丢点击），
+	// TouchDevice 为 sendevent 使用的设备节点（如 /dev/input/event3），为空时自动查找
+	TapMethod   = "input"
+	TouchDevice = ""
+	// 连接手机上的伴侣 App（无障碍服务），由它点击并读取界面中的手数与对话框；CompanionPort 为 adb forward 的本机端口
+	Companion     = false
+	CompanionPort = 27190
+	// 每隔 UIDumpInterval 用 uiautomator dump 读取 App 的前台界面、对话框与手数（0 为不读取），
+	// UIMoveNumberID 为显示手数的控件的资源 ID，为空时在所有文字中查找
+	UIDumpInterval = time.Duration(0)
+	UIMoveNumberID = ""
+	// KEY=value 格式的参数文件，运行中修改后自动重新加载可调参数（见 syncer.Tunables），为空时不启用
+	ConfigFile = ""
 )
 
 var (
-	detector        *vision.Detector
-	KATRAIN_URL     = "http://localhost:8080"
-	lastKatrainMove int
-	lastKatrainX    int
-	lastKatrainY    int
-	lastPhoneMove   int
-	lastPhoneX      int
-	lastPhoneY      int
-	mu              sync.RWMutex
+	KATRAIN_URL = "http://localhost:8080"
+	// KaTrain 使用自签名证书的 HTTPS 时信任的 CA 证书（PEM）。认证信息见环境变量 KATRAIN_API_KEY 等（README）
+	KatrainCAFile = ""
+	// 另外几个 KaTrain 实例的地址（逗号分隔），同步的每一手也发给它们，如一个快速分析、一个深度分析
+	KatrainMirrors = ""
+	// screen 模式下截取的桌面区域（scrcpy 窗口或桌面客户端的棋盘），为空时截取整个屏幕
+	ScreenRegion = image.Rect(0, 0, 0, 0)
+	// screen 模式下桌面客户端棋盘在截图中的四角（左上、右上、右下、左下），为空时截取的是 scrcpy 投屏
+	ScreenBoardCorners = []image.Point{}
+	// 传给 scrcpy 的额外参数（窗口标题由 WindowTitle 指定）
+	ScrcpyArgs = []string{"--always-on-top", "--max-fps", "15"}
 )
 
 func main() {
-	detector = vision.NewDetector()
-
-	fmt.Printf("🚀 程序已启动\n")
-	fmt.Printf("   监控窗口: %s\n", WindowTitle)
-	fmt.Printf("   截图保存路径: %s\n", TempImage)
-	fmt.Printf("   KaTrain API: %s\n", KATRAIN_URL)
-	fmt.Printf("   屏幕分辨率: %dx%d\n", TargetW, TargetH)
-	fmt.Println("   按 Ctrl+C 停止程序")
-	fmt.Println(strings.Repeat("=", 60))
-
-	// 启动前先把 katrain 的棋盘清空
-	clearKatrainBoard()
-
-	go startScrcpy()
-
-	time.Sleep(1 * time.Second)
-
-	fmt.Printf("[%s] 🔄 启动双向同步...\n", time.Now().Format("15:04:05"))
-	fmt.Printf("[%s] 📱 监听手机 → KaTrain\n", time.Now().Format("15:04:05"))
-	fmt.Printf("[%s] 🖥️  监听 KaTrain → 手机\n", time.Now().Format("15:04:05"))
-	fmt.Println(strings.Repeat("=", 60))
-
-	go syncPhoneToKatrain()
-	go syncKatrainToPhone()
-
-	select {}
-}
-
-func startScrcpy() {
-	cmd := exec.Command("scrcpy",
-		"--window-title", WindowTitle,
-		"--always-on-top",
-		"--max-fps", "15",
-	)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Run()
-}
-
-func captureWithADB() (string, error) {
-	adbPath, err := exec.LookPath("adb")
-	if err != nil {
-		return "", fmt.Errorf("未找到 adb: %v", err)
+	gtpMode := flag.Bool("gtp", false, "作为 GTP 引擎运行：从标准输入读取 GTP 命令，手机上的对手落子作为 genmove 的结果")
+	spectate := flag.Bool("spectate", false, "观战模式：只同步手机 → KaTrain，从不点击手机")
+	dockerMode := flag.Bool("docker", false, "容器模式：配置从环境变量读取，不启动 scrcpy，棋谱写到数据卷")
+	configFile := flag.String("config", "", "可调参数文件（KEY=value），修改后自动重新加载")
+	live := flag.Bool("live", false, "打开实时预览窗口，显示识别叠加图")
+	tuiMode := flag.Bool("tui", false, "全屏终端界面：显示棋盘、最近的错误、日志与按键，适合通过 SSH 运行")
+	serviceMode := flag.Bool("service", false, "服务模式：写 PID 文件，日志写到按大小轮转的文件，配置错误与暂时故障以不同的退出码退出")
+	systemdUnit := flag.Bool("systemd-unit", false, "按当前参数与 GOBOARDSYNC_ 环境变量输出 systemd unit 后退出")
+	launchdPlist := flag.Bool("launchd-plist", false, "按当前参数与 GOBOARDSYNC_ 环境变量输出 launchd plist 后退出")
+	captureNode := flag.Bool("capture-node", false, "作为采集端运行：在手机旁的设备上截图并执行点击，供 remote 画面来源的分析端连接")
+	insecureNode := flag.Bool("capture-node-insecure", false, "允许采集端在未设置 REMOTE_TOKEN 时启动（同一网络中的任何人都能看到手机画面并点击手机）")
+	report := flag.String("report", "", "把最近的调试帧（名字、头像打上马赛克）、识别详情、配置与日志打包为 zip 写到此文件后退出，用于提交问题")
+	reportFrames := flag.Int("report-frames", 10, "-report 打包的调试帧数")
+	showStats := flag.Bool("stats", false, "按失败率汇总 StatsFile 中的识别统计后退出")
+	flag.Parse()
+
+	if err := loadEnv(); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(service.ExitConfig)
+	}
+	if *systemdUnit || *launchdPlist {
+		if err := printServiceUnit(*launchdPlist); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
-
-	timestamp := time.Now().UnixNano()
-	remotePath := fmt.Sprintf("/sdcard/go_screenshot_%d.png", timestamp)
-	tempPNGPath := fmt.Sprintf("/Users/chengjiahua/project/my-app/temp_%d.png", timestamp)
-
-	capCmd := exec.Command(adbPath, "shell", "screencap", "-p", remotePath)
-	if err := capCmd.Run(); err != nil {
-		return "", fmt.Errorf("ADB 截图失败: %v", err)
+	if *captureNode {
+		if err := runCaptureNode(*insecureNode); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
-
-	pullCmd := exec.Command("adb", "pull", remotePath, tempPNGPath)
-	if err := pullCmd.Run(); err != nil {
-		return "", fmt.Errorf("拉取截图失败: %v", err)
+	if *showStats {
+		if err := printStats(); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
-
-	rmCmd := exec.Command("adb", "shell", "rm", remotePath)
-	rmCmd.Run()
-
-	if _, err := os.Stat(tempPNGPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("截图文件未生成")
+	if *report != "" {
+		if *configFile != "" {
+			ConfigFile = *configFile
+		}
+		if err := writeReport(*report, *reportFrames); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
-
-	err = convertPNGtoJPG(tempPNGPath, TempImage)
-	os.Remove(tempPNGPath)
-	if err != nil {
-		return "", fmt.Errorf("转换格式失败: %v", err)
+	if *configFile != "" {
+		ConfigFile = *configFile
 	}
-
-	return TempImage, nil
-}
-
-func convertPNGtoJPG(pngPath, jpgPath string) error {
-	file, err := os.Open(pngPath)
-	if err != nil {
-		return err
+	if *dockerMode {
+		DockerMode = true
 	}
-	defer file.Close()
-
-	img, _, err := image.Decode(file)
-	if err != nil {
-		return err
+	if *spectate {
+		SpectatorMode = true
 	}
-
-	out, err := os.Create(jpgPath)
-	if err != nil {
-		return err
+	if *live {
+		LiveView = true
 	}
-	defer out.Close()
-
-	return jpeg.Encode(out, img, &jpeg.Options{Quality: 90})
-}
-
-func getFileSize(path string) int64 {
-	info, err := os.Stat(path)
-	if err != nil {
-		return 0
+	if *tuiMode {
+		PrintBoard = false
 	}
-	return info.Size()
-}
-
-func resizeImage(imagePath string, targetW, targetH int) error {
-	file, err := os.Open(imagePath)
-	if err != nil {
-		return err
+	if DockerMode {
+		EnableScrcpy = false
+		// 容器内只监听回环地址时，端口映射进来的请求访问不到看板与 /healthz
+		if DashboardAddr == syncer.DefaultDashboardAddr {
+			DashboardAddr = ":8090"
+		}
+		if ImageDir == "" {
+			ImageDir = DockerDataDir
+		}
 	}
-	defer file.Close()
 
-	img, _, err := image.Decode(file)
-	if err != nil {
-		return err
+	// GTP 模式下标准输出只能输出协议响应，日志改写到标准错误
+	gtpOut := os.Stdout
+	if *gtpMode {
+		os.Stdout = os.Stderr
 	}
 
-	newImg := resize.Resize(uint(targetW), uint(targetH), img, resize.Lanczos3)
-
-	out, err := os.Create(imagePath)
-	if err != nil {
-		return err
+	stopService := func() {}
+	if *serviceMode {
+		stop, err := startService()
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(service.ExitConfig)
+		}
+		stopService = stop
 	}
-	defer out.Close()
-
-	return png.Encode(out, newImg)
-}
+	defer stopService()
 
-func recognizeWithVision(imagePath string) (*vision.Result, error) {
-	err := resizeImage(imagePath, TargetW, TargetH)
-	if err != nil {
-		fmt.Printf("[%s] 图片缩放失败: %v\n", time.Now().Format("15:04:05"), err)
+	cfg := newConfig()
+	if StoneTemplateDir != "" {
+		classifier, err := vision.LoadTemplateClassifier(StoneTemplateDir)
+		if err != nil {
+			fmt.Printf("⚠️  加载分类模板失败，使用亮度规则: %v\n", err)
+		} else {
+			cfg.Classifier = classifier
+		}
 	}
-
-	img := gocv.IMRead(imagePath, gocv.IMReadColor)
-	if img.Empty() {
-		return nil, fmt.Errorf("无法读取图片")
+	if ConfirmTemplate != "" {
+		button, err := vision.LoadButtonTemplate(ConfirmTemplate)
+		if err != nil {
+			fmt.Printf("⚠️  %v，使用配置的确认按钮坐标\n", err)
+		} else {
+			cfg.ConfirmButton = button
+		}
 	}
-	defer img.Close()
 
-	moveNumber, err := detector.FetchMoveNumberFromOCR(img)
-	// fmt.Printf("[%s] OCR识别结果: moveNumber=%d, err=%v\n", time.Now().Format("15:04:05"), moveNumber, err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	if err != nil || moveNumber == 0 {
-		fmt.Printf("[%s] ⚠️  OCR识别失败或返回0，使用默认策略\n", time.Now().Format("15:04:05"))
-	}
-
-	result, err := vision.DetectLastMoveCoord(img, moveNumber)
+	s, err := syncer.NewSession(ctx, cfg)
 	if err != nil {
-		return &result, nil
+		fmt.Printf("❌ %v\n", err)
+		stopService()
+		os.Exit(exitCode(err))
 	}
-	printResult(&result)
-	return &result, nil
-}
+	defer s.Close()
 
-func printResult(r *vision.Result) {
-	colorName := "黑棋"
-	if r.Color == "W" {
-		colorName = "白棋"
+	if *gtpMode {
+		if err := s.RunGTP(ctx, os.Stdin, gtpOut); err != nil {
+			fmt.Printf("[%s] ❌ %v\n", time.Now().Format("15:04:05"), err)
+		}
+		return
 	}
 
-	xLetter := string(rune('A' + r.X - 1))
-	if xLetter > "S" {
-		xLetter = "T"
+	// 服务模式下没有终端，通过看板操作
+	if !*serviceMode {
+		if *tuiMode {
+			stopTUI, err := startTUI(ctx, s)
+			if err != nil {
+				fmt.Printf("❌ 启动终端界面失败: %v\n", err)
+				return
+			}
+			defer stopTUI()
+		} else {
+			fmt.Println("按 Ctrl+C 停止程序；输入 p 回车暂停/继续，f 重新同步，x 标记最后一手识别有误，s 保存棋谱")
+			if ApproveMoves {
+				fmt.Println("KaTrain 的新一手需确认后才落子：输入 a 回车确认，r 回车放弃")
+			}
+		}
+		go s.ReadControls(ctx, os.Stdin)
 	}
-
-	fmt.Printf("[%s] ✅ 第 %d 手 - %s - 坐标: %s%d\n",
-		time.Now().Format("15:04:05"),
-		r.Move,
-		colorName,
-		xLetter,
-		r.Y,
-	)
-
+	s.Run(ctx)
 }
 
-func checkPosition(x, y int) (bool, string, error) {
-	url := fmt.Sprintf("%s/api/check-position?x=%d&y=%d", KATRAIN_URL, x, y)
-	resp, err := http.Get(url)
+// runCaptureNode 运行采集端：截图以 JPEG 流提供给分析端，并代为执行 adb 命令，直到 Ctrl+C。
+// 未设置 REMOTE_TOKEN 时拒绝启动，insecure 为 true 时只给出警告
+func runCaptureNode(insecure bool) error {
+	token := os.Getenv("REMOTE_TOKEN")
+	if token == "" && !insecure {
+		return fmt.Errorf("未设置 REMOTE_TOKEN，采集端拒绝启动；确需不设令牌（如只在隔离的网络中使用）请加 -capture-node-insecure")
+	}
+	work, err := workdir.New("")
 	if err != nil {
-		return false, "", err
+		return err
 	}
-	defer resp.Body.Close()
+	defer work.Remove()
 
-	body, _ := io.ReadAll(resp.Body)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	var result struct {
-		Success  bool   `json:"success"`
-		HasStone bool   `json:"has_stone"`
-		Player   string `json:"player"`
-		Error    string `json:"error"`
+	phone := adb.NewClient(ADBSerial)
+	phone.Timeout = ADBTimeout
+	if err := phone.Connect(ctx); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
 	}
+	source := capture.NewADBSource(phone, work.Path, work.Join("screenshot.jpg"), TargetW, TargetH)
+	frame := func(ctx context.Context) ([]byte, error) { return source.JPEG(ctx, CaptureNodeQuality) }
+	srv := remote.NewServer(frame, phone, Interval, token)
 
-	if err := json.Unmarshal(body, &result); err != nil {
-		return false, "", err
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe(CaptureNodeAddr) }()
+	fmt.Printf("📷 采集端已启动，监听 %s；分析端设置 CAPTURE_SOURCE=remote 与 REMOTE_CAPTURE_URL 连接\n", CaptureNodeAddr)
+	if srv.Token == "" {
+		fmt.Printf("⚠️  未设置 REMOTE_TOKEN，同一网络中的任何人都能看到手机画面并点击手机\n")
 	}
 
-	if !result.Success {
-		return false, "", fmt.Errorf("API错误: %s", result.Error)
+	select {
+	case err := <-errc:
+		return fmt.Errorf("采集端启动失败: %v", err)
+	case <-ctx.Done():
+		return nil
 	}
+}
 
-	return result.HasStone, result.Player, nil
+// startTUI 把日志改写到内存，在终端上显示全屏界面。返回的函数关闭界面、恢复输出，
+// 并打印最后的日志（退出时保存棋谱等信息）
+func startTUI(ctx context.Context, s *syncer.Session) (func(), error) {
+	term := os.Stdout
+	logs := tui.NewLogs(200)
+	restore, err := service.RedirectOutput(logs)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tui.Run(ctx, term, func() tui.View {
+			return tui.View{Frame: s.Frame(), Status: s.Dashboard().Snapshot(), Keys: controlKeys(s), Logs: logs.Last(10)}
+		}, 500*time.Millisecond)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+		restore()
+		for _, line := range logs.Last(20) {
+			fmt.Println(line)
+		}
+	}, nil
 }
 
-func makeMove(x, y int, player string) error {
-	url := fmt.Sprintf("%s/api/make-move", KATRAIN_URL)
+// controlKeys 已注册的操作及其终端按键，按注册顺序排列
+func controlKeys(s *syncer.Session) []tui.Key {
+	var keys []tui.Key
+	for _, c := range s.Dashboard().Commands() {
+		for key, name := range syncer.ControlKeys {
+			if name == c.Name {
+				keys = append(keys, tui.Key{Key: key, Label: c.Label})
+			}
+		}
+	}
+	return keys
+}
 
-	data := fmt.Sprintf(`{"x": %d, "y": %d, "player": "%s"}`, x, y, player)
-	fmt.Printf("[%s] 发送请求: %s\n", time.Now().Format("15:04:05"), data)
+// exitCode 启动失败时的退出码：配置错误重启也不会成功，其他错误（如手机未连接）视为暂时故障，由服务管理器重启
+func exitCode(err error) int {
+	var configErr *syncer.ConfigError
+	if errors.As(err, &configErr) {
+		return service.ExitConfig
+	}
+	return service.ExitTransient
+}
 
-	resp, err := http.Post(url, "application/json", strings.NewReader(data))
+// writeReport 生成问题报告（见 bugreport）写到 path
+func writeReport(path string, frames int) error {
+	_, logDir, err := serviceDirs()
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-
-	var result struct {
-		Success bool   `json:"success"`
-		Error   string `json:"error"`
+	f, err := os.Create(path)
+	if err != nil {
+		return err
 	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
-		return fmt.Errorf("解析响应失败: %s", string(body))
+	summary, err := bugreport.Write(f, bugreport.Options{
+		DebugDir:   DebugDir,
+		Frames:     frames,
+		Anonymize:  vision.AnonymizeJPEG,
+		Config:     config.Dump(envFields()),
+		ConfigFile: ConfigFile,
+		LogDir:     logDir,
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
 	}
-
-	if !result.Success {
-		return fmt.Errorf("落子失败: %s", result.Error)
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("生成问题报告失败: %v", err)
 	}
 
+	if summary.Run == "" {
+		fmt.Printf("⚠️  %s 下没有调试帧，可用 GOBOARDSYNC_DEBUG_LEVEL=all 运行复现问题后再生成报告\n", DebugDir)
+	} else if summary.Skipped > 0 {
+		fmt.Printf("⚠️  %d 帧的分辨率没有配置个人信息区域，未打包\n", summary.Skipped)
+	}
+	fmt.Printf("✅ 问题报告已写到 %s：%d 帧截图，%d 个日志文件\n", path, summary.Frames, len(summary.Logs))
 	return nil
 }
 
-func getLastMove() (int, int, string, int, error) {
-	url := fmt.Sprintf("%s/api/last-move", KATRAIN_URL)
-	resp, err := http.Get(url)
+// printStats 输出识别统计的汇总（见 stats.Summarize）
+func printStats() error {
+	if StatsFile == "" {
+		return fmt.Errorf("未开启识别统计，请设置 GOBOARDSYNC_STATS_FILE")
+	}
+	rows, err := stats.Load(StatsFile)
 	if err != nil {
-		return 0, 0, "", 0, err
+		return err
 	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
+	stats.Summarize(os.Stdout, rows)
+	return nil
+}
 
-	var result struct {
-		Success    bool   `json:"success"`
-		MoveNumber int    `json:"move_number"`
-		Error      string `json:"error"`
-		LastMove   struct {
-			Player     string `json:"player"`
-			MoveNumber int    `json:"move_number"`
-			Coords     []int  `json:"coords"`
-		} `json:"last_move"`
+// serviceDirs 服务模式的 PID 文件路径与日志目录，未配置时放在数据目录下
+func serviceDirs() (pidFile, logDir string, err error) {
+	pidFile, logDir = PIDFile, LogDir
+	if pidFile == "" || logDir == "" {
+		dir, err := platform.DataDir()
+		if err != nil {
+			return "", "", err
+		}
+		if pidFile == "" {
+			pidFile = filepath.Join(dir, "goboardsync.pid")
+		}
+		if logDir == "" {
+			logDir = filepath.Join(dir, "logs")
+		}
 	}
+	return pidFile, logDir, nil
+}
 
-	if err := json.Unmarshal(body, &result); err != nil {
-		return 0, 0, "", 0, err
+// startService 写 PID 文件，并把日志改写到按大小轮转的 goboardsync.log。
+// 返回的函数恢复输出、关闭日志并删除 PID 文件
+func startService() (func(), error) {
+	pidFile, logDir, err := serviceDirs()
+	if err != nil {
+		return nil, err
 	}
-
-	if !result.Success {
-		return 0, 0, "", 0, fmt.Errorf("API错误: %s", result.Error)
+	removePID, err := service.WritePIDFile(pidFile)
+	if err != nil {
+		return nil, err
 	}
-
-	if result.LastMove.Coords == nil {
-		return 0, 0, "", 0, nil
+	logFile, err := service.OpenLogFile(filepath.Join(logDir, "goboardsync.log"), int64(LogMaxMB)<<20, LogMaxFiles)
+	if err != nil {
+		removePID()
+		return nil, err
 	}
-
-	return result.LastMove.Coords[0], result.LastMove.Coords[1], result.LastMove.Player, result.LastMove.MoveNumber, nil
+	restore, err := service.RedirectOutput(logFile)
+	if err != nil {
+		logFile.Close()
+		removePID()
+		return nil, err
+	}
+
+	// 日志中的时间只有时分秒，启动时记一行日期
+	fmt.Printf("===== %s 以服务模式启动 (pid %d) =====\n", time.Now().Format("2006-01-02 15:04:05"), os.Getpid())
+	return func() {
+		restore()
+		logFile.Close()
+		removePID()
+	}, nil
 }
 
-func resetKatrainBoard() error {
-	url := fmt.Sprintf("%s/api/reset-board", KATRAIN_URL)
-	resp, err := http.Get(url)
+// printServiceUnit 输出 systemd unit（launchd 为 false）或 launchd plist：以当前可执行文件、工作目录
+// 与命令行参数（去掉生成用的参数，加上 -service）启动，并带上当前的 GOBOARDSYNC_ 环境变量
+func printServiceUnit(launchd bool) error {
+	exe, err := os.Executable()
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-
-	var result struct {
-		Success bool   `json:"success"`
-		Error   string `json:"error"`
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
 	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
-		return fmt.Errorf("解析响应失败: %s", string(body))
+	_, logDir, err := serviceDirs()
+	if err != nil {
+		return err
 	}
 
-	if !result.Success {
-		return fmt.Errorf("重置棋盘失败: %s", result.Error)
+	args := []string{"-service"}
+	for _, arg := range os.Args[1:] {
+		switch strings.TrimLeft(arg, "-") {
+		case "service", "systemd-unit", "launchd-plist":
+			continue
+		}
+		args = append(args, arg)
 	}
+	var env []string
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, config.Prefix) {
+			env = append(env, kv)
+		}
+	}
+	sort.Strings(env)
 
+	u := service.Unit{Name: "goboardsync", Exec: exe, Args: args, WorkDir: wd, Env: env, LogDir: logDir}
+	if launchd {
+		fmt.Print(service.LaunchdPlist(u))
+	} else {
+		fmt.Print(service.SystemdUnit(u))
+	}
 	return nil
 }
 
-func clearKatrainBoard() {
-	fmt.Printf("[%s] 🧹 正在清空 KaTrain 棋盘...\n", time.Now().Format("15:04:05"))
-	err := resetKatrainBoard()
-	if err != nil {
-		fmt.Printf("[%s] ❌ 清空棋盘失败: %v\n", time.Now().Format("15:04:05"), err)
-	} else {
-		fmt.Printf("[%s] ✅ KaTrain 棋盘已清空\n", time.Now().Format("15:04:05"))
+// newConfig 把 main.go 中的配置转换为同步会话的配置
+func newConfig() syncer.Config {
+	return syncer.Config{
+		WindowTitle: WindowTitle,
+		RecordDir:   ImageDir,
+		TargetW:     TargetW,
+		TargetH:     TargetH,
+		Tunables: syncer.Tunables{
+			Interval:     Interval,
+			PollInterval: POLL_INTERVAL,
+			Geometry: syncer.Geometry{
+				BoardStartX: BoardStartX,
+				BoardStartY: BoardStartY,
+				BoardGap:    BoardGap,
+				ConfirmX:    ConfirmX,
+				ConfirmY:    ConfirmY,
+			},
+			// 横屏坐标只在参数文件中调整
+			Landscape:      syncer.DefaultTunables().Landscape,
+			TapDelay:       TapDelay,
+			ConfirmTimeout: ConfirmTimeout,
+			TapRetries:     TapRetries,
+		},
+		ConfigFile:         ConfigFile,
+		EnableClockOCR:     EnableClockOCR,
+		OCREndpoint:        OCREndpoint,
+		OCRBackend:         OCRBackend,
+		OCRLanguage:        OCRLanguage,
+		MoveNumberPatterns: moveNumberPatterns(),
+		OCRProfile:         OCRProfile,
+		OCRFallbacks:       ocrFallbacks(),
+		OCRCredentials: ocr.Credentials{
+			BaiduAPIKey:    os.Getenv("BAIDU_OCR_API_KEY"),
+			BaiduSecretKey: os.Getenv("BAIDU_OCR_SECRET_KEY"),
+			GoogleAPIKey:   os.Getenv("GOOGLE_VISION_API_KEY"),
+			AzureKey:       os.Getenv("AZURE_VISION_KEY"),
+			AzureEndpoint:  os.Getenv("AZURE_VISION_ENDPOINT"),
+		},
+		EnableMoveListFallback:   EnableMoveListFallback,
+		MoveListPanelDelay:       MoveListPanelDelay,
+		Spectator:                SpectatorMode,
+		Tables:                   Tables,
+		TableDwell:               TableDwell,
+		TableKatrains:            TableKatrains,
+		PhoneToKatrainColors:     syncer.ColorFilter(SyncToKatrainColors),
+		KatrainToPhoneColors:     syncer.ColorFilter(SyncToPhoneColors),
+		SetupGame:                SetupKatrainGame,
+		Komi:                     Komi,
+		Handicap:                 Handicap,
+		Ruleset:                  Ruleset,
+		AttachMidGame:            AttachMidGame,
+		EstimateScore:            EstimateScore,
+		DeadStoneScoring:         DeadStoneScoring,
+		DeadMark:                 vision.DeadMark{Size: DeadMarkSize, MinContrast: DeadMarkContrast},
+		AnalysisCandidates:       AnalysisCandidates,
+		AnalysisVisits:           AnalysisVisits,
+		AnalysisTime:             AnalysisTime,
+		BlunderThreshold:         BlunderThreshold,
+		BlunderColors:            syncer.ColorFilter(BlunderColors),
+		BlunderSound:             BlunderSound,
+		DeviceCheckInterval:      DeviceCheckInterval,
+		AppPackage:               AppPackage,
+		TrackDevices:             TrackDevices,
+		WakeDevice:               WakeDevice,
+		AppActivity:              AppActivity,
+		ResumeFlow:               ResumeFlow,
+		CoordinateFlow:           CoordinateFlow,
+		TapMethod:                TapMethod,
+		TouchDevice:              TouchDevice,
+		Companion:                Companion,
+		CompanionPort:            CompanionPort,
+		UIDumpInterval:           UIDumpInterval,
+		UIMoveNumberID:           UIMoveNumberID,
+		ThrottleBatteryBelow:     ThrottleBatteryBelow,
+		ThrottleTemperatureAbove: ThrottleTemperatureAbove,
+		ThrottleInterval:         ThrottleInterval,
+		ApproveMoves:             ApproveMoves,
+		DetectReview:             DetectReview,
+		FuseSignals:              FuseSignals,
+		LiveView:                 LiveView,
+		PrintBoard:               PrintBoard,
+		RecordVideo:              RecordVideo,
+		VideoFPS:                 VideoFPS,
+		OverlayFile:              OverlayFile,
+		OverlaySize:              OverlaySize,
+		DebugLevel:               DebugLevel,
+		DebugDir:                 DebugDir,
+		DebugMaxRuns:             DebugMaxRuns,
+		DebugMaxMB:               DebugMaxMB,
+		StatsFile:                StatsFile,
+		SampleDir:                SampleDir,
+		DashboardAddr:            DashboardAddr,
+		DashboardAuth:            dashboardAuth(),
+		DashboardCertFile:        DashboardCertFile,
+		DashboardKeyFile:         DashboardKeyFile,
+		CaptureSource:            CaptureSource,
+		RemoteURL:                RemoteCaptureURL,
+		RemoteToken:              os.Getenv("REMOTE_TOKEN"),
+		CameraDevice:             CameraDevice,
+		CameraStableFrames:       CameraStableFrames,
+		ScreenRegion:             ScreenRegion,
+		ScreenBoardCorners:       ScreenBoardCorners,
+		KatrainURL:               KATRAIN_URL,
+		KatrainBackend:           KatrainBackend,
+		KatrainWindowTitle:       KatrainWindowTitle,
+		KatrainAuth:              katrainAuth(),
+		KatrainMirrors:           splitList(KatrainMirrors),
+		RelayBackend:             RelayBackend,
+		RelayAddr:                RelayAddr,
+		RelayUser:                os.Getenv("RELAY_USER"),
+		RelayPassword:            os.Getenv("RELAY_PASSWORD"),
+		KGSRoomID:                KGSRoomID,
+		NotifyErrorAfter:         NotifyErrorAfter,
+		DivergenceMoves:          DivergenceMoves,
+		MonitorDrift:             MonitorDrift,
+		DriftThreshold:           DriftThreshold,
+		DriftFrames:              DriftFrames,
+		EnableScrcpy:             EnableScrcpy,
+		ScrcpyArgs:               ScrcpyArgs,
+		ScrcpyReadyTimeout:       ScrcpyReadyTimeout,
+		HealthTimeout:            HealthTimeout,
+		KatrainTimeout:           KatrainTimeout,
+		ADBTimeout:               ADBTimeout,
+		OCRTimeout:               OCRTimeout,
+		BoardSkin:                BoardSkin,
+		MarkerExclusions:         MarkerExclusions,
+		SmoothFrames:             SmoothFrames,
+		SettleFrames:             SettleFrames,
+		AdaptToPhase:             AdaptToPhase,
+		OpeningBook:              OpeningBook,
+		OpeningBookFile:          OpeningBookFile,
+		BoardRotation:            BoardRotation,
+		CheckLabels:              CheckLabels,
+		Phone:                    adb.NewClient(ADBSerial),
+		Notifier:                 newNotifier(),
+		Hooks:                    newHooks(),
 	}
 }
 
-// func gridToScreen(gridX, gridY int) (int, int) {
-// 	boardLeft := 40
-// 	boardTop := 536
-// 	boardRight := 1160
-// 	boardBottom := 1650
-
-// 	boardWidth := boardRight - boardLeft
-// 	boardHeight := boardBottom - boardTop
-
-// 	cellW := float64(boardWidth) / 18.0
-// 	cellH := float64(boardHeight) / 18.0
-
-// 	screenX := boardLeft + int(float64(gridX)*cellW+cellW/2)
-// 	screenY := boardTop + int(float64(gridY)*cellH+cellH/2)
-
-// 	return screenX, screenY
-// }
-
-func gridToScreen(x, y int) (int, int) {
-	// 针对 1200x2670 分辨率的腾讯围棋 App 精确对齐
-	// x: KaTrain 的 X 坐标 (0-18)，0代表A线，18代表S线
-	// y: KaTrain 的 Y 坐标 (0-18)，0代表底部(19线)，18代表顶部(1线)
-
-	const (
-		// A线 (第1根纵线) 的中心 X 像素
-		startX = 60.0
-		// 1线 (第1根横线) 的中心 Y 像素
-		startY = 560.0
-		// 棋盘格子的精确间距 (像素)
-		gap = 60.0
-	)
-
-	// 计算 X 轴：从左向右增加
-	// 公式：起始点 + 索引 * 间距
-	screenX := startX + float64(x)*gap
-
-	// 计算 Y 轴：KaTrain 的 Y=0 是最下面，而屏幕坐标 Y 是从上往下算的
-	// 所以需要翻转：屏幕Y = 起始点 + (18 - KaTrainY) * 间距
-	screenY := startY + float64(18-y)*gap
-
-	// 打印一下，方便你在日志里核对
-	// fmt.Printf("[坐标转换] KaTrain(%d,%d) -> 屏幕(%d,%d)\n", x, y, int(screenX), int(screenY))
-
-	return int(screenX), int(screenY)
+// moveNumberPatterns 未配置 MoveNumberPattern 时返回 nil，使用内置规则
+func moveNumberPatterns() []string {
+	if MoveNumberPattern == "" {
+		return nil
+	}
+	return []string{MoveNumberPattern}
 }
 
-func tapOnPhone(gridX, gridY int) error {
-	// fmt.Printf("[%s] 🎯 准备落子: gridX:%d, gridY:%d\n", time.Now().Format("15:04:05"), gridX, gridY)
-
-	// 1. 计算棋盘落子点的屏幕坐标
-	screenX, screenY := gridToScreen(gridX, gridY)
+// ocrFallbacks 把逗号分隔的 OCRFallback 拆为服务列表
+func ocrFallbacks() []string {
+	return splitList(OCRFallback)
+}
 
-	adbPath, err := exec.LookPath("adb")
-	if err != nil {
-		return fmt.Errorf("未找到 adb: %v", err)
+// splitList 拆分逗号分隔的配置，去掉空项
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
+}
 
-	// 2. 执行第一次点击：移动落子指示标
-	cmd1 := exec.Command(adbPath, "shell", "input", "tap", fmt.Sprintf("%d", screenX), fmt.Sprintf("%d", screenY))
-	if err := cmd1.Run(); err != nil {
-		return fmt.Errorf("移动指示标失败: %v", err)
+// loadEnv 用环境变量覆盖配置
+func loadEnv() error {
+	applied, err := config.ApplyEnv(envFields())
+	if err != nil {
+		return err
 	}
-	// fmt.Printf("[%s] 📍 已移动指针到: (%d, %d)\n", time.Now().Format("15:04:05"), screenX, screenY)
-
-	// 3. 等待 300 毫秒，确保 App 反应过来了
-	time.Sleep(300 * time.Millisecond)
-
-	// 4. 执行第二次点击：点击“确认”按钮 (坐标 600, 2150)
-	confirmX, confirmY := 600, 2150
-	cmd2 := exec.Command(adbPath, "shell", "input", "tap", fmt.Sprintf("%d", confirmX), fmt.Sprintf("%d", confirmY))
-	if err := cmd2.Run(); err != nil {
-		return fmt.Errorf("点击确认按钮失败: %v", err)
+	// 写到标准错误，不混入 GTP 协议响应与 -systemd-unit 等生成的配置
+	if len(applied) > 0 {
+		fmt.Fprintf(os.Stderr, "⚙️  环境变量覆盖配置: %s\n", strings.Join(applied, ", "))
 	}
-
-	// 打印输出
-	xLetter := string(rune('A' + gridX)) // 修正字母显示逻辑
-	if xLetter >= "I" {
-		xLetter = string(rune('A' + gridX + 1))
-	} // 跳过 'I' 是某些棋盘的习惯，腾讯围棋通常不跳过
-
-	fmt.Printf("[%s] ✅ 落子成功！已点击“确认”按钮 (屏幕坐标: %d, %d)\n",
-		time.Now().Format("15:04:05"),
-		confirmX,
-		confirmY,
-	)
-
 	return nil
 }
-func syncPhoneToKatrain() {
-	ticker := time.NewTicker(Interval)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		screenshotPath, err := captureWithADB()
-		if err != nil {
-			fmt.Printf("[%s] 📸 截图失败: %v\n", time.Now().Format("15:04:05"), err)
-			continue
-		}
-
-		fmt.Printf("[%s] 📸 截图成功: %s\n", time.Now().Format("15:04:05"), screenshotPath)
-
-		result, err := recognizeWithVision(screenshotPath)
-		if err != nil {
-			fmt.Printf("[%s] ❌ 识别失败: %v\n", time.Now().Format("15:04:05"), err)
-			os.Remove(screenshotPath)
-			continue
-		}
 
-		fmt.Printf("[%s] ✅ 识别成功: 第 %d 手, 坐标: %d-%d, 颜色: %s\n",
-			time.Now().Format("15:04:05"),
-			result.Move,
-			result.X,
-			result.Y,
-			result.Color,
-		)
-
-		mu.Lock()
-		isNewFromPhone := (result.X != lastPhoneX || result.Y != lastPhoneY)
-		mu.Unlock()
-
-		if isNewFromPhone {
-			fmt.Printf("[%s] 🔄 检测到新手: %d > %d  X:%d  Y:%d\n", time.Now().Format("15:04:05"), result.Move, lastPhoneMove, result.X, result.Y)
-			colorForKatrain := result.Color
-			katrainX, katrainY := phoneGridToKatrain(result.X, result.Y)
-			hasStone, _, err := checkPosition(katrainX, katrainY)
-			if err != nil {
-				fmt.Printf("[%s] ❌ 检查位置失败: X:%d Y:%d %v\n", time.Now().Format("15:04:05"), katrainX, katrainY, err)
-			} else if !hasStone {
-				err := makeMove(katrainX, katrainY, colorForKatrain)
-				if err != nil {
-					fmt.Printf("[%s] ❌ 同步落子失败: %v\n", time.Now().Format("15:04:05"), err)
-				} else {
-					fmt.Printf("[%s] ✅ 手机→KaTrain: 第 %d 手 %s %s%d\n",
-						time.Now().Format("15:04:05"),
-						result.Move,
-						mapColorToChinese(colorForKatrain),
-						string(rune('A'+katrainX)),
-						katrainY+1,
-					)
-				}
-			} else {
-				fmt.Printf("[%s] ℹ️  KaTrain 已有棋子，跳过: %s%d\n",
-					time.Now().Format("15:04:05"),
-					string(rune('A'+katrainX)),
-				)
-			}
-
-			mu.Lock()
-			lastPhoneMove = result.Move
-			lastPhoneX = result.X
-			lastPhoneY = result.Y
-			mu.Unlock()
-		}
-
-		os.Remove(screenshotPath)
+// envFields 可由环境变量覆盖的配置项，键为去掉 config.Prefix 的变量名
+func envFields() map[string]any {
+	return map[string]any{
+		"WINDOW_TITLE":               &WindowTitle,
+		"INTERVAL":                   &Interval,
+		"IMAGE_DIR":                  &ImageDir,
+		"ADB_SERIAL":                 &ADBSerial,
+		"TARGET_W":                   &TargetW,
+		"TARGET_H":                   &TargetH,
+		"POLL_INTERVAL":              &POLL_INTERVAL,
+		"ENABLE_CLOCK_OCR":           &EnableClockOCR,
+		"OCR_ENDPOINT":               &OCREndpoint,
+		"OCR_BACKEND":                &OCRBackend,
+		"OCR_LANGUAGE":               &OCRLanguage,
+		"OCR_FALLBACK":               &OCRFallback,
+		"MOVE_NUMBER_PATTERN":        &MoveNumberPattern,
+		"OCR_PROFILE":                &OCRProfile,
+		"SPECTATOR":                  &SpectatorMode,
+		"TABLES":                     &Tables,
+		"TABLE_DWELL":                &TableDwell,
+		"TABLE_KATRAINS":             &TableKatrains,
+		"SYNC_TO_KATRAIN_COLORS":     &SyncToKatrainColors,
+		"SYNC_TO_PHONE_COLORS":       &SyncToPhoneColors,
+		"APPROVE_MOVES":              &ApproveMoves,
+		"DETECT_REVIEW":              &DetectReview,
+		"FUSE_SIGNALS":               &FuseSignals,
+		"PID_FILE":                   &PIDFile,
+		"LOG_DIR":                    &LogDir,
+		"LOG_MAX_MB":                 &LogMaxMB,
+		"LOG_MAX_FILES":              &LogMaxFiles,
+		"LIVE_VIEW":                  &LiveView,
+		"PRINT_BOARD":                &PrintBoard,
+		"RECORD_VIDEO":               &RecordVideo,
+		"VIDEO_FPS":                  &VideoFPS,
+		"OVERLAY_FILE":               &OverlayFile,
+		"OVERLAY_SIZE":               &OverlaySize,
+		"DEBUG_LEVEL":                &DebugLevel,
+		"DEBUG_DIR":                  &DebugDir,
+		"DEBUG_MAX_RUNS":             &DebugMaxRuns,
+		"DEBUG_MAX_MB":               &DebugMaxMB,
+		"STATS_FILE":                 &StatsFile,
+		"SAMPLE_DIR":                 &SampleDir,
+		"APP_PACKAGE":                &AppPackage,
+		"APP_ACTIVITY":               &AppActivity,
+		"WAKE_DEVICE":                &WakeDevice,
+		"TRACK_DEVICES":              &TrackDevices,
+		"RESUME_FLOW":                &ResumeFlow,
+		"COORDINATE_FLOW":            &CoordinateFlow,
+		"THROTTLE_BATTERY_BELOW":     &ThrottleBatteryBelow,
+		"THROTTLE_TEMPERATURE_ABOVE": &ThrottleTemperatureAbove,
+		"THROTTLE_INTERVAL":          &ThrottleInterval,
+		"DASHBOARD_ADDR":             &DashboardAddr,
+		"DASHBOARD_CERT_FILE":        &DashboardCertFile,
+		"DASHBOARD_KEY_FILE":         &DashboardKeyFile,
+		"STONE_TEMPLATE_DIR":         &StoneTemplateDir,
+		"BOARD_SKIN":                 &BoardSkin,
+		"MARKER_EXCLUSIONS":          &MarkerExclusions,
+		"SMOOTH_FRAMES":              &SmoothFrames,
+		"SETTLE_FRAMES":              &SettleFrames,
+		"ADAPT_TO_PHASE":             &AdaptToPhase,
+		"OPENING_BOOK":               &OpeningBook,
+		"OPENING_BOOK_FILE":          &OpeningBookFile,
+		"BOARD_ROTATION":             &BoardRotation,
+		"CHECK_LABELS":               &CheckLabels,
+		"CONFIRM_TEMPLATE":           &ConfirmTemplate,
+		"CAPTURE_SOURCE":             &CaptureSource,
+		"REMOTE_CAPTURE_URL":         &RemoteCaptureURL,
+		"CAPTURE_NODE_ADDR":          &CaptureNodeAddr,
+		"CAPTURE_NODE_QUALITY":       &CaptureNodeQuality,
+		"CAMERA_DEVICE":              &CameraDevice,
+		"CAMERA_STABLE_FRAMES":       &CameraStableFrames,
+		"KATRAIN_URL":                &KATRAIN_URL,
+		"KATRAIN_BACKEND":            &KatrainBackend,
+		"KATRAIN_CA_FILE":            &KatrainCAFile,
+		"KATRAIN_MIRRORS":            &KatrainMirrors,
+		"ANALYSIS_VISITS":            &AnalysisVisits,
+		"ANALYSIS_TIME":              &AnalysisTime,
+		"ATTACH_MID_GAME":            &AttachMidGame,
+		"DEAD_STONE_SCORING":         &DeadStoneScoring,
+		"DEAD_MARK_SIZE":             &DeadMarkSize,
+		"DEAD_MARK_CONTRAST":         &DeadMarkContrast,
+		"BLUNDER_THRESHOLD":          &BlunderThreshold,
+		"BLUNDER_COLORS":             &BlunderColors,
+		"BLUNDER_SOUND":              &BlunderSound,
+		"RELAY_BACKEND":              &RelayBackend,
+		"RELAY_ADDR":                 &RelayAddr,
+		"KGS_ROOM_ID":                &KGSRoomID,
+		"NOTIFY_ERROR_AFTER":         &NotifyErrorAfter,
+		"HOOK_URL":                   &HookURL,
+		"HOOK_COMMAND":               &HookCommand,
+		"DIVERGENCE_MOVES":           &DivergenceMoves,
+		"MONITOR_DRIFT":              &MonitorDrift,
+		"DRIFT_THRESHOLD":            &DriftThreshold,
+		"DRIFT_FRAMES":               &DriftFrames,
+		"ENABLE_SCRCPY":              &EnableScrcpy,
+		"DOCKER":                     &DockerMode,
+		"DOCKER_DATA_DIR":            &DockerDataDir,
+		"HEALTH_TIMEOUT":             &HealthTimeout,
+		"KATRAIN_TIMEOUT":            &KatrainTimeout,
+		"ADB_TIMEOUT":                &ADBTimeout,
+		"OCR_TIMEOUT":                &OCRTimeout,
+		"BOARD_START_X":              &BoardStartX,
+		"BOARD_START_Y":              &BoardStartY,
+		"BOARD_GAP":                  &BoardGap,
+		"CONFIRM_X":                  &ConfirmX,
+		"CONFIRM_Y":                  &ConfirmY,
+		"TAP_DELAY":                  &TapDelay,
+		"CONFIRM_TIMEOUT":            &ConfirmTimeout,
+		"TAP_RETRIES":                &TapRetries,
+		"TAP_METHOD":                 &TapMethod,
+		"TOUCH_DEVICE":               &TouchDevice,
+		"COMPANION":                  &Companion,
+		"COMPANION_PORT":             &CompanionPort,
+		"UI_DUMP_INTERVAL":           &UIDumpInterval,
+		"UI_MOVE_NUMBER_ID":          &UIMoveNumberID,
+		"CONFIG_FILE":                &ConfigFile,
 	}
 }
 
-func phoneGridToKatrain(x, y int) (katrainX int, katrainY int) {
-	katrainX = x - 1
-	katrainY = 19 - y
-	return
+// katrainAuth 访问 KaTrain 的认证信息，密钥与密码只从不带前缀的环境变量读取，不写进生成的服务配置
+func katrainAuth() katrain.Auth {
+	return katrain.Auth{
+		APIKey:   os.Getenv("KATRAIN_API_KEY"),
+		Username: os.Getenv("KATRAIN_USER"),
+		Password: os.Getenv("KATRAIN_PASSWORD"),
+		CAFile:   KatrainCAFile,
+	}
 }
-func syncKatrainToPhone() {
-	ticker := time.NewTicker(POLL_INTERVAL)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		x, y, _, moveNumber, err := getLastMove()
-		fmt.Printf("[%s] ✅ 获取 KaTrain 最后一手: X:%d Y:%d (手数: %d)\n",
-			time.Now().Format("15:04:05"),
-			x,
-			y,
-			moveNumber,
-		)
-		if err != nil {
-			fmt.Printf("[%s] ❌ 获取 KaTrain 最后一手失败: %v\n", time.Now().Format("15:04:05"), err)
-			continue
-		}
-
-		if moveNumber == 0 {
-			continue
-		}
-
-		mu.Lock()
-		isNewFromKatrain := (x != lastKatrainX || y != lastKatrainY)
-		mu.Unlock()
 
-		if isNewFromKatrain {
-			err := tapOnPhone(x, y)
-			if err != nil {
-				fmt.Printf("[%s] ❌ 手机点击失败: %v\n", time.Now().Format("15:04:05"), err)
-			}
+// dashboardAuth 看板的访问控制，与 katrainAuth 一样只从不带前缀的环境变量读取
+func dashboardAuth() dashboard.Auth {
+	return dashboard.Auth{
+		Token:    os.Getenv("DASHBOARD_TOKEN"),
+		Username: os.Getenv("DASHBOARD_USER"),
+		Password: os.Getenv("DASHBOARD_PASSWORD"),
+	}
+}
 
-			mu.Lock()
-			lastKatrainMove = moveNumber
-			lastKatrainX = x
-			lastKatrainY = y
-			mu.Unlock()
-		}
+// newHooks 按 HookURL、HookCommand 创建外部扩展
+func newHooks() []hooks.Named {
+	var named []hooks.Named
+	if HookURL != "" {
+		named = append(named, hooks.Named{Name: "webhook", Hook: hooks.NewWebhook(HookURL)})
+	}
+	if fields := strings.Fields(HookCommand); len(fields) > 0 {
+		named = append(named, hooks.Named{Name: fields[0], Hook: hooks.NewExec(fields[0], fields[1:]...)})
 	}
+	return named
 }
 
-func mapColorToChinese(color string) string {
-	if color == "B" {
-		return "黑棋"
+// newNotifier 按环境变量创建通知渠道，均未配置时返回 nil
+func newNotifier() notify.Notifier {
+	var notifiers notify.Multi
+	if url := os.Getenv("DISCORD_WEBHOOK_URL"); url != "" {
+		notifiers = append(notifiers, notify.NewDiscord(url))
+	}
+	if token, chat := os.Getenv("TELEGRAM_BOT_TOKEN"), os.Getenv("TELEGRAM_CHAT_ID"); token != "" && chat != "" {
+		notifiers = append(notifiers, notify.NewTelegram(token, chat))
+	}
+	if url := os.Getenv("NOTIFY_WEBHOOK_URL"); url != "" {
+		notifiers = append(notifiers, notify.NewWebhook(url))
+	}
+	if len(notifiers) == 0 {
+		return nil
 	}
-	return "白棋"
+	return notifiers
 }
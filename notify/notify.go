@@ -0,0 +1,176 @@
+// Package notify 把同步事件（开始同步、持续出错、双方局面不一致、手机断开与重连、同步循环崩溃、对局结束）推送到
+// Discord、Telegram 或任意 webhook。
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Kind 事件类型
+type Kind string
+
+const (
+	SyncStarted        Kind = "sync_started"
+	ErrorPersist       Kind = "error_persist"
+	Divergence         Kind = "divergence"
+	GameEnded          Kind = "game_ended"
+	DeviceDisconnected Kind = "device_disconnected"
+	DeviceReconnected  Kind = "device_reconnected"
+	Drift              Kind = "drift"
+	Blunder            Kind = "blunder"
+	LoopPanic          Kind = "loop_panic"
+)
+
+// Event 一次通知
+type Event struct {
+	Kind    Kind      `json:"kind"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// Text 推送到聊天软件的文本
+func (e Event) Text() string {
+	return fmt.Sprintf("[goboardsync %s] %s", e.Time.Format("15:04:05"), e.Message)
+}
+
+// Notifier 通知渠道
+type Notifier interface {
+	Notify(e Event) error
+}
+
+// Multi 依次推送到多个渠道，返回第一个错误
+type Multi []Notifier
+
+func (m Multi) Notify(e Event) error {
+	var first error
+	for _, n := range m {
+		if err := n.Notify(e); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Discord 通过频道 webhook 推送
+type Discord struct {
+	WebhookURL string
+	HTTPClient *http.Client
+}
+
+func NewDiscord(webhookURL string) *Discord {
+	return &Discord{WebhookURL: webhookURL, HTTPClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (d *Discord) Notify(e Event) error {
+	return postJSON(d.HTTPClient, d.WebhookURL, map[string]string{"content": e.Text()})
+}
+
+// Telegram 通过 Bot API 推送到指定会话
+type Telegram struct {
+	APIBase    string
+	Token      string
+	ChatID     string
+	HTTPClient *http.Client
+}
+
+func NewTelegram(token, chatID string) *Telegram {
+	return &Telegram{
+		APIBase:    "https://api.telegram.org",
+		Token:      token,
+		ChatID:     chatID,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (t *Telegram) Notify(e Event) error {
+	url := fmt.Sprintf("%s/bot%s/sendMessage", t.APIBase, t.Token)
+	return postJSON(t.HTTPClient, url, map[string]string{"chat_id": t.ChatID, "text": e.Text()})
+}
+
+// Webhook 把事件原样以 JSON POST 到任意地址
+type Webhook struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+func NewWebhook(url string) *Webhook {
+	return &Webhook{URL: url, HTTPClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *Webhook) Notify(e Event) error {
+	return postJSON(w.HTTPClient, w.URL, e)
+}
+
+func postJSON(client *http.Client, url string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("推送通知失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("推送通知失败: HTTP %d %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// ErrorTracker 记录各环节（截图、识别、KaTrain 等）连续出错的起始时间，
+// 持续超过 Threshold 时只提醒一次，恢复正常后重新计时
+type ErrorTracker struct {
+	Threshold time.Duration
+
+	mu      sync.Mutex
+	since   map[string]time.Time
+	alerted map[string]bool
+	now     func() time.Time
+}
+
+func NewErrorTracker(threshold time.Duration) *ErrorTracker {
+	return &ErrorTracker{
+		Threshold: threshold,
+		since:     make(map[string]time.Time),
+		alerted:   make(map[string]bool),
+		now:       time.Now,
+	}
+}
+
+// Fail 记录一次出错，返回是否需要提醒以及已持续的时长
+func (t *ErrorTracker) Fail(key string) (bool, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	start, ok := t.since[key]
+	if !ok {
+		t.since[key] = now
+		start = now
+	}
+
+	elapsed := now.Sub(start)
+	if elapsed < t.Threshold || t.alerted[key] {
+		return false, elapsed
+	}
+	t.alerted[key] = true
+	return true, elapsed
+}
+
+// OK 记录一次成功，清除该环节的出错状态
+func (t *ErrorTracker) OK(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.since, key)
+	delete(t.alerted, key)
+}
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotifiers(t *testing.T) {
+	var paths []string
+	var bodies []map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		paths = append(paths, r.URL.Path)
+		bodies = append(bodies, body)
+		if strings.Contains(r.URL.Path, "broken") {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	telegram := NewTelegram("TOKEN", "42")
+	telegram.APIBase = server.URL
+
+	event := Event{Kind: GameEnded, Message: "对局结束", Time: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	err := Multi{
+		NewDiscord(server.URL + "/discord"),
+		telegram,
+		NewWebhook(server.URL + "/hook"),
+	}.Notify(event)
+	if err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+
+	if len(bodies) != 3 {
+		t.Fatalf("收到 %d 次推送, want 3", len(bodies))
+	}
+	if bodies[0]["content"] != "[goboardsync 12:00:00] 对局结束" {
+		t.Errorf("Discord content = %v", bodies[0]["content"])
+	}
+	if paths[1] != "/botTOKEN/sendMessage" || bodies[1]["chat_id"] != "42" {
+		t.Errorf("Telegram 请求 = %s %v", paths[1], bodies[1])
+	}
+	if bodies[2]["kind"] != string(GameEnded) || bodies[2]["message"] != "对局结束" {
+		t.Errorf("Webhook body = %v", bodies[2])
+	}
+
+	if err := NewWebhook(server.URL + "/broken").Notify(event); err == nil {
+		t.Errorf("HTTP 400 时 Notify() 应返回错误")
+	}
+}
+
+func TestErrorTracker(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewErrorTracker(30 * time.Second)
+	tracker.now = func() time.Time { return now }
+
+	steps := []struct {
+		advance time.Duration
+		ok      bool
+		alert   bool
+	}{
+		{0, false, false},
+		{20 * time.Second, false, false},
+		{15 * time.Second, false, true},  // 持续 35 秒，提醒
+		{10 * time.Second, false, false}, // 已提醒过
+		{0, true, false},                 // 恢复
+		{5 * time.Second, false, false},  // 重新计时
+		{30 * time.Second, false, true},
+	}
+
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		if step.ok {
+			tracker.OK("capture")
+			continue
+		}
+		if alert, _ := tracker.Fail("capture"); alert != step.alert {
+			t.Errorf("步骤 %d: Fail() = %v, want %v", i, alert, step.alert)
+		}
+	}
+}
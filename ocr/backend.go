@@ -0,0 +1,397 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Backend 一种 OCR 服务的请求与响应格式
+type Backend struct {
+	Name string
+	// Cloud 是否为需要密钥的云端服务
+	Cloud bool
+	// DefaultEndpoint Client.Endpoint 为空时使用的地址，本地服务没有默认地址
+	DefaultEndpoint string
+	// NewRequest 按 c 的地址、语言与密钥把 JPG 图片编码为请求，语言为空时使用服务的默认语言；
+	// 请求（及换取密钥等附带的请求）在 ctx 取消或超时后中止
+	NewRequest func(ctx context.Context, c *Client, jpg []byte) (*http.Request, error)
+	// Parse 从响应中取出识别出的全部文本，多段文本以空格分隔
+	Parse func(body []byte) (string, error)
+}
+
+// Backends 支持的 OCR 服务，键为配置中使用的名称
+var Backends = map[string]Backend{
+	// multipart 本项目附带的本地 OCR 服务：表单字段 file 上传图片，
+	// 返回 [{"words": ...}] 或 {"results": [{"words": ...}]}，其他内容按纯文本处理
+	"multipart": {Name: "multipart", NewRequest: newMultipartRequest, Parse: parseWords},
+	// paddleocr PaddleHub Serving 的 ocr_system 模块，语言由部署的模型决定
+	"paddleocr": {Name: "paddleocr", NewRequest: newPaddleRequest, Parse: parsePaddle},
+	// umi-ocr Umi-OCR 的 HTTP 接口（/api/ocr），language 为其 ocr.language 参数，如 models/config_chinese.txt
+	"umi-ocr": {Name: "umi-ocr", NewRequest: newUmiRequest, Parse: parseUmi},
+	// baidu 百度智能云通用文字识别，language 为 language_type，如 CHN_ENG。
+	// 配置了 APIKey 与 SecretKey 时自动获取 access_token，否则 endpoint 需自带 access_token
+	"baidu": {
+		Name:            "baidu",
+		Cloud:           true,
+		DefaultEndpoint: "https://aip.baidubce.com/rest/2.0/ocr/v1/general_basic",
+		NewRequest:      newBaiduRequest,
+		Parse:           parseBaidu,
+	},
+	// google Google Cloud Vision 的 TEXT_DETECTION，APIKey 为 API 密钥，language 为 languageHints，如 zh
+	"google": {
+		Name:            "google",
+		Cloud:           true,
+		DefaultEndpoint: "https://vision.googleapis.com/v1/images:annotate",
+		NewRequest:      newGoogleRequest,
+		Parse:           parseGoogle,
+	},
+	// azure Azure AI Vision 的同步 OCR 接口，endpoint 为资源地址（如 https://NAME.cognitiveservices.azure.com），
+	// APIKey 为订阅密钥，language 如 zh-Hans，为空时自动检测
+	"azure": {Name: "azure", Cloud: true, NewRequest: newAzureRequest, Parse: parseAzure},
+}
+
+// DefaultBackend 未指定时使用的 OCR 服务格式
+var DefaultBackend = Backends["multipart"]
+
+// BackendByName 按名称查找 OCR 服务格式，为空时返回 DefaultBackend
+func BackendByName(name string) (Backend, error) {
+	if name == "" {
+		return DefaultBackend, nil
+	}
+	if b, ok := Backends[name]; ok {
+		return b, nil
+	}
+
+	names := make([]string, 0, len(Backends))
+	for n := range Backends {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return Backend{}, fmt.Errorf("未知的 OCR 服务: %s（可选 %s）", name, strings.Join(names, "、"))
+}
+
+func newMultipartRequest(ctx context.Context, c *Client, jpg []byte) (*http.Request, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	part, err := writer.CreateFormFile("file", "image.jpg")
+	if err != nil {
+		return nil, fmt.Errorf("创建表单文件失败: %v", err)
+	}
+	if _, err := part.Write(jpg); err != nil {
+		return nil, fmt.Errorf("写入图片数据失败: %v", err)
+	}
+	if c.Language != "" {
+		writer.WriteField("lang", c.Language)
+	}
+	writer.Close()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint(), body)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req, nil
+}
+
+// parseWords 兼容 [{"words": ...}] 与 {"results": [{"words": ...}]} 两种格式，
+// 都不匹配时按纯文本处理
+func parseWords(respData []byte) (string, error) {
+	var allText strings.Builder
+
+	var results []struct {
+		Words string `json:"words"`
+	}
+	err := json.Unmarshal(respData, &results)
+	if err == nil && len(results) > 0 {
+		for _, r := range results {
+			allText.WriteString(r.Words)
+			allText.WriteString(" ")
+		}
+	} else {
+		var wrapper struct {
+			Results []struct {
+				Words string `json:"words"`
+			} `json:"results"`
+		}
+		if err2 := json.Unmarshal(respData, &wrapper); err2 == nil && len(wrapper.Results) > 0 {
+			for _, r := range wrapper.Results {
+				allText.WriteString(r.Words)
+				allText.WriteString(" ")
+			}
+		} else {
+			allText.WriteString(string(respData))
+		}
+	}
+
+	return strings.TrimSpace(allText.String()), nil
+}
+
+func newJSONRequest(ctx context.Context, endpoint string, payload any) (*http.Request, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func newPaddleRequest(ctx context.Context, c *Client, jpg []byte) (*http.Request, error) {
+	return newJSONRequest(ctx, c.endpoint(), map[string]any{"images": []string{base64.StdEncoding.EncodeToString(jpg)}})
+}
+
+// parsePaddle 解析 {"status": "000", "msg": "", "results": [[{"text": ...}]]}，results 每项对应一张图片
+func parsePaddle(body []byte) (string, error) {
+	var resp struct {
+		Status  string `json:"status"`
+		Msg     string `json:"msg"`
+		Results [][]struct {
+			Text string `json:"text"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("解析 PaddleOCR 响应失败: %s", string(body))
+	}
+	if resp.Status != "" && resp.Status != "000" {
+		return "", fmt.Errorf("PaddleOCR 错误: %s %s", resp.Status, resp.Msg)
+	}
+
+	var texts []string
+	for _, image := range resp.Results {
+		for _, r := range image {
+			texts = append(texts, r.Text)
+		}
+	}
+	return strings.Join(texts, " "), nil
+}
+
+func newUmiRequest(ctx context.Context, c *Client, jpg []byte) (*http.Request, error) {
+	payload := map[string]any{"base64": base64.StdEncoding.EncodeToString(jpg)}
+	if c.Language != "" {
+		payload["options"] = map[string]any{"ocr.language": c.Language}
+	}
+	return newJSONRequest(ctx, c.endpoint(), payload)
+}
+
+// parseUmi 解析 {"code": 100, "data": [{"text": ...}]}；code 101 表示图中没有文字，
+// 其他 code 时 data 为错误信息
+func parseUmi(body []byte) (string, error) {
+	var resp struct {
+		Code int             `json:"code"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("解析 Umi-OCR 响应失败: %s", string(body))
+	}
+
+	switch resp.Code {
+	case 100:
+		var data []struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			return "", fmt.Errorf("解析 Umi-OCR 响应失败: %s", string(body))
+		}
+		texts := make([]string, len(data))
+		for i, d := range data {
+			texts[i] = d.Text
+		}
+		return strings.Join(texts, " "), nil
+	case 101:
+		return "", nil
+	}
+	return "", fmt.Errorf("Umi-OCR 错误 %d: %s", resp.Code, string(resp.Data))
+}
+
+func newBaiduRequest(ctx context.Context, c *Client, jpg []byte) (*http.Request, error) {
+	endpoint := c.endpoint()
+	if c.APIKey != "" && c.SecretKey != "" {
+		token, err := c.baiduToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		endpoint = withQuery(endpoint, "access_token", token)
+	}
+
+	form := url.Values{"image": {base64.StdEncoding.EncodeToString(jpg)}}
+	if c.Language != "" {
+		form.Set("language_type", c.Language)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
+
+// parseBaidu 解析 {"words_result": [{"words": ...}]}，出错时响应为 {"error_code": ..., "error_msg": ...}
+func parseBaidu(body []byte) (string, error) {
+	var resp struct {
+		ErrorCode   int    `json:"error_code"`
+		ErrorMsg    string `json:"error_msg"`
+		WordsResult []struct {
+			Words string `json:"words"`
+		} `json:"words_result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("解析百度 OCR 响应失败: %s", string(body))
+	}
+	if resp.ErrorCode != 0 {
+		return "", fmt.Errorf("百度 OCR 错误 %d: %s", resp.ErrorCode, resp.ErrorMsg)
+	}
+
+	texts := make([]string, len(resp.WordsResult))
+	for i, w := range resp.WordsResult {
+		texts[i] = w.Words
+	}
+	return strings.Join(texts, " "), nil
+}
+
+// baiduTokenURL 百度 OAuth 接口，测试时替换
+var baiduTokenURL = "https://aip.baidubce.com/oauth/2.0/token"
+
+// baiduToken 用 APIKey、SecretKey 换取 access_token，有效期内复用，提前一小时刷新
+func (c *Client) baiduToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}, "client_id": {c.APIKey}, "client_secret": {c.SecretKey}}
+	req, err := http.NewRequestWithContext(ctx, "POST", baiduTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("获取百度 access_token 失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("解析百度 access_token 响应失败: %v", err)
+	}
+	if result.AccessToken == "" {
+		return "", fmt.Errorf("获取百度 access_token 失败: %s %s", result.Error, result.ErrorDescription)
+	}
+
+	c.token = result.AccessToken
+	c.tokenExpiry = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - time.Hour)
+	return c.token, nil
+}
+
+func newGoogleRequest(ctx context.Context, c *Client, jpg []byte) (*http.Request, error) {
+	request := map[string]any{
+		"image":    map[string]string{"content": base64.StdEncoding.EncodeToString(jpg)},
+		"features": []map[string]string{{"type": "TEXT_DETECTION"}},
+	}
+	if c.Language != "" {
+		request["imageContext"] = map[string]any{"languageHints": []string{c.Language}}
+	}
+	return newJSONRequest(ctx, withQuery(c.endpoint(), "key", c.APIKey), map[string]any{"requests": []any{request}})
+}
+
+// parseGoogle 解析 {"responses": [{"fullTextAnnotation": {"text": ...}}]}，出错时该项带 error
+func parseGoogle(body []byte) (string, error) {
+	var resp struct {
+		Responses []struct {
+			FullTextAnnotation struct {
+				Text string `json:"text"`
+			} `json:"fullTextAnnotation"`
+			Error *struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"responses"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("解析 Google Vision 响应失败: %s", string(body))
+	}
+
+	var texts []string
+	for _, r := range resp.Responses {
+		if r.Error != nil {
+			return "", fmt.Errorf("Google Vision 错误 %d: %s", r.Error.Code, r.Error.Message)
+		}
+		texts = append(texts, strings.Fields(r.FullTextAnnotation.Text)...)
+	}
+	return strings.Join(texts, " "), nil
+}
+
+func newAzureRequest(ctx context.Context, c *Client, jpg []byte) (*http.Request, error) {
+	if c.endpoint() == "" {
+		return nil, fmt.Errorf("未配置 Azure 资源地址")
+	}
+	endpoint := strings.TrimSuffix(c.endpoint(), "/") + "/vision/v3.2/ocr"
+	if c.Language != "" {
+		endpoint = withQuery(endpoint, "language", c.Language)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(jpg))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Ocp-Apim-Subscription-Key", c.APIKey)
+	return req, nil
+}
+
+// parseAzure 解析 {"regions": [{"lines": [{"words": [{"text": ...}]}]}]}，每行的词直接相连
+func parseAzure(body []byte) (string, error) {
+	var resp struct {
+		Regions []struct {
+			Lines []struct {
+				Words []struct {
+					Text string `json:"text"`
+				} `json:"words"`
+			} `json:"lines"`
+		} `json:"regions"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("解析 Azure 响应失败: %s", string(body))
+	}
+
+	var lines []string
+	for _, region := range resp.Regions {
+		for _, line := range region.Lines {
+			var words []string
+			for _, w := range line.Words {
+				words = append(words, w.Text)
+			}
+			// 中文按字切分，英文按词切分；拼回时不加空格，手数正则允许数字前后没有空格
+			lines = append(lines, strings.Join(words, ""))
+		}
+	}
+	return strings.Join(lines, " "), nil
+}
+
+// withQuery 给 rawURL 加上查询参数
+func withQuery(rawURL, key, value string) string {
+	sep := "?"
+	if strings.Contains(rawURL, "?") {
+		sep = "&"
+	}
+	return rawURL + sep + url.QueryEscape(key) + "=" + url.QueryEscape(value)
+}
@@ -0,0 +1,161 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrQuotaExceeded 请求次数已达 Quota 上限
+var ErrQuotaExceeded = errors.New("OCR 请求次数已达上限")
+
+// Quota 请求次数上限，按分钟、按天（UTC）计数，0 表示不限。
+// 云端服务通常按次计费或有免费额度，用它避免每 100ms 一次的截图识别把额度用完
+type Quota struct {
+	PerMinute int
+	PerDay    int
+}
+
+type quotaUsage struct {
+	minute, day         time.Time
+	minuteUsed, dayUsed int
+}
+
+// take 在额度内时记一次请求并返回 true
+func (c *Client) take(now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	minute, day := now.Truncate(time.Minute), now.Truncate(24*time.Hour)
+	if !c.used.minute.Equal(minute) {
+		c.used.minute, c.used.minuteUsed = minute, 0
+	}
+	if !c.used.day.Equal(day) {
+		c.used.day, c.used.dayUsed = day, 0
+	}
+	if c.Quota.PerMinute > 0 && c.used.minuteUsed >= c.Quota.PerMinute {
+		return false
+	}
+	if c.Quota.PerDay > 0 && c.used.dayUsed >= c.Quota.PerDay {
+		return false
+	}
+	c.used.minuteUsed++
+	c.used.dayUsed++
+	return true
+}
+
+// Credentials 云端 OCR 服务的密钥，只应从环境变量读取
+type Credentials struct {
+	BaiduAPIKey    string
+	BaiduSecretKey string
+	GoogleAPIKey   string
+	AzureKey       string
+	// AzureEndpoint Azure AI Vision 资源地址，如 https://NAME.cognitiveservices.azure.com
+	AzureEndpoint string
+}
+
+// Chain 按顺序尝试的一组 OCR 服务：前一个出错或额度用完时换下一个，
+// 适合本地服务为主、云端服务兜底，或只用几个免费额度有限的云端服务
+type Chain struct {
+	Clients []*Client
+}
+
+// NewChain 按 specs 的顺序创建 OCR 服务链。spec 为 "名称" 或 "名称:每分钟上限/每天上限"，
+// 如 "baidu:30/500"，上限写 0 或省略表示不限。endpoint 与 language 只用于第一个服务；
+// 其余服务只能是云端服务（baidu、google、azure），使用默认地址并由服务自动判断语言。
+// 云端服务的密钥取自 creds，第一个服务是百度且 endpoint 已带 access_token 时可以不配密钥
+func NewChain(specs []string, endpoint, language string, creds Credentials) (*Chain, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("未配置 OCR 服务")
+	}
+
+	chain := &Chain{}
+	for i, spec := range specs {
+		name, quota, err := ParseSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		backend, err := BackendByName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		c := &Client{
+			Backend:    backend,
+			Quota:      quota,
+			HTTPClient: &http.Client{},
+		}
+		if i == 0 {
+			c.Endpoint, c.Language = endpoint, language
+		}
+		switch backend.Name {
+		case "baidu":
+			c.APIKey, c.SecretKey = creds.BaiduAPIKey, creds.BaiduSecretKey
+			if (c.APIKey == "" || c.SecretKey == "") && !strings.Contains(c.Endpoint, "access_token=") {
+				return nil, fmt.Errorf("使用百度 OCR 需设置 BAIDU_OCR_API_KEY 与 BAIDU_OCR_SECRET_KEY")
+			}
+		case "google":
+			c.APIKey = creds.GoogleAPIKey
+			if c.APIKey == "" {
+				return nil, fmt.Errorf("使用 Google Vision 需设置 GOOGLE_VISION_API_KEY")
+			}
+		case "azure":
+			c.APIKey = creds.AzureKey
+			if creds.AzureEndpoint != "" {
+				c.Endpoint = creds.AzureEndpoint
+			}
+			if c.APIKey == "" || c.Endpoint == "" {
+				return nil, fmt.Errorf("使用 Azure OCR 需设置 AZURE_VISION_KEY 与 AZURE_VISION_ENDPOINT")
+			}
+		}
+		if !backend.Cloud && i > 0 {
+			return nil, fmt.Errorf("本地 OCR 服务 %s 只能作为第一个服务", backend.Name)
+		}
+		chain.Clients = append(chain.Clients, c)
+	}
+	return chain, nil
+}
+
+// ParseSpec 解析 "名称[:每分钟上限/每天上限]"
+func ParseSpec(spec string) (string, Quota, error) {
+	name, limits, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	if limits == "" {
+		return name, Quota{}, nil
+	}
+
+	perMinute, perDay, _ := strings.Cut(limits, "/")
+	var quota Quota
+	var err error
+	if perMinute != "" {
+		if quota.PerMinute, err = strconv.Atoi(perMinute); err != nil || quota.PerMinute < 0 {
+			return "", Quota{}, fmt.Errorf("OCR 服务 %q 的每分钟上限无效", spec)
+		}
+	}
+	if perDay != "" {
+		if quota.PerDay, err = strconv.Atoi(perDay); err != nil || quota.PerDay < 0 {
+			return "", Quota{}, fmt.Errorf("OCR 服务 %q 的每天上限无效", spec)
+		}
+	}
+	return name, quota, nil
+}
+
+// Recognize 依次尝试各服务，返回第一个成功的结果；全部失败时返回各服务的错误。每个服务各自按 Timeout 超时，
+// ctx 取消后不再尝试后面的服务
+func (ch *Chain) Recognize(ctx context.Context, jpg []byte) (string, error) {
+	var errs []string
+	for _, c := range ch.Clients {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		text, err := c.Recognize(ctx, jpg)
+		if err == nil {
+			return text, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", c.backend().Name, err))
+	}
+	return "", fmt.Errorf("所有 OCR 服务均失败: %s", strings.Join(errs, "; "))
+}
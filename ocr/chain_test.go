@@ -0,0 +1,153 @@
+package ocr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		spec      string
+		name      string
+		quota     Quota
+		shouldErr bool
+	}{
+		{spec: "umi-ocr", name: "umi-ocr"},
+		{spec: " baidu:30/500 ", name: "baidu", quota: Quota{PerMinute: 30, PerDay: 500}},
+		{spec: "google:/1000", name: "google", quota: Quota{PerDay: 1000}},
+		{spec: "azure:20", name: "azure", quota: Quota{PerMinute: 20}},
+		{spec: "baidu:x/1", shouldErr: true},
+		{spec: "baidu:1/-1", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			name, quota, err := ParseSpec(tt.spec)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("ParseSpec(%q) expected error", tt.spec)
+				}
+				return
+			}
+			if err != nil || name != tt.name || quota != tt.quota {
+				t.Errorf("ParseSpec(%q) = %q, %+v, %v, want %q, %+v", tt.spec, name, quota, err, tt.name, tt.quota)
+			}
+		})
+	}
+}
+
+func TestQuota(t *testing.T) {
+	c := &Client{Quota: Quota{PerMinute: 2, PerDay: 3}}
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		at   time.Duration
+		want bool
+	}{
+		{0, true},
+		{10 * time.Second, true},
+		{20 * time.Second, false}, // 本分钟已用 2 次
+		{time.Minute, true},
+		{time.Minute + time.Second, false}, // 当天已用 3 次
+		{24 * time.Hour, true},
+	}
+	for _, step := range steps {
+		if got := c.take(start.Add(step.at)); got != step.want {
+			t.Errorf("take(+%v) = %v, want %v", step.at, got, step.want)
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	var localHits, cloudHits int
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		localHits++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer local.Close()
+	cloud := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cloudHits++
+		w.Write([]byte(`{"language": "zh-Hans", "regions": [{"lines": [{"words": [{"text": "第"}, {"text": "3"}, {"text": "手"}]}]}]}`))
+	}))
+	defer cloud.Close()
+
+	chain, err := NewChain([]string{"paddleocr", "azure:0/1"}, local.URL, "", Credentials{AzureKey: "k", AzureEndpoint: cloud.URL})
+	if err != nil {
+		t.Fatalf("NewChain() error = %v", err)
+	}
+
+	text, err := chain.Recognize(context.Background(), []byte("jpg"))
+	if err != nil || text != "第3手" {
+		t.Fatalf("Recognize() = %q, %v, want 第3手", text, err)
+	}
+
+	// 本地服务仍不可用，云端当天额度已用完
+	_, err = chain.Recognize(context.Background(), []byte("jpg"))
+	if err == nil || !strings.Contains(err.Error(), ErrQuotaExceeded.Error()) {
+		t.Errorf("Recognize() error = %v, want 额度用完", err)
+	}
+	if localHits != 2 || cloudHits != 1 {
+		t.Errorf("请求次数 本地 %d 云端 %d, want 2 1", localHits, cloudHits)
+	}
+
+	if _, err := NewChain([]string{"google"}, "", "", Credentials{}); err == nil {
+		t.Error("缺少 Google 密钥时 NewChain() 应返回错误")
+	}
+	if _, err := NewChain([]string{"baidu", "umi-ocr"}, "", "", Credentials{BaiduAPIKey: "ak", BaiduSecretKey: "sk"}); err == nil {
+		t.Error("本地服务作为后备时 NewChain() 应返回错误")
+	}
+	if _, err := NewChain([]string{"baidu"}, "https://aip.baidubce.com/rest/2.0/ocr/v1/general_basic?access_token=tok", "", Credentials{}); err != nil {
+		t.Errorf("地址已带 access_token 时 NewChain() error = %v", err)
+	}
+}
+
+func TestBaiduToken(t *testing.T) {
+	var tokenRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/2.0/token":
+			tokenRequests++
+			if r.FormValue("client_id") != "ak" || r.FormValue("client_secret") != "sk" {
+				w.Write([]byte(`{"error": "invalid_client", "error_description": "unknown client id"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token": "tok", "expires_in": 2592000}`))
+		default:
+			if r.URL.Query().Get("access_token") != "tok" {
+				w.Write([]byte(`{"error_code": 110, "error_msg": "Access token invalid or no longer valid"}`))
+				return
+			}
+			w.Write([]byte(`{"words_result": [{"words": "第 5 手"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	saved := baiduTokenURL
+	baiduTokenURL = server.URL + "/oauth/2.0/token"
+	defer func() { baiduTokenURL = saved }()
+
+	client := NewClient(server.URL + "/rest/2.0/ocr/v1/general_basic")
+	client.Backend = Backends["baidu"]
+	client.APIKey, client.SecretKey = "ak", "sk"
+
+	for i := 0; i < 2; i++ {
+		text, err := client.Recognize(context.Background(), []byte("jpg"))
+		if err != nil || text != "第 5 手" {
+			t.Fatalf("Recognize() = %q, %v, want 第 5 手", text, err)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("access_token 请求 %d 次, want 1（应复用）", tokenRequests)
+	}
+
+	client = NewClient(server.URL)
+	client.Backend = Backends["baidu"]
+	client.APIKey, client.SecretKey = "ak", "wrong"
+	if _, err := client.Recognize(context.Background(), []byte("jpg")); err == nil {
+		t.Error("密钥错误时 Recognize() 应返回错误")
+	}
+}
@@ -0,0 +1,109 @@
+// Package ocr 封装本地与云端 OCR 服务的调用以及识别文本的解析。
+package ocr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Recognizer 识别 JPG 图片中的全部文本，Client 与 Chain 都实现了它
+type Recognizer interface {
+	Recognize(ctx context.Context, jpg []byte) (string, error)
+}
+
+// DefaultTimeout Client.Timeout 为 0 时每次识别的超时
+const DefaultTimeout = 10 * time.Second
+
+// Client OCR 服务客户端
+type Client struct {
+	// Endpoint 服务地址，为空时使用 Backend 的默认地址
+	Endpoint string
+	// Backend 服务的请求与响应格式，零值时使用 DefaultBackend
+	Backend Backend
+	// Language 识别语言，含义取决于 Backend，为空时使用服务的默认语言
+	Language string
+	// APIKey、SecretKey 云端服务的密钥，只应从环境变量读取，不要写进配置文件
+	APIKey    string
+	SecretKey string
+	// Quota 请求次数上限，超出时 Recognize 返回 ErrQuotaExceeded
+	Quota Quota
+	// Timeout 每次识别（含换取密钥）的超时，为 0 时使用 DefaultTimeout
+	Timeout    time.Duration
+	HTTPClient *http.Client
+
+	mu          sync.Mutex
+	used        quotaUsage
+	token       string
+	tokenExpiry time.Time
+}
+
+func NewClient(endpoint string) *Client {
+	return &Client{
+		Endpoint:   endpoint,
+		Backend:    DefaultBackend,
+		HTTPClient: &http.Client{},
+	}
+}
+
+// Recognize 上传 JPG 图片，返回识别出的全部文本。ctx 取消或超过 Timeout 时中止请求
+func (c *Client) Recognize(ctx context.Context, jpg []byte) (string, error) {
+	if len(jpg) == 0 {
+		return "", fmt.Errorf("图片为空")
+	}
+
+	backend := c.backend()
+	if !c.take(time.Now()) {
+		return "", ErrQuotaExceeded
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+	req, err := backend.NewRequest(ctx, c, jpg)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("OCR 请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respData, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("OCR 响应错误: %d, 响应: %s", resp.StatusCode, string(respData))
+	}
+
+	respData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("读取响应失败: %v", err)
+	}
+
+	return backend.Parse(respData)
+}
+
+func (c *Client) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultTimeout
+}
+
+func (c *Client) backend() Backend {
+	if c.Backend.NewRequest == nil {
+		return DefaultBackend
+	}
+	return c.Backend
+}
+
+// endpoint 返回 Endpoint，为空时使用 Backend 的默认地址
+func (c *Client) endpoint() string {
+	if c.Endpoint != "" {
+		return c.Endpoint
+	}
+	return c.Backend.DefaultEndpoint
+}
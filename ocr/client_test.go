@@ -0,0 +1,254 @@
+package ocr
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecognize(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		mockResponse string
+		expected     string
+		shouldError  bool
+	}{
+		{
+			name:         "数组格式",
+			status:       http.StatusOK,
+			mockResponse: `[{"words": "第 37 手"}, {"words": "黑方"}]`,
+			expected:     "第 37 手 黑方",
+		},
+		{
+			name:         "results 包装格式",
+			status:       http.StatusOK,
+			mockResponse: `{"results": [{"words": "05:32"}]}`,
+			expected:     "05:32",
+		},
+		{
+			name:         "纯文本",
+			status:       http.StatusOK,
+			mockResponse: `第12手`,
+			expected:     "第12手",
+		},
+		{
+			name:         "服务器错误",
+			status:       http.StatusInternalServerError,
+			mockResponse: `internal error`,
+			shouldError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				file, _, err := r.FormFile("file")
+				if err != nil {
+					t.Errorf("请求缺少 file 字段: %v", err)
+				} else {
+					data, _ := io.ReadAll(file)
+					if string(data) != "jpg" {
+						t.Errorf("上传内容 = %q, want %q", data, "jpg")
+					}
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			text, err := NewClient(server.URL).Recognize(context.Background(), []byte("jpg"))
+
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("Recognize() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Recognize() unexpected error: %v", err)
+				return
+			}
+
+			if text != tt.expected {
+				t.Errorf("Recognize() = %q, want %q", text, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBackends(t *testing.T) {
+	tests := []struct {
+		backend      string
+		language     string
+		apiKey       string
+		mockResponse string
+		checkRequest func(r *http.Request) string
+		wantRequest  string
+		expected     string
+		shouldError  bool
+	}{
+		{
+			backend:      "paddleocr",
+			mockResponse: `{"msg": "", "status": "000", "results": [[{"text": "第 37 手", "confidence": 0.98}, {"text": "黑方"}]]}`,
+			checkRequest: func(r *http.Request) string {
+				var req struct{ Images []string }
+				json.NewDecoder(r.Body).Decode(&req)
+				return strings.Join(req.Images, ",")
+			},
+			wantRequest: "anBn", // base64("jpg")
+			expected:    "第 37 手 黑方",
+		},
+		{
+			backend:      "paddleocr",
+			mockResponse: `{"msg": "model not found", "status": "101", "results": []}`,
+			shouldError:  true,
+		},
+		{
+			backend:      "umi-ocr",
+			language:     "models/config_chinese.txt",
+			mockResponse: `{"code": 100, "data": [{"text": "第12手", "score": 0.99}]}`,
+			checkRequest: func(r *http.Request) string {
+				var req struct {
+					Base64  string
+					Options map[string]string
+				}
+				json.NewDecoder(r.Body).Decode(&req)
+				return req.Base64 + "," + req.Options["ocr.language"]
+			},
+			wantRequest: "anBn,models/config_chinese.txt",
+			expected:    "第12手",
+		},
+		{
+			backend:      "umi-ocr",
+			mockResponse: `{"code": 101, "data": ""}`,
+			expected:     "",
+		},
+		{
+			backend:      "baidu",
+			language:     "CHN_ENG",
+			mockResponse: `{"log_id": 1, "words_result_num": 2, "words_result": [{"words": "第 8 手"}, {"words": "05:32"}]}`,
+			checkRequest: func(r *http.Request) string {
+				r.ParseForm()
+				return r.PostForm.Get("image") + "," + r.PostForm.Get("language_type")
+			},
+			wantRequest: "anBn,CHN_ENG",
+			expected:    "第 8 手 05:32",
+		},
+		{
+			backend:      "baidu",
+			mockResponse: `{"error_code": 110, "error_msg": "Access token invalid or no longer valid"}`,
+			shouldError:  true,
+		},
+		{
+			backend:      "google",
+			language:     "zh",
+			apiKey:       "g-key",
+			mockResponse: `{"responses": [{"textAnnotations": [{"description": "第 21 手"}], "fullTextAnnotation": {"text": "第 21 手\n05:32\n"}}]}`,
+			checkRequest: func(r *http.Request) string {
+				var req struct {
+					Requests []struct {
+						Image        struct{ Content string }
+						ImageContext struct{ LanguageHints []string }
+					}
+				}
+				json.NewDecoder(r.Body).Decode(&req)
+				if len(req.Requests) != 1 {
+					return "请求数不为 1"
+				}
+				return r.URL.Query().Get("key") + "," + req.Requests[0].Image.Content + "," + strings.Join(req.Requests[0].ImageContext.LanguageHints, ",")
+			},
+			wantRequest: "g-key,anBn,zh",
+			expected:    "第 21 手 05:32",
+		},
+		{
+			backend:      "google",
+			mockResponse: `{"responses": [{"error": {"code": 7, "message": "API key not valid"}}]}`,
+			shouldError:  true,
+		},
+		{
+			backend:      "azure",
+			language:     "zh-Hans",
+			apiKey:       "a-key",
+			mockResponse: `{"language": "zh-Hans", "regions": [{"lines": [{"words": [{"text": "第"}, {"text": "9"}, {"text": "手"}]}, {"words": [{"text": "05:32"}]}]}]}`,
+			checkRequest: func(r *http.Request) string {
+				data, _ := io.ReadAll(r.Body)
+				return r.URL.Path + "?" + r.URL.RawQuery + "," + r.Header.Get("Ocp-Apim-Subscription-Key") + "," + string(data)
+			},
+			wantRequest: "/vision/v3.2/ocr?language=zh-Hans,a-key,jpg",
+			expected:    "第9手 05:32",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.checkRequest != nil {
+					if got := tt.checkRequest(r); got != tt.wantRequest {
+						t.Errorf("请求内容 = %q, want %q", got, tt.wantRequest)
+					}
+				}
+				w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			backend, err := BackendByName(tt.backend)
+			if err != nil {
+				t.Fatalf("BackendByName(%q) error = %v", tt.backend, err)
+			}
+			client := NewClient(server.URL)
+			client.Backend = backend
+			client.Language = tt.language
+			client.APIKey = tt.apiKey
+
+			text, err := client.Recognize(context.Background(), []byte("jpg"))
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("Recognize() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Recognize() unexpected error: %v", err)
+			}
+			if text != tt.expected {
+				t.Errorf("Recognize() = %q, want %q", text, tt.expected)
+			}
+		})
+	}
+
+	if _, err := BackendByName("tesseract"); err == nil {
+		t.Error("BackendByName(tesseract) 应返回错误")
+	}
+}
+
+func TestRecognizeTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(server.URL)
+	client.Timeout = 50 * time.Millisecond
+	start := time.Now()
+	if _, err := client.Recognize(context.Background(), []byte("jpg")); err == nil {
+		t.Error("OCR 服务无响应时 Recognize() 应返回错误")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Recognize() 用时 %v，未按 Timeout 中止", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Recognize(ctx, []byte("jpg")); err == nil {
+		t.Error("ctx 已取消时 Recognize() 应返回错误")
+	}
+}
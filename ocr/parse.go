@@ -0,0 +1,209 @@
+package ocr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExtractMoveNumber 从 OCR 文本中提取手数（先经 NormalizeNumerals 转换数字写法），失败返回 0
+func ExtractMoveNumber(text string) int {
+	if text == "" {
+		return 0
+	}
+	text = NormalizeNumerals(text)
+
+	patterns := []struct {
+		name     string
+		pattern  string
+		priority int
+	}{
+		{"中文格式", `第\s*(\d+)\s*手`, 1},
+		{"纯数字+手", `(\d+)\s*手`, 2},
+		{"井号格式", `#\s*(\d+)`, 3},
+		{"move格式", `(?i)move\s*:?\s*(\d+)`, 4},
+		{"Step格式", `(?i)step\s*:?\s*(\d+)`, 5},
+		{"最后数字", `(\d+)$`, 6},
+	}
+
+	for _, p := range patterns {
+		re := regexp.MustCompile(p.pattern)
+		matches := re.FindStringSubmatch(text)
+		if len(matches) > 1 {
+			num, err := strconv.Atoi(matches[1])
+			if err == nil && num > 0 && num < 2000 {
+				return num
+			}
+		}
+	}
+
+	nums := regexp.MustCompile(`(\d+)`).FindAllString(text, -1)
+
+	for i := len(nums) - 1; i >= 0; i-- {
+		if num, err := strconv.Atoi(nums[i]); err == nil && num > 0 && num < 500 {
+			return num
+		}
+	}
+
+	return 0
+}
+
+// CompileMoveNumberPatterns 编译自定义的手数正则。每个正则至少有一个捕获分组，
+// 用 | 组合多种写法时可以各有一个分组，取第一个匹配到的分组
+func CompileMoveNumberPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("手数正则 %q 无效: %v", p, err)
+		}
+		if re.NumSubexp() == 0 {
+			return nil, fmt.Errorf("手数正则 %q 缺少捕获手数的分组", p)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// ExtractMoveNumberWith 依次用 patterns 提取手数，patterns 为空时同 ExtractMoveNumber。
+// 指定了 patterns 时只用它们，不再退回到“最后一个数字”之类的宽松规则，
+// 避免其他语言界面中的数字被误认为手数
+func ExtractMoveNumberWith(text string, patterns []*regexp.Regexp) int {
+	if len(patterns) == 0 {
+		return ExtractMoveNumber(text)
+	}
+	text = NormalizeNumerals(text)
+	for _, re := range patterns {
+		m := re.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		// 未参与匹配的分组为空字符串
+		for _, group := range m[1:] {
+			if num, err := strconv.Atoi(group); err == nil && num > 0 && num < 2000 {
+				return num
+			}
+		}
+	}
+	return 0
+}
+
+// Clock 一方的计时信息
+type Clock struct {
+	Remaining time.Duration `json:"remaining"`
+	Periods   int           `json:"periods"`
+	ByoYomi   bool          `json:"byo_yomi"`
+}
+
+var (
+	clockTimeRe    = regexp.MustCompile(`(?:(\d{1,2})\s*[:：]\s*)?(\d{1,2})\s*[:：]\s*(\d{2})`)
+	clockSecondsRe = regexp.MustCompile(`(\d+)\s*秒`)
+	clockPeriodsRe = regexp.MustCompile(`(\d+)\s*次|[x×]\s*(\d+)|\((\d+)\)|（(\d+)）`)
+	clockByoYomiRe = regexp.MustCompile(`读秒|(?i)byo`)
+)
+
+// ParseClock 解析计时区域的文本，支持 "05:32"、"1:02:03"、"读秒 00:25 (3)"、"30秒 3次" 等格式
+func ParseClock(text string) (Clock, error) {
+	var clock Clock
+
+	if m := clockTimeRe.FindStringSubmatch(text); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		min, _ := strconv.Atoi(m[2])
+		sec, _ := strconv.Atoi(m[3])
+		clock.Remaining = time.Duration(h)*time.Hour + time.Duration(min)*time.Minute + time.Duration(sec)*time.Second
+	} else if m := clockSecondsRe.FindStringSubmatch(text); m != nil {
+		sec, _ := strconv.Atoi(m[1])
+		clock.Remaining = time.Duration(sec) * time.Second
+		clock.ByoYomi = true
+	} else {
+		return Clock{}, fmt.Errorf("未识别到时间: %q", text)
+	}
+
+	if m := clockPeriodsRe.FindStringSubmatch(text); m != nil {
+		for _, g := range m[1:] {
+			if g != "" {
+				clock.Periods, _ = strconv.Atoi(g)
+				clock.ByoYomi = true
+				break
+			}
+		}
+	}
+
+	if clockByoYomiRe.MatchString(text) {
+		clock.ByoYomi = true
+	}
+
+	return clock, nil
+}
+
+var moveListEntryRe = regexp.MustCompile(`(?:(\d+)\s*(?:手|[.、:：])?\s*)?(?:[黑白BW]\s*)?\b([A-Ta-t])\s*(1[0-9]|[1-9])\b`)
+
+// ParseMoveList 从棋谱面板的文本中找出最后一手的坐标（如 "K10"）。
+// 带手数的条目取手数最大者，否则取文本中最后出现的坐标；手数未知时返回 0。
+func ParseMoveList(text string) (string, int, error) {
+	matches := moveListEntryRe.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return "", 0, fmt.Errorf("未识别到棋谱坐标: %q", text)
+	}
+
+	best := matches[len(matches)-1]
+	bestMove := 0
+	for _, m := range matches {
+		if m[1] == "" {
+			continue
+		}
+		if move, err := strconv.Atoi(m[1]); err == nil && move > bestMove {
+			best = m
+			bestMove = move
+		}
+	}
+
+	return strings.ToUpper(best[2]) + best[3], bestMove, nil
+}
+
+var playerRankRe = regexp.MustCompile(`\s*(?:[(（][^()（）]*[)）]|(?:\d+|[一二三四五六七八九十]+)\s*(?:段|级|[DdKk])|职业|业余)$`)
+
+// ParsePlayerName 从对局者信息栏的文本中取出名字：取第一行非空文本，去掉末尾的段位（如 "5段"、"九段"、"3D"）及括号内的说明
+func ParsePlayerName(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		name := strings.TrimSpace(line)
+		for {
+			trimmed := strings.TrimSpace(playerRankRe.ReplaceAllString(name, ""))
+			if trimmed == name {
+				break
+			}
+			name = trimmed
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+var boardLabelRe = regexp.MustCompile(`1[0-9]|[1-9]|[A-HJ-Ta-hj-t]`)
+
+// ParseBoardLabels 按出现顺序取出棋盘边上一条坐标标签的文本中的标签，返回 0 起的序号（A、1 为 0）。
+// 字母（A-T，不含 I）为列标签，数字（1-19）为行标签，一条边上只有一种；两种都有时取多的一种，
+// 另一种视为误读。letters 表示取的是列标签
+func ParseBoardLabels(text string) (index []int, letters bool) {
+	var columns, rows []int
+	for _, m := range boardLabelRe.FindAllString(text, -1) {
+		if n, err := strconv.Atoi(m); err == nil {
+			rows = append(rows, n-1)
+			continue
+		}
+		c := strings.ToUpper(m)[0]
+		i := int(c - 'A')
+		if c > 'I' {
+			i--
+		}
+		columns = append(columns, i)
+	}
+	if len(columns) > len(rows) {
+		return columns, true
+	}
+	return rows, false
+}
@@ -0,0 +1,258 @@
+package ocr
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExtractMoveNumber(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"第 37 手", 37},
+		{"黑 第128手 白", 128},
+		{"12 手", 12},
+		{"#45", 45},
+		{"Move: 9", 9},
+		{"step 3", 3},
+		{"abc 88", 88},
+		{"", 0},
+		{"无数字", 0},
+	}
+
+	for _, tt := range tests {
+		if got := ExtractMoveNumber(tt.text); got != tt.expected {
+			t.Errorf("ExtractMoveNumber(%q) = %d, want %d", tt.text, got, tt.expected)
+		}
+	}
+}
+
+func TestExtractMoveNumberWith(t *testing.T) {
+	patterns, err := CompileMoveNumberPatterns([]string{`(\d+)手目|第(\d+)着`, `(?i)move\s+(\d+)`})
+	if err != nil {
+		t.Fatalf("CompileMoveNumberPatterns() error = %v", err)
+	}
+
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"黒番 42手目", 42},
+		{"第15着", 15},
+		{"Move 17 of 200", 17},
+		{"残り 05:32", 0}, // 不退回到宽松规则
+	}
+	for _, tt := range tests {
+		if got := ExtractMoveNumberWith(tt.text, patterns); got != tt.expected {
+			t.Errorf("ExtractMoveNumberWith(%q) = %d, want %d", tt.text, got, tt.expected)
+		}
+	}
+
+	if got := ExtractMoveNumberWith("第 37 手", nil); got != 37 {
+		t.Errorf("未指定正则时 ExtractMoveNumberWith() = %d, want 37", got)
+	}
+
+	for _, bad := range []string{`(\d+`, `\d+手`} {
+		if _, err := CompileMoveNumberPatterns([]string{bad}); err == nil {
+			t.Errorf("CompileMoveNumberPatterns(%q) 应返回错误", bad)
+		}
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		expected    Clock
+		shouldError bool
+	}{
+		{
+			name:     "包干时间",
+			text:     "05:32",
+			expected: Clock{Remaining: 5*time.Minute + 32*time.Second},
+		},
+		{
+			name:     "带小时",
+			text:     "1:02:03",
+			expected: Clock{Remaining: time.Hour + 2*time.Minute + 3*time.Second},
+		},
+		{
+			name:     "读秒括号次数",
+			text:     "读秒 00:25 (3)",
+			expected: Clock{Remaining: 25 * time.Second, Periods: 3, ByoYomi: true},
+		},
+		{
+			name:     "秒加次数",
+			text:     "30秒 3次",
+			expected: Clock{Remaining: 30 * time.Second, Periods: 3, ByoYomi: true},
+		},
+		{
+			name:     "全角冒号",
+			text:     "10：00",
+			expected: Clock{Remaining: 10 * time.Minute},
+		},
+		{
+			name:        "无时间",
+			text:        "黑方",
+			shouldError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock, err := ParseClock(tt.text)
+
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("ParseClock(%q) expected error, got nil", tt.text)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("ParseClock(%q) unexpected error: %v", tt.text, err)
+				return
+			}
+
+			if clock != tt.expected {
+				t.Errorf("ParseClock(%q) = %+v, want %+v", tt.text, clock, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseMoveList(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		expectedCoord string
+		expectedMove  int
+		shouldError   bool
+	}{
+		{"带手数取最大", "35 Q3 36 R4 37 K10", "K10", 37, false},
+		{"手数乱序", "第37手 K10 第36手 R4", "K10", 37, false},
+		{"带颜色", "黑 D4 白 Q16", "Q16", 0, false},
+		{"小写字母", "12. c17", "C17", 12, false},
+		{"无坐标", "棋谱", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, move, err := ParseMoveList(tt.text)
+
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("ParseMoveList(%q) expected error, got nil", tt.text)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("ParseMoveList(%q) unexpected error: %v", tt.text, err)
+				return
+			}
+
+			if coord != tt.expectedCoord || move != tt.expectedMove {
+				t.Errorf("ParseMoveList(%q) = %s, %d, want %s, %d", tt.text, coord, move, tt.expectedCoord, tt.expectedMove)
+			}
+		})
+	}
+}
+
+func TestParsePlayerName(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"柯洁 九段", "柯洁"},
+		{"  \nGoFan 5D\n00:30", "GoFan"},
+		{"棋友123(业余 3段)", "棋友123"},
+		{"小明 1级", "小明"},
+		{"职业 九段", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ParsePlayerName(tt.text); got != tt.want {
+			t.Errorf("ParsePlayerName(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseBoardLabels(t *testing.T) {
+	tests := []struct {
+		text        string
+		wantIndex   []int
+		wantLetters bool
+	}{
+		{"A B C D E F G H J K L M N O P Q R S T", []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18}, true},
+		{"TSR", []int{18, 17, 16}, true},
+		{"19\n18\n17\n16", []int{18, 17, 16, 15}, false},
+		{"1 2 3 10 11", []int{0, 1, 2, 9, 10}, false},
+		{"a b 3 c", []int{0, 1, 2}, true},
+		{"", nil, false},
+	}
+
+	for _, tt := range tests {
+		index, letters := ParseBoardLabels(tt.text)
+		if !reflect.DeepEqual(index, tt.wantIndex) || letters != tt.wantLetters {
+			t.Errorf("ParseBoardLabels(%q) = %v, %v, want %v, %v", tt.text, index, letters, tt.wantIndex, tt.wantLetters)
+		}
+	}
+}
+
+func TestNormalizeNumerals(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"第１２８手", "第128手"},
+		{"第一百二十三手", "第123手"},
+		{"四十二手目", "42手目"},
+		{"第十手", "第10手"},
+		{"제 五 수", "제 5 수"},
+		{"一二三手", "123手"},
+		{"一力遼 9段", "一力遼 9段"}, // 名字中的汉字不是手数
+	}
+	for _, tt := range tests {
+		if got := NormalizeNumerals(tt.text); got != tt.want {
+			t.Errorf("NormalizeNumerals(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	tests := []struct {
+		profile string
+		text    string
+		want    int
+	}{
+		{"ja", "黒番 42手目 残り 05:32", 42},
+		{"ja", "第百五十手", 150},
+		{"ko", "흑 57수", 57},
+		{"ko", "제 12 수 백", 12},
+		{"en", "Move 17 of 200", 17},
+		{"en", "Move No. 88", 88},
+		{"zh", "第１０３手", 103},
+		{"ko", "남은 시간 05:32", 0},
+	}
+	for _, tt := range tests {
+		raw, err := ProfilePatterns(tt.profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		patterns, err := CompileMoveNumberPatterns(raw)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.profile, err)
+		}
+		if got := ExtractMoveNumberWith(tt.text, patterns); got != tt.want {
+			t.Errorf("%s: ExtractMoveNumberWith(%q) = %d, want %d", tt.profile, tt.text, got, tt.want)
+		}
+	}
+
+	if _, err := ProfilePatterns("fr"); err == nil {
+		t.Errorf("未知的界面语言应返回错误")
+	}
+}
@@ -0,0 +1,104 @@
+package ocr
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Profile 一种界面语言（或一款 App）的 OCR 文本写法
+type Profile struct {
+	// Description 说明，用于帮助与错误提示
+	Description string
+	// MoveNumberPatterns 提取手数的正则，格式见 CompileMoveNumberPatterns
+	MoveNumberPatterns []string
+}
+
+// Profiles 内置的界面语言配置，按名字选用。中文界面不需要配置，内置规则即可
+var Profiles = map[string]Profile{
+	"zh": {
+		Description:        "中文（第 N 手、N 手）",
+		MoveNumberPatterns: []string{`第\s*(\d+)\s*手`, `(\d+)\s*手`},
+	},
+	"ja": {
+		Description:        "日文（N手目、第N手）",
+		MoveNumberPatterns: []string{`(\d+)\s*手目`, `第\s*(\d+)\s*手`},
+	},
+	"ko": {
+		Description:        "韩文（N수、제N수）",
+		MoveNumberPatterns: []string{`제\s*(\d+)\s*수`, `(\d+)\s*수`},
+	},
+	"en": {
+		Description:        "英文（Move N、N moves）",
+		MoveNumberPatterns: []string{`(?i)move\s*(?:no\.?|#)?\s*:?\s*(\d+)`, `(?i)(\d+)\s*moves?\b`},
+	},
+}
+
+// ProfilePatterns 返回名为 name 的界面语言配置的手数正则
+func ProfilePatterns(name string) ([]string, error) {
+	p, ok := Profiles[name]
+	if !ok {
+		var names []string
+		for n, p := range Profiles {
+			names = append(names, n+"（"+p.Description+"）")
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("未知的界面语言 %q，可选: %s", name, strings.Join(names, "、"))
+	}
+	return p.MoveNumberPatterns, nil
+}
+
+// kanjiDigits 汉字数字，日文界面也使用
+var kanjiDigits = map[rune]int{
+	'〇': 0, '零': 0, '一': 1, '二': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+// kanjiNumberRe 紧跟“第”或后面跟着“手”“수”的汉字数字，只转换这些位置，避免把名字中的“一”当作数字
+var kanjiNumberRe = regexp.MustCompile(`(第|제)(\s*)([〇零一二三四五六七八九十百千]+)|([〇零一二三四五六七八九十百千]+)(\s*(?:手|수))`)
+
+// NormalizeNumerals 把 OCR 文本中的全角数字转为半角，并把手数位置上的汉字数字（如“第一百二十三手”）转为阿拉伯数字
+func NormalizeNumerals(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if r >= '０' && r <= '９' {
+			return '0' + r - '０'
+		}
+		return r
+	}, text)
+
+	return kanjiNumberRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := kanjiNumberRe.FindStringSubmatch(s)
+		if m[3] != "" {
+			return m[1] + m[2] + fmt.Sprint(parseKanjiNumber(m[3]))
+		}
+		return fmt.Sprint(parseKanjiNumber(m[4])) + m[5]
+	})
+}
+
+// parseKanjiNumber 解析汉字数字，支持“一百二十三”与逐位写的“一二三”两种写法
+func parseKanjiNumber(s string) int {
+	if !strings.ContainsAny(s, "十百千") {
+		n := 0
+		for _, r := range s {
+			n = n*10 + kanjiDigits[r]
+		}
+		return n
+	}
+
+	total, digit := 0, 0
+	for _, r := range s {
+		switch r {
+		case '十', '百', '千':
+			unit := map[rune]int{'十': 10, '百': 100, '千': 1000}[r]
+			// “十二”省略了前面的“一”
+			if digit == 0 {
+				digit = 1
+			}
+			total += digit * unit
+			digit = 0
+		default:
+			digit = kanjiDigits[r]
+		}
+	}
+	return total + digit
+}
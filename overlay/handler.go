@@ -0,0 +1,102 @@
+package overlay
+
+import (
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"strconv"
+)
+
+// Handler 返回叠加画面的 HTTP 路由：/ 为透明背景的网页（OBS 浏览器源），/frame.json 为当前一帧，
+// /board.png 为 PNG（?size= 指定边长，默认 600）。frame 每次请求时调用
+func Handler(frame func() Frame) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/frame.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(frame())
+	})
+	mux.HandleFunc("/board.png", func(w http.ResponseWriter, r *http.Request) {
+		size, err := strconv.Atoi(r.URL.Query().Get("size"))
+		if err != nil || size < 100 || size > 2000 {
+			size = 600
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Cache-Control", "no-store")
+		png.Encode(w, Render(frame(), size))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(pageHTML))
+	})
+	return mux
+}
+
+// pageHTML 每秒读取 frame.json 用 SVG 画出棋盘，背景透明；size 参数指定棋盘边长（像素）
+const pageHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>goboardsync overlay</title>
+<style>
+html, body { background: transparent; margin: 0; font-family: sans-serif; }
+#info { color: #fff; text-shadow: 0 0 4px #000; font-size: 28px; margin: 4px 0; }
+</style>
+</head>
+<body>
+<svg id="board"></svg>
+<div id="info"></div>
+<script>
+const size = Number(new URLSearchParams(location.search).get("size")) || 600;
+const cell = size / 20;
+const svg = document.getElementById("board");
+svg.setAttribute("width", size);
+svg.setAttribute("height", size + cell);
+
+function el(name, attrs) {
+  const e = document.createElementNS("http://www.w3.org/2000/svg", name);
+  for (const k in attrs) e.setAttribute(k, attrs[k]);
+  svg.appendChild(e);
+}
+
+function draw(f) {
+  svg.innerHTML = "";
+  el("rect", {width: size, height: size, fill: "rgba(220,179,92,0.92)"});
+  for (let i = 0; i < 19; i++) {
+    const p = cell + i * cell;
+    el("line", {x1: cell, y1: p, x2: 19 * cell, y2: p, stroke: "#281e14"});
+    el("line", {x1: p, y1: cell, x2: p, y2: 19 * cell, stroke: "#281e14"});
+  }
+  for (const x of [3, 9, 15]) for (const y of [3, 9, 15])
+    el("circle", {cx: cell + x * cell, cy: cell + y * cell, r: cell / 8, fill: "#281e14"});
+  f.rows.forEach((row, r) => [...row].forEach((c, x) => {
+    if (c === ".") return;
+    el("circle", {cx: cell + x * cell, cy: cell + r * cell, r: cell * 0.47,
+      fill: c === "B" ? "#141414" : "#f5f5f5", stroke: "#281e14"});
+  }));
+  if (f.last) el("circle", {cx: cell + f.last[0] * cell, cy: cell + f.last[1] * cell, r: cell / 5, fill: "#e62828"});
+
+  let info = "第 " + f.move + " 手" + (f.coord ? " " + f.coord : "");
+  if (f.winrate !== undefined) {
+    el("rect", {y: size, width: size * f.winrate, height: cell, fill: "#141414"});
+    el("rect", {x: size * f.winrate, y: size, width: size * (1 - f.winrate), height: cell, fill: "#f5f5f5"});
+    info += "　黑胜率 " + (f.winrate * 100).toFixed(1) + "%";
+  }
+  document.getElementById("info").textContent = info;
+}
+
+async function refresh() {
+  try {
+    draw(await (await fetch("frame.json" + location.search)).json());
+  } catch (e) {}
+}
+refresh();
+setInterval(refresh, 1000);
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,150 @@
+// Package overlay 生成直播用的棋盘叠加画面：透明背景的 PNG 与网页，分别作为 OBS 的图像源与浏览器源，
+// 显示当前局面、最后一手、手数，有 KaTrain 分析时显示胜率条。
+package overlay
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+)
+
+// Frame 叠加画面的一帧。Last 为最后一手的 KaTrain 坐标，Winrate 为黑方胜率（0-1），没有时为 nil
+type Frame struct {
+	Board   board.Board
+	Last    *image.Point
+	Move    int
+	Winrate *float64
+}
+
+var (
+	boardColor = color.NRGBA{220, 179, 92, 235}
+	lineColor  = color.NRGBA{40, 30, 20, 255}
+	blackStone = color.NRGBA{20, 20, 20, 255}
+	whiteStone = color.NRGBA{245, 245, 245, 255}
+	lastMarker = color.NRGBA{230, 40, 40, 255}
+)
+
+// Render 画出 size×size 的棋盘，下方留出一格高的胜率条（没有胜率时透明）
+func Render(f Frame, size int) *image.NRGBA {
+	cell := size / (coords.Size + 1)
+	barH := cell
+	img := image.NewNRGBA(image.Rect(0, 0, size, size+barH))
+
+	fill(img, image.Rect(0, 0, size, size), boardColor)
+	width := max(size/400, 1)
+	for i := range coords.Size {
+		p := cell + i*cell
+		fill(img, image.Rect(cell, p, cell*coords.Size+width, p+width), lineColor)
+		fill(img, image.Rect(p, cell, p+width, cell*coords.Size+width), lineColor)
+	}
+	for _, x := range []int{3, 9, 15} {
+		for _, y := range []int{3, 9, 15} {
+			disc(img, point(x, y, cell), max(cell/8, 2), lineColor)
+		}
+	}
+
+	for x := range coords.Size {
+		for y := range coords.Size {
+			switch f.Board.At(x, y) {
+			case board.Black:
+				disc(img, point(x, y, cell), cell*47/100, blackStone)
+			case board.White:
+				disc(img, point(x, y, cell), cell*47/100, lineColor)
+				disc(img, point(x, y, cell), cell*47/100-width, whiteStone)
+			}
+		}
+	}
+	if f.Last != nil {
+		disc(img, point(f.Last.X, f.Last.Y, cell), max(cell/5, 2), lastMarker)
+	}
+
+	if f.Winrate != nil {
+		split := int(float64(size) * min(max(*f.Winrate, 0), 1))
+		fill(img, image.Rect(0, size, split, size+barH), blackStone)
+		fill(img, image.Rect(split, size, size, size+barH), whiteStone)
+	}
+	return img
+}
+
+// point 返回 KaTrain 坐标 (x, y) 的交叉点在图中的像素位置
+func point(x, y, cell int) image.Point {
+	return image.Pt(cell+x*cell, cell+(coords.Size-1-y)*cell)
+}
+
+func fill(img *image.NRGBA, r image.Rectangle, c color.NRGBA) {
+	r = r.Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+}
+
+func disc(img *image.NRGBA, center image.Point, radius int, c color.NRGBA) {
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y <= radius*radius {
+				if p := center.Add(image.Pt(x, y)); p.In(img.Bounds()) {
+					img.SetNRGBA(p.X, p.Y, c)
+				}
+			}
+		}
+	}
+}
+
+// WritePNG 把 Render 的结果写到 path。先写临时文件再改名，OBS 读到的总是完整的图片
+func WritePNG(path string, f Frame, size int) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".overlay-*.png")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := png.Encode(tmp, Render(f, size)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// MarshalJSON 供网页使用：rows 为从上到下的 19 行，每行 19 个字符（. 空、B 黑、W 白），
+// last 为最后一手所在的行列（从 0 起，行从上往下数）
+func (f Frame) MarshalJSON() ([]byte, error) {
+	rows := make([]string, coords.Size)
+	for row := range coords.Size {
+		line := make([]byte, coords.Size)
+		for x := range coords.Size {
+			switch f.Board.At(x, coords.Size-1-row) {
+			case board.Black:
+				line[x] = 'B'
+			case board.White:
+				line[x] = 'W'
+			default:
+				line[x] = '.'
+			}
+		}
+		rows[row] = string(line)
+	}
+
+	out := struct {
+		Rows    []string `json:"rows"`
+		Last    *[2]int  `json:"last,omitempty"`
+		Coord   string   `json:"coord,omitempty"`
+		Move    int      `json:"move"`
+		Winrate *float64 `json:"winrate,omitempty"`
+	}{Rows: rows, Move: f.Move, Winrate: f.Winrate}
+	if f.Last != nil {
+		out.Last = &[2]int{f.Last.X, coords.Size - 1 - f.Last.Y}
+		out.Coord = coords.Format(f.Last.X, f.Last.Y, coords.GTP)
+	}
+	return json.Marshal(out)
+}
@@ -0,0 +1,113 @@
+package overlay
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"goboardsync/board"
+)
+
+func testFrame() Frame {
+	var b board.Board
+	b.Set(15, 15, board.Black) // Q16
+	b.Set(3, 3, board.White)   // D4
+	winrate := 0.75
+	return Frame{Board: b, Last: &image.Point{X: 3, Y: 3}, Move: 2, Winrate: &winrate}
+}
+
+func TestRender(t *testing.T) {
+	img := Render(testFrame(), 400)
+	cell := 400 / 20
+
+	tests := []struct {
+		name string
+		at   image.Point
+		want color.NRGBA
+	}{
+		{"黑子 Q16", point(15, 15, cell).Add(image.Pt(cell/3, 0)), blackStone},
+		{"白子 D4", point(3, 3, cell).Add(image.Pt(cell/3, 0)), whiteStone},
+		{"最后一手标记", point(3, 3, cell), lastMarker},
+		{"胜率条黑方", image.Pt(400*3/4-5, 400+cell/2), blackStone},
+		{"胜率条白方", image.Pt(400*3/4+5, 400+cell/2), whiteStone},
+	}
+	for _, tt := range tests {
+		if got := img.NRGBAAt(tt.at.X, tt.at.Y); got != tt.want {
+			t.Errorf("%s %v = %v, want %v", tt.name, tt.at, got, tt.want)
+		}
+	}
+
+	// 没有胜率时胜率条透明
+	f := testFrame()
+	f.Winrate = nil
+	if got := Render(f, 400).NRGBAAt(10, 400+cell/2); got.A != 0 {
+		t.Errorf("没有胜率时胜率条 = %v, want 透明", got)
+	}
+}
+
+func TestFrameJSON(t *testing.T) {
+	data, err := json.Marshal(testFrame())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Rows    []string
+		Last    [2]int
+		Coord   string
+		Move    int
+		Winrate float64
+	}
+	json.Unmarshal(data, &got)
+
+	if len(got.Rows) != 19 || got.Rows[3][15] != 'B' || got.Rows[15][3] != 'W' {
+		t.Errorf("rows = %q", got.Rows)
+	}
+	if got.Last != [2]int{3, 15} || got.Coord != "D4" || got.Move != 2 || got.Winrate != 0.75 {
+		t.Errorf("JSON = %s", data)
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overlay.png")
+	if err := WritePNG(path, testFrame(), 200); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil || img.Bounds().Dx() != 200 {
+		t.Errorf("PNG = %v, %v", img.Bounds(), err)
+	}
+	if files, _ := os.ReadDir(filepath.Dir(path)); len(files) != 1 {
+		t.Errorf("临时文件未清理: %v", files)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	srv := httptest.NewServer(Handler(testFrame))
+	defer srv.Close()
+
+	for path, want := range map[string]string{
+		"/":                   "text/html; charset=utf-8",
+		"/frame.json":         "application/json",
+		"/board.png?size=300": "image/png",
+	} {
+		resp, err := srv.Client().Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != 200 || !strings.HasPrefix(resp.Header.Get("Content-Type"), want) {
+			t.Errorf("GET %s = %d %s", path, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+	}
+}
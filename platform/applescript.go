@@ -0,0 +1,31 @@
+package platform
+
+import "fmt"
+
+// macOS 通过 System Events 查找窗口。窗口标题（如 scrcpy 的 --window-title）与进程名（scrcpy）无关，
+// 必须逐个进程比较窗口的名字，不能按进程名匹配
+
+// windowExistsScript 返回存在标题包含 title 的窗口时输出 true、否则输出 false 的 AppleScript
+func windowExistsScript(title string) string {
+	return fmt.Sprintf(`tell application "System Events"
+	repeat with p in (every process whose background only is false)
+		if (count (windows of p whose name contains %q)) > 0 then return true
+	end repeat
+	return false
+end tell`, title)
+}
+
+// activateWindowScript 返回把标题包含 title 的窗口所属进程切到前台并提升该窗口的 AppleScript，没有该窗口时报错
+func activateWindowScript(title string) string {
+	return fmt.Sprintf(`tell application "System Events"
+	repeat with p in (every process whose background only is false)
+		set matched to (windows of p whose name contains %[1]q)
+		if (count matched) > 0 then
+			set frontmost of p to true
+			perform action "AXRaise" of (item 1 of matched)
+			return
+		end if
+	end repeat
+	error "没有标题包含 " & %[1]q & " 的窗口"
+end tell`, title)
+}
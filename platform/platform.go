@@ -0,0 +1,79 @@
+// Package platform 收拢与操作系统相关的差异：外部工具（adb、scrcpy）的查找、默认数据目录、
+// 是否有图形界面以及窗口检测与激活，各系统的实现分别放在带构建标签的文件中。
+package platform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// FindTool 查找外部工具：先看 PATH，再看 Android SDK 环境变量（ANDROID_HOME / ANDROID_SDK_ROOT）
+// 下的 platform-tools，最后看各系统的常见安装位置
+func FindTool(name string) (string, error) {
+	exe := executableName(name)
+	if path, err := exec.LookPath(exe); err == nil {
+		return path, nil
+	}
+
+	var dirs []string
+	for _, env := range []string{"ANDROID_HOME", "ANDROID_SDK_ROOT"} {
+		if sdk := os.Getenv(env); sdk != "" {
+			dirs = append(dirs, filepath.Join(sdk, "platform-tools"))
+		}
+	}
+	dirs = append(dirs, toolDirs()...)
+
+	for _, dir := range dirs {
+		path := filepath.Join(dir, exe)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("未找到 %s，请安装后加入 PATH", name)
+}
+
+// DataDir 默认的棋谱与数据目录，不存在时创建
+func DataDir() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("创建数据目录失败: %v", err)
+	}
+	return dir, nil
+}
+
+// Headless 当前环境是否没有图形界面（如通过 SSH 登录的 Linux 服务器），此时不启动 scrcpy 等窗口程序
+func Headless() bool {
+	return headless()
+}
+
+// WindowExists 是否存在标题包含 title 的窗口；系统不支持检测时返回错误
+func WindowExists(title string) (bool, error) {
+	return windowExists(title)
+}
+
+// ActivateWindow 把标题包含 title 的窗口切到前台
+func ActivateWindow(title string) error {
+	return activateWindow(title)
+}
+
+// PlaySound 播放声音文件（WAV 各系统都支持），播完才返回。Linux 用 paplay 或 aplay，macOS 用 afplay，
+// Windows 用 PowerShell 的 SoundPlayer
+func PlaySound(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("声音文件不可用: %v", err)
+	}
+	return playSound(path)
+}
+
+func homeDir(elem ...string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("无法获取用户目录: %v", err)
+	}
+	return filepath.Join(append([]string{home}, elem...)...), nil
+}
@@ -0,0 +1,44 @@
+//go:build darwin
+
+package platform
+
+import (
+	"os/exec"
+	"strings"
+)
+
+func executableName(name string) string {
+	return name
+}
+
+func toolDirs() []string {
+	dirs := []string{"/opt/homebrew/bin", "/usr/local/bin"}
+	if sdk, err := homeDir("Library", "Android", "sdk", "platform-tools"); err == nil {
+		dirs = append(dirs, sdk)
+	}
+	return dirs
+}
+
+func dataDir() (string, error) {
+	return homeDir("Documents", "goboardsync")
+}
+
+func headless() bool {
+	return false
+}
+
+func windowExists(title string) (bool, error) {
+	out, err := exec.Command("osascript", "-e", windowExistsScript(title)).Output()
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(out)) == "true", nil
+}
+
+func activateWindow(title string) error {
+	return exec.Command("osascript", "-e", activateWindowScript(title)).Run()
+}
+
+func playSound(path string) error {
+	return exec.Command("afplay", path).Run()
+}
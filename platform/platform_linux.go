@@ -0,0 +1,67 @@
+//go:build linux
+
+package platform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func executableName(name string) string {
+	return name
+}
+
+func toolDirs() []string {
+	dirs := []string{"/usr/bin", "/usr/local/bin", "/snap/bin"}
+	if sdk, err := homeDir("Android", "Sdk", "platform-tools"); err == nil {
+		dirs = append(dirs, sdk)
+	}
+	return dirs
+}
+
+func dataDir() (string, error) {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, "goboardsync"), nil
+	}
+	return homeDir(".local", "share", "goboardsync")
+}
+
+func headless() bool {
+	return os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+}
+
+func windowExists(title string) (bool, error) {
+	xdotool, err := exec.LookPath("xdotool")
+	if err != nil {
+		return false, fmt.Errorf("未找到 xdotool: %v", err)
+	}
+	out, err := exec.Command(xdotool, "search", "--name", title).Output()
+	if err != nil {
+		// 没有匹配的窗口时 xdotool 以状态码 1 退出
+		if _, ok := err.(*exec.ExitError); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return strings.TrimSpace(string(out)) != "", nil
+}
+
+func activateWindow(title string) error {
+	xdotool, err := exec.LookPath("xdotool")
+	if err != nil {
+		return fmt.Errorf("未找到 xdotool: %v", err)
+	}
+	return exec.Command(xdotool, "search", "--name", title, "windowactivate", "--sync").Run()
+}
+
+func playSound(path string) error {
+	for _, player := range []string{"paplay", "aplay"} {
+		if exe, err := exec.LookPath(player); err == nil {
+			return exec.Command(exe, path).Run()
+		}
+	}
+	return fmt.Errorf("未找到 paplay 或 aplay，无法播放声音")
+}
@@ -0,0 +1,36 @@
+//go:build !darwin && !linux && !windows
+
+package platform
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func executableName(name string) string {
+	return name
+}
+
+func toolDirs() []string {
+	return []string{"/usr/local/bin"}
+}
+
+func dataDir() (string, error) {
+	return homeDir("goboardsync")
+}
+
+func headless() bool {
+	return true
+}
+
+func windowExists(title string) (bool, error) {
+	return false, fmt.Errorf("当前系统不支持窗口检测: %s", runtime.GOOS)
+}
+
+func activateWindow(title string) error {
+	return fmt.Errorf("当前系统不支持窗口激活: %s", runtime.GOOS)
+}
+
+func playSound(path string) error {
+	return fmt.Errorf("当前系统不支持播放声音: %s", runtime.GOOS)
+}
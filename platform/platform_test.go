@@ -0,0 +1,71 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindToolFromSDK(t *testing.T) {
+	sdk := t.TempDir()
+	tools := filepath.Join(sdk, "platform-tools")
+	os.MkdirAll(tools, 0o755)
+
+	name := "goboardsync-test-adb"
+	path := filepath.Join(tools, executableName(name))
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("写入测试文件失败: %v", err)
+	}
+
+	t.Setenv("ANDROID_HOME", sdk)
+	got, err := FindTool(name)
+	if err != nil {
+		t.Fatalf("FindTool(%q) unexpected error: %v", name, err)
+	}
+	if got != path {
+		t.Errorf("FindTool(%q) = %s, want %s", name, got, path)
+	}
+
+	if _, err := FindTool("goboardsync-missing-tool"); err == nil {
+		t.Errorf("FindTool 找不到工具时应返回错误")
+	}
+}
+
+func TestDataDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_DATA_HOME", "")
+
+	dir, err := DataDir()
+	if err != nil {
+		t.Fatalf("DataDir() unexpected error: %v", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("DataDir() = %s, 目录未创建", dir)
+	}
+	if rel, err := filepath.Rel(home, dir); err != nil || rel == dir || filepath.IsAbs(rel) {
+		t.Errorf("DataDir() = %s, want %s 下的目录", dir, home)
+	}
+}
+
+func TestWindowScripts(t *testing.T) {
+	// 按窗口名字匹配，而不是按进程名（scrcpy 的进程名是 scrcpy，窗口标题才是 my_phone）
+	for name, script := range map[string]string{
+		"windowExistsScript":   windowExistsScript("my_phone"),
+		"activateWindowScript": activateWindowScript("my_phone"),
+	} {
+		if !strings.Contains(script, `windows of p whose name contains "my_phone"`) {
+			t.Errorf("%s 没有按窗口标题匹配:\n%s", name, script)
+		}
+		if strings.Contains(script, `process whose name contains`) {
+			t.Errorf("%s 不应按进程名匹配窗口标题:\n%s", name, script)
+		}
+	}
+
+	// 标题中的引号要转义
+	if script := windowExistsScript(`a"b`); !strings.Contains(script, `"a\"b"`) {
+		t.Errorf("标题中的引号没有转义:\n%s", script)
+	}
+}
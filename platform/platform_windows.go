@@ -0,0 +1,53 @@
+//go:build windows
+
+package platform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func executableName(name string) string {
+	return name + ".exe"
+}
+
+func toolDirs() []string {
+	var dirs []string
+	if local := os.Getenv("LOCALAPPDATA"); local != "" {
+		dirs = append(dirs, filepath.Join(local, "Android", "Sdk", "platform-tools"))
+	}
+	if scoop, err := homeDir("scoop", "shims"); err == nil {
+		dirs = append(dirs, scoop)
+	}
+	return dirs
+}
+
+func dataDir() (string, error) {
+	return homeDir("Documents", "goboardsync")
+}
+
+func headless() bool {
+	return false
+}
+
+func windowExists(title string) (bool, error) {
+	script := fmt.Sprintf(`@(Get-Process | Where-Object { $_.MainWindowTitle -like '*%s*' }).Count`, strings.ReplaceAll(title, "'", "''"))
+	out, err := exec.Command("powershell", "-NoProfile", "-Command", script).Output()
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(out)) != "0", nil
+}
+
+func activateWindow(title string) error {
+	script := fmt.Sprintf(`(New-Object -ComObject WScript.Shell).AppActivate('%s')`, strings.ReplaceAll(title, "'", "''"))
+	return exec.Command("powershell", "-NoProfile", "-Command", script).Run()
+}
+
+func playSound(path string) error {
+	script := fmt.Sprintf(`(New-Object Media.SoundPlayer '%s').PlaySync()`, strings.ReplaceAll(path, "'", "''"))
+	return exec.Command("powershell", "-NoProfile", "-Command", script).Run()
+}
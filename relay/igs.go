@@ -0,0 +1,200 @@
+// Package relay 把同步中的对局转播到围棋服务器（IGS 教学棋盘、KGS 演示棋盘），
+// 各后端实现 target.SyncTarget，坐标统一使用 KaTrain 坐标。
+package relay
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+)
+
+const DefaultIGSAddr = "igs.joyjoy.net:6969"
+
+// IGS 通过 telnet 协议登录 IGS，开一个教学棋盘（teach）并逐手摆出对局。
+// 教学棋盘由自己执黑白双方，观战者可直接 observe
+type IGS struct {
+	Addr     string
+	User     string
+	Password string
+	Timeout  time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	board  board.Board
+}
+
+func NewIGS(addr, user, password string) *IGS {
+	if addr == "" {
+		addr = DefaultIGSAddr
+	}
+	return &IGS{
+		Addr:     addr,
+		User:     user,
+		Password: password,
+		Timeout:  10 * time.Second,
+	}
+}
+
+func (g *IGS) Name() string {
+	return fmt.Sprintf("IGS 教学棋盘 (%s)", g.User)
+}
+
+// Reset 首次调用时登录，之后每次开一个新的教学棋盘
+func (g *IGS) Reset(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.conn == nil {
+		if err := g.login(ctx); err != nil {
+			return err
+		}
+	}
+	defer g.interruptOn(ctx)()
+
+	if err := g.send(fmt.Sprintf("teach %d", coords.Size)); err != nil {
+		return fmt.Errorf("创建教学棋盘失败: %v", err)
+	}
+	g.board = board.Board{}
+	return nil
+}
+
+func (g *IGS) HasStone(ctx context.Context, x, y int) (bool, error) {
+	if !coords.Valid(x, y) {
+		return false, fmt.Errorf("坐标超出棋盘: (%d,%d)", x, y)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.board.At(x, y) != board.Empty, nil
+}
+
+// Play 教学棋盘按轮次交替落子，color 只用于本地棋盘（HasStone 据此判断，提子与 IGS 一致）
+func (g *IGS) Play(ctx context.Context, x, y int, color string) error {
+	if !coords.Valid(x, y) {
+		return fmt.Errorf("坐标超出棋盘: (%d,%d)", x, y)
+	}
+	c := board.ParseColor(color)
+	if c == board.Empty {
+		return fmt.Errorf("玩家颜色必须是 B 或 W: %s", color)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.conn == nil {
+		return fmt.Errorf("未连接 IGS")
+	}
+	// 先在副本上按规则落子，提掉的棋子随之移除，不合法（已有棋子、自杀）时不发送
+	next := g.board
+	if _, err := next.Play(x, y, c); err != nil {
+		return err
+	}
+	defer g.interruptOn(ctx)()
+	if err := g.send(coords.Format(x, y, coords.GTP)); err != nil {
+		return fmt.Errorf("转播落子失败: %v", err)
+	}
+	g.board = next
+	return nil
+}
+
+// Close 断开连接
+func (g *IGS) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.conn == nil {
+		return nil
+	}
+	g.send("exit")
+	err := g.conn.Close()
+	g.conn = nil
+	return err
+}
+
+func (g *IGS) login(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: g.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", g.Addr)
+	if err != nil {
+		return fmt.Errorf("连接 IGS 失败: %v", err)
+	}
+	g.conn = conn
+	g.reader = bufio.NewReader(conn)
+	// 登录过程中 ctx 取消时读写都立即返回
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	steps := []struct{ prompt, reply string }{
+		{"Login:", g.User},
+		{"Password:", g.Password},
+	}
+	for _, step := range steps {
+		if err := g.expect(step.prompt); err != nil {
+			g.conn.Close()
+			g.conn = nil
+			return fmt.Errorf("登录 IGS 失败: %v", err)
+		}
+		if err := g.send(step.reply); err != nil {
+			g.conn.Close()
+			g.conn = nil
+			return fmt.Errorf("登录 IGS 失败: %v", err)
+		}
+	}
+
+	if err := g.expect("#>"); err != nil {
+		g.conn.Close()
+		g.conn = nil
+		return fmt.Errorf("登录 IGS 失败（用户名或密码错误？）: %v", err)
+	}
+	if !stop() {
+		g.conn.Close()
+		g.conn = nil
+		return fmt.Errorf("登录 IGS 失败: %v", ctx.Err())
+	}
+
+	// 之后的服务器输出（观战者消息、棋盘刷新等）不需要处理，持续读掉以免阻塞连接
+	go io.Copy(io.Discard, g.reader)
+	return nil
+}
+
+// interruptOn ctx 取消时让连接上正在进行的发送立即超时返回，调用返回的函数停止监视。
+// 只影响写：登录后后台一直在读掉服务器输出，读超时会让它退出
+func (g *IGS) interruptOn(ctx context.Context) func() {
+	conn := g.conn
+	stop := context.AfterFunc(ctx, func() { conn.SetWriteDeadline(time.Now()) })
+	return func() { stop() }
+}
+
+// expect 读取服务器输出直到出现 prompt
+func (g *IGS) expect(prompt string) error {
+	g.conn.SetReadDeadline(time.Now().Add(g.Timeout))
+	defer g.conn.SetReadDeadline(time.Time{})
+
+	var seen strings.Builder
+	for {
+		b, err := g.reader.ReadByte()
+		if err != nil {
+			return fmt.Errorf("等待 %q 超时: %v", prompt, err)
+		}
+		seen.WriteByte(b)
+		if strings.HasSuffix(seen.String(), prompt) {
+			return nil
+		}
+	}
+}
+
+func (g *IGS) send(line string) error {
+	g.conn.SetWriteDeadline(time.Now().Add(g.Timeout))
+	defer g.conn.SetWriteDeadline(time.Time{})
+
+	_, err := fmt.Fprintf(g.conn, "%s\r\n", line)
+	return err
+}
@@ -0,0 +1,122 @@
+package relay
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeIGS 模拟 IGS 的登录流程，记录收到的命令
+func fakeIGS(t *testing.T, password string) (string, <-chan string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	lines := make(chan string, 16)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		defer close(lines)
+
+		r := bufio.NewReader(conn)
+		readLine := func() string {
+			line, _ := r.ReadString('\n')
+			return strings.TrimSpace(line)
+		}
+
+		conn.Write([]byte("Welcome to IGS\r\nLogin: "))
+		readLine()
+		conn.Write([]byte("Password: "))
+		if readLine() != password {
+			conn.Write([]byte("Invalid password.\r\n"))
+			return
+		}
+		conn.Write([]byte("#> "))
+
+		for {
+			line := readLine()
+			if line == "" {
+				return
+			}
+			lines <- line
+			conn.Write([]byte("#> "))
+		}
+	}()
+
+	return ln.Addr().String(), lines
+}
+
+func TestIGSRelay(t *testing.T) {
+	ctx := context.Background()
+	addr, lines := fakeIGS(t, "secret")
+
+	g := NewIGS(addr, "tester", "secret")
+	g.Timeout = 2 * time.Second
+
+	if err := g.Play(ctx, 3, 15, "B"); err == nil {
+		t.Errorf("未连接时 Play 应返回错误")
+	}
+	if err := g.Reset(ctx); err != nil {
+		t.Fatalf("Reset() unexpected error: %v", err)
+	}
+	if err := g.Play(ctx, 3, 15, "B"); err != nil {
+		t.Fatalf("Play(3, 15, B) unexpected error: %v", err)
+	}
+	if err := g.Play(ctx, 15, 3, "W"); err != nil {
+		t.Fatalf("Play(15, 3, W) unexpected error: %v", err)
+	}
+	if err := g.Play(ctx, 19, 3, "B"); err == nil {
+		t.Errorf("Play(19, 3, B) expected error, got nil")
+	}
+	if hasStone, _ := g.HasStone(ctx, 3, 15); !hasStone {
+		t.Errorf("HasStone(3, 15) = false, want true")
+	}
+	// 已有棋子的点不发送
+	if err := g.Play(ctx, 3, 15, "W"); err == nil {
+		t.Errorf("Play(3, 15, W) 落在已有棋子的点上应返回错误")
+	}
+	// 角上的白子被提掉后，本地棋盘上该点为空
+	for _, m := range []struct {
+		x, y  int
+		color string
+	}{{0, 0, "W"}, {1, 0, "B"}, {0, 1, "B"}} {
+		if err := g.Play(ctx, m.x, m.y, m.color); err != nil {
+			t.Fatalf("Play(%d, %d, %s) unexpected error: %v", m.x, m.y, m.color, err)
+		}
+	}
+	if hasStone, _ := g.HasStone(ctx, 0, 0); hasStone {
+		t.Errorf("HasStone(0, 0) = true, want false（白子已被提）")
+	}
+	g.Close()
+
+	var got []string
+	for line := range lines {
+		got = append(got, line)
+	}
+	want := []string{"teach 19", "D16", "Q4", "A1", "B1", "A2", "exit"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("IGS 收到的命令 = %v, want %v", got, want)
+	}
+}
+
+func TestIGSLoginFailed(t *testing.T) {
+	ctx := context.Background()
+	addr, _ := fakeIGS(t, "secret")
+
+	g := NewIGS(addr, "tester", "wrong")
+	g.Timeout = 500 * time.Millisecond
+
+	if err := g.Reset(ctx); err == nil {
+		t.Errorf("密码错误时 Reset() 应返回错误")
+	}
+}
@@ -0,0 +1,220 @@
+package relay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+	"time"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+)
+
+const DefaultKGSURL = "https://www.gokgs.com/json/access"
+
+// KGS 通过 KGS JSON 协议登录，在指定房间开演示棋盘并逐手摆出对局。
+// 协议为 POST 发送消息、GET 长轮询接收消息，会话由 cookie 维持
+type KGS struct {
+	URL      string
+	User     string
+	Password string
+	RoomID   int // 演示棋盘所在的房间
+	Timeout  time.Duration
+
+	mu         sync.Mutex
+	httpClient *http.Client
+	loggedIn   bool
+	channelID  int
+	board      board.Board
+}
+
+// kgsMessage KGS 协议消息，不同类型的字段不同
+type kgsMessage map[string]any
+
+func NewKGS(url, user, password string, roomID int) *KGS {
+	if url == "" {
+		url = DefaultKGSURL
+	}
+	jar, _ := cookiejar.New(nil)
+	return &KGS{
+		URL:        url,
+		User:       user,
+		Password:   password,
+		RoomID:     roomID,
+		Timeout:    30 * time.Second,
+		httpClient: &http.Client{Jar: jar, Timeout: 70 * time.Second},
+	}
+}
+
+func (k *KGS) Name() string {
+	return fmt.Sprintf("KGS 演示棋盘 (%s)", k.User)
+}
+
+// Reset 首次调用时登录，之后每次创建一个新的演示棋盘
+func (k *KGS) Reset(ctx context.Context) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if !k.loggedIn {
+		if err := k.login(ctx); err != nil {
+			return err
+		}
+		k.loggedIn = true
+	}
+
+	err := k.post(ctx, kgsMessage{
+		"type":        "CHALLENGE_CREATE",
+		"channelId":   k.RoomID,
+		"callbackKey": 1,
+		"global":      true,
+		"text":        "goboardsync",
+		"proposal": kgsMessage{
+			"gameType": "demonstration",
+			"rules":    kgsMessage{"size": coords.Size, "rules": "chinese", "komi": 7.5},
+			"players":  []kgsMessage{{"role": "owner", "name": k.User}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("创建演示棋盘失败: %v", err)
+	}
+
+	msg, err := k.wait(ctx, "GAME_JOIN", "CHALLENGE_CREATE_FAILED")
+	if err != nil {
+		return fmt.Errorf("创建演示棋盘失败: %v", err)
+	}
+	if msg["type"] != "GAME_JOIN" {
+		return fmt.Errorf("创建演示棋盘失败: %v", msg["type"])
+	}
+
+	id, _ := msg["channelId"].(float64)
+	k.channelID = int(id)
+	k.board = board.Board{}
+	return nil
+}
+
+func (k *KGS) HasStone(ctx context.Context, x, y int) (bool, error) {
+	if !coords.Valid(x, y) {
+		return false, fmt.Errorf("坐标超出棋盘: (%d,%d)", x, y)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.board.At(x, y) != board.Empty, nil
+}
+
+// Play KGS 坐标以左上角为原点，y 向下递增
+func (k *KGS) Play(ctx context.Context, x, y int, color string) error {
+	if !coords.Valid(x, y) {
+		return fmt.Errorf("坐标超出棋盘: (%d,%d)", x, y)
+	}
+	c := board.ParseColor(color)
+	if c == board.Empty {
+		return fmt.Errorf("玩家颜色必须是 B 或 W: %s", color)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.channelID == 0 {
+		return fmt.Errorf("尚未创建演示棋盘")
+	}
+	// 先在副本上按规则落子，提掉的棋子随之移除，不合法（已有棋子、自杀）时不发送
+	next := k.board
+	if _, err := next.Play(x, y, c); err != nil {
+		return err
+	}
+
+	err := k.post(ctx, kgsMessage{
+		"type":      "GAME_MOVE",
+		"channelId": k.channelID,
+		"loc":       kgsMessage{"x": x, "y": coords.Size - 1 - y},
+	})
+	if err != nil {
+		return fmt.Errorf("转播落子失败: %v", err)
+	}
+	k.board = next
+	return nil
+}
+
+func (k *KGS) login(ctx context.Context) error {
+	err := k.post(ctx, kgsMessage{
+		"type":     "LOGIN",
+		"name":     k.User,
+		"password": k.Password,
+		"locale":   "zh_CN",
+	})
+	if err != nil {
+		return fmt.Errorf("登录 KGS 失败: %v", err)
+	}
+
+	msg, err := k.wait(ctx, "LOGIN_SUCCESS", "LOGIN_FAILED_BAD_PASSWORD", "LOGIN_FAILED_NO_SUCH_USER", "LOGIN_FAILED_USER_ALREADY_EXISTS")
+	if err != nil {
+		return fmt.Errorf("登录 KGS 失败: %v", err)
+	}
+	if msg["type"] != "LOGIN_SUCCESS" {
+		return fmt.Errorf("登录 KGS 失败: %v", msg["type"])
+	}
+	return nil
+}
+
+func (k *KGS) post(ctx context.Context, msg kgsMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// wait 长轮询接收消息，直到收到 types 中的任意一种
+func (k *KGS) wait(ctx context.Context, types ...string) (kgsMessage, error) {
+	deadline := time.Now().Add(k.Timeout)
+	for time.Now().Before(deadline) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.URL, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := k.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Messages []kgsMessage `json:"messages"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("解析响应失败: %v", err)
+		}
+
+		for _, msg := range result.Messages {
+			for _, t := range types {
+				if msg["type"] == t {
+					return msg, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("等待 %v 超时", types)
+}
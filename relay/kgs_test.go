@@ -0,0 +1,78 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKGSRelay(t *testing.T) {
+	ctx := context.Background()
+	var (
+		mu       sync.Mutex
+		received []kgsMessage
+		pending  []kgsMessage
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method == http.MethodPost {
+			var msg kgsMessage
+			json.NewDecoder(r.Body).Decode(&msg)
+			received = append(received, msg)
+
+			switch msg["type"] {
+			case "LOGIN":
+				pending = append(pending, kgsMessage{"type": "LOGIN_SUCCESS"})
+			case "CHALLENGE_CREATE":
+				pending = append(pending, kgsMessage{"type": "GAME_JOIN", "channelId": 4242})
+			}
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{"messages": pending})
+		pending = nil
+	}))
+	defer server.Close()
+
+	k := NewKGS(server.URL, "tester", "secret", 7)
+	k.Timeout = 2 * time.Second
+
+	if err := k.Play(ctx, 3, 15, "B"); err == nil {
+		t.Errorf("未创建演示棋盘时 Play 应返回错误")
+	}
+	if err := k.Reset(ctx); err != nil {
+		t.Fatalf("Reset() unexpected error: %v", err)
+	}
+	if err := k.Play(ctx, 3, 15, "B"); err != nil {
+		t.Fatalf("Play(3, 15, B) unexpected error: %v", err)
+	}
+	if hasStone, _ := k.HasStone(ctx, 3, 15); !hasStone {
+		t.Errorf("HasStone(3, 15) = false, want true")
+	}
+	// 已有棋子的点不发送
+	if err := k.Play(ctx, 3, 15, "W"); err == nil {
+		t.Errorf("Play(3, 15, W) 落在已有棋子的点上应返回错误")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(received) != 3 {
+		t.Fatalf("KGS 收到 %d 条消息, want 3: %v", len(received), received)
+	}
+	move := received[2]
+	if move["type"] != "GAME_MOVE" || move["channelId"] != float64(4242) {
+		t.Errorf("落子消息 = %v, want GAME_MOVE channelId=4242", move)
+	}
+	loc, _ := move["loc"].(map[string]any)
+	if loc["x"] != float64(3) || loc["y"] != float64(3) {
+		t.Errorf("落子位置 = %v, want x=3 y=3（KGS 以左上角为原点）", loc)
+	}
+}
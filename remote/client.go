@@ -0,0 +1,255 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+const (
+	// maxFrameSize 单帧截图的上限，超过时断开重连
+	maxFrameSize = 32 << 20
+	// maxBackoff 重连间隔的上限，从 1 秒起每次失败加倍
+	maxBackoff = 30 * time.Second
+)
+
+// Client 分析端：连接采集端读取截图、发送 adb 命令
+type Client struct {
+	URL   string
+	Token string
+	// HTTPClient 发送 adb 命令用；截图流是长连接，不受它的超时限制
+	HTTPClient *http.Client
+	// FrameTimeout Grab 等待新一帧的最长时间，截图流超过这个时间没有数据时断开重连
+	FrameTimeout time.Duration
+}
+
+func NewClient(url, token string) *Client {
+	return &Client{
+		URL:          strings.TrimRight(url, "/"),
+		Token:        token,
+		HTTPClient:   &http.Client{Timeout: 30 * time.Second},
+		FrameTimeout: 10 * time.Second,
+	}
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.URL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return req, nil
+}
+
+// ADB 让采集端执行一条 adb 命令并返回标准输出，可直接用作 adb.Client.Runner
+func (c *Client) ADB(ctx context.Context, args ...string) ([]byte, error) {
+	data, err := json.Marshal(adbRequest{Args: args})
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, "/adb", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("连接采集端失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("采集端拒绝 adb %s: HTTP %d %s", strings.Join(args, " "), resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	var out adbResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("采集端响应无法解析: %v", err)
+	}
+	if out.Error != "" {
+		return out.Output, errors.New(out.Error)
+	}
+	return out.Output, nil
+}
+
+// Source 从采集端的截图流读取画面，实现 capture.Source。后台保持连接，断开后按退避间隔重连；
+// 只保留最新的一帧，分析端处理不过来时跳过中间的帧
+type Source struct {
+	client *Client
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	frame   []byte
+	err     error
+	seq     uint64
+	taken   uint64
+	updated chan struct{}
+}
+
+// Source 开始读取采集端的截图流，用完后调用 Close
+func (c *Client) Source() *Source {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Source{client: c, cancel: cancel, done: make(chan struct{}), updated: make(chan struct{})}
+	go s.run(ctx)
+	return s
+}
+
+// Grab 返回比上次更新的一帧，FrameTimeout 内没有收到或 ctx 取消时返回错误
+func (s *Source) Grab(ctx context.Context) (gocv.Mat, error) {
+	data, err := s.next(ctx)
+	if err != nil {
+		return gocv.Mat{}, err
+	}
+	img, err := gocv.IMDecode(data, gocv.IMReadColor)
+	if err != nil {
+		return gocv.Mat{}, err
+	}
+	if img.Empty() {
+		img.Close()
+		return gocv.Mat{}, fmt.Errorf("无法解码采集端的截图")
+	}
+	return img, nil
+}
+
+func (s *Source) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// next 等待比上次取走的更新的一帧，返回 JPEG 数据或采集端报告的截图错误
+func (s *Source) next(ctx context.Context) ([]byte, error) {
+	timer := time.NewTimer(s.client.FrameTimeout)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		if s.seq != s.taken {
+			s.taken = s.seq
+			data, err := s.frame, s.err
+			s.mu.Unlock()
+			return data, err
+		}
+		updated := s.updated
+		s.mu.Unlock()
+
+		select {
+		case <-updated:
+		case <-timer.C:
+			return nil, fmt.Errorf("%v 内没有收到采集端的截图", s.client.FrameTimeout)
+		case <-s.done:
+			return nil, fmt.Errorf("截图流已关闭")
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (s *Source) publish(data []byte, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.frame, s.err = data, err
+	s.seq++
+	close(s.updated)
+	s.updated = make(chan struct{})
+}
+
+// run 保持与采集端的连接直到 ctx 取消，断开后按 1、2、4…秒（最多 maxBackoff）重连，收到过画面后重新从 1 秒算起
+func (s *Source) run(ctx context.Context) {
+	defer close(s.done)
+	backoff := time.Second
+	for {
+		received, err := s.stream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			backoff = time.Second
+		}
+		fmt.Printf("[%s] 🔌 与采集端的连接断开: %v，%v 后重连\n", time.Now().Format("15:04:05"), err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// stream 读取一次截图流直到出错，received 表示这次连接收到过数据
+func (s *Source) stream(ctx context.Context) (received bool, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// 超过 FrameTimeout 没有新的一帧时视为连接已卡住
+	stall := time.AfterFunc(s.client.FrameTimeout, cancel)
+	defer stall.Stop()
+
+	req, err := s.client.newRequest(ctx, http.MethodGet, "/frames", nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	_, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || params["boundary"] == "" {
+		return false, fmt.Errorf("截图流格式错误: %q", resp.Header.Get("Content-Type"))
+	}
+	fmt.Printf("[%s] 🔗 已连接采集端 %s\n", time.Now().Format("15:04:05"), s.client.URL)
+
+	r := textproto.NewReader(bufio.NewReader(resp.Body))
+	for {
+		line, err := r.ReadLine()
+		if err != nil {
+			return received, err
+		}
+		if line == "" {
+			continue
+		}
+		if line != "--"+params["boundary"] {
+			return received, fmt.Errorf("截图流格式错误: %q", line)
+		}
+		header, err := r.ReadMIMEHeader()
+		if err != nil {
+			return received, err
+		}
+		size, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil || size < 0 || size > maxFrameSize {
+			return received, fmt.Errorf("截图大小错误: %q", header.Get("Content-Length"))
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r.R, data); err != nil {
+			return received, err
+		}
+		stall.Reset(s.client.FrameTimeout)
+		received = true
+
+		if strings.HasPrefix(header.Get("Content-Type"), "image/") {
+			s.publish(data, nil)
+		} else {
+			s.publish(nil, fmt.Errorf("采集端截图失败: %s", data))
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"goboardsync/adb"
+	"goboardsync/uistate"
+)
+
+func TestADB(t *testing.T) {
+	var got []string
+	phone := &adb.Client{Runner: func(ctx context.Context, args ...string) ([]byte, error) {
+		got = args
+		if args[0] == "get-state" {
+			return nil, errors.New("device offline")
+		}
+		return []byte("ok\n"), nil
+	}}
+	srv := httptest.NewServer(NewServer(nil, phone, time.Second, "secret").Handler())
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := NewClient(srv.URL+"/", "secret")
+	out, err := c.ADB(ctx, "shell", "input", "tap", "100", "200")
+	if err != nil || string(out) != "ok\n" {
+		t.Fatalf("ADB() = %q, %v", out, err)
+	}
+	if strings.Join(got, " ") != "shell input tap 100 200" {
+		t.Errorf("采集端执行了 %q", got)
+	}
+
+	if _, err := c.ADB(ctx, "get-state"); err == nil || err.Error() != "device offline" {
+		t.Errorf("adb 失败时 error = %v, 应为采集端的错误", err)
+	}
+	if _, err := c.ADB(ctx, "pull", "/sdcard/a.png", "/tmp/a.png"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("pull 应被拒绝, error = %v", err)
+	}
+	for _, args := range [][]string{
+		{"shell", "cat", "/sdcard/DCIM/Camera/a.jpg"},
+		{"shell", "cat /sdcard/DCIM/Camera/a.jpg"},
+		{"shell", "input", "tap", "1", "2;rm", "-rf", "/sdcard"},
+		{"shell", "input", "text", "a;rm", "-rf", "/sdcard"},
+		{"shell", "dumpsys", "power", "&&", "screencap", "-p", "/sdcard/a.png"},
+		{"shell", "sendevent /dev/input/event2 3 57 1;cat /sdcard/a.png"},
+		{"shell", "am", "start", "-n", "com.a/.B;reboot"},
+		{"shell"},
+	} {
+		got = nil
+		if _, err := c.ADB(ctx, args...); err == nil || !strings.Contains(err.Error(), "403") {
+			t.Errorf("%q 应被拒绝, error = %v", args, err)
+		}
+		if got != nil {
+			t.Errorf("%q 不应在采集端执行", args)
+		}
+	}
+	if _, err := NewClient(srv.URL, "wrong").ADB(ctx, "shell", "true"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("token 错误时 error = %v, 应为 401", err)
+	}
+}
+
+// TestADBAllowed 分析端经 adb、uistate 包发出的命令都在采集端的白名单中
+func TestADBAllowed(t *testing.T) {
+	phone := &adb.Client{Runner: func(ctx context.Context, args ...string) ([]byte, error) {
+		return []byte("ok\n"), nil
+	}}
+	srv := httptest.NewServer(NewServer(nil, phone, time.Second, "").Handler())
+	defer srv.Close()
+
+	ctx := context.Background()
+	rejected := func(err error) bool { return err != nil && strings.Contains(err.Error(), "403") }
+	c := &adb.Client{Runner: NewClient(srv.URL, "").ADB}
+	calls := map[string]func() error{
+		"Tap":        func() error { return c.Tap(ctx, 100, 200) },
+		"State":      func() error { _, err := c.State(ctx); return err },
+		"ScreenOn":   func() error { _, err := c.ScreenOn(ctx); return err },
+		"Locked":     func() error { _, err := c.Locked(ctx); return err },
+		"Foreground": func() error { _, err := c.ForegroundPackage(ctx); return err },
+		"Activity":   func() error { _, err := c.FocusedActivity(ctx); return err },
+		"Uptime":     func() error { _, err := c.Uptime(ctx); return err },
+		"Battery":    func() error { _, err := c.Battery(ctx); return err },
+		"Wake":       func() error { return c.Wake(ctx) },
+		"StartApp":   func() error { return c.StartApp(ctx, "com.example.go") },
+		"Launch":     func() error { return c.Launch(ctx, "com.example.go/.ui.GameActivity") },
+		"InputText":  func() error { return c.InputText(ctx, `it's a "go" game; 1+1 & (x*y) ~ $5 \ |ok|`) },
+		"KeyEvent":   func() error { return c.KeyEvent(ctx, "KEYCODE_ENTER") },
+		"Touch":      func() error { _, err := c.FindTouchDevice(ctx, ""); return err },
+		"Dump":       func() error { _, err := uistate.Dump(ctx, c); return err },
+		"TouchTap": func() error {
+			touch := &adb.Client{Runner: c.Runner, Touch: &adb.TouchDevice{
+				Path: "/dev/input/event2", MaxX: 1079, MaxY: 2399, Width: 1080, Height: 2400, Pressure: true, TouchMajor: true,
+			}}
+			return touch.Tap(ctx, 100, 200)
+		},
+	}
+	for name, call := range calls {
+		if err := call(); rejected(err) {
+			t.Errorf("%s 被采集端拒绝: %v", name, err)
+		}
+	}
+}
+
+func TestSource(t *testing.T) {
+	var n atomic.Int32
+	frame := func(context.Context) ([]byte, error) {
+		if i := n.Add(1); i%3 == 0 {
+			return nil, errors.New("screencap failed")
+		}
+		return []byte("jpeg"), nil
+	}
+	srv := httptest.NewServer(NewServer(frame, nil, 10*time.Millisecond, "").Handler())
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "")
+	c.FrameTimeout = 5 * time.Second
+	s := c.Source()
+	defer s.Close()
+
+	var frames, failures int
+	for frames < 2 || failures < 1 {
+		data, err := s.next(context.Background())
+		switch {
+		case err != nil && strings.Contains(err.Error(), "screencap failed"):
+			failures++
+		case err != nil:
+			t.Fatal(err)
+		case string(data) != "jpeg":
+			t.Fatalf("next() = %q", data)
+		default:
+			frames++
+		}
+	}
+}
+
+func TestSourceReconnect(t *testing.T) {
+	s := NewServer(func(context.Context) ([]byte, error) { return []byte("jpeg"), nil }, nil, 10*time.Millisecond, "")
+	srv := httptest.NewServer(s.Handler())
+	c := NewClient(srv.URL, "")
+	c.FrameTimeout = 5 * time.Second
+	src := c.Source()
+
+	if _, err := src.next(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟采集端重启：断开连接，稍后在同一地址重新监听
+	addr := srv.Listener.Addr().String()
+	srv.CloseClientConnections()
+	srv.Close()
+	restarted := httptest.NewUnstartedServer(s.Handler())
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("无法在原地址重新监听: %v", err)
+	}
+	restarted.Listener = ln
+	restarted.Start()
+	defer restarted.Close()
+	defer src.Close()
+
+	// 丢掉断开前缓存的帧，之后必须是重连后收到的
+	src.mu.Lock()
+	src.taken = src.seq
+	src.mu.Unlock()
+	if _, err := src.next(context.Background()); err != nil {
+		t.Fatalf("重连后 next() error = %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+// Package remote 把同步拆到两台机器上：手机旁的小设备（采集端）负责截图与点击，
+// 性能强的机器（分析端）负责识别并与 KaTrain 同步。两端通过 HTTP 连接：采集端把截图以 MJPEG 流
+// 推给分析端，分析端把 adb 命令（点击、操作流程、设备状态）发给采集端执行，断开后分析端自动重连。
+package remote
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"goboardsync/adb"
+	"goboardsync/uistate"
+)
+
+// Server 采集端：截图以 MJPEG 流提供给分析端（/frames），并代分析端执行 adb 命令（/adb）
+type Server struct {
+	// Frame 返回一帧 JPEG 截图，ctx 为请求的 context，分析端断开时取消
+	Frame func(ctx context.Context) ([]byte, error)
+	// Phone 执行分析端发来的 adb 命令，为 nil 时不接受命令
+	Phone *adb.Client
+	// Interval 流中两帧之间的最短间隔
+	Interval time.Duration
+	// Token 不为空时要求请求带 Authorization: Bearer <Token>
+	Token string
+
+	// mu 多个分析端同时连接时依次截图
+	mu sync.Mutex
+}
+
+func NewServer(frame func(ctx context.Context) ([]byte, error), phone *adb.Client, interval time.Duration, token string) *Server {
+	return &Server{Frame: frame, Phone: phone, Interval: interval, Token: token}
+}
+
+// boundary 截图流各段之间的分隔
+const boundary = "goboardsync-frame"
+
+// shellCommands 分析端可以让采集端执行的 adb shell 命令，与 adb、uistate 包发出的命令一一对应。
+// adb 把参数以空格连接后交给手机上的 shell 解析，所以按连接后的整条命令匹配，不允许 ;、&&、| 等连接其他命令
+// （sendevent 点击脚本除外，只能由 sendevent 组成）。pull、push、install 等读写文件的子命令一律不接受
+var shellCommands = []*regexp.Regexp{
+	regexp.MustCompile(`^input tap \d+ \d+$`),
+	regexp.MustCompile(`^input keyevent \w+$`),
+	// input text 的参数经过 adb.InputText 转义：shell 的特殊字符前都有反斜杠
+	regexp.MustCompile(`^input text (?:[^\s"'` + "`" + `$&|;<>()*~\\]|\\[\\"'` + "`" + `$&|;<>()*~])+$`),
+	regexp.MustCompile(`^dumpsys (?:power|window|battery|input)$`),
+	regexp.MustCompile(`^cat /proc/uptime$`),
+	regexp.MustCompile(`^cat ` + regexp.QuoteMeta(uistate.DumpPath) + `$`),
+	regexp.MustCompile(`^uiautomator dump ` + regexp.QuoteMeta(uistate.DumpPath) + `$`),
+	regexp.MustCompile(`^getevent -pl$`),
+	regexp.MustCompile(`^sendevent /dev/input/event\d+ -?\d+ -?\d+ -?\d+(?:;sendevent /dev/input/event\d+ -?\d+ -?\d+ -?\d+)*$`),
+	regexp.MustCompile(`^wm (?:size|dismiss-keyguard)$`),
+	regexp.MustCompile(`^am start -n [\w.]+/[\w.]+$`),
+	regexp.MustCompile(`^monkey -p [\w.]+ -c android\.intent\.category\.LAUNCHER 1$`),
+}
+
+// allowed 分析端发来的 adb 命令是否可以执行：get-state 或 shellCommands 中的 shell 命令
+func allowed(args []string) bool {
+	switch args[0] {
+	case "get-state":
+		return len(args) == 1
+	case "shell":
+		command := strings.Join(args[1:], " ")
+		for _, re := range shellCommands {
+			if re.MatchString(command) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// adbRequest、adbResponse 为 /adb 的请求与响应。adb 执行失败时仍返回 200，错误放在 Error 中
+type adbRequest struct {
+	Args []string `json:"args"`
+}
+
+type adbResponse struct {
+	Output []byte `json:"output"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Handler 返回采集端的 HTTP 路由：/frames、/adb，以及不需要认证的 /healthz
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	mux.Handle("/frames", s.authorize(http.HandlerFunc(s.serveFrames)))
+	mux.Handle("/adb", s.authorize(http.HandlerFunc(s.serveADB)))
+	return mux
+}
+
+// ListenAndServe 启动采集端服务
+func (s *Server) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, s.Handler())
+}
+
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if s.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveFrames 每隔 Interval 截一帧，以 multipart/x-mixed-replace 推送，直到分析端断开。
+// 截图失败时推送一段 text/plain 的错误信息，分析端的 Grab 会原样返回
+func (s *Server) serveFrames(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary="+boundary)
+	w.Header().Set("Cache-Control", "no-store")
+
+	for {
+		start := time.Now()
+		s.mu.Lock()
+		data, err := s.Frame(r.Context())
+		s.mu.Unlock()
+
+		contentType := "image/jpeg"
+		if err != nil {
+			contentType = "text/plain; charset=utf-8"
+			data = []byte(err.Error())
+		}
+		// 每段带 Content-Length，分析端读完这一段就能处理，不用等下一段的分隔行
+		if _, err := fmt.Fprintf(w, "--%s\r\nContent-Type: %s\r\nContent-Length: %d\r\n\r\n", boundary, contentType, len(data)); err != nil {
+			return
+		}
+		if _, err := w.Write(data); err != nil {
+			return
+		}
+		if _, err := w.Write([]byte("\r\n")); err != nil {
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(s.Interval - time.Since(start)):
+		}
+	}
+}
+
+func (s *Server) serveADB(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req adbRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Args) == 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if s.Phone == nil || !allowed(req.Args) {
+		http.Error(w, fmt.Sprintf("不允许的 adb 命令: %s", strings.Join(req.Args, " ")), http.StatusForbidden)
+		return
+	}
+
+	var resp adbResponse
+	out, err := s.Phone.Output(r.Context(), req.Args...)
+	resp.Output = out
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,199 @@
+// Package scrcpy 把 scrcpy 作为受监管的子进程运行：进程退出（崩溃、设备重连）后按退避时间自动重启。
+package scrcpy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"goboardsync/platform"
+)
+
+// Status 进程当前状态
+type Status struct {
+	Running   bool
+	PID       int
+	Restarts  int
+	LastError string
+	StartedAt time.Time
+}
+
+// Supervisor scrcpy 进程监管
+type Supervisor struct {
+	Path string
+	Args []string
+
+	MinBackoff time.Duration // 首次重启前的等待时间
+	MaxBackoff time.Duration // 等待时间翻倍增长的上限
+	// StableAfter 进程运行超过该时长后退出视为偶发，退避时间重置为 MinBackoff
+	StableAfter time.Duration
+
+	// ReadyCheck 判断 scrcpy 是否已就绪（如投屏窗口已出现），为 nil 时进程持续运行 ReadyAfter 即视为就绪
+	ReadyCheck func() bool
+	ReadyAfter time.Duration
+
+	// OnStatus 状态变化时回调，用于日志与看板
+	OnStatus func(Status)
+
+	mu        sync.Mutex
+	status    Status
+	ready     chan struct{}
+	readyOnce sync.Once
+}
+
+// NewSupervisor 以窗口标题和额外参数创建监管器
+func NewSupervisor(windowTitle string, extraArgs ...string) *Supervisor {
+	path, err := platform.FindTool("scrcpy")
+	if err != nil {
+		path = "scrcpy"
+	}
+
+	args := []string{"--window-title", windowTitle}
+	return &Supervisor{
+		Path:        path,
+		Args:        append(args, extraArgs...),
+		MinBackoff:  time.Second,
+		MaxBackoff:  30 * time.Second,
+		StableAfter: 10 * time.Second,
+		ReadyCheck:  WindowCheck(windowTitle),
+		ReadyAfter:  2 * time.Second,
+	}
+}
+
+// WaitReady 等待 scrcpy 首次就绪，超时或 ctx 取消时返回错误
+func (s *Supervisor) WaitReady(ctx context.Context, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-s.readyChan():
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("等待 scrcpy 就绪超时（%s）", timeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Supervisor) readyChan() chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ready == nil {
+		s.ready = make(chan struct{})
+	}
+	return s.ready
+}
+
+// watchReady 进程运行期间轮询就绪状态，就绪后通知 WaitReady
+func (s *Supervisor) watchReady(exited <-chan struct{}) {
+	ready := s.readyChan()
+	select {
+	case <-ready:
+		return
+	default:
+	}
+
+	started := time.Now()
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-exited:
+			return
+		case <-ticker.C:
+			ok := false
+			if s.ReadyCheck != nil {
+				ok = s.ReadyCheck()
+			} else {
+				ok = time.Since(started) >= s.ReadyAfter
+			}
+			if ok {
+				s.readyOnce.Do(func() { close(ready) })
+				return
+			}
+		}
+	}
+}
+
+// Status 返回当前状态
+func (s *Supervisor) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Run 启动 scrcpy 并在退出后重启，直到 ctx 取消
+func (s *Supervisor) Run(ctx context.Context) {
+	backoff := s.MinBackoff
+
+	for {
+		started := time.Now()
+		err := s.runOnce(ctx)
+		if ctx.Err() != nil {
+			s.update(func(st *Status) { st.Running = false; st.PID = 0 })
+			return
+		}
+
+		if time.Since(started) >= s.StableAfter {
+			backoff = s.MinBackoff
+		}
+
+		msg := "进程已退出"
+		if err != nil {
+			msg = err.Error()
+		}
+		s.update(func(st *Status) {
+			st.Running = false
+			st.PID = 0
+			st.LastError = fmt.Sprintf("%s，%s 后重启", msg, backoff)
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		s.update(func(st *Status) { st.Restarts++ })
+		backoff *= 2
+		if backoff > s.MaxBackoff {
+			backoff = s.MaxBackoff
+		}
+	}
+}
+
+func (s *Supervisor) runOnce(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, s.Path, s.Args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("启动 scrcpy 失败: %v", err)
+	}
+	s.update(func(st *Status) {
+		st.Running = true
+		st.PID = cmd.Process.Pid
+		st.StartedAt = time.Now()
+	})
+
+	exited := make(chan struct{})
+	defer close(exited)
+	go s.watchReady(exited)
+
+	return cmd.Wait()
+}
+
+func (s *Supervisor) update(fn func(*Status)) {
+	s.mu.Lock()
+	fn(&s.status)
+	status := s.status
+	s.mu.Unlock()
+
+	if s.OnStatus != nil {
+		s.OnStatus(status)
+	}
+}
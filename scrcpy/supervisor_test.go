@@ -0,0 +1,105 @@
+package scrcpy
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSupervisorRestart(t *testing.T) {
+	s := &Supervisor{
+		Path:        "sh",
+		Args:        []string{"-c", "exit 1"},
+		MinBackoff:  10 * time.Millisecond,
+		MaxBackoff:  40 * time.Millisecond,
+		StableAfter: time.Hour,
+	}
+
+	var mu sync.Mutex
+	var history []Status
+	s.OnStatus = func(st Status) {
+		mu.Lock()
+		history = append(history, st)
+		mu.Unlock()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	status := s.Status()
+	if status.Running {
+		t.Errorf("Run() 返回后 Running = true")
+	}
+	// 退避 10+20+40+40... 毫秒，300 毫秒内至少重启 3 次
+	if status.Restarts < 3 {
+		t.Errorf("Restarts = %d, want >= 3", status.Restarts)
+	}
+	if status.LastError == "" {
+		t.Errorf("LastError 为空")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	sawRunning := false
+	for _, st := range history {
+		if st.Running && st.PID > 0 {
+			sawRunning = true
+		}
+	}
+	if !sawRunning {
+		t.Errorf("OnStatus 未报告运行状态")
+	}
+}
+
+func TestSupervisorMissingBinary(t *testing.T) {
+	s := NewSupervisor("my_phone")
+	s.Path = "/nonexistent/scrcpy"
+	s.MinBackoff = 5 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	if s.Status().Restarts == 0 {
+		t.Errorf("找不到程序时也应按退避重试")
+	}
+}
+
+func TestSupervisorWaitReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		script    string
+		check     func() bool
+		wantReady bool
+	}{
+		{"进程持续运行", "exec sleep 5", nil, true},
+		{"进程立即退出", "exit 1", nil, false},
+		{"窗口出现", "exec sleep 5", func() bool { return true }, true},
+		{"窗口未出现", "exec sleep 5", func() bool { return false }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Supervisor{
+				Path:        "sh",
+				Args:        []string{"-c", tt.script},
+				MinBackoff:  time.Second,
+				MaxBackoff:  time.Second,
+				StableAfter: time.Hour,
+				ReadyCheck:  tt.check,
+				ReadyAfter:  100 * time.Millisecond,
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go s.Run(ctx)
+
+			err := s.WaitReady(ctx, 600*time.Millisecond)
+			if ready := err == nil; ready != tt.wantReady {
+				t.Errorf("WaitReady() error = %v, want ready %v", err, tt.wantReady)
+			}
+		})
+	}
+}
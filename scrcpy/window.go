@@ -0,0 +1,17 @@
+package scrcpy
+
+import (
+	"goboardsync/platform"
+)
+
+// WindowCheck 返回检查标题为 title 的窗口是否存在的函数；系统不支持窗口检测时返回 nil，
+// 由调用方退回到进程存活检查
+func WindowCheck(title string) func() bool {
+	if _, err := platform.WindowExists(title); err != nil {
+		return nil
+	}
+	return func() bool {
+		ok, _ := platform.WindowExists(title)
+		return ok
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// LogFile 按大小轮转的日志文件：超过 MaxBytes 时把 path 改名为 path.1（原 path.1 改为 path.2，依此类推），
+// 最多保留 Keep 个旧文件
+type LogFile struct {
+	Path     string
+	MaxBytes int64
+	Keep     int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// OpenLogFile 以追加方式打开日志文件，目录不存在时创建；maxBytes 为 0 时不轮转
+func OpenLogFile(path string, maxBytes int64, keep int) (*LogFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("创建日志目录失败: %v", err)
+	}
+	l := &LogFile{Path: path, MaxBytes: maxBytes, Keep: keep}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *LogFile) open() error {
+	f, err := os.OpenFile(l.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("打开日志文件失败: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("打开日志文件失败: %v", err)
+	}
+	l.f, l.size = f, info.Size()
+	return nil
+}
+
+// Write 写入日志，写入后超过 MaxBytes 时轮转
+func (l *LogFile) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.f == nil {
+		return 0, os.ErrClosed
+	}
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+	if err == nil && l.MaxBytes > 0 && l.size >= l.MaxBytes {
+		err = l.rotate()
+	}
+	return n, err
+}
+
+// rotate 关闭当前文件，依次把旧文件序号加一（超出 Keep 的删除），再新建 Path
+func (l *LogFile) rotate() error {
+	l.f.Close()
+	l.f = nil
+
+	os.Remove(fmt.Sprintf("%s.%d", l.Path, l.Keep))
+	for i := l.Keep - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.Path, i), fmt.Sprintf("%s.%d", l.Path, i+1))
+	}
+	if l.Keep > 0 {
+		os.Rename(l.Path, l.Path+".1")
+	} else {
+		os.Remove(l.Path)
+	}
+	return l.open()
+}
+
+// Close 关闭日志文件
+func (l *LogFile) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
@@ -0,0 +1,63 @@
+// Package service 支持作为长期运行的系统服务（systemd / launchd）运行：PID 文件、按大小轮转的日志文件、
+// 区分配置错误与暂时故障的退出码，以及生成 systemd unit 与 launchd plist。
+package service
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"goboardsync/workdir"
+)
+
+// 退出码，取自 sysexits.h。服务管理器据此决定是否重启：配置错误重启也不会成功，暂时故障（设备未连接等）可以重试
+const (
+	ExitTransient = 75 // EX_TEMPFAIL
+	ExitConfig    = 78 // EX_CONFIG
+)
+
+// WritePIDFile 把当前进程号写入 path，返回的函数删除该文件。
+// 文件中记录的进程仍在运行时返回错误，避免两个实例同时操作同一台手机
+func WritePIDFile(path string) (func(), error) {
+	if data, err := os.ReadFile(path); err == nil {
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && pid != os.Getpid() && workdir.ProcessAlive(pid) {
+			return nil, fmt.Errorf("已有实例在运行 (pid %d, %s)", pid, path)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("创建 PID 文件目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644); err != nil {
+		return nil, fmt.Errorf("写入 PID 文件失败: %v", err)
+	}
+	return func() { os.Remove(path) }, nil
+}
+
+// RedirectOutput 把 os.Stdout 与 os.Stderr 改写到 w（程序的日志都打印到标准输出），
+// 返回的函数恢复原来的输出，并等待已打印的内容写完
+func RedirectOutput(w io.Writer) (func(), error) {
+	r, pw, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = pw, pw
+
+	done := make(chan struct{})
+	go func() {
+		io.Copy(w, r)
+		r.Close()
+		close(done)
+	}()
+
+	return func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		pw.Close()
+		<-done
+	}, nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWritePIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "goboardsync.pid")
+
+	remove, err := WritePIDFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.TrimSpace(string(data)) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("PID 文件内容 = %q", data)
+	}
+	remove()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("remove 后 PID 文件仍存在")
+	}
+
+	// 父进程（go test）仍在运行，视为已有实例
+	os.WriteFile(path, []byte(strconv.Itoa(os.Getppid())), 0o644)
+	if _, err := WritePIDFile(path); err == nil {
+		t.Errorf("记录的进程仍在运行时应返回错误")
+	}
+}
+
+func TestLogFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "goboardsync.log")
+	l, err := OpenLogFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 4; i++ {
+		fmt.Fprintf(l, "line %d...\n", i)
+	}
+	l.Close()
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{path, ""},
+		{path + ".1", "line 4...\n"},
+		{path + ".2", "line 3...\n"},
+	}
+	for _, tt := range tests {
+		got, err := os.ReadFile(tt.file)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s = %q, %v, want %q", filepath.Base(tt.file), got, err, tt.want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("超出 Keep 的旧日志应删除")
+	}
+}
+
+func TestUnits(t *testing.T) {
+	u := Unit{
+		Name:    "goboardsync",
+		Exec:    "/opt/goboard sync/goboardsync",
+		Args:    []string{"-service", "-config", "/etc/goboardsync.conf"},
+		WorkDir: "/var/lib/goboardsync",
+		Env:     []string{"GOBOARDSYNC_ADB_SERIAL=192.168.1.5:5555", "GOBOARDSYNC_RESUME_FLOW=tap 600,2300 & wait 1s"},
+		LogDir:  "/var/log/goboardsync",
+	}
+
+	unit := SystemdUnit(u)
+	for _, want := range []string{
+		`ExecStart="/opt/goboard sync/goboardsync" -service -config /etc/goboardsync.conf`,
+		"WorkingDirectory=/var/lib/goboardsync",
+		`Environment="GOBOARDSYNC_RESUME_FLOW=tap 600,2300 & wait 1s"`,
+		"RestartPreventExitStatus=78",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("systemd unit 缺少 %q:\n%s", want, unit)
+		}
+	}
+
+	plist := LaunchdPlist(u)
+	if err := xml.Unmarshal([]byte(plist), new(any)); err != nil {
+		t.Errorf("plist 不是合法的 XML: %v\n%s", err, plist)
+	}
+	for _, want := range []string{
+		"<string>/opt/goboard sync/goboardsync</string>",
+		"<key>GOBOARDSYNC_RESUME_FLOW</key>",
+		"<string>tap 600,2300 &amp; wait 1s</string>",
+		"<string>/var/log/goboardsync/launchd.log</string>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist 缺少 %q:\n%s", want, plist)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"text/template"
+)
+
+// Unit 生成服务配置所需的信息
+type Unit struct {
+	// Name 服务名，systemd 的 unit 文件名与 launchd 的 Label
+	Name string
+	// Exec 可执行文件的绝对路径，Args 为启动参数（通常包含 -service）
+	Exec    string
+	Args    []string
+	WorkDir string
+	// Env 传给程序的环境变量（KEY=value）
+	Env []string
+	// LogDir 日志目录，launchd 把程序自身写不进日志文件的输出（如崩溃信息）也写到这里
+	LogDir string
+}
+
+var systemdTemplate = template.Must(template.New("systemd").Funcs(template.FuncMap{
+	"quote": systemdQuote,
+}).Parse(`[Unit]
+Description=goboardsync 手机与 KaTrain 同步
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=simple
+ExecStart={{quote .Exec}}{{range .Args}} {{quote .}}{{end}}
+{{- if .WorkDir}}
+WorkingDirectory={{quote .WorkDir}}
+{{- end}}
+{{- range .Env}}
+Environment={{quote .}}
+{{- end}}
+Restart=on-failure
+RestartSec=5
+# 配置错误（退出码 78）时不重启，修改配置后手动 systemctl restart
+RestartPreventExitStatus=78
+
+[Install]
+WantedBy=default.target
+`))
+
+// SystemdUnit 生成 systemd unit：异常退出后 5 秒重启，配置错误时不重启
+func SystemdUnit(u Unit) string {
+	var b bytes.Buffer
+	systemdTemplate.Execute(&b, u)
+	return b.String()
+}
+
+// systemdQuote 含空白、引号或反斜杠的参数加双引号
+func systemdQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+var launchdTemplate = template.Must(template.New("launchd").Funcs(template.FuncMap{
+	"xml": func(s string) string {
+		var b bytes.Buffer
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	},
+	"envKey": func(kv string) string {
+		k, _, _ := strings.Cut(kv, "=")
+		return k
+	},
+	"envValue": func(kv string) string {
+		_, v, _ := strings.Cut(kv, "=")
+		return v
+	},
+}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{xml .Name}}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{xml .Exec}}</string>
+{{- range .Args}}
+		<string>{{xml .}}</string>
+{{- end}}
+	</array>
+{{- if .WorkDir}}
+	<key>WorkingDirectory</key>
+	<string>{{xml .WorkDir}}</string>
+{{- end}}
+{{- if .Env}}
+	<key>EnvironmentVariables</key>
+	<dict>
+{{- range .Env}}
+		<key>{{xml (envKey .)}}</key>
+		<string>{{xml (envValue .)}}</string>
+{{- end}}
+	</dict>
+{{- end}}
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>ThrottleInterval</key>
+	<integer>10</integer>
+{{- if .LogDir}}
+	<key>StandardOutPath</key>
+	<string>{{xml .LogDir}}/launchd.log</string>
+	<key>StandardErrorPath</key>
+	<string>{{xml .LogDir}}/launchd.log</string>
+{{- end}}
+</dict>
+</plist>
+`))
+
+// LaunchdPlist 生成 launchd plist：登录后启动，非正常退出后至少间隔 10 秒重启。
+// launchd 不能按退出码区分是否重启，配置错误时也会反复重试，需查看日志
+func LaunchdPlist(u Unit) string {
+	var b bytes.Buffer
+	launchdTemplate.Execute(&b, u)
+	return b.String()
+}
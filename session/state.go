@@ -0,0 +1,280 @@
+// Package session 保存一次同步会话中两个方向共享的状态，所有读写都在锁内完成，可被多个协程并发调用。
+package session
+
+import (
+	"sync"
+	"time"
+)
+
+// Last 某一方最后处理过的一手，X/Y 的坐标系由调用方决定
+// （手机方向使用手机坐标，KaTrain 方向使用 KaTrain 坐标）
+type Last struct {
+	Move int
+	X, Y int
+}
+
+// Move 手机上识别到的一手。Position 为这手落下后的局面哈希（已有棋子时为当前局面），
+// 由调用方按本地棋盘模型计算，0 表示未知。Stamp 为截图时手机的开机时长（见 capture.Stamped），0 表示未知
+type Move struct {
+	Number   int
+	X, Y     int
+	Color    string
+	Position uint64
+	Stamp    time.Duration
+}
+
+// Verdict ObservePhone 对识别到的一手的判断
+type Verdict int
+
+const (
+	// Duplicate 已经处理过的一手
+	Duplicate Verdict = iota
+	// New 新的一手，已记为手机的最后一手
+	New
+	// Jump 坐标是新的，但手数不是上一手加一，可能是 OCR 误读了手数（如 12 读成 72），暂不处理
+	Jump
+	// Stale 截图早于已处理过的一帧，截图乱序到达（如流水线截图），画面已过时，不处理
+	Stale
+)
+
+// JumpFrames 同一个手数跳变连续出现这么多次后才认作新手（确实漏看了中间的棋步）
+const JumpFrames = 3
+
+// moveKey 手机上一手棋的身份：落子后的局面、坐标与颜色
+type moveKey struct {
+	position uint64
+	x, y     int
+	color    string
+}
+
+// State 同步会话状态
+type State struct {
+	mu                sync.Mutex
+	phone             Last
+	katrain           Last
+	divergenceAlerted bool
+	// seen 手机方向已处理过的棋步；jump 为尚未确认的手数跳变及其连续出现的次数
+	seen      map[moveKey]bool
+	jump      moveKey
+	jumpMove  int
+	jumpCount int
+	// stamp 已处理过的截图中最新的时间戳
+	stamp time.Duration
+	// liveMove 实战局面中见过的最大手数，reviewing 手机是否正在显示复盘或变化图；
+	// liveJump 为尚未确认的实战手数跳变及其连续出现的次数
+	liveMove      int
+	reviewing     bool
+	liveJump      int
+	liveJumpCount int
+	// echoes 已从手机同步到 KaTrain、还没在 KaTrain 方向见到的棋步，按 KaTrain 手数记录坐标（KaTrain 坐标）
+	echoes map[int][2]int
+}
+
+// ReviewStoneSlack 盘面棋子数允许超出手数的误差（交叉点分类偶有误判）
+const ReviewStoneSlack = 2
+
+func NewState() *State {
+	return &State{}
+}
+
+// ObservePhone 判断手机上识别到的一手是否为新手，是新手时立即记为最后一手，返回更新前的记录。
+// 坐标与上一手相同，或 (局面, 坐标, 颜色) 已处理过时为 Duplicate；手数已知且不是上一手加一时为 Jump，
+// 同样的跳变连续出现 JumpFrames 次后才记为新手；带时间戳的截图早于此前处理过的截图时为 Stale。
+// 检查与更新在同一把锁内完成，两个协程不会同时认领同一手
+func (s *State) ObservePhone(m Move) (Last, Verdict) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := s.phone
+	if m.Stamp > 0 {
+		if m.Stamp < s.stamp {
+			return prev, Stale
+		}
+		s.stamp = m.Stamp
+	}
+	key := moveKey{position: m.Position, x: m.X, y: m.Y, color: m.Color}
+	if (prev.X == m.X && prev.Y == m.Y) || (m.Position != 0 && s.seen[key]) {
+		s.jumpCount = 0
+		return prev, Duplicate
+	}
+
+	if prev.Move > 0 && m.Number > 0 && m.Number != prev.Move+1 {
+		if s.jumpCount == 0 || s.jump != key || s.jumpMove != m.Number {
+			s.jump, s.jumpMove, s.jumpCount = key, m.Number, 0
+		}
+		s.jumpCount++
+		if s.jumpCount < JumpFrames {
+			return prev, Jump
+		}
+	}
+
+	s.jumpCount = 0
+	s.phone = Last{Move: m.Number, X: m.X, Y: m.Y}
+	if m.Position != 0 {
+		if s.seen == nil {
+			s.seen = make(map[moveKey]bool)
+		}
+		s.seen[key] = true
+	}
+	return prev, New
+}
+
+// ObserveKatrain 坐标与 KaTrain 上一手不同时记为新手并立即更新，返回更新前的记录。
+// KaTrain 的手数可靠，不做跳变检查。ExpectEcho 在同一手数记下的棋步只更新记录，不算新手；
+// 手数不大于 move 的其余记录不会再出现（被 KaTrain 上的其他棋步取代或已悔棋），一并丢弃
+func (s *State) ObserveKatrain(move, x, y int) (Last, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	echo, ok := s.echoes[move]
+	for n := range s.echoes {
+		if n <= move {
+			delete(s.echoes, n)
+		}
+	}
+	if ok && echo == [2]int{x, y} {
+		prev := s.katrain
+		s.katrain = Last{Move: move, X: x, Y: y}
+		return prev, false
+	}
+	return s.observeLocked(&s.katrain, move, x, y)
+}
+
+// ExpectEcho 记下即将作为 KaTrain 第 move 手从手机同步过去的一手。轮询 KaTrain 时可能先读到这手、
+// 后读到手机上的下一手，只比较最后一手无法分辨，需要逐手记录，避免把它当作 KaTrain 的新手点回手机。
+// 同步失败时用 CancelEcho 撤销
+func (s *State) ExpectEcho(move, x, y int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.echoes == nil {
+		s.echoes = make(map[int][2]int)
+	}
+	s.echoes[move] = [2]int{x, y}
+}
+
+// CancelEcho 撤销 ExpectEcho 在第 move 手记下的棋步
+func (s *State) CancelEcho(move int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.echoes, move)
+}
+
+func (s *State) observeLocked(last *Last, move, x, y int) (Last, bool) {
+	prev := *last
+	if prev.X == x && prev.Y == y {
+		return prev, false
+	}
+	*last = Last{Move: move, X: x, Y: y}
+	return prev, true
+}
+
+// SetPhone 直接设置手机方向的最后一手，例如程序自己在手机上点出的棋步
+func (s *State) SetPhone(last Last) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.phone = last
+}
+
+// SetKatrain 直接设置 KaTrain 方向的最后一手，例如中途接入时程序自己摆到 KaTrain 上的局面
+func (s *State) SetKatrain(last Last) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.katrain = last
+}
+
+// Phone 返回手机方向的最后一手
+func (s *State) Phone() Last {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.phone
+}
+
+// Katrain 返回 KaTrain 方向的最后一手
+func (s *State) Katrain() Last {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.katrain
+}
+
+// Reset 清除两个方向的记录，下一轮重新比较
+func (s *State) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.phone = Last{}
+	s.katrain = Last{}
+	s.liveMove = 0
+	s.reviewing = false
+	s.liveJumpCount = 0
+	s.echoes = nil
+	s.seen = nil
+	s.jumpCount = 0
+	s.stamp = 0
+}
+
+// advanceLive 用实战局面中的手数推进 liveMove，调用方需持有 s.mu
+func (s *State) advanceLive(move int) {
+	switch {
+	case move <= s.liveMove:
+		s.liveJumpCount = 0
+	case s.liveMove == 0 || move == s.liveMove+1:
+		s.liveMove = move
+		s.liveJumpCount = 0
+	default:
+		if s.liveJumpCount == 0 || s.liveJump != move {
+			s.liveJump, s.liveJumpCount = move, 0
+		}
+		s.liveJumpCount++
+		if s.liveJumpCount >= JumpFrames {
+			s.liveMove = move
+			s.liveJumpCount = 0
+		}
+	}
+}
+
+// CheckDivergence 比较 KaTrain 手数与手机最后一手的手数，相差超过 threshold 时报告不一致。
+// alert 只在刚进入不一致状态时为 true，恢复一致后重新检测
+func (s *State) CheckDivergence(katrainMove, threshold int) (phoneMove int, alert bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	phoneMove = s.phone.Move
+	diff := katrainMove - phoneMove
+	if diff < 0 {
+		diff = -diff
+	}
+	diverged := phoneMove > 0 && katrainMove > 0 && diff > threshold
+
+	alert = diverged && !s.divergenceAlerted
+	s.divergenceAlerted = diverged
+	return phoneMove, alert
+}
+
+// LiveMove 实战局面中见过的最大手数，手机显示的手数比它小时不需要盘面棋子数就能判断为复盘
+func (s *State) LiveMove() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.liveMove
+}
+
+// CheckReview 根据手机上显示的手数与盘面棋子数（未知时传负数）判断是否进入了 App 的复盘/变化图：
+// 手数比实战中见过的小（回看前面的棋步），或除去 handicap 颗让子后棋子数超过手数（摆出了变化），
+// 都不可能出现在实战局面中。手数回到实战进度后恢复。move 为 0（OCR 未识别到）时保持原状态。changed 表示状态刚发生变化。
+// 实战进度只随上一手加一前进，更大的跳变同 ObservePhone 一样连续出现 JumpFrames 次后才采用，
+// 一帧误读（如 12 读成 72）不会让之后的实战局面都被当作复盘
+func (s *State) CheckReview(move, stones, handicap int) (reviewing, changed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if move == 0 {
+		return s.reviewing, false
+	}
+
+	now := move < s.liveMove || (stones >= 0 && stones-handicap > move+ReviewStoneSlack)
+	if !now {
+		s.advanceLive(move)
+	}
+
+	changed = now != s.reviewing
+	s.reviewing = now
+	return now, changed
+}
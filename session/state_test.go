@@ -0,0 +1,264 @@
+package session
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestObservePhone(t *testing.T) {
+	s := NewState()
+
+	tests := []struct {
+		move, x, y int
+		want       Verdict
+	}{
+		{1, 4, 4, New},
+		{1, 4, 4, Duplicate},
+		{2, 16, 16, New},
+		{0, 16, 16, Duplicate}, // OCR 读不到手数，坐标相同仍视为同一手
+		{3, 4, 16, New},
+	}
+
+	for _, tt := range tests {
+		_, got := s.ObservePhone(Move{Number: tt.move, X: tt.x, Y: tt.y})
+		if got != tt.want {
+			t.Errorf("ObservePhone(%d, %d, %d) = %v, want %v", tt.move, tt.x, tt.y, got, tt.want)
+		}
+	}
+
+	if got := s.Phone(); got != (Last{Move: 3, X: 4, Y: 16}) {
+		t.Errorf("Phone() = %+v, want {3 4 16}", got)
+	}
+	if got := s.Katrain(); got != (Last{}) {
+		t.Errorf("Katrain() = %+v, want 零值", got)
+	}
+
+	s.Reset()
+	if _, got := s.ObservePhone(Move{Number: 3, X: 4, Y: 16}); got != New {
+		t.Errorf("Reset() 后同一手应重新视为新手")
+	}
+}
+
+func TestObservePhonePosition(t *testing.T) {
+	s := NewState()
+	a := Move{Number: 12, X: 4, Y: 4, Color: "B", Position: 100}
+	b := Move{Number: 13, X: 5, Y: 4, Color: "W", Position: 200}
+
+	s.ObservePhone(a)
+	s.ObservePhone(b)
+	// 角标在两点间闪烁：局面没变的 a 已经处理过
+	if _, got := s.ObservePhone(a); got != Duplicate {
+		t.Errorf("局面与坐标都相同的一手 = %v, want Duplicate", got)
+	}
+	// 同一点在另一个局面中再次落子（如打劫）是新的一手
+	if _, got := s.ObservePhone(Move{Number: 14, X: 4, Y: 4, Color: "B", Position: 300}); got != New {
+		t.Errorf("新局面中的同一点 = %v, want New", got)
+	}
+}
+
+func TestObservePhoneJump(t *testing.T) {
+	s := NewState()
+	s.ObservePhone(Move{Number: 11, X: 4, Y: 4})
+
+	// 12 误读成 72：跳变不处理，下一帧读对后正常同步
+	if _, got := s.ObservePhone(Move{Number: 72, X: 16, Y: 16}); got != Jump {
+		t.Errorf("手数跳变 = %v, want Jump", got)
+	}
+	if _, got := s.ObservePhone(Move{Number: 12, X: 16, Y: 16}); got != New {
+		t.Errorf("手数加一 = %v, want New", got)
+	}
+
+	// 确实漏看了一手：同样的跳变连续出现 JumpFrames 次后接受
+	m := Move{Number: 14, X: 3, Y: 3}
+	for i := 1; i < JumpFrames; i++ {
+		if _, got := s.ObservePhone(m); got != Jump {
+			t.Fatalf("第 %d 次跳变 = %v, want Jump", i, got)
+		}
+	}
+	if _, got := s.ObservePhone(m); got != New {
+		t.Errorf("连续 %d 次跳变 = %v, want New", JumpFrames, got)
+	}
+	if got := s.Phone(); got != (Last{Move: 14, X: 3, Y: 3}) {
+		t.Errorf("Phone() = %+v", got)
+	}
+
+	// 手数未知时不检查
+	if _, got := s.ObservePhone(Move{X: 5, Y: 5}); got != New {
+		t.Errorf("手数未知 = %v, want New", got)
+	}
+}
+
+// TestObservePhoneStale 乱序到达的旧截图不处理，即使其中是没见过的一手
+func TestObservePhoneStale(t *testing.T) {
+	s := NewState()
+	s.ObservePhone(Move{Number: 11, X: 4, Y: 4, Stamp: 10 * time.Second})
+	if _, got := s.ObservePhone(Move{Number: 13, X: 6, Y: 6, Stamp: 12 * time.Second}); got != Jump {
+		t.Fatalf("手数跳变 = %v, want Jump", got)
+	}
+	// 第 12 手的截图晚于第 13 手到达
+	if _, got := s.ObservePhone(Move{Number: 12, X: 5, Y: 5, Stamp: 11 * time.Second}); got != Stale {
+		t.Errorf("旧截图 = %v, want Stale", got)
+	}
+	if got := s.Phone(); got != (Last{Move: 11, X: 4, Y: 4}) {
+		t.Errorf("Phone() = %+v", got)
+	}
+
+	// 没有时间戳的截图照常处理
+	if _, got := s.ObservePhone(Move{Number: 12, X: 5, Y: 5}); got != New {
+		t.Errorf("无时间戳 = %v, want New", got)
+	}
+	s.Reset()
+	if _, got := s.ObservePhone(Move{Number: 1, X: 3, Y: 3, Stamp: time.Second}); got != New {
+		t.Errorf("Reset 后 = %v, want New", got)
+	}
+}
+
+// TestObserveKatrainEcho 从手机同步过去的棋步即使轮询时先后顺序错开，也不算 KaTrain 的新手
+func TestObserveKatrainEcho(t *testing.T) {
+	s := NewState()
+	s.ExpectEcho(1, 3, 3)
+	s.ExpectEcho(2, 16, 3)
+
+	// 轮询先读到第一手，再读到第二手
+	for _, tt := range []struct{ move, x, y int }{{1, 3, 3}, {2, 16, 3}} {
+		if _, isNew := s.ObserveKatrain(tt.move, tt.x, tt.y); isNew {
+			t.Errorf("ObserveKatrain(%d, %d, %d) 是手机同步过去的一手，不应视为新手", tt.move, tt.x, tt.y)
+		}
+	}
+	if got := s.Katrain(); got != (Last{Move: 2, X: 16, Y: 3}) {
+		t.Errorf("Katrain() = %+v, want {2 16 3}", got)
+	}
+
+	if _, isNew := s.ObserveKatrain(3, 15, 15); !isNew {
+		t.Error("KaTrain 自己的一手应视为新手")
+	}
+
+	s.ExpectEcho(4, 4, 4)
+	s.CancelEcho(4)
+	if _, isNew := s.ObserveKatrain(4, 4, 4); !isNew {
+		t.Error("CancelEcho 后同一位置应视为新手")
+	}
+}
+
+// TestObserveKatrainEchoExpires 回声按手数匹配：KaTrain 已经走过的手数上没见到的回声随即丢弃，
+// 之后同一位置再出现（如提子后在原处落子）时照常视为新手
+func TestObserveKatrainEchoExpires(t *testing.T) {
+	s := NewState()
+	s.ExpectEcho(5, 3, 3)
+
+	// KaTrain 第 5 手下在别处（同步失败后 KaTrain 上有人落子），回声作废
+	if _, isNew := s.ObserveKatrain(5, 9, 9); !isNew {
+		t.Error("第 5 手不是回声的位置，应视为新手")
+	}
+	if _, isNew := s.ObserveKatrain(6, 3, 3); !isNew {
+		t.Error("第 5 手的回声应已丢弃，第 6 手在同一位置应视为新手")
+	}
+
+	// 回声只匹配自己的手数
+	s.ExpectEcho(8, 4, 4)
+	if _, isNew := s.ObserveKatrain(7, 4, 4); !isNew {
+		t.Error("第 7 手不是回声的手数，应视为新手")
+	}
+	if _, isNew := s.ObserveKatrain(8, 10, 10); !isNew {
+		t.Error("第 8 手不是回声的位置，应视为新手")
+	}
+	if len(s.echoes) != 0 {
+		t.Errorf("KaTrain 走过的手数上的回声应全部丢弃，剩余 %v", s.echoes)
+	}
+}
+
+// TestObserveConcurrent 多个协程同时看到同一手时只有一个认领成功
+func TestObserveConcurrent(t *testing.T) {
+	s := NewState()
+
+	for move := 1; move <= 50; move++ {
+		var claimed atomic.Int32
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, isNew := s.ObserveKatrain(move, move%19, move/19); isNew {
+					claimed.Add(1)
+				}
+				s.Katrain()
+				s.CheckDivergence(move, 2)
+			}()
+		}
+		wg.Wait()
+
+		if claimed.Load() != 1 {
+			t.Fatalf("第 %d 手被认领 %d 次, want 1", move, claimed.Load())
+		}
+	}
+}
+
+func TestCheckDivergence(t *testing.T) {
+	s := NewState()
+	s.SetPhone(Last{Move: 10, X: 3, Y: 3})
+
+	tests := []struct {
+		katrainMove int
+		alert       bool
+	}{
+		{11, false},
+		{13, true},
+		{14, false}, // 已提醒过
+		{10, false}, // 恢复一致
+		{20, true},
+		{0, false},
+	}
+
+	for _, tt := range tests {
+		if _, alert := s.CheckDivergence(tt.katrainMove, 2); alert != tt.alert {
+			t.Errorf("CheckDivergence(%d, 2) alert = %v, want %v", tt.katrainMove, alert, tt.alert)
+		}
+	}
+}
+
+func TestCheckReview(t *testing.T) {
+	s := NewState()
+
+	tests := []struct {
+		move, stones       int
+		reviewing, changed bool
+	}{
+		{10, 10, false, false},
+		{11, -1, false, false},
+		{6, 6, true, true},    // 回看第 6 手
+		{0, 6, true, false},   // OCR 未识别到手数，保持原状态
+		{8, 8, true, false},   // 仍在实战进度之前
+		{11, 11, false, true}, // 回到实战局面
+		{12, 20, true, true},  // 摆出了变化
+		{12, 12, false, true},
+		{72, -1, false, false}, // 一帧把 13 误读成 72，不作为实战进度
+		{13, 13, false, false},
+		{16, 16, false, false}, // 确实漏看了两手，连续出现 JumpFrames 次后采用
+		{16, 16, false, false},
+		{16, 16, false, false},
+		{15, 15, true, true},
+		{16, 16, false, true},
+	}
+
+	for _, tt := range tests {
+		reviewing, changed := s.CheckReview(tt.move, tt.stones, 0)
+		if reviewing != tt.reviewing || changed != tt.changed {
+			t.Errorf("CheckReview(%d, %d) = %v, %v, want %v, %v", tt.move, tt.stones, reviewing, changed, tt.reviewing, tt.changed)
+		}
+	}
+
+	s.Reset()
+	if reviewing, _ := s.CheckReview(3, 3, 0); reviewing {
+		t.Errorf("Reset() 后（如悔棋后强制重新同步）应以新的手数为实战进度")
+	}
+
+	s.Reset()
+	if reviewing, _ := s.CheckReview(1, 5, 4); reviewing {
+		t.Errorf("让四子局第 1 手盘面有 5 子，不应判为复盘")
+	}
+	if reviewing, _ := s.CheckReview(2, 9, 4); !reviewing {
+		t.Errorf("让四子局第 2 手盘面有 9 子，应判为复盘")
+	}
+}
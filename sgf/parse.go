@@ -0,0 +1,169 @@
+package sgf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tree 带变化的棋谱树，用于读取定式、布局等 SGF 文件。落子节点的 B/W 属性解析到 Color、X、Y，
+// 根节点、摆子与停一手的节点 Color 为空
+type Tree struct {
+	Node
+	Children []*Tree
+}
+
+// Parse 解析 SGF 文本，只取其中的第一局，只支持 19 路
+func Parse(data []byte) (*Tree, error) {
+	p := &parser{data: data}
+	p.skipSpace()
+	if !p.consume('(') {
+		return nil, fmt.Errorf("SGF 格式错误: 缺少 (")
+	}
+	root, err := p.tree()
+	if err != nil {
+		return nil, err
+	}
+	if sz := getProp(root.Props, "SZ"); len(sz) > 0 && sz[0] != "19" {
+		return nil, fmt.Errorf("只支持 19 路棋盘，SZ[%s]", sz[0])
+	}
+	return root, nil
+}
+
+type parser struct {
+	data []byte
+	pos  int
+}
+
+// tree 解析 ( 之后的一段节点序列与其中的变化，返回序列的第一个节点
+func (p *parser) tree() (*Tree, error) {
+	var first, last *Tree
+	for {
+		p.skipSpace()
+		switch {
+		case p.consume(';'):
+			n, err := p.node()
+			if err != nil {
+				return nil, err
+			}
+			if first == nil {
+				first = n
+			} else {
+				last.Children = append(last.Children, n)
+			}
+			last = n
+		case p.consume('('):
+			if last == nil {
+				return nil, p.errorf("变化之前没有节点")
+			}
+			child, err := p.tree()
+			if err != nil {
+				return nil, err
+			}
+			last.Children = append(last.Children, child)
+		case p.consume(')'):
+			if first == nil {
+				return nil, p.errorf("空的棋谱")
+			}
+			return first, nil
+		default:
+			return nil, p.errorf("意外的字符")
+		}
+	}
+}
+
+// node 解析一个节点的属性
+func (p *parser) node() (*Tree, error) {
+	n := &Tree{}
+	for {
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.data) && isLetter(p.data[p.pos]) {
+			p.pos++
+		}
+		if p.pos == start {
+			return n, nil
+		}
+		// 旧版 SGF 的属性名夹有小写字母（如 AddBlack），只保留大写部分
+		key := strings.Map(func(r rune) rune {
+			if r >= 'A' && r <= 'Z' {
+				return r
+			}
+			return -1
+		}, string(p.data[start:p.pos]))
+
+		var values []string
+		for p.skipSpace(); p.consume('['); p.skipSpace() {
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		if len(values) == 0 {
+			return nil, p.errorf("属性 %s 没有值", key)
+		}
+
+		if (key == "B" || key == "W") && values[0] != "" && values[0] != "tt" {
+			x, y, err := parsePoint(values[0])
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			n.Color, n.X, n.Y = key, x, y
+			continue
+		}
+		n.Props = append(n.Props, Property{Key: key, Values: values})
+	}
+}
+
+// value 读取 [ 之后到 ] 为止的属性值，处理 \ 转义与软换行
+func (p *parser) value() (string, error) {
+	var b strings.Builder
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case ']':
+			return b.String(), nil
+		case '\\':
+			if p.pos < len(p.data) {
+				if next := p.data[p.pos]; next != '\n' {
+					b.WriteByte(next)
+				}
+				p.pos++
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("属性值没有结束")
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) && strings.IndexByte(" \t\r\n", p.data[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *parser) consume(c byte) bool {
+	if p.pos < len(p.data) && p.data[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("SGF 格式错误（第 %d 字节）: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func isLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+// parsePoint 把 SGF 坐标（左上角为 aa）转换为 KaTrain 坐标，与 Game.Point 相反
+func parsePoint(s string) (int, int, error) {
+	if len(s) != 2 || s[0] < 'a' || s[0] > 's' || s[1] < 'a' || s[1] > 's' {
+		return 0, 0, fmt.Errorf("无效的坐标 %q", s)
+	}
+	return int(s[0] - 'a'), 18 - int(s[1]-'a'), nil
+}
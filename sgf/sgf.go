@@ -0,0 +1,160 @@
+// Package sgf 记录同步过程中的对局，并导出为 SGF 棋谱。
+package sgf
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Property SGF 属性，例如 B[pd]、BL[300]
+type Property struct {
+	Key    string
+	Values []string
+}
+
+// Node 一手棋对应的节点
+type Node struct {
+	Color string // "B" 或 "W"
+	X     int    // KaTrain 坐标，0-18 从左到右
+	Y     int    // KaTrain 坐标，0-18 从下到上
+	Props []Property
+}
+
+// Set 设置节点属性，已存在时覆盖
+func (n *Node) Set(key string, values ...string) {
+	n.Props = setProp(n.Props, key, values)
+}
+
+// Get 读取节点属性
+func (n *Node) Get(key string) []string {
+	return getProp(n.Props, key)
+}
+
+// AddComment 追加注释（C），已有注释时另起一行
+func (n *Node) AddComment(text string) {
+	if c := n.Get("C"); len(c) > 0 && c[0] != "" {
+		text = c[0] + "\n" + text
+	}
+	n.Set("C", text)
+}
+
+// SetTimeLeft 记录落子后该方的剩余时间（BL/WL）与读秒次数（OB/OW）
+func (n *Node) SetTimeLeft(remaining time.Duration, periods int) {
+	n.Set(n.Color+"L", strconv.FormatFloat(remaining.Seconds(), 'f', -1, 64))
+	if periods > 0 {
+		n.Set("O"+n.Color, strconv.Itoa(periods))
+	}
+}
+
+// SetMoveTime 在注释中记录这一手的用时（精确到秒），不足 1 秒时不记录
+func (n *Node) SetMoveTime(spent time.Duration) {
+	if sec := int(spent.Round(time.Second).Seconds()); sec > 0 {
+		n.AddComment(fmt.Sprintf("用时 %d 秒", sec))
+	}
+}
+
+// Game 一局棋的棋谱
+type Game struct {
+	Size  int
+	Props []Property // 根节点属性
+	Nodes []*Node
+}
+
+func NewGame() *Game {
+	return &Game{Size: 19}
+}
+
+// SetRoot 设置根节点属性，例如 PB、PW、KM、TM、OT
+func (g *Game) SetRoot(key string, values ...string) {
+	g.Props = setProp(g.Props, key, values)
+}
+
+// Root 读取根节点属性
+func (g *Game) Root(key string) []string {
+	return getProp(g.Props, key)
+}
+
+// AddMove 追加一手棋，x/y 为 KaTrain 坐标
+func (g *Game) AddMove(color string, x, y int) *Node {
+	n := &Node{Color: color, X: x, Y: y}
+	g.Nodes = append(g.Nodes, n)
+	return n
+}
+
+// LastMove 返回最后一手，没有落子时返回 nil
+func (g *Game) LastMove() *Node {
+	if len(g.Nodes) == 0 {
+		return nil
+	}
+	return g.Nodes[len(g.Nodes)-1]
+}
+
+// Point 把 KaTrain 坐标转换为 SGF 坐标（左上角为 aa）
+func (g *Game) Point(x, y int) string {
+	return string(rune('a'+x)) + string(rune('a'+g.Size-1-y))
+}
+
+// AddLabel 在节点 n 上把 (x, y) 标注为 label（LB），x/y 为 KaTrain 坐标
+func (g *Game) AddLabel(n *Node, x, y int, label string) {
+	n.Set("LB", append(n.Get("LB"), g.Point(x, y)+":"+label)...)
+}
+
+// String 编码为 SGF 文本
+func (g *Game) String() string {
+	var b strings.Builder
+
+	b.WriteString("(;GM[1]FF[4]CA[UTF-8]AP[goboardsync]")
+	fmt.Fprintf(&b, "SZ[%d]", g.Size)
+	writeProps(&b, g.Props)
+
+	for _, n := range g.Nodes {
+		b.WriteString("\n;")
+		fmt.Fprintf(&b, "%s[%s]", n.Color, g.Point(n.X, n.Y))
+		writeProps(&b, n.Props)
+	}
+
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// WriteFile 保存棋谱到文件
+func (g *Game) WriteFile(path string) error {
+	return os.WriteFile(path, []byte(g.String()), 0644)
+}
+
+// Escape 转义属性值中的 ] 与 \
+func Escape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "]", `\]`)
+}
+
+func writeProps(b *strings.Builder, props []Property) {
+	for _, p := range props {
+		b.WriteString(p.Key)
+		for _, v := range p.Values {
+			b.WriteString("[" + Escape(v) + "]")
+		}
+	}
+}
+
+func setProp(props []Property, key string, values []string) []Property {
+	for i := range props {
+		if props[i].Key == key {
+			props[i].Values = values
+			return props
+		}
+	}
+	return append(props, Property{Key: key, Values: values})
+}
+
+func getProp(props []Property, key string) []string {
+	for _, p := range props {
+		if p.Key == key {
+			return p.Values
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package sgf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGameString(t *testing.T) {
+	g := NewGame()
+	g.SetRoot("PB", "黑方")
+	g.SetRoot("OT", "3x30 byo-yomi")
+
+	n := g.AddMove("B", 15, 15)
+	n.SetTimeLeft(5*time.Minute+30*time.Second, 0)
+
+	n = g.AddMove("W", 3, 3)
+	n.SetTimeLeft(25*time.Second, 3)
+
+	expected := "(;GM[1]FF[4]CA[UTF-8]AP[goboardsync]SZ[19]PB[黑方]OT[3x30 byo-yomi]\n" +
+		";B[pd]BL[330]\n" +
+		";W[dp]WL[25]OW[3])\n"
+
+	if got := g.String(); got != expected {
+		t.Errorf("String() = %q, want %q", got, expected)
+	}
+}
+
+func TestPoint(t *testing.T) {
+	g := NewGame()
+	tests := []struct {
+		x, y     int
+		expected string
+	}{
+		{0, 18, "aa"},
+		{18, 0, "ss"},
+		{3, 15, "dd"},
+		{9, 9, "jj"},
+	}
+
+	for _, tt := range tests {
+		if got := g.Point(tt.x, tt.y); got != tt.expected {
+			t.Errorf("Point(%d, %d) = %s, want %s", tt.x, tt.y, got, tt.expected)
+		}
+	}
+}
+
+func TestSetOverwrites(t *testing.T) {
+	g := NewGame()
+	n := g.AddMove("B", 0, 0)
+	n.Set("C", "first")
+	n.Set("C", "second")
+
+	if got := n.Get("C"); len(got) != 1 || got[0] != "second" {
+		t.Errorf("Get(C) = %v, want [second]", got)
+	}
+	if len(n.Props) != 1 {
+		t.Errorf("len(Props) = %d, want 1", len(n.Props))
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	g := NewGame()
+	n := g.AddMove("B", 3, 15)
+	n.AddComment("识别有误")
+	n.AddComment("黑胜率 55.0%")
+	g.AddLabel(n, 15, 15, "A")
+	g.AddLabel(n, 2, 3, "B")
+
+	if got := n.Get("C"); len(got) != 1 || got[0] != "识别有误\n黑胜率 55.0%" {
+		t.Errorf("Get(C) = %q", got)
+	}
+	if got := g.String(); !strings.Contains(got, "LB[pd:A][cp:B]") {
+		t.Errorf("String() = %s, want LB[pd:A][cp:B]", got)
+	}
+}
+
+func TestSetMoveTime(t *testing.T) {
+	g := NewGame()
+	n := g.AddMove("B", 15, 15)
+	n.SetMoveTime(400 * time.Millisecond)
+	if c := n.Get("C"); c != nil {
+		t.Errorf("不足 1 秒时不应记录，C = %q", c)
+	}
+	n.SetMoveTime(12600 * time.Millisecond)
+	n.AddComment("黑胜率 55.0%")
+	if c := n.Get("C"); len(c) != 1 || c[0] != "用时 13 秒\n黑胜率 55.0%" {
+		t.Errorf("C = %q", c)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got := Escape(`a]b\c`); got != `a\]b\\c` {
+		t.Errorf("Escape() = %s", got)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	g := NewGame()
+	g.AddMove("B", 3, 15)
+
+	path := filepath.Join(t.TempDir(), "game.sgf")
+	if err := g.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取棋谱失败: %v", err)
+	}
+	if string(data) != g.String() {
+		t.Errorf("文件内容 = %q, want %q", data, g.String())
+	}
+}
+
+func TestParse(t *testing.T) {
+	data := `(;GM[1]SZ[19]C[定式\]测试]
+;B[pd]N[星位]
+(;W[qc]N[点三三];B[pc])
+(;W[qf]
+  ;B[nc]C[小飞
+守角\
+])
+(;W[tt]))`
+
+	root, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := root.Get("C"); len(got) != 1 || got[0] != "定式]测试" {
+		t.Errorf("根节点 C = %q", got)
+	}
+	if len(root.Children) != 1 {
+		t.Fatalf("根节点有 %d 个子节点, want 1", len(root.Children))
+	}
+	star := root.Children[0]
+	if star.Color != "B" || star.X != 15 || star.Y != 15 || star.Get("N")[0] != "星位" {
+		t.Errorf("第一手 = %+v", star.Node)
+	}
+	if len(star.Children) != 3 {
+		t.Fatalf("星位之后有 %d 个变化, want 3", len(star.Children))
+	}
+	if n := star.Children[0].Children[0]; n.Color != "B" || n.X != 15 || n.Y != 16 {
+		t.Errorf("点三三之后 = %+v", n.Node)
+	}
+	if c := star.Children[1].Children[0].Get("C"); len(c) != 1 || c[0] != "小飞\n守角" {
+		t.Errorf("软换行处理后 C = %q", c)
+	}
+	if pass := star.Children[2]; pass.Color != "" || pass.Get("W")[0] != "tt" {
+		t.Errorf("停一手 = %+v", pass.Node)
+	}
+
+	// 导出的棋谱可以读回
+	g := NewGame()
+	g.AddMove("B", 3, 15)
+	g.AddMove("W", 15, 3)
+	root, err = Parse([]byte(g.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := root.Children[0].Children[0]; n.Color != "W" || n.X != 15 || n.Y != 3 {
+		t.Errorf("读回的第二手 = %+v", n.Node)
+	}
+
+	for _, bad := range []string{"", "(;B[pd]", "(;SZ[13];B[aa])", "(;B[zz])", "(;C[x)"} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("Parse(%q) 应返回错误", bad)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Package stats 在本地文件中跨多次运行累计识别成功与失败的帧数，按棋盘皮肤、截图分辨率与光线分组，
+// 用于找出识别效果差、需要调整参数的配置。只写本地文件，不上传任何数据。
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// FlushEvery 累计多少帧写一次文件，Close 时写入剩余的部分
+const FlushEvery = 100
+
+// Key 统计的分组，识别详情中没有的一项为 "-"
+type Key struct {
+	Skin       string `json:"skin"`
+	Resolution string `json:"resolution"`
+	Lighting   string `json:"lighting"`
+}
+
+// Counts 一组中识别成功与失败的帧数
+type Counts struct {
+	OK     int `json:"ok"`
+	Failed int `json:"failed"`
+}
+
+// FailureRate 失败帧所占比例，没有帧时为 0
+func (c Counts) FailureRate() float64 {
+	if c.OK+c.Failed == 0 {
+		return 0
+	}
+	return float64(c.Failed) / float64(c.OK+c.Failed)
+}
+
+// Row 统计文件中的一组
+type Row struct {
+	Key
+	Counts
+}
+
+// Recorder 在内存中累计，每 FlushEvery 帧合并进统计文件，可并发使用
+type Recorder struct {
+	path string
+
+	mu      sync.Mutex
+	pending map[Key]Counts
+	frames  int
+}
+
+// NewRecorder 返回写入 path 的 Recorder，path 为空时返回 nil（不统计）
+func NewRecorder(path string) *Recorder {
+	if path == "" {
+		return nil
+	}
+	return &Recorder{path: path, pending: map[Key]Counts{}}
+}
+
+// Add 记录一帧，累计满 FlushEvery 帧时写入文件并返回写入的错误
+func (r *Recorder) Add(key Key, ok bool) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.pending[key]
+	if ok {
+		c.OK++
+	} else {
+		c.Failed++
+	}
+	r.pending[key] = c
+	r.frames++
+	if r.frames < FlushEvery {
+		return nil
+	}
+	return r.flush()
+}
+
+// Close 把还没写入的帧合并进文件
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.flush()
+}
+
+// flush 读出文件中已有的统计，加上 pending 后整体写回。失败时保留 pending，下次再试
+func (r *Recorder) flush() error {
+	if len(r.pending) == 0 {
+		return nil
+	}
+	rows, err := Load(r.path)
+	if err != nil {
+		return err
+	}
+	if err := write(r.path, merge(rows, r.pending)); err != nil {
+		return fmt.Errorf("写入识别统计失败: %v", err)
+	}
+	r.pending = map[Key]Counts{}
+	r.frames = 0
+	return nil
+}
+
+// Load 读取统计文件，文件不存在时返回空
+func Load(path string) ([]Row, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取识别统计失败: %v", err)
+	}
+	var rows []Row
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("解析识别统计 %s 失败: %v", path, err)
+	}
+	return rows, nil
+}
+
+// merge 把 add 加到 rows 上，返回按分组排序的结果
+func merge(rows []Row, add map[Key]Counts) []Row {
+	totals := make(map[Key]Counts, len(rows)+len(add))
+	for _, row := range rows {
+		c := totals[row.Key]
+		totals[row.Key] = Counts{OK: c.OK + row.OK, Failed: c.Failed + row.Failed}
+	}
+	for key, a := range add {
+		c := totals[key]
+		totals[key] = Counts{OK: c.OK + a.OK, Failed: c.Failed + a.Failed}
+	}
+
+	merged := make([]Row, 0, len(totals))
+	for key, c := range totals {
+		merged = append(merged, Row{Key: key, Counts: c})
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		a, b := merged[i].Key, merged[j].Key
+		if a.Skin != b.Skin {
+			return a.Skin < b.Skin
+		}
+		if a.Resolution != b.Resolution {
+			return a.Resolution < b.Resolution
+		}
+		return a.Lighting < b.Lighting
+	})
+	return merged
+}
+
+// write 先写临时文件再改名，进程中途退出不会留下损坏的统计文件
+func write(path string, rows []Row) error {
+	data, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".stats-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Summarize 按失败率从高到低输出各组的帧数与失败率，以及全部的合计
+func Summarize(w io.Writer, rows []Row) {
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "还没有识别统计")
+		return
+	}
+	sorted := append([]Row(nil), rows...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].FailureRate() > sorted[j].FailureRate() })
+
+	var total Counts
+	fmt.Fprintf(w, "%-10s | %-10s | %-8s | %-10s | %s\n", "皮肤", "分辨率", "光线", "帧数", "失败率")
+	for _, row := range sorted {
+		fmt.Fprintf(w, "%-10s | %-10s | %-8s | %-10d | %.1f%%\n", row.Skin, row.Resolution, row.Lighting, row.OK+row.Failed, row.FailureRate()*100)
+		total.OK += row.OK
+		total.Failed += row.Failed
+	}
+	fmt.Fprintf(w, "合计: %d 帧，失败 %d，失败率 %.1f%%\n", total.OK+total.Failed, total.Failed, total.FailureRate()*100)
+}
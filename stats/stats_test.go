@@ -0,0 +1,68 @@
+package stats
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats", "stats.json")
+	classic := Key{Skin: "classic", Resolution: "1200x2670", Lighting: "normal"}
+	dark := Key{Skin: "dark", Resolution: "1200x2670", Lighting: "dark"}
+
+	// 第一次运行：满 FlushEvery 帧时写入文件
+	r := NewRecorder(path)
+	for i := 0; i < FlushEvery-1; i++ {
+		r.Add(classic, i%10 != 0)
+	}
+	if rows, _ := Load(path); rows != nil {
+		t.Fatalf("未满 %d 帧时不应写入: %+v", FlushEvery, rows)
+	}
+	if err := r.Add(dark, false); err != nil {
+		t.Fatal(err)
+	}
+	// 第二次运行：Close 时写入剩余的帧，与已有的统计合并
+	r = NewRecorder(path)
+	r.Add(dark, true)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Row{
+		{Key: classic, Counts: Counts{OK: 89, Failed: 10}},
+		{Key: dark, Counts: Counts{OK: 1, Failed: 1}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Load() = %+v, want %+v", rows, want)
+	}
+
+	var buf bytes.Buffer
+	Summarize(&buf, rows)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[1], "dark") || !strings.Contains(lines[1], "50.0%") {
+		t.Errorf("失败率高的组应排在前面:\n%s", buf.String())
+	}
+	if !strings.Contains(lines[3], "101 帧，失败 11") {
+		t.Errorf("合计 = %q", lines[3])
+	}
+}
+
+func TestNilRecorder(t *testing.T) {
+	var r *Recorder = NewRecorder("")
+	if r != nil {
+		t.Fatal("path 为空时应返回 nil")
+	}
+	if err := r.Add(Key{}, true); err != nil {
+		t.Error(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,73 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"goboardsync/vision"
+
+	"gocv.io/x/gocv"
+)
+
+// moveArchive 按手保存截图（见 Config.SampleDir）：每帧识别后留下该帧，确认是新的一手后以样本文件名
+// （vision.SampleFilename）写入本局的目录，每手只存一张。真实对局因此自动成为 bench 与批量识别测试的标注样本
+type moveArchive struct {
+	mu  sync.Mutex
+	dir string
+	// frame 最近一帧截图，saved 已保存过的手数
+	frame gocv.Mat
+	saved map[int]bool
+}
+
+func newMoveArchive(dir string) *moveArchive {
+	return &moveArchive{dir: dir, frame: gocv.NewMat(), saved: make(map[int]bool)}
+}
+
+// hold 留下本帧截图，之后 save 时保存它；a 为 nil（未开启）时跳过
+func (a *moveArchive) hold(img gocv.Mat) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	img.CopyTo(&a.frame)
+}
+
+// save 把 hold 留下的截图按 result 的手数、坐标与颜色保存，返回文件路径。同一手数只保存第一次，
+// 手数未知（OCR 没读到）或已保存过时返回空字符串
+func (a *moveArchive) save(result *vision.Result) (string, error) {
+	if a == nil || result.Move == 0 || result.X == 0 {
+		return "", nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.saved[result.Move] || a.frame.Empty() {
+		return "", nil
+	}
+
+	buf, err := gocv.IMEncode(".jpg", a.frame)
+	if err != nil {
+		return "", fmt.Errorf("编码样本截图失败: %v", err)
+	}
+	defer buf.Close()
+	if err := os.MkdirAll(a.dir, 0o755); err != nil {
+		return "", fmt.Errorf("创建样本目录失败: %v", err)
+	}
+	path := filepath.Join(a.dir, vision.SampleFilename(result.Move, result.Color, result.X, result.Y, ".jpg"))
+	if err := os.WriteFile(path, buf.GetBytes(), 0o644); err != nil {
+		return "", fmt.Errorf("保存样本截图失败: %v", err)
+	}
+	a.saved[result.Move] = true
+	return path, nil
+}
+
+func (a *moveArchive) close() {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.frame.Close()
+}
@@ -0,0 +1,180 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"time"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+	"goboardsync/dashboard"
+	"goboardsync/session"
+	"goboardsync/target"
+)
+
+// attach 中途接入：开始同步前截图识别手机上的整盘局面。盘上已有棋子时把局面摆到 KaTrain（不支持摆子时逐个落子），
+// 按最后一手的角标（没有时按手数或双方棋子数）推断轮到哪方，本地棋盘、棋谱与两个方向的最后一手都从这个局面开始，
+// 之后照常一手一手增量同步。空盘（新对局）时什么也不做
+func (s *Session) attach(ctx context.Context) error {
+	img, err := s.source.Grab(ctx)
+	if err != nil {
+		return fmt.Errorf("截图失败: %v", err)
+	}
+	defer img.Close()
+
+	screen, err := s.detector.ReadScreenBoard(img)
+	if err != nil {
+		return fmt.Errorf("识别局面失败: %v", err)
+	}
+	stones := screen.Count(board.Black) + screen.Count(board.White)
+	if stones == 0 {
+		return nil
+	}
+
+	var b board.Board
+	for x := range coords.Size {
+		for y := range coords.Size {
+			if c := screen.At(x, y); c != board.Empty {
+				kx, ky := s.orientation.FromScreen(x, y)
+				b.Set(kx, ky, c)
+			}
+		}
+	}
+
+	moveNumber, _ := s.fetchMoveNumber(ctx, img)
+	result, _ := s.detector.DetectLastMoveCoord(img, moveNumber)
+	next := board.SideToMove(&b, moveNumber, s.cfg.Handicap)
+	var last *image.Point
+	if result.X != 0 {
+		// 角标落在空点上说明识别有误，不作为最后一手
+		if x, y := s.phoneToBoard(result.X, result.Y); b.At(x, y) != board.Empty {
+			last = &image.Point{X: x, Y: y}
+			next = b.At(x, y).Opponent()
+		}
+	}
+
+	p := target.Position{Next: next.String(), MoveNumber: moveNumber}
+	if p.MoveNumber == 0 {
+		p.MoveNumber = stones
+	}
+	for x := range coords.Size {
+		for y := range coords.Size {
+			switch b.At(x, y) {
+			case board.Black:
+				p.Black = append(p.Black, image.Pt(x, y))
+			case board.White:
+				p.White = append(p.White, image.Pt(x, y))
+			}
+		}
+	}
+
+	fmt.Printf("[%s] 🧭 手机上已有对局（黑 %d 子、白 %d 子，第 %d 手），中途接入，轮到%s\n",
+		time.Now().Format("15:04:05"), len(p.Black), len(p.White), p.MoveNumber, mapColorToChinese(p.Next))
+	katrainLast, err := s.loadPosition(ctx, p, last)
+	if err != nil {
+		s.reportError("KaTrain 落子", err)
+		return err
+	}
+	s.reportOK("KaTrain 落子")
+	s.loadRelayPositions(ctx, p)
+
+	// 逐个落子时 KaTrain 的手数是盘上的棋子数，棋谱与分析的手数都以 KaTrain 为准
+	base := p.MoveNumber
+	if katrainLast.Number > 0 {
+		base = katrainLast.Number
+	}
+	s.mu.Lock()
+	s.game.Setup(b, base)
+	s.setupMoves = base
+	for _, setup := range []struct {
+		key    string
+		stones []image.Point
+	}{{"AB", p.Black}, {"AW", p.White}} {
+		if len(setup.stones) == 0 {
+			continue
+		}
+		points := make([]string, len(setup.stones))
+		for i, pt := range setup.stones {
+			points[i] = s.record.Point(pt.X, pt.Y)
+		}
+		s.record.SetRoot(setup.key, points...)
+	}
+	s.record.SetRoot("PL", p.Next)
+	s.mu.Unlock()
+
+	// 手机上的最后一手与 KaTrain 上摆好的棋子都已同步，两个方向都不会再把它们当作新手
+	if last != nil {
+		s.state.SetPhone(session.Last{Move: moveNumber, X: result.X, Y: result.Y})
+	}
+	s.state.SetKatrain(session.Last{Move: katrainLast.Number, X: katrainLast.X, Y: katrainLast.Y})
+	s.dash.Update(func(st *dashboard.Status) { st.PhoneMove = moveNumber })
+	s.updatePhase()
+	s.updateOpening()
+	s.overlayChanged()
+	return nil
+}
+
+// loadPosition 把局面 p 摆到同步目标上，返回摆好后目标上的最后一手（直接摆子时没有最后一手，为零值）。
+// 目标不支持摆子时逐个落子：先下轮到的一方的棋子，最后下 last（已知时），使目标的最后一手与手机一致、
+// 接下来轮到 p.Next。整盘局面中每块棋都有气，按任何顺序摆上去都不会提子
+func (s *Session) loadPosition(ctx context.Context, p target.Position, last *image.Point) (target.Move, error) {
+	if setter, ok := s.positionSetter(); ok {
+		err := setter.SetupPosition(ctx, p)
+		if err == nil {
+			fmt.Printf("[%s] ✅ 已在 %s 摆好局面\n", time.Now().Format("15:04:05"), s.target.Name())
+			return target.Move{}, nil
+		}
+		fmt.Printf("[%s] ⚠️  %s 摆放局面失败，改为逐个落子: %v\n", time.Now().Format("15:04:05"), s.target.Name(), err)
+	}
+
+	mover, other := p.Black, p.White
+	if p.Next == "W" {
+		mover, other = p.White, p.Black
+	}
+	moves := make([]target.Move, 0, len(mover)+len(other))
+	for _, stones := range []struct {
+		points []image.Point
+		color  string
+	}{{mover, p.Next}, {other, board.ParseColor(p.Next).Opponent().String()}} {
+		for _, pt := range stones.points {
+			if last == nil || pt != *last {
+				moves = append(moves, target.Move{X: pt.X, Y: pt.Y, Color: stones.color})
+			}
+		}
+	}
+	if last != nil {
+		moves = append(moves, target.Move{X: last.X, Y: last.Y, Color: board.ParseColor(p.Next).Opponent().String()})
+	}
+
+	for i, m := range moves {
+		if err := s.target.Play(ctx, m.X, m.Y, m.Color); err != nil {
+			return target.Move{}, fmt.Errorf("摆放 %s 失败: %w", coords.Format(m.X, m.Y, coords.GTP), err)
+		}
+		moves[i].Number = i + 1
+	}
+	fmt.Printf("[%s] ✅ 已在 %s 逐个摆上 %d 颗棋子\n", time.Now().Format("15:04:05"), s.target.Name(), len(moves))
+	return moves[len(moves)-1], nil
+}
+
+// loadRelayPositions 把局面摆到同时同步的其他 KaTrain 实例上；不能摆子的转播目标只接收接入之后的棋步
+func (s *Session) loadRelayPositions(ctx context.Context, p target.Position) {
+	for _, r := range s.relays {
+		setter, ok := r.(target.PositionSetter)
+		if prober, isProber := r.(target.Prober); ok && isProber && !prober.Supports(target.FeatureSetupPosition) {
+			ok = false
+		}
+		if !ok {
+			fmt.Printf("[%s] ℹ️  %s 不能摆放局面，只转播接入之后的棋步\n", time.Now().Format("15:04:05"), r.Name())
+			continue
+		}
+		if err := setter.SetupPosition(ctx, p); err != nil {
+			fmt.Printf("[%s] ⚠️  %s 摆放局面失败: %v\n", time.Now().Format("15:04:05"), r.Name(), err)
+		}
+	}
+}
+
+// moveNumber 棋谱中最后一手在 KaTrain 中的手数，中途接入时加上摆上的局面的手数。调用方持有 s.mu
+func (s *Session) moveNumber() int {
+	return s.setupMoves + len(s.record.Nodes)
+}
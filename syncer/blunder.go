@@ -0,0 +1,131 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+	"goboardsync/dashboard"
+	"goboardsync/hooks"
+	"goboardsync/notify"
+	"goboardsync/platform"
+	"goboardsync/sgf"
+)
+
+// blunderWait 等待 KaTrain 分析出一手前后两个局面的最长时间，超时后不再检查这一手
+const blunderWait = 30 * time.Second
+
+// blunderQueue 等待检查的棋步最多排多少手，KaTrain 分析跟不上时丢弃之后的棋步
+const blunderQueue = 16
+
+// blunderCheck 等待检查的一手。record 为记下这一手时的棋谱，多桌轮换切到别的桌后不再检查
+type blunderCheck struct {
+	move   int
+	color  string
+	x, y   int
+	record *sgf.Game
+}
+
+// queueBlunderCheck 把记入棋谱的第 move 手交给 runBlunderCheck，不阻塞；未开启 BlunderThreshold、
+// 颜色不需检查或队列已满时忽略
+func (s *Session) queueBlunderCheck(c blunderCheck) {
+	if s.cfg.BlunderThreshold <= 0 || !s.cfg.BlunderColors.Allows(c.color) {
+		return
+	}
+	select {
+	case s.blunders <- c:
+	default:
+	}
+}
+
+// runBlunderCheck 逐手比较 KaTrain 分析的落子前后胜率，损失超过 BlunderThreshold 时报警，直到 ctx 取消。
+// 新的一手 KaTrain 往往还没分析完，每 overlayRetry 重试一次，最多等 blunderWait
+func (s *Session) runBlunderCheck(ctx context.Context) {
+	if _, ok := s.analyzer(); !ok {
+		fmt.Printf("[%s] ℹ️  %s 不提供分析结果，不检查恶手\n", time.Now().Format("15:04:05"), s.target.Name())
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case c := <-s.blunders:
+			deadline := time.Now().Add(blunderWait)
+			for {
+				loss, before, after, err := s.winrateLoss(ctx, c)
+				if err == nil {
+					if loss*100 >= s.cfg.BlunderThreshold {
+						s.alertBlunder(c, loss, before, after)
+					}
+					break
+				}
+				if time.Now().After(deadline) {
+					break
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(overlayRetry):
+				}
+			}
+		}
+	}
+}
+
+// winrateLoss 返回第 c.move 手让落子方损失的胜率（0-1，负数为胜率上升）以及落子前后的黑方胜率。
+// 分析不可用或已切到别的桌时返回错误
+func (s *Session) winrateLoss(ctx context.Context, c blunderCheck) (loss, before, after float64, err error) {
+	s.mu.RLock()
+	current := s.record
+	s.mu.RUnlock()
+	if current != c.record {
+		return 0, 0, 0, fmt.Errorf("已切换对局")
+	}
+
+	analyzer, ok := s.analyzer()
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("目标不提供分析结果")
+	}
+	prev, err := analyzer.Analysis(ctx, c.move-1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	next, err := analyzer.Analysis(ctx, c.move)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	loss = prev.Winrate - next.Winrate
+	if board.ParseColor(c.color) == board.White {
+		loss = -loss
+	}
+	return loss, prev.Winrate, next.Winrate, nil
+}
+
+// alertBlunder 记录日志、推送提醒、调用扩展并播放 BlunderSound
+func (s *Session) alertBlunder(c blunderCheck, loss, before, after float64) {
+	coord := coords.Format(c.x, c.y, coords.GTP)
+	message := fmt.Sprintf("第 %d 手 %s %s 胜率损失 %.1f%%（黑胜率 %.1f%% → %.1f%%）",
+		c.move, mapColorToChinese(c.color), coord, loss*100, before*100, after*100)
+	fmt.Printf("[%s] 🚨 恶手: %s\n", time.Now().Format("15:04:05"), message)
+
+	s.dash.Update(func(st *dashboard.Status) { st.Blunder = message })
+	s.notifyEvent(notify.Blunder, message)
+	s.hooks.Fire(hooks.Event{
+		Kind:    hooks.Blunder,
+		Move:    c.move,
+		Color:   c.color,
+		X:       c.x,
+		Y:       c.y,
+		Coord:   coord,
+		Message: message,
+	})
+	if s.cfg.BlunderSound != "" {
+		if err := platform.PlaySound(s.cfg.BlunderSound); err != nil {
+			fmt.Printf("[%s] ⚠️  播放提示音失败: %v\n", time.Now().Format("15:04:05"), err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"goboardsync/companion"
+	"goboardsync/ocr"
+
+	"gocv.io/x/gocv"
+)
+
+// setupCompanion 连接手机上的伴侣 App，之后的点击交给它执行，手数与对话框从界面结构中读取；
+// 连不上时打印警告，仍使用 adb 点击与 OCR
+func (s *Session) setupCompanion(ctx context.Context) {
+	if s.cfg.CaptureSource == "remote" {
+		fmt.Printf("⚠️  手机接在采集端上，无法转发伴侣 App 的端口，不使用伴侣 App\n")
+		return
+	}
+	port := s.cfg.CompanionPort
+	if port == 0 {
+		port = companion.DefaultPort
+	}
+	c, version, err := companion.Dial(ctx, s.phone, port)
+	if err != nil {
+		fmt.Printf("⚠️  %v，不使用伴侣 App\n", err)
+		return
+	}
+	s.companion = c
+	s.phone.Tapper = c
+	fmt.Printf("🤝 已连接伴侣 App %s（端口 %d），通过无障碍服务点击并读取界面\n", version, port)
+}
+
+// fetchMoveNumber 读取手机上的手数：连接了伴侣 App 时从界面结构的文字中提取，读不到时用 OCR；
+// OCR 也失败时使用 uiautomator 最近读到的手数（见 uiMoveNumber）
+func (s *Session) fetchMoveNumber(ctx context.Context, img gocv.Mat) (int, error) {
+	if s.companion != nil {
+		tree, err := s.companion.Tree(ctx)
+		if err == nil {
+			s.showDialog(tree.FindDialog())
+			if n := ocr.ExtractMoveNumberWith(strings.Join(tree.Texts(), "\n"), s.detector.MoveNumberPatterns); n > 0 {
+				return n, nil
+			}
+		}
+	}
+	n, err := s.detector.FetchMoveNumberFromOCR(img)
+	if err != nil {
+		if ui := s.uiMoveNumber(); ui > 0 {
+			return ui, nil
+		}
+	}
+	return n, err
+}
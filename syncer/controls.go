@@ -0,0 +1,125 @@
+package syncer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"goboardsync/coords"
+	"goboardsync/dashboard"
+)
+
+// registerControls 注册运行中的人工干预操作，看板页面显示为按钮，ReadControls 读到对应字母也可触发
+func (s *Session) registerControls() {
+	s.dash.HandleCommand("pause", "暂停/继续", local(s.TogglePause))
+	s.dash.HandleCommand("resync", "重新同步", local(s.ForceResync))
+	s.dash.HandleCommand("wrong", "标记最后一手有误", local(s.MarkLastMoveWrong))
+	if s.cfg.ApproveMoves {
+		s.dash.HandleCommand("approve", "确认建议落子", s.ApproveSuggestion)
+		s.dash.HandleCommand("reject", "放弃建议", local(s.RejectSuggestion))
+	}
+	if s.cfg.DeadStoneScoring {
+		s.dash.HandleCommand("score", "读取死子并数子", s.HandoffScoring)
+	}
+	s.dash.HandleCommand("save", "保存棋谱", local(func() error {
+		if s.SaveRecord() == "" {
+			return fmt.Errorf("没有可保存的棋谱")
+		}
+		return nil
+	}))
+}
+
+// local 包装不访问手机与同步目标的操作，这类操作不需要 ctx
+func local(fn func() error) func(context.Context) error {
+	return func(context.Context) error { return fn() }
+}
+
+// ControlKeys 终端输入的字母与看板操作的对应关系
+var ControlKeys = map[string]string{
+	"p": "pause",
+	"f": "resync",
+	"x": "wrong",
+	"s": "save",
+	"a": "approve",
+	"r": "reject",
+	"d": "score",
+}
+
+// ReadControls 逐行读取终端输入，执行对应的操作，操作的 ctx 为 ctx
+func (s *Session) ReadControls(ctx context.Context, in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		name, ok := ControlKeys[strings.ToLower(strings.TrimSpace(scanner.Text()))]
+		if !ok {
+			continue
+		}
+		if err := s.dash.Run(ctx, name); err != nil {
+			fmt.Printf("[%s] ⚠️  %v\n", time.Now().Format("15:04:05"), err)
+		}
+	}
+}
+
+// TogglePause 暂停或继续同步
+func (s *Session) TogglePause() error {
+	now := !s.paused.Load()
+	s.paused.Store(now)
+	s.dash.Update(func(st *dashboard.Status) { st.Paused = now })
+
+	if now {
+		fmt.Printf("[%s] ⏸️  同步已暂停\n", time.Now().Format("15:04:05"))
+	} else {
+		fmt.Printf("[%s] ▶️  同步已继续\n", time.Now().Format("15:04:05"))
+	}
+	return nil
+}
+
+// ForceResync 清除双方最后一手的记录，下一轮重新比较并同步（已有棋子的位置会被跳过）
+func (s *Session) ForceResync() error {
+	s.state.Reset()
+	s.detector.ResetSmoothing()
+
+	fmt.Printf("[%s] 🔄 强制重新同步\n", time.Now().Format("15:04:05"))
+	return nil
+}
+
+// MarkLastMoveWrong 在棋谱最后一手上注明识别有误，便于赛后核对
+func (s *Session) MarkLastMoveWrong() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last := s.record.LastMove()
+	if last == nil {
+		return fmt.Errorf("棋谱中还没有棋步")
+	}
+	last.Set("C", "识别有误")
+	fmt.Printf("[%s] 🏷️  已标记第 %d 手 %s 识别有误\n",
+		time.Now().Format("15:04:05"),
+		s.moveNumber(),
+		coords.Format(last.X, last.Y, coords.GTP),
+	)
+	return nil
+}
+
+// ApproveSuggestion 在手机上落下待确认的 KaTrain 建议
+func (s *Session) ApproveSuggestion(ctx context.Context) error {
+	m := s.suggestion.Swap(nil)
+	if m == nil {
+		return fmt.Errorf("没有待确认的建议")
+	}
+	s.dash.Update(func(st *dashboard.Status) { st.Suggestion = "" })
+	return s.playOnPhone(ctx, *m)
+}
+
+// RejectSuggestion 放弃待确认的 KaTrain 建议，手机上不落子
+func (s *Session) RejectSuggestion() error {
+	m := s.suggestion.Swap(nil)
+	if m == nil {
+		return fmt.Errorf("没有待确认的建议")
+	}
+	s.dash.Update(func(st *dashboard.Status) { st.Suggestion = "" })
+	fmt.Printf("[%s] 🚫 已放弃建议 %s\n", time.Now().Format("15:04:05"), coords.Format(m.X, m.Y, coords.GTP))
+	return nil
+}
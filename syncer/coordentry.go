@@ -0,0 +1,53 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"goboardsync/adb"
+	"goboardsync/coords"
+	"goboardsync/sgf"
+)
+
+// expandCoordinate 把坐标输入流程中的占位符换成 KaTrain 坐标 (x, y) 的各种写法：
+// {coord} 为 GTP 写法（Q16），{col}、{row} 为其列字母与行号，{tencent} 为腾讯围棋的写法，{sgf} 为 SGF 坐标（pd）
+func expandCoordinate(flow string, x, y int) string {
+	return strings.NewReplacer(
+		"{coord}", coords.Format(x, y, coords.GTP),
+		"{col}", coords.ColumnLetter(x, true),
+		"{row}", strconv.Itoa(y+1),
+		"{tencent}", coords.Format(x, y, coords.Tencent),
+		"{sgf}", sgf.NewGame().Point(x, y),
+	).Replace(flow)
+}
+
+// parseCoordinateFlow 检查 CoordinateFlow 的格式，至少要用到一个坐标占位符
+func parseCoordinateFlow(flow string) error {
+	if expandCoordinate(flow, 0, 0) == flow {
+		return fmt.Errorf("坐标输入流程中没有 {coord}、{sgf} 等坐标占位符: %q", flow)
+	}
+	if _, err := adb.ParseFlow(expandCoordinate(flow, 0, 0)); err != nil {
+		return fmt.Errorf("坐标输入流程配置错误: %v", err)
+	}
+	return nil
+}
+
+// enterCoordinate 按 CoordinateFlow 在 App 的坐标输入框中输入 (x, y) 落子，不依赖棋盘在屏幕上的像素位置
+func (s *Session) enterCoordinate(ctx context.Context, x, y int) error {
+	if !coords.Valid(x, y) {
+		return fmt.Errorf("坐标超出棋盘: (%d, %d)", x, y)
+	}
+	flow, err := adb.ParseFlow(expandCoordinate(s.cfg.CoordinateFlow, x, y))
+	if err != nil {
+		return err
+	}
+	if err := s.phone.RunFlow(ctx, flow); err != nil {
+		return fmt.Errorf("坐标输入失败: %v", err)
+	}
+
+	fmt.Printf("[%s] ✅ 落子成功！%s 已通过坐标输入落子\n", time.Now().Format("15:04:05"), coords.Format(x, y, coords.GTP))
+	return nil
+}
@@ -0,0 +1,235 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"goboardsync/adb"
+	"goboardsync/board"
+	"goboardsync/dashboard"
+	"goboardsync/notify"
+)
+
+// watchDevice 定期检查手机屏幕与前台应用。熄屏、锁屏或 App 退到后台时截图内容不是棋盘，
+// 暂停同步直到恢复；WakeDevice 开启时自动点亮屏幕并把 App 切回前台
+func (s *Session) watchDevice(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.DeviceCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		// 断开期间由 trackDevices 负责，adb 调用只会失败
+		if s.disconnected.Load() {
+			continue
+		}
+
+		problem := s.deviceProblem(ctx)
+		if away := problem != ""; away != s.deviceAway.Swap(away) {
+			if away {
+				fmt.Printf("[%s] 💤 %s，暂停同步\n", time.Now().Format("15:04:05"), problem)
+			} else {
+				fmt.Printf("[%s] ▶️  手机已恢复，继续同步\n", time.Now().Format("15:04:05"))
+			}
+			s.dash.Update(func(st *dashboard.Status) { st.Device = problem })
+		}
+
+		if problem != "" && s.cfg.WakeDevice {
+			s.wakeDevice(ctx)
+		}
+		s.checkBattery(ctx)
+	}
+}
+
+// DeviceRetryInterval adb track-devices 退出（如 adb server 重启）后重新监听、
+// WiFi 调试的手机断开后重新 adb connect 的间隔
+const DeviceRetryInterval = 5 * time.Second
+
+// trackDevices 通过 adb track-devices 监听手机插拔：断开（或变为 offline、unauthorized）时暂停同步并通知，
+// 重新连上后再继续，而不是断开期间每次截图、点击各自报错。WiFi 调试的手机断开后定期重新 adb connect
+func (s *Session) trackDevices(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(DeviceRetryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if s.disconnected.Load() && s.phone.IsNetwork() {
+				s.phone.Connect(ctx)
+			}
+		}
+	}()
+
+	for {
+		err := s.phone.TrackDevices(ctx, s.observeDevices)
+		if ctx.Err() != nil {
+			return
+		}
+		fmt.Printf("[%s] ⚠️  %v，%v 后重新监听\n", time.Now().Format("15:04:05"), err, DeviceRetryInterval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(DeviceRetryInterval):
+		}
+	}
+}
+
+// observeDevices 处理 track-devices 报告的设备列表。手机重新连上时清除双方最后一手的记录，
+// 下一轮重新比较局面，断开期间手机上落下的棋子照常同步（KaTrain 已有的跳过）
+func (s *Session) observeDevices(devices []adb.Device) {
+	problem := ""
+	if d, ok := s.phone.Find(devices); !ok && s.phone.Serial == "" && len(devices) > 1 {
+		problem = "连接了多台设备，请设置 ADBSerial"
+	} else if !ok {
+		problem = "手机已断开"
+	} else if d.State != "device" {
+		problem = fmt.Sprintf("手机状态异常（%s）", d.State)
+	}
+	if away := problem != ""; away == s.disconnected.Swap(away) {
+		return
+	}
+
+	if problem != "" {
+		fmt.Printf("[%s] 🔌 %s，暂停同步\n", time.Now().Format("15:04:05"), problem)
+		s.dash.Update(func(st *dashboard.Status) { st.Device = problem })
+		s.notifyEvent(notify.DeviceDisconnected, problem+"，同步已暂停")
+		return
+	}
+
+	s.state.Reset()
+	fmt.Printf("[%s] 🔌 手机已重新连接，重新比较局面后继续同步\n", time.Now().Format("15:04:05"))
+	if !s.deviceAway.Load() {
+		s.dash.Update(func(st *dashboard.Status) { st.Device = "" })
+	}
+	s.notifyEvent(notify.DeviceReconnected, "手机已重新连接，继续同步")
+}
+
+// 解除降频时留出的余量，避免电量、温度在阈值附近来回切换
+const (
+	throttleBatteryMargin     = 5
+	throttleTemperatureMargin = 2.0
+)
+
+// checkBattery 电量过低（且未充电）或电池过热时把截图间隔放慢到 ThrottleInterval，恢复后还原。
+// 读不到电池信息时保持原状态
+func (s *Session) checkBattery(ctx context.Context) {
+	if s.cfg.ThrottleInterval <= 0 || (s.cfg.ThrottleBatteryBelow <= 0 && s.cfg.ThrottleTemperatureAbove <= 0) {
+		return
+	}
+	b, err := s.phone.Battery(ctx)
+	if err != nil {
+		return
+	}
+
+	throttled := s.throttled.Load()
+	reason := throttleReason(b, s.cfg.ThrottleBatteryBelow, s.cfg.ThrottleTemperatureAbove)
+	if throttled && reason == "" {
+		// 已降频时需回到阈值以内一定余量才恢复，未启用的阈值保持为 0
+		batteryBelow, temperatureAbove := s.cfg.ThrottleBatteryBelow, s.cfg.ThrottleTemperatureAbove
+		if batteryBelow > 0 {
+			batteryBelow += throttleBatteryMargin
+		}
+		if temperatureAbove > 0 {
+			temperatureAbove -= throttleTemperatureMargin
+		}
+		reason = throttleReason(b, batteryBelow, temperatureAbove)
+	}
+	if (reason != "") == throttled {
+		return
+	}
+
+	s.throttled.Store(reason != "")
+	if reason != "" {
+		fmt.Printf("[%s] 🌡️  %s，截图间隔放慢到 %v\n", time.Now().Format("15:04:05"), reason, s.cfg.ThrottleInterval)
+	} else {
+		fmt.Printf("[%s] 🌡️  电量 %d%%、温度 %.1f°C 已恢复，截图间隔还原\n", time.Now().Format("15:04:05"), b.Level, b.Temperature)
+	}
+	s.dash.Update(func(st *dashboard.Status) { st.Throttle = reason })
+}
+
+func throttleReason(b adb.Battery, batteryBelow int, temperatureAbove float64) string {
+	if temperatureAbove > 0 && b.Temperature > temperatureAbove {
+		return fmt.Sprintf("电池温度 %.1f°C", b.Temperature)
+	}
+	if batteryBelow > 0 && !b.Charging && b.Level < batteryBelow {
+		return fmt.Sprintf("电量仅剩 %d%%", b.Level)
+	}
+	return ""
+}
+
+// captureInterval 返回当前的截图间隔：开启 AdaptToPhase 时布局阶段减半，降频期间不快于 ThrottleInterval
+func (s *Session) captureInterval() time.Duration {
+	interval := s.tuned.Load().Interval
+	if s.cfg.AdaptToPhase && board.Phase(s.phase.Load()) == board.Opening {
+		interval /= 2
+	}
+	if s.throttled.Load() && interval < s.cfg.ThrottleInterval {
+		return s.cfg.ThrottleInterval
+	}
+	return interval
+}
+
+// deviceProblem 返回手机当前不能同步的原因，正常时返回空字符串。
+// adb 本身出错时不判断，交给截图环节报告
+func (s *Session) deviceProblem(ctx context.Context) string {
+	if on, err := s.phone.ScreenOn(ctx); err != nil {
+		return ""
+	} else if !on {
+		return "屏幕已关闭"
+	}
+
+	if locked, err := s.phone.Locked(ctx); err == nil && locked {
+		return "屏幕已锁定"
+	}
+
+	if s.cfg.AppPackage == "" {
+		return ""
+	}
+	if pkg, err := s.phone.ForegroundPackage(ctx); err == nil && pkg != "" && pkg != s.cfg.AppPackage {
+		return fmt.Sprintf("App 不在前台（当前 %s）", pkg)
+	}
+	return ""
+}
+
+// ResumeRetryInterval 两次执行返回对局流程之间的最短间隔，避免 App 加载慢时反复点击
+const ResumeRetryInterval = 30 * time.Second
+
+// wakeDevice 点亮屏幕、解除无密码锁屏，并把 App 切回前台。App 此前不在前台
+// （例如误触退出）时按 ResumeFlow 从 App 首页点回正在进行的对局
+func (s *Session) wakeDevice(ctx context.Context) {
+	if err := s.phone.Wake(ctx); err != nil {
+		fmt.Printf("[%s] ⚠️  唤醒手机失败: %v\n", time.Now().Format("15:04:05"), err)
+		return
+	}
+	if s.cfg.AppPackage == "" {
+		return
+	}
+	if pkg, err := s.phone.ForegroundPackage(ctx); err != nil || pkg == s.cfg.AppPackage {
+		return
+	}
+
+	app := s.cfg.AppPackage
+	if s.cfg.AppActivity != "" {
+		app += "/" + s.cfg.AppActivity
+	}
+	if err := s.phone.Launch(ctx, app); err != nil {
+		fmt.Printf("[%s] ⚠️  启动 %s 失败: %v\n", time.Now().Format("15:04:05"), app, err)
+		return
+	}
+
+	if len(s.resumeFlow) == 0 || time.Since(s.resumedAt) < ResumeRetryInterval {
+		return
+	}
+	s.resumedAt = time.Now()
+	fmt.Printf("[%s] 🧭 已启动 %s，按配置的流程返回对局\n", time.Now().Format("15:04:05"), app)
+	if err := s.phone.RunFlow(ctx, s.resumeFlow); err != nil {
+		fmt.Printf("[%s] ⚠️  返回对局失败: %v\n", time.Now().Format("15:04:05"), err)
+	}
+}
@@ -0,0 +1,72 @@
+package syncer
+
+import (
+	"fmt"
+	"time"
+
+	"goboardsync/dashboard"
+	"goboardsync/notify"
+
+	"gocv.io/x/gocv"
+)
+
+// driftMonitor 识别偏差的连续帧计数。状态（偏离或正常）连续 frames 帧与当前不同时才切换，
+// 避免一手棋刚落下、KaTrain 还没同步时的短暂差异或单帧误判引起报警
+type driftMonitor struct {
+	alerted bool
+	streak  int
+}
+
+// observe 记录一帧是否偏离，返回报警状态是否切换
+func (m *driftMonitor) observe(drifting bool, frames int) bool {
+	if drifting == m.alerted {
+		m.streak = 0
+		return false
+	}
+	m.streak++
+	if m.streak < frames {
+		return false
+	}
+	m.alerted, m.streak = drifting, 0
+	return true
+}
+
+// katrainMoves KaTrain 当前的手数：双向同步时为轮询 KaTrain 读到的手数（中途接入后还没有落子时为摆上的局面的手数），
+// 观战模式或不能读取 KaTrain 棋步时为已同步到 KaTrain 的手数
+func (s *Session) katrainMoves() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.moveSource(); ok && !s.cfg.Spectator {
+		return max(int(s.katrainMove.Load()), s.setupMoves)
+	}
+	return s.moveNumber()
+}
+
+// checkDrift 识别整盘局面，按棋子数加提子数推断手机上的手数，与 KaTrain 的手数比较。
+// 连续 DriftFrames 帧相差超过 DriftThreshold 时推送通知并在看板显示横幅，恢复后撤下横幅。只由 syncPhoneToKatrain 调用
+func (s *Session) checkDrift(img gocv.Mat) {
+	b, err := s.detector.ReadScreenBoard(img)
+	if err != nil {
+		return
+	}
+	s.observeDrift(s.game.InferMoveNumber(&b), s.katrainMoves())
+}
+
+func (s *Session) observeDrift(phoneMoves, katrainMoves int) {
+	diff := phoneMoves - katrainMoves
+	if !s.drift.observe(diff > s.cfg.DriftThreshold || -diff > s.cfg.DriftThreshold, s.cfg.DriftFrames) {
+		return
+	}
+
+	if !s.drift.alerted {
+		fmt.Printf("[%s] ✅ 识别已恢复正常：手机约 %d 手，KaTrain %d 手\n", time.Now().Format("15:04:05"), phoneMoves, katrainMoves)
+		s.dash.Update(func(st *dashboard.Status) { st.Alert = "" })
+		return
+	}
+
+	msg := fmt.Sprintf("识别持续偏离：手机盘面约 %d 手（棋子数加提子数），KaTrain %d 手，已连续 %d 帧，请检查棋盘参数与 KaTrain 局面",
+		phoneMoves, katrainMoves, s.cfg.DriftFrames)
+	fmt.Printf("[%s] 🚨 %s\n", time.Now().Format("15:04:05"), msg)
+	s.dash.Update(func(st *dashboard.Status) { st.Alert = msg })
+	s.notifyEvent(notify.Drift, msg)
+}
@@ -0,0 +1,125 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"goboardsync/target"
+)
+
+// featureNames 可选功能在日志中的名称与不支持时的影响
+var featureNames = map[target.Feature]string{
+	target.FeatureLastMove:       "读取最后一手（不同步 KaTrain → 手机）",
+	target.FeatureNewGame:        "设置新对局（只清空棋盘）",
+	target.FeatureAnalysis:       "分析结果（棋谱不加注释，叠加画面不显示胜率）",
+	target.FeatureAnalysisBudget: "限制分析计算量（沿用 KaTrain 自己的设置）",
+	target.FeatureDeadStones:     "标记死子（数子结果只按盘面估算）",
+	target.FeatureSetupPosition:  "摆放局面（中途接入时逐个落子）",
+}
+
+// probeTarget 启动时探测同步目标支持的功能并打印不支持的功能。探测失败（如 KaTrain 还没启动）时
+// 按全部支持处理，之后哪个接口返回“不支持”再停用对应功能
+func (s *Session) probeTarget(ctx context.Context) {
+	p, ok := s.target.(target.Prober)
+	if !ok {
+		return
+	}
+
+	version, missing, err := p.Probe(ctx)
+	if err != nil {
+		fmt.Printf("[%s] ⚠️  探测 %s 接口失败，按全部支持处理: %v\n", time.Now().Format("15:04:05"), s.target.Name(), err)
+		return
+	}
+	if version == "" {
+		version = "未知（没有 /api/version）"
+	}
+	fmt.Printf("[%s] 🔌 %s 版本: %s\n", time.Now().Format("15:04:05"), s.target.Name(), version)
+	if len(missing) > 0 {
+		var names []string
+		for _, f := range missing {
+			// 没有配置分析计算量时用不到该接口，不必提示
+			if f == target.FeatureAnalysisBudget && s.analysisBudget() == (target.AnalysisBudget{}) {
+				continue
+			}
+			// 没有开启数子交接时同样不提示
+			if f == target.FeatureDeadStones && !s.cfg.DeadStoneScoring {
+				continue
+			}
+			if f == target.FeatureSetupPosition && !s.cfg.AttachMidGame {
+				continue
+			}
+			names = append(names, featureNames[f])
+		}
+		if len(names) > 0 {
+			fmt.Printf("[%s] ℹ️  %s 不支持: %s\n", time.Now().Format("15:04:05"), s.target.Name(), strings.Join(names, "、"))
+		}
+	}
+}
+
+// supports 目标是否支持可选功能 f；没有实现 target.Prober 的目标只看是否实现了对应接口
+func (s *Session) supports(f target.Feature) bool {
+	p, ok := s.target.(target.Prober)
+	return !ok || p.Supports(f)
+}
+
+// moveSource 目标支持读取最后一手时返回对应接口
+func (s *Session) moveSource() (target.MoveSource, bool) {
+	m, ok := s.target.(target.MoveSource)
+	return m, ok && s.supports(target.FeatureLastMove)
+}
+
+// gameSetter 目标支持设置新对局时返回对应接口
+func (s *Session) gameSetter() (target.GameSetter, bool) {
+	g, ok := s.target.(target.GameSetter)
+	return g, ok && s.supports(target.FeatureNewGame)
+}
+
+// positionSetter 目标支持摆放局面时返回对应接口
+func (s *Session) positionSetter() (target.PositionSetter, bool) {
+	p, ok := s.target.(target.PositionSetter)
+	return p, ok && s.supports(target.FeatureSetupPosition)
+}
+
+// analyzer 目标支持读取分析结果时返回对应接口
+func (s *Session) analyzer() (target.Analyzer, bool) {
+	a, ok := s.target.(target.Analyzer)
+	return a, ok && s.supports(target.FeatureAnalysis)
+}
+
+// budgetSetter 目标支持限制分析计算量时返回对应接口
+func (s *Session) budgetSetter() (target.BudgetSetter, bool) {
+	b, ok := s.target.(target.BudgetSetter)
+	return b, ok && s.supports(target.FeatureAnalysisBudget)
+}
+
+func (s *Session) analysisBudget() target.AnalysisBudget {
+	return target.AnalysisBudget{Visits: s.cfg.AnalysisVisits, Time: s.cfg.AnalysisTime}
+}
+
+// applyAnalysisBudget 配置了 AnalysisVisits 或 AnalysisTime 时限制 KaTrain 每一手的分析计算量
+func (s *Session) applyAnalysisBudget(ctx context.Context) {
+	budget := s.analysisBudget()
+	if budget == (target.AnalysisBudget{}) {
+		return
+	}
+	setter, ok := s.budgetSetter()
+	if !ok {
+		fmt.Printf("[%s] ℹ️  %s 不支持限制分析计算量，沿用 KaTrain 自己的设置\n", time.Now().Format("15:04:05"), s.target.Name())
+		return
+	}
+	if err := setter.SetAnalysisBudget(ctx, budget); err != nil {
+		fmt.Printf("[%s] ⚠️  设置 KaTrain 分析计算量失败: %v\n", time.Now().Format("15:04:05"), err)
+		return
+	}
+
+	var limits []string
+	if budget.Visits > 0 {
+		limits = append(limits, fmt.Sprintf("%d 次访问", budget.Visits))
+	}
+	if budget.Time > 0 {
+		limits = append(limits, budget.Time.String())
+	}
+	fmt.Printf("[%s] 🧮 KaTrain 每一手最多分析 %s\n", time.Now().Format("15:04:05"), strings.Join(limits, "、"))
+}
@@ -0,0 +1,22 @@
+package syncer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ColorFilter 某个同步方向允许同步的颜色："B" 只同步黑棋，"W" 只同步白棋，为空或 "BW" 时双方都同步
+type ColorFilter string
+
+// Allows 判断 color（"B" 或 "W"）的棋步是否需要同步
+func (f ColorFilter) Allows(color string) bool {
+	return f == "" || strings.Contains(strings.ToUpper(string(f)), color)
+}
+
+// Validate 检查是否只包含 B、W
+func (f ColorFilter) Validate() error {
+	if strings.Trim(strings.ToUpper(string(f)), "BW") != "" {
+		return fmt.Errorf("颜色过滤只能包含 B、W: %q", string(f))
+	}
+	return nil
+}
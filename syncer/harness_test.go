@@ -0,0 +1,324 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"goboardsync/adb"
+	"goboardsync/capture"
+	"goboardsync/coords"
+	"goboardsync/hooks"
+	"goboardsync/katrain"
+	"goboardsync/katrain/katraintest"
+	"goboardsync/target"
+	"goboardsync/vision"
+
+	"gocv.io/x/gocv"
+)
+
+// fakePhone 代替 adb 可执行文件，记录所有点击，其他命令一律成功
+type fakePhone struct {
+	mu   sync.Mutex
+	taps []image.Point
+}
+
+func (p *fakePhone) run(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := strings.Join(args, " ")
+	var x, y int
+	if _, err := fmt.Sscanf(cmd, "shell input tap %d %d", &x, &y); err == nil {
+		p.mu.Lock()
+		p.taps = append(p.taps, image.Pt(x, y))
+		p.mu.Unlock()
+	}
+	return nil, nil
+}
+
+func (p *fakePhone) Taps() []image.Point {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]image.Point(nil), p.taps...)
+}
+
+// harness 把会话接到假手机与假 KaTrain 上：截图来自 source，点击记录在 phone，落子记录在 katrain
+type harness struct {
+	s       *Session
+	phone   *fakePhone
+	katrain *katraintest.Server
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func newHarness(t *testing.T, source capture.Source) *harness {
+	t.Helper()
+	h := &harness{phone: &fakePhone{}, katrain: katraintest.NewServer(), done: make(chan struct{})}
+	t.Cleanup(h.katrain.Close)
+
+	cfg := DefaultConfig()
+	cfg.Source = source
+	cfg.Phone = &adb.Client{Runner: h.phone.run}
+	cfg.Target = target.NewKaTrain(h.katrain.URL)
+	cfg.RecordDir = t.TempDir()
+	cfg.DashboardAddr = ""
+	cfg.EnableScrcpy = false
+	cfg.DeviceCheckInterval = 0
+	cfg.TrackDevices = false
+	// 脚本与录制的截图都从第一手开始，逐手同步，不做中途接入
+	cfg.AttachMidGame = false
+	cfg.Tunables.Interval = 10 * time.Millisecond
+	cfg.Tunables.PollInterval = 10 * time.Millisecond
+	cfg.Tunables.TapDelay = time.Millisecond
+	cfg.Tunables.ConfirmTimeout = 0
+
+	s, err := NewSession(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	h.s = s
+	return h
+}
+
+// start 在后台运行同步，stop 停止并等待会话保存棋谱
+func (h *harness) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	go func() {
+		h.s.Run(ctx)
+		close(h.done)
+	}()
+}
+
+func (h *harness) stop() {
+	h.cancel()
+	<-h.done
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(30 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待超时: %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// scriptedSource 按顺序给出预先写好的识别结果，代替真实截图与识别，只检验同步流程本身。
+// 每次 Grab 前进一帧，放完后停在最后一帧
+type scriptedSource struct {
+	mu     sync.Mutex
+	frames []vision.Result
+	cur    int
+}
+
+func newScriptedSource(frames ...vision.Result) *scriptedSource {
+	return &scriptedSource{frames: frames, cur: -1}
+}
+
+func (s *scriptedSource) Grab(context.Context) (gocv.Mat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cur < len(s.frames)-1 {
+		s.cur++
+	}
+	return gocv.NewMat(), nil
+}
+
+func (s *scriptedSource) Close() error { return nil }
+
+func (s *scriptedSource) recognize(context.Context, gocv.Mat) (*vision.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := s.frames[s.cur]
+	return &result, nil
+}
+
+func TestSyncBothWays(t *testing.T) {
+	// 第 0 帧为开局的空棋盘，开始同步前读取对局者名字时取走
+	source := newScriptedSource(
+		vision.Result{},
+		vision.Result{Move: 1, X: 16, Y: 4, Color: "B"},
+		vision.Result{Move: 2, X: 4, Y: 16, Color: "W"},
+		vision.Result{Move: 3, X: 17, Y: 16, Color: "B"},
+	)
+	h := newHarness(t, source)
+	h.s.recognize = source.recognize
+	h.start()
+
+	var want []katraintest.Move
+	for _, f := range source.frames[1:] {
+		x, y := coords.FromPhone(f.X, f.Y)
+		want = append(want, katraintest.Move{X: x, Y: y, Player: f.Color})
+	}
+	waitFor(t, "手机上的棋步同步到 KaTrain", func() bool { return len(h.katrain.Moves()) >= len(want) })
+
+	// 同步到 KaTrain 的棋步不应再被点回手机
+	time.Sleep(50 * time.Millisecond)
+	if taps := h.phone.Taps(); len(taps) != 0 {
+		t.Fatalf("手机 → KaTrain 的棋步被点回了手机: %v", taps)
+	}
+
+	// 在 KaTrain 中落子（例如 AI 应对），应在手机上点击落子点与确认按钮
+	if err := h.katrain.Play(15, 3, "W"); err != nil {
+		t.Fatalf("Play(15, 3, W) error = %v", err)
+	}
+	want = append(want, katraintest.Move{X: 15, Y: 3, Player: "W"})
+	waitFor(t, "KaTrain 的棋步点到手机", func() bool { return len(h.phone.Taps()) >= 2 })
+	h.stop()
+
+	if got := h.katrain.Moves(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("KaTrain 棋步 = %v, want %v", got, want)
+	}
+
+	screenX, screenY := h.s.gridToScreen(15, 3)
+	g := h.s.geometry()
+	wantTaps := []image.Point{{screenX, screenY}, {g.ConfirmX, g.ConfirmY}}
+	if got := h.phone.Taps(); fmt.Sprint(got) != fmt.Sprint(wantTaps) {
+		t.Errorf("手机点击 = %v, want %v", got, wantTaps)
+	}
+
+	if n := len(h.s.record.Nodes); n != len(want) {
+		t.Errorf("棋谱手数 = %d, want %d", n, len(want))
+	}
+	if setup, ok := h.katrain.Setup(); !ok || setup.Komi != 7.5 {
+		t.Errorf("KaTrain 对局设置 = %+v, %v, want 贴目 7.5", setup, ok)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	source := newScriptedSource(
+		vision.Result{},
+		vision.Result{Move: 1, X: 16, Y: 4, Color: "B"},
+		vision.Result{Move: 2, X: 4, Y: 16, Color: "W"},
+	)
+	h := newHarness(t, source)
+	h.s.recognize = source.recognize
+
+	var mu sync.Mutex
+	var events []string
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(events)
+	}
+	h.s.hooks = hooks.NewDispatcher([]hooks.Named{{Name: "record", Hook: hooks.Func(func(e hooks.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, strings.TrimSpace(fmt.Sprintf("%s %s %s", e.Kind, e.Coord, e.Direction)))
+		return nil
+	})}})
+	h.start()
+
+	waitFor(t, "手机上的棋步同步到 KaTrain", func() bool { return len(h.katrain.Moves()) >= 2 })
+	if err := h.katrain.Play(15, 3, "W"); err != nil {
+		t.Fatalf("Play(15, 3, W) error = %v", err)
+	}
+	waitFor(t, "扩展收到 KaTrain → 手机的同步", func() bool { return count() >= 5 })
+	h.stop()
+	h.s.hooks.Close()
+
+	want := []string{
+		"move_detected Q16",
+		"move_synced Q16 phone_to_katrain",
+		"move_detected D4",
+		"move_synced D4 phone_to_katrain",
+		"move_synced Q4 katrain_to_phone",
+		"game_ended",
+	}
+	if strings.Join(events, "\n") != strings.Join(want, "\n") {
+		t.Errorf("扩展收到的事件:\n%s\nwant:\n%s", strings.Join(events, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLegacyKatrain(t *testing.T) {
+	source := newScriptedSource(
+		vision.Result{},
+		vision.Result{Move: 1, X: 16, Y: 4, Color: "B"},
+	)
+	h := newHarness(t, source)
+	h.s.recognize = source.recognize
+	// 旧版补丁：只能落子，不能读取最后一手、设置对局与分析
+	h.katrain.Disable(katrain.PathLastMove, katrain.PathNewGame, katrain.PathAnalysis)
+	h.start()
+
+	waitFor(t, "手机上的棋步同步到 KaTrain", func() bool { return len(h.katrain.Moves()) >= 1 })
+	if err := h.katrain.Play(15, 3, "W"); err != nil {
+		t.Fatalf("Play(15, 3, W) error = %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	h.stop()
+
+	if taps := h.phone.Taps(); len(taps) != 0 {
+		t.Errorf("不支持读取最后一手时不应点击手机: %v", taps)
+	}
+	for _, f := range []target.Feature{target.FeatureLastMove, target.FeatureNewGame, target.FeatureAnalysis} {
+		if h.s.supports(f) {
+			t.Errorf("supports(%s) = true, want false", f)
+		}
+	}
+	if _, ok := h.katrain.Setup(); ok {
+		t.Error("不支持设置对局时不应调用 new-game")
+	}
+}
+
+func TestRejectMoveNumberJump(t *testing.T) {
+	source := newScriptedSource(
+		vision.Result{},
+		vision.Result{Move: 1, X: 16, Y: 4, Color: "B"},
+		// 手数 2 误读成 72，同一帧的角标也落在了别处
+		vision.Result{Move: 72, X: 10, Y: 10, Color: "W"},
+		vision.Result{Move: 2, X: 4, Y: 16, Color: "W"},
+	)
+	h := newHarness(t, source)
+	h.s.recognize = source.recognize
+	h.start()
+
+	waitFor(t, "手机上的棋步同步到 KaTrain", func() bool { return len(h.katrain.Moves()) >= 2 })
+	time.Sleep(50 * time.Millisecond)
+	h.stop()
+
+	var want []katraintest.Move
+	for _, f := range []vision.Result{source.frames[1], source.frames[3]} {
+		x, y := coords.FromPhone(f.X, f.Y)
+		want = append(want, katraintest.Move{X: x, Y: y, Player: f.Color})
+	}
+	if got := h.katrain.Moves(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("KaTrain 棋步 = %v, want %v（手数跳变的一帧不应同步）", got, want)
+	}
+}
+
+func TestEngineRejectsJumpAfterPlay(t *testing.T) {
+	source := newScriptedSource(
+		// 自己刚点出的第 1 手
+		vision.Result{Move: 1, X: 16, Y: 4, Color: "B"},
+		// 对手的第 2 手，手数误读成 72，同一帧的角标也落在了别处
+		vision.Result{Move: 72, X: 10, Y: 10, Color: "W"},
+		vision.Result{Move: 2, X: 4, Y: 16, Color: "W"},
+	)
+	h := newHarness(t, source)
+	h.s.recognize = source.recognize
+	engine := phoneEngine{h.s}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := engine.Play(ctx, 15, 3, "B"); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if last := h.s.state.Phone(); last.Move != 1 {
+		t.Errorf("Play() 后手机最后一手的手数 = %d, want 1", last.Move)
+	}
+
+	x, y, _, err := engine.NextMove(ctx, "W")
+	if err != nil {
+		t.Fatalf("NextMove() error = %v", err)
+	}
+	if wantX, wantY := h.s.phoneToBoard(4, 16); x != wantX || y != wantY {
+		t.Errorf("NextMove() = (%d, %d), want (%d, %d)（手数跳变的一帧不应作为对手的棋步）", x, y, wantX, wantY)
+	}
+}
@@ -0,0 +1,71 @@
+//go:build integration
+
+package syncer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"goboardsync/capture"
+	"goboardsync/katrain/katraintest"
+	"goboardsync/vision"
+)
+
+// TestReplayRecordedGame 回放 images 目录中录制的截图，走完整的 OCR 与角标识别，
+// 检查每一手都按顺序同步到 KaTrain 且没有点回手机。需要 OpenCV 与 Tesseract：
+//
+//	go test -tags integration ./syncer/
+func TestReplayRecordedGame(t *testing.T) {
+	const frames = 8
+
+	type sample struct {
+		path  string
+		move  int
+		color string
+		x, y  int
+	}
+	entries, err := os.ReadDir("../images")
+	if err != nil {
+		t.Fatalf("读取录制截图失败: %v", err)
+	}
+	var samples []sample
+	for _, e := range entries {
+		move, color, x, y, err := vision.ParseSampleFilename(e.Name())
+		if err != nil {
+			continue
+		}
+		samples = append(samples, sample{filepath.Join("../images", e.Name()), move, color, x, y})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].move < samples[j].move })
+	if len(samples) > frames {
+		samples = samples[:frames]
+	}
+
+	// 开始同步前读取对局者名字时取走一帧，第一张截图放两次
+	paths := []string{samples[0].path}
+	for _, smp := range samples {
+		paths = append(paths, smp.path)
+	}
+	source := capture.NewReplaySource(paths)
+	h := newHarness(t, source)
+	h.start()
+
+	var want []katraintest.Move
+	for _, smp := range samples {
+		x, y := h.s.phoneToBoard(smp.x, smp.y)
+		want = append(want, katraintest.Move{X: x, Y: y, Player: smp.color})
+	}
+	waitFor(t, "回放完全部截图", source.Done)
+	waitFor(t, "录制的棋步同步到 KaTrain", func() bool { return len(h.katrain.Moves()) >= len(want) })
+	h.stop()
+
+	if got := h.katrain.Moves(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("KaTrain 棋步 = %v, want %v", got, want)
+	}
+	if taps := h.phone.Taps(); len(taps) != 0 {
+		t.Errorf("手机 → KaTrain 的棋步被点回了手机: %v", taps)
+	}
+}
@@ -0,0 +1,71 @@
+package syncer
+
+import (
+	"runtime"
+	"sync"
+
+	"gocv.io/x/gocv"
+)
+
+// liveView 实时预览窗口，显示校正后的棋盘与识别结果的叠加图（见 Session.drawOverlay）。
+// OpenCV 的窗口要在同一个系统线程上创建和刷新，所以由单独的 goroutine 锁定线程显示；
+// 识别循环只把叠加图交给它，窗口来不及刷新时丢弃这一帧，不拖慢识别
+type liveView struct {
+	mu     sync.Mutex
+	closed bool
+	frames chan gocv.Mat
+	done   chan struct{}
+}
+
+func newLiveView(title string) *liveView {
+	v := &liveView{frames: make(chan gocv.Mat, 1), done: make(chan struct{})}
+	go v.run(title)
+	return v
+}
+
+func (v *liveView) run(title string) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(v.done)
+
+	window := gocv.NewWindow(title)
+	defer window.Close()
+	for frame := range v.frames {
+		window.IMShow(frame)
+		frame.Close()
+		window.WaitKey(1)
+	}
+}
+
+// show 显示叠加图（复制一份，overlay 仍由调用方 Close）；v 为 nil（未开启）时什么都不做
+func (v *liveView) show(overlay gocv.Mat) {
+	if v == nil {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.closed {
+		return
+	}
+	frame := overlay.Clone()
+	select {
+	case v.frames <- frame:
+	default:
+		frame.Close()
+	}
+}
+
+// close 关闭窗口并等待显示的 goroutine 退出
+func (v *liveView) close() {
+	if v == nil {
+		return
+	}
+	v.mu.Lock()
+	if !v.closed {
+		v.closed = true
+		close(v.frames)
+	}
+	v.mu.Unlock()
+	<-v.done
+}
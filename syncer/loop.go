@@ -0,0 +1,267 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"goboardsync/capture"
+	"goboardsync/coords"
+	"goboardsync/dashboard"
+	"goboardsync/hooks"
+	"goboardsync/session"
+	"goboardsync/target"
+)
+
+// suspended 同步是否暂停：手动暂停、手机熄屏或 App 不在前台、手机断开
+func (s *Session) suspended() bool {
+	return s.paused.Load() || s.deviceAway.Load() || s.disconnected.Load()
+}
+
+func (s *Session) syncPhoneToKatrain(ctx context.Context) {
+	interval := s.captureInterval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var settle settleState
+	defer settle.close()
+	labelsChecked := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		retune(ticker, &interval, s.captureInterval())
+		if s.suspended() {
+			continue
+		}
+		if s.tableDue() {
+			s.switchTable(ctx)
+		}
+
+		img, stamp, err := capture.GrabStamped(ctx, s.source)
+		if err != nil {
+			fmt.Printf("[%s] 📸 截图失败: %v\n", time.Now().Format("15:04:05"), err)
+			s.reportError("截图", err)
+			continue
+		}
+		s.reportOK("截图")
+		s.lastFrame.Store(time.Now().UnixNano())
+		if s.cfg.CheckLabels && !labelsChecked {
+			labelsChecked = true
+			s.checkLabels(img)
+		}
+
+		result, err := s.recognize(ctx, img)
+		settled := err != nil || result == nil || s.settled(&settle, img, result)
+		s.archive.hold(img)
+		img.Close()
+		if err != nil {
+			fmt.Printf("[%s] ❌ 识别失败: %v\n", time.Now().Format("15:04:05"), err)
+			s.reportError("识别", err)
+			continue
+		}
+		s.reportOK("识别")
+		if result == nil {
+			continue
+		}
+		if result.X != 0 {
+			s.lastDetection.Store(time.Now().UnixNano())
+		}
+
+		fmt.Printf("[%s] ✅ 识别成功: 第 %d 手, 坐标: %d-%d, 颜色: %s\n",
+			time.Now().Format("15:04:05"),
+			result.Move,
+			result.X,
+			result.Y,
+			result.Color,
+		)
+
+		// 没找到最后一手时不更新记录，否则下一帧找到时手数对不上，会被当作跳变
+		if result.X == 0 {
+			continue
+		}
+		fromX, fromY := result.X, result.Y
+		if s.pickCandidate(result) {
+			fmt.Printf("[%s] 🔀 %d-%d 已有棋子且有得分接近的候选，改用 %d-%d\n",
+				time.Now().Format("15:04:05"), fromX, fromY, result.X, result.Y)
+		}
+		if !settled {
+			continue
+		}
+		katrainX, katrainY := s.phoneToBoard(result.X, result.Y)
+		prev, verdict := s.state.ObservePhone(session.Move{
+			Number:   result.Move,
+			X:        result.X,
+			Y:        result.Y,
+			Color:    result.Color,
+			Position: s.positionAfter(katrainX, katrainY, result.Color),
+			Stamp:    stamp,
+		})
+		if verdict == session.Stale {
+			fmt.Printf("[%s] ⏪ 截图早于已处理的画面，忽略第 %d 手 %s\n",
+				time.Now().Format("15:04:05"), result.Move, coords.Format(katrainX, katrainY, coords.GTP))
+			continue
+		}
+		if verdict == session.Jump {
+			fmt.Printf("[%s] ⚠️  手数跳变 %d → %d（%s），可能是 OCR 误读，暂不同步\n",
+				time.Now().Format("15:04:05"), prev.Move, result.Move, coords.Format(katrainX, katrainY, coords.GTP))
+			continue
+		}
+		if verdict == session.New {
+			fmt.Printf("[%s] 🔄 检测到新手: %d > %d  X:%d  Y:%d\n", time.Now().Format("15:04:05"), result.Move, prev.Move, result.X, result.Y)
+			if path, err := s.archive.save(result); err != nil {
+				fmt.Printf("[%s] ⚠️  %v\n", time.Now().Format("15:04:05"), err)
+			} else if path != "" {
+				fmt.Printf("[%s] 🗂️  样本截图已保存: %s\n", time.Now().Format("15:04:05"), path)
+			}
+			s.fireMove(hooks.MoveDetected, "", result.Move, result.Color, katrainX, katrainY)
+			if !s.cfg.PhoneToKatrainColors.Allows(result.Color) {
+				fmt.Printf("[%s] ℹ️  %s不同步到 KaTrain，跳过\n", time.Now().Format("15:04:05"), mapColorToChinese(result.Color))
+				continue
+			}
+			colorForKatrain := result.Color
+			if reason := s.localConflict(katrainX, katrainY); reason != "" {
+				fmt.Printf("[%s] ℹ️  %s，跳过: %s\n", time.Now().Format("15:04:05"), reason, coords.Format(katrainX, katrainY, coords.GTP))
+				continue
+			}
+			hasStone, err := s.target.HasStone(ctx, katrainX, katrainY)
+			if err != nil {
+				fmt.Printf("[%s] ❌ 检查位置失败: X:%d Y:%d %v\n", time.Now().Format("15:04:05"), katrainX, katrainY, err)
+				s.reportError("KaTrain 落子", err)
+			} else if !hasStone {
+				// 先按它在 KaTrain 上的手数记下这手，避免轮询 KaTrain 时把它当作新手再点回手机
+				s.mu.RLock()
+				echoMove := s.moveNumber() + 1
+				s.mu.RUnlock()
+				s.state.ExpectEcho(echoMove, katrainX, katrainY)
+				err := s.target.Play(ctx, katrainX, katrainY, colorForKatrain)
+				if err != nil {
+					s.state.CancelEcho(echoMove)
+					fmt.Printf("[%s] ❌ 同步落子失败: %v\n", time.Now().Format("15:04:05"), err)
+					s.reportError("KaTrain 落子", err)
+				} else {
+					s.reportOK("KaTrain 落子")
+					fmt.Printf("[%s] ✅ 手机→KaTrain: 第 %d 手 %s %s\n",
+						time.Now().Format("15:04:05"),
+						result.Move,
+						mapColorToChinese(colorForKatrain),
+						coords.Format(katrainX, katrainY, coords.GTP),
+					)
+					s.recordMove(ctx, colorForKatrain, katrainX, katrainY)
+					s.fireMove(hooks.MoveSynced, hooks.PhoneToKatrain, result.Move, colorForKatrain, katrainX, katrainY)
+					s.dash.Update(func(st *dashboard.Status) {
+						st.PhoneMove = result.Move
+						st.PhoneCoord = coords.Format(katrainX, katrainY, coords.GTP)
+					})
+				}
+			} else {
+				fmt.Printf("[%s] ℹ️  KaTrain 已有棋子，跳过: %s\n",
+					time.Now().Format("15:04:05"),
+					coords.Format(katrainX, katrainY, coords.GTP),
+				)
+			}
+		}
+	}
+}
+
+func (s *Session) syncKatrainToPhone(ctx context.Context, katrain target.MoveSource) {
+	interval := s.tuned.Load().PollInterval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		retune(ticker, &interval, s.tuned.Load().PollInterval)
+		if s.suspended() {
+			continue
+		}
+
+		last, err := katrain.LastMove(ctx)
+		x, y, moveNumber := last.X, last.Y, last.Number
+		fmt.Printf("[%s] ✅ 获取 KaTrain 最后一手: X:%d Y:%d (手数: %d)\n",
+			time.Now().Format("15:04:05"),
+			x,
+			y,
+			moveNumber,
+		)
+		if errors.Is(err, target.ErrUnsupported) {
+			fmt.Printf("[%s] ℹ️  KaTrain 不支持读取最后一手，停止 KaTrain → 手机 同步: %v\n", time.Now().Format("15:04:05"), err)
+			return
+		}
+		if err != nil {
+			fmt.Printf("[%s] ❌ 获取 KaTrain 最后一手失败: %v\n", time.Now().Format("15:04:05"), err)
+			s.reportError("KaTrain 读取", err)
+			continue
+		}
+		s.reportOK("KaTrain 读取")
+		s.katrainMove.Store(int64(moveNumber))
+		s.checkDivergence(moveNumber)
+
+		if moveNumber == 0 {
+			continue
+		}
+
+		if _, isNewFromKatrain := s.state.ObserveKatrain(moveNumber, x, y); isNewFromKatrain {
+			if !s.cfg.KatrainToPhoneColors.Allows(last.Color) {
+				continue
+			}
+			if s.cfg.ApproveMoves {
+				s.suggest(last)
+				continue
+			}
+			s.playOnPhone(ctx, last)
+		}
+	}
+}
+
+// playOnPhone 在手机上点出 KaTrain 的一手，成功后记入棋谱并更新看板
+func (s *Session) playOnPhone(ctx context.Context, m target.Move) error {
+	if err := s.tapOnPhone(ctx, m.X, m.Y); err != nil {
+		var rangeErr *TapRangeError
+		if errors.As(err, &rangeErr) {
+			fmt.Printf("[%s] ❌ 拒绝点击棋盘外的位置，请检查棋盘参数: %v\n", time.Now().Format("15:04:05"), err)
+		} else {
+			fmt.Printf("[%s] ❌ 手机点击失败: %v\n", time.Now().Format("15:04:05"), err)
+		}
+		s.reportError("手机点击", err)
+		return err
+	}
+
+	s.reportOK("手机点击")
+	s.recordMove(ctx, m.Color, m.X, m.Y)
+	s.fireMove(hooks.MoveSynced, hooks.KatrainToPhone, m.Number, m.Color, m.X, m.Y)
+	s.dash.Update(func(st *dashboard.Status) {
+		st.KatrainMove = m.Number
+		st.KatrainCoord = coords.Format(m.X, m.Y, coords.GTP)
+	})
+	return nil
+}
+
+// suggest 需要人工确认时，把 KaTrain 的一手记为待确认的建议，新的建议覆盖旧的
+func (s *Session) suggest(m target.Move) {
+	s.suggestion.Store(&m)
+	coord := coords.Format(m.X, m.Y, coords.GTP)
+	s.dash.Update(func(st *dashboard.Status) { st.Suggestion = m.Color + " " + coord })
+	fmt.Printf("[%s] 💡 KaTrain 建议第 %d 手 %s %s，输入 a 回车在手机上落子，r 回车放弃\n",
+		time.Now().Format("15:04:05"),
+		m.Number,
+		mapColorToChinese(m.Color),
+		coord,
+	)
+}
+
+func mapColorToChinese(color string) string {
+	if color == "B" {
+		return "黑棋"
+	}
+	return "白棋"
+}
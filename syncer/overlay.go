@@ -0,0 +1,80 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"time"
+
+	"goboardsync/overlay"
+)
+
+// overlayRetry 没有取到 KaTrain 胜率时（KaTrain 往往还没分析完新局面）重试的间隔
+const overlayRetry = 2 * time.Second
+
+// overlayChanged 通知 runOverlay 局面已变化，不阻塞
+func (s *Session) overlayChanged() {
+	select {
+	case s.overlayDirty <- struct{}{}:
+	default:
+	}
+}
+
+// runOverlay 每次局面变化后更新直播叠加画面（看板 /overlay/ 与 OverlayFile），直到 ctx 取消。
+// KaTrain 能提供分析时附上当前局面的黑方胜率，还没分析出来时稍后重试
+func (s *Session) runOverlay(ctx context.Context) {
+	retry := time.NewTicker(overlayRetry)
+	defer retry.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.overlayDirty:
+		case <-retry.C:
+			f := s.overlayFrame.Load()
+			if _, canAnalyze := s.analyzer(); !canAnalyze || f == nil || f.Move == 0 || f.Winrate != nil {
+				continue
+			}
+		}
+
+		f := s.currentFrame(ctx)
+		s.overlayFrame.Store(&f)
+		if s.cfg.OverlayFile != "" {
+			if err := overlay.WritePNG(s.cfg.OverlayFile, f, s.cfg.OverlaySize); err != nil {
+				fmt.Printf("[%s] ⚠️  写入直播叠加图失败: %v\n", time.Now().Format("15:04:05"), err)
+			}
+		}
+	}
+}
+
+// boardFrame 按已同步的棋步生成叠加画面，不含胜率
+func (s *Session) boardFrame() overlay.Frame {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f := overlay.Frame{Board: s.game.Board(), Move: s.moveNumber()}
+	if last := s.record.LastMove(); last != nil {
+		f.Last = &image.Point{X: last.X, Y: last.Y}
+	}
+	return f
+}
+
+// currentFrame 在 boardFrame 的基础上附上 KaTrain 分析的胜率
+func (s *Session) currentFrame(ctx context.Context) overlay.Frame {
+	f := s.boardFrame()
+	if analyzer, ok := s.analyzer(); ok && f.Move > 0 {
+		if a, err := analyzer.Analysis(ctx, f.Move); err == nil {
+			f.Winrate = &a.Winrate
+		}
+	}
+	return f
+}
+
+// Frame 返回最近一次更新的叠加画面（局面、最后一手与胜率），还没有画面时为空棋盘。
+// 看板的 /overlay/ 与终端界面都读取它
+func (s *Session) Frame() overlay.Frame {
+	if f := s.overlayFrame.Load(); f != nil {
+		return *f
+	}
+	return overlay.Frame{}
+}
@@ -0,0 +1,76 @@
+package syncer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"goboardsync/board"
+	"goboardsync/dashboard"
+	"goboardsync/joseki"
+)
+
+// updatePhase 按已同步的手数与盘面重新判断对局阶段，阶段变化时记录日志并更新看板。
+// 调用时不能持有 s.mu
+func (s *Session) updatePhase() {
+	s.mu.RLock()
+	b := s.game.Board()
+	phase := board.ClassifyPhase(s.moveNumber(), &b)
+	s.mu.RUnlock()
+
+	if board.Phase(s.phase.Swap(int32(phase))) == phase {
+		return
+	}
+	if s.cfg.AdaptToPhase {
+		fmt.Printf("[%s] 🧭 对局进入%s\n", time.Now().Format("15:04:05"), phase)
+	}
+	s.dash.Update(func(st *dashboard.Status) { st.Phase = phase.String() })
+}
+
+// settleFrames 返回当前阶段要等待的 SettleFrames。开启 AdaptToPhase 时布局阶段减半，
+// 官子阶段盘面拥挤、误识别的风险最高，至少多等一帧且不少于 2 帧
+func (s *Session) settleFrames() int {
+	frames := s.cfg.SettleFrames
+	if !s.cfg.AdaptToPhase {
+		return frames
+	}
+	switch board.Phase(s.phase.Load()) {
+	case board.Opening:
+		return frames / 2
+	case board.Endgame:
+		return max(frames+1, 2)
+	}
+	return frames
+}
+
+// updateOpening 布局阶段在定式书中查找当前局面，名称变化时记录日志并更新看板，布局阶段之后清空。
+// 调用时不能持有 s.mu
+func (s *Session) updateOpening() {
+	if s.book == nil {
+		return
+	}
+	var matches []joseki.Match
+	if board.Phase(s.phase.Load()) == board.Opening {
+		s.mu.RLock()
+		b := s.game.Board()
+		s.mu.RUnlock()
+		matches = s.book.Lookup(&b)
+	}
+
+	openings := make([]dashboard.Opening, 0, len(matches))
+	var names []string
+	for _, m := range matches {
+		o := dashboard.Opening{Corner: m.Corner, Name: m.Name}
+		for _, next := range m.Next {
+			o.Next = append(o.Next, next.String())
+		}
+		openings = append(openings, o)
+		names = append(names, strings.TrimSpace(m.Corner+" "+m.Name))
+	}
+	s.dash.Update(func(st *dashboard.Status) { st.Opening = openings })
+
+	summary := strings.Join(names, "，")
+	if prev := s.opening.Swap(&summary); summary != "" && (prev == nil || *prev != summary) {
+		fmt.Printf("[%s] 📖 %s\n", time.Now().Format("15:04:05"), summary)
+	}
+}
@@ -0,0 +1,300 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"time"
+
+	"goboardsync/capture"
+	"goboardsync/coords"
+	"goboardsync/gtp"
+	"goboardsync/session"
+	"goboardsync/sgf"
+	"goboardsync/vision"
+
+	"gocv.io/x/gocv"
+)
+
+// phoneToBoard 把识别结果的手机坐标换算为标准方向的 KaTrain 坐标
+func (s *Session) phoneToBoard(x, y int) (int, int) {
+	return s.orientation.FromScreen(coords.FromPhone(x, y))
+}
+
+// boardToPhone 是 phoneToBoard 的逆变换
+func (s *Session) boardToPhone(x, y int) (int, int) {
+	return coords.ToPhone(s.orientation.ToScreen(x, y))
+}
+
+// gridToScreen 把 KaTrain 坐标换算为手机屏幕坐标，棋盘旋转显示时先换算到屏幕上的位置
+func (s *Session) gridToScreen(x, y int) (int, int) {
+	x, y = s.orientation.ToScreen(x, y)
+
+	// x: KaTrain 的 X 坐标 (0-18)，0代表A线，18代表S线
+	// y: KaTrain 的 Y 坐标 (0-18)，0代表底部(19线)，18代表顶部(1线)
+
+	// A线 (第1根纵线) 与 1线 (第1根横线) 的中心像素、棋盘格子的间距，可在参数文件中调整
+	g := s.geometry()
+	startX, startY, gap := g.BoardStartX, g.BoardStartY, g.BoardGap
+
+	// 计算 X 轴：从左向右增加
+	// 公式：起始点 + 索引 * 间距
+	screenX := startX + float64(x)*gap
+
+	// 计算 Y 轴：KaTrain 的 Y=0 是最下面，而屏幕坐标 Y 是从上往下算的
+	// 所以需要翻转：屏幕Y = 起始点 + (18 - KaTrainY) * 间距
+	screenY := startY + float64(18-y)*gap
+
+	return int(screenX), int(screenY)
+}
+
+// TapRangeError 落子点不在棋盘范围内（KaTrain 坐标越界或棋盘参数有误），tapOnPhone 拒绝点击
+type TapRangeError struct {
+	// X、Y 为 KaTrain 坐标，Point 为换算出的屏幕坐标，Board 为当前棋盘参数下的棋盘范围
+	X, Y  int
+	Point image.Point
+	Board image.Rectangle
+}
+
+func (e *TapRangeError) Error() string {
+	return fmt.Sprintf("落子点 (%d, %d) 的屏幕坐标 %v 不在棋盘范围 %v 内", e.X, e.Y, e.Point, e.Board)
+}
+
+// checkTap 检查落子点在棋盘范围内，否则返回 *TapRangeError
+func (s *Session) checkTap(gridX, gridY, screenX, screenY int) error {
+	board := s.geometry().Board()
+	pt := image.Pt(screenX, screenY)
+	if gridX < 0 || gridX > 18 || gridY < 0 || gridY > 18 || !pt.In(board) {
+		return &TapRangeError{X: gridX, Y: gridY, Point: pt, Board: board}
+	}
+	return nil
+}
+
+func (s *Session) tapOnPhone(ctx context.Context, gridX, gridY int) error {
+	if s.cfg.Spectator {
+		return fmt.Errorf("观战模式下不点击手机")
+	}
+	if s.cfg.CoordinateFlow != "" {
+		return s.enterCoordinate(ctx, gridX, gridY)
+	}
+
+	// 1. 计算棋盘落子点的屏幕坐标，不在棋盘范围内时不点击
+	screenX, screenY := s.gridToScreen(gridX, gridY)
+	if err := s.checkTap(gridX, gridY, screenX, screenY); err != nil {
+		return err
+	}
+
+	// 2. 执行第一次点击：移动落子指示标，等 App 显示出来（或固定等待 TapDelay）
+	if err := s.placeStone(ctx, screenX, screenY); err != nil {
+		return err
+	}
+
+	// 4. 执行第二次点击：点击“确认”按钮 (默认坐标 600, 2150，横屏时另有配置；配置了按钮模板时以截图中找到的位置为准)
+	confirmX, confirmY := s.confirmButton(ctx)
+	if err := s.phone.Tap(ctx, confirmX, confirmY); err != nil {
+		return fmt.Errorf("点击确认按钮失败: %v", err)
+	}
+
+	fmt.Printf("[%s] ✅ 落子成功！%s 已点击“确认”按钮 (屏幕坐标: %d, %d)\n",
+		time.Now().Format("15:04:05"),
+		coords.Format(gridX, gridY, coords.GTP),
+		confirmX,
+		confirmY,
+	)
+
+	return nil
+}
+
+// indicatorPollInterval 等待落子指示标时两次截图之间的最短间隔
+const indicatorPollInterval = 50 * time.Millisecond
+
+// indicatorMinFrames 等待落子指示标时至少比较的截图数。adb screencap 一帧可能要近一秒，
+// 还要与识别循环的截图排队，只按 ConfirmTimeout 计时时可能只截到一张点击前后、App 尚未重绘的画面就判为超时
+const indicatorMinFrames = 3
+
+// placeStone 点击落子点并等待 App 显示落子指示标。ConfirmTimeout 大于 0 时先截图留底，点击后反复截图比较落子点周围，
+// 出现变化即返回；超时仍未出现时重新点击，重试 TapRetries 次后返回错误，不点确认。
+// 未开启或无法截图比较时（如截图失败、落子点超出截图）退回到固定等待 TapDelay
+func (s *Session) placeStone(ctx context.Context, x, y int) error {
+	t := s.tuned.Load()
+	region := vision.IndicatorRegion(image.Pt(x, y), s.geometry().BoardGap)
+
+	before := gocv.NewMat()
+	if t.ConfirmTimeout > 0 {
+		if img, err := s.source.Grab(ctx); err == nil {
+			before.Close()
+			before = img
+		}
+	}
+	defer before.Close()
+
+	if _, err := vision.RegionDiff(before, before, region); err != nil {
+		if err := s.phone.Tap(ctx, x, y); err != nil {
+			return fmt.Errorf("移动指示标失败: %v", err)
+		}
+		time.Sleep(t.TapDelay)
+		return nil
+	}
+
+	for attempt := 0; attempt <= t.TapRetries; attempt++ {
+		if attempt > 0 {
+			fmt.Printf("[%s] ⚠️  %v 内未看到落子指示标，重新点击 (%d, %d)\n", time.Now().Format("15:04:05"), t.ConfirmTimeout, x, y)
+		}
+		if err := s.phone.Tap(ctx, x, y); err != nil {
+			return fmt.Errorf("移动指示标失败: %v", err)
+		}
+		if s.waitIndicator(ctx, before, region, t.ConfirmTimeout) {
+			return nil
+		}
+	}
+	return fmt.Errorf("点击 (%d, %d) 后未看到落子指示标，已重试 %d 次，未点击确认", x, y, t.TapRetries)
+}
+
+// waitIndicator 反复截图，直到 region 内与 before 相比出现明显变化（App 显示了落子指示标）或超时。
+// 截图较慢时超时后仍会截满 indicatorMinFrames 张再放弃
+func (s *Session) waitIndicator(ctx context.Context, before gocv.Mat, region image.Rectangle, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for n := 0; (n < indicatorMinFrames || time.Now().Before(deadline)) && ctx.Err() == nil; n++ {
+		start := time.Now()
+		if img, err := s.source.Grab(ctx); err == nil {
+			diff, err := vision.RegionDiff(before, img, region)
+			img.Close()
+			if err == nil && diff >= vision.IndicatorMinDiff {
+				return true
+			}
+		}
+		if wait := indicatorPollInterval - time.Since(start); wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+	return false
+}
+
+// Config.TapMethod 的取值
+const (
+	TapInput     = "input"
+	TapSendevent = "sendevent"
+)
+
+// setupTouchDevice 查找 sendevent 使用的触摸屏，之后的点击都直接写入触摸事件；找不到时退回 input tap
+func (s *Session) setupTouchDevice(ctx context.Context) {
+	dev, err := s.phone.FindTouchDevice(ctx, s.cfg.TouchDevice)
+	if err != nil {
+		fmt.Printf("⚠️  %v，使用 input tap 点击\n", err)
+		return
+	}
+	s.phone.Touch = dev
+	fmt.Printf("👆 使用 sendevent 点击: %s（%s，触摸范围 %dx%d，屏幕 %dx%d，方向 %d）\n",
+		dev.Path, dev.Name, dev.MaxX-dev.MinX+1, dev.MaxY-dev.MinY+1, dev.Width, dev.Height, dev.Rotation*90)
+}
+
+// confirmButton 返回“确认”按钮的屏幕坐标。配置了按钮模板时截图查找，
+// 找不到时退回到参数中的坐标，避免 App 布局变化后静默点错位置
+func (s *Session) confirmButton(ctx context.Context) (int, int) {
+	g := s.geometry()
+	if s.detector.ConfirmButton == nil {
+		return g.ConfirmX, g.ConfirmY
+	}
+
+	img, err := s.source.Grab(ctx)
+	if err != nil {
+		fmt.Printf("[%s] ⚠️  查找确认按钮时截图失败，使用配置的坐标: %v\n", time.Now().Format("15:04:05"), err)
+		return g.ConfirmX, g.ConfirmY
+	}
+	defer img.Close()
+
+	pt, err := s.detector.FindConfirmButton(img)
+	if err != nil {
+		fmt.Printf("[%s] ⚠️  %v，使用配置的坐标 (%d, %d)\n", time.Now().Format("15:04:05"), err, g.ConfirmX, g.ConfirmY)
+		return g.ConfirmX, g.ConfirmY
+	}
+	return pt.X, pt.Y
+}
+
+// RunGTP 作为 GTP 引擎运行，直到 in 读完或 ctx 取消；返回前保存棋谱并推送对局结束通知。
+// out 只写协议响应，日志仍写到标准输出，调用方需自行把两者分开
+func (s *Session) RunGTP(ctx context.Context, in io.Reader, out io.Writer) error {
+	fmt.Printf("[%s] 🔌 GTP 引擎模式已启动\n", time.Now().Format("15:04:05"))
+	err := gtp.NewEngine(phoneEngine{s}).Run(ctx, in, out)
+	s.EndGame(context.WithoutCancel(ctx))
+	if err != nil {
+		return fmt.Errorf("GTP 输入读取失败: %v", err)
+	}
+	return nil
+}
+
+// phoneEngine 把手机作为 GTP 引擎的后端：play 在手机上点击，genmove 等待手机上识别出的下一手
+type phoneEngine struct {
+	s *Session
+}
+
+func (e phoneEngine) Play(ctx context.Context, x, y int, color string) error {
+	if err := e.s.tapOnPhone(ctx, x, y); err != nil {
+		return err
+	}
+
+	e.s.recordMove(ctx, color, x, y)
+
+	// 自己点出的这手随后会被识别到，记为已处理，避免作为对手的棋步返回。连同手数一起记下，
+	// 对手下一手的手数跳变检查与手数不一致提醒照常生效
+	e.s.mu.RLock()
+	move := e.s.moveNumber()
+	e.s.mu.RUnlock()
+	phoneX, phoneY := e.s.boardToPhone(x, y)
+	e.s.state.SetPhone(session.Last{Move: move, X: phoneX, Y: phoneY})
+	return nil
+}
+
+// NextMove 等待手机上识别出 color 方的新一手，直到 ctx 取消。颜色不符的识别结果（自己刚点出的一手或颜色误读）
+// 在认领之前跳过，下一帧重新识别，不会把这一手记为已处理而漏掉
+func (e phoneEngine) NextMove(ctx context.Context, color string) (int, int, bool, error) {
+	s := e.s
+	interval := s.captureInterval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var settle settleState
+	defer settle.close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, 0, false, fmt.Errorf("停止等待: %w", ctx.Err())
+		case <-ticker.C:
+		}
+		retune(ticker, &interval, s.captureInterval())
+		img, stamp, err := capture.GrabStamped(ctx, s.source)
+		if err != nil {
+			continue
+		}
+		result, err := s.recognize(ctx, img)
+		settled := err == nil && result != nil && result.X != 0 && s.settled(&settle, img, result)
+		img.Close()
+		if !settled || result.Color != color {
+			continue
+		}
+
+		x, y := s.phoneToBoard(result.X, result.Y)
+		move := session.Move{Number: result.Move, X: result.X, Y: result.Y, Color: result.Color, Position: s.positionAfter(x, y, result.Color), Stamp: stamp}
+		if _, verdict := s.state.ObservePhone(move); verdict != session.New {
+			continue
+		}
+
+		s.recordMove(ctx, color, x, y)
+		return x, y, false, nil
+	}
+}
+
+// Clear 手机上的对局无法由程序重开，只清空本地棋谱
+func (e phoneEngine) Clear() error {
+	e.s.mu.Lock()
+	e.s.record = sgf.NewGame()
+	e.s.setupMoves = 0
+	e.s.timer = moveTimer{}
+	e.s.game.Reset()
+	e.s.overlayChanged()
+	e.s.mu.Unlock()
+	e.s.updatePhase()
+	e.s.updateOpening()
+	return nil
+}
@@ -0,0 +1,369 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"time"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+	"goboardsync/dashboard"
+	"goboardsync/hooks"
+	"goboardsync/session"
+	"goboardsync/stats"
+	"goboardsync/vision"
+
+	"gocv.io/x/gocv"
+)
+
+func (s *Session) recognizeWithVision(ctx context.Context, img gocv.Mat) (*vision.Result, error) {
+	s.landscape.Store(img.Cols() > img.Rows())
+
+	if s.cfg.EnableClockOCR {
+		s.readClocks(img)
+	}
+
+	moveNumber, err := s.fetchMoveNumber(ctx, img)
+	// fmt.Printf("[%s] OCR识别结果: moveNumber=%d, err=%v\n", time.Now().Format("15:04:05"), moveNumber, err)
+
+	if err != nil || moveNumber == 0 {
+		fmt.Printf("[%s] ⚠️  OCR识别失败或返回0，使用默认策略\n", time.Now().Format("15:04:05"))
+	}
+
+	// 复盘期间与摄像头局面未稳定时一样返回 nil，表示没有可同步的新手
+	if s.cfg.DetectReview && s.reviewing(img, moveNumber) {
+		return nil, nil
+	}
+
+	result, err := s.detector.DetectLastMoveCoord(img, moveNumber)
+	defer s.saveDebug(img, &result)
+	defer s.recordStats(img, &result)
+	defer s.drawOverlay(img, &result)
+	if err != nil {
+		return &result, nil
+	}
+
+	if result.X == 0 && s.cfg.EnableMoveListFallback && (s.cfg.CaptureSource == "adb" || s.cfg.CaptureSource == "remote") {
+		fallback, err := s.moveListFallback(ctx, img, moveNumber)
+		if err != nil {
+			fmt.Printf("[%s] ⚠️  棋谱面板识别失败: %v\n", time.Now().Format("15:04:05"), err)
+			return &result, nil
+		}
+		result = fallback
+	}
+
+	if s.spectated != nil {
+		s.catchUp(ctx, img, &result)
+	}
+	if s.cfg.MonitorDrift {
+		s.checkDrift(img)
+	}
+	if s.cfg.EstimateScore && result.X != 0 && s.needsScore(result) {
+		if b, err := s.detector.ReadScreenBoard(img); err == nil {
+			s.estimateScore(b)
+		}
+	}
+
+	s.printResult(&result)
+	return &result, nil
+}
+
+// saveDebug 按 DebugLevel 保存本帧截图与识别详情，识别出坐标的帧视为成功
+func (s *Session) saveDebug(img gocv.Mat, result *vision.Result) {
+	frame := int(s.debugFrames.Add(1))
+	ok := result.X != 0
+	if !s.debug.Wants(ok) {
+		return
+	}
+
+	buf, err := gocv.IMEncode(".jpg", img)
+	if err != nil {
+		return
+	}
+	defer buf.Close()
+
+	files := map[string][]byte{"frame.jpg": buf.GetBytes()}
+	if err := s.debug.Save(frame, ok, files, result.Debug); err != nil {
+		fmt.Printf("[%s] ⚠️  %v\n", time.Now().Format("15:04:05"), err)
+	}
+}
+
+// recordStats 开启 StatsFile 时按识别详情中的皮肤与光线累计本帧是否识别成功，与 saveDebug 一样识别出坐标的帧视为成功
+func (s *Session) recordStats(img gocv.Mat, result *vision.Result) {
+	if s.stats == nil {
+		return
+	}
+	key := stats.Key{Skin: "-", Resolution: fmt.Sprintf("%dx%d", img.Cols(), img.Rows()), Lighting: "-"}
+	if result.Debug.Skin != "" {
+		key.Skin = result.Debug.Skin
+	}
+	if result.Debug.Lighting != "" {
+		key.Lighting = result.Debug.Lighting
+	}
+	if err := s.stats.Add(key, result.X != 0); err != nil {
+		fmt.Printf("[%s] ⚠️  %v\n", time.Now().Format("15:04:05"), err)
+	}
+}
+
+// drawOverlay 开启实时预览或录像时画出本帧的识别叠加图，交给预览窗口并写入录像
+func (s *Session) drawOverlay(img gocv.Mat, result *vision.Result) {
+	if s.live == nil && s.video == nil {
+		return
+	}
+	overlay, err := s.detector.DrawOverlay(img, *result)
+	defer overlay.Close()
+	if err != nil {
+		return
+	}
+	s.live.show(overlay)
+	s.video.write(overlay)
+}
+
+// catchUp 观战模式下比较整盘局面，把两次截图之间漏掉的棋步补同步到 KaTrain。
+// 观战不会点击手机，误判的代价只是 KaTrain 上多一颗子，所以每次稳定的局面变化都补；
+// 本帧角标识别出的一手 last 留给正常流程同步。漏掉的多手之间无法确定先后，按交叉点顺序补
+func (s *Session) catchUp(ctx context.Context, img gocv.Mat, last *vision.Result) {
+	b, err := s.detector.ReadScreenBoard(img)
+	if err != nil {
+		return
+	}
+
+	lastX, lastY := -1, -1
+	if last.X != 0 {
+		lastX, lastY = s.phoneToBoard(last.X, last.Y)
+	}
+
+	for _, c := range s.spectated.ObserveAll(b) {
+		x, y := s.orientation.FromScreen(c.X, c.Y)
+		if x == lastX && y == lastY || !s.cfg.PhoneToKatrainColors.Allows(c.To.String()) {
+			continue
+		}
+		if s.localConflict(x, y) != "" {
+			continue
+		}
+		if hasStone, err := s.target.HasStone(ctx, x, y); err != nil || hasStone {
+			continue
+		}
+
+		color := c.To.String()
+		s.fireMove(hooks.MoveDetected, "", 0, color, x, y)
+		if err := s.target.Play(ctx, x, y, color); err != nil {
+			fmt.Printf("[%s] ❌ 补同步失败: %v\n", time.Now().Format("15:04:05"), err)
+			continue
+		}
+		s.recordMove(ctx, color, x, y)
+		s.fireMove(hooks.MoveSynced, hooks.PhoneToKatrain, 0, color, x, y)
+		fmt.Printf("[%s] 🧩 补同步漏掉的一手: %s %s\n",
+			time.Now().Format("15:04:05"),
+			mapColorToChinese(color),
+			coords.Format(x, y, coords.GTP),
+		)
+	}
+}
+
+// reviewing 判断手机是否正在显示复盘或变化图（此时盘面不是实战局面，不能同步），状态变化时打印并更新看板。
+// 只在手数不足以判断时（未落后于实战进度）才识别盘面数棋子
+func (s *Session) reviewing(img gocv.Mat, moveNumber int) bool {
+	stones := -1
+	if moveNumber > 0 && moveNumber >= s.state.LiveMove() {
+		if b, err := s.detector.ReadScreenBoard(img); err == nil {
+			stones = b.Count(board.Black) + b.Count(board.White)
+		}
+	}
+
+	reviewing, changed := s.state.CheckReview(moveNumber, stones, s.cfg.Handicap)
+	if changed {
+		if reviewing {
+			if stones >= 0 {
+				fmt.Printf("[%s] 🔍 手机进入复盘/变化图（第 %d 手，%d 子），暂停同步\n", time.Now().Format("15:04:05"), moveNumber, stones)
+			} else {
+				fmt.Printf("[%s] 🔍 手机进入复盘/变化图（第 %d 手），暂停同步\n", time.Now().Format("15:04:05"), moveNumber)
+			}
+		} else {
+			fmt.Printf("[%s] ▶️  手机回到实战局面（第 %d 手），继续同步\n", time.Now().Format("15:04:05"), moveNumber)
+		}
+		s.dash.Update(func(st *dashboard.Status) { st.Reviewing = reviewing })
+	}
+	return reviewing
+}
+
+// moveListFallback 角标识别失败时（动画、广告遮挡等），通过 OCR 读取棋谱面板确定最后一手。
+// 面板需要点击打开时，打开后重新截图识别，结束后关闭面板
+func (s *Session) moveListFallback(ctx context.Context, img gocv.Mat, moveNumber int) (vision.Result, error) {
+	resKey := fmt.Sprintf("%dx%d", img.Cols(), img.Rows())
+	panel, ok := vision.FixedMoveListPanels[resKey]
+	if !ok {
+		return vision.Result{}, fmt.Errorf("未配置棋谱面板: %s", resKey)
+	}
+
+	src := img
+	if panel.OpenTap != (image.Point{}) {
+		if s.cfg.Spectator {
+			return vision.Result{}, fmt.Errorf("观战模式下不点击手机打开棋谱面板")
+		}
+		if err := s.phone.Tap(ctx, panel.OpenTap.X, panel.OpenTap.Y); err != nil {
+			return vision.Result{}, fmt.Errorf("打开棋谱面板失败: %v", err)
+		}
+		if panel.CloseTap != (image.Point{}) {
+			defer s.phone.Tap(context.WithoutCancel(ctx), panel.CloseTap.X, panel.CloseTap.Y)
+		}
+
+		time.Sleep(s.cfg.MoveListPanelDelay)
+
+		panelImg, err := s.source.Grab(ctx)
+		if err != nil {
+			return vision.Result{}, fmt.Errorf("无法读取棋谱面板截图: %v", err)
+		}
+		defer panelImg.Close()
+		src = panelImg
+	}
+
+	x, y, move, err := s.detector.FetchLastMoveFromMoveList(src, panel.Region)
+	if err != nil {
+		return vision.Result{}, err
+	}
+
+	if move == 0 {
+		move = moveNumber
+	}
+	if move == 0 {
+		return vision.Result{}, fmt.Errorf("无法确定手数与颜色")
+	}
+
+	color := "B"
+	if move%2 == 0 {
+		color = "W"
+	}
+
+	return vision.Result{
+		Move:       move,
+		Color:      color,
+		X:          x,
+		Y:          y,
+		Confidence: 0.5,
+		Debug:      vision.DebugInfo{Source: "move_list"},
+	}, nil
+}
+
+// readClocks 识别双方计时，更新看板，并在首次识别时记录棋谱的 TM/OT
+func (s *Session) readClocks(img gocv.Mat) {
+	regions, ok := vision.FixedClockRegions[fmt.Sprintf("%dx%d", img.Cols(), img.Rows())]
+	if !ok {
+		return
+	}
+
+	for color, region := range map[string]image.Rectangle{"B": regions.Black, "W": regions.White} {
+		clock, err := s.detector.FetchClockFromOCR(img, region)
+		if err != nil {
+			continue
+		}
+
+		s.mu.Lock()
+		s.clocks[color] = clock
+		if len(s.record.Nodes) == 0 && !clock.ByoYomi && s.record.Root("TM") == nil {
+			s.record.SetRoot("TM", fmt.Sprintf("%d", int(clock.Remaining.Seconds())))
+		}
+		if clock.ByoYomi && clock.Periods > 0 && s.record.Root("OT") == nil {
+			s.record.SetRoot("OT", fmt.Sprintf("%d 次读秒", clock.Periods))
+		}
+		s.mu.Unlock()
+
+		dashClock := &dashboard.Clock{
+			RemainingSeconds: int(clock.Remaining.Seconds()),
+			Periods:          clock.Periods,
+			ByoYomi:          clock.ByoYomi,
+		}
+		s.dash.Update(func(st *dashboard.Status) {
+			if color == "B" {
+				st.BlackClock = dashClock
+			} else {
+				st.WhiteClock = dashClock
+			}
+		})
+	}
+}
+
+// recognizeFromCamera 识别实体棋盘的整个局面，与上一个稳定局面比较得出新的一手。
+// 局面尚未稳定或没有新手时返回 nil
+func (s *Session) recognizeFromCamera(_ context.Context, img gocv.Mat) (*vision.Result, error) {
+	b, err := s.detector.ReadPhysicalBoard(img)
+	if err != nil {
+		return nil, err
+	}
+
+	move, ok := s.tracker.Observe(b)
+	if !ok {
+		return nil, nil
+	}
+	if s.cfg.EstimateScore {
+		s.estimateScore(b)
+	}
+
+	// 摄像头看到的是旋转后的棋盘，与手机截图一样按屏幕位置记录，换算由 phoneToBoard 负责
+	x, y := coords.ToPhone(move.X, move.Y)
+	result := &vision.Result{
+		Move:       s.tracker.Moves(),
+		Color:      move.To.String(),
+		X:          x,
+		Y:          y,
+		Confidence: 1,
+		Debug:      vision.DebugInfo{Source: "camera"},
+	}
+
+	s.printResult(result)
+	return result, nil
+}
+
+func (s *Session) printResult(r *vision.Result) {
+	colorName := "黑棋"
+	if r.Color == "W" {
+		colorName = "白棋"
+	}
+
+	x, y := s.phoneToBoard(r.X, r.Y)
+	fmt.Printf("[%s] ✅ 第 %d 手 - %s - 坐标: %s\n",
+		time.Now().Format("15:04:05"),
+		r.Move,
+		colorName,
+		coords.Format(x, y, coords.Tencent),
+	)
+
+}
+
+// needsScore 每手只做一次形势判断：与上次判断时的手数、坐标相同时跳过
+func (s *Session) needsScore(r vision.Result) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last := session.Last{Move: r.Move, X: r.X, Y: r.Y}
+	if s.scoredAt == last {
+		return false
+	}
+	s.scoredAt = last
+	return true
+}
+
+// estimateScore 对识别出的局面做形势判断并更新看板
+func (s *Session) estimateScore(b board.Board) {
+	s.setScore(board.Score(&b, s.cfg.Komi))
+}
+
+// checkLabels 读取棋盘边上的坐标标签，与棋盘角点及 BoardRotation 核对，不一致时提示检查配置。
+// 只提示不修改配置：App 没有显示坐标或 OCR 误读时推断也会出错
+func (s *Session) checkLabels(img gocv.Mat) {
+	now := time.Now().Format("15:04:05")
+	check, err := s.detector.CheckBoardLabels(img)
+	switch {
+	case err != nil:
+		fmt.Printf("[%s] ⚠️  无法校验坐标标签: %v\n", now, err)
+	case check.Rotation == -1:
+		fmt.Printf("[%s] ⚠️  坐标标签读不出方向（上方 %d 个，左侧 %d 个），请确认 App 已显示坐标\n", now, len(check.Top), len(check.Left))
+	case check.Rotation != s.orientation.Degrees():
+		fmt.Printf("[%s] ⚠️  坐标标签显示棋盘旋转了 %d°，与 BoardRotation = %d 不一致，请检查配置\n", now, check.Rotation, s.cfg.BoardRotation)
+	case !check.Complete:
+		fmt.Printf("[%s] ⚠️  坐标标签不全（上方 %d 个，左侧 %d 个），棋盘角点可能有偏移\n", now, len(check.Top), len(check.Left))
+	default:
+		fmt.Printf("[%s] ✅ 坐标标签与棋盘角点、方向一致\n", now)
+	}
+}
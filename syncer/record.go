@@ -0,0 +1,303 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+	"goboardsync/dashboard"
+	"goboardsync/hooks"
+	"goboardsync/notify"
+	"goboardsync/scrcpy"
+	"goboardsync/tui"
+	"goboardsync/vision"
+)
+
+// recordMove 把同步成功的一手记入棋谱并转播，连续重复的同一手只记一次
+func (s *Session) recordMove(ctx context.Context, color string, x, y int) {
+	s.mu.Lock()
+	if last := s.record.LastMove(); last != nil && last.Color == color && last.X == x && last.Y == y {
+		s.mu.Unlock()
+		return
+	}
+
+	node := s.record.AddMove(color, x, y)
+	if len(s.record.Nodes) == 1 && s.setupMoves > 0 {
+		node.Set("MN", strconv.Itoa(s.setupMoves+1))
+	}
+	check := blunderCheck{move: s.moveNumber(), color: color, x: x, y: y, record: s.record}
+	s.game.Play(x, y, board.ParseColor(color))
+	spent := s.timer.move(color, time.Now())
+	// 识别到手机计时时以手机为准，否则按 TM 与估算的用时推算
+	if clock, ok := s.clocks[color]; ok {
+		node.SetTimeLeft(clock.Remaining, clock.Periods)
+	} else if remaining, ok := s.timeLeft(color); ok {
+		node.SetTimeLeft(remaining, 0)
+	}
+	node.SetMoveTime(spent)
+	timing := s.timer.status()
+	s.mu.Unlock()
+
+	s.dash.Update(func(st *dashboard.Status) { st.Timing = timing })
+	s.updatePhase()
+	s.updateOpening()
+	s.overlayChanged()
+	if s.cfg.PrintBoard {
+		fmt.Print(tui.Board(s.boardFrame(), false))
+	}
+
+	for _, r := range s.relays {
+		if err := r.Play(ctx, x, y, color); err != nil {
+			fmt.Printf("[%s] ⚠️  %s 转播失败: %v\n", time.Now().Format("15:04:05"), r.Name(), err)
+		}
+	}
+	s.queueBlunderCheck(check)
+}
+
+// fireMove 把一手棋的事件交给扩展，x/y 为 KaTrain 坐标，move 为 0 表示手数未知
+func (s *Session) fireMove(kind hooks.Kind, direction string, move int, color string, x, y int) {
+	s.hooks.Fire(hooks.Event{
+		Kind:      kind,
+		Move:      move,
+		Color:     color,
+		X:         x,
+		Y:         y,
+		Coord:     coords.Format(x, y, coords.GTP),
+		Direction: direction,
+	})
+}
+
+// SaveRecord 保存棋谱，返回文件路径；没有棋步或保存失败时返回空字符串
+func (s *Session) SaveRecord() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.record.Nodes) == 0 {
+		return ""
+	}
+
+	name := fmt.Sprintf("game_%s.sgf", time.Now().Format("20060102_150405"))
+	if table := s.currentTable(); table != "" {
+		name = fmt.Sprintf("game_%s_%s.sgf", time.Now().Format("20060102_150405"), table)
+	}
+	path := filepath.Join(s.cfg.RecordDir, name)
+	if err := s.record.WriteFile(path); err != nil {
+		fmt.Printf("[%s] ❌ 保存棋谱失败: %v\n", time.Now().Format("15:04:05"), err)
+		return ""
+	}
+	fmt.Printf("[%s] 💾 棋谱已保存: %s\n", time.Now().Format("15:04:05"), path)
+	return path
+}
+
+// notifyEvent 在后台推送通知，不阻塞同步
+func (s *Session) notifyEvent(kind notify.Kind, message string) {
+	if s.notifier == nil {
+		return
+	}
+	go s.sendNotification(kind, message)
+}
+
+func (s *Session) sendNotification(kind notify.Kind, message string) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.Notify(notify.Event{Kind: kind, Message: message, Time: time.Now()}); err != nil {
+		fmt.Printf("[%s] ⚠️  %v\n", time.Now().Format("15:04:05"), err)
+	}
+}
+
+// reportError 记录某个环节出错，持续出错超过 NotifyErrorAfter 时推送提醒
+func (s *Session) reportError(key string, err error) {
+	if strings.HasPrefix(key, "KaTrain") {
+		msg := err.Error()
+		s.katrainErr.Store(&msg)
+	}
+	s.dash.Update(func(st *dashboard.Status) {
+		st.LastError = &dashboard.Failure{Stage: key, Message: err.Error(), At: time.Now()}
+	})
+	if alert, elapsed := s.errTracker.Fail(key); alert {
+		s.notifyEvent(notify.ErrorPersist, fmt.Sprintf("%s 已持续出错 %s: %v", key, elapsed.Round(time.Second), err))
+	}
+}
+
+// reportOK 记录 key 对应的操作已成功，与 reportError 配对使用
+func (s *Session) reportOK(key string) {
+	s.errTracker.OK(key)
+	if strings.HasPrefix(key, "KaTrain") {
+		ok := ""
+		s.katrainErr.Store(&ok)
+	}
+}
+
+// checkDivergence 比较 KaTrain 与手机的手数，相差超过 DivergenceMoves 时提醒一次，恢复一致后重新检测
+func (s *Session) checkDivergence(katrainMove int) {
+	if phoneMove, alert := s.state.CheckDivergence(katrainMove, s.cfg.DivergenceMoves); alert {
+		s.notifyEvent(notify.Divergence, fmt.Sprintf("手机与 KaTrain 局面不一致：手机第 %d 手，KaTrain 第 %d 手", phoneMove, katrainMove))
+	}
+}
+
+// EndGame 开启 DeadStoneScoring 时先做数子交接，写入 KaTrain 分析后保存棋谱，并推送对局结束通知。
+// 同步停止后调用时 ctx 应去掉取消（context.WithoutCancel），否则数子与读取分析会立即失败
+func (s *Session) EndGame(ctx context.Context) {
+	if s.cfg.DeadStoneScoring {
+		if err := s.HandoffScoring(ctx); err != nil {
+			fmt.Printf("[%s] ⚠️  数子交接失败: %v\n", time.Now().Format("15:04:05"), err)
+		}
+	}
+	s.annotateRecord(ctx)
+	path := s.SaveRecord()
+	s.saveTables()
+
+	s.mu.RLock()
+	moves := s.moveNumber()
+	result := s.record.Root("RE")
+	score := s.score
+	s.mu.RUnlock()
+
+	message := fmt.Sprintf("对局结束，共 %d 手", moves)
+	if len(result) > 0 {
+		message += "，结果 " + result[0]
+	}
+	if score != nil {
+		fmt.Printf("[%s] 📊 形势判断: %s\n", time.Now().Format("15:04:05"), score)
+		message += "，形势判断 " + score.String()
+	}
+	if path != "" {
+		message += "，棋谱: " + path
+	}
+	s.sendNotification(notify.GameEnded, message)
+	s.hooks.Fire(hooks.Event{Kind: hooks.GameEnded, Move: moves, Record: path, Message: message})
+}
+
+// startScrcpy 以监管方式运行 scrcpy，崩溃或设备重连后自动重启，状态同步到看板。
+// 等到投屏窗口出现（无法检测窗口时为进程持续运行）后返回，超时只打印警告
+func (s *Session) startScrcpy(ctx context.Context) {
+	supervisor := scrcpy.NewSupervisor(s.cfg.WindowTitle, s.cfg.ScrcpyArgs...)
+	supervisor.OnStatus = func(st scrcpy.Status) {
+		if st.Running {
+			fmt.Printf("[%s] 📺 scrcpy 已启动 (pid %d)\n", time.Now().Format("15:04:05"), st.PID)
+		} else if st.LastError != "" {
+			fmt.Printf("[%s] ⚠️  scrcpy: %s\n", time.Now().Format("15:04:05"), st.LastError)
+		}
+		s.dash.Update(func(ds *dashboard.Status) {
+			ds.Scrcpy = &dashboard.Process{Running: st.Running, Restarts: st.Restarts, LastError: st.LastError}
+		})
+	}
+	go supervisor.Run(ctx)
+
+	if err := supervisor.WaitReady(ctx, s.cfg.ScrcpyReadyTimeout); err != nil {
+		fmt.Printf("[%s] ⚠️  %v，继续同步\n", time.Now().Format("15:04:05"), err)
+	}
+}
+
+// annotateRecord 读取 KaTrain 对每一手之后局面的分析，把胜率、目差写成注释，前 AnalysisCandidates 个
+// 推荐点标为 A、B、C…，得到可以直接复盘的棋谱。目标不支持分析或第一手就读取失败时保持原样
+func (s *Session) annotateRecord(ctx context.Context) {
+	analyzer, ok := s.analyzer()
+	if !ok || s.cfg.AnalysisCandidates <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	annotated := 0
+	for i, node := range s.record.Nodes {
+		a, err := analyzer.Analysis(ctx, s.setupMoves+i+1)
+		if err != nil {
+			if annotated == 0 {
+				fmt.Printf("[%s] ⚠️  无法读取 KaTrain 分析，棋谱不加注释: %v\n", time.Now().Format("15:04:05"), err)
+				return
+			}
+			continue
+		}
+
+		var candidates []string
+		for j, c := range a.Candidates {
+			if j >= s.cfg.AnalysisCandidates {
+				break
+			}
+			label := string(rune('A' + j))
+			s.record.AddLabel(node, c.X, c.Y, label)
+			candidates = append(candidates, fmt.Sprintf("%s %s %.1f%% %s",
+				label, coords.Format(c.X, c.Y, coords.GTP), c.Winrate*100, formatLead(c.ScoreLead)))
+		}
+
+		comment := fmt.Sprintf("黑胜率 %.1f%%，%s", a.Winrate*100, formatLead(a.ScoreLead))
+		if len(candidates) > 0 {
+			comment += "\n推荐: " + strings.Join(candidates, "，")
+		}
+		node.AddComment(comment)
+		annotated++
+	}
+	fmt.Printf("[%s] 📝 已为 %d 手写入 KaTrain 分析\n", time.Now().Format("15:04:05"), annotated)
+}
+
+// formatLead 把黑方视角的目差格式化为“黑领先 1.5 目”或“白领先 2.0 目”
+func formatLead(lead float64) string {
+	if lead < 0 {
+		return fmt.Sprintf("白领先 %.1f 目", -lead)
+	}
+	return fmt.Sprintf("黑领先 %.1f 目", lead)
+}
+
+// positionAfter 按本地棋盘模型计算 (x, y) 落下 color 后的局面哈希，该点已有棋子时为当前局面的哈希。
+// 同一手棋识别多少次结果都相同，用于手机方向的去重；颜色未知时返回 0（只按坐标去重）
+func (s *Session) positionAfter(x, y int, color string) uint64 {
+	c := board.ParseColor(color)
+	if c == board.Empty {
+		return 0
+	}
+	b := s.game.Board()
+	if b.At(x, y) == board.Empty {
+		b.Play(x, y, c)
+	}
+	return b.Hash()
+}
+
+// localConflict 返回的原因
+const (
+	conflictLastMove = "与上一手相同"
+	conflictOccupied = "本地棋盘该点已有棋子"
+)
+
+// localConflict 按本地棋盘模型检查识别到的一手：与棋谱最后一手相同或落在已有棋子的点上时返回原因，
+// 不必再向 KaTrain 查询。本地模型只含已同步的棋步，空点是否真的可下仍以 KaTrain 为准
+func (s *Session) localConflict(x, y int) string {
+	s.mu.RLock()
+	last := s.record.LastMove()
+	s.mu.RUnlock()
+	if last != nil && last.X == x && last.Y == y {
+		return conflictLastMove
+	}
+	if b := s.game.Board(); b.At(x, y) != board.Empty {
+		return conflictOccupied
+	}
+	return ""
+}
+
+// pickCandidate 首选与次选得分接近（见 vision.Result.Ambiguous）且首选落在本地棋盘已有棋子的点上时，
+// 改用第一个本地棋盘上空着的候选，返回是否改选。首选就是上一手时不改选：角标停在上一手是常态
+func (s *Session) pickCandidate(result *vision.Result) bool {
+	if !result.Ambiguous() {
+		return false
+	}
+	if x, y := s.phoneToBoard(result.X, result.Y); s.localConflict(x, y) != conflictOccupied {
+		return false
+	}
+	for _, c := range result.Candidates[1:] {
+		if x, y := s.phoneToBoard(c.X, c.Y); s.localConflict(x, y) == "" {
+			result.X, result.Y = c.X, c.Y
+			result.GridIndex = image.Pt(c.X-1, c.Y-1)
+			result.MarkerRect, result.StoneCenter = image.Rectangle{}, image.Point{}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+	"goboardsync/dashboard"
+	"goboardsync/target"
+)
+
+// HandoffScoring 数子交接：截图找出 App 在数子界面标出的死子（只检查已同步的棋子），交给 KaTrain 数子，
+// 按去掉死子的局面更新形势判断，并把死子（DD）与 KaTrain 给出的结果（RE，棋谱还没有结果时）写入棋谱。
+// 开启 DeadStoneScoring 时对局结束自动调用，也可在 App 进入数子界面后从看板或终端触发
+func (s *Session) HandoffScoring(ctx context.Context) error {
+	b := s.game.Board()
+	if b.Count(board.Black)+b.Count(board.White) == 0 {
+		return fmt.Errorf("还没有同步过棋步，无法判断死子")
+	}
+
+	img, err := s.source.Grab(ctx)
+	if err != nil {
+		return fmt.Errorf("数子时截图失败: %v", err)
+	}
+	dead, err := s.detector.ReadDeadStones(img, &b)
+	img.Close()
+	if err != nil {
+		return fmt.Errorf("识别死子失败: %v", err)
+	}
+
+	if len(dead) == 0 {
+		fmt.Printf("[%s] 🪦 没有识别到死子标记\n", time.Now().Format("15:04:05"))
+	} else {
+		fmt.Printf("[%s] 🪦 识别到 %d 颗死子: %s\n", time.Now().Format("15:04:05"), len(dead), formatPoints(dead))
+	}
+	e := board.ScoreDead(&b, dead, s.cfg.Komi)
+	s.setScore(e)
+	fmt.Printf("[%s] 📊 去掉死子后: %s\n", time.Now().Format("15:04:05"), e)
+
+	var result string
+	if m, ok := s.deadStoneMarker(); ok {
+		result, err = m.MarkDead(ctx, dead)
+		if err != nil {
+			fmt.Printf("[%s] ⚠️  %s 数子失败: %v\n", time.Now().Format("15:04:05"), s.target.Name(), err)
+		} else if result != "" {
+			fmt.Printf("[%s] 🏁 %s 数子结果: %s\n", time.Now().Format("15:04:05"), s.target.Name(), result)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(dead) > 0 {
+		points := make([]string, len(dead))
+		for i, p := range dead {
+			points[i] = s.record.Point(p.X, p.Y)
+		}
+		if last := s.record.LastMove(); last != nil {
+			last.Set("DD", points...)
+		} else {
+			s.record.SetRoot("DD", points...)
+		}
+	}
+	if result != "" && len(s.record.Root("RE")) == 0 {
+		s.record.SetRoot("RE", result)
+	}
+	return nil
+}
+
+// deadStoneMarker 目标支持标记死子时返回对应接口
+func (s *Session) deadStoneMarker() (target.DeadStoneMarker, bool) {
+	m, ok := s.target.(target.DeadStoneMarker)
+	return m, ok && s.supports(target.FeatureDeadStones)
+}
+
+// setScore 记下形势判断并更新看板
+func (s *Session) setScore(e board.Estimate) {
+	s.mu.Lock()
+	s.score = &e
+	s.mu.Unlock()
+
+	s.dash.Update(func(st *dashboard.Status) {
+		st.Score = &dashboard.Score{Black: e.Black(), White: e.White(), Komi: e.Komi, Lead: e.Lead()}
+	})
+}
+
+// formatPoints 把 KaTrain 坐标列表格式化为 "D4 Q16"
+func formatPoints(points []image.Point) string {
+	names := make([]string, len(points))
+	for i, p := range points {
+		names[i] = coords.Format(p.X, p.Y, coords.GTP)
+	}
+	return strings.Join(names, " ")
+}
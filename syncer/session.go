@@ -0,0 +1,1039 @@
+// Package syncer 是手机与 KaTrain 双向同步的核心：截图识别手机上的新一手并落到同步目标，
+// 轮询 KaTrain 的新一手并在手机上点击，同时维护棋谱、看板、通知与转播。
+//
+// 其他 Go 程序可以直接嵌入同步引擎：
+//
+//	s, err := syncer.NewSession(ctx, syncer.DefaultConfig())
+//	if err != nil { ... }
+//	defer s.Close()
+//	err = s.Run(ctx)
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"goboardsync/adb"
+	"goboardsync/board"
+	"goboardsync/capture"
+	"goboardsync/companion"
+	"goboardsync/coords"
+	"goboardsync/dashboard"
+	"goboardsync/debugsink"
+	"goboardsync/hooks"
+	"goboardsync/joseki"
+	"goboardsync/katrain"
+	"goboardsync/notify"
+	"goboardsync/ocr"
+	"goboardsync/overlay"
+	"goboardsync/platform"
+	"goboardsync/relay"
+	"goboardsync/remote"
+	"goboardsync/session"
+	"goboardsync/sgf"
+	"goboardsync/stats"
+	"goboardsync/target"
+	"goboardsync/uistate"
+	"goboardsync/vision"
+	"goboardsync/workdir"
+
+	"gocv.io/x/gocv"
+)
+
+// Config 同步会话的配置，各项含义与 README 中的配置说明一致
+type Config struct {
+	WindowTitle string
+	// RecordDir 棋谱保存目录，为空时使用系统默认数据目录
+	RecordDir string
+	TargetW   int
+	TargetH   int
+	// DebugLevel 保存调试截图与识别详情的级别：off、failures 或 all（见 debugsink.ParseLevel）。
+	// 每次运行在 DebugDir 下建一个子目录，启动时只保留最近 DebugMaxRuns 次、总共不超过 DebugMaxMB，为 0 时不限
+	DebugLevel   string
+	DebugDir     string
+	DebugMaxRuns int
+	DebugMaxMB   int
+	// StatsFile 识别统计文件（见 stats），跨多次运行按棋盘皮肤、分辨率与光线累计识别成功与失败的帧数，为空时不统计
+	StatsFile string
+	// SampleDir 每识别到一手新棋时把该帧截图以样本文件名（如 037-J10-B.jpg）保存到此目录下本局的子目录，
+	// 供 bench 与批量识别测试使用；为空时不保存
+	SampleDir string
+	// Tunables 运行中可热更新的参数的初始值
+	Tunables Tunables
+	// ConfigFile KEY=value 格式的参数文件，启动时读取，修改后自动重新加载；为空时不启用
+	ConfigFile string
+
+	EnableClockOCR         bool
+	EnableMoveListFallback bool
+	// OCREndpoint OCR 服务地址，为空时使用 vision 的默认地址；OCRBackend 服务格式（见 ocr.Backends），
+	// 可带请求上限，如 "baidu:30/500"（见 ocr.ParseSpec）；OCRLanguage 识别语言。
+	// MoveNumberPatterns 提取手数的正则，适配其他语言或其他 App 的界面，为空时使用内置规则
+	OCREndpoint        string
+	OCRBackend         string
+	OCRLanguage        string
+	MoveNumberPatterns []string
+	// OCRProfile 界面语言（见 ocr.Profiles，如 ja、ko、en），其手数正则排在 MoveNumberPatterns 之后使用
+	OCRProfile string
+	// OCRFallbacks OCRBackend 失败或达到上限时依次尝试的云端服务，格式同 OCRBackend；
+	// OCRCredentials 云端服务的密钥，只从环境变量读取
+	OCRFallbacks   []string
+	OCRCredentials ocr.Credentials
+	// Spectator 观战模式：只把手机上双方的棋步同步到 KaTrain，从不点击手机，
+	// 并逐帧比较整盘局面，补上角标识别漏掉的棋步
+	Spectator bool
+	// OverlayFile 每手棋后写出的直播叠加图（透明背景 PNG，供 OBS 图像源使用），为空时不写；
+	// OverlaySize 为棋盘边长（像素）。看板的 /overlay/ 总是提供同样内容的网页
+	OverlayFile string
+	OverlaySize int
+	// PrintBoard 每记下一手棋后把当前局面画成文本打印到日志（最后一手加括号），无图形界面或通过 SSH 运行时便于查看
+	PrintBoard bool
+	// Tables 观战模式下轮换观看同一台手机上的多桌对局（如 App 的多桌观战），格式为以 | 分隔的 "名字=切换流程"，
+	// 流程格式见 adb.ParseFlow；每桌停留 TableDwell。各桌的棋谱、用时与整盘局面各自独立，KaTrain 显示当前桌。
+	// 为空时只看当前一桌
+	Tables     string
+	TableDwell time.Duration
+	// PhoneToKatrainColors、KatrainToPhoneColors 两个方向各自同步的颜色。实战中通常只需把引擎替自己
+	// 走的一方点到手机上，如执黑时 KatrainToPhoneColors 设为 "B"
+	PhoneToKatrainColors ColorFilter
+	KatrainToPhoneColors ColorFilter
+	// SetupGame 开始同步前在 KaTrain 开始新对局：对局者名字从手机截图 OCR 读取，
+	// 贴目、让子与规则取下面的配置（手机 App 中无法可靠读取）。KaTrain 不支持时只清空棋盘
+	SetupGame bool
+	Komi      float64
+	Handicap  int
+	Ruleset   string
+	// EstimateScore 识别到新手时按盘面做粗略的形势判断，显示在看板并在对局结束时记录，不依赖 KaTrain
+	EstimateScore bool
+	// DeadStoneScoring 数子交接（见 HandoffScoring）：对局结束时识别 App 标出的死子，交给 KaTrain 数子并写入棋谱，
+	// 看板与终端也可手动触发；DeadMark 死子标记的判断参数，零值时使用 vision.DefaultDeadMark
+	DeadStoneScoring bool
+	DeadMark         vision.DeadMark
+	// AttachMidGame 开始同步时手机上已有棋子（中途启动程序）时，先把整盘局面摆到 KaTrain 再增量同步，见 attach
+	AttachMidGame bool
+	// AnalysisCandidates 对局结束时把 KaTrain 的胜率、目差写入棋谱注释，并标出前几个推荐点；为 0 时不写
+	AnalysisCandidates int
+	// AnalysisVisits、AnalysisTime 开始同步时让 KaTrain 把每一手的分析限制在这么多次访问、这么长时间内，
+	// 快棋落子快时分析不会越积越多；为 0 时沿用 KaTrain 自己的设置
+	AnalysisVisits int
+	AnalysisTime   time.Duration
+	// DeviceCheckInterval 检查手机屏幕与前台应用的间隔，为 0 时不检查。AppPackage 为对弈 App 的包名，
+	// 为空时只检查熄屏与锁屏；WakeDevice 开启时自动唤醒手机并把 App 切回前台
+	DeviceCheckInterval time.Duration
+	AppPackage          string
+	WakeDevice          bool
+	// AppActivity 切回 App 时用 am start 打开的界面，为空时打开主界面。ResumeFlow 为 App 被切走后
+	// 从首页回到对局的操作流程，格式见 adb.ParseFlow，为空时只切回 App
+	AppActivity string
+	ResumeFlow  string
+	// CoordinateFlow 通过 App 的坐标输入框落子的操作流程，格式见 adb.ParseFlow，其中 {coord}、{sgf} 等占位符
+	// 换成要落子的坐标（见 expandCoordinate）。为空时按棋盘的像素位置点击落子
+	CoordinateFlow string
+	// TapMethod 点击方式：TapInput（默认，adb shell input tap）或 TapSendevent（用 sendevent 直接向触摸屏
+	// 写入事件，见 adb.FindTouchDevice）；TouchDevice 为 sendevent 使用的设备节点，为空时自动查找
+	TapMethod   string
+	TouchDevice string
+	// Companion 连接手机上的伴侣 App（无障碍服务，见 companion 包），通过它点击并从界面结构读取手数与对话框，
+	// 连不上时退回 adb 点击与 OCR；CompanionPort 为 adb forward 转发到的本机端口，0 为 companion.DefaultPort
+	Companion     bool
+	CompanionPort int
+	// UIDumpInterval 每隔多久用 uiautomator dump 读取一次 App 的界面状态（前台界面、对话框、手数），0 为不读取。
+	// UIMoveNumberID 为 App 中显示手数的控件的资源 ID，为空时在界面的所有文字中按手数规则查找
+	UIDumpInterval time.Duration
+	UIMoveNumberID string
+	// TrackDevices 通过 adb track-devices 监听手机插拔，断开期间暂停同步，重新连上后重新比较局面再继续
+	TrackDevices bool
+	// 随手机状态检查一起读取电池信息：电量低于 ThrottleBatteryBelow（未充电时）或电池温度高于
+	// ThrottleTemperatureAbove 摄氏度时，截图间隔放慢到 ThrottleInterval，恢复后还原；阈值为 0 时不检查该项
+	ThrottleBatteryBelow     int
+	ThrottleTemperatureAbove float64
+	ThrottleInterval         time.Duration
+	// ApproveMoves KaTrain 的新一手只作为建议显示，人工确认（终端输入 a 或看板 approve）后才在手机上落子
+	ApproveMoves bool
+	// DetectReview 识别手机是否进入了复盘/变化图，期间暂停手机 → KaTrain 同步
+	DetectReview bool
+	// LiveView 打开实时预览窗口，显示校正后的棋盘、角标、选中的交叉点与置信度；无图形界面时忽略
+	LiveView bool
+	// RecordVideo 把每帧的识别叠加图录成 RecordDir 下的 game_时间.mp4，VideoFPS 为播放帧率
+	RecordVideo bool
+	VideoFPS    float64
+	// FuseSignals 融合角标、手数奇偶、局面变化与交叉点分类判断最后一手（见 vision.Detector.Fusion）
+	FuseSignals        bool
+	MoveListPanelDelay time.Duration
+	// DashboardAddr 看板的监听地址，为空时不启动看板。看板上的操作可以暂停同步、在手机上落子，
+	// 监听非本机地址时应同时设置 DashboardAuth
+	DashboardAddr string
+	// DashboardAuth 看板（含 /overlay/）的访问控制；DashboardCertFile、DashboardKeyFile 都设置时以 HTTPS 提供看板
+	DashboardAuth     dashboard.Auth
+	DashboardCertFile string
+	DashboardKeyFile  string
+
+	// CaptureSource 画面来源：adb、screen、camera 或 remote
+	CaptureSource      string
+	CameraDevice       int
+	CameraStableFrames int
+	ScreenRegion       image.Rectangle
+	// ScreenBoardCorners screen 模式下桌面客户端棋盘在截图中的四角（左上、右上、右下、左下，截图像素，
+	// Retina 屏上为逻辑坐标的两倍）。设置后截图保持原尺寸并按这四角识别；为空时截取的是 scrcpy 投屏，
+	// 缩放到 TargetW x TargetH 后沿用手机的棋盘四角
+	ScreenBoardCorners []image.Point
+	// RemoteURL remote 模式下采集端（goboardsync -capture-node）的地址，截图与 adb 命令都经过它；
+	// RemoteToken 为采集端要求的令牌
+	RemoteURL   string
+	RemoteToken string
+
+	// KatrainBackend KaTrain 接入方式：http 或 gui
+	KatrainURL         string
+	KatrainBackend     string
+	KatrainWindowTitle string
+	// KatrainAuth http 方式访问 KaTrain 的认证与自签名 HTTPS 证书
+	KatrainAuth katrain.Auth
+	// KatrainMirrors 另外几个 KaTrain 实例（HTTP API）的地址，同步到 KaTrain 的每一手也发给它们，
+	// 如一个做快速分析、一个做深度分析同一盘棋。只写不读，认证与 KatrainAuth 相同
+	KatrainMirrors []string
+	// TableKatrains 多桌轮换时各桌使用的 KaTrain 实例，格式为以 | 分隔的 "名字=地址"，
+	// 如 "A=http://localhost:8080 | B=http://localhost:8081"；没有配置的桌使用 KatrainURL。
+	// 独占一个实例的桌切换时不再清空重放，KaTrain 一直显示这一桌，分析也不会中断
+	TableKatrains string
+
+	// RelayBackend 转播目标：igs、kgs 或为空
+	RelayBackend  string
+	RelayAddr     string
+	RelayUser     string
+	RelayPassword string
+	KGSRoomID     int
+
+	NotifyErrorAfter time.Duration
+	DivergenceMoves  int
+	// MonitorDrift 每帧额外识别整盘局面，按棋子数加提子数推断手机上的手数并与 KaTrain 的手数比较，
+	// 连续 DriftFrames 帧相差超过 DriftThreshold 时推送提醒并在看板显示横幅，同样连续 DriftFrames 帧恢复后撤下
+	MonitorDrift   bool
+	DriftThreshold int
+	DriftFrames    int
+
+	EnableScrcpy       bool
+	ScrcpyArgs         []string
+	ScrcpyReadyTimeout time.Duration
+
+	HealthTimeout time.Duration
+	// KatrainTimeout、ADBTimeout、OCRTimeout 每次访问 KaTrain、执行 adb 命令（Phone 的 Timeout）、
+	// 请求 OCR 服务的超时，为 0 时使用各包的默认值（见 katrain.DefaultTimeout 等）
+	KatrainTimeout time.Duration
+	ADBTimeout     time.Duration
+	OCRTimeout     time.Duration
+
+	// BoardSkin 棋盘皮肤（classic/dark/green），为空时按棋盘底色自动识别
+	BoardSkin string
+	// MarkerExclusions 找角标时忽略的区域（校正棋盘坐标，格式见 vision.ParseMarkerExclusions），
+	// 遮住压在棋盘上的横幅、聊天气泡等界面元素
+	MarkerExclusions string
+	// SmoothFrames 对最近这么多帧识别出的交叉点做多数表决（见 vision.Detector.SmoothFrames），0 为不表决
+	SmoothFrames int
+	// SettleFrames 新识别到的一手要等落子点周围连续这么多帧没有变化（提子、落子动画结束）才同步，0 为不等待
+	SettleFrames int
+	// BlunderThreshold KaTrain 分析认为一手棋让落子方损失的胜率超过这么多个百分点时报警（日志、推送、
+	// 扩展事件与 BlunderSound），0 为不检查；BlunderColors 为检查的颜色，为空时双方都检查
+	BlunderThreshold float64
+	BlunderColors    ColorFilter
+	// BlunderSound 报警时播放的声音文件（WAV），为空时不播放
+	BlunderSound string
+	// OpeningBook 布局阶段在定式书中查找当前局面，在日志与看板中显示名称与书中的后续下法
+	OpeningBook bool
+	// OpeningBookFile SGF 格式的定式书（见 joseki.Load），为空时使用内置的书
+	OpeningBookFile string
+	// AdaptToPhase 按对局阶段调整：布局阶段截图间隔与 SettleFrames 减半，官子阶段多等一帧再同步
+	AdaptToPhase bool
+	// BoardRotation 手机（或摄像头画面）上的棋盘相对黑方视角顺时针旋转的角度，
+	// 执白时 App 把棋盘倒过来显示应设为 180
+	BoardRotation int
+	// CheckLabels 开始同步时通过 OCR 读取棋盘边上的坐标标签，核对棋盘角点与 BoardRotation（需在 App 中打开坐标显示）
+	CheckLabels bool
+	// Classifier 交叉点分类器，为空时使用亮度规则
+	Classifier vision.StoneClassifier
+	// ConfirmButton “确定/确认”按钮模板，设置后每次确认前在截图中查找按钮，为空时点击 ConfirmX/ConfirmY
+	ConfirmButton *vision.ButtonTemplate
+
+	// 以下为可选的注入点，为 nil 时按上面的配置创建
+	Phone    *adb.Client
+	Source   capture.Source
+	Target   target.SyncTarget
+	Notifier notify.Notifier
+	// Hooks 识别到新棋步、棋步同步完成与对局结束时调用的扩展，与 hooks.Register 注册的全局扩展一起调用
+	Hooks []hooks.Named
+}
+
+// DefaultDashboardAddr 看板的默认监听地址，只允许本机访问
+const DefaultDashboardAddr = "127.0.0.1:8090"
+
+// DefaultConfig 返回默认配置（1200x2670 的腾讯围棋 App，KaTrain 在 localhost:8080）
+func DefaultConfig() Config {
+	return Config{
+		WindowTitle:              "my_phone",
+		TargetW:                  1200,
+		TargetH:                  2670,
+		DebugDir:                 "debug",
+		DebugMaxRuns:             10,
+		DebugMaxMB:               200,
+		VideoFPS:                 2,
+		OverlaySize:              600,
+		Tunables:                 DefaultTunables(),
+		MoveListPanelDelay:       500 * time.Millisecond,
+		DetectReview:             true,
+		TableDwell:               30 * time.Second,
+		SetupGame:                true,
+		AttachMidGame:            true,
+		EstimateScore:            true,
+		AnalysisCandidates:       3,
+		DeviceCheckInterval:      2 * time.Second,
+		TrackDevices:             true,
+		ThrottleBatteryBelow:     20,
+		ThrottleTemperatureAbove: 42,
+		ThrottleInterval:         time.Second,
+		Komi:                     7.5,
+		Ruleset:                  "chinese",
+		DashboardAddr:            DefaultDashboardAddr,
+		CaptureSource:            "adb",
+		CameraStableFrames:       3,
+		KatrainURL:               "http://localhost:8080",
+		KatrainBackend:           "http",
+		KatrainWindowTitle:       "KaTrain",
+		NotifyErrorAfter:         30 * time.Second,
+		DivergenceMoves:          2,
+		DriftThreshold:           2,
+		DriftFrames:              5,
+		EnableScrcpy:             true,
+		ScrcpyArgs:               []string{"--always-on-top", "--max-fps", "15"},
+		ScrcpyReadyTimeout:       10 * time.Second,
+		HealthTimeout:            30 * time.Second,
+		KatrainTimeout:           katrain.DefaultTimeout,
+		ADBTimeout:               adb.DefaultTimeout,
+		OCRTimeout:               ocr.DefaultTimeout,
+	}
+}
+
+// Session 一次同步会话
+type Session struct {
+	cfg      Config
+	detector *vision.Detector
+	state    *session.State
+	work     *workdir.Dir
+	phone    *adb.Client
+	// mu 保护 record、clocks、timer 与形势判断，双方最后一手等同步状态由 state 自行加锁
+	mu     sync.RWMutex
+	record *sgf.Game
+	timer  moveTimer
+	// setupMoves 中途接入时摆上的局面在 KaTrain 中的手数，棋谱中的第 i 手是 KaTrain 的第 setupMoves+i 手（由 mu 保护）
+	setupMoves int
+	// debug 调试文件目录，debugFrames 为已识别的帧数，用作调试文件的帧号
+	debug       *debugsink.Sink
+	debugFrames atomic.Int64
+	// stats 识别统计，未开启时为 nil
+	stats *stats.Recorder
+	// live 实时预览窗口，video 识别过程录像，archive 按手保存的样本截图，未开启时为 nil
+	live    *liveView
+	video   *videoRecorder
+	archive *moveArchive
+	// game 按规则重放已同步的棋步，累计提子数，OCR 不可用时据此从盘面推断手数
+	game       *board.Game
+	clocks     map[string]ocr.Clock
+	score      *board.Estimate
+	scoredAt   session.Last
+	dash       *dashboard.Dashboard
+	target     target.SyncTarget
+	relays     []target.SyncTarget
+	notifier   notify.Notifier
+	hooks      *hooks.Dispatcher
+	errTracker *notify.ErrorTracker
+	paused     atomic.Bool
+	// deviceAway 手机熄屏、锁屏或 App 不在前台，期间暂停同步
+	deviceAway atomic.Bool
+	// katrainMove syncKatrainToPhone 最近一次读到的 KaTrain 手数
+	katrainMove atomic.Int64
+	// drift 识别偏差监测的状态，只由 syncPhoneToKatrain 访问
+	drift driftMonitor
+	// disconnected adb track-devices 报告手机已断开或不可用，期间暂停同步
+	disconnected atomic.Bool
+	// resumeFlow 解析后的 ResumeFlow，resumedAt 为上次执行的时间，只由 watchDevice 使用
+	resumeFlow adb.Flow
+	resumedAt  time.Time
+	// phase 当前的对局阶段（board.Phase），由 updatePhase 更新
+	phase atomic.Int32
+	// book 定式书，未开启 OpeningBook 时为 nil；opening 为最近一次查到的名称，由 updateOpening 更新
+	book    *joseki.Book
+	opening atomic.Pointer[string]
+	// throttled 电量低或过热，截图已降频
+	throttled atomic.Bool
+	tuned     atomic.Pointer[Tunables]
+	// lastFrame 最近一次成功截图的时间（UnixNano），启动时记为当前时间
+	lastFrame atomic.Int64
+	// lastDetection 最近一次识别出棋步的时间（UnixNano），0 表示还没有
+	lastDetection atomic.Int64
+	// katrainErr 最近一次访问 KaTrain 的错误，空字符串表示成功，nil 表示还没访问过
+	katrainErr atomic.Pointer[string]
+	source     capture.Source
+	recognize  func(context.Context, gocv.Mat) (*vision.Result, error)
+	tracker    *board.Tracker
+	// suggestion 等待人工确认的 KaTrain 建议
+	suggestion atomic.Pointer[target.Move]
+	// spectated 观战模式下整盘局面的跟踪，用于补同步漏掉的棋步
+	spectated *board.Tracker
+	// landscape 最近一次截图是否为横屏，决定点击时使用哪套坐标
+	landscape atomic.Bool
+	// orientation 屏幕上棋盘的方向，识别结果与点击坐标都要经过它换算
+	orientation coords.Orientation
+	// blunders 等待 runBlunderCheck 检查胜率损失的棋步
+	blunders chan blunderCheck
+	// overlayDirty 通知 runOverlay 局面已变化，overlayFrame 为最近一次生成的直播叠加画面
+	overlayDirty chan struct{}
+	overlayFrame atomic.Pointer[overlay.Frame]
+	// tables 多桌轮换的各桌，tableIdx 为当前桌（还没切换过时为 -1，由 mu 保护），tableSince 为切到当前桌的时间
+	tables     []*table
+	tableIdx   int
+	tableSince time.Time
+	// tableKatrains 各桌的 KaTrain 实例（即 target），没有配置 TableKatrains 时为 nil
+	tableKatrains *target.Switch
+	// companion 手机上的伴侣 App，未开启 Companion 或连不上时为 nil；dialog 为最近一次看到的对话框内容
+	companion *companion.Client
+	dialog    atomic.Pointer[string]
+	// ui 最近一次用 uiautomator 读到的界面状态，未开启 UIDumpInterval 时为 nil
+	ui atomic.Pointer[uistate.State]
+}
+
+// ConfigError 配置有误导致无法启动，重试也不会成功；NewSession 的其他错误（如打开画面来源失败）可能是暂时的
+type ConfigError struct {
+	Err error
+}
+
+func (e *ConfigError) Error() string { return e.Err.Error() }
+
+func (e *ConfigError) Unwrap() error { return e.Err }
+
+// NewSession 准备同步会话：读取参数文件、连接手机、创建临时目录并打开画面来源。
+// 配置有误时返回 *ConfigError。用完后调用 Close 释放
+func NewSession(ctx context.Context, cfg Config) (*Session, error) {
+	orientation, err := coords.OrientationFromDegrees(cfg.BoardRotation)
+	if err != nil {
+		return nil, &ConfigError{err}
+	}
+	for _, f := range []ColorFilter{cfg.PhoneToKatrainColors, cfg.KatrainToPhoneColors, cfg.BlunderColors} {
+		if err := f.Validate(); err != nil {
+			return nil, &ConfigError{err}
+		}
+	}
+	ocrEndpoint := cfg.OCREndpoint
+	if name, _, _ := ocr.ParseSpec(cfg.OCRBackend); ocrEndpoint == "" && !ocr.Backends[name].Cloud {
+		ocrEndpoint = vision.DefaultOCREndpoint
+	}
+	ocrChain, err := ocr.NewChain(append([]string{cfg.OCRBackend}, cfg.OCRFallbacks...), ocrEndpoint, cfg.OCRLanguage, cfg.OCRCredentials)
+	if err != nil {
+		return nil, &ConfigError{err}
+	}
+	for _, c := range ocrChain.Clients {
+		c.Timeout = cfg.OCRTimeout
+	}
+	patterns := cfg.MoveNumberPatterns
+	if cfg.OCRProfile != "" {
+		profile, err := ocr.ProfilePatterns(cfg.OCRProfile)
+		if err != nil {
+			return nil, &ConfigError{err}
+		}
+		patterns = append(append([]string(nil), patterns...), profile...)
+	}
+	movePatterns, err := ocr.CompileMoveNumberPatterns(patterns)
+	if err != nil {
+		return nil, &ConfigError{err}
+	}
+	resumeFlow, err := adb.ParseFlow(cfg.ResumeFlow)
+	if err != nil {
+		return nil, &ConfigError{fmt.Errorf("返回对局流程配置错误: %v", err)}
+	}
+	switch cfg.TapMethod {
+	case "", TapInput, TapSendevent:
+	default:
+		return nil, &ConfigError{fmt.Errorf("未知的点击方式 %q，可选 %s、%s", cfg.TapMethod, TapInput, TapSendevent)}
+	}
+	if cfg.CoordinateFlow != "" {
+		if err := parseCoordinateFlow(cfg.CoordinateFlow); err != nil {
+			return nil, &ConfigError{err}
+		}
+	}
+	markerExclusions, err := vision.ParseMarkerExclusions(cfg.MarkerExclusions)
+	if err != nil {
+		return nil, &ConfigError{err}
+	}
+	tables, err := parseTables(cfg.Tables)
+	if err != nil {
+		return nil, &ConfigError{err}
+	}
+	if len(tables) > 0 && (!cfg.Spectator || cfg.Phone == nil || cfg.RelayBackend != "" || len(cfg.KatrainMirrors) > 0) {
+		return nil, &ConfigError{fmt.Errorf("多桌轮换只支持观战模式，需要 ADB 切换对局，且不能同时转播或同步到其他 KaTrain 实例")}
+	}
+	if cfg.TableKatrains != "" && (len(tables) == 0 || cfg.KatrainBackend == "gui" || cfg.Target != nil) {
+		return nil, &ConfigError{fmt.Errorf("各桌的 KaTrain 实例需要配置多桌轮换，并通过 HTTP API 接入 KaTrain")}
+	}
+	if cfg.CaptureSource == "remote" && cfg.RemoteURL == "" {
+		return nil, &ConfigError{fmt.Errorf("remote 画面来源需要设置采集端地址")}
+	}
+	if (cfg.DashboardCertFile == "") != (cfg.DashboardKeyFile == "") {
+		return nil, &ConfigError{fmt.Errorf("看板 HTTPS 需要同时设置证书与私钥")}
+	}
+	syncTarget := cfg.Target
+	var tableKatrains *target.Switch
+	if cfg.TableKatrains != "" {
+		if tableKatrains, err = newTableKatrains(tables, cfg); err != nil {
+			return nil, &ConfigError{err}
+		}
+		syncTarget = tableKatrains
+	}
+	if syncTarget == nil {
+		if syncTarget, err = newSyncTarget(cfg); err != nil {
+			return nil, &ConfigError{err}
+		}
+	}
+	debugLevel, err := debugsink.ParseLevel(cfg.DebugLevel)
+	if err != nil {
+		return nil, &ConfigError{err}
+	}
+	debug, err := debugsink.New(cfg.DebugDir, debugLevel, cfg.DebugMaxRuns, cfg.DebugMaxMB)
+	if err != nil {
+		return nil, err
+	}
+	var book *joseki.Book
+	if cfg.OpeningBook {
+		if book, err = joseki.Load(cfg.OpeningBookFile); err != nil {
+			return nil, &ConfigError{err}
+		}
+	}
+
+	s := &Session{
+		cfg:          cfg,
+		state:        session.NewState(),
+		phone:        cfg.Phone,
+		record:       sgf.NewGame(),
+		debug:        debug,
+		stats:        stats.NewRecorder(cfg.StatsFile),
+		game:         board.NewGame(),
+		clocks:       make(map[string]ocr.Clock),
+		dash:         dashboard.New(),
+		target:       syncTarget,
+		resumeFlow:   resumeFlow,
+		notifier:     cfg.Notifier,
+		hooks:        hooks.NewDispatcher(append(hooks.Registered(), cfg.Hooks...)),
+		errTracker:   notify.NewErrorTracker(cfg.NotifyErrorAfter),
+		tracker:      board.NewTracker(cfg.CameraStableFrames),
+		orientation:  orientation,
+		tables:       tables,
+		tableIdx:     -1,
+		overlayDirty: make(chan struct{}, 1),
+	}
+	if cfg.Spectator {
+		s.spectated = board.NewTracker(2)
+	}
+	s.tableKatrains = tableKatrains
+	s.book = book
+	s.blunders = make(chan blunderCheck, blunderQueue)
+
+	opts := []vision.Option{
+		vision.WithSkin(cfg.BoardSkin),
+		vision.WithTuning(cfg.Tunables.Vision),
+		vision.WithOCR(ocrChain),
+		vision.WithMoveNumberPatterns(movePatterns),
+		vision.WithGame(s.game),
+		vision.WithFusion(cfg.FuseSignals),
+		vision.WithMarkerExclusions(markerExclusions),
+		vision.WithSmoothing(cfg.SmoothFrames),
+		vision.WithDeadMark(cfg.DeadMark),
+		vision.WithDebugDetail(debugLevel != debugsink.Off),
+	}
+	if cfg.Classifier != nil {
+		opts = append(opts, vision.WithClassifier(cfg.Classifier))
+	}
+	if cfg.ConfirmButton != nil {
+		opts = append(opts, vision.WithConfirmButton(cfg.ConfirmButton))
+	}
+	s.detector = vision.NewDetector(opts...)
+
+	tunables := cfg.Tunables
+	s.tuned.Store(&tunables)
+	if cfg.ConfigFile != "" {
+		if err := s.ReloadConfig(cfg.ConfigFile); err != nil {
+			return nil, &ConfigError{err}
+		}
+	}
+
+	if s.cfg.RecordDir == "" {
+		dir, err := platform.DataDir()
+		if err != nil {
+			return nil, err
+		}
+		s.cfg.RecordDir = dir
+	} else if err := os.MkdirAll(s.cfg.RecordDir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建棋谱目录失败: %v", err)
+	}
+
+	if s.phone == nil {
+		s.phone = adb.NewClient("")
+	}
+	if cfg.ADBTimeout > 0 {
+		s.phone.Timeout = cfg.ADBTimeout
+	}
+	// 手机接在采集端上，adb 命令交给采集端执行
+	if cfg.CaptureSource == "remote" && s.phone.Runner == nil {
+		s.phone.Runner = remote.NewClient(cfg.RemoteURL, cfg.RemoteToken).ADB
+	}
+	if err := s.phone.Connect(ctx); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+	if cfg.TapMethod == TapSendevent {
+		s.setupTouchDevice(ctx)
+	}
+	if cfg.Companion {
+		s.setupCompanion(ctx)
+	}
+
+	work, err := s.setupWorkDir()
+	if err != nil {
+		return nil, err
+	}
+	s.work = work
+
+	s.source, s.recognize, err = s.newSource(ctx)
+	if err != nil {
+		s.work.Remove()
+		return nil, fmt.Errorf("打开画面来源失败: %v", err)
+	}
+
+	if cfg.RecordVideo {
+		path := filepath.Join(s.cfg.RecordDir, fmt.Sprintf("game_%s.mp4", time.Now().Format("20060102_150405")))
+		if s.video, err = newVideoRecorder(path, cfg.VideoFPS); err != nil {
+			fmt.Printf("⚠️  %v，不录像\n", err)
+		}
+	}
+	if cfg.SampleDir != "" {
+		s.archive = newMoveArchive(filepath.Join(cfg.SampleDir, time.Now().Format("20060102_150405")))
+	}
+	if cfg.LiveView {
+		if platform.Headless() {
+			fmt.Printf("ℹ️  无图形界面，不打开实时预览窗口\n")
+		} else {
+			s.live = newLiveView("goboardsync")
+		}
+	}
+
+	return s, nil
+}
+
+// Close 关闭画面来源、预览窗口与录像并删除临时目录
+func (s *Session) Close() error {
+	s.live.close()
+	s.archive.close()
+	s.hooks.Close()
+	if s.companion != nil {
+		s.companion.Close()
+	}
+	err := s.source.Close()
+	if videoErr := s.video.close(); err == nil {
+		err = videoErr
+	}
+	if statsErr := s.stats.Close(); err == nil {
+		err = statsErr
+	}
+	if rmErr := s.work.Remove(); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// Dashboard 返回会话的看板，可用于读取状态或注册额外的操作
+func (s *Session) Dashboard() *dashboard.Dashboard {
+	return s.dash
+}
+
+// Run 启动双向同步，直到 ctx 取消；返回前保存棋谱并推送对局结束通知
+func (s *Session) Run(ctx context.Context) error {
+	for _, url := range s.cfg.KatrainMirrors {
+		mirror, err := newKaTrain(url, s.cfg)
+		if err == nil {
+			mirror.Label = url
+			err = mirror.Reset(ctx)
+		}
+		if err != nil {
+			fmt.Printf("⚠️  KaTrain 实例 %s 连接失败，不同步到它: %v\n", url, err)
+			continue
+		}
+		s.relays = append(s.relays, mirror)
+		fmt.Printf("🪞 同时同步到 KaTrain 实例: %s\n", url)
+	}
+	if r := s.newRelay(); r != nil {
+		if err := r.Reset(ctx); err != nil {
+			fmt.Printf("⚠️  %s 连接失败，不转播: %v\n", r.Name(), err)
+		} else {
+			s.relays = append(s.relays, r)
+			fmt.Printf("📡 已开启转播: %s\n", r.Name())
+		}
+	}
+
+	fmt.Printf("🚀 程序已启动\n")
+	fmt.Printf("   画面来源: %s\n", s.cfg.CaptureSource)
+	fmt.Printf("   监控窗口: %s\n", s.cfg.WindowTitle)
+	fmt.Printf("   临时目录: %s\n", s.work.Path)
+	fmt.Printf("   棋谱目录: %s\n", s.cfg.RecordDir)
+	if s.video != nil {
+		fmt.Printf("   识别录像: %s\n", s.video.path)
+	}
+	if s.archive != nil {
+		fmt.Printf("   样本目录: %s\n", s.archive.dir)
+	}
+	if s.debug.Dir != "" {
+		fmt.Printf("   调试目录: %s\n", s.debug.Dir)
+	}
+	fmt.Printf("   同步目标: %s\n", s.target.Name())
+	fmt.Printf("   屏幕分辨率: %dx%d\n", s.cfg.TargetW, s.cfg.TargetH)
+	scheme := "http"
+	if s.cfg.DashboardCertFile != "" {
+		scheme = "https"
+	}
+	fmt.Printf("   看板地址: %s://%s\n", scheme, dashboardHost(s.cfg.DashboardAddr))
+	fmt.Println(strings.Repeat("=", 60))
+
+	// 启动前先在 KaTrain 开始新对局（不支持时清空棋盘）
+	s.probeTarget(ctx)
+	s.applyAnalysisBudget(ctx)
+	s.setupKatrainGame(ctx)
+	if s.cfg.AttachMidGame && s.cfg.CaptureSource != "camera" && len(s.tables) == 0 {
+		if err := s.attach(ctx); err != nil {
+			fmt.Printf("[%s] ⚠️  中途接入失败，按新对局同步: %v\n", time.Now().Format("15:04:05"), err)
+		}
+	}
+
+	if s.cfg.EnableScrcpy && s.cfg.CaptureSource != "camera" && s.cfg.CaptureSource != "remote" {
+		if platform.Headless() {
+			fmt.Printf("[%s] ℹ️  无图形界面，不启动 scrcpy\n", time.Now().Format("15:04:05"))
+		} else {
+			s.startScrcpy(ctx)
+		}
+	}
+
+	fmt.Printf("[%s] 🔄 启动双向同步...\n", time.Now().Format("15:04:05"))
+	fmt.Printf("[%s] 📱 监听手机 → KaTrain\n", time.Now().Format("15:04:05"))
+	if s.cfg.Spectator {
+		fmt.Printf("[%s] 👀 观战模式：不会点击手机\n", time.Now().Format("15:04:05"))
+		if len(s.tables) > 0 {
+			fmt.Printf("[%s] 🔀 轮换观看 %d 桌对局，每桌 %v（只点击切换流程）\n", time.Now().Format("15:04:05"), len(s.tables), s.cfg.TableDwell)
+		}
+	} else {
+		fmt.Printf("[%s] 🖥️  监听 KaTrain → 手机\n", time.Now().Format("15:04:05"))
+	}
+	fmt.Println(strings.Repeat("=", 60))
+	s.notifyEvent(notify.SyncStarted, fmt.Sprintf("开始同步：%s → %s", s.cfg.CaptureSource, s.target.Name()))
+
+	s.lastFrame.Store(time.Now().UnixNano())
+	s.dash.SetHealthCheck(s.checkHealth)
+	s.dash.SetHeartbeat(s.heartbeat)
+	s.dash.Handle("/overlay/", http.StripPrefix("/overlay", overlay.Handler(s.Frame)))
+	s.dash.SetAuth(s.cfg.DashboardAuth)
+	if s.cfg.DashboardAddr != "" {
+		if !loopbackAddr(s.cfg.DashboardAddr) && s.cfg.DashboardAuth == (dashboard.Auth{}) {
+			fmt.Printf("[%s] ⚠️  看板监听 %s 且未设置认证，同一网络中的任何人都能暂停同步、在手机上落子，请设置 DASHBOARD_TOKEN\n",
+				time.Now().Format("15:04:05"), s.cfg.DashboardAddr)
+		}
+		go func() {
+			var err error
+			if s.cfg.DashboardCertFile != "" {
+				err = s.dash.ListenAndServeTLS(s.cfg.DashboardAddr, s.cfg.DashboardCertFile, s.cfg.DashboardKeyFile)
+			} else {
+				err = s.dash.ListenAndServe(s.cfg.DashboardAddr)
+			}
+			if err != nil {
+				fmt.Printf("[%s] ❌ 看板启动失败: %v\n", time.Now().Format("15:04:05"), err)
+			}
+		}()
+	}
+
+	s.registerControls()
+	go s.runOverlay(ctx)
+	s.overlayChanged()
+	if s.cfg.BlunderThreshold > 0 {
+		go s.runBlunderCheck(ctx)
+	}
+	if s.cfg.ConfigFile != "" {
+		go s.watchConfig(ctx, s.cfg.ConfigFile)
+	}
+
+	if s.cfg.DeviceCheckInterval > 0 && s.cfg.CaptureSource != "camera" {
+		go s.watchDevice(ctx)
+	}
+	if s.cfg.UIDumpInterval > 0 && s.cfg.CaptureSource != "camera" {
+		go s.watchUIState(ctx)
+	}
+	// remote 模式下手机不在本机，track-devices 无从监听，断开由截图流重连处理
+	if s.cfg.TrackDevices && s.cfg.CaptureSource != "camera" && s.cfg.CaptureSource != "remote" {
+		go s.trackDevices(ctx)
+	}
+	go s.supervise(ctx, "手机 → KaTrain", s.syncPhoneToKatrain)
+	if katrain, ok := s.moveSource(); ok && !s.cfg.Spectator {
+		go s.supervise(ctx, "KaTrain → 手机", func(ctx context.Context) { s.syncKatrainToPhone(ctx, katrain) })
+	}
+
+	<-ctx.Done()
+	s.EndGame(context.WithoutCancel(ctx))
+	return nil
+}
+
+// loopbackAddr 监听地址 addr 是否只允许本机访问（localhost 或回环 IP）；不带主机名的 ":8090" 监听所有网卡
+func loopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// dashboardHost 在本机访问监听地址 addr 时使用的主机与端口，监听所有网卡时为 localhost
+func dashboardHost(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// checkHealth 供 /healthz 使用：暂停或手机熄屏时总是健康，否则要求最近 HealthTimeout 内成功截过图
+func (s *Session) checkHealth() error {
+	if s.paused.Load() || s.deviceAway.Load() {
+		return nil
+	}
+	last := time.Unix(0, s.lastFrame.Load())
+	if since := time.Since(last); since > s.cfg.HealthTimeout {
+		return fmt.Errorf("已有 %s 没有成功截图", since.Round(time.Second))
+	}
+	return nil
+}
+
+// heartbeat 供 /healthz 报告运行状况：截图与识别时间、KaTrain 是否可达、ADB 设备状态与双方手数
+func (s *Session) heartbeat(ctx context.Context) dashboard.Heartbeat {
+	h := dashboard.Heartbeat{
+		LastCapture: time.Unix(0, s.lastFrame.Load()),
+		Katrain:     "unknown",
+		PhoneMove:   s.state.Phone().Move,
+		KatrainMove: s.state.Katrain().Move,
+		Paused:      s.paused.Load(),
+	}
+	if t := s.lastDetection.Load(); t != 0 {
+		at := time.Unix(0, t)
+		h.LastDetection = &at
+	}
+	if e := s.katrainErr.Load(); e != nil {
+		h.Katrain = "ok"
+		if *e != "" {
+			h.Katrain, h.KatrainError = "error", *e
+		}
+	}
+	if s.cfg.CaptureSource != "camera" {
+		state, err := s.phone.State(ctx)
+		if err != nil {
+			h.DeviceError = err.Error()
+		}
+		h.Device = state
+	}
+	return h
+}
+
+// setupWorkDir 清理上次异常退出遗留的临时目录与截图，并为本次运行创建新的临时目录
+func (s *Session) setupWorkDir() (*workdir.Dir, error) {
+	removed, _ := workdir.Sweep("", 24*time.Hour)
+	legacy, _ := workdir.SweepFiles(s.cfg.RecordDir, time.Minute, "temp_*.png", "screen_*.png", "screenshot.jpg")
+	if n := len(removed) + len(legacy); n > 0 {
+		fmt.Printf("🧹 已清理 %d 个遗留的临时文件/目录\n", n)
+	}
+
+	return workdir.New("")
+}
+
+// newSyncTarget 按 KatrainBackend 创建同步目标，KaTrain 的 CA 证书无法读取时返回错误
+func newSyncTarget(cfg Config) (target.SyncTarget, error) {
+	if cfg.KatrainBackend == "gui" {
+		return target.NewGUI(cfg.KatrainWindowTitle), nil
+	}
+	k, err := newKaTrain(cfg.KatrainURL, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// newKaTrain 按 KatrainAuth 与 KatrainTimeout 创建访问 url 的 KaTrain 实例
+func newKaTrain(url string, cfg Config) (*target.KaTrain, error) {
+	k, err := target.NewKaTrainWithAuth(url, cfg.KatrainAuth)
+	if err != nil {
+		return nil, err
+	}
+	k.Client.Timeout = cfg.KatrainTimeout
+	return k, nil
+}
+
+// newTableKatrains 按 TableKatrains 为各桌创建 KaTrain 实例
+func newTableKatrains(tables []*table, cfg Config) (*target.Switch, error) {
+	urls, err := parseTableKatrains(tables, cfg.TableKatrains, cfg.KatrainURL)
+	if err != nil {
+		return nil, err
+	}
+	instances := make([]*target.KaTrain, len(urls))
+	for i, url := range urls {
+		if instances[i], err = newKaTrain(url, cfg); err != nil {
+			return nil, err
+		}
+		instances[i].Label = url
+	}
+	return target.NewSwitch(instances...), nil
+}
+
+// newSource 按 CaptureSource 创建画面来源及对应的识别方式；注入了 Source 时按 CaptureSource 选择识别方式
+func (s *Session) newSource(ctx context.Context) (capture.Source, func(context.Context, gocv.Mat) (*vision.Result, error), error) {
+	recognize := s.recognizeWithVision
+	if s.cfg.CaptureSource == "camera" {
+		recognize = s.recognizeFromCamera
+	}
+	if s.cfg.Source != nil {
+		return s.cfg.Source, recognize, nil
+	}
+
+	switch s.cfg.CaptureSource {
+	case "adb":
+		return capture.NewADBSource(s.phone, s.work.Path, s.work.Join("screenshot.jpg"), s.cfg.TargetW, s.cfg.TargetH), recognize, nil
+	case "screen":
+		if len(s.cfg.ScreenBoardCorners) == 0 {
+			return capture.NewScreenSource(s.cfg.ScreenRegion, s.work.Path, s.cfg.TargetW, s.cfg.TargetH), recognize, nil
+		}
+		src := capture.NewScreenSource(s.cfg.ScreenRegion, s.work.Path, 0, 0)
+		if err := s.registerScreenCorners(ctx, src, s.cfg.ScreenBoardCorners); err != nil {
+			return nil, nil, err
+		}
+		return src, recognize, nil
+	case "camera":
+		cam, err := capture.NewCameraSource(s.cfg.CameraDevice, 0, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		return cam, recognize, nil
+	case "remote":
+		return remote.NewClient(s.cfg.RemoteURL, s.cfg.RemoteToken).Source(), recognize, nil
+	}
+	return nil, nil, fmt.Errorf("未知的画面来源: %s", s.cfg.CaptureSource)
+}
+
+// registerScreenCorners 截一张图得到桌面客户端截图的原尺寸，把 corners 登记为本会话 Detector 在该分辨率下的棋盘四角，
+// 不改动全局的 vision.FixedBoardCorners。在同步协程启动之前调用，之后识别只读 s.detector.BoardCorners
+func (s *Session) registerScreenCorners(ctx context.Context, src *capture.ScreenSource, corners []image.Point) error {
+	if len(corners) != 4 {
+		return fmt.Errorf("ScreenBoardCorners 应为 4 个角点，实际为 %d 个", len(corners))
+	}
+	img, err := src.Grab(ctx)
+	if err != nil {
+		return fmt.Errorf("桌面截图失败，无法确定截图尺寸: %v", err)
+	}
+	defer img.Close()
+
+	size := image.Rect(0, 0, img.Cols(), img.Rows())
+	for _, p := range corners {
+		if !p.In(size) {
+			return fmt.Errorf("ScreenBoardCorners 的角点 %v 不在 %dx%d 的截图内", p, img.Cols(), img.Rows())
+		}
+	}
+	key := fmt.Sprintf("%dx%d", img.Cols(), img.Rows())
+	vision.WithBoardCorners(key, corners)(s.detector)
+	fmt.Printf("🖥️  桌面截图 %s，按配置的棋盘四角识别: %v\n", key, corners)
+	return nil
+}
+
+// newRelay 按 RelayBackend 创建转播目标
+func (s *Session) newRelay() target.SyncTarget {
+	switch s.cfg.RelayBackend {
+	case "igs":
+		return relay.NewIGS(s.cfg.RelayAddr, s.cfg.RelayUser, s.cfg.RelayPassword)
+	case "kgs":
+		return relay.NewKGS(s.cfg.RelayAddr, s.cfg.RelayUser, s.cfg.RelayPassword, s.cfg.KGSRoomID)
+	}
+	return nil
+}
+
+// setupKatrainGame 按手机上的对局在 KaTrain 开始新对局并写入棋谱头，目标不支持或设置失败时只清空棋盘
+func (s *Session) setupKatrainGame(ctx context.Context) {
+	setter, ok := s.gameSetter()
+	if !s.cfg.SetupGame || !ok {
+		s.clearKatrainBoard(ctx)
+		return
+	}
+
+	game := target.Game{
+		Size:     coords.Size,
+		Komi:     s.cfg.Komi,
+		Handicap: s.cfg.Handicap,
+		Ruleset:  s.cfg.Ruleset,
+	}
+	if s.cfg.CaptureSource != "camera" {
+		if img, err := s.source.Grab(ctx); err == nil {
+			game.BlackName, game.WhiteName, err = s.detector.FetchPlayerNames(img)
+			img.Close()
+			if err != nil {
+				fmt.Printf("[%s] ⚠️  未能读取对局者名字: %v\n", time.Now().Format("15:04:05"), err)
+			}
+		}
+	}
+
+	if err := setter.NewGame(ctx, game); err != nil {
+		fmt.Printf("[%s] ⚠️  KaTrain 设置对局失败，只清空棋盘: %v\n", time.Now().Format("15:04:05"), err)
+		s.clearKatrainBoard(ctx)
+		return
+	}
+	fmt.Printf("[%s] ✅ KaTrain 已开始新对局: 黑 %q 白 %q，贴目 %g，让 %d 子，规则 %s\n",
+		time.Now().Format("15:04:05"),
+		game.BlackName,
+		game.WhiteName,
+		game.Komi,
+		game.Handicap,
+		game.Ruleset,
+	)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record.SetRoot("KM", strconv.FormatFloat(game.Komi, 'f', -1, 64))
+	if game.Handicap > 0 {
+		s.record.SetRoot("HA", strconv.Itoa(game.Handicap))
+	}
+	if game.Ruleset != "" {
+		s.record.SetRoot("RU", game.Ruleset)
+	}
+	if game.BlackName != "" {
+		s.record.SetRoot("PB", game.BlackName)
+	}
+	if game.WhiteName != "" {
+		s.record.SetRoot("PW", game.WhiteName)
+	}
+}
+
+func (s *Session) clearKatrainBoard(ctx context.Context) {
+	fmt.Printf("[%s] 🧹 正在清空 KaTrain 棋盘...\n", time.Now().Format("15:04:05"))
+	err := s.target.Reset(ctx)
+	if err != nil {
+		fmt.Printf("[%s] ❌ 清空棋盘失败: %v\n", time.Now().Format("15:04:05"), err)
+	} else {
+		fmt.Printf("[%s] ✅ KaTrain 棋盘已清空\n", time.Now().Format("15:04:05"))
+	}
+}
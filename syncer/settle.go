@@ -0,0 +1,67 @@
+package syncer
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"goboardsync/vision"
+
+	"gocv.io/x/gocv"
+)
+
+// settleState 等待落子点周围的动画（提子时棋子淡出、落子高亮圈）结束，只由一个识别循环使用
+type settleState struct {
+	// prev 上一帧截图，at 正在等待的交叉点（0 起下标），frames 该点周围连续没有变化的帧数
+	prev   gocv.Mat
+	at     image.Point
+	frames int
+	logged bool
+}
+
+func (st *settleState) close() {
+	if !st.prev.Empty() {
+		st.prev.Close()
+	}
+	st.prev = gocv.Mat{}
+}
+
+// settled 开启 SettleFrames 时，新识别到的一手要等落子点周围连续 SettleFrames 帧（随对局阶段调整，见 settleFrames）没有变化才返回 true，
+// 避免把动画中途的画面（淡出的棋子、高亮圈）当作最终局面。已处理过的一手、没识别出最后一手、
+// 摄像头画面（局面本身已按帧稳定）以及无法比较两帧时不等待
+func (s *Session) settled(st *settleState, img gocv.Mat, result *vision.Result) bool {
+	frames := s.settleFrames()
+	if frames <= 0 || s.cfg.CaptureSource == "camera" || result.X == 0 {
+		return true
+	}
+	if last := s.state.Phone(); last.X == result.X && last.Y == result.Y {
+		st.close()
+		return true
+	}
+
+	prev := st.prev
+	st.prev = img.Clone()
+	if prev.Empty() || result.GridIndex != st.at {
+		st.at, st.frames, st.logged = result.GridIndex, 0, false
+		if !prev.Empty() {
+			prev.Close()
+		}
+		return false
+	}
+	defer prev.Close()
+
+	diff, err := s.detector.AnimationDiff(prev, img, st.at)
+	if err != nil {
+		return true
+	}
+	if diff >= vision.AnimationMinDiff {
+		if !st.logged {
+			fmt.Printf("[%s] ⏳ %d-%d 周围仍在变化（%.1f），等动画结束再同步\n", time.Now().Format("15:04:05"), result.X, result.Y, diff)
+			st.logged = true
+		}
+		st.frames = 0
+		return false
+	}
+	st.frames++
+	return st.frames >= frames
+}
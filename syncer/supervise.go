@@ -0,0 +1,64 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"runtime/debug"
+	"time"
+
+	"goboardsync/dashboard"
+	"goboardsync/notify"
+)
+
+// 同步循环崩溃后重新运行前的等待时间，连续崩溃时翻倍，运行超过 loopStableAfter 后重置
+var (
+	loopMinBackoff  = time.Second
+	loopMaxBackoff  = 30 * time.Second
+	loopStableAfter = time.Minute
+)
+
+// supervise 运行同步循环 loop。loop 崩溃（panic，如访问空的 Mat）时打印堆栈、推送通知、在看板上累计重启次数，
+// 等待退避时间后重新运行，不让一帧的异常拖垮整个进程；loop 正常返回或 ctx 取消时结束
+func (s *Session) supervise(ctx context.Context, name string, loop func(context.Context)) {
+	backoff := loopMinBackoff
+	for {
+		started := time.Now()
+		if !s.runRecovered(ctx, name, loop) || ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) >= loopStableAfter {
+			backoff = loopMinBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		s.dash.Update(func(st *dashboard.Status) {
+			// 换成新的 map，Snapshot 返回的副本不会与之后的修改共享
+			restarts := maps.Clone(st.LoopRestarts)
+			if restarts == nil {
+				restarts = make(map[string]int)
+			}
+			restarts[name]++
+			st.LoopRestarts = restarts
+		})
+		fmt.Printf("[%s] 🔁 重新启动 %s 同步\n", time.Now().Format("15:04:05"), name)
+		backoff = min(backoff*2, loopMaxBackoff)
+	}
+}
+
+// runRecovered 运行一次 loop，返回它是否因 panic 退出
+func (s *Session) runRecovered(ctx context.Context, name string, loop func(context.Context)) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			fmt.Printf("[%s] 💥 %s 同步崩溃: %v\n%s", time.Now().Format("15:04:05"), name, r, debug.Stack())
+			s.notifyEvent(notify.LoopPanic, fmt.Sprintf("%s 同步崩溃，自动重启: %v", name, r))
+		}
+	}()
+	loop(ctx)
+	return false
+}
@@ -0,0 +1,1107 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"goboardsync/adb"
+	"goboardsync/board"
+	"goboardsync/coords"
+	"goboardsync/dashboard"
+	"goboardsync/joseki"
+	"goboardsync/katrain"
+	"goboardsync/katrain/katraintest"
+	"goboardsync/notify"
+	"goboardsync/session"
+	"goboardsync/sgf"
+	"goboardsync/stats"
+	"goboardsync/target"
+	"goboardsync/uistate"
+	"goboardsync/vision"
+	"goboardsync/vision/synth"
+
+	"gocv.io/x/gocv"
+)
+
+func newTestSession() *Session {
+	s := &Session{cfg: DefaultConfig(), detector: vision.NewDetector(), record: sgf.NewGame(), game: board.NewGame(), dash: dashboard.New()}
+	tunables := DefaultTunables()
+	s.tuned.Store(&tunables)
+	return s
+}
+
+func TestGridToScreen(t *testing.T) {
+	s := newTestSession()
+
+	tests := []struct {
+		x, y   int
+		sx, sy int
+	}{
+		{0, 18, 60, 560},
+		{0, 0, 60, 1640},
+		{18, 0, 1140, 1640},
+		{9, 9, 600, 1100},
+	}
+
+	for _, tt := range tests {
+		sx, sy := s.gridToScreen(tt.x, tt.y)
+		if sx != tt.sx || sy != tt.sy {
+			t.Errorf("gridToScreen(%d, %d) = (%d, %d), want (%d, %d)", tt.x, tt.y, sx, sy, tt.sx, tt.sy)
+		}
+	}
+}
+
+func TestLandscapeGeometry(t *testing.T) {
+	s := newTestSession()
+	s.landscape.Store(true)
+
+	if sx, sy := s.gridToScreen(0, 18); sx != 60 || sy != 60 {
+		t.Errorf("横屏 gridToScreen(0, 18) = (%d, %d), want (60, 60)", sx, sy)
+	}
+	if g := s.geometry(); g.ConfirmX != 1950 || g.ConfirmY != 1050 {
+		t.Errorf("横屏确认按钮 = (%d, %d), want (1950, 1050)", g.ConfirmX, g.ConfirmY)
+	}
+}
+
+func TestRotatedBoard(t *testing.T) {
+	s := newTestSession()
+	s.orientation = coords.Rotate180
+
+	// 倒过来显示时，D4 出现在屏幕右上方 Q16 的位置
+	if sx, sy := s.gridToScreen(3, 3); sx != 60+15*60 || sy != 560+3*60 {
+		t.Errorf("gridToScreen(D4) = (%d, %d), want (960, 740)", sx, sy)
+	}
+
+	px, py := s.boardToPhone(3, 3)
+	if x, y := s.phoneToBoard(px, py); x != 3 || y != 3 {
+		t.Errorf("phoneToBoard(boardToPhone(3, 3)) = (%d, %d)", x, y)
+	}
+	if px != 16 || py != 4 {
+		t.Errorf("boardToPhone(3, 3) = (%d, %d), want (16, 4)", px, py)
+	}
+}
+
+func TestSpectatorNeverTaps(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSession()
+	s.cfg.Spectator = true
+
+	// phone 为 nil，真的点击会 panic
+	if err := s.tapOnPhone(ctx, 3, 3); err == nil {
+		t.Error("观战模式下 tapOnPhone() 应返回错误")
+	}
+}
+
+func TestCoordinateFlow(t *testing.T) {
+	ctx := context.Background()
+	var commands []string
+	s := newTestSession()
+	s.phone = &adb.Client{Runner: func(ctx context.Context, args ...string) ([]byte, error) {
+		commands = append(commands, strings.Join(args, " "))
+		return nil, nil
+	}}
+	s.cfg.CoordinateFlow = "tap 1100,2400; text {coord} {sgf} {tencent}; key ENTER"
+
+	if err := s.tapOnPhone(ctx, 15, 15); err != nil {
+		t.Fatalf("tapOnPhone() error = %v", err)
+	}
+	want := []string{"shell input tap 1100 2400", "shell input text Q16%spd%sP4", "shell input keyevent KEYCODE_ENTER"}
+	if !slices.Equal(commands, want) {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+
+	for _, flow := range []string{"tap 1100,2400; key ENTER", "text {coord}; tap 1"} {
+		if err := parseCoordinateFlow(flow); err == nil {
+			t.Errorf("parseCoordinateFlow(%q) 应返回错误", flow)
+		}
+	}
+}
+
+func TestSetupTouchDevice(t *testing.T) {
+	ctx := context.Background()
+	getevent := "add device 1: /dev/input/event2\n  name: \"ts\"\n" +
+		"    ABS (0003): ABS_MT_POSITION_X : value 0, min 0, max 1079\n" +
+		"                ABS_MT_POSITION_Y : value 0, min 0, max 2399\n"
+	s := newTestSession()
+	s.phone = &adb.Client{Runner: func(ctx context.Context, args ...string) ([]byte, error) {
+		switch strings.Join(args, " ") {
+		case "shell getevent -pl":
+			return []byte(getevent), nil
+		case "shell wm size":
+			return []byte("Physical size: 1080x2400"), nil
+		}
+		return nil, nil
+	}}
+
+	s.cfg.TouchDevice = "/dev/input/event5"
+	s.setupTouchDevice(ctx)
+	if s.phone.Touch != nil {
+		t.Errorf("找不到 %s 时应退回 input tap", s.cfg.TouchDevice)
+	}
+
+	s.cfg.TouchDevice = ""
+	s.setupTouchDevice(ctx)
+	if s.phone.Touch == nil || s.phone.Touch.Path != "/dev/input/event2" {
+		t.Errorf("Touch = %+v, want /dev/input/event2", s.phone.Touch)
+	}
+}
+
+func TestUIState(t *testing.T) {
+	s := newTestSession()
+	dialog := &uistate.Node{Children: []*uistate.Node{
+		{Class: "android.app.AlertDialog", Children: []*uistate.Node{
+			{Text: "对方申请数子"},
+			{Text: "同意", Clickable: true, Bounds: [4]int{700, 1500, 1100, 1600}},
+		}},
+	}}
+
+	s.updateUIState(&uistate.State{Time: time.Now(), Activity: "com.tencent.tmgp.go/.GameActivity", Dialog: dialog.FindDialog(), MoveNumber: 88})
+	st := s.dash.Snapshot()
+	if st.Dialog != "对方申请数子，按钮: 同意 (900, 1550)" || st.Activity != "com.tencent.tmgp.go/.GameActivity" {
+		t.Errorf("dashboard dialog = %q, activity = %q", st.Dialog, st.Activity)
+	}
+	if n := s.uiMoveNumber(); n != 88 {
+		t.Errorf("uiMoveNumber() = %d, want 88", n)
+	}
+
+	// 对话框关闭；读取时间过久的手数不再使用
+	s.updateUIState(&uistate.State{Time: time.Now().Add(-time.Minute), MoveNumber: 88})
+	if st := s.dash.Snapshot(); st.Dialog != "" {
+		t.Errorf("对话框关闭后 dialog = %q, want 空", st.Dialog)
+	}
+	if n := s.uiMoveNumber(); n != 0 {
+		t.Errorf("过时的 uiMoveNumber() = %d, want 0", n)
+	}
+}
+
+func TestTapOutsideBoard(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSession()
+
+	// phone 为 nil，真的点击会 panic
+	for _, pt := range [][2]int{{19, 3}, {3, -1}} {
+		err := s.tapOnPhone(ctx, pt[0], pt[1])
+		var rangeErr *TapRangeError
+		if !errors.As(err, &rangeErr) {
+			t.Errorf("tapOnPhone(%d, %d) error = %v, want *TapRangeError", pt[0], pt[1], err)
+		}
+	}
+
+	// 棋盘参数有误（线间距为 0）时任何落子点都不点击
+	tunables := DefaultTunables()
+	tunables.BoardGap = 0
+	s.tuned.Store(&tunables)
+	if err := s.tapOnPhone(ctx, 3, 3); !errors.As(err, new(*TapRangeError)) {
+		t.Errorf("线间距为 0 时 tapOnPhone() error = %v, want *TapRangeError", err)
+	}
+
+	if b := DefaultTunables().Geometry.Board(); b != image.Rect(30, 530, 1171, 1671) {
+		t.Errorf("默认棋盘范围 = %v", b)
+	}
+}
+
+func TestSuggestion(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSession()
+	s.cfg.ApproveMoves = true
+
+	if err := s.ApproveSuggestion(ctx); err == nil {
+		t.Error("没有建议时 ApproveSuggestion() 应返回错误")
+	}
+
+	s.suggest(target.Move{X: 3, Y: 3, Color: "B", Number: 1})
+	s.suggest(target.Move{X: 15, Y: 15, Color: "B", Number: 1})
+	if got := s.dash.Snapshot().Suggestion; got != "B Q16" {
+		t.Errorf("看板建议 = %q, want %q", got, "B Q16")
+	}
+
+	if err := s.RejectSuggestion(); err != nil {
+		t.Fatalf("RejectSuggestion() error = %v", err)
+	}
+	if got := s.dash.Snapshot().Suggestion; got != "" {
+		t.Errorf("放弃后看板建议 = %q, want 空", got)
+	}
+	if err := s.RejectSuggestion(); err == nil {
+		t.Error("建议只能处理一次")
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	tests := []struct {
+		battery adb.Battery
+		want    string
+	}{
+		{adb.Battery{Level: 80, Temperature: 35}, ""},
+		{adb.Battery{Level: 15, Temperature: 35}, "电量仅剩 15%"},
+		{adb.Battery{Level: 15, Temperature: 35, Charging: true}, ""},
+		{adb.Battery{Level: 80, Temperature: 43.5}, "电池温度 43.5°C"},
+	}
+	for _, tt := range tests {
+		if got := throttleReason(tt.battery, 20, 42); got != tt.want {
+			t.Errorf("throttleReason(%+v) = %q, want %q", tt.battery, got, tt.want)
+		}
+	}
+
+	s := newTestSession()
+	if got := s.captureInterval(); got != s.tuned.Load().Interval {
+		t.Errorf("captureInterval() = %v, want %v", got, s.tuned.Load().Interval)
+	}
+	s.throttled.Store(true)
+	if got := s.captureInterval(); got != s.cfg.ThrottleInterval {
+		t.Errorf("降频时 captureInterval() = %v, want %v", got, s.cfg.ThrottleInterval)
+	}
+}
+
+func TestColorFilter(t *testing.T) {
+	tests := []struct {
+		filter ColorFilter
+		color  string
+		want   bool
+	}{
+		{"", "B", true},
+		{"", "W", true},
+		{"B", "B", true},
+		{"B", "W", false},
+		{"w", "W", true},
+		{"BW", "W", true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.filter.Allows(tt.color); got != tt.want {
+			t.Errorf("ColorFilter(%q).Allows(%q) = %v, want %v", tt.filter, tt.color, got, tt.want)
+		}
+	}
+
+	if err := ColorFilter("BX").Validate(); err == nil {
+		t.Error(`ColorFilter("BX").Validate() 应返回错误`)
+	}
+}
+
+// analyzingTarget 只实现分析接口的假目标，第 2 手之后尚未分析
+type analyzingTarget struct {
+	target.SyncTarget
+}
+
+func (analyzingTarget) Analysis(ctx context.Context, move int) (target.Analysis, error) {
+	if move > 1 {
+		return target.Analysis{}, fmt.Errorf("尚未分析")
+	}
+	return target.Analysis{
+		Winrate:   0.42,
+		ScoreLead: -1.5,
+		Candidates: []target.Candidate{
+			{X: 15, Y: 15, Winrate: 0.45, ScoreLead: -0.5},
+			{X: 15, Y: 3, Winrate: 0.44, ScoreLead: -0.8},
+		},
+	}, nil
+}
+
+func TestAnnotateRecord(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSession()
+	s.cfg.AnalysisCandidates = 1
+	s.target = analyzingTarget{}
+	s.record.AddMove("B", 3, 3)
+	s.record.AddMove("W", 15, 3)
+
+	s.annotateRecord(ctx)
+
+	first := s.record.Nodes[0]
+	if got, want := first.Get("C"), "黑胜率 42.0%，白领先 1.5 目\n推荐: A Q16 45.0% 白领先 0.5 目"; len(got) != 1 || got[0] != want {
+		t.Errorf("注释 = %q, want %q", got, want)
+	}
+	if got := first.Get("LB"); len(got) != 1 || got[0] != "pd:A" {
+		t.Errorf("LB = %v, want [pd:A]", got)
+	}
+	if got := s.record.Nodes[1].Get("C"); got != nil {
+		t.Errorf("尚未分析的一手不应有注释: %q", got)
+	}
+}
+
+// winrateTarget 按手数返回给定黑方胜率的假目标，没有给出的手数尚未分析
+type winrateTarget struct {
+	target.SyncTarget
+	winrates map[int]float64
+}
+
+func (w winrateTarget) Name() string { return "KaTrain" }
+
+func (w winrateTarget) Analysis(ctx context.Context, move int) (target.Analysis, error) {
+	winrate, ok := w.winrates[move]
+	if !ok {
+		return target.Analysis{}, fmt.Errorf("尚未分析")
+	}
+	return target.Analysis{Winrate: winrate}, nil
+}
+
+func TestBlunderCheck(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSession()
+	s.cfg.BlunderThreshold = 10
+	s.cfg.BlunderColors = "W"
+	s.blunders = make(chan blunderCheck, blunderQueue)
+	s.target = winrateTarget{winrates: map[int]float64{0: 0.5, 1: 0.52, 2: 0.55, 3: 0.4, 4: 0.7}}
+
+	tests := []struct {
+		move  int
+		color string
+		want  float64
+	}{
+		{1, "B", -0.02},
+		{2, "W", 0.03},
+		{3, "B", 0.15},
+		{4, "W", 0.3},
+	}
+	for _, tt := range tests {
+		loss, _, _, err := s.winrateLoss(ctx, blunderCheck{move: tt.move, color: tt.color, record: s.record})
+		if err != nil || math.Abs(loss-tt.want) > 1e-9 {
+			t.Errorf("第 %d 手 winrateLoss() = %v, %v, want %v", tt.move, loss, err, tt.want)
+		}
+	}
+	if _, _, _, err := s.winrateLoss(ctx, blunderCheck{move: 5, color: "B", record: s.record}); err == nil {
+		t.Errorf("尚未分析时应返回错误")
+	}
+	if _, _, _, err := s.winrateLoss(ctx, blunderCheck{move: 4, color: "W", record: sgf.NewGame()}); err == nil {
+		t.Errorf("切换对局后应返回错误")
+	}
+
+	// 只检查白棋：第 3 手黑棋损失 15% 不报警，第 4 手白棋损失 30% 报警
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.runBlunderCheck(ctx)
+	s.queueBlunderCheck(blunderCheck{move: 3, color: "B", x: 3, y: 3, record: s.record})
+	s.queueBlunderCheck(blunderCheck{move: 4, color: "W", x: 15, y: 15, record: s.record})
+	deadline := time.Now().Add(2 * time.Second)
+	for s.dash.Snapshot().Blunder == "" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got, want := s.dash.Snapshot().Blunder, "第 4 手 白棋 Q16 胜率损失 30.0%（黑胜率 40.0% → 70.0%）"; got != want {
+		t.Errorf("看板 Blunder = %q, want %q", got, want)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	s := newTestSession()
+	path := filepath.Join(t.TempDir(), "goboardsync.conf")
+
+	os.WriteFile(path, []byte("BOARD_GAP=62\nINTERVAL=200ms\n"), 0o644)
+	if err := s.ReloadConfig(path); err != nil {
+		t.Fatalf("ReloadConfig() error = %v", err)
+	}
+	if got := s.tuned.Load(); got.BoardGap != 62 || got.Interval != 200*time.Millisecond || got.ConfirmX != 600 {
+		t.Errorf("参数 = %+v, want BoardGap 62 Interval 200ms，其余不变", *got)
+	}
+
+	os.WriteFile(path, []byte("BOARD_GAP=70\nPOLL_INTERVAL=0s\n"), 0o644)
+	if err := s.ReloadConfig(path); err == nil {
+		t.Errorf("ReloadConfig(轮询间隔为 0) error = nil, want error")
+	}
+	if got := s.tuned.Load().BoardGap; got != 62 {
+		t.Errorf("出错后 BoardGap = %v, want 62", got)
+	}
+}
+
+func TestRecordMoveSkipsRepeat(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSession()
+
+	s.recordMove(ctx, "B", 3, 15)
+	s.recordMove(ctx, "B", 3, 15)
+	s.recordMove(ctx, "W", 15, 3)
+
+	if got := len(s.record.Nodes); got != 2 {
+		t.Errorf("棋谱手数 = %d, want 2", got)
+	}
+	if b := s.game.Board(); b.At(3, 15) != board.Black || b.At(15, 3) != board.White {
+		t.Errorf("同步的棋步未按规则记入对局")
+	}
+}
+
+func TestMoveTiming(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSession()
+	s.record.SetRoot("TM", "600")
+
+	// 第一手不计用时，之后每手的用时记在落子方名下
+	start := time.Date(2024, 5, 1, 20, 0, 0, 0, time.Local)
+	s.timer.move("B", start)
+	s.timer.move("W", start.Add(20*time.Second))
+	s.timer.move("B", start.Add(50*time.Second))
+
+	got := s.timer.status()
+	if got.BlackSeconds != 30 || got.WhiteSeconds != 20 || got.LastMoveSeconds != 30 {
+		t.Errorf("用时 = %+v, want 黑 30 白 20 最近一手 30", got)
+	}
+	if left, ok := s.timeLeft("W"); !ok || left != 580*time.Second {
+		t.Errorf("白方剩余时间 = %v, %v, want 9m40s", left, ok)
+	}
+
+	s.recordMove(ctx, "W", 15, 3)
+	if timing := s.dash.Snapshot().Timing; timing == nil || timing.WhiteSeconds < 20 {
+		t.Errorf("看板用时 = %+v", timing)
+	}
+	if wl := s.record.LastMove().Get("WL"); len(wl) != 1 {
+		t.Errorf("没有手机计时时应按 TM 推算 WL，得到 %q", wl)
+	}
+}
+
+func TestSwitchTables(t *testing.T) {
+	ctx := context.Background()
+	if _, err := parseTables("A=tap 100,300"); err == nil {
+		t.Error("只有一桌时 parseTables() 应返回错误")
+	}
+
+	tables, err := parseTables("A=tap 100,300; wait 1ms | B=tap 400,300; wait 1ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	katrain := katraintest.NewServer()
+	defer katrain.Close()
+	phone := &fakePhone{}
+
+	s := newTestSession()
+	s.state = session.NewState()
+	s.errTracker = notify.NewErrorTracker(time.Minute)
+	s.phone = &adb.Client{Runner: phone.run}
+	s.target = target.NewKaTrain(katrain.URL)
+	s.tables, s.tableIdx = tables, -1
+
+	// 第一次切到 A，在 A 下两手；切到 B 后 KaTrain 清空，B 下一手；再切回 A 时 KaTrain 恢复 A 的两手
+	s.switchTable(ctx)
+	s.recordMove(ctx, "B", 15, 15)
+	s.recordMove(ctx, "W", 3, 3)
+	s.switchTable(ctx)
+	if got := len(katrain.Moves()); got != 0 {
+		t.Errorf("切到 B 后 KaTrain 有 %d 手, want 0", got)
+	}
+	s.recordMove(ctx, "B", 16, 3)
+	s.switchTable(ctx)
+
+	if got := len(katrain.Moves()); got != 2 {
+		t.Errorf("切回 A 后 KaTrain 有 %d 手, want 2", got)
+	}
+	if b := s.game.Board(); len(s.record.Nodes) != 2 || b.At(16, 3) != board.Empty {
+		t.Errorf("切回 A 后棋谱 %d 手，或对局混入了 B 的棋步", len(s.record.Nodes))
+	}
+	if got := s.dash.Snapshot().Table; got != "A" {
+		t.Errorf("看板当前桌 = %q, want A", got)
+	}
+	want := []image.Point{{100, 300}, {400, 300}, {100, 300}}
+	if taps := phone.Taps(); fmt.Sprint(taps) != fmt.Sprint(want) {
+		t.Errorf("切换点击 = %v, want %v", taps, want)
+	}
+
+	s.cfg.RecordDir = t.TempDir()
+	s.SaveRecord()
+	s.saveTables()
+	files, _ := filepath.Glob(filepath.Join(s.cfg.RecordDir, "game_*_?.sgf"))
+	if len(files) != 2 {
+		t.Errorf("保存的棋谱 = %v, want A、B 各一份", files)
+	}
+}
+
+func TestTableKatrains(t *testing.T) {
+	ctx := context.Background()
+	tables, err := parseTables("A=tap 100,300; wait 1ms | B=tap 400,300; wait 1ms | C=tap 700,300; wait 1ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseTableKatrains(tables, "D=http://localhost:8081", "http://localhost:8080"); err == nil {
+		t.Error("不在多桌配置中的桌应返回错误")
+	}
+	urls, err := parseTableKatrains(tables, "A=http://localhost:8081 | B=http://localhost:8082", "http://localhost:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != 3 || tables[0].katrain != 1 || tables[1].katrain != 2 || tables[2].katrain != 0 {
+		t.Fatalf("urls = %v, 各桌实例 = %d %d %d", urls, tables[0].katrain, tables[1].katrain, tables[2].katrain)
+	}
+
+	// A、B 各自独占一个实例，C 与 A 共用一个：切回 A 时要重放，B 一直保留
+	tables, _ = parseTables("A=tap 100,300; wait 1ms | B=tap 400,300; wait 1ms | C=tap 700,300; wait 1ms")
+	parseTableKatrains(tables, "A=shared | C=shared | B=own", "unused")
+	if tables[0].ownKatrain || !tables[1].ownKatrain || tables[2].ownKatrain {
+		t.Fatalf("独占 = %v %v %v, want false true false", tables[0].ownKatrain, tables[1].ownKatrain, tables[2].ownKatrain)
+	}
+
+	base, shared, own := katraintest.NewServer(), katraintest.NewServer(), katraintest.NewServer()
+	defer base.Close()
+	defer shared.Close()
+	defer own.Close()
+	s := newTestSession()
+	s.state = session.NewState()
+	s.errTracker = notify.NewErrorTracker(time.Minute)
+	s.phone = &adb.Client{Runner: (&fakePhone{}).run}
+	s.tableKatrains = target.NewSwitch(target.NewKaTrain(base.URL), target.NewKaTrain(shared.URL), target.NewKaTrain(own.URL))
+	s.target = s.tableKatrains
+	s.tables, s.tableIdx = tables, -1
+
+	s.switchTable(ctx) // A
+	s.target.Play(ctx, 15, 15, "B")
+	s.recordMove(ctx, "B", 15, 15)
+	s.switchTable(ctx) // B
+	s.target.Play(ctx, 3, 3, "B")
+	s.recordMove(ctx, "B", 3, 3)
+	s.switchTable(ctx) // C：与 A 共用，清空
+	if got := len(shared.Moves()); got != 0 {
+		t.Errorf("切到 C 后共用的实例有 %d 手, want 0", got)
+	}
+	s.switchTable(ctx) // A：重放 A 的一手
+	s.switchTable(ctx) // B：独占，不重放
+	if got := len(shared.Moves()); got != 1 {
+		t.Errorf("切回 A 后共用的实例有 %d 手, want 1", got)
+	}
+	if got := own.Moves(); len(got) != 1 || got[0].X != 3 {
+		t.Errorf("B 独占的实例 = %v, want 只有 B 的一手", got)
+	}
+	if len(base.Moves()) != 0 {
+		t.Errorf("各桌都配置了实例时不应使用 KatrainURL")
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSession()
+	s.state = session.NewState()
+	s.errTracker = notify.NewErrorTracker(time.Minute)
+	s.phone = &adb.Client{Runner: func(ctx context.Context, args ...string) ([]byte, error) {
+		if len(args) == 1 && args[0] == "get-state" {
+			return []byte("device\n"), nil
+		}
+		return nil, fmt.Errorf("unexpected adb %v", args)
+	}}
+
+	h := s.heartbeat(ctx)
+	if h.Katrain != "unknown" || h.LastDetection != nil || h.Device != "device" {
+		t.Errorf("启动时 heartbeat() = %+v", h)
+	}
+
+	s.state.ObservePhone(session.Move{Number: 37, X: 16, Y: 4})
+	s.lastDetection.Store(time.Now().UnixNano())
+	s.reportError("KaTrain 读取", fmt.Errorf("connection refused"))
+	h = s.heartbeat(ctx)
+	if h.PhoneMove != 37 || h.LastDetection == nil || h.Katrain != "error" || h.KatrainError != "connection refused" {
+		t.Errorf("KaTrain 出错时 heartbeat() = %+v", h)
+	}
+
+	s.reportOK("KaTrain 读取")
+	if h = s.heartbeat(ctx); h.Katrain != "ok" || h.KatrainError != "" {
+		t.Errorf("KaTrain 恢复后 heartbeat() = %+v", h)
+	}
+}
+
+func TestDashboardAddr(t *testing.T) {
+	tests := []struct {
+		addr     string
+		loopback bool
+		host     string
+	}{
+		{DefaultDashboardAddr, true, "127.0.0.1:8090"},
+		{"localhost:8090", true, "localhost:8090"},
+		{"[::1]:8090", true, "[::1]:8090"},
+		{":8090", false, "localhost:8090"},
+		{"0.0.0.0:8090", false, "localhost:8090"},
+		{"192.168.1.10:8090", false, "192.168.1.10:8090"},
+		{"8090", false, "8090"},
+	}
+	for _, tt := range tests {
+		if got := loopbackAddr(tt.addr); got != tt.loopback {
+			t.Errorf("loopbackAddr(%q) = %v, want %v", tt.addr, got, tt.loopback)
+		}
+		if got := dashboardHost(tt.addr); got != tt.host {
+			t.Errorf("dashboardHost(%q) = %q, want %q", tt.addr, got, tt.host)
+		}
+	}
+}
+
+func TestObserveDevices(t *testing.T) {
+	s := newTestSession()
+	s.state = session.NewState()
+	s.phone = adb.NewClient("192.168.1.23:5555")
+	s.state.ObservePhone(session.Move{Number: 37, X: 16, Y: 4})
+
+	tests := []struct {
+		devices      []adb.Device
+		disconnected bool
+		device       string
+	}{
+		{[]adb.Device{{Serial: "192.168.1.23:5555", State: "device"}}, false, ""},
+		{nil, true, "手机已断开"},
+		{[]adb.Device{{Serial: "192.168.1.23:5555", State: "offline"}}, true, "手机已断开"},
+		{[]adb.Device{{Serial: "R58M1234", State: "device"}, {Serial: "192.168.1.23:5555", State: "device"}}, false, ""},
+	}
+	for i, tt := range tests {
+		s.observeDevices(tt.devices)
+		if s.disconnected.Load() != tt.disconnected || s.suspended() != tt.disconnected || s.dash.Snapshot().Device != tt.device {
+			t.Errorf("case %d: disconnected = %v, device = %q, want %v, %q", i, s.disconnected.Load(), s.dash.Snapshot().Device, tt.disconnected, tt.device)
+		}
+	}
+	if s.state.Phone().Move != 0 {
+		t.Errorf("重新连上后应清除手机最后一手的记录")
+	}
+}
+
+func TestDriftAlert(t *testing.T) {
+	s := newTestSession()
+	s.cfg.DriftFrames = 3
+	for i := range 10 {
+		s.record.AddMove("B", i, 0)
+	}
+
+	// KaTrain 10 手（观战，按已同步的手数），手机盘面偏离超过 2 手的帧要连续 3 帧才报警，中间一帧正常就重新计数
+	for i, phone := range []int{14, 14, 10, 14, 14} {
+		s.observeDrift(phone, s.katrainMoves())
+		if alert := s.dash.Snapshot().Alert; alert != "" {
+			t.Fatalf("第 %d 帧就报警: %s", i+1, alert)
+		}
+	}
+	s.observeDrift(6, s.katrainMoves())
+	if alert := s.dash.Snapshot().Alert; !strings.Contains(alert, "KaTrain 10 手") {
+		t.Fatalf("连续 3 帧偏离后横幅 = %q", alert)
+	}
+
+	// 刚落下一手、相差不超过阈值时不算偏离，连续 3 帧正常后撤下横幅
+	for _, phone := range []int{11, 10} {
+		s.observeDrift(phone, s.katrainMoves())
+	}
+	if s.dash.Snapshot().Alert == "" {
+		t.Fatal("恢复不足 3 帧就撤下了横幅")
+	}
+	s.observeDrift(12, s.katrainMoves())
+	if alert := s.dash.Snapshot().Alert; alert != "" {
+		t.Errorf("恢复后横幅 = %q, want 空", alert)
+	}
+}
+
+func TestLocalConflict(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSession()
+	s.recordMove(ctx, "B", 3, 3)
+	s.recordMove(ctx, "W", 15, 15)
+
+	tests := []struct {
+		x, y int
+		want string
+	}{
+		{15, 15, "与上一手相同"},
+		{3, 3, "本地棋盘该点已有棋子"},
+		{16, 3, ""},
+	}
+	for _, tt := range tests {
+		if got := s.localConflict(tt.x, tt.y); got != tt.want {
+			t.Errorf("localConflict(%d, %d) = %q, want %q", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestPickCandidate(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSession()
+	s.recordMove(ctx, "B", 3, 3)
+	s.recordMove(ctx, "W", 15, 15)
+
+	// 手机坐标 4-16 对应 (3, 3)，16-4 对应 (15, 15)，17-4 对应 (16, 15)
+	candidates := func(first vision.Candidate, rest ...vision.Candidate) *vision.Result {
+		return &vision.Result{X: first.X, Y: first.Y, Candidates: append([]vision.Candidate{first}, rest...)}
+	}
+	tests := []struct {
+		name   string
+		result *vision.Result
+		want   image.Point
+	}{
+		{"首选已有棋子，改用次选", candidates(vision.Candidate{X: 4, Y: 16, Score: 0.5}, vision.Candidate{X: 17, Y: 4, Score: 0.45}), image.Pt(17, 4)},
+		{"次选得分差得多", candidates(vision.Candidate{X: 4, Y: 16, Score: 0.9}, vision.Candidate{X: 17, Y: 4, Score: 0.1}), image.Pt(4, 16)},
+		{"首选是上一手", candidates(vision.Candidate{X: 16, Y: 4, Score: 0.5}, vision.Candidate{X: 17, Y: 4, Score: 0.5}), image.Pt(16, 4)},
+		{"首选空着", candidates(vision.Candidate{X: 17, Y: 4, Score: 0.5}, vision.Candidate{X: 4, Y: 16, Score: 0.5}), image.Pt(17, 4)},
+		{"候选都已有棋子", candidates(vision.Candidate{X: 4, Y: 16, Score: 0.5}, vision.Candidate{X: 16, Y: 4, Score: 0.5}), image.Pt(4, 16)},
+	}
+	for _, tt := range tests {
+		picked := s.pickCandidate(tt.result)
+		if got := image.Pt(tt.result.X, tt.result.Y); got != tt.want || picked != (got != image.Pt(tt.result.Candidates[0].X, tt.result.Candidates[0].Y)) {
+			t.Errorf("%s: pickCandidate() = %v %v, want %v", tt.name, got, picked, tt.want)
+		}
+	}
+}
+
+func TestSettled(t *testing.T) {
+	s := newTestSession()
+	s.state = session.NewState()
+	var st settleState
+	defer st.close()
+	result := &vision.Result{X: 4, Y: 16, GridIndex: image.Pt(3, 15)}
+	img := gocv.NewMat()
+	defer img.Close()
+
+	if !s.settled(&st, img, result) {
+		t.Errorf("未开启 SettleFrames 时不应等待")
+	}
+
+	s.cfg.SettleFrames = 2
+	if s.settled(&st, img, result) {
+		t.Errorf("新的一手第一帧应等待")
+	}
+	if !s.settled(&st, img, &vision.Result{}) {
+		t.Errorf("没识别出最后一手时不应等待")
+	}
+
+	s.state.SetPhone(session.Last{Move: 37, X: 4, Y: 16})
+	if !s.settled(&st, img, result) {
+		t.Errorf("已处理过的一手不应等待")
+	}
+	if !st.prev.Empty() {
+		t.Errorf("已处理过的一手应释放保存的上一帧")
+	}
+}
+
+func TestAdaptToPhase(t *testing.T) {
+	s := newTestSession()
+	s.cfg.AdaptToPhase = true
+	s.cfg.SettleFrames = 2
+	interval := s.tuned.Load().Interval
+
+	s.updatePhase()
+	if got := s.captureInterval(); got != interval/2 {
+		t.Errorf("布局阶段 captureInterval() = %v, want %v", got, interval/2)
+	}
+	if got := s.settleFrames(); got != 1 {
+		t.Errorf("布局阶段 settleFrames() = %d, want 1", got)
+	}
+
+	// 摆满大半个棋盘，不提子
+	for i := 0; i < 190; i++ {
+		x, y := i%19+1, i/19+1
+		color := "B"
+		if (x+y)%2 == 1 {
+			color = "W"
+		}
+		s.record.AddMove(color, x, y)
+		s.game.Play(x, y, board.ParseColor(color))
+	}
+	s.updatePhase()
+	if got := s.captureInterval(); got != interval {
+		t.Errorf("官子阶段 captureInterval() = %v, want %v", got, interval)
+	}
+	if got := s.settleFrames(); got != 3 {
+		t.Errorf("官子阶段 settleFrames() = %d, want 3", got)
+	}
+	if got := s.dash.Snapshot().Phase; got != "官子" {
+		t.Errorf("看板 Phase = %q, want 官子", got)
+	}
+
+	s.cfg.AdaptToPhase = false
+	if got := s.settleFrames(); got != 2 {
+		t.Errorf("未开启时 settleFrames() = %d, want 2", got)
+	}
+}
+
+func TestUpdateOpening(t *testing.T) {
+	s := newTestSession()
+	s.updateOpening()
+	if got := s.dash.Snapshot().Opening; got != nil {
+		t.Errorf("未开启时 Opening = %v", got)
+	}
+
+	book, err := joseki.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.book = book
+	s.game.Play(15, 15, board.Black)
+	s.game.Play(16, 13, board.White)
+	s.updatePhase()
+	s.updateOpening()
+	got := s.dash.Snapshot().Opening
+	if len(got) != 1 || got[0].Name != "星位 小飞挂" || len(got[0].Next) == 0 {
+		t.Fatalf("Opening = %+v", got)
+	}
+
+	s.phase.Store(int32(board.Middlegame))
+	s.updateOpening()
+	if got := s.dash.Snapshot().Opening; len(got) != 0 {
+		t.Errorf("布局阶段之后 Opening = %+v, want 空", got)
+	}
+}
+
+func TestRecordStats(t *testing.T) {
+	s := newTestSession()
+	path := filepath.Join(t.TempDir(), "stats.json")
+	img := gocv.NewMat()
+	defer img.Close()
+
+	// 未开启时不记录
+	s.recordStats(img, &vision.Result{X: 4, Y: 16})
+
+	s.stats = stats.NewRecorder(path)
+	s.recordStats(img, &vision.Result{X: 4, Y: 16, Debug: vision.DebugInfo{Skin: "dark", Lighting: "bright"}})
+	s.recordStats(img, &vision.Result{Debug: vision.DebugInfo{Skin: "dark", Lighting: "bright"}})
+	s.recordStats(img, &vision.Result{Debug: vision.DebugInfo{FinalStatus: "failed_at_warp"}})
+	if err := s.stats.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := stats.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("rows = %+v, want 2 组", rows)
+	}
+	if rows[0].Skin != "-" || rows[0].Lighting != "-" || rows[0].Failed != 1 {
+		t.Errorf("识别详情中没有皮肤与光线时 = %+v", rows[0])
+	}
+	if rows[1].Skin != "dark" || rows[1].Lighting != "bright" || rows[1].OK != 1 || rows[1].Failed != 1 {
+		t.Errorf("dark 组 = %+v", rows[1])
+	}
+}
+
+func TestApplyAnalysisBudget(t *testing.T) {
+	ctx := context.Background()
+	katrain := katraintest.NewServer()
+	defer katrain.Close()
+	s := newTestSession()
+	s.target = target.NewKaTrain(katrain.URL)
+
+	// 未配置时不设置
+	s.applyAnalysisBudget(ctx)
+	if _, ok := katrain.Budget(); ok {
+		t.Error("未配置分析计算量时不应调用 /api/analysis-settings")
+	}
+
+	s.cfg.AnalysisVisits, s.cfg.AnalysisTime = 200, 1500*time.Millisecond
+	s.applyAnalysisBudget(ctx)
+	if budget, ok := katrain.Budget(); !ok || budget.MaxVisits != 200 || budget.MaxTime != 1.5 {
+		t.Errorf("KaTrain 收到的分析计算量 = %+v, %v", budget, ok)
+	}
+}
+
+func TestSupervise(t *testing.T) {
+	ctx := context.Background()
+	defer func(b time.Duration) { loopMinBackoff = b }(loopMinBackoff)
+	loopMinBackoff = time.Millisecond
+	s := newTestSession()
+
+	runs := 0
+	s.supervise(context.Background(), "手机 → KaTrain", func(context.Context) {
+		runs++
+		if runs <= 2 {
+			var m map[string]int
+			m["x"]++ // 写入 nil map
+		}
+	})
+	if runs != 3 {
+		t.Errorf("runs = %d, want 3（崩溃两次后重启，第三次正常返回）", runs)
+	}
+	if got := s.dash.Snapshot().LoopRestarts["手机 → KaTrain"]; got != 2 {
+		t.Errorf("LoopRestarts = %d, want 2", got)
+	}
+
+	// ctx 取消后不再重启
+	ctx, cancel := context.WithCancel(context.Background())
+	runs = 0
+	s.supervise(ctx, "KaTrain → 手机", func(context.Context) {
+		runs++
+		cancel()
+		panic("boom")
+	})
+	if runs != 1 {
+		t.Errorf("ctx 取消后 runs = %d, want 1", runs)
+	}
+}
+
+// imageSource 每次 Grab 都返回同一张图片
+type imageSource struct {
+	img image.Image
+}
+
+func (s imageSource) Grab(context.Context) (gocv.Mat, error) { return synth.ToMat(s.img) }
+func (imageSource) Close() error                             { return nil }
+
+func TestHandoffScoring(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSession()
+	if err := s.HandoffScoring(ctx); err == nil {
+		t.Error("HandoffScoring() 在没有棋步时应返回错误")
+	}
+
+	srv := katraintest.NewServer()
+	defer srv.Close()
+	s.target = target.NewKaTrain(srv.URL)
+
+	// 黑地里一颗白死子
+	var scene synth.Scene
+	for y := 0; y < 19; y++ {
+		scene.Board.Set(9, y, board.Black)
+		scene.Board.Set(10, y, board.White)
+	}
+	scene.Board.Set(3, 3, board.White)
+	scene.Dead = []image.Point{{3, 3}}
+	for x := range 19 {
+		for y := range 19 {
+			if c := scene.Board.At(x, y); c != board.Empty {
+				s.game.Play(x, y, c)
+				srv.Play(x, y, c.String())
+				s.record.AddMove(c.String(), x, y)
+			}
+		}
+	}
+
+	s.source = imageSource{synth.Render(scene, synth.DefaultStyle())}
+	if img, err := s.source.Grab(ctx); err != nil || img.Empty() {
+		t.Skip("OpenCV 不可用")
+	} else {
+		img.Close()
+	}
+
+	if err := s.HandoffScoring(ctx); err != nil {
+		t.Fatalf("HandoffScoring() error = %v", err)
+	}
+	if dead, ok := srv.Dead(); !ok || !slices.Equal(dead, scene.Dead) {
+		t.Errorf("KaTrain 收到的死子 = %v, %v, want %v", dead, ok, scene.Dead)
+	}
+	if e := s.score; e == nil || e.BlackTerritory != 9*19 {
+		t.Errorf("形势判断 = %v, want 黑地 %d", e, 9*19)
+	}
+	if got := s.record.LastMove().Get("DD"); !slices.Equal(got, []string{"dp"}) {
+		t.Errorf("棋谱 DD = %v, want [dp]", got)
+	}
+	if got := s.record.Root("RE"); !slices.Equal(got, []string{"B+11.5"}) {
+		t.Errorf("棋谱 RE = %v, want [B+11.5]", got)
+	}
+}
+
+func TestLoadPosition(t *testing.T) {
+	ctx := context.Background()
+	p := target.Position{
+		Black:      []image.Point{{3, 3}, {15, 15}},
+		White:      []image.Point{{15, 3}, {3, 15}},
+		Next:       "B",
+		MoveNumber: 6,
+	}
+	last := &image.Point{X: 15, Y: 3}
+
+	srv := katraintest.NewServer()
+	defer srv.Close()
+	s := newTestSession()
+	s.target = target.NewKaTrain(srv.URL)
+	m, err := s.loadPosition(ctx, p, last)
+	if err != nil {
+		t.Fatalf("loadPosition() error = %v", err)
+	}
+	if got, ok := srv.Position(); !ok || got.Next != "B" || got.MoveNumber != 6 || len(got.Black) != 2 || len(got.White) != 2 {
+		t.Errorf("KaTrain 摆上的局面 = %+v, %v", got, ok)
+	}
+	if m != (target.Move{}) || len(srv.Moves()) != 0 {
+		t.Errorf("直接摆子时不应有棋步: %+v, %v", m, srv.Moves())
+	}
+
+	// 旧版补丁没有摆子接口时逐个落子，最后一手与手机一致
+	old := katraintest.NewServer()
+	defer old.Close()
+	old.Disable(katrain.PathSetupPosition)
+	k := target.NewKaTrain(old.URL)
+	k.Probe(ctx)
+	s.target = k
+	m, err = s.loadPosition(ctx, p, last)
+	if err != nil {
+		t.Fatalf("逐个落子 loadPosition() error = %v", err)
+	}
+	moves := old.Moves()
+	if len(moves) != 4 || moves[3] != (katraintest.Move{X: 15, Y: 3, Player: "W"}) {
+		t.Errorf("逐个落子 = %v, want 4 手，最后为白 Q4", moves)
+	}
+	if m != (target.Move{X: 15, Y: 3, Color: "W", Number: 4}) {
+		t.Errorf("loadPosition() 最后一手 = %+v", m)
+	}
+}
+
+func TestAttach(t *testing.T) {
+	ctx := context.Background()
+	srv := katraintest.NewServer()
+	defer srv.Close()
+	s := newTestSession()
+	s.state = session.NewState()
+	s.target = target.NewKaTrain(srv.URL)
+
+	// 黑先，双方各两手，白 Q4 为最后一手
+	var scene synth.Scene
+	scene.Board.Set(3, 3, board.Black)
+	scene.Board.Set(15, 15, board.Black)
+	scene.Board.Set(3, 15, board.White)
+	scene.Board.Set(15, 3, board.White)
+	scene.Last = &image.Point{X: 15, Y: 3}
+	scene.MoveNumber = 4
+	s.source = imageSource{synth.Render(scene, synth.DefaultStyle())}
+	if img, err := s.source.Grab(ctx); err != nil || img.Empty() {
+		t.Skip("OpenCV 不可用")
+	} else {
+		img.Close()
+	}
+
+	if err := s.attach(ctx); err != nil {
+		t.Fatalf("attach() error = %v", err)
+	}
+	p, ok := srv.Position()
+	if !ok || p.Next != "B" || len(p.Black) != 2 || len(p.White) != 2 {
+		t.Fatalf("KaTrain 摆上的局面 = %+v, %v, want 黑 2 子白 2 子，轮到黑", p, ok)
+	}
+	if b := s.game.Board(); b != scene.Board {
+		t.Error("本地棋盘应为手机上的局面")
+	}
+	if got := s.record.Root("PL"); !slices.Equal(got, []string{"B"}) {
+		t.Errorf("棋谱 PL = %v, want [B]", got)
+	}
+	if got := s.record.Root("AW"); len(got) != 2 {
+		t.Errorf("棋谱 AW = %v, want 2 子", got)
+	}
+	// 手机上的最后一手已同步，不会再当作新手
+	if x, y := s.boardToPhone(15, 3); s.state.Phone().X != x || s.state.Phone().Y != y {
+		t.Errorf("手机方向最后一手 = %+v, want Q4 (%d, %d)", s.state.Phone(), x, y)
+	}
+}
+
+func TestMoveArchive(t *testing.T) {
+	var disabled *moveArchive
+	disabled.hold(gocv.NewMat())
+	if path, err := disabled.save(&vision.Result{Move: 1, X: 4, Y: 16, Color: "B"}); path != "" || err != nil {
+		t.Errorf("未开启时 save() = %q, %v", path, err)
+	}
+
+	scene := synth.Scene{MoveNumber: 37}
+	scene.Board.Set(9, 9, board.Black)
+	scene.Last = &image.Point{X: 9, Y: 9}
+	img, err := synth.ToMat(synth.Render(scene, synth.DefaultStyle()))
+	if err != nil || img.Empty() {
+		t.Skip("OpenCV 不可用")
+	}
+	defer img.Close()
+
+	dir := t.TempDir()
+	a := newMoveArchive(dir)
+	defer a.close()
+	a.hold(img)
+	result := &vision.Result{Move: 37, X: 10, Y: 10, Color: "B"}
+	path, err := a.save(result)
+	if err != nil || path != filepath.Join(dir, "037-J10-B.jpg") {
+		t.Fatalf("save() = %q, %v", path, err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("样本截图没有写出: %v", err)
+	}
+
+	// 同一手只保存一次，手数未知时不保存
+	if path, _ := a.save(result); path != "" {
+		t.Errorf("重复保存第 37 手: %q", path)
+	}
+	if path, _ := a.save(&vision.Result{X: 4, Y: 16, Color: "W"}); path != "" {
+		t.Errorf("手数未知时不应保存: %q", path)
+	}
+}
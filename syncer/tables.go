@@ -0,0 +1,227 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"goboardsync/adb"
+	"goboardsync/board"
+	"goboardsync/dashboard"
+	"goboardsync/ocr"
+	"goboardsync/session"
+	"goboardsync/sgf"
+)
+
+// table 多桌轮换中的一桌。切走时保存这一桌的棋谱、用时、计时与整盘局面，切回来时恢复，
+// 第一次切到时都为空
+type table struct {
+	name string
+	// flow 从其他桌切换到这一桌的操作流程
+	flow adb.Flow
+	// katrain 这一桌使用的 KaTrain 实例（Session.tableKatrains 中的序号，见 TableKatrains），
+	// ownKatrain 表示该实例只有这一桌使用，切回来时不必清空重放
+	katrain    int
+	ownKatrain bool
+
+	record    *sgf.Game
+	timer     moveTimer
+	clocks    map[string]ocr.Clock
+	score     *board.Estimate
+	scoredAt  session.Last
+	spectated *board.Tracker
+}
+
+// parseTables 解析 Config.Tables：以 | 分隔的多桌，每桌为 "名字=切换流程"，流程格式见 adb.ParseFlow，
+// 如 "A=tap 100,300; wait 2s | B=tap 400,300; wait 2s"
+func parseTables(s string) ([]*table, error) {
+	var tables []*table
+	for _, part := range strings.Split(s, "|") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("多桌配置应为 名字=切换流程: %q", strings.TrimSpace(part))
+		}
+		flow, err := adb.ParseFlow(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s 的切换流程配置错误: %v", name, err)
+		}
+		if len(flow) == 0 {
+			return nil, fmt.Errorf("%s 没有切换流程", name)
+		}
+		for _, t := range tables {
+			if t.name == name {
+				return nil, fmt.Errorf("多桌名字重复: %s", name)
+			}
+		}
+		tables = append(tables, &table{name: name, flow: flow})
+	}
+	if len(tables) == 1 {
+		return nil, fmt.Errorf("多桌轮换至少需要两桌")
+	}
+	return tables, nil
+}
+
+// parseTableKatrains 解析 Config.TableKatrains：以 | 分隔的 "名字=KaTrain 地址"，设置各桌使用的实例，
+// 返回各实例的地址。第一个实例为 base（KatrainURL），没有单独配置的桌共用它；地址相同的桌共用一个实例
+func parseTableKatrains(tables []*table, s, base string) ([]string, error) {
+	urls := []string{base}
+	for _, part := range strings.Split(s, "|") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, url, _ := strings.Cut(part, "=")
+		name, url = strings.TrimSpace(name), strings.TrimSpace(url)
+		if name == "" || url == "" {
+			return nil, fmt.Errorf("各桌的 KaTrain 配置应为 名字=地址: %q", strings.TrimSpace(part))
+		}
+		i := slices.IndexFunc(tables, func(t *table) bool { return t.name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("KaTrain 配置中的 %s 不在多桌配置中", name)
+		}
+		idx := slices.Index(urls, url)
+		if idx < 0 {
+			idx = len(urls)
+			urls = append(urls, url)
+		}
+		tables[i].katrain = idx
+	}
+
+	users := make([]int, len(urls))
+	for _, t := range tables {
+		users[t.katrain]++
+	}
+	for _, t := range tables {
+		t.ownKatrain = users[t.katrain] == 1
+	}
+	return urls, nil
+}
+
+// currentTable 返回当前桌的名字，没有配置多桌时为空。调用方需持有 s.mu
+func (s *Session) currentTable() string {
+	if s.tableIdx < 0 || s.tableIdx >= len(s.tables) {
+		return ""
+	}
+	return s.tables[s.tableIdx].name
+}
+
+// tableDue 是否该切换到下一桌，只由 syncPhoneToKatrain 调用
+func (s *Session) tableDue() bool {
+	return len(s.tables) > 0 && (s.tableIdx < 0 || time.Since(s.tableSince) >= s.cfg.TableDwell)
+}
+
+// switchTable 执行下一桌的切换流程，保存当前桌的状态并换上下一桌的，再把 KaTrain 的棋盘换成下一桌的棋谱。
+// 切换失败时留在当前桌，停留 TableDwell 后再试。只由 syncPhoneToKatrain 调用，与识别不会同时进行
+func (s *Session) switchTable(ctx context.Context) {
+	next := (s.tableIdx + 1) % len(s.tables)
+	t := s.tables[next]
+	s.tableSince = time.Now()
+
+	if err := s.phone.RunFlow(ctx, t.flow); err != nil {
+		fmt.Printf("[%s] ❌ 切换到对局 %s 失败: %v\n", time.Now().Format("15:04:05"), t.name, err)
+		s.reportError("切换对局", err)
+		return
+	}
+	s.reportOK("切换对局")
+
+	if s.tableIdx >= 0 {
+		s.stashTable(s.tables[s.tableIdx])
+	}
+	first := t.record == nil
+	moves := s.loadTable(next)
+
+	fmt.Printf("[%s] 🔀 切换到对局 %s（已同步 %d 手）\n", time.Now().Format("15:04:05"), t.name, len(moves))
+	s.dash.Update(func(st *dashboard.Status) {
+		st.Table = t.name
+		st.Timing = nil
+		st.Score = nil
+		st.BlackClock, st.WhiteClock = nil, nil
+	})
+	if s.tableKatrains != nil {
+		s.tableKatrains.Select(t.katrain)
+	}
+	// 这一桌独占的 KaTrain 一直显示它的棋盘，只在第一次切到时清空
+	if first || !t.ownKatrain {
+		s.replayTarget(ctx, moves)
+	}
+}
+
+// stashTable 把当前的同步状态保存到 t
+func (s *Session) stashTable(t *table) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t.record, t.timer, t.clocks = s.record, s.timer, s.clocks
+	t.score, t.scoredAt = s.score, s.scoredAt
+	t.spectated = s.spectated
+}
+
+// loadTable 换上第 i 桌保存的同步状态（第一次切到时为空），按棋谱重放对局，返回已同步的棋步。
+// 双方最后一手重新比较，切回来后第一次识别到的最后一手 KaTrain 上已经有了，不会重复同步
+func (s *Session) loadTable(i int) []*sgf.Node {
+	t := s.tables[i]
+	if t.record == nil {
+		t.record = sgf.NewGame()
+		t.clocks = make(map[string]ocr.Clock)
+		t.spectated = board.NewTracker(2)
+	}
+
+	s.mu.Lock()
+	s.tableIdx = i
+	s.record, s.timer, s.clocks = t.record, t.timer, t.clocks
+	s.score, s.scoredAt = t.score, t.scoredAt
+	s.spectated = t.spectated
+	moves := append([]*sgf.Node(nil), s.record.Nodes...)
+	s.game.Reset()
+	for _, n := range moves {
+		s.game.Play(n.X, n.Y, board.ParseColor(n.Color))
+	}
+	s.mu.Unlock()
+
+	s.state.Reset()
+	s.detector.ResetSmoothing()
+	s.updatePhase()
+	s.updateOpening()
+	s.overlayChanged()
+	return moves
+}
+
+// replayTarget 清空 KaTrain 棋盘并按顺序摆上 moves
+func (s *Session) replayTarget(ctx context.Context, moves []*sgf.Node) {
+	if err := s.target.Reset(ctx); err != nil {
+		fmt.Printf("[%s] ❌ 清空 KaTrain 棋盘失败: %v\n", time.Now().Format("15:04:05"), err)
+		s.reportError("KaTrain 落子", err)
+		return
+	}
+	for _, n := range moves {
+		if err := s.target.Play(ctx, n.X, n.Y, n.Color); err != nil {
+			fmt.Printf("[%s] ❌ 重放棋谱失败: %v\n", time.Now().Format("15:04:05"), err)
+			s.reportError("KaTrain 落子", err)
+			return
+		}
+	}
+	s.reportOK("KaTrain 落子")
+}
+
+// saveTables 保存切走的各桌棋谱（当前桌由 SaveRecord 保存），文件名带桌名
+func (s *Session) saveTables() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i, t := range s.tables {
+		if i == s.tableIdx || t.record == nil || len(t.record.Nodes) == 0 {
+			continue
+		}
+		path := filepath.Join(s.cfg.RecordDir, fmt.Sprintf("game_%s_%s.sgf", time.Now().Format("20060102_150405"), t.name))
+		if err := t.record.WriteFile(path); err != nil {
+			fmt.Printf("[%s] ❌ 保存对局 %s 的棋谱失败: %v\n", time.Now().Format("15:04:05"), t.name, err)
+			continue
+		}
+		fmt.Printf("[%s] 💾 对局 %s 的棋谱已保存: %s\n", time.Now().Format("15:04:05"), t.name, path)
+	}
+}
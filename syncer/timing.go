@@ -0,0 +1,57 @@
+package syncer
+
+import (
+	"strconv"
+	"time"
+
+	"goboardsync/dashboard"
+)
+
+// moveTimer 根据同步到每手棋的时刻估算双方用时：一手棋的用时为它与上一手之间的间隔，记在落子方名下。
+// 真正的计时在手机 App 上，这里包含截图识别与同步的延迟；程序可能在对局中途启动，第一手不计用时
+type moveTimer struct {
+	last  time.Time
+	spent time.Duration
+	used  map[string]time.Duration
+}
+
+// move 记录 color 方在 at 时刻落下一手，返回这一手的用时
+func (t *moveTimer) move(color string, at time.Time) time.Duration {
+	var spent time.Duration
+	if !t.last.IsZero() && at.After(t.last) {
+		spent = at.Sub(t.last)
+	}
+	if t.used == nil {
+		t.used = make(map[string]time.Duration)
+	}
+	t.used[color] += spent
+	t.last, t.spent = at, spent
+	return spent
+}
+
+// status 返回看板展示的用时，还没有落子时为 nil
+func (t *moveTimer) status() *dashboard.Timing {
+	if t.last.IsZero() {
+		return nil
+	}
+	return &dashboard.Timing{
+		BlackSeconds:    int(t.used["B"].Seconds()),
+		WhiteSeconds:    int(t.used["W"].Seconds()),
+		LastMoveSeconds: int(t.spent.Seconds()),
+		LastMoveAt:      t.last,
+	}
+}
+
+// timeLeft 根据棋谱的 TM（包干时间，秒）与 color 方的累计用时估算剩余时间，没有 TM 时返回 false。
+// 调用方需持有 s.mu
+func (s *Session) timeLeft(color string) (time.Duration, bool) {
+	tm := s.record.Root("TM")
+	if len(tm) == 0 {
+		return 0, false
+	}
+	sec, err := strconv.ParseFloat(tm[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	return max(time.Duration(sec*float64(time.Second))-s.timer.used[color], 0), true
+}
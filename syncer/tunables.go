@@ -0,0 +1,145 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"time"
+
+	"goboardsync/config"
+	"goboardsync/vision"
+)
+
+// Tunables 运行中可通过参数文件热更新的参数。每次重新加载都替换整个结构体，
+// 各协程通过 tuned.Load() 读到的总是同一份完整配置
+type Tunables struct {
+	// Interval 截图识别间隔，PollInterval KaTrain 轮询间隔
+	Interval     time.Duration
+	PollInterval time.Duration
+	// Geometry 竖屏时的点击位置，Landscape 横屏（截图宽大于高）时的点击位置
+	Geometry
+	Landscape Geometry
+	// TapDelay 两次点击之间的等待时间。ConfirmTimeout 大于 0 时改为截图确认落子指示标出现后再点确认，
+	// 最多等待 ConfirmTimeout，未出现时重新点击落子点，最多重试 TapRetries 次；无法截图比较时仍等待 TapDelay
+	TapDelay       time.Duration
+	ConfirmTimeout time.Duration
+	TapRetries     int
+	Vision         vision.Tuning
+}
+
+// Geometry 一种屏幕方向下的点击位置，均为缩放到目标分辨率后的屏幕坐标
+type Geometry struct {
+	// 手机棋盘 A 线、第 1 线交叉点中心的屏幕坐标与线间距
+	BoardStartX float64
+	BoardStartY float64
+	BoardGap    float64
+	// 落子后“确认”按钮的屏幕坐标
+	ConfirmX int
+	ConfirmY int
+}
+
+// Board 返回棋盘在屏幕上的范围：四边交叉点再向外各半个线间距。线间距不大于 0 时为空
+func (g Geometry) Board() image.Rectangle {
+	if g.BoardGap <= 0 {
+		return image.Rectangle{}
+	}
+	half := g.BoardGap / 2
+	return image.Rect(
+		int(g.BoardStartX-half), int(g.BoardStartY-half),
+		int(g.BoardStartX+18*g.BoardGap+half)+1, int(g.BoardStartY+18*g.BoardGap+half)+1,
+	)
+}
+
+// DefaultTunables 返回针对 1200x2670 腾讯围棋 App 的默认参数，横屏时棋盘在左侧、确认按钮在右下方
+func DefaultTunables() Tunables {
+	return Tunables{
+		Interval:     100 * time.Millisecond,
+		PollInterval: 300 * time.Millisecond,
+		Geometry: Geometry{
+			BoardStartX: 60,
+			BoardStartY: 560,
+			BoardGap:    60,
+			ConfirmX:    600,
+			ConfirmY:    2150,
+		},
+		Landscape: Geometry{
+			BoardStartX: 60,
+			BoardStartY: 60,
+			BoardGap:    60,
+			ConfirmX:    1950,
+			ConfirmY:    1050,
+		},
+		TapDelay:       300 * time.Millisecond,
+		ConfirmTimeout: 1500 * time.Millisecond,
+		TapRetries:     1,
+	}
+}
+
+// Fields 返回参数文件（及环境变量）中各键对应的字段
+func (t *Tunables) Fields() map[string]any {
+	return map[string]any{
+		"INTERVAL":        &t.Interval,
+		"POLL_INTERVAL":   &t.PollInterval,
+		"BOARD_START_X":   &t.BoardStartX,
+		"BOARD_START_Y":   &t.BoardStartY,
+		"BOARD_GAP":       &t.BoardGap,
+		"CONFIRM_X":       &t.ConfirmX,
+		"CONFIRM_Y":       &t.ConfirmY,
+		"TAP_DELAY":       &t.TapDelay,
+		"CONFIRM_TIMEOUT": &t.ConfirmTimeout,
+		"TAP_RETRIES":     &t.TapRetries,
+
+		"LANDSCAPE_BOARD_START_X": &t.Landscape.BoardStartX,
+		"LANDSCAPE_BOARD_START_Y": &t.Landscape.BoardStartY,
+		"LANDSCAPE_BOARD_GAP":     &t.Landscape.BoardGap,
+		"LANDSCAPE_CONFIRM_X":     &t.Landscape.ConfirmX,
+		"LANDSCAPE_CONFIRM_Y":     &t.Landscape.ConfirmY,
+		"MARKER_MIN_S":            &t.Vision.MarkerMinS,
+		"MARKER_MIN_V":            &t.Vision.MarkerMinV,
+	}
+}
+
+// ReloadConfig 读取参数文件，全部解析成功后才整体替换当前参数，并打印变化的项
+func (s *Session) ReloadConfig(path string) error {
+	next := *s.tuned.Load()
+	changes, err := config.LoadFile(path, next.Fields())
+	if err != nil {
+		return err
+	}
+	if next.Interval <= 0 || next.PollInterval <= 0 {
+		return fmt.Errorf("%s: 轮询间隔必须大于 0", path)
+	}
+
+	s.tuned.Store(&next)
+	s.detector.SetTuning(next.Vision)
+	for _, c := range changes {
+		fmt.Printf("[%s] ⚙️  参数已更新 %s\n", time.Now().Format("15:04:05"), c)
+	}
+	return nil
+}
+
+// watchConfig 参数文件变化时重新加载，出错时保留原参数
+func (s *Session) watchConfig(ctx context.Context, path string) {
+	config.Watch(ctx, path, time.Second, func() {
+		if err := s.ReloadConfig(path); err != nil {
+			fmt.Printf("[%s] ⚠️  参数未更新: %v\n", time.Now().Format("15:04:05"), err)
+		}
+	})
+}
+
+// geometry 返回当前屏幕方向下的点击位置，方向按最近一次截图的宽高判断
+func (s *Session) geometry() Geometry {
+	t := s.tuned.Load()
+	if s.landscape.Load() {
+		return t.Landscape
+	}
+	return t.Geometry
+}
+
+// retune 轮询间隔被修改后重置 ticker
+func retune(ticker *time.Ticker, current *time.Duration, next time.Duration) {
+	if next != *current {
+		ticker.Reset(next)
+		*current = next
+	}
+}
@@ -0,0 +1,88 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"goboardsync/dashboard"
+	"goboardsync/uistate"
+)
+
+// uiStateMaxAge 界面状态中的手数在读取后多久内可以代替 OCR，超过后可能已经又下了几手
+const uiStateMaxAge = 3 * time.Second
+
+// watchUIState 每隔 UIDumpInterval 用 uiautomator dump 读取一次界面状态，直到 ctx 取消：
+// 前台界面变化与弹出的对话框打印到日志并显示在看板，手数在 OCR 失败时使用（见 fetchMoveNumber）
+func (s *Session) watchUIState(ctx context.Context) {
+	reader := &uistate.Reader{Phone: s.phone, MoveNumberID: s.cfg.UIMoveNumberID, Patterns: s.detector.MoveNumberPatterns}
+	ticker := time.NewTicker(s.cfg.UIDumpInterval)
+	defer ticker.Stop()
+
+	// 只在开始出错时打印一次，之后由 reportError 持续出错时提醒
+	failing := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if s.disconnected.Load() || s.deviceAway.Load() {
+			continue
+		}
+
+		st, err := reader.Read(ctx)
+		if err != nil {
+			if !failing {
+				fmt.Printf("[%s] ⚠️  读取界面状态失败: %v\n", time.Now().Format("15:04:05"), err)
+			}
+			failing = true
+			s.reportError("界面状态", err)
+			continue
+		}
+		failing = false
+		s.reportOK("界面状态")
+		s.updateUIState(st)
+	}
+}
+
+// updateUIState 记下最新的界面状态，前台界面变化时打印日志
+func (s *Session) updateUIState(st *uistate.State) {
+	if prev := s.ui.Swap(st); st.Activity != "" && (prev == nil || prev.Activity != st.Activity) {
+		fmt.Printf("[%s] 📱 前台界面: %s\n", time.Now().Format("15:04:05"), st.Activity)
+	}
+	s.dash.Update(func(ds *dashboard.Status) { ds.Activity = st.Activity })
+	s.showDialog(st.Dialog)
+}
+
+// uiMoveNumber 返回最近读到的界面状态中的手数，没有或已过时时返回 0
+func (s *Session) uiMoveNumber() int {
+	st := s.ui.Load()
+	if st == nil || time.Since(st.Time) > uiStateMaxAge {
+		return 0
+	}
+	return st.MoveNumber
+}
+
+// showDialog App 弹出对话框（如申请数子、对局结束）时打印其中的文字与按钮并显示在看板，同一个对话框只打印一次
+func (s *Session) showDialog(d *uistate.Dialog) {
+	var text string
+	if d != nil {
+		text = strings.Join(d.Texts, " ")
+		var buttons []string
+		for _, b := range d.Buttons {
+			buttons = append(buttons, fmt.Sprintf("%s (%d, %d)", b.Text, b.X, b.Y))
+		}
+		if len(buttons) > 0 {
+			text += "，按钮: " + strings.Join(buttons, "、")
+		}
+	}
+	if prev := s.dialog.Swap(&text); prev == nil || *prev != text {
+		if text != "" {
+			fmt.Printf("[%s] 💬 App 弹出对话框: %s\n", time.Now().Format("15:04:05"), text)
+		}
+		s.dash.Update(func(st *dashboard.Status) { st.Dialog = text })
+	}
+}
@@ -0,0 +1,69 @@
+package syncer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+	"time"
+
+	"goboardsync/vision"
+
+	"gocv.io/x/gocv"
+)
+
+// videoRecorder 把每帧的识别叠加图编码为 MP4，用于回看整局的识别过程或附在问题报告中。
+// 截图间隔不固定，视频按固定帧率播放，每帧左下角写上截图时间
+type videoRecorder struct {
+	mu     sync.Mutex
+	path   string
+	writer *gocv.VideoWriter
+}
+
+func newVideoRecorder(path string, fps float64) (*videoRecorder, error) {
+	writer, err := gocv.VideoWriterFile(path, "mp4v", fps, vision.BoardWarpSize, vision.BoardWarpSize, true)
+	if err != nil {
+		return nil, fmt.Errorf("创建录像失败: %v", err)
+	}
+	if !writer.IsOpened() {
+		writer.Close()
+		return nil, fmt.Errorf("创建录像失败: OpenCV 无法以 mp4v 编码写入 %s", path)
+	}
+	return &videoRecorder{path: path, writer: writer}, nil
+}
+
+// write 写入一帧叠加图；v 为 nil（未开启）或尺寸不是 BoardWarpSize 见方时跳过
+func (v *videoRecorder) write(overlay gocv.Mat) {
+	if v == nil || overlay.Cols() != vision.BoardWarpSize || overlay.Rows() != vision.BoardWarpSize {
+		return
+	}
+
+	frame := overlay.Clone()
+	defer frame.Close()
+	gocv.PutText(&frame, time.Now().Format("15:04:05.000"), image.Pt(20, vision.BoardWarpSize-30),
+		gocv.FontHersheySimplex, 1, color.RGBA{255, 255, 255, 0}, 2)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.writer == nil {
+		return
+	}
+	if err := v.writer.Write(frame); err != nil {
+		fmt.Printf("[%s] ⚠️  写入录像失败: %v\n", time.Now().Format("15:04:05"), err)
+	}
+}
+
+// close 结束录像；之后的 write 不再写入
+func (v *videoRecorder) close() error {
+	if v == nil {
+		return nil
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.writer == nil {
+		return nil
+	}
+	err := v.writer.Close()
+	v.writer = nil
+	return err
+}
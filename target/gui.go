@@ -0,0 +1,100 @@
+package target
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sync"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+)
+
+// GUI 激活 KaTrain 窗口并用键盘输入 GTP 坐标落子，适用于未打补丁、没有 HTTP API 的 KaTrain。
+// 窗口无法查询局面，HasStone 使用本地按规则落子（含提子）记录的棋盘；不支持读取最后一手，因此只能单向同步。
+// 键盘输入调用系统工具：macOS 使用 osascript，Linux 使用 xdotool
+type GUI struct {
+	WindowTitle string
+
+	mu    sync.Mutex
+	board board.Board
+	run   func(ctx context.Context, name string, args ...string) error
+}
+
+func NewGUI(windowTitle string) *GUI {
+	return &GUI{
+		WindowTitle: windowTitle,
+		run: func(ctx context.Context, name string, args ...string) error {
+			return exec.CommandContext(ctx, name, args...).Run()
+		},
+	}
+}
+
+func (g *GUI) Name() string {
+	return fmt.Sprintf("KaTrain 窗口 (%s)", g.WindowTitle)
+}
+
+// Reset 只清空本地记录的棋盘，KaTrain 窗口需要手动新建对局
+func (g *GUI) Reset(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.board = board.Board{}
+	return nil
+}
+
+func (g *GUI) HasStone(ctx context.Context, x, y int) (bool, error) {
+	if !coords.Valid(x, y) {
+		return false, fmt.Errorf("坐标超出棋盘: (%d,%d)", x, y)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.board.At(x, y) != board.Empty, nil
+}
+
+func (g *GUI) Play(ctx context.Context, x, y int, color string) error {
+	if !coords.Valid(x, y) {
+		return fmt.Errorf("坐标超出棋盘: (%d,%d)", x, y)
+	}
+	c := board.ParseColor(color)
+	if c == board.Empty {
+		return fmt.Errorf("玩家颜色必须是 B 或 W: %s", color)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// 先在副本上按规则落子，提掉的棋子随之移除，不合法（已有棋子、自杀）时不发送键盘输入
+	next := g.board
+	if _, err := next.Play(x, y, c); err != nil {
+		return err
+	}
+
+	if err := g.typeMove(ctx, coords.Format(x, y, coords.GTP)); err != nil {
+		return fmt.Errorf("键盘输入失败: %v", err)
+	}
+
+	g.board = next
+	return nil
+}
+
+// typeMove 激活 KaTrain 窗口，输入坐标并回车
+func (g *GUI) typeMove(ctx context.Context, move string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf(`tell application "System Events"
+	set frontmost of (first process whose name contains %q) to true
+	keystroke %q
+	key code 36
+end tell`, g.WindowTitle, move)
+		return g.run(ctx, "osascript", "-e", script)
+
+	case "linux":
+		if err := g.run(ctx, "xdotool", "search", "--name", g.WindowTitle, "windowactivate", "--sync"); err != nil {
+			return fmt.Errorf("激活窗口失败: %v", err)
+		}
+		return g.run(ctx, "xdotool", "type", "--delay", "50", move+"\n")
+	}
+	return fmt.Errorf("当前系统不支持键盘输入: %s", runtime.GOOS)
+}
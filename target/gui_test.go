@@ -0,0 +1,79 @@
+package target
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestGUIPlay(t *testing.T) {
+	if runtime.GOOS != "darwin" && runtime.GOOS != "linux" {
+		t.Skip("当前系统不支持键盘输入")
+	}
+
+	ctx := context.Background()
+	var commands []string
+	g := NewGUI("KaTrain")
+	g.run = func(ctx context.Context, name string, args ...string) error {
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		return nil
+	}
+
+	if err := g.Play(ctx, 3, 15, "B"); err != nil {
+		t.Fatalf("Play(3, 15, B) unexpected error: %v", err)
+	}
+	if len(commands) == 0 || !strings.Contains(strings.Join(commands, "\n"), "D16") {
+		t.Errorf("Play(3, 15, B) 未输入 D16: %v", commands)
+	}
+
+	hasStone, err := g.HasStone(ctx, 3, 15)
+	if err != nil || !hasStone {
+		t.Errorf("HasStone(3, 15) = %v, %v, want true", hasStone, err)
+	}
+
+	tests := []struct {
+		name  string
+		x, y  int
+		color string
+	}{
+		{"已有棋子", 3, 15, "W"},
+		{"超出棋盘", 19, 0, "W"},
+		{"负坐标", -1, 5, "B"},
+		{"无效颜色", 5, 5, "X"},
+	}
+
+	for _, tt := range tests {
+		commands = nil
+		if err := g.Play(ctx, tt.x, tt.y, tt.color); err == nil {
+			t.Errorf("%s: Play(%d, %d, %s) expected error, got nil", tt.name, tt.x, tt.y, tt.color)
+		}
+		if len(commands) != 0 {
+			t.Errorf("%s: 校验失败时不应发送键盘输入: %v", tt.name, commands)
+		}
+	}
+
+	// 提掉的棋子从本地棋盘移除，原处可以再落子：黑 D16 被白 C16、E16、D17、D15 围住
+	for _, m := range []struct {
+		x, y int
+	}{{2, 15}, {4, 15}, {3, 16}, {3, 14}} {
+		if err := g.Play(ctx, m.x, m.y, "W"); err != nil {
+			t.Fatalf("Play(%d, %d, W) unexpected error: %v", m.x, m.y, err)
+		}
+	}
+	if hasStone, _ := g.HasStone(ctx, 3, 15); hasStone {
+		t.Errorf("D16 被提后 HasStone(3, 15) = true, want false")
+	}
+	commands = nil
+	if err := g.Play(ctx, 3, 15, "B"); err == nil {
+		t.Error("Play(3, 15, B) 是自杀，expected error")
+	}
+	if len(commands) != 0 {
+		t.Errorf("自杀时不应发送键盘输入: %v", commands)
+	}
+
+	g.Reset(ctx)
+	if hasStone, _ := g.HasStone(ctx, 3, 15); hasStone {
+		t.Errorf("Reset() 后 HasStone(3, 15) = true, want false")
+	}
+}
@@ -0,0 +1,140 @@
+package target
+
+import (
+	"context"
+	"image"
+
+	"goboardsync/katrain"
+)
+
+// KaTrain 通过 HTTP API 同步到打过补丁的 KaTrain。每个请求的时长由 Client.Timeout 限制，ctx 取消时提前结束
+type KaTrain struct {
+	Client *katrain.Client
+	// Label 同时使用多个 KaTrain 实例时在日志中区分它们（通常为地址），为空时不显示
+	Label string
+}
+
+func NewKaTrain(baseURL string) *KaTrain {
+	return &KaTrain{Client: katrain.NewClient(baseURL)}
+}
+
+// NewKaTrainWithAuth 通过带认证的客户端访问 KaTrain，见 katrain.Auth
+func NewKaTrainWithAuth(baseURL string, auth katrain.Auth) (*KaTrain, error) {
+	client, err := katrain.NewClientWithAuth(baseURL, auth)
+	if err != nil {
+		return nil, err
+	}
+	return &KaTrain{Client: client}, nil
+}
+
+func (k *KaTrain) Name() string {
+	if k.Label != "" {
+		return "KaTrain HTTP (" + k.Label + ")"
+	}
+	return "KaTrain HTTP"
+}
+
+func (k *KaTrain) Reset(ctx context.Context) error {
+	return k.Client.Reset(ctx)
+}
+
+func (k *KaTrain) HasStone(ctx context.Context, x, y int) (bool, error) {
+	hasStone, _, err := k.Client.CheckPosition(ctx, x, y)
+	return hasStone, err
+}
+
+func (k *KaTrain) Play(ctx context.Context, x, y int, color string) error {
+	return k.Client.MakeMove(ctx, x, y, color)
+}
+
+func (k *KaTrain) LastMove(ctx context.Context) (Move, error) {
+	x, y, color, number, err := k.Client.LastMove(ctx)
+	if err != nil {
+		return Move{}, err
+	}
+	return Move{X: x, Y: y, Color: color, Number: number}, nil
+}
+
+func (k *KaTrain) NewGame(ctx context.Context, g Game) error {
+	return k.Client.NewGame(ctx, katrain.GameSetup{
+		Size:      g.Size,
+		Komi:      g.Komi,
+		Handicap:  g.Handicap,
+		BlackName: g.BlackName,
+		WhiteName: g.WhiteName,
+		Ruleset:   g.Ruleset,
+	})
+}
+
+func (k *KaTrain) Analysis(ctx context.Context, move int) (Analysis, error) {
+	a, err := k.Client.Analysis(ctx, move)
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	result := Analysis{Winrate: a.Winrate, ScoreLead: a.ScoreLead}
+	for _, c := range a.Candidates {
+		if len(c.Coords) != 2 {
+			continue
+		}
+		result.Candidates = append(result.Candidates, Candidate{
+			X:         c.Coords[0],
+			Y:         c.Coords[1],
+			Winrate:   c.Winrate,
+			ScoreLead: c.ScoreLead,
+			Visits:    c.Visits,
+		})
+	}
+	return result, nil
+}
+
+func (k *KaTrain) SetAnalysisBudget(ctx context.Context, b AnalysisBudget) error {
+	return k.Client.SetAnalysisBudget(ctx, katrain.AnalysisBudget{MaxVisits: b.Visits, MaxTime: b.Time.Seconds()})
+}
+
+func (k *KaTrain) MarkDead(ctx context.Context, stones []image.Point) (string, error) {
+	return k.Client.MarkDead(ctx, stones)
+}
+
+func (k *KaTrain) SetupPosition(ctx context.Context, p Position) error {
+	return k.Client.SetupPosition(ctx, katrain.Position{
+		Black:      toCoords(p.Black),
+		White:      toCoords(p.White),
+		Next:       p.Next,
+		MoveNumber: p.MoveNumber,
+	})
+}
+
+func toCoords(points []image.Point) [][2]int {
+	coords := make([][2]int, len(points))
+	for i, p := range points {
+		coords[i] = [2]int{p.X, p.Y}
+	}
+	return coords
+}
+
+// features 各可选功能对应的接口
+var features = map[Feature]string{
+	FeatureLastMove:       katrain.PathLastMove,
+	FeatureNewGame:        katrain.PathNewGame,
+	FeatureAnalysis:       katrain.PathAnalysis,
+	FeatureAnalysisBudget: katrain.PathAnalysisSettings,
+	FeatureDeadStones:     katrain.PathDeadStones,
+	FeatureSetupPosition:  katrain.PathSetupPosition,
+}
+
+func (k *KaTrain) Probe(ctx context.Context) (string, []Feature, error) {
+	caps, err := k.Client.Probe(ctx)
+	var missing []Feature
+	for _, f := range []Feature{FeatureLastMove, FeatureNewGame, FeatureAnalysis, FeatureAnalysisBudget, FeatureDeadStones, FeatureSetupPosition} {
+		if !k.Supports(f) {
+			missing = append(missing, f)
+		}
+	}
+	return caps.Version, missing, err
+}
+
+func (k *KaTrain) Supports(f Feature) bool {
+	path, ok := features[f]
+	return !ok || k.Client.Supports(path)
+}
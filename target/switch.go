@@ -0,0 +1,102 @@
+package target
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"slices"
+	"sync/atomic"
+)
+
+// Switch 在几个 KaTrain 实例之间切换的同步目标，如多桌轮换时每桌对应一个实例。
+// 所有调用都转给当前选中的实例，Select 可与其他调用并发
+type Switch struct {
+	targets []*KaTrain
+	current atomic.Int32
+}
+
+// NewSwitch 返回在 targets 之间切换的目标，初始选中第一个。targets 不能为空
+func NewSwitch(targets ...*KaTrain) *Switch {
+	return &Switch{targets: targets}
+}
+
+// Select 选中第 i 个实例，之后的调用都转给它
+func (s *Switch) Select(i int) {
+	s.current.Store(int32(i))
+}
+
+// Current 当前选中的实例
+func (s *Switch) Current() *KaTrain {
+	return s.targets[s.current.Load()]
+}
+
+func (s *Switch) Name() string {
+	return fmt.Sprintf("KaTrain HTTP（%d 个实例）", len(s.targets))
+}
+
+func (s *Switch) Reset(ctx context.Context) error {
+	return s.Current().Reset(ctx)
+}
+
+func (s *Switch) HasStone(ctx context.Context, x, y int) (bool, error) {
+	return s.Current().HasStone(ctx, x, y)
+}
+
+func (s *Switch) Play(ctx context.Context, x, y int, color string) error {
+	return s.Current().Play(ctx, x, y, color)
+}
+
+func (s *Switch) LastMove(ctx context.Context) (Move, error) {
+	return s.Current().LastMove(ctx)
+}
+
+func (s *Switch) NewGame(ctx context.Context, g Game) error {
+	return s.Current().NewGame(ctx, g)
+}
+
+func (s *Switch) Analysis(ctx context.Context, move int) (Analysis, error) {
+	return s.Current().Analysis(ctx, move)
+}
+
+func (s *Switch) MarkDead(ctx context.Context, stones []image.Point) (string, error) {
+	return s.Current().MarkDead(ctx, stones)
+}
+
+func (s *Switch) SetupPosition(ctx context.Context, p Position) error {
+	return s.Current().SetupPosition(ctx, p)
+}
+
+// SetAnalysisBudget 对全部实例设置分析计算量，各桌切换后不必重新设置
+func (s *Switch) SetAnalysisBudget(ctx context.Context, b AnalysisBudget) error {
+	for _, t := range s.targets {
+		if err := t.SetAnalysisBudget(ctx, b); err != nil {
+			return fmt.Errorf("%s: %w", t.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Probe 探测全部实例，返回第一个实例的版本与任一实例不支持的功能
+func (s *Switch) Probe(ctx context.Context) (string, []Feature, error) {
+	var version string
+	var missing []Feature
+	for i, t := range s.targets {
+		v, m, err := t.Probe(ctx)
+		if err != nil {
+			return version, missing, fmt.Errorf("%s: %v", t.Name(), err)
+		}
+		if i == 0 {
+			version = v
+		}
+		for _, f := range m {
+			if !slices.Contains(missing, f) {
+				missing = append(missing, f)
+			}
+		}
+	}
+	return version, missing, nil
+}
+
+func (s *Switch) Supports(f Feature) bool {
+	return s.Current().Supports(f)
+}
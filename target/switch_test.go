@@ -0,0 +1,47 @@
+package target
+
+import (
+	"context"
+	"testing"
+
+	"goboardsync/katrain"
+	"goboardsync/katrain/katraintest"
+)
+
+func TestSwitch(t *testing.T) {
+	ctx := context.Background()
+	a, b := katraintest.NewServer(), katraintest.NewServer()
+	defer a.Close()
+	defer b.Close()
+	b.Disable(katrain.PathAnalysis)
+
+	sw := NewSwitch(NewKaTrain(a.URL), NewKaTrain(b.URL))
+	if err := sw.Play(ctx, 3, 3, "B"); err != nil {
+		t.Fatal(err)
+	}
+	sw.Select(1)
+	if err := sw.Play(ctx, 15, 15, "B"); err != nil {
+		t.Fatal(err)
+	}
+	if has, _ := sw.HasStone(ctx, 3, 3); has {
+		t.Errorf("切到第二个实例后不应看到第一个实例的棋子")
+	}
+	if len(a.Moves()) != 1 || len(b.Moves()) != 1 || b.Moves()[0].X != 15 {
+		t.Errorf("a = %v, b = %v, want 各一手", a.Moves(), b.Moves())
+	}
+
+	_, missing, err := sw.Probe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0] != FeatureAnalysis {
+		t.Errorf("Probe() missing = %v, want [%s]", missing, FeatureAnalysis)
+	}
+	if sw.Supports(FeatureAnalysis) {
+		t.Errorf("当前实例不支持分析时 Supports() 应返回 false")
+	}
+	sw.Select(0)
+	if !sw.Supports(FeatureAnalysis) {
+		t.Errorf("切回第一个实例后 Supports() 应返回 true")
+	}
+}
@@ -0,0 +1,131 @@
+// Package target 定义同步的目标端（KaTrain HTTP API、KaTrain 窗口键盘输入等），
+// 坐标统一使用 KaTrain 坐标。与目标通信的方法都带 ctx，ctx 取消时尽快返回。
+package target
+
+import (
+	"context"
+	"image"
+	"time"
+
+	"goboardsync/katrain"
+)
+
+// Move 目标端上的一手棋
+type Move struct {
+	X, Y   int
+	Color  string // "B" 或 "W"
+	Number int    // 手数，棋盘为空时为 0
+}
+
+// SyncTarget 手机识别出的棋步同步到的目标
+type SyncTarget interface {
+	// Name 目标名称，用于日志
+	Name() string
+	// Reset 清空目标棋盘
+	Reset(ctx context.Context) error
+	// HasStone 查询 (x, y) 是否已有棋子
+	HasStone(ctx context.Context, x, y int) (bool, error)
+	// Play 以 color 在 (x, y) 落子
+	Play(ctx context.Context, x, y int, color string) error
+}
+
+// MoveSource 能读取最后一手的目标，用于 KaTrain → 手机 方向的同步
+type MoveSource interface {
+	LastMove(ctx context.Context) (Move, error)
+}
+
+// Game 新对局的设置
+type Game struct {
+	Size      int
+	Komi      float64
+	Handicap  int
+	BlackName string
+	WhiteName string
+	Ruleset   string
+}
+
+// GameSetter 能按手机上的对局设置新对局的目标，不支持时同步开始前只清空棋盘
+type GameSetter interface {
+	NewGame(ctx context.Context, g Game) error
+}
+
+// Candidate 分析给出的候选点，胜率（0-1）与目差均为黑方视角
+type Candidate struct {
+	X, Y      int
+	Winrate   float64
+	ScoreLead float64
+	Visits    int
+}
+
+// Analysis 某一手之后局面的分析结果，胜率与目差均为黑方视角
+type Analysis struct {
+	Winrate    float64
+	ScoreLead  float64
+	Candidates []Candidate
+}
+
+// Analyzer 能提供分析结果的目标，用于导出带胜率注释的复盘棋谱
+type Analyzer interface {
+	Analysis(ctx context.Context, move int) (Analysis, error)
+}
+
+// AnalysisBudget 每一手的分析计算量，为 0 的项沿用目标自己的设置
+type AnalysisBudget struct {
+	Visits int
+	Time   time.Duration
+}
+
+// BudgetSetter 能限制每一手分析计算量的目标，快棋转播时避免分析任务越积越多
+type BudgetSetter interface {
+	SetAnalysisBudget(ctx context.Context, b AnalysisBudget) error
+}
+
+// DeadStoneMarker 数子时能接收死子的目标，终局结果按手机上确认的死子计算
+type DeadStoneMarker interface {
+	// MarkDead 标记死子（KaTrain 坐标），返回目标按此数子的结果（如 "B+3.5"），目标不给出结果时为空
+	MarkDead(ctx context.Context, stones []image.Point) (string, error)
+}
+
+// Position 对局中途的局面（KaTrain 坐标），中途接入时摆到目标上
+type Position struct {
+	Black, White []image.Point
+	// Next 轮到落子的一方（B/W）
+	Next string
+	// MoveNumber 局面对应的手数，0 表示未知
+	MoveNumber int
+}
+
+// PositionSetter 能直接摆放局面的目标。不支持时只能把棋子逐个当作棋步下上去
+type PositionSetter interface {
+	// SetupPosition 清空棋盘并摆上 p，之后的落子接在 p 后面，由 p.Next 先下
+	SetupPosition(ctx context.Context, p Position) error
+}
+
+// Feature 目标的可选功能
+type Feature string
+
+const (
+	// FeatureLastMove 读取最后一手（MoveSource，KaTrain → 手机方向的同步）
+	FeatureLastMove Feature = "last_move"
+	// FeatureNewGame 按对局设置开始新对局（GameSetter）
+	FeatureNewGame Feature = "new_game"
+	// FeatureAnalysis 读取分析结果（Analyzer）
+	FeatureAnalysis Feature = "analysis"
+	// FeatureAnalysisBudget 限制每一手的分析计算量（BudgetSetter）
+	FeatureAnalysisBudget Feature = "analysis_budget"
+	// FeatureDeadStones 数子时标记死子（DeadStoneMarker）
+	FeatureDeadStones Feature = "dead_stones"
+	// FeatureSetupPosition 中途接入时摆放局面（PositionSetter）
+	FeatureSetupPosition Feature = "setup_position"
+)
+
+// ErrUnsupported 目标没有某项功能的接口（如旧版 KaTrain 补丁），调用方应停止使用该功能而不是反复重试
+var ErrUnsupported = katrain.ErrUnsupported
+
+// Prober 启动时能探测自己支持哪些可选功能的目标。探测后，以及运行中某个接口返回 ErrUnsupported 后，
+// Supports 对不支持的功能返回 false，同步流程视同目标没有实现对应的接口
+type Prober interface {
+	// Probe 探测目标，返回版本与不支持的功能（版本未知时为空）；无法连接时返回错误
+	Probe(ctx context.Context) (version string, missing []Feature, err error)
+	Supports(f Feature) bool
+}
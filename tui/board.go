@@ -0,0 +1,85 @@
+// Package tui 在终端里显示棋盘与同步状态：Board 把局面画成文本（每次同步后打印到日志），
+// Run 为全屏的终端界面（-tui），通过 SSH 运行时不需要浏览器也能看到局面、最近的错误并操作。
+package tui
+
+import (
+	"strings"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+	"goboardsync/overlay"
+)
+
+// ANSI 反色，用于高亮最后一手
+const (
+	reverse = "\x1b[7m"
+	reset   = "\x1b[0m"
+)
+
+// Board 把一帧画成终端文本：上下为列字母（A-T，跳过 I），两侧为行号，X 为黑、O 为白、+ 为星位，
+// 最后一手两侧加括号；highlight 为 true 时最后一手再用 ANSI 反色显示
+func Board(f overlay.Frame, highlight bool) string {
+	var b strings.Builder
+	header := func() {
+		b.WriteString("  ")
+		for x := range coords.Size {
+			b.WriteString(" " + coords.ColumnLetter(x, true))
+		}
+		b.WriteString("\n")
+	}
+
+	header()
+	for y := coords.Size - 1; y >= 0; y-- {
+		lastX := -1
+		if f.Last != nil && f.Last.Y == y {
+			lastX = f.Last.X
+		}
+
+		b.WriteString(rowLabel(y))
+		for x := range coords.Size {
+			switch {
+			case x == lastX:
+				b.WriteString("(")
+			case x > 0 && x-1 == lastX:
+				b.WriteString(")")
+			default:
+				b.WriteString(" ")
+			}
+			point := pointChar(&f.Board, x, y)
+			if x == lastX && highlight {
+				point = reverse + point + reset
+			}
+			b.WriteString(point)
+		}
+		if lastX == coords.Size-1 {
+			b.WriteString(")")
+		} else {
+			b.WriteString(" ")
+		}
+		b.WriteString(strings.TrimSpace(rowLabel(y)) + "\n")
+	}
+	header()
+	return b.String()
+}
+
+// rowLabel 行号，右对齐为两个字符
+func rowLabel(y int) string {
+	label := coords.Format(0, y, coords.GTP)[1:]
+	if len(label) == 1 {
+		label = " " + label
+	}
+	return label
+}
+
+func pointChar(b *board.Board, x, y int) string {
+	switch b.At(x, y) {
+	case board.Black:
+		return "X"
+	case board.White:
+		return "O"
+	}
+	if (x == 3 || x == 9 || x == 15) && (y == 3 || y == 9 || y == 15) {
+		return "+"
+	}
+	return "."
+}
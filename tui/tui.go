@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"goboardsync/dashboard"
+	"goboardsync/overlay"
+)
+
+// Logs 保存最近的若干行日志，作为 service.RedirectOutput 的目标，界面只显示最后几行
+type Logs struct {
+	mu      sync.Mutex
+	max     int
+	lines   []string
+	partial string
+}
+
+func NewLogs(max int) *Logs {
+	return &Logs{max: max}
+}
+
+func (l *Logs) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	text := l.partial + string(p)
+	parts := strings.Split(text, "\n")
+	l.partial = parts[len(parts)-1]
+	for _, line := range parts[:len(parts)-1] {
+		l.lines = append(l.lines, strings.TrimRight(line, "\r"))
+	}
+	if len(l.lines) > l.max {
+		l.lines = append([]string(nil), l.lines[len(l.lines)-l.max:]...)
+	}
+	return len(p), nil
+}
+
+// Last 返回最后 n 行
+func (l *Logs) Last(n int) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	start := max(len(l.lines)-n, 0)
+	return append([]string(nil), l.lines[start:]...)
+}
+
+// Key 一个按键及其操作的名称
+type Key struct {
+	Key   string
+	Label string
+}
+
+// View 界面上的一屏内容
+type View struct {
+	Frame  overlay.Frame
+	Status dashboard.Status
+	Keys   []Key
+	Logs   []string
+}
+
+// Render 画出一屏（不含清屏的控制符）
+func Render(v View) string {
+	var b strings.Builder
+	st := v.Status
+
+	b.WriteString("goboardsync")
+	if st.Table != "" {
+		fmt.Fprintf(&b, "  对局 %s", st.Table)
+	}
+	if st.Paused {
+		b.WriteString("  [已暂停]")
+	}
+	if st.Reviewing {
+		b.WriteString("  [复盘中]")
+	}
+	if st.Device != "" {
+		fmt.Fprintf(&b, "  设备 %s", st.Device)
+	}
+	b.WriteString("\n")
+	if st.Alert != "" {
+		fmt.Fprintf(&b, "🚨 %s\n", st.Alert)
+	}
+
+	fmt.Fprintf(&b, "手机 第 %d 手 %s   KaTrain 第 %d 手 %s", st.PhoneMove, st.PhoneCoord, st.KatrainMove, st.KatrainCoord)
+	if v.Frame.Winrate != nil {
+		fmt.Fprintf(&b, "   黑胜率 %.1f%%", *v.Frame.Winrate*100)
+	}
+	b.WriteString("\n")
+	if st.Suggestion != "" {
+		fmt.Fprintf(&b, "💡 建议 %s\n", st.Suggestion)
+	}
+	b.WriteString("\n")
+
+	b.WriteString(Board(v.Frame, true))
+	b.WriteString("\n")
+
+	if e := st.LastError; e != nil {
+		fmt.Fprintf(&b, "最近错误 [%s] %s: %s\n", e.At.Format("15:04:05"), e.Stage, e.Message)
+	} else {
+		b.WriteString("最近错误 无\n")
+	}
+
+	b.WriteString("\n── 日志 ──\n")
+	for _, line := range v.Logs {
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("\n── 按键（输入字母后回车）──\n")
+	for i, k := range v.Keys {
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		fmt.Fprintf(&b, "%s %s", k.Key, k.Label)
+	}
+	b.WriteString("  Ctrl+C 退出\n> ")
+	return b.String()
+}
+
+// Run 每隔 interval 读取一次界面内容，有变化时清屏重画，直到 ctx 取消
+func Run(ctx context.Context, out io.Writer, view func() View, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last string
+	for {
+		if screen := Render(view()); screen != last {
+			io.WriteString(out, "\x1b[H\x1b[2J"+screen)
+			last = screen
+		}
+		select {
+		case <-ctx.Done():
+			io.WriteString(out, "\n")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"testing"
+	"time"
+
+	"goboardsync/board"
+	"goboardsync/dashboard"
+	"goboardsync/overlay"
+)
+
+func TestBoard(t *testing.T) {
+	var b board.Board
+	b.Set(15, 15, board.Black) // Q16
+	b.Set(18, 0, board.White)  // T1
+	f := overlay.Frame{Board: b, Last: &image.Point{X: 18, Y: 0}}
+
+	lines := strings.Split(strings.TrimSuffix(Board(f, false), "\n"), "\n")
+	if len(lines) != 21 {
+		t.Fatalf("行数 = %d, want 21", len(lines))
+	}
+	if lines[0] != "   A B C D E F G H J K L M N O P Q R S T" {
+		t.Errorf("列字母 = %q", lines[0])
+	}
+
+	tests := []struct {
+		line int
+		want string
+	}{
+		{1, "19 . . . . . . . . . . . . . . . . . . . 19"},
+		{4, "16 . . . + . . . . . + . . . . . X . . . 16"},
+		{19, " 1 . . . . . . . . . . . . . . . . . .(O)1"},
+	}
+	for _, tt := range tests {
+		if lines[tt.line] != tt.want {
+			t.Errorf("第 %d 行 = %q, want %q", tt.line, lines[tt.line], tt.want)
+		}
+	}
+
+	f.Last = &image.Point{X: 15, Y: 15}
+	if got := Board(f, true); !strings.Contains(got, "("+reverse+"X"+reset+")") {
+		t.Errorf("最后一手没有高亮:\n%s", got)
+	}
+}
+
+func TestLogs(t *testing.T) {
+	logs := NewLogs(3)
+	fmt.Fprintf(logs, "一\n二\n")
+	fmt.Fprintf(logs, "三")
+	if got := logs.Last(5); strings.Join(got, ",") != "一,二" {
+		t.Errorf("未写完的行不应显示: %q", got)
+	}
+	fmt.Fprintf(logs, "\n四\n")
+	if got := logs.Last(5); strings.Join(got, ",") != "二,三,四" {
+		t.Errorf("只保留最后 3 行: %q", got)
+	}
+	if got := logs.Last(1); strings.Join(got, ",") != "四" {
+		t.Errorf("Last(1) = %q", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	winrate := 0.552
+	v := View{
+		Frame: overlay.Frame{Winrate: &winrate},
+		Status: dashboard.Status{
+			PhoneMove: 12, PhoneCoord: "Q16", KatrainMove: 12, KatrainCoord: "Q16", Paused: true,
+			LastError: &dashboard.Failure{Stage: "截图", Message: "device offline", At: time.Date(2024, 5, 1, 20, 3, 4, 0, time.Local)},
+		},
+		Keys: []Key{{"p", "暂停/继续"}, {"s", "保存棋谱"}},
+		Logs: []string{"[20:03:00] ✅ 同步"},
+	}
+	got := Render(v)
+	for _, want := range []string{
+		"[已暂停]",
+		"手机 第 12 手 Q16   KaTrain 第 12 手 Q16   黑胜率 55.2%",
+		"最近错误 [20:03:04] 截图: device offline",
+		"[20:03:00] ✅ 同步",
+		"p 暂停/继续  s 保存棋谱",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("界面缺少 %q:\n%s", want, got)
+		}
+	}
+}
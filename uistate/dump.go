@@ -0,0 +1,133 @@
+package uistate
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"goboardsync/adb"
+	"goboardsync/ocr"
+)
+
+// DumpPath uiautomator dump 在手机上写出的文件，采集端只允许 cat 这一个文件
+const DumpPath = "/data/local/tmp/goboardsync_ui.xml"
+
+// State 某一时刻的界面状态
+type State struct {
+	Time time.Time
+	// Activity 前台界面（包名/类名），读不到时为空
+	Activity string
+	// Dialog 弹出的对话框，没有时为 nil
+	Dialog *Dialog
+	// MoveNumber 界面上显示的手数，App 没有以文字控件显示时为 0
+	MoveNumber int
+}
+
+// Reader 定期读取界面状态的配置
+type Reader struct {
+	Phone *adb.Client
+	// MoveNumberID 显示手数的控件的资源 ID（如 com.tencent.tmgp.go:id/tv_step），为空时在所有文字中按 Patterns 查找
+	MoveNumberID string
+	// Patterns 提取手数的正则，为空时使用 ocr.ExtractMoveNumber 的内置规则
+	Patterns []*regexp.Regexp
+}
+
+// Read 用 uiautomator dump 读取一次界面状态。uiautomator 每次需要一到两秒，不适合逐帧调用
+func (r *Reader) Read(ctx context.Context) (*State, error) {
+	root, err := Dump(ctx, r.Phone)
+	if err != nil {
+		return nil, err
+	}
+	st := &State{Time: time.Now(), Dialog: root.FindDialog(), MoveNumber: r.moveNumber(root)}
+	// 前台界面只用于显示，读不到时不影响其余状态
+	st.Activity, _ = r.Phone.FocusedActivity(ctx)
+	return st, nil
+}
+
+func (r *Reader) moveNumber(root *Node) int {
+	if r.MoveNumberID == "" {
+		return ocr.ExtractMoveNumberWith(strings.Join(root.Texts(), "\n"), r.Patterns)
+	}
+	var n int
+	root.Walk(func(node *Node) bool {
+		if n == 0 && node.ID == r.MoveNumberID {
+			// 指定的控件可能只显示数字，没有“第 N 手”之类的文字
+			if n = ocr.ExtractMoveNumberWith(node.label(), r.Patterns); n == 0 {
+				n, _ = strconv.Atoi(digitsRe.FindString(ocr.NormalizeNumerals(node.label())))
+			}
+		}
+		return n == 0
+	})
+	return n
+}
+
+var digitsRe = regexp.MustCompile(`\d+`)
+
+// Dump 执行 uiautomator dump 并读取生成的控件树
+func Dump(ctx context.Context, phone *adb.Client) (*Node, error) {
+	out, err := phone.Output(ctx, "shell", "uiautomator", "dump", DumpPath)
+	if err != nil {
+		return nil, fmt.Errorf("uiautomator dump 失败: %v", err)
+	}
+	// 界面一直在变化（如动画）时 uiautomator 等不到空闲，退出码仍为 0
+	if strings.Contains(string(out), "ERROR") {
+		return nil, fmt.Errorf("uiautomator dump 失败: %s", strings.TrimSpace(string(out)))
+	}
+	data, err := phone.Output(ctx, "shell", "cat", DumpPath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDump(data)
+}
+
+// dumpNode uiautomator dump 中的 node 元素
+type dumpNode struct {
+	Text      string     `xml:"text,attr"`
+	ID        string     `xml:"resource-id,attr"`
+	Class     string     `xml:"class,attr"`
+	Desc      string     `xml:"content-desc,attr"`
+	Clickable bool       `xml:"clickable,attr"`
+	Bounds    string     `xml:"bounds,attr"`
+	Children  []dumpNode `xml:"node"`
+}
+
+var boundsRe = regexp.MustCompile(`^\[(-?\d+),(-?\d+)\]\[(-?\d+),(-?\d+)\]$`)
+
+// ParseDump 解析 uiautomator dump 的 XML，返回 hierarchy 下的控件树（有多个窗口时放在同一个根节点下）
+func ParseDump(data []byte) (*Node, error) {
+	var h struct {
+		Nodes []dumpNode `xml:"node"`
+	}
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&h); err != nil {
+		return nil, fmt.Errorf("无法解析 uiautomator dump: %v", err)
+	}
+	if len(h.Nodes) == 0 {
+		return nil, fmt.Errorf("uiautomator dump 中没有控件")
+	}
+	if len(h.Nodes) == 1 {
+		return convert(h.Nodes[0]), nil
+	}
+	root := &Node{}
+	for _, n := range h.Nodes {
+		root.Children = append(root.Children, convert(n))
+	}
+	return root, nil
+}
+
+func convert(d dumpNode) *Node {
+	n := &Node{Class: d.Class, Text: d.Text, Desc: d.Desc, ID: d.ID, Clickable: d.Clickable}
+	if m := boundsRe.FindStringSubmatch(d.Bounds); m != nil {
+		for i := range n.Bounds {
+			n.Bounds[i], _ = strconv.Atoi(m[i+1])
+		}
+	}
+	for _, c := range d.Children {
+		n.Children = append(n.Children, convert(c))
+	}
+	return n
+}
@@ -0,0 +1,53 @@
+package uistate
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"goboardsync/adb"
+)
+
+const dumpXML = `<?xml version='1.0' encoding='UTF-8' standalone='yes' ?><hierarchy rotation="0">` +
+	`<node index="0" text="" resource-id="" class="android.widget.FrameLayout" package="com.tencent.tmgp.go" content-desc="" clickable="false" bounds="[0,0][1200,2670]">` +
+	`<node index="0" text="１２３" resource-id="com.tencent.tmgp.go:id/tv_step" class="android.widget.TextView" package="com.tencent.tmgp.go" content-desc="" clickable="false" bounds="[500,300][700,360]" />` +
+	`<node index="1" text="" resource-id="com.tencent.tmgp.go:id/dialog_root" class="android.widget.LinearLayout" package="com.tencent.tmgp.go" content-desc="" clickable="false" bounds="[100,1000][1100,1700]">` +
+	`<node index="0" text="对方申请悔棋" resource-id="" class="android.widget.TextView" package="com.tencent.tmgp.go" content-desc="" clickable="false" bounds="[150,1050][1050,1200]" />` +
+	`<node index="1" text="同意" resource-id="" class="android.widget.Button" package="com.tencent.tmgp.go" content-desc="" clickable="true" bounds="[600,1500][1000,1600]" />` +
+	`</node></node></hierarchy>`
+
+func TestReader(t *testing.T) {
+	phone := &adb.Client{Runner: func(ctx context.Context, args ...string) ([]byte, error) {
+		switch strings.Join(args, " ") {
+		case "shell uiautomator dump " + DumpPath:
+			return []byte("UI hierchary dumped to: " + DumpPath), nil
+		case "shell cat " + DumpPath:
+			return []byte(dumpXML), nil
+		case "shell dumpsys window":
+			return []byte("  mCurrentFocus=Window{5e1f2a1 u0 com.tencent.tmgp.go/com.tencent.go.GameActivity}"), nil
+		}
+		return nil, fmt.Errorf("unexpected command %q", args)
+	}}
+
+	r := &Reader{Phone: phone, MoveNumberID: "com.tencent.tmgp.go:id/tv_step"}
+	st, err := r.Read(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Activity != "com.tencent.tmgp.go/com.tencent.go.GameActivity" {
+		t.Errorf("Activity = %q", st.Activity)
+	}
+	if st.MoveNumber != 123 {
+		t.Errorf("MoveNumber = %d, want 123", st.MoveNumber)
+	}
+	want := &Dialog{Texts: []string{"对方申请悔棋"}, Buttons: []Button{{Text: "同意", X: 800, Y: 1550}}}
+	if !reflect.DeepEqual(st.Dialog, want) {
+		t.Errorf("Dialog = %+v, want %+v", st.Dialog, want)
+	}
+
+	if _, err := ParseDump([]byte(`<hierarchy rotation="0"></hierarchy>`)); err == nil {
+		t.Error("没有控件时 ParseDump() 应返回错误")
+	}
+}
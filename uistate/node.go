@@ -0,0 +1,91 @@
+// Package uistate 表示对弈 App 的界面结构（控件树），从中读取手数、弹出的对话框等界面状态，
+// 减少对截图像素规则的依赖。控件树来自 uiautomator dump（见 Dump）或伴侣 App（见 companion 包）。
+package uistate
+
+import "strings"
+
+// Node 界面结构中的一个节点（对应 Android 的 AccessibilityNodeInfo）
+type Node struct {
+	Class     string  `json:"class,omitempty"`
+	Text      string  `json:"text,omitempty"`
+	Desc      string  `json:"desc,omitempty"` // contentDescription
+	ID        string  `json:"id,omitempty"`   // viewIdResourceName，如 com.tencent.weiqi:id/move_count
+	Bounds    [4]int  `json:"bounds"`         // 屏幕上的范围：左、上、右、下
+	Clickable bool    `json:"clickable,omitempty"`
+	Children  []*Node `json:"children,omitempty"`
+}
+
+// Walk 先序遍历节点及其子孙，fn 返回 false 时不再进入该节点的子节点
+func (n *Node) Walk(fn func(*Node) bool) {
+	if n == nil || !fn(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// Texts 按先序返回节点及其子孙中所有非空的文字（Text，没有时为 Desc）
+func (n *Node) Texts() []string {
+	var texts []string
+	n.Walk(func(node *Node) bool {
+		if t := node.label(); t != "" {
+			texts = append(texts, t)
+		}
+		return true
+	})
+	return texts
+}
+
+func (n *Node) label() string {
+	if t := strings.TrimSpace(n.Text); t != "" {
+		return t
+	}
+	return strings.TrimSpace(n.Desc)
+}
+
+// Dialog 界面上弹出的对话框
+type Dialog struct {
+	Texts   []string // 对话框中的文字，按钮除外
+	Buttons []Button
+}
+
+// Button 对话框中可点击的按钮，X、Y 为中心的屏幕坐标
+type Button struct {
+	Text string
+	X, Y int
+}
+
+// FindDialog 查找界面中的对话框：类名含 Dialog 或资源 ID 含 dialog 的节点。没有时返回 nil
+func (n *Node) FindDialog() *Dialog {
+	var found *Node
+	n.Walk(func(node *Node) bool {
+		if found != nil {
+			return false
+		}
+		if strings.Contains(node.Class, "Dialog") || strings.Contains(strings.ToLower(node.ID), "dialog") {
+			found = node
+			return false
+		}
+		return true
+	})
+	if found == nil {
+		return nil
+	}
+
+	d := &Dialog{}
+	found.Walk(func(node *Node) bool {
+		if node.Clickable && node != found {
+			if texts := node.Texts(); len(texts) > 0 {
+				b := node.Bounds
+				d.Buttons = append(d.Buttons, Button{Text: strings.Join(texts, " "), X: (b[0] + b[2]) / 2, Y: (b[1] + b[3]) / 2})
+			}
+			return false
+		}
+		if t := node.label(); t != "" {
+			d.Texts = append(d.Texts, t)
+		}
+		return true
+	})
+	return d
+}
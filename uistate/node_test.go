@@ -0,0 +1,33 @@
+package uistate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindDialog(t *testing.T) {
+	tree := &Node{Children: []*Node{
+		{Class: "android.widget.TextView", Text: "第 120 手"},
+		{Class: "android.app.AlertDialog", Children: []*Node{
+			{Text: "对方申请数子，是否同意？"},
+			{Clickable: true, Bounds: [4]int{100, 1500, 500, 1600}, Children: []*Node{{Text: "拒绝"}}},
+			{Clickable: true, Bounds: [4]int{700, 1500, 1100, 1600}, Text: "同意"},
+		}},
+	}}
+
+	d := tree.FindDialog()
+	if d == nil {
+		t.Fatal("FindDialog() = nil")
+	}
+	if !reflect.DeepEqual(d.Texts, []string{"对方申请数子，是否同意？"}) {
+		t.Errorf("Texts = %q", d.Texts)
+	}
+	want := []Button{{Text: "拒绝", X: 300, Y: 1550}, {Text: "同意", X: 900, Y: 1550}}
+	if !reflect.DeepEqual(d.Buttons, want) {
+		t.Errorf("Buttons = %+v, want %+v", d.Buttons, want)
+	}
+
+	if d := tree.Children[0].FindDialog(); d != nil {
+		t.Errorf("没有对话框时 FindDialog() = %+v", d)
+	}
+}
@@ -0,0 +1,54 @@
+package vision
+
+import (
+	"fmt"
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+// AnimationRadius 判断落子、提子动画时比较落子点周围多少格：被提的棋子与高亮圈都在落子点附近
+const AnimationRadius = 2
+
+// AnimationMinDiff 落子点周围的平均灰度变化（0-255）达到此值时认为动画仍在进行。
+// 区域为 5×5 格，一颗棋子出现或消失约使平均值变化 5，截图压缩的噪声在 1 以内
+const AnimationMinDiff = 2.0
+
+// AnimationRegion 交叉点 at（0 起下标）周围 AnimationRadius 格的区域，坐标为 size 大小的棋盘图
+func AnimationRegion(at image.Point, size image.Point) image.Rectangle {
+	cellW := float64(size.X) / 19.0
+	cellH := float64(size.Y) / 19.0
+	rect := image.Rect(
+		int(float64(at.X-AnimationRadius)*cellW),
+		int(float64(at.Y-AnimationRadius)*cellH),
+		int(float64(at.X+AnimationRadius+1)*cellW),
+		int(float64(at.Y+AnimationRadius+1)*cellH),
+	)
+	return rect.Intersect(image.Rect(0, 0, size.X, size.Y))
+}
+
+// AnimationDiff 返回两帧截图中交叉点 at 周围（见 AnimationRegion）棋盘图的平均灰度差，
+// 用于判断提子、落子动画是否已结束。两帧须为同一分辨率且已配置棋盘角点
+func (d *Detector) AnimationDiff(before, after gocv.Mat, at image.Point) (float64, error) {
+	if before.Cols() != after.Cols() || before.Rows() != after.Rows() {
+		return 0, fmt.Errorf("截图尺寸不同: %dx%d, %dx%d", before.Cols(), before.Rows(), after.Cols(), after.Rows())
+	}
+	_, corners, ok := d.boardCorners(after)
+	if !ok {
+		return 0, fmt.Errorf("不支持的图片分辨率: %dx%d", after.Cols(), after.Rows())
+	}
+
+	var debugInfo DebugInfo
+	a, err := d.boardView(before, corners, &debugInfo)
+	if err != nil {
+		return 0, err
+	}
+	defer a.Close()
+	b, err := d.boardView(after, corners, &debugInfo)
+	if err != nil {
+		return 0, err
+	}
+	defer b.Close()
+
+	return RegionDiff(a, b, AnimationRegion(at, image.Pt(b.Cols(), b.Rows())))
+}
@@ -0,0 +1,50 @@
+package vision
+
+import (
+	"image"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+func TestAnimationRegion(t *testing.T) {
+	size := image.Pt(190, 190)
+	tests := []struct {
+		at   image.Point
+		want image.Rectangle
+	}{
+		{image.Pt(9, 9), image.Rect(70, 70, 120, 120)},
+		{image.Pt(0, 0), image.Rect(0, 0, 30, 30)},
+		{image.Pt(18, 17), image.Rect(160, 150, 190, 190)},
+	}
+	for _, tt := range tests {
+		if got := AnimationRegion(tt.at, size); got != tt.want {
+			t.Errorf("AnimationRegion(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestAnimationDiff(t *testing.T) {
+	before := gocv.NewMatWithSizeFromScalar(Skins[0].BoardColor, 2670, 1200, gocv.MatTypeCV8UC3)
+	defer before.Close()
+	after := before.Clone()
+	defer after.Close()
+
+	// 棋盘左上角 (40, 536) 起，每格约 59 像素：在第 (3, 3) 格画一颗淡出中的棋子
+	fading := after.Region(image.Rect(40+3*59, 536+3*59, 40+4*59, 536+4*59))
+	fading.SetTo(gocv.NewScalar(60, 60, 60, 0))
+	fading.Close()
+
+	d := NewDetector()
+	if diff, err := d.AnimationDiff(before, after, image.Pt(4, 4)); err != nil || diff < AnimationMinDiff {
+		t.Errorf("附近有变化时 AnimationDiff() = %.1f, %v", diff, err)
+	}
+	if diff, err := d.AnimationDiff(before, after, image.Pt(15, 15)); err != nil || diff != 0 {
+		t.Errorf("远处没有变化时 AnimationDiff() = %.1f, %v", diff, err)
+	}
+	empty := gocv.NewMat()
+	defer empty.Close()
+	if _, err := d.AnimationDiff(before, empty, image.Pt(4, 4)); err == nil {
+		t.Errorf("尺寸不同时应返回错误")
+	}
+}
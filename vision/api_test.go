@@ -0,0 +1,54 @@
+package vision_test
+
+import (
+	"context"
+	"testing"
+
+	"goboardsync/board"
+	"goboardsync/capture"
+	"goboardsync/vision"
+
+	"gocv.io/x/gocv"
+)
+
+// 以下赋值在编译期检查公开 API 的签名，改动签名时这里会先编译失败
+var (
+	_ func(...vision.Option) *vision.Detector                                                      = vision.NewDetector
+	_ func(*vision.Detector, gocv.Mat, int) (vision.Result, error)                                 = (*vision.Detector).DetectLastMoveCoord
+	_ func(*vision.Detector, gocv.Mat) (board.Board, error)                                        = (*vision.Detector).ReadPhysicalBoard
+	_ func(*vision.Detector, gocv.Mat) (int, error)                                                = (*vision.Detector).FetchMoveNumberFromOCR
+	_ func(*vision.Detector, vision.Tuning)                                                        = (*vision.Detector).SetTuning
+	_ func(*vision.Detector, context.Context, capture.Source) (<-chan vision.Result, <-chan error) = (*vision.Detector).Watch
+
+	_ = []vision.Option{
+		vision.WithOCREndpoint(""),
+		vision.WithBoardModel(nil),
+		vision.WithThreshold(0),
+		vision.WithSkin(""),
+		vision.WithClassifier(vision.BrightnessClassifier{}),
+		vision.WithLightingNormalization(true),
+		vision.WithWarpSkip(true),
+		vision.WithTuning(vision.Tuning{}),
+		vision.WithInterval(0),
+		vision.WithMinConfidence(0),
+	}
+
+	_ vision.StoneClassifier = vision.BrightnessClassifier{}
+	_ vision.StoneClassifier = (*vision.TemplateClassifier)(nil)
+)
+
+func TestNewDetectorOptions(t *testing.T) {
+	var model board.Board
+	d := vision.NewDetector(
+		vision.WithOCREndpoint("http://ocr:5001/ocr"),
+		vision.WithBoardModel(&model),
+		vision.WithThreshold(40),
+	)
+
+	if d.OCREndpoint != "http://ocr:5001/ocr" || d.BoardModel != &model || d.MinMarkerArea != 40 {
+		t.Errorf("NewDetector() = %+v, 选项未生效", d)
+	}
+	if def := vision.NewDetector(); def.OCREndpoint == "" || def.MinMarkerArea != 0 {
+		t.Errorf("默认 Detector = %+v, want 默认 OCR 地址且不过滤角标", def)
+	}
+}
@@ -0,0 +1,178 @@
+// Package bench 批量识别带标注的截图样本（文件名格式见 vision.ParseSampleFilename），
+// 统计识别准确率并导出报告，供 vision 的测试与 bench 命令共用。
+package bench
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"goboardsync/coords"
+	"goboardsync/vision"
+)
+
+// Stats 批量识别统计信息。误差只统计识别出坐标的样本
+type Stats struct {
+	TotalCount   int     `json:"total_count"`
+	SuccessCount int     `json:"success_count"`
+	FailureCount int     `json:"failure_count"`
+	SuccessRate  float64 `json:"success_rate"`
+	BlackCount   int     `json:"black_count"`
+	WhiteCount   int     `json:"white_count"`
+	MSE          float64 `json:"mse"`
+	RMSE         float64 `json:"rmse"`
+	MaxDistance  float64 `json:"max_distance"`
+	MinDistance  float64 `json:"min_distance"`
+}
+
+// Detail 单张样本的识别详情
+type Detail struct {
+	Filename  string        `json:"filename"`
+	Success   bool          `json:"success"`
+	Result    vision.Result `json:"result"`
+	Error     string        `json:"error,omitempty"`
+	ExpectedX int           `json:"expected_x"`
+	ExpectedY int           `json:"expected_y"`
+	ImageSize string        `json:"image_size"`
+	Distance  float64       `json:"distance"`
+	// Overlay 校正棋盘上画出网格、角标与识别结果的 JPG，供 HTML 报告内嵌
+	Overlay []byte `json:"-"`
+}
+
+// Run 用 d 识别 dir 中的全部图片样本（格式见 vision.ImageExtensions）。文件名无法解析或图片读取失败的样本只记入 details，不计入统计
+func Run(dir string, d *vision.Detector) (*Stats, []Detail, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取图像目录失败: %v", err)
+	}
+
+	var details []Detail
+	for _, file := range files {
+		if file.IsDir() || !vision.IsImageFile(file.Name()) {
+			continue
+		}
+		details = append(details, recognize(filepath.Join(dir, file.Name()), d))
+	}
+	return Summarize(details), details, nil
+}
+
+func recognize(path string, d *vision.Detector) Detail {
+	filename := filepath.Base(path)
+	moveNumber, color, expectedX, expectedY, err := vision.ParseSampleFilename(filename)
+	if err != nil {
+		return Detail{Filename: filename, Error: fmt.Sprintf("解析文件名失败: %v", err)}
+	}
+
+	img, err := vision.ReadImage(path)
+	if err != nil {
+		return Detail{Filename: filename, Error: fmt.Sprintf("读取图像失败: %v", err)}
+	}
+	defer img.Close()
+
+	result, err := d.DetectLastMoveCoord(img, moveNumber)
+	if err != nil {
+		return Detail{Filename: filename, Error: fmt.Sprintf("检测失败: %v", err)}
+	}
+
+	distance := math.Hypot(float64(result.X-expectedX), float64(result.Y-expectedY))
+	return Detail{
+		Filename:  filename,
+		Success:   result.X > 0 && result.Y > 0 && result.Color == color && distance < 0.5,
+		Result:    result,
+		ExpectedX: expectedX,
+		ExpectedY: expectedY,
+		ImageSize: fmt.Sprintf("%dx%d", img.Cols(), img.Rows()),
+		Distance:  distance,
+		Overlay:   Overlay(img, result, expectedX, expectedY),
+	}
+}
+
+// Summarize 由识别详情计算统计信息，带 Error 的样本不计入
+func Summarize(details []Detail) *Stats {
+	var stats Stats
+	located := 0
+	for _, d := range details {
+		if d.Error != "" {
+			continue
+		}
+		stats.TotalCount++
+		if d.Success {
+			stats.SuccessCount++
+			if d.Result.Color == "B" {
+				stats.BlackCount++
+			} else {
+				stats.WhiteCount++
+			}
+		} else {
+			stats.FailureCount++
+		}
+
+		if d.Result.X > 0 && d.Result.Y > 0 {
+			if located == 0 || d.Distance < stats.MinDistance {
+				stats.MinDistance = d.Distance
+			}
+			located++
+			stats.MSE += d.Distance * d.Distance
+			stats.MaxDistance = math.Max(stats.MaxDistance, d.Distance)
+		}
+	}
+
+	if stats.TotalCount > 0 {
+		stats.SuccessRate = float64(stats.SuccessCount) / float64(stats.TotalCount) * 100
+	}
+	if located > 0 {
+		stats.MSE /= float64(located)
+		stats.RMSE = math.Sqrt(stats.MSE)
+	}
+	return &stats
+}
+
+// Coord 把手机坐标格式化为样本文件名中的写法（如 P4），未识别时为 "-"
+func Coord(x, y int) string {
+	if x <= 0 || y <= 0 {
+		return "-"
+	}
+	return coords.FormatPhone(x, y)
+}
+
+// Print 把识别详情与统计打印为表格
+func Print(w io.Writer, stats *Stats, details []Detail) {
+	line := strings.Repeat("-", 104)
+	fmt.Fprintln(w, "\n"+line)
+	fmt.Fprintf(w, "%-30s | %-15s | %-15s | %-10s | %-10s | %s\n", "文件名", "预期结果", "检测结果", "图像尺寸", "置信度", "状态")
+	fmt.Fprintln(w, line)
+
+	for _, d := range details {
+		expected := fmt.Sprintf("%d-%s", d.Result.Move, Coord(d.ExpectedX, d.ExpectedY))
+		detected := fmt.Sprintf("%d-%s", d.Result.Move, Coord(d.Result.X, d.Result.Y))
+
+		status := "✅ 正确"
+		if !d.Success {
+			status = "❌ 错误"
+		}
+		fmt.Fprintf(w, "%-30s | %-15s | %-15s | %-10s | %-10.2f | %s\n",
+			d.Filename, expected, detected, d.ImageSize, d.Result.Confidence, status)
+
+		if d.Error != "" {
+			fmt.Fprintf(w, "   -> %s\n", d.Error)
+		} else if !d.Success {
+			fmt.Fprintf(w, "   -> 坐标误差: %.2f\n", d.Distance)
+		}
+	}
+
+	fmt.Fprintln(w, line)
+	fmt.Fprintf(w, "测试总结: 总计 %d, 成功 %d, 失败 %d, 成功率 %.2f%%\n",
+		stats.TotalCount, stats.SuccessCount, stats.FailureCount, stats.SuccessRate)
+	fmt.Fprintln(w, line)
+
+	if stats.TotalCount > 0 {
+		fmt.Fprintln(w, "误差统计:")
+		fmt.Fprintf(w, "均方误差 (MSE): %.2f\n", stats.MSE)
+		fmt.Fprintf(w, "均方根误差 (RMSE): %.2f\n", stats.RMSE)
+		fmt.Fprintf(w, "最大误差: %.2f\n", stats.MaxDistance)
+		fmt.Fprintf(w, "最小误差: %.2f\n", stats.MinDistance)
+	}
+}
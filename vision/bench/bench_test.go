@@ -0,0 +1,117 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"goboardsync/vision"
+)
+
+// TestBatchRecognition 识别 images/ 中的全部样本，检查结果字段一致，
+// 并把叠加图写到 debug/ 下，识别错误的样本打印出来供人工查看
+func TestBatchRecognition(t *testing.T) {
+	debugBaseDir := "debug"
+	os.RemoveAll(debugBaseDir)
+
+	_, details, err := Run("../../images", vision.NewDetector())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range details {
+		if d.Error != "" {
+			continue
+		}
+		if d.Result.X > 0 && d.Result.GridIndex != image.Pt(d.Result.X-1, d.Result.Y-1) {
+			t.Errorf("%s: GridIndex = %v, want (%d, %d)", d.Filename, d.Result.GridIndex, d.Result.X-1, d.Result.Y-1)
+		}
+		if d.Result.WarpSize != image.Pt(vision.BoardWarpSize, vision.BoardWarpSize) {
+			t.Errorf("%s: WarpSize = %v, want %dx%d", d.Filename, d.Result.WarpSize, vision.BoardWarpSize, vision.BoardWarpSize)
+		}
+
+		caseDir := filepath.Join(debugBaseDir, strings.TrimSuffix(d.Filename, filepath.Ext(d.Filename)))
+		os.MkdirAll(caseDir, 0755)
+		os.WriteFile(filepath.Join(caseDir, "debug_warped.jpg"), d.Overlay, 0644)
+
+		if !d.Success {
+			fmt.Printf("错误样本已记录: %s\n", d.Filename)
+		}
+	}
+}
+
+// TestBatchReport 设置 BATCH_REPORT_DIR 时识别 images/ 中的全部样本，
+// 写出 report.csv、report.json 与 report.html，用于跨提交比较识别准确率
+func TestBatchReport(t *testing.T) {
+	dir := os.Getenv("BATCH_REPORT_DIR")
+	if dir == "" {
+		t.Skip("未设置 BATCH_REPORT_DIR")
+	}
+
+	stats, details, err := Run("../../images", vision.NewDetector())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteReports(dir, stats, details); err != nil {
+		t.Fatal(err)
+	}
+	Print(os.Stdout, stats, details)
+}
+
+func TestSummarizeAndExport(t *testing.T) {
+	details := []Detail{
+		{Filename: "1-P4-black.jpg", Success: true, Result: vision.Result{X: 16, Y: 4, Color: "B", Confidence: 0.8}, ExpectedX: 16, ExpectedY: 4, Overlay: []byte("jpg")},
+		{Filename: "2-Q5-white.jpg", Result: vision.Result{X: 17, Y: 3, Color: "W"}, ExpectedX: 17, ExpectedY: 5, Distance: 2},
+		{Filename: "notes.jpg", Error: "解析文件名失败"},
+	}
+
+	stats := Summarize(details)
+	want := Stats{TotalCount: 2, SuccessCount: 1, FailureCount: 1, SuccessRate: 50, BlackCount: 1, MSE: 2, RMSE: math.Sqrt(2), MaxDistance: 2}
+	if *stats != want {
+		t.Errorf("Summarize() = %+v, want %+v", *stats, want)
+	}
+
+	var csvOut strings.Builder
+	if err := WriteCSV(&csvOut, details); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n"); len(lines) != 4 || lines[2] != "2-Q5-white.jpg,Q5,Q3,W,0.00,2.00,false," {
+		t.Errorf("CSV = %q", csvOut.String())
+	}
+
+	var jsonOut strings.Builder
+	if err := WriteJSON(&jsonOut, stats, details); err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Summary Stats
+		Details []Detail
+	}
+	if err := json.Unmarshal([]byte(jsonOut.String()), &report); err != nil {
+		t.Fatalf("JSON 无法解析: %v", err)
+	}
+	if report.Summary != want || len(report.Details) != 3 {
+		t.Errorf("JSON 汇总 = %+v", report.Summary)
+	}
+
+	var htmlOut strings.Builder
+	if err := WriteHTML(&htmlOut, stats, details); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"成功率 50.00%", `class="fail"`, "data:image/jpeg;base64,anBn"} {
+		if !strings.Contains(htmlOut.String(), s) {
+			t.Errorf("HTML 缺少 %q", s)
+		}
+	}
+
+	var table strings.Builder
+	Print(&table, stats, details)
+	if !strings.Contains(table.String(), "成功率 50.00%") {
+		t.Errorf("Print() 缺少统计: %s", table.String())
+	}
+}
@@ -0,0 +1,144 @@
+package bench
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"goboardsync/vision"
+
+	"gocv.io/x/gocv"
+)
+
+// WriteCSV 每张样本一行，便于在表格中比较不同提交的识别结果
+func WriteCSV(w io.Writer, details []Detail) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"filename", "expected", "detected", "color", "confidence", "distance", "success", "error"})
+	for _, d := range details {
+		cw.Write([]string{
+			d.Filename,
+			Coord(d.ExpectedX, d.ExpectedY),
+			Coord(d.Result.X, d.Result.Y),
+			d.Result.Color,
+			strconv.FormatFloat(d.Result.Confidence, 'f', 2, 64),
+			strconv.FormatFloat(d.Distance, 'f', 2, 64),
+			strconv.FormatBool(d.Success),
+			d.Error,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON 写出统计信息与每张样本的识别详情（含 Debug 信息）
+func WriteJSON(w io.Writer, stats *Stats, details []Detail) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Summary *Stats   `json:"summary"`
+		Details []Detail `json:"details"`
+	}{stats, details})
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"coord": Coord,
+	"overlay": func(jpg []byte) template.URL {
+		return template.URL("data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(jpg))
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>识别报告</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; vertical-align: top; }
+tr.fail { background: #fdd; }
+img { width: 240px; }
+</style>
+</head>
+<body>
+<h1>识别报告</h1>
+<p>总计 {{.Summary.TotalCount}}，成功 {{.Summary.SuccessCount}}，失败 {{.Summary.FailureCount}}，
+成功率 {{printf "%.2f" .Summary.SuccessRate}}%，RMSE {{printf "%.2f" .Summary.RMSE}}，最大误差 {{printf "%.2f" .Summary.MaxDistance}}</p>
+<table>
+<tr><th>文件名</th><th>预期</th><th>识别</th><th>置信度</th><th>误差</th><th>叠加图</th></tr>
+{{range .Details}}<tr{{if not .Success}} class="fail"{{end}}>
+<td>{{.Filename}}{{if .Error}}<br>{{.Error}}{{end}}</td>
+<td>{{coord .ExpectedX .ExpectedY}}</td>
+<td>{{coord .Result.X .Result.Y}} {{.Result.Color}}</td>
+<td>{{printf "%.2f" .Result.Confidence}}</td>
+<td>{{printf "%.2f" .Distance}}</td>
+<td>{{if .Overlay}}<img src="{{overlay .Overlay}}" alt="{{.Filename}}">{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// WriteHTML 写出单文件 HTML 报告，叠加图以 data URL 内嵌，便于存档与分享
+func WriteHTML(w io.Writer, stats *Stats, details []Detail) error {
+	return reportTemplate.Execute(w, struct {
+		Summary *Stats
+		Details []Detail
+	}{stats, details})
+}
+
+// WriteReports 在 dir 下写出 report.csv、report.json 与 report.html
+func WriteReports(dir string, stats *Stats, details []Detail) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建报告目录失败: %v", err)
+	}
+
+	writers := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"report.csv", func(w io.Writer) error { return WriteCSV(w, details) }},
+		{"report.json", func(w io.Writer) error { return WriteJSON(w, stats, details) }},
+		{"report.html", func(w io.Writer) error { return WriteHTML(w, stats, details) }},
+	}
+	for _, r := range writers {
+		f, err := os.Create(filepath.Join(dir, r.name))
+		if err != nil {
+			return fmt.Errorf("创建 %s 失败: %v", r.name, err)
+		}
+		err = r.write(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("写出 %s 失败: %v", r.name, err)
+		}
+	}
+	return nil
+}
+
+// Overlay 在 vision.DrawOverlay 的叠加图上再写出预期/识别坐标，返回 JPG。
+// 分辨率未配置棋盘角点或校正失败时返回 nil
+func Overlay(img gocv.Mat, result vision.Result, expectedX, expectedY int) []byte {
+	warped, err := vision.DrawOverlay(img, result)
+	if err != nil {
+		return nil
+	}
+	defer warped.Close()
+
+	info := fmt.Sprintf("Exp: %s, Got: %s", Coord(expectedX, expectedY), Coord(result.X, result.Y))
+	gocv.PutText(&warped, info, image.Pt(20, 100), gocv.FontHersheySimplex, 1.2, color.RGBA{255, 0, 255, 0}, 3)
+
+	buf, err := gocv.IMEncode(".jpg", warped)
+	if err != nil {
+		return nil
+	}
+	defer buf.Close()
+	return append([]byte(nil), buf.GetBytes()...)
+}
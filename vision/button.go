@@ -0,0 +1,85 @@
+package vision
+
+import (
+	"fmt"
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+// ButtonMinScore 模板匹配的最低相关性，低于该值视为画面中没有按钮
+const ButtonMinScore = 0.8
+
+// FixedConfirmButtonRegions 按分辨率配置的“确定/确认”按钮搜索区域（屏幕下部，横屏时为右下方）
+var FixedConfirmButtonRegions = map[string]image.Rectangle{
+	"1200x2670": image.Rect(0, 1900, 1200, 2670),
+	"2670x1200": image.Rect(1300, 800, 2670, 1200),
+}
+
+// ButtonTemplate 按钮截图模板，在搜索区域内做归一化相关匹配定位按钮
+type ButtonTemplate struct {
+	tmpl gocv.Mat
+}
+
+// LoadButtonTemplate 读取按钮截图（从目标分辨率的截图上裁下的按钮区域）
+func LoadButtonTemplate(path string) (*ButtonTemplate, error) {
+	tmpl := gocv.IMRead(path, gocv.IMReadGrayScale)
+	if tmpl.Empty() {
+		return nil, fmt.Errorf("无法读取按钮模板: %s", path)
+	}
+	return &ButtonTemplate{tmpl: tmpl}, nil
+}
+
+// Find 在 img 的 region 区域内查找按钮，返回按钮中心的屏幕坐标与相关性；
+// 相关性低于 ButtonMinScore 时 ok 为 false
+func (b *ButtonTemplate) Find(img gocv.Mat, region image.Rectangle) (center image.Point, score float64, ok bool) {
+	region = region.Intersect(image.Rect(0, 0, img.Cols(), img.Rows()))
+	if region.Dx() < b.tmpl.Cols() || region.Dy() < b.tmpl.Rows() {
+		return image.Point{}, 0, false
+	}
+
+	roi := img.Region(region)
+	defer roi.Close()
+
+	gray := gocv.NewMat()
+	defer gray.Close()
+	if roi.Channels() == 1 {
+		roi.CopyTo(&gray)
+	} else {
+		gocv.CvtColor(roi, &gray, gocv.ColorBGRToGray)
+	}
+
+	result := gocv.NewMat()
+	defer result.Close()
+	mask := gocv.NewMat()
+	defer mask.Close()
+	gocv.MatchTemplate(gray, b.tmpl, &result, gocv.TmCcoeffNormed, mask)
+	_, maxVal, _, maxLoc := gocv.MinMaxLoc(result)
+
+	center = region.Min.Add(maxLoc).Add(image.Pt(b.tmpl.Cols()/2, b.tmpl.Rows()/2))
+	return center, float64(maxVal), float64(maxVal) >= ButtonMinScore
+}
+
+func (b *ButtonTemplate) Close() {
+	b.tmpl.Close()
+}
+
+// FindConfirmButton 在截图下部查找“确定/确认”按钮，返回按钮中心的屏幕坐标。
+// 未配置按钮模板、分辨率没有对应的搜索区域或没有找到按钮时返回错误，调用方应退回到配置的坐标
+func (d *Detector) FindConfirmButton(img gocv.Mat) (image.Point, error) {
+	if d.ConfirmButton == nil {
+		return image.Point{}, fmt.Errorf("未配置确认按钮模板")
+	}
+
+	resKey := fmt.Sprintf("%dx%d", img.Cols(), img.Rows())
+	region, ok := FixedConfirmButtonRegions[resKey]
+	if !ok {
+		return image.Point{}, fmt.Errorf("未配置确认按钮搜索区域: %s", resKey)
+	}
+
+	center, score, ok := d.ConfirmButton.Find(img, region)
+	if !ok {
+		return image.Point{}, fmt.Errorf("未找到确认按钮（相关性 %.2f）", score)
+	}
+	return center, nil
+}
@@ -0,0 +1,39 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+func TestFindConfirmButton(t *testing.T) {
+	img := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(200, 200, 200, 0), 2670, 1200, gocv.MatTypeCV8UC3)
+	defer img.Close()
+
+	// 按钮从默认位置 (600, 2150) 下移到 (700, 2300)
+	button := image.Rect(550, 2250, 850, 2350)
+	gocv.Rectangle(&img, button, color.RGBA{40, 120, 230, 0}, -1)
+	gocv.PutText(&img, "OK", image.Pt(650, 2320), gocv.FontHersheySimplex, 2, color.RGBA{255, 255, 255, 0}, 4)
+
+	roi := img.Region(button.Inset(-10))
+	gray := gocv.NewMat()
+	gocv.CvtColor(roi, &gray, gocv.ColorBGRToGray)
+	roi.Close()
+
+	d := NewDetector(WithConfirmButton(&ButtonTemplate{tmpl: gray}))
+	defer d.ConfirmButton.Close()
+
+	got, err := d.FindConfirmButton(img)
+	if err != nil {
+		t.Fatalf("FindConfirmButton() error = %v", err)
+	}
+	if d := got.Sub(image.Pt(700, 2300)); d.X < -2 || d.X > 2 || d.Y < -2 || d.Y > 2 {
+		t.Errorf("FindConfirmButton() = %v, want (700,2300)", got)
+	}
+
+	if _, err := NewDetector().FindConfirmButton(img); err == nil {
+		t.Error("未配置模板时 FindConfirmButton() 应返回错误")
+	}
+}
@@ -0,0 +1,101 @@
+package vision
+
+import (
+	"fmt"
+	"image"
+	"sort"
+
+	"goboardsync/board"
+
+	"gocv.io/x/gocv"
+)
+
+// CameraCorners 摄像头画面中实体棋盘的四个角点（左上、右上、右下、左下），
+// 应取最外圈交叉点再向外延伸半格的位置；为空时每帧自动检测棋盘轮廓
+var CameraCorners []image.Point
+
+// DetectBoardCorners 在摄像头画面中寻找面积最大的四边形轮廓作为棋盘，返回排好序的四个角点
+func DetectBoardCorners(img gocv.Mat) ([]image.Point, error) {
+	gray := gocv.NewMat()
+	defer gray.Close()
+	gocv.CvtColor(img, &gray, gocv.ColorBGRToGray)
+	gocv.GaussianBlur(gray, &gray, image.Pt(5, 5), 0, 0, gocv.BorderDefault)
+
+	edges := gocv.NewMat()
+	defer edges.Close()
+	gocv.Canny(gray, &edges, 50, 150)
+
+	contours := gocv.FindContours(edges, gocv.RetrievalExternal, gocv.ChainApproxSimple)
+	defer contours.Close()
+
+	minArea := float64(img.Cols()*img.Rows()) * 0.1
+	var best []image.Point
+	bestArea := 0.0
+
+	for i := 0; i < contours.Size(); i++ {
+		contour := contours.At(i)
+		area := gocv.ContourArea(contour)
+		if area < minArea || area <= bestArea {
+			continue
+		}
+
+		approx := gocv.ApproxPolyDP(contour, 0.02*gocv.ArcLength(contour, true), true)
+		if approx.Size() == 4 {
+			best = approx.ToPoints()
+			bestArea = area
+		}
+		approx.Close()
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("未找到棋盘轮廓")
+	}
+	return OrderCorners(best), nil
+}
+
+// OrderCorners 把四个角点排成左上、右上、右下、左下的顺序
+func OrderCorners(pts []image.Point) []image.Point {
+	if len(pts) != 4 {
+		return pts
+	}
+
+	sorted := append([]image.Point(nil), pts...)
+	// x+y 最小为左上、最大为右下；y-x 最小为右上、最大为左下
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].X+sorted[i].Y < sorted[j].X+sorted[j].Y
+	})
+	tl, br := sorted[0], sorted[3]
+	tr, bl := sorted[1], sorted[2]
+	if tr.Y-tr.X > bl.Y-bl.X {
+		tr, bl = bl, tr
+	}
+
+	return []image.Point{tl, tr, br, bl}
+}
+
+// ReadPhysicalBoard 使用默认参数的 Detector 识别实体棋盘
+//
+// Deprecated: 使用 NewDetector(...).ReadPhysicalBoard。
+func ReadPhysicalBoard(img gocv.Mat) (board.Board, error) {
+	return NewDetector().ReadPhysicalBoard(img)
+}
+
+// ReadPhysicalBoard 把摄像头画面中的实体棋盘校正为正视图，并用 d.Classifier 识别每个交叉点
+func (d *Detector) ReadPhysicalBoard(img gocv.Mat) (board.Board, error) {
+	corners := CameraCorners
+	if len(corners) != 4 {
+		detected, err := DetectBoardCorners(img)
+		if err != nil {
+			return board.Board{}, err
+		}
+		corners = detected
+	}
+
+	warped, err := WarpBoard(img, corners)
+	if err != nil {
+		return board.Board{}, err
+	}
+	defer warped.Close()
+
+	return ReadBoard(warped, d.Classifier), nil
+}
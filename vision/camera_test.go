@@ -0,0 +1,26 @@
+package vision
+
+import (
+	"image"
+	"testing"
+)
+
+func TestOrderCorners(t *testing.T) {
+	want := []image.Point{{120, 80}, {980, 110}, {1010, 940}, {90, 900}}
+
+	tests := [][]image.Point{
+		{{120, 80}, {980, 110}, {1010, 940}, {90, 900}},
+		{{1010, 940}, {90, 900}, {120, 80}, {980, 110}},
+		{{90, 900}, {980, 110}, {120, 80}, {1010, 940}},
+	}
+
+	for _, pts := range tests {
+		got := OrderCorners(pts)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("OrderCorners(%v) = %v, want %v", pts, got, want)
+				break
+			}
+		}
+	}
+}
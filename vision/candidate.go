@@ -0,0 +1,56 @@
+package vision
+
+import (
+	"image"
+	"sort"
+)
+
+// MaxCandidates Result.Candidates 最多列出的候选数
+const MaxCandidates = 3
+
+// AmbiguityRatio 次选得分达到首选的这个比例时，认为首选不可靠（见 Result.Ambiguous）
+const AmbiguityRatio = 0.8
+
+// Candidate 最后一手的一个候选交叉点。X、Y 为手机坐标（1 起，Y 从上往下数），
+// Score 为该交叉点上角标轮廓面积占全部角标轮廓的比例，各候选之和不超过 1
+type Candidate struct {
+	X     int     `json:"x"`
+	Y     int     `json:"y"`
+	Score float64 `json:"score"`
+}
+
+// Ambiguous 次选与首选得分接近（不低于首选的 AmbiguityRatio）时返回 true，此时首选可能是
+// 横幅、动画等的误判，调用方宜结合其他信息在候选中选择，而不是直接采用 X、Y
+func (r Result) Ambiguous() bool {
+	return len(r.Candidates) > 1 && r.Candidates[1].Score >= r.Candidates[0].Score*AmbiguityRatio
+}
+
+// rankCandidates 把角标轮廓按所在的交叉点合并，按面积之和从大到小排列，最多返回 MaxCandidates 个。
+// contours 已按面积从大到小排列，第一个候选总是最大轮廓所在的交叉点
+func rankCandidates(contours []markerContour, width, height int) []Candidate {
+	var total float64
+	areas := make(map[image.Point]float64)
+	var order []image.Point
+	for _, c := range contours {
+		x, y, _ := calculateGrid(c.rect, width, height)
+		p := image.Pt(x, y)
+		if _, ok := areas[p]; !ok {
+			order = append(order, p)
+		}
+		areas[p] += c.area
+		total += c.area
+	}
+	if total == 0 {
+		return nil
+	}
+
+	// 最大轮廓所在的交叉点即识别结果，固定排在第一位；其余按面积之和排列
+	rest := order[1:]
+	sort.SliceStable(rest, func(i, j int) bool { return areas[rest[i]] > areas[rest[j]] })
+
+	var candidates []Candidate
+	for _, p := range order[:min(len(order), MaxCandidates)] {
+		candidates = append(candidates, Candidate{X: p.X + 1, Y: p.Y + 1, Score: areas[p] / total})
+	}
+	return candidates
+}
@@ -0,0 +1,50 @@
+package vision
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestRankCandidates(t *testing.T) {
+	// 190 见方的棋盘每格 10 像素
+	contours := []markerContour{
+		{rect: image.Rect(30, 30, 38, 38), area: 40},
+		{rect: image.Rect(100, 50, 108, 58), area: 25},
+		{rect: image.Rect(150, 150, 158, 158), area: 20},
+		{rect: image.Rect(101, 51, 104, 54), area: 10},
+		{rect: image.Rect(0, 180, 5, 185), area: 5},
+	}
+	got := rankCandidates(contours, 190, 190)
+
+	want := []Candidate{{X: 4, Y: 4, Score: 0.4}, {X: 11, Y: 6, Score: 0.35}, {X: 16, Y: 16, Score: 0.2}}
+	if len(got) != len(want) {
+		t.Fatalf("rankCandidates() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].X != want[i].X || got[i].Y != want[i].Y || math.Abs(got[i].Score-want[i].Score) > 1e-9 {
+			t.Errorf("候选 %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if rankCandidates(nil, 190, 190) != nil {
+		t.Errorf("没有轮廓时应返回 nil")
+	}
+}
+
+func TestAmbiguous(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []Candidate
+		want       bool
+	}{
+		{"没有候选", nil, false},
+		{"只有一个候选", []Candidate{{X: 4, Y: 4, Score: 1}}, false},
+		{"次选差得多", []Candidate{{X: 4, Y: 4, Score: 0.7}, {X: 5, Y: 4, Score: 0.3}}, false},
+		{"次选接近", []Candidate{{X: 4, Y: 4, Score: 0.5}, {X: 5, Y: 4, Score: 0.45}}, true},
+	}
+	for _, tt := range tests {
+		if got := (Result{Candidates: tt.candidates}).Ambiguous(); got != tt.want {
+			t.Errorf("%s: Ambiguous() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
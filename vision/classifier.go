@@ -0,0 +1,237 @@
+package vision
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+
+	"gocv.io/x/gocv"
+)
+
+// TemplateSize 分类模板的边长（像素）
+const TemplateSize = 32
+
+// StoneClasses 各类样本/模板使用的目录名与文件名
+var StoneClasses = map[board.Color]string{
+	board.Empty: "empty",
+	board.Black: "black",
+	board.White: "white",
+}
+
+// StoneClassifier 判断单个交叉点是空点、黑子还是白子，返回结果与置信度
+type StoneClassifier interface {
+	Classify(cell gocv.Mat) (board.Color, float64)
+}
+
+// DefaultClassifier 未指定分类器时使用的分类器
+//
+// Deprecated: 使用 WithClassifier 为每个 Detector 单独设置，此变量只作为 NewDetector 的默认值。
+var DefaultClassifier StoneClassifier = BrightnessClassifier{}
+
+// BrightnessClassifier 按交叉点中心区域的亮度与饱和度判断，未训练模板时使用
+type BrightnessClassifier struct{}
+
+func (BrightnessClassifier) Classify(cell gocv.Mat) (board.Color, float64) {
+	if cell.Empty() {
+		return board.Empty, 0
+	}
+
+	w, h := cell.Cols(), cell.Rows()
+	center := cell.Region(image.Rect(w/4, h/4, w*3/4, h*3/4))
+	defer center.Close()
+
+	hsv := gocv.NewMat()
+	defer hsv.Close()
+	gocv.CvtColor(center, &hsv, gocv.ColorBGRToHSV)
+
+	mean := hsv.Mean()
+	s, v := mean.Val2, mean.Val3
+
+	switch {
+	case v < 90:
+		return board.Black, 1 - v/90
+	case v > 200 && s < 50:
+		return board.White, 1 - s/50
+	}
+	return board.Empty, 0.5
+}
+
+// TemplateClassifier 与各类平均模板做归一化相关匹配，取相关性最高的类别
+type TemplateClassifier struct {
+	templates map[board.Color]gocv.Mat
+}
+
+// LoadTemplateClassifier 读取 dir 下的 empty.png、black.png、white.png
+func LoadTemplateClassifier(dir string) (*TemplateClassifier, error) {
+	c := &TemplateClassifier{templates: make(map[board.Color]gocv.Mat)}
+	for color, name := range StoneClasses {
+		path := filepath.Join(dir, name+".png")
+		tmpl := gocv.IMRead(path, gocv.IMReadGrayScale)
+		if tmpl.Empty() {
+			c.Close()
+			return nil, fmt.Errorf("无法读取模板: %s", path)
+		}
+		c.templates[color] = tmpl
+	}
+	return c, nil
+}
+
+func (c *TemplateClassifier) Classify(cell gocv.Mat) (board.Color, float64) {
+	sample := prepareCell(cell)
+	defer sample.Close()
+
+	mask := gocv.NewMat()
+	defer mask.Close()
+
+	best, bestScore := board.Empty, -1.0
+	for color, tmpl := range c.templates {
+		result := gocv.NewMat()
+		gocv.MatchTemplate(sample, tmpl, &result, gocv.TmCcoeffNormed, mask)
+		_, maxVal, _, _ := gocv.MinMaxLoc(result)
+		result.Close()
+
+		if float64(maxVal) > bestScore {
+			best, bestScore = color, float64(maxVal)
+		}
+	}
+	return best, bestScore
+}
+
+func (c *TemplateClassifier) Close() {
+	for _, tmpl := range c.templates {
+		tmpl.Close()
+	}
+}
+
+// prepareCell 把交叉点区域转为 TemplateSize 大小的灰度图
+func prepareCell(cell gocv.Mat) gocv.Mat {
+	gray := gocv.NewMat()
+	if cell.Channels() == 1 {
+		cell.CopyTo(&gray)
+	} else {
+		gocv.CvtColor(cell, &gray, gocv.ColorBGRToGray)
+	}
+
+	resized := gocv.NewMat()
+	gocv.Resize(gray, &resized, image.Pt(TemplateSize, TemplateSize), 0, 0, gocv.InterpolationArea)
+	gray.Close()
+	return resized
+}
+
+// TrainTemplates 读取 cropsDir/{empty,black,white}/ 下人工标注的样本，逐像素平均得到各类模板
+func TrainTemplates(cropsDir string) (map[board.Color]gocv.Mat, error) {
+	templates := make(map[board.Color]gocv.Mat)
+
+	for color, name := range StoneClasses {
+		files, err := os.ReadDir(filepath.Join(cropsDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("读取样本目录失败: %v", err)
+		}
+
+		sum := make([]float64, TemplateSize*TemplateSize)
+		count := 0
+		for _, file := range files {
+			lower := strings.ToLower(file.Name())
+			if file.IsDir() || !(strings.HasSuffix(lower, ".jpg") || strings.HasSuffix(lower, ".png")) {
+				continue
+			}
+
+			img := gocv.IMRead(filepath.Join(cropsDir, name, file.Name()), gocv.IMReadColor)
+			if img.Empty() {
+				continue
+			}
+			sample := prepareCell(img)
+			for i, v := range sample.ToBytes() {
+				sum[i] += float64(v)
+			}
+			sample.Close()
+			img.Close()
+			count++
+		}
+
+		if count == 0 {
+			return nil, fmt.Errorf("没有 %s 样本", name)
+		}
+
+		pixels := make([]byte, len(sum))
+		for i, v := range sum {
+			pixels[i] = byte(v / float64(count))
+		}
+		tmpl, err := gocv.NewMatFromBytes(TemplateSize, TemplateSize, gocv.MatTypeCV8U, pixels)
+		if err != nil {
+			return nil, fmt.Errorf("生成模板失败: %v", err)
+		}
+		templates[color] = tmpl
+	}
+
+	return templates, nil
+}
+
+// SaveTemplates 把模板保存为 dir/{empty,black,white}.png
+func SaveTemplates(templates map[board.Color]gocv.Mat, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for color, tmpl := range templates {
+		path := filepath.Join(dir, StoneClasses[color]+".png")
+		if !gocv.IMWrite(path, tmpl) {
+			return fmt.Errorf("保存模板失败: %s", path)
+		}
+	}
+	return nil
+}
+
+// CellRect 返回校正后棋盘上第 (gridX, gridY) 个交叉点（从左上角起，0 起）所在的格子
+func CellRect(warped gocv.Mat, gridX, gridY int) image.Rectangle {
+	cellW := float64(warped.Cols()) / 19.0
+	cellH := float64(warped.Rows()) / 19.0
+
+	rect := image.Rect(
+		int(float64(gridX)*cellW),
+		int(float64(gridY)*cellH),
+		int(float64(gridX+1)*cellW),
+		int(float64(gridY+1)*cellH),
+	)
+	return rect.Intersect(image.Rect(0, 0, warped.Cols(), warped.Rows()))
+}
+
+// ClassifyAt 对校正后棋盘上的单个交叉点分类
+func ClassifyAt(warped gocv.Mat, gridX, gridY int, c StoneClassifier) (board.Color, float64) {
+	cell := warped.Region(CellRect(warped, gridX, gridY))
+	defer cell.Close()
+	return c.Classify(cell)
+}
+
+// ReadScreenBoard 按分辨率对应的棋盘位置截取手机截图中的棋盘，用 d.Classifier 识别整个局面
+func (d *Detector) ReadScreenBoard(img gocv.Mat) (board.Board, error) {
+	_, corners, ok := d.boardCorners(img)
+	if !ok {
+		return board.Board{}, fmt.Errorf("不支持的图片分辨率: %dx%d", img.Cols(), img.Rows())
+	}
+
+	warped, err := d.boardView(img, corners, &DebugInfo{})
+	if err != nil {
+		return board.Board{}, err
+	}
+	defer warped.Close()
+
+	return ReadBoard(warped, d.Classifier), nil
+}
+
+// ReadBoard 逐个交叉点分类，重建整个棋盘局面（warped 为 WarpBoard 的输出）
+func ReadBoard(warped gocv.Mat, c StoneClassifier) board.Board {
+	var b board.Board
+	for gx := 0; gx < coords.Size; gx++ {
+		for gy := 0; gy < coords.Size; gy++ {
+			color, _ := ClassifyAt(warped, gx, gy, c)
+			kx, ky := coords.FromPhone(gx+1, gy+1)
+			b.Set(kx, ky, color)
+		}
+	}
+	return b
+}
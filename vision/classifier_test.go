@@ -0,0 +1,81 @@
+package vision
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+
+	"gocv.io/x/gocv"
+)
+
+func TestBrightnessClassifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		bgr      gocv.Scalar
+		expected board.Color
+	}{
+		{"黑子", gocv.NewScalar(30, 30, 30, 0), board.Black},
+		{"白子", gocv.NewScalar(240, 240, 240, 0), board.White},
+		{"棋盘木色", gocv.NewScalar(100, 180, 220, 0), board.Empty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cell := gocv.NewMatWithSizeFromScalar(tt.bgr, 54, 54, gocv.MatTypeCV8UC3)
+			defer cell.Close()
+
+			got, _ := BrightnessClassifier{}.Classify(cell)
+			if got != tt.expected {
+				t.Errorf("Classify() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadBoardLastMove(t *testing.T) {
+	imagesDir := "../images"
+	files, _ := os.ReadDir(imagesDir)
+
+	total, correct := 0, 0
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".jpg") {
+			continue
+		}
+
+		_, color, expX, expY, err := ParseSampleFilename(file.Name())
+		if err != nil {
+			continue
+		}
+
+		img := gocv.IMRead(filepath.Join(imagesDir, file.Name()), gocv.IMReadColor)
+		if img.Empty() {
+			continue
+		}
+
+		warped, err := WarpBoard(img, FixedBoardCorners["1200x2670"])
+		img.Close()
+		if err != nil {
+			t.Fatalf("WarpBoard(%s) unexpected error: %v", file.Name(), err)
+		}
+
+		b := ReadBoard(warped, DefaultClassifier)
+		warped.Close()
+
+		kx, ky := coords.FromPhone(expX, expY)
+		total++
+		if b.At(kx, ky) == board.ParseColor(color) {
+			correct++
+		} else {
+			fmt.Printf("分类错误: %s 得到 %q\n", file.Name(), b.At(kx, ky).String())
+		}
+	}
+
+	if total > 0 {
+		t.Logf("最后一手分类正确 %d/%d", correct, total)
+	}
+}
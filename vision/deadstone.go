@@ -0,0 +1,106 @@
+package vision
+
+import (
+	"fmt"
+	"image"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+
+	"gocv.io/x/gocv"
+)
+
+// DeadMark 数子阶段 App 在死子上所画标记的判断参数。腾讯围棋在死子中央画一个与棋子反色的小方块
+// （黑子上为浅色、白子上为深色），其他 App 或皮肤的标记大小与深浅不同，可按实际截图调整
+type DeadMark struct {
+	// Size 标记（棋子中央检查区域）的边长占一格的比例，为 0 时使用 DefaultDeadMark 的值
+	Size float64
+	// MinContrast 中央区域与棋子外圈的平均灰度差（0-255）达到此值时认为棋子带标记，为 0 时使用 DefaultDeadMark 的值
+	MinContrast float64
+}
+
+// DefaultDeadMark 腾讯围棋默认皮肤下的死子标记
+var DefaultDeadMark = DeadMark{Size: 0.3, MinContrast: 60}
+
+// stoneSquare 比较外圈时取的棋子中央方框的边长（格），棋子直径约 0.94 格，方框在棋子内
+const stoneSquare = 0.6
+
+func (m DeadMark) orDefault() DeadMark {
+	if m.Size <= 0 {
+		m.Size = DefaultDeadMark.Size
+	}
+	if m.MinContrast <= 0 {
+		m.MinContrast = DefaultDeadMark.MinContrast
+	}
+	return m
+}
+
+// DeadStones 找出校正后棋盘 warped 上带死子标记的棋子。标记会让分类器把棋子认错，
+// 因此只检查 b（标记出现前的局面）中有子的交叉点，按 b 中的颜色判断标记的深浅。返回 KaTrain 坐标
+func DeadStones(warped gocv.Mat, b *board.Board, m DeadMark) []image.Point {
+	m = m.orDefault()
+	gray := gocv.NewMat()
+	defer gray.Close()
+	gocv.CvtColor(warped, &gray, gocv.ColorBGRToGray)
+
+	var dead []image.Point
+	for x := range coords.Size {
+		for y := range coords.Size {
+			c := b.At(x, y)
+			if c == board.Empty {
+				continue
+			}
+			gx, gy := coords.ToPhone(x, y)
+			contrast := markContrast(gray, CellRect(gray, gx-1, gy-1), m.Size)
+			if c == board.White {
+				contrast = -contrast
+			}
+			if contrast >= m.MinContrast {
+				dead = append(dead, image.Pt(x, y))
+			}
+		}
+	}
+	return dead
+}
+
+// ReadDeadStones 按分辨率对应的棋盘位置截取截图 img 中的棋盘，用 d.DeadMark 找出 b 中带死子标记的棋子
+func (d *Detector) ReadDeadStones(img gocv.Mat, b *board.Board) ([]image.Point, error) {
+	_, corners, ok := d.boardCorners(img)
+	if !ok {
+		return nil, fmt.Errorf("不支持的图片分辨率: %dx%d", img.Cols(), img.Rows())
+	}
+
+	warped, err := d.boardView(img, corners, &DebugInfo{})
+	if err != nil {
+		return nil, err
+	}
+	defer warped.Close()
+
+	return DeadStones(warped, b, d.DeadMark), nil
+}
+
+// markContrast 格子 cell 中央边长 size 格的区域比外圈（stoneSquare 方框内的其余部分）平均亮多少
+func markContrast(gray gocv.Mat, cell image.Rectangle, size float64) float64 {
+	inner, outer := centered(cell, size), centered(cell, stoneSquare)
+	innerArea, outerArea := float64(inner.Dx()*inner.Dy()), float64(outer.Dx()*outer.Dy())
+	if innerArea == 0 || outerArea <= innerArea {
+		return 0
+	}
+
+	innerMean, outerMean := regionMean(gray, inner), regionMean(gray, outer)
+	ring := (outerMean*outerArea - innerMean*innerArea) / (outerArea - innerArea)
+	return innerMean - ring
+}
+
+// centered 格子 cell 中央边长为 frac 格的方框
+func centered(cell image.Rectangle, frac float64) image.Rectangle {
+	c := cell.Min.Add(cell.Max).Div(2)
+	hw, hh := int(float64(cell.Dx())*frac/2), int(float64(cell.Dy())*frac/2)
+	return image.Rect(c.X-hw, c.Y-hh, c.X+hw, c.Y+hh)
+}
+
+func regionMean(gray gocv.Mat, r image.Rectangle) float64 {
+	region := gray.Region(r)
+	defer region.Close()
+	return region.Mean().Val1
+}
@@ -0,0 +1,46 @@
+package vision
+
+// DebugInfo 识别过程的附加信息，供排查问题。每帧识别时从零值开始填写，不同帧之间不共享；
+// 较占内存的文字信息放在 DebugDetail 中，只在开启 DebugDetail 时分配，长时间运行时每帧只多几个定长字段
+type DebugInfo struct {
+	// Source 结果不是由截图识别得到时的来源，如 move_list（手数列表）、camera（摄像头）
+	Source string `json:"source,omitempty"`
+	// MoveNumber 传入的手数，InferredMoveNumber 没有 OCR 手数时从盘面推断出的手数
+	MoveNumber         int `json:"move_number"`
+	InferredMoveNumber int `json:"inferred_move_number,omitempty"`
+	// BoardView 取棋盘的方式：warp（透视变换）或 region（直接截取）
+	BoardView string `json:"board_view,omitempty"`
+	// Skin 识别用的棋盘皮肤，Lighting 光线分档（见 LightingBucket）
+	Skin     string `json:"skin,omitempty"`
+	Lighting string `json:"lighting,omitempty"`
+	Fusion   bool   `json:"fusion,omitempty"`
+	// FinalStatus success、failed_at_warp 或 failed_at_detection，不支持的分辨率时为空
+	FinalStatus string `json:"final_status,omitempty"`
+	// SmoothVotes 开启 SmoothFrames 时报告的交叉点在窗口中的票数，SmoothedFrom 被表决换掉的本帧坐标
+	SmoothVotes  int     `json:"smooth_votes,omitempty"`
+	SmoothedFrom *[2]int `json:"smoothed_from,omitempty"`
+
+	*DebugDetail
+}
+
+// DebugDetail 开启 DebugDetail 时才记录的详细信息
+type DebugDetail struct {
+	ImageSize       string  `json:"image_size,omitempty"`
+	FixedResolution string  `json:"fixed_resolution,omitempty"`
+	WarpError       string  `json:"warp_error,omitempty"`
+	DetectionError  string  `json:"detection_error,omitempty"`
+	StoneColor      string  `json:"stone_color,omitempty"`
+	StoneConfidence float64 `json:"stone_confidence,omitempty"`
+	ModelColor      string  `json:"model_color,omitempty"`
+}
+
+// detail 开启 DebugDetail 时返回 info 的详细信息（首次调用时分配），否则返回 nil，调用方据此跳过格式化
+func (d *Detector) detail(info *DebugInfo) *DebugDetail {
+	if !d.DebugDetail {
+		return nil
+	}
+	if info.DebugDetail == nil {
+		info.DebugDetail = &DebugDetail{}
+	}
+	return info.DebugDetail
+}
@@ -1,19 +1,19 @@
 package vision
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"image"
-	"io"
 	"math"
-	"mime/multipart"
-	"net/http"
 	"regexp"
-	"strconv"
-	"strings"
+	"sort"
+	"sync/atomic"
 	"time"
 
+	"goboardsync/board"
+	"goboardsync/coords"
+	"goboardsync/ocr"
+
 	"gocv.io/x/gocv"
 )
 
@@ -21,6 +21,8 @@ const (
 	BoardWarpSize = 1024
 )
 
+// FixedBoardCorners 按分辨率配置的棋盘四角（左上、右上、右下、左下）。
+// 横屏（含平板横放）的截图宽高互换后缩放，棋盘位于左侧
 var FixedBoardCorners = map[string][]image.Point{
 	"1200x2670": {
 		{40, 536},
@@ -28,153 +30,305 @@ var FixedBoardCorners = map[string][]image.Point{
 		{1160, 1650},
 		{40, 1650},
 	},
+	"2670x1200": {
+		{40, 40},
+		{1160, 40},
+		{1160, 1154},
+		{40, 1154},
+	},
+}
+
+// ClockRegions 双方计时显示区域
+type ClockRegions struct {
+	Black image.Rectangle
+	White image.Rectangle
+}
+
+// FixedClockRegions 按分辨率配置的计时区域（腾讯围棋对局界面，棋盘上方的双方信息栏）
+var FixedClockRegions = map[string]ClockRegions{
+	"1200x2670": {
+		Black: image.Rect(150, 400, 450, 480),
+		White: image.Rect(750, 400, 1050, 480),
+	},
+	"2670x1200": {
+		Black: image.Rect(1300, 80, 1600, 160),
+		White: image.Rect(1900, 80, 2200, 160),
+	},
+}
+
+// PlayerRegions 双方对局者名字的显示区域
+type PlayerRegions struct {
+	Black image.Rectangle
+	White image.Rectangle
+}
+
+// FixedPlayerRegions 按分辨率配置的对局者名字区域（计时上方）
+var FixedPlayerRegions = map[string]PlayerRegions{
+	"1200x2670": {
+		Black: image.Rect(150, 320, 450, 400),
+		White: image.Rect(750, 320, 1050, 400),
+	},
+	"2670x1200": {
+		Black: image.Rect(1300, 10, 1600, 80),
+		White: image.Rect(1900, 10, 2200, 80),
+	},
+}
+
+// MoveListPanel 棋谱面板配置。OpenTap 为零值时表示面板常驻，直接识别当前截图
+type MoveListPanel struct {
+	Region   image.Rectangle
+	OpenTap  image.Point
+	CloseTap image.Point
+}
+
+// FixedMoveListPanels 按分辨率配置的棋谱面板（棋盘下方的手顺区域）
+var FixedMoveListPanels = map[string]MoveListPanel{
+	"1200x2670": {
+		Region: image.Rect(40, 1700, 1160, 2050),
+	},
+	"2670x1200": {
+		Region: image.Rect(1300, 260, 2600, 900),
+	},
 }
 
+// Result 识别结果。X、Y 为手机坐标（1 起，Y 从上往下数），未识别到时为 0
 type Result struct {
-	Move       int             `json:"move"`
-	Color      string          `json:"color"`
-	X          int             `json:"x"`
-	Y          int             `json:"y"`
-	Confidence float64         `json:"confidence"`
-	MarkerRect image.Rectangle `json:"marker_rect"`
-	Debug      map[string]any  `json:"debug"`
+	Move       int     `json:"move"`
+	Color      string  `json:"color"`
+	X          int     `json:"x"`
+	Y          int     `json:"y"`
+	Confidence float64 `json:"confidence"`
+	// MarkerRect 最后一手角标的外接矩形，StoneCenter 对应棋子的中心，
+	// 都换算到 WarpSize 大小的校正棋盘坐标，与截图分辨率及取图方式无关
+	MarkerRect  image.Rectangle `json:"marker_rect"`
+	StoneCenter image.Point     `json:"stone_center"`
+	// GridIndex 交叉点下标（0 起，从左上角数），即 X-1、Y-1
+	GridIndex image.Point `json:"grid_index"`
+	// WarpSize 校正棋盘的尺寸（BoardWarpSize 见方），棋盘定位失败时为零值
+	WarpSize image.Point `json:"warp_size"`
+	// Signals 开启 Fusion 时各路信号对结果的表态，未开启时为空
+	Signals []Signal `json:"signals,omitempty"`
+	// Candidates 按得分从高到低排列的候选交叉点（最多 MaxCandidates 个，第一个即 X、Y），
+	// 由各角标轮廓的面积得出；开启 Fusion 或没找到角标时为空。首选不可靠时调用方可以改选其他候选（见 Ambiguous）
+	Candidates []Candidate `json:"candidates,omitempty"`
+	// Debug 识别过程的附加信息（取图方式、皮肤、失败环节等），供排查问题，字段不保证稳定
+	Debug DebugInfo `json:"debug"`
 }
 
+// DefaultOCREndpoint 附带的本地 OCR 服务地址
+const DefaultOCREndpoint = "http://127.0.0.1:5001/ocr"
+
+// Detector 识别最后一手。识别参数都保存在实例中，多台设备可以各用一套参数
 type Detector struct {
 	OCREndpoint string
+	// OCRBackend OCR 服务的请求与响应格式，零值时使用 ocr.DefaultBackend；OCRLanguage 识别语言，含义取决于服务
+	OCRBackend  ocr.Backend
+	OCRLanguage string
+	// OCR 设置后代替 OCREndpoint、OCRBackend 与 OCRLanguage 识别文本，用于带额度与后备顺序的 ocr.Chain
+	OCR ocr.Recognizer
+	// MoveNumberPatterns 从 OCR 文本中提取手数的正则，为空时使用 ocr.ExtractMoveNumber 的内置规则
+	MoveNumberPatterns []*regexp.Regexp
+	// Skin 棋盘皮肤名称，为空时按棋盘底色自动识别
+	Skin string
+	// NormalizeLighting 按棋盘底色的亮度自适应调整角标阈值
+	NormalizeLighting bool
+	// SkipWarpWhenAligned 棋盘角点与坐标轴平行时直接截取棋盘区域
+	SkipWarpWhenAligned bool
+	// Classifier 交叉点分类器，OCR 未识别到手数时用于判断颜色
+	Classifier StoneClassifier
+	// BoardModel 已同步的局面，OCR 未识别到手数且分类器也无法判断时，按双方棋子数推断颜色。
+	// 调用方需保证识别进行时不修改它
+	BoardModel *board.Board
+	// Game 已同步的对局，OCR 未识别到手数时按屏幕上的棋子数加上对局中的提子数推断手数
+	Game *board.Game
+	// Fusion 不再按“手数奇偶→角标→分类”的固定顺序判断，而是对角标位置与新出现的棋子逐一打分，
+	// 取与各路信号最一致的结果（见 SignalWeights）；每帧多做一次整盘分类
+	Fusion bool
+	// MinMarkerArea 角标轮廓的最小面积（像素），更小的轮廓视为噪点
+	MinMarkerArea float64
+	// MarkerExclusions 找角标时忽略的区域（校正棋盘坐标），遮住压在棋盘上的横幅、聊天气泡、头像等
+	// 含红蓝色的界面元素。每台设备一个 Detector，可以按各自 App 的界面分别设置
+	MarkerExclusions []Polygon
+	// SmoothFrames 大于 1 时对最近这么多帧识别出的交叉点做多数表决，角标在相邻交叉点之间闪烁时
+	// 保持原来的结果；新的一手要在窗口中占多数后才报告，相应地晚一两帧
+	SmoothFrames int
+	// WatchInterval Watch 的截图间隔，为 0 时使用 DefaultWatchInterval
+	WatchInterval time.Duration
+	// MinConfidence Watch 只发送置信度不低于该值的结果
+	MinConfidence float64
+	// ConfirmButton “确定/确认”按钮模板，为 nil 时 FindConfirmButton 总是失败
+	ConfirmButton *ButtonTemplate
+	// DeadMark 数子阶段死子标记的判断参数（见 ReadDeadStones），零值时使用 DefaultDeadMark
+	DeadMark DeadMark
+	// DebugDetail 是否在 Result.Debug 中记录图片尺寸、错误原因等详细信息（见 DebugDetail），
+	// 不保存调试文件时没有用处，关闭可省去每帧的分配
+	DebugDetail bool
+	// BoardCorners 只对这个 Detector 生效的棋盘四角，按分辨率（如 "1920x1080"）查找，优先于 FixedBoardCorners，
+	// 用于桌面客户端截图等不在 FixedBoardCorners 中的画面。调用方需保证识别进行时不修改它
+	BoardCorners map[string][]image.Point
+
+	tuning atomic.Pointer[Tuning]
+	// prevScreen 开启 Fusion 时上一帧分类出的局面，用于比较出新出现的棋子
+	prevScreen atomic.Pointer[board.Board]
+	smoother   smoother
 }
 
-func NewDetector() *Detector {
-	return &Detector{
-		OCREndpoint: "http://127.0.0.1:5001/ocr",
+// NewDetector 创建识别器，未通过 opts 指定的参数取默认值
+// （为兼容旧代码，默认值来自已弃用的包级变量 ForcedSkin、NormalizeLighting 等）
+func NewDetector(opts ...Option) *Detector {
+	d := &Detector{
+		OCREndpoint:         DefaultOCREndpoint,
+		Skin:                ForcedSkin,
+		NormalizeLighting:   NormalizeLighting,
+		SkipWarpWhenAligned: SkipWarpWhenAligned,
+		Classifier:          DefaultClassifier,
 	}
-}
-
-func (d *Detector) FetchMoveNumberFromOCR(img gocv.Mat) (int, error) {
-	if img.Empty() {
-		return 0, fmt.Errorf("图片为空")
+	for _, opt := range opts {
+		opt(d)
 	}
+	return d
+}
 
-	buf := new(bytes.Buffer)
-	imgBytes, err := gocv.IMEncode(".jpg", img)
-	if err != nil {
-		return 0, fmt.Errorf("编码图片失败: %v", err)
+// boardCorners 截图 img 的分辨率及对应的棋盘四角：先查 d.BoardCorners，再查 FixedBoardCorners
+func (d *Detector) boardCorners(img gocv.Mat) (string, []image.Point, bool) {
+	key := fmt.Sprintf("%dx%d", img.Cols(), img.Rows())
+	if c, ok := d.BoardCorners[key]; ok {
+		return key, c, true
 	}
-	defer imgBytes.Close()
-	buf.Write(imgBytes.GetBytes())
+	c, ok := FixedBoardCorners[key]
+	return key, c, ok
+}
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+// SetTuning 替换可热更新的识别参数，可在识别进行中调用
+func (d *Detector) SetTuning(t Tuning) {
+	d.tuning.Store(&t)
+}
 
-	part, err := writer.CreateFormFile("file", "image.jpg")
-	if err != nil {
-		return 0, fmt.Errorf("创建表单文件失败: %v", err)
+// Tuning 返回当前的可热更新参数
+func (d *Detector) Tuning() Tuning {
+	if t := d.tuning.Load(); t != nil {
+		return *t
 	}
+	return Tuning{}
+}
 
-	_, err = io.Copy(part, buf)
+func (d *Detector) FetchMoveNumberFromOCR(img gocv.Mat) (int, error) {
+	fullText, err := d.recognizeText(img)
 	if err != nil {
-		return 0, fmt.Errorf("写入图片数据失败: %v", err)
+		return 0, err
 	}
-	writer.Close()
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest("POST", d.OCREndpoint, body)
-	if err != nil {
-		return 0, fmt.Errorf("创建请求失败: %v", err)
+	moveNumber := ocr.ExtractMoveNumberWith(fullText, d.MoveNumberPatterns)
+
+	if moveNumber > 0 {
+		return moveNumber, nil
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0, fmt.Errorf("OCR 请求失败: %v", err)
+	return 0, fmt.Errorf("未识别到有效手数")
+}
+
+// FetchClockFromOCR 识别 region 区域内的计时信息（包干时间或读秒）
+func (d *Detector) FetchClockFromOCR(img gocv.Mat, region image.Rectangle) (ocr.Clock, error) {
+	if img.Empty() {
+		return ocr.Clock{}, fmt.Errorf("图片为空")
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		respData, _ := io.ReadAll(resp.Body)
-		return 0, fmt.Errorf("OCR 响应错误: %d, 响应: %s", resp.StatusCode, string(respData))
+	region = region.Intersect(image.Rect(0, 0, img.Cols(), img.Rows()))
+	if region.Empty() {
+		return ocr.Clock{}, fmt.Errorf("计时区域超出图片范围")
 	}
 
-	respData, err := io.ReadAll(resp.Body)
+	roi := img.Region(region)
+	defer roi.Close()
+
+	text, err := d.recognizeText(roi)
 	if err != nil {
-		return 0, fmt.Errorf("读取响应失败: %v", err)
+		return ocr.Clock{}, err
 	}
 
-	var allText strings.Builder
+	return ocr.ParseClock(text)
+}
 
-	var results []struct {
-		Words string `json:"words"`
+// FetchPlayerNames 通过 OCR 读取双方对局者的名字，读不到的一方为空字符串
+func (d *Detector) FetchPlayerNames(img gocv.Mat) (string, string, error) {
+	regions, ok := FixedPlayerRegions[fmt.Sprintf("%dx%d", img.Cols(), img.Rows())]
+	if !ok {
+		return "", "", fmt.Errorf("未配置对局者区域: %dx%d", img.Cols(), img.Rows())
 	}
-	err = json.Unmarshal(respData, &results)
-	if err == nil && len(results) > 0 {
-		for _, r := range results {
-			allText.WriteString(r.Words)
-			allText.WriteString(" ")
-		}
-	} else {
-		var wrapper struct {
-			Results []struct {
-				Words string `json:"words"`
-			} `json:"results"`
+
+	var names [2]string
+	for i, region := range []image.Rectangle{regions.Black, regions.White} {
+		region = region.Intersect(image.Rect(0, 0, img.Cols(), img.Rows()))
+		if region.Empty() {
+			continue
 		}
-		if err2 := json.Unmarshal(respData, &wrapper); err2 == nil && len(wrapper.Results) > 0 {
-			for _, r := range wrapper.Results {
-				allText.WriteString(r.Words)
-				allText.WriteString(" ")
-			}
-		} else {
-			allText.WriteString(string(respData))
+
+		roi := img.Region(region)
+		text, err := d.recognizeText(roi)
+		roi.Close()
+		if err != nil {
+			return "", "", err
 		}
+		names[i] = ocr.ParsePlayerName(text)
 	}
+	return names[0], names[1], nil
+}
 
-	fullText := strings.TrimSpace(allText.String())
-	moveNumber := extractMoveNumber(fullText)
+// FetchLastMoveFromMoveList 通过 OCR 读取棋谱面板，返回最后一手的手机坐标（1-19）与手数（未知时为 0）
+func (d *Detector) FetchLastMoveFromMoveList(img gocv.Mat, region image.Rectangle) (int, int, int, error) {
+	if img.Empty() {
+		return 0, 0, 0, fmt.Errorf("图片为空")
+	}
 
-	if moveNumber > 0 {
-		return moveNumber, nil
+	region = region.Intersect(image.Rect(0, 0, img.Cols(), img.Rows()))
+	if region.Empty() {
+		return 0, 0, 0, fmt.Errorf("棋谱区域超出图片范围")
 	}
 
-	return 0, fmt.Errorf("未识别到有效手数")
-}
+	roi := img.Region(region)
+	defer roi.Close()
 
-func extractMoveNumber(text string) int {
-	if text == "" {
-		return 0
+	text, err := d.recognizeText(roi)
+	if err != nil {
+		return 0, 0, 0, err
 	}
 
-	patterns := []struct {
-		name     string
-		pattern  string
-		priority int
-	}{
-		{"中文格式", `第\s*(\d+)\s*手`, 1},
-		{"纯数字+手", `(\d+)\s*手`, 2},
-		{"井号格式", `#\s*(\d+)`, 3},
-		{"move格式", `(?i)move\s*:?\s*(\d+)`, 4},
-		{"Step格式", `(?i)step\s*:?\s*(\d+)`, 5},
-		{"最后数字", `(\d+)$`, 6},
+	coord, move, err := ocr.ParseMoveList(text)
+	if err != nil {
+		return 0, 0, 0, err
 	}
 
-	for _, p := range patterns {
-		re := regexp.MustCompile(p.pattern)
-		matches := re.FindStringSubmatch(text)
-		if len(matches) > 1 {
-			num, err := strconv.Atoi(matches[1])
-			if err == nil && num > 0 && num < 2000 {
-				return num
-			}
-		}
+	kx, ky, err := coords.Parse(coord, coords.Tencent)
+	if err != nil {
+		return 0, 0, 0, err
 	}
 
-	nums := regexp.MustCompile(`(\d+)`).FindAllString(text, -1)
+	x, y := coords.ToPhone(kx, ky)
+	return x, y, move, nil
+}
 
-	for i := len(nums) - 1; i >= 0; i-- {
-		if num, err := strconv.Atoi(nums[i]); err == nil && num > 0 && num < 500 {
-			return num
-		}
+func (d *Detector) recognizeText(img gocv.Mat) (string, error) {
+	if img.Empty() {
+		return "", fmt.Errorf("图片为空")
+	}
+
+	imgBytes, err := gocv.IMEncode(".jpg", img)
+	if err != nil {
+		return "", fmt.Errorf("编码图片失败: %v", err)
 	}
+	defer imgBytes.Close()
 
-	return 0
+	// 识别逐帧进行，不跟随调用方取消；单次请求的时长由 OCR 客户端的 Timeout 限制
+	ctx := context.Background()
+	if d.OCR != nil {
+		return d.OCR.Recognize(ctx, imgBytes.GetBytes())
+	}
+	client := ocr.NewClient(d.OCREndpoint)
+	if d.OCRBackend.NewRequest != nil {
+		client.Backend = d.OCRBackend
+	}
+	client.Language = d.OCRLanguage
+	return client.Recognize(ctx, imgBytes.GetBytes())
 }
 
 func WarpBoard(img gocv.Mat, corners []image.Point) (gocv.Mat, error) {
@@ -206,24 +360,97 @@ func WarpBoard(img gocv.Mat, corners []image.Point) (gocv.Mat, error) {
 	return warped, nil
 }
 
+// SkipWarpWhenAligned 棋盘角点与坐标轴平行时（模拟器、截图输入），直接截取原图中的棋盘区域，
+// 省去每帧一次的全尺寸透视变换
+//
+// Deprecated: 使用 WithWarpSkip 为每个 Detector 单独设置，此变量只作为 NewDetector 的默认值。
+var SkipWarpWhenAligned = true
+
+// AlignedBoardRect 四个角点（左上、右上、右下、左下）构成与坐标轴平行的矩形时返回该矩形
+func AlignedBoardRect(corners []image.Point) (image.Rectangle, bool) {
+	if len(corners) != 4 {
+		return image.Rectangle{}, false
+	}
+
+	tl, tr, br, bl := corners[0], corners[1], corners[2], corners[3]
+	if tl.Y != tr.Y || bl.Y != br.Y || tl.X != bl.X || tr.X != br.X {
+		return image.Rectangle{}, false
+	}
+
+	rect := image.Rectangle{Min: tl, Max: br}
+	if rect.Dx() <= 0 || rect.Dy() <= 0 {
+		return image.Rectangle{}, false
+	}
+	return rect, true
+}
+
+// boardView 返回棋盘区域图像：角点与坐标轴平行时直接截取，否则做透视变换
+func (d *Detector) boardView(img gocv.Mat, corners []image.Point, debugInfo *DebugInfo) (gocv.Mat, error) {
+	if d.SkipWarpWhenAligned {
+		rect, ok := AlignedBoardRect(corners)
+		if ok && rect.In(image.Rect(0, 0, img.Cols(), img.Rows())) {
+			debugInfo.BoardView = "region"
+			return img.Region(rect), nil
+		}
+	}
+
+	debugInfo.BoardView = "warp"
+	return WarpBoard(img, corners)
+}
+
+// toWarpSpace 把棋盘区域内的矩形换算到 BoardWarpSize 大小的校正棋盘坐标，使 MarkerRect 与取图方式无关
+func toWarpSpace(r image.Rectangle, w, h int) image.Rectangle {
+	if w == BoardWarpSize && h == BoardWarpSize || w == 0 || h == 0 {
+		return r
+	}
+
+	sx := float64(BoardWarpSize) / float64(w)
+	sy := float64(BoardWarpSize) / float64(h)
+	return image.Rect(
+		int(float64(r.Min.X)*sx),
+		int(float64(r.Min.Y)*sy),
+		int(float64(r.Max.X)*sx),
+		int(float64(r.Max.Y)*sy),
+	)
+}
+
+// DetectLastMoveCoord 使用默认参数的 Detector 识别最后一手
+//
+// Deprecated: 使用 NewDetector(...).DetectLastMoveCoord，识别参数不再依赖包级变量。
 func DetectLastMoveCoord(img gocv.Mat, moveNumber int) (Result, error) {
-	debugInfo := make(map[string]any)
-	debugInfo["image_size"] = fmt.Sprintf("%dx%d", img.Cols(), img.Rows())
-	debugInfo["move_number"] = moveNumber
+	return NewDetector().DetectLastMoveCoord(img, moveNumber)
+}
+
+// DetectLastMoveCoord 定位棋盘并按角标识别最后一手，moveNumber 的奇偶决定颜色
+// （为 0 时先按 Game 从盘面推断手数，仍无法确定时按交叉点分类判断）。
+// 开启 SmoothFrames 时报告最近几帧多数表决的交叉点
+func (d *Detector) DetectLastMoveCoord(img gocv.Mat, moveNumber int) (Result, error) {
+	r, err := d.detectLastMoveCoord(img, moveNumber)
+	if err != nil {
+		return r, err
+	}
+	return d.smooth(r), nil
+}
+
+func (d *Detector) detectLastMoveCoord(img gocv.Mat, moveNumber int) (Result, error) {
+	debugInfo := DebugInfo{MoveNumber: moveNumber}
+	detail := d.detail(&debugInfo)
+	if detail != nil {
+		detail.ImageSize = fmt.Sprintf("%dx%d", img.Cols(), img.Rows())
+	}
 
 	var corners []image.Point
 	var color string
 	var gridX, gridY int
 	var markerRect image.Rectangle
+	var candidates []Candidate
 	var err error
 
-	debugInfo["step"] = "board_localization"
-	debugInfo["board_localization_method"] = "fixed"
-
-	resKey := fmt.Sprintf("%dx%d", img.Cols(), img.Rows())
-	if c, ok := FixedBoardCorners[resKey]; ok {
+	if resKey, c, ok := d.boardCorners(img); ok {
 		corners = c
-		debugInfo["fixed_resolution"] = resKey
+		if detail != nil {
+			detail.FixedResolution = resKey
+		}
 	} else {
 		return Result{
 			Move:       moveNumber,
@@ -235,10 +462,12 @@ func DetectLastMoveCoord(img gocv.Mat, moveNumber int) (Result, error) {
 		}, fmt.Errorf("不支持的图片分辨率: %dx%d", img.Cols(), img.Rows())
 	}
 
-	warped, err := WarpBoard(img, corners)
+	warped, err := d.boardView(img, corners, &debugInfo)
 	if err != nil {
-		debugInfo["warp_error"] = err.Error()
-		debugInfo["final_status"] = "failed_at_warp"
+		if detail != nil {
+			detail.WarpError = err.Error()
+		}
+		debugInfo.FinalStatus = "failed_at_warp"
 		return Result{
 			Move:       moveNumber,
 			Color:      "B",
@@ -249,39 +478,68 @@ func DetectLastMoveCoord(img gocv.Mat, moveNumber int) (Result, error) {
 		}, nil
 	}
 	defer warped.Close()
+	warpSize := image.Pt(BoardWarpSize, BoardWarpSize)
 
 	// fmt.Printf("[检测] 开始检测最后一手，moveNumber=%d\n", moveNumber)
 
+	skin := d.selectSkin(warped)
+	debugInfo.Skin = skin.Name
+	debugInfo.Lighting = LightingBucket(warped, skin)
+
+	var screen *board.Board
+	if d.Fusion || moveNumber == 0 && d.Game != nil {
+		b := ReadBoard(warped, d.Classifier)
+		screen = &b
+	}
+
+	// 没有 OCR 手数时从盘面推断，推断出的手数同样决定颜色与找哪种角标
+	if moveNumber == 0 && d.Game != nil {
+		if n := d.Game.InferMoveNumber(screen); n > 0 {
+			moveNumber = n
+			debugInfo.InferredMoveNumber = n
+		}
+	}
+
+	if d.Fusion {
+		return d.detectFused(warped, skin, screen, moveNumber, &debugInfo), nil
+	}
+
 	isBlack := moveNumber%2 == 1
 	if isBlack {
-		markerRect, gridX, gridY, err = boardblack(warped)
+		markerRect, gridX, gridY, candidates, err = d.boardblack(warped, skin)
 		if err != nil {
-			debugInfo["detection_error"] = err.Error()
-			debugInfo["final_status"] = "failed_at_detection"
+			if detail != nil {
+				detail.DetectionError = err.Error()
+			}
+			debugInfo.FinalStatus = "failed_at_detection"
 			return Result{
 				Move:       moveNumber,
 				Color:      "B",
 				X:          0,
 				Y:          0,
 				Confidence: 0,
-				MarkerRect: markerRect,
+				MarkerRect: toWarpSpace(markerRect, warped.Cols(), warped.Rows()),
+				WarpSize:   warpSize,
 				Debug:      debugInfo,
 			}, nil
 		}
 		color = "B"
 		// fmt.Printf("[检测] 黑棋，检测到标记位置: %v\n", markerRect)
 	} else {
-		markerRect, gridX, gridY, err = boardwhite(warped)
+		markerRect, gridX, gridY, candidates, err = d.boardwhite(warped, skin)
 		if err != nil {
-			debugInfo["detection_error"] = err.Error()
-			debugInfo["final_status"] = "failed_at_detection"
+			if detail != nil {
+				detail.DetectionError = err.Error()
+			}
+			debugInfo.FinalStatus = "failed_at_detection"
 			return Result{
 				Move:       moveNumber,
 				Color:      "W",
 				X:          0,
 				Y:          0,
 				Confidence: 0,
-				MarkerRect: markerRect,
+				MarkerRect: toWarpSpace(markerRect, warped.Cols(), warped.Rows()),
+				WarpSize:   warpSize,
 				Debug:      debugInfo,
 			}, nil
 		}
@@ -289,18 +547,41 @@ func DetectLastMoveCoord(img gocv.Mat, moveNumber int) (Result, error) {
 		// fmt.Printf("[检测] 白棋，检测到标记位置: %v\n", markerRect)
 	}
 
-	debugInfo["final_status"] = "success"
+	// OCR 未识别到手数时无法按奇偶判断颜色，改用交叉点分类结果
+	if moveNumber == 0 {
+		if stone, confidence := ClassifyAt(warped, gridX, gridY, d.Classifier); stone != board.Empty {
+			color = stone.String()
+			if detail != nil {
+				detail.StoneColor, detail.StoneConfidence = color, confidence
+			}
+		} else if d.BoardModel != nil {
+			color = d.nextColor()
+			if detail != nil {
+				detail.ModelColor = color
+			}
+		}
+	}
+
+	_, _, center := calculateGrid(markerRect, warped.Cols(), warped.Rows())
+	stoneCenter := toWarpSpace(image.Rectangle{Min: center, Max: center}, warped.Cols(), warped.Rows()).Min
+	markerRect = toWarpSpace(markerRect, warped.Cols(), warped.Rows())
+
+	debugInfo.FinalStatus = "success"
 	result := Result{
-		Move:       moveNumber,
-		Color:      color,
-		X:          gridX + 1,
-		Y:          gridY + 1,
-		Confidence: 0.8,
-		MarkerRect: markerRect,
-		Debug:      debugInfo,
+		Move:        moveNumber,
+		Color:       color,
+		X:           gridX + 1,
+		Y:           gridY + 1,
+		Confidence:  0.8,
+		MarkerRect:  markerRect,
+		StoneCenter: stoneCenter,
+		GridIndex:   image.Pt(gridX, gridY),
+		WarpSize:    warpSize,
+		Candidates:  candidates,
+		Debug:       debugInfo,
 	}
 
-	// fmt.Printf("[检测] 完成，坐标: %d-%s%d\n", result.Move, string(rune('A'+result.X-1)), result.Y)
+	// fmt.Printf("[检测] 完成，坐标: %d-%s\n", result.Move, coords.FormatPhone(result.X, result.Y))
 
 	return result, nil
 }
@@ -318,72 +599,83 @@ func calculateGrid(markerRect image.Rectangle, width, height int) (int, int, ima
 	return clamp(gridX, 0, 18), clamp(gridY, 0, 18), image.Pt(int(centerX), int(centerY))
 }
 
-func boardblack(img gocv.Mat) (image.Rectangle, int, int, error) {
-	markerRect, found := findLastMoveMarker(img)
-	if !found {
-		return image.Rectangle{}, 0, 0, fmt.Errorf("未找到红色最后一手标记")
+func (d *Detector) boardblack(img gocv.Mat, skin Skin) (image.Rectangle, int, int, []Candidate, error) {
+	contours := d.findMarkerContours(img, skin)
+	if len(contours) == 0 {
+		return image.Rectangle{}, 0, 0, nil, fmt.Errorf("未找到红色最后一手标记")
 	}
 
+	markerRect := contours[0].rect
 	gridX, gridY, _ := calculateGrid(markerRect, img.Cols(), img.Rows())
 
-	return markerRect, gridX, gridY, nil
+	return markerRect, gridX, gridY, rankCandidates(contours, img.Cols(), img.Rows()), nil
 }
 
-func boardwhite(img gocv.Mat) (image.Rectangle, int, int, error) {
-	markerRect, found := findLastMoveMarker(img)
-	if !found {
-		return image.Rectangle{}, 0, 0, fmt.Errorf("未检测到蓝色角标")
+func (d *Detector) boardwhite(img gocv.Mat, skin Skin) (image.Rectangle, int, int, []Candidate, error) {
+	contours := d.findMarkerContours(img, skin)
+	if len(contours) == 0 {
+		return image.Rectangle{}, 0, 0, nil, fmt.Errorf("未检测到蓝色角标")
 	}
 
+	markerRect := contours[0].rect
 	gridX, gridY, _ := calculateGrid(markerRect, img.Cols(), img.Rows())
 
-	return markerRect, gridX, gridY, nil
+	return markerRect, gridX, gridY, rankCandidates(contours, img.Cols(), img.Rows()), nil
+}
+
+// findLastMoveMarker 返回面积最大的角标轮廓
+func (d *Detector) findLastMoveMarker(img gocv.Mat, skin Skin) (image.Rectangle, bool) {
+	contours := d.findMarkerContours(img, skin)
+	if len(contours) == 0 {
+		return image.Rectangle{}, false
+	}
+	return contours[0].rect, true
 }
 
+// markerContour 一个角标颜色的轮廓
+type markerContour struct {
+	rect image.Rectangle
+	area float64
+}
 
-func findLastMoveMarker(img gocv.Mat) (image.Rectangle, bool) {
+// findMarkerContours 返回面积不小于 MinMarkerArea 的角标轮廓，按面积从大到小排列（面积相同时保持轮廓顺序）
+func (d *Detector) findMarkerContours(img gocv.Mat, skin Skin) []markerContour {
 	hsv := gocv.NewMat()
 	defer hsv.Close()
 	gocv.CvtColor(img, &hsv, gocv.ColorBGRToHSV)
 
-	mask := gocv.NewMat()
-	defer mask.Close()
-
-	mRed1 := gocv.NewMat()
-	mRed2 := gocv.NewMat()
-	gocv.InRangeWithScalar(hsv, gocv.NewScalar(0, 160, 100, 0), gocv.NewScalar(10, 255, 255, 0), &mRed1)
-	gocv.InRangeWithScalar(hsv, gocv.NewScalar(170, 160, 100, 0), gocv.NewScalar(180, 255, 255, 0), &mRed2)
-
-	mBlue := gocv.NewMat()
-	gocv.InRangeWithScalar(hsv, gocv.NewScalar(100, 160, 100, 0), gocv.NewScalar(140, 255, 255, 0), &mBlue)
-
-	gocv.BitwiseOr(mRed1, mRed2, &mask)
-	gocv.BitwiseOr(mask, mBlue, &mask)
+	ranges := d.Tuning().Apply(skin.MarkerRanges)
+	if d.NormalizeLighting {
+		ranges = AdaptRanges(ranges, EstimateBoardValue(hsv), skin.BoardValue)
+	}
 
-	mRed1.Close()
-	mRed2.Close()
-	mBlue.Close()
+	mask := markerMask(hsv, ranges)
+	defer mask.Close()
+	d.excludeMarkerRegions(&mask)
 
 	contours := gocv.FindContours(mask, gocv.RetrievalExternal, gocv.ChainApproxSimple)
 	defer contours.Close()
 
-	if contours.Size() == 0 {
-		return image.Rectangle{}, false
-	}
-
-	var bestRect image.Rectangle
-	maxArea := 0.0
+	var found []markerContour
 	for i := 0; i < contours.Size(); i++ {
 		area := gocv.ContourArea(contours.At(i))
-		if area > maxArea {
-			maxArea = area
-			bestRect = gocv.BoundingRect(contours.At(i))
+		if area > 0 && area >= d.MinMarkerArea {
+			found = append(found, markerContour{rect: gocv.BoundingRect(contours.At(i)), area: area})
 		}
 	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].area > found[j].area })
+
+	// fmt.Printf("[HSV检测] 找到 %d 个轮廓\n", len(found))
 
-	// fmt.Printf("[HSV检测] 找到 %d 个轮廓，最大面积: %.2f\n", contours.Size(), maxArea)
+	return found
+}
 
-	return bestRect, maxArea > 0
+// nextColor 按 BoardModel 中双方棋子数推断刚落下的一手的颜色（不考虑提子）
+func (d *Detector) nextColor() string {
+	if d.BoardModel.Count(board.Black) > d.BoardModel.Count(board.White) {
+		return board.White.String()
+	}
+	return board.Black.String()
 }
 
 func findMarker(img gocv.Mat) (float64, float64, bool) {
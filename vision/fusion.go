@@ -0,0 +1,184 @@
+package vision
+
+import (
+	"image"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+
+	"gocv.io/x/gocv"
+)
+
+// Signal 一路识别信号对最终结果的表态。只列出本帧参与表决的信号，
+// 如没有 OCR 手数时不列 parity，局面没有变化时不列 diff
+type Signal struct {
+	Name   string  `json:"name"`
+	Agree  bool    `json:"agree"`
+	Weight float64 `json:"weight"`
+}
+
+// SignalWeights 融合时各路信号的权重：marker 角标位置，parity 手数奇偶对应的颜色，
+// diff 与上一帧相比新出现的棋子，classifier 交叉点分类出的颜色。
+// 角标滞后（动画未结束）时，新出现的棋子加上手数奇偶可以胜过旧角标
+var SignalWeights = map[string]float64{
+	"marker":     0.35,
+	"parity":     0.25,
+	"diff":       0.25,
+	"classifier": 0.15,
+}
+
+// hypothesis 对最后一手的一种假设，位置为交叉点下标（0 起，从左上角数）
+type hypothesis struct {
+	at    image.Point
+	color board.Color
+}
+
+// evidence 单帧中可用的全部信号
+type evidence struct {
+	// marker 角标所在的交叉点，未找到时为 nil
+	marker     *image.Point
+	moveNumber int
+	// screen 本帧分类出的局面，prev 上一帧的局面（第一帧时为 nil），均为 ReadBoard 的坐标
+	screen *board.Board
+	prev   *board.Board
+}
+
+// stoneAt 按交叉点下标读取 ReadBoard 局面
+func stoneAt(b *board.Board, at image.Point) board.Color {
+	x, y := coords.FromPhone(at.X+1, at.Y+1)
+	return b.At(x, y)
+}
+
+// fuse 对角标位置与新出现的棋子两种来源的候选逐一打分，返回与各路信号最一致的假设、
+// 置信度（一致信号的权重占参与表决信号的比例）与各路信号的表态；没有任何候选时 ok 为 false。
+// 得分相同时优先角标位置，其次手数奇偶对应的颜色
+func fuse(ev evidence) (hypothesis, float64, []Signal, bool) {
+	parity := board.Empty
+	if ev.moveNumber > 0 {
+		parity = board.White
+		if ev.moveNumber%2 == 1 {
+			parity = board.Black
+		}
+	}
+
+	added := map[image.Point]board.Color{}
+	if ev.prev != nil {
+		for _, c := range board.Added(board.Diff(ev.prev, ev.screen)) {
+			phoneX, phoneY := coords.ToPhone(c.X, c.Y)
+			added[image.Pt(phoneX-1, phoneY-1)] = c.To
+		}
+	}
+
+	var positions []image.Point
+	if ev.marker != nil {
+		positions = append(positions, *ev.marker)
+	}
+	for y := 0; y < coords.Size; y++ {
+		for x := 0; x < coords.Size; x++ {
+			if p := image.Pt(x, y); added[p] != board.Empty && (ev.marker == nil || p != *ev.marker) {
+				positions = append(positions, p)
+			}
+		}
+	}
+	colors := []board.Color{board.Black, board.White}
+	if parity == board.White {
+		colors = []board.Color{board.White, board.Black}
+	}
+
+	var best hypothesis
+	var bestScore, bestTotal float64
+	var bestSignals []Signal
+	found := false
+	for _, at := range positions {
+		for _, color := range colors {
+			h := hypothesis{at: at, color: color}
+			signals := h.signals(ev, parity, added)
+			score, total := 0.0, 0.0
+			for _, s := range signals {
+				total += s.Weight
+				if s.Agree {
+					score += s.Weight
+				}
+			}
+			if !found || score > bestScore {
+				best, bestScore, bestTotal, bestSignals, found = h, score, total, signals, true
+			}
+		}
+	}
+	if !found || bestTotal == 0 {
+		return hypothesis{}, 0, nil, false
+	}
+	return best, bestScore / bestTotal, bestSignals, true
+}
+
+// signals 列出参与表决的信号对假设 h 的表态
+func (h hypothesis) signals(ev evidence, parity board.Color, added map[image.Point]board.Color) []Signal {
+	var signals []Signal
+	vote := func(name string, agree bool) {
+		signals = append(signals, Signal{Name: name, Agree: agree, Weight: SignalWeights[name]})
+	}
+
+	if ev.marker != nil {
+		vote("marker", h.at == *ev.marker)
+	}
+	if parity != board.Empty {
+		vote("parity", h.color == parity)
+	}
+	if len(added) > 0 {
+		vote("diff", added[h.at] == h.color)
+	}
+	if stone := stoneAt(ev.screen, h.at); stone != board.Empty {
+		vote("classifier", stone == h.color)
+	}
+	return signals
+}
+
+// detectFused 融合各路信号识别最后一手，screen 为本帧分类出的局面
+func (d *Detector) detectFused(warped gocv.Mat, skin Skin, screen *board.Board, moveNumber int, debugInfo *DebugInfo) Result {
+	warpSize := image.Pt(BoardWarpSize, BoardWarpSize)
+	ev := evidence{moveNumber: moveNumber, screen: screen, prev: d.prevScreen.Swap(screen)}
+
+	markerRect, found := d.findLastMoveMarker(warped, skin)
+	if found {
+		gridX, gridY, _ := calculateGrid(markerRect, warped.Cols(), warped.Rows())
+		ev.marker = &image.Point{X: gridX, Y: gridY}
+	}
+
+	h, confidence, signals, ok := fuse(ev)
+	debugInfo.Fusion = true
+	if !ok {
+		if detail := d.detail(debugInfo); detail != nil {
+			detail.DetectionError = "未找到角标，局面也没有新增棋子"
+		}
+		debugInfo.FinalStatus = "failed_at_detection"
+		color := "W"
+		if moveNumber%2 == 1 {
+			color = "B"
+		}
+		return Result{Move: moveNumber, Color: color, WarpSize: warpSize, Debug: *debugInfo}
+	}
+
+	cell := CellRect(warped, h.at.X, h.at.Y)
+	center := image.Pt((cell.Min.X+cell.Max.X)/2, (cell.Min.Y+cell.Max.Y)/2)
+	if ev.marker != nil && h.at == *ev.marker {
+		_, _, center = calculateGrid(markerRect, warped.Cols(), warped.Rows())
+		markerRect = toWarpSpace(markerRect, warped.Cols(), warped.Rows())
+	} else {
+		markerRect = image.Rectangle{}
+	}
+
+	debugInfo.FinalStatus = "success"
+	return Result{
+		Move:        moveNumber,
+		Color:       h.color.String(),
+		X:           h.at.X + 1,
+		Y:           h.at.Y + 1,
+		Confidence:  confidence,
+		MarkerRect:  markerRect,
+		StoneCenter: toWarpSpace(image.Rectangle{Min: center, Max: center}, warped.Cols(), warped.Rows()).Min,
+		GridIndex:   h.at,
+		WarpSize:    warpSize,
+		Signals:     signals,
+		Debug:       *debugInfo,
+	}
+}
@@ -0,0 +1,115 @@
+package vision
+
+import (
+	"image"
+	"testing"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+)
+
+// screenBoard 按交叉点下标（0 起，从左上角数）摆出 ReadBoard 坐标的局面
+func screenBoard(stones map[image.Point]board.Color) *board.Board {
+	var b board.Board
+	for p, c := range stones {
+		x, y := coords.FromPhone(p.X+1, p.Y+1)
+		b.Set(x, y, c)
+	}
+	return &b
+}
+
+func TestFuse(t *testing.T) {
+	a, b := image.Pt(3, 3), image.Pt(15, 15)
+
+	tests := []struct {
+		name       string
+		ev         evidence
+		want       hypothesis
+		confidence float64
+		disagree   []string
+		ok         bool
+	}{
+		{
+			name: "角标、奇偶与分类一致",
+			ev: evidence{
+				marker:     &a,
+				moveNumber: 1,
+				screen:     screenBoard(map[image.Point]board.Color{a: board.Black}),
+			},
+			want:       hypothesis{at: a, color: board.Black},
+			confidence: 1,
+			ok:         true,
+		},
+		{
+			name: "角标滞后，新出现的棋子与奇偶一致",
+			ev: evidence{
+				marker:     &a,
+				moveNumber: 2,
+				screen:     screenBoard(map[image.Point]board.Color{a: board.Black, b: board.White}),
+				prev:       screenBoard(map[image.Point]board.Color{a: board.Black}),
+			},
+			want:       hypothesis{at: b, color: board.White},
+			confidence: 0.65,
+			disagree:   []string{"marker"},
+			ok:         true,
+		},
+		{
+			name: "没有手数时按分类判断颜色",
+			ev: evidence{
+				marker: &a,
+				screen: screenBoard(map[image.Point]board.Color{a: board.White}),
+			},
+			want:       hypothesis{at: a, color: board.White},
+			confidence: 1,
+			ok:         true,
+		},
+		{
+			name: "分类与奇偶矛盾时以奇偶为准",
+			ev: evidence{
+				marker:     &a,
+				moveNumber: 3,
+				screen:     screenBoard(map[image.Point]board.Color{a: board.White}),
+			},
+			want:       hypothesis{at: a, color: board.Black},
+			confidence: 0.6 / 0.75,
+			disagree:   []string{"classifier"},
+			ok:         true,
+		},
+		{
+			name: "没有角标也没有新棋子",
+			ev: evidence{
+				moveNumber: 5,
+				screen:     screenBoard(nil),
+				prev:       screenBoard(nil),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, confidence, signals, ok := fuse(tt.ev)
+			if ok != tt.ok {
+				t.Fatalf("fuse() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if h != tt.want {
+				t.Errorf("fuse() = %+v, want %+v", h, tt.want)
+			}
+			if diff := confidence - tt.confidence; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("confidence = %v, want %v", confidence, tt.confidence)
+			}
+
+			var disagree []string
+			for _, s := range signals {
+				if !s.Agree {
+					disagree = append(disagree, s.Name)
+				}
+			}
+			if len(disagree) != len(tt.disagree) || len(disagree) > 0 && disagree[0] != tt.disagree[0] {
+				t.Errorf("不一致的信号 = %v, want %v", disagree, tt.disagree)
+			}
+		})
+	}
+}
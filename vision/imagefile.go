@@ -0,0 +1,206 @@
+package vision
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gocv.io/x/gocv"
+)
+
+// ImageExtensions ReadImage 支持的图片格式（小写扩展名）。HEIC/HEIF 需要外部转换工具（见 heicConverters），
+// 其余由 OpenCV 解码
+var ImageExtensions = []string{".jpg", ".jpeg", ".png", ".webp", ".bmp", ".heic", ".heif"}
+
+// IsImageFile 按扩展名判断是否为 ReadImage 支持的图片
+func IsImageFile(name string) bool {
+	return slices.Contains(ImageExtensions, strings.ToLower(filepath.Ext(name)))
+}
+
+// heicConverters 把 HEIC 转为 JPEG 的外部工具，按顺序使用第一个找得到的
+var heicConverters = []struct {
+	name string
+	args func(in, out string) []string
+}{
+	{"heif-convert", func(in, out string) []string { return []string{"-q", "95", in, out} }},
+	{"magick", func(in, out string) []string { return []string{in, "-auto-orient", out} }},
+	{"sips", func(in, out string) []string { return []string{"-s", "format", "jpeg", in, "--out", out} }},
+}
+
+// ReadImage 读取一张截图或相册里的照片（JPG、PNG、WebP、HEIC 等），按 EXIF 中的方向转正。
+// 返回的 Mat 由调用方 Close
+func ReadImage(path string) (gocv.Mat, error) {
+	var data []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".heic", ".heif":
+		data, err = convertHEIC(path)
+	default:
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return gocv.Mat{}, err
+	}
+
+	// 方向由 applyOrientation 统一处理，不依赖 OpenCV 是否支持该格式的 EXIF
+	img, err := gocv.IMDecode(data, gocv.IMReadColor|gocv.IMReadIgnoreOrientation)
+	if err != nil || img.Empty() {
+		if err == nil {
+			img.Close()
+		}
+		return gocv.Mat{}, fmt.Errorf("无法解码图片: %s", path)
+	}
+	return applyOrientation(img, exifOrientation(data)), nil
+}
+
+// convertHEIC 用外部工具把 HEIC 转为 JPEG，返回 JPEG 数据
+func convertHEIC(path string) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "goboardsync-heic")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "image.jpg")
+
+	for _, c := range heicConverters {
+		exe, err := exec.LookPath(c.name)
+		if err != nil {
+			continue
+		}
+		if output, err := exec.Command(exe, c.args(path, out)...).CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("%s 转换 %s 失败: %v %s", c.name, path, err, bytes.TrimSpace(output))
+		}
+		return os.ReadFile(out)
+	}
+	return nil, fmt.Errorf("读取 HEIC 需要安装 heif-convert（libheif）或 ImageMagick，macOS 自带的 sips 也可以")
+}
+
+// applyOrientation 按 EXIF 方向（1-8）把图片转正，返回新的 Mat 并释放 img；方向为 1 或未知时原样返回
+func applyOrientation(img gocv.Mat, orientation int) gocv.Mat {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	defer img.Close()
+
+	out := gocv.NewMat()
+	switch orientation {
+	case 2:
+		gocv.Flip(img, &out, 1)
+	case 3:
+		gocv.Rotate(img, &out, gocv.Rotate180Clockwise)
+	case 4:
+		gocv.Flip(img, &out, 0)
+	case 5, 7:
+		// 5 沿主对角线翻转，7 沿副对角线翻转：都先顺时针转 90°，再分别左右、上下翻转
+		rotated := gocv.NewMat()
+		defer rotated.Close()
+		gocv.Rotate(img, &rotated, gocv.Rotate90Clockwise)
+		flip := 1
+		if orientation == 7 {
+			flip = 0
+		}
+		gocv.Flip(rotated, &out, flip)
+	case 6:
+		gocv.Rotate(img, &out, gocv.Rotate90Clockwise)
+	case 8:
+		gocv.Rotate(img, &out, gocv.Rotate90CounterClockwise)
+	}
+	return out
+}
+
+// exifOrientation 读取 JPEG（APP1）、PNG（eXIf）或 WebP（EXIF 块）中的 EXIF 方向，没有时返回 0
+func exifOrientation(data []byte) int {
+	var tiff []byte
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		tiff = jpegExif(data)
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		tiff = pngExif(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		tiff = webpExif(data)
+	}
+	return tiffOrientation(bytes.TrimPrefix(tiff, []byte("Exif\x00\x00")))
+}
+
+func jpegExif(data []byte) []byte {
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || size < 2 || i+2+size > len(data) {
+			// 图像数据开始，EXIF 只会出现在它之前
+			return nil
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment
+		}
+		i += 2 + size
+	}
+	return nil
+}
+
+func pngExif(data []byte) []byte {
+	for i := 8; i+8 <= len(data); {
+		size := int(binary.BigEndian.Uint32(data[i:]))
+		if i+12+size > len(data) {
+			return nil
+		}
+		if string(data[i+4:i+8]) == "eXIf" {
+			return data[i+8 : i+8+size]
+		}
+		i += 12 + size
+	}
+	return nil
+}
+
+func webpExif(data []byte) []byte {
+	for i := 12; i+8 <= len(data); {
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		if i+8+size > len(data) {
+			return nil
+		}
+		if string(data[i:i+4]) == "EXIF" {
+			return data[i+8 : i+8+size]
+		}
+		// 块的长度为奇数时补一个字节
+		i += 8 + size + size%2
+	}
+	return nil
+}
+
+// tiffOrientation 在 EXIF 的 TIFF 结构中找第一个 IFD 的 Orientation（0x0112）标签
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package vision
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+// exifTIFF 生成只含 Orientation 标签的 EXIF（TIFF 结构）
+func exifTIFF(order binary.ByteOrder, orientation int) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], 0x0112)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], uint16(orientation))
+	return tiff
+}
+
+func TestExifOrientation(t *testing.T) {
+	exif := append([]byte("Exif\x00\x00"), exifTIFF(binary.BigEndian, 6)...)
+
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x04, 0x00, 0x00, 0xFF, 0xE1}
+	jpeg = binary.BigEndian.AppendUint16(jpeg, uint16(len(exif)+2))
+	jpeg = append(jpeg, exif...)
+	jpeg = append(jpeg, 0xFF, 0xDA, 0x00, 0x02)
+
+	png := []byte("\x89PNG\r\n\x1a\n")
+	png = binary.BigEndian.AppendUint32(png, 13)
+	png = append(png, "IHDR"...)
+	png = append(png, make([]byte, 13+4)...)
+	tiff := exifTIFF(binary.LittleEndian, 3)
+	png = binary.BigEndian.AppendUint32(png, uint32(len(tiff)))
+	png = append(png, "eXIf"...)
+	png = append(png, tiff...)
+	png = append(png, 0, 0, 0, 0)
+
+	webp := []byte("RIFF\x00\x00\x00\x00WEBPVP8X")
+	webp = binary.LittleEndian.AppendUint32(webp, 9)
+	webp = append(webp, make([]byte, 10)...)
+	tiff = exifTIFF(binary.LittleEndian, 8)
+	webp = append(webp, "EXIF"...)
+	webp = binary.LittleEndian.AppendUint32(webp, uint32(len(tiff)))
+	webp = append(webp, tiff...)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"JPEG", jpeg, 6},
+		{"PNG", png, 3},
+		{"WebP（奇数长度的块后补齐）", webp, 8},
+		{"没有 EXIF", []byte{0xFF, 0xD8, 0xFF, 0xDA, 0x00, 0x02}, 0},
+		{"截断的数据", jpeg[:20], 0},
+		{"其他格式", []byte("GIF89a"), 0},
+	}
+	for _, tt := range tests {
+		if got := exifOrientation(tt.data); got != tt.want {
+			t.Errorf("%s: exifOrientation() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsImageFile(t *testing.T) {
+	for name, want := range map[string]bool{
+		"37-Q4-black.jpg": true,
+		"IMG_0001.HEIC":   true,
+		"shot.webp":       true,
+		"notes.txt":       false,
+		"README":          false,
+	} {
+		if got := IsImageFile(name); got != want {
+			t.Errorf("IsImageFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	for orientation, want := range map[int][2]int{1: {40, 30}, 3: {40, 30}, 6: {30, 40}, 7: {30, 40}} {
+		img := applyOrientation(gocv.NewMatWithSizeFromScalar(gocv.NewScalar(0, 0, 0, 0), 30, 40, gocv.MatTypeCV8UC3), orientation)
+		if img.Cols() != want[0] || img.Rows() != want[1] {
+			t.Errorf("方向 %d: %dx%d, want %dx%d", orientation, img.Cols(), img.Rows(), want[0], want[1])
+		}
+		img.Close()
+	}
+}
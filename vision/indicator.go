@@ -0,0 +1,53 @@
+package vision
+
+import (
+	"fmt"
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+// IndicatorMinDiff 点击落子点后，该点周围的平均灰度变化（0-255）达到此值时认为 App 已显示落子指示标
+const IndicatorMinDiff = 10.0
+
+// IndicatorRegion 落子点 pt 周围用于判断指示标是否出现的区域，边长为一个棋盘间距
+func IndicatorRegion(pt image.Point, gap float64) image.Rectangle {
+	r := int(gap / 2)
+	if r < 4 {
+		r = 4
+	}
+	return image.Rect(pt.X-r, pt.Y-r, pt.X+r, pt.Y+r)
+}
+
+// RegionDiff 返回两张截图在 rect 内的平均灰度差（0-255）。截图为空、尺寸不同或 rect 超出截图时返回错误
+func RegionDiff(before, after gocv.Mat, rect image.Rectangle) (float64, error) {
+	if before.Empty() || after.Empty() {
+		return 0, fmt.Errorf("截图为空")
+	}
+	if before.Cols() != after.Cols() || before.Rows() != after.Rows() {
+		return 0, fmt.Errorf("截图尺寸不同: %dx%d, %dx%d", before.Cols(), before.Rows(), after.Cols(), after.Rows())
+	}
+	if !rect.In(image.Rect(0, 0, before.Cols(), before.Rows())) {
+		return 0, fmt.Errorf("区域 %v 超出截图 %dx%d", rect, before.Cols(), before.Rows())
+	}
+
+	a, b := toGray(before.Region(rect)), toGray(after.Region(rect))
+	defer a.Close()
+	defer b.Close()
+
+	diff := gocv.NewMat()
+	defer diff.Close()
+	gocv.AbsDiff(a, b, &diff)
+	return diff.Mean().Val1, nil
+}
+
+// toGray 把截图区域转为灰度图并释放原区域
+func toGray(m gocv.Mat) gocv.Mat {
+	if m.Channels() == 1 {
+		return m
+	}
+	defer m.Close()
+	gray := gocv.NewMat()
+	gocv.CvtColor(m, &gray, gocv.ColorBGRToGray)
+	return gray
+}
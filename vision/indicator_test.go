@@ -0,0 +1,32 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+func TestRegionDiff(t *testing.T) {
+	before := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(90, 170, 220, 0), 400, 300, gocv.MatTypeCV8UC3)
+	defer before.Close()
+	after := before.Clone()
+	defer after.Close()
+
+	// 落子点 (150, 200) 出现半透明棋子指示标
+	pt := image.Pt(150, 200)
+	gocv.Circle(&after, pt, 25, color.RGBA{30, 30, 30, 0}, -1)
+
+	region := IndicatorRegion(pt, 60)
+	diff, err := RegionDiff(before, after, region)
+	if err != nil || diff < IndicatorMinDiff {
+		t.Errorf("指示标处 RegionDiff() = %.1f, %v, want >= %.0f", diff, err, IndicatorMinDiff)
+	}
+	if diff, err := RegionDiff(before, after, IndicatorRegion(image.Pt(60, 60), 60)); err != nil || diff >= IndicatorMinDiff {
+		t.Errorf("无变化处 RegionDiff() = %.1f, %v", diff, err)
+	}
+	if _, err := RegionDiff(before, after, IndicatorRegion(image.Pt(290, 200), 60)); err == nil {
+		t.Error("区域超出截图时应返回错误")
+	}
+}
@@ -0,0 +1,133 @@
+package vision
+
+import (
+	"fmt"
+	"image"
+
+	"goboardsync/ocr"
+
+	"gocv.io/x/gocv"
+)
+
+// LabelRegions 棋盘边上坐标标签的显示区域：Top 为棋盘上方一条，Left 为左侧一条，
+// 两端与棋盘角点对齐（App 设置中打开“显示坐标”后才有）
+type LabelRegions struct {
+	Top  image.Rectangle
+	Left image.Rectangle
+}
+
+// FixedLabelRegions 按分辨率配置的坐标标签区域
+var FixedLabelRegions = map[string]LabelRegions{
+	"1200x2670": {
+		Top:  image.Rect(40, 496, 1160, 536),
+		Left: image.Rect(0, 536, 40, 1650),
+	},
+	"2670x1200": {
+		Top:  image.Rect(40, 0, 1160, 40),
+		Left: image.Rect(0, 40, 40, 1154),
+	},
+}
+
+// LabelCheck 坐标标签的校验结果
+type LabelCheck struct {
+	// Top、Left 两条标签读出的序号（0 起，A、1 为 0），TopLetters、LeftLetters 表示读到的是字母列标签
+	Top         []int
+	TopLetters  bool
+	Left        []int
+	LeftLetters bool
+	// Rotation 按标签的种类与顺序推断出的棋盘旋转角度（与 BoardRotation 含义相同），无法判断或两条标签矛盾时为 -1
+	Rotation int
+	// Complete 两条标签都读全了 19 个。棋盘角点偏了一格以上时，首尾的标签会被截到区域外
+	Complete bool
+}
+
+// CheckBoardLabels 通过 OCR 读取棋盘上方与左侧的坐标标签，校验棋盘角点与方向。
+// 分辨率没有配置标签区域或 OCR 失败时返回错误
+func (d *Detector) CheckBoardLabels(img gocv.Mat) (LabelCheck, error) {
+	regions, ok := FixedLabelRegions[fmt.Sprintf("%dx%d", img.Cols(), img.Rows())]
+	if !ok {
+		return LabelCheck{}, fmt.Errorf("未配置坐标标签区域: %dx%d", img.Cols(), img.Rows())
+	}
+
+	var check LabelCheck
+	for i, region := range []image.Rectangle{regions.Top, regions.Left} {
+		region = region.Intersect(image.Rect(0, 0, img.Cols(), img.Rows()))
+		if region.Empty() {
+			return LabelCheck{}, fmt.Errorf("坐标标签区域超出图片范围")
+		}
+
+		roi := img.Region(region)
+		text, err := d.recognizeText(roi)
+		roi.Close()
+		if err != nil {
+			return LabelCheck{}, err
+		}
+		if i == 0 {
+			check.Top, check.TopLetters = ocr.ParseBoardLabels(text)
+		} else {
+			check.Left, check.LeftLetters = ocr.ParseBoardLabels(text)
+		}
+	}
+
+	check.Rotation = labelRotation(check.Top, check.TopLetters, check.Left, check.LeftLetters)
+	check.Complete = len(check.Top) == 19 && len(check.Left) == 19
+	return check, nil
+}
+
+// labelOrder 标签序列的方向：1 为递增（A→T、1→19），-1 为递减，相邻两个的增减没有三分之二以上一致时为 0
+func labelOrder(index []int) int {
+	up, down := 0, 0
+	for i := 1; i < len(index); i++ {
+		switch {
+		case index[i] > index[i-1]:
+			up++
+		case index[i] < index[i-1]:
+			down++
+		}
+	}
+	switch total := up + down; {
+	case total == 0:
+		return 0
+	case up*3 >= total*2:
+		return 1
+	case down*3 >= total*2:
+		return -1
+	}
+	return 0
+}
+
+// labelRotation 按两条标签推断棋盘顺时针旋转的角度。黑方视角下上方为 A→T，左侧从上往下为 19→1；
+// 转 90° 后上方变为 1→19、左侧变为 A→T，180° 时两条都反过来。只有一条能判断时以它为准，矛盾时返回 -1
+func labelRotation(top []int, topLetters bool, left []int, leftLetters bool) int {
+	fromTop := -1
+	switch order := labelOrder(top); {
+	case order == 1 && topLetters:
+		fromTop = 0
+	case order == -1 && topLetters:
+		fromTop = 180
+	case order == 1:
+		fromTop = 90
+	case order == -1:
+		fromTop = 270
+	}
+
+	fromLeft := -1
+	switch order := labelOrder(left); {
+	case order == -1 && !leftLetters:
+		fromLeft = 0
+	case order == 1 && !leftLetters:
+		fromLeft = 180
+	case order == 1:
+		fromLeft = 90
+	case order == -1:
+		fromLeft = 270
+	}
+
+	switch {
+	case fromTop == -1:
+		return fromLeft
+	case fromLeft == -1 || fromLeft == fromTop:
+		return fromTop
+	}
+	return -1
+}
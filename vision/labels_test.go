@@ -0,0 +1,33 @@
+package vision
+
+import "testing"
+
+func TestLabelRotation(t *testing.T) {
+	ascending := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18}
+	descending := []int{18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0}
+
+	tests := []struct {
+		name        string
+		top         []int
+		topLetters  bool
+		left        []int
+		leftLetters bool
+		want        int
+	}{
+		{"黑方视角", ascending, true, descending, false, 0},
+		{"倒过来", descending, true, ascending, false, 180},
+		{"顺时针 90°", ascending, false, ascending, true, 90},
+		{"顺时针 270°", descending, false, descending, true, 270},
+		{"只读到上方", ascending, true, nil, false, 0},
+		{"只读到左侧的一部分", nil, false, []int{5, 4, 3, 2}, false, 0},
+		{"个别标签误读", []int{0, 1, 2, 7, 4, 5, 6, 7, 8}, true, nil, false, 0},
+		{"两条矛盾", ascending, true, ascending, false, -1},
+		{"都读不出", nil, false, []int{3}, false, -1},
+	}
+
+	for _, tt := range tests {
+		if got := labelRotation(tt.top, tt.topLetters, tt.left, tt.leftLetters); got != tt.want {
+			t.Errorf("%s: labelRotation() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
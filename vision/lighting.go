@@ -0,0 +1,75 @@
+package vision
+
+import (
+	"gocv.io/x/gocv"
+)
+
+// HSVRange HSV 颜色范围（OpenCV 取值：H 0-180，S/V 0-255）
+type HSVRange struct {
+	Lower gocv.Scalar
+	Upper gocv.Scalar
+}
+
+// NormalizeLighting 按棋盘底色的亮度自适应调整角标阈值，应对夜间模式、主题与屏幕亮度变化
+//
+// Deprecated: 使用 WithLightingNormalization 为每个 Detector 单独设置，此变量只作为 NewDetector 的默认值。
+var NormalizeLighting = true
+
+// EstimateBoardValue 返回棋盘底色 V 值的中位数（hsv 为 HSV 图像）
+func EstimateBoardValue(hsv gocv.Mat) float64 {
+	return SampleBoardColor(hsv).Val3
+}
+
+// AdaptRanges 底色比标定时（reference）偏暗时按比例降低 V 下限（最多降到 40%），
+// 底色正常或更亮时保持不变
+func AdaptRanges(ranges []HSVRange, boardValue, reference float64) []HSVRange {
+	if reference <= 0 {
+		return ranges
+	}
+
+	gain := boardValue / reference
+	if gain >= 1 {
+		return ranges
+	}
+	if gain < 0.4 {
+		gain = 0.4
+	}
+
+	adapted := make([]HSVRange, len(ranges))
+	for i, r := range ranges {
+		adapted[i] = r
+		adapted[i].Lower.Val3 = r.Lower.Val3 * gain
+	}
+	return adapted
+}
+
+// LightingBucket 按棋盘底色的亮度分组（dark、normal、bright），用于按光线统计识别效果。
+// 亮度与皮肤标定值（Skin.BoardValue）相比，低于 70% 为 dark，高于 115% 为 bright
+func LightingBucket(boardImg gocv.Mat, skin Skin) string {
+	color := SampleBoardColor(boardImg)
+	return lightingBucket(max(color.Val1, color.Val2, color.Val3), skin.BoardValue)
+}
+
+func lightingBucket(value, reference float64) string {
+	switch {
+	case reference <= 0:
+		return "normal"
+	case value < reference*0.7:
+		return "dark"
+	case value > reference*1.15:
+		return "bright"
+	}
+	return "normal"
+}
+
+// markerMask 生成角标颜色的掩码
+func markerMask(hsv gocv.Mat, ranges []HSVRange) gocv.Mat {
+	mask := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(0, 0, 0, 0), hsv.Rows(), hsv.Cols(), gocv.MatTypeCV8U)
+	for _, r := range ranges {
+		m := gocv.NewMat()
+		gocv.InRangeWithScalar(hsv, r.Lower, r.Upper, &m)
+		gocv.BitwiseOr(mask, m, &mask)
+		m.Close()
+	}
+	return mask
+}
@@ -0,0 +1,106 @@
+package vision
+
+import (
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+func TestAdaptRanges(t *testing.T) {
+	skin := Skins[0]
+
+	tests := []struct {
+		name       string
+		boardValue float64
+		expectedV  float64
+	}{
+		{"正常亮度", skin.BoardValue, 100},
+		{"更亮的主题", 240, 100},
+		{"夜间模式", 100, 50},
+		{"极暗时限制下限", 20, 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapted := AdaptRanges(skin.MarkerRanges, tt.boardValue, skin.BoardValue)
+			for i, r := range adapted {
+				if r.Lower.Val3 != tt.expectedV {
+					t.Errorf("range %d Lower.V = %.1f, want %.1f", i, r.Lower.Val3, tt.expectedV)
+				}
+				if r.Lower.Val2 != skin.MarkerRanges[i].Lower.Val2 || r.Upper != skin.MarkerRanges[i].Upper {
+					t.Errorf("range %d 除 V 下限外不应改变: %+v", i, r)
+				}
+			}
+		})
+	}
+
+	if skin.MarkerRanges[0].Lower.Val3 != 100 {
+		t.Errorf("AdaptRanges 不应修改原阈值")
+	}
+}
+
+func TestEstimateBoardValue(t *testing.T) {
+	// 暗色底板 (V=120) 上放一颗白子，个别采样点被覆盖也不影响中位数
+	hsv := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(20, 100, 120, 0), 190, 190, gocv.MatTypeCV8UC3)
+	defer hsv.Close()
+
+	stone := hsv.Region(CellRect(hsv, 9, 9))
+	stone.SetTo(gocv.NewScalar(0, 0, 250, 0))
+	stone.Close()
+
+	if got := EstimateBoardValue(hsv); got != 120 {
+		t.Errorf("EstimateBoardValue() = %.1f, want 120", got)
+	}
+}
+
+func TestTuningApply(t *testing.T) {
+	skin := Skins[0]
+
+	tests := []struct {
+		name      string
+		tuning    Tuning
+		expectedS float64
+		expectedV float64
+	}{
+		{"未设置", Tuning{}, 160, 100},
+		{"只调饱和度", Tuning{MarkerMinS: 120}, 120, 100},
+		{"同时调整", Tuning{MarkerMinS: 140, MarkerMinV: 80}, 140, 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, r := range tt.tuning.Apply(skin.MarkerRanges) {
+				if r.Lower.Val2 != tt.expectedS || r.Lower.Val3 != tt.expectedV {
+					t.Errorf("range %d Lower = %+v, want S %.0f V %.0f", i, r.Lower, tt.expectedS, tt.expectedV)
+				}
+				if r.Upper != skin.MarkerRanges[i].Upper {
+					t.Errorf("range %d 上限不应改变: %+v", i, r.Upper)
+				}
+			}
+		})
+	}
+
+	if skin.MarkerRanges[0].Lower.Val2 != 160 {
+		t.Errorf("Apply 不应修改原阈值")
+	}
+}
+
+func TestLightingBucket(t *testing.T) {
+	tests := []struct {
+		value, reference float64
+		want             string
+	}{
+		{200, 200, "normal"},
+		{150, 200, "normal"},
+		{130, 200, "dark"},
+		{240, 200, "bright"},
+		{60, 60, "normal"},
+		{30, 60, "dark"},
+		{100, 0, "normal"},
+	}
+	for _, tt := range tests {
+		if got := lightingBucket(tt.value, tt.reference); got != tt.want {
+			t.Errorf("lightingBucket(%v, %v) = %s, want %s", tt.value, tt.reference, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package vision
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"gocv.io/x/gocv"
+)
+
+// Polygon 多边形区域，顶点为 BoardWarpSize 见方的校正棋盘坐标（与 Result.MarkerRect 相同）
+type Polygon []image.Point
+
+// RectPolygon 把矩形写成四个顶点的多边形
+func RectPolygon(r image.Rectangle) Polygon {
+	return Polygon{r.Min, {r.Max.X, r.Min.Y}, r.Max, {r.Min.X, r.Max.Y}}
+}
+
+// ParseMarkerExclusions 解析角标检测时忽略的区域：以 ; 分隔，每个区域为逗号分隔的坐标，
+// 4 个数为矩形的左上角与右下角（x1,y1,x2,y2），6 个及以上为多边形的各顶点（x1,y1,x2,y2,x3,y3,...），
+// 如 "0,0,300,120; 700,900,1024,900,1024,1024"
+func ParseMarkerExclusions(s string) ([]Polygon, error) {
+	var polygons []Polygon
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		fields := strings.Split(part, ",")
+		if len(fields) != 4 && (len(fields) < 6 || len(fields)%2 != 0) {
+			return nil, fmt.Errorf("忽略区域应为 4 个数（矩形）或 3 个以上的顶点: %q", part)
+		}
+		nums := make([]int, len(fields))
+		for i, f := range fields {
+			n, err := strconv.Atoi(strings.TrimSpace(f))
+			if err != nil {
+				return nil, fmt.Errorf("忽略区域坐标错误: %q", part)
+			}
+			nums[i] = n
+		}
+
+		if len(nums) == 4 {
+			r := image.Rectangle{Min: image.Pt(nums[0], nums[1]), Max: image.Pt(nums[2], nums[3])}
+			if r.Empty() {
+				return nil, fmt.Errorf("忽略区域为空: %q", part)
+			}
+			polygons = append(polygons, RectPolygon(r))
+			continue
+		}
+		var p Polygon
+		for i := 0; i < len(nums); i += 2 {
+			p = append(p, image.Pt(nums[i], nums[i+1]))
+		}
+		polygons = append(polygons, p)
+	}
+	return polygons, nil
+}
+
+// excludeMarkerRegions 把 d.MarkerExclusions 覆盖的区域从角标掩码中去掉。
+// 掩码与棋盘图同样大小，直接截取棋盘区域时不是 BoardWarpSize 见方，顶点按比例换算
+func (d *Detector) excludeMarkerRegions(mask *gocv.Mat) {
+	if len(d.MarkerExclusions) == 0 {
+		return
+	}
+
+	sx := float64(mask.Cols()) / float64(BoardWarpSize)
+	sy := float64(mask.Rows()) / float64(BoardWarpSize)
+	points := make([][]image.Point, len(d.MarkerExclusions))
+	for i, p := range d.MarkerExclusions {
+		for _, pt := range p {
+			points[i] = append(points[i], image.Pt(int(float64(pt.X)*sx), int(float64(pt.Y)*sy)))
+		}
+	}
+
+	pv := gocv.NewPointsVectorFromPoints(points)
+	defer pv.Close()
+	gocv.FillPoly(mask, pv, color.RGBA{})
+}
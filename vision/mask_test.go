@@ -0,0 +1,56 @@
+package vision
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+func TestParseMarkerExclusions(t *testing.T) {
+	got, err := ParseMarkerExclusions(" 0,0,300,120 ; 700,900, 1024,900,1024,1024;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Polygon{
+		{{0, 0}, {300, 0}, {300, 120}, {0, 120}},
+		{{700, 900}, {1024, 900}, {1024, 1024}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseMarkerExclusions() = %v, want %v", got, want)
+	}
+
+	if got, err := ParseMarkerExclusions(""); err != nil || got != nil {
+		t.Errorf("空配置应返回 nil, got %v, %v", got, err)
+	}
+	for _, bad := range []string{"1,2,3", "0,0,10,10,20", "0,0,a,10", "10,10,0,0", "0,0,10,10,20,20,30"} {
+		if _, err := ParseMarkerExclusions(bad); err == nil {
+			t.Errorf("ParseMarkerExclusions(%q) 应返回错误", bad)
+		}
+	}
+}
+
+func TestMarkerExclusions(t *testing.T) {
+	// 直接截取棋盘区域时棋盘图为 512 见方，忽略区域按校正棋盘坐标（1024 见方）配置
+	img := gocv.NewMatWithSizeFromScalar(Skins[0].BoardColor, 512, 512, gocv.MatTypeCV8UC3)
+	defer img.Close()
+
+	// 右上角的“横幅”比棋盘上的角标大，不忽略时会被当成角标
+	banner := img.Region(image.Rect(400, 0, 512, 40))
+	banner.SetTo(gocv.NewScalar(0, 0, 255, 0))
+	banner.Close()
+	marker := img.Region(image.Rect(200, 200, 210, 210))
+	marker.SetTo(gocv.NewScalar(0, 0, 255, 0))
+	marker.Close()
+
+	if rect, found := NewDetector().findLastMoveMarker(img, Skins[0]); !found || rect.Min.X != 400 {
+		t.Fatalf("未设置忽略区域时应找到横幅, got %v %v", rect, found)
+	}
+
+	d := NewDetector(WithMarkerExclusions([]Polygon{RectPolygon(image.Rect(780, 0, 1024, 100))}))
+	rect, found := d.findLastMoveMarker(img, Skins[0])
+	if !found || rect != image.Rect(200, 200, 210, 210) {
+		t.Errorf("findLastMoveMarker() = %v %v, want 棋盘上的角标", rect, found)
+	}
+}
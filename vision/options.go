@@ -0,0 +1,127 @@
+package vision
+
+import (
+	"image"
+	"regexp"
+	"time"
+
+	"goboardsync/board"
+	"goboardsync/ocr"
+)
+
+// Option 创建 Detector 时的可选配置
+type Option func(*Detector)
+
+// WithSkin 指定棋盘皮肤名称；为空或未知时按棋盘底色自动识别
+func WithSkin(name string) Option {
+	return func(d *Detector) { d.Skin = name }
+}
+
+// WithClassifier 指定交叉点分类器（如 LoadTemplateClassifier 加载的模板分类器）
+func WithClassifier(c StoneClassifier) Option {
+	return func(d *Detector) { d.Classifier = c }
+}
+
+// WithConfirmButton 指定“确定/确认”按钮模板（如 LoadButtonTemplate 加载的模板）
+func WithConfirmButton(b *ButtonTemplate) Option {
+	return func(d *Detector) { d.ConfirmButton = b }
+}
+
+// WithLightingNormalization 是否按棋盘底色亮度自适应调整角标阈值
+func WithLightingNormalization(enabled bool) Option {
+	return func(d *Detector) { d.NormalizeLighting = enabled }
+}
+
+// WithWarpSkip 棋盘角点与坐标轴平行时是否直接截取棋盘区域、省去透视变换
+func WithWarpSkip(enabled bool) Option {
+	return func(d *Detector) { d.SkipWarpWhenAligned = enabled }
+}
+
+// WithOCREndpoint 指定 OCR 服务地址
+func WithOCREndpoint(url string) Option {
+	return func(d *Detector) { d.OCREndpoint = url }
+}
+
+// WithOCRBackend 指定 OCR 服务的请求与响应格式（见 ocr.Backends）与识别语言
+func WithOCRBackend(backend ocr.Backend, language string) Option {
+	return func(d *Detector) {
+		d.OCRBackend = backend
+		d.OCRLanguage = language
+	}
+}
+
+// WithOCR 指定识别文本的 OCR 服务（如 ocr.Chain），优先于 WithOCREndpoint 与 WithOCRBackend
+func WithOCR(r ocr.Recognizer) Option {
+	return func(d *Detector) { d.OCR = r }
+}
+
+// WithMoveNumberPatterns 指定提取手数的正则（见 ocr.CompileMoveNumberPatterns），用于其他语言或其他 App 的界面
+func WithMoveNumberPatterns(patterns []*regexp.Regexp) Option {
+	return func(d *Detector) { d.MoveNumberPatterns = patterns }
+}
+
+// WithBoardCorners 指定分辨率 key（如 "1920x1080"）下的棋盘四角（左上、右上、右下、左下），
+// 只对这个 Detector 生效，优先于 FixedBoardCorners
+func WithBoardCorners(key string, corners []image.Point) Option {
+	return func(d *Detector) {
+		if d.BoardCorners == nil {
+			d.BoardCorners = make(map[string][]image.Point)
+		}
+		d.BoardCorners[key] = append([]image.Point(nil), corners...)
+	}
+}
+
+// WithGame 提供已同步的对局，OCR 未识别到手数时从盘面推断手数
+func WithGame(g *board.Game) Option {
+	return func(d *Detector) { d.Game = g }
+}
+
+// WithFusion 是否融合角标、手数奇偶、局面变化与交叉点分类，取最一致的结果
+func WithFusion(enabled bool) Option {
+	return func(d *Detector) { d.Fusion = enabled }
+}
+
+// WithBoardModel 提供已同步的局面，OCR 与分类器都无法判断颜色时按双方棋子数推断
+func WithBoardModel(b *board.Board) Option {
+	return func(d *Detector) { d.BoardModel = b }
+}
+
+// WithThreshold 设置角标轮廓的最小面积（像素），过滤截图压缩或动画产生的小色块
+func WithThreshold(minMarkerArea float64) Option {
+	return func(d *Detector) { d.MinMarkerArea = minMarkerArea }
+}
+
+// WithMarkerExclusions 设置找角标时忽略的区域（见 ParseMarkerExclusions）
+func WithMarkerExclusions(polygons []Polygon) Option {
+	return func(d *Detector) { d.MarkerExclusions = polygons }
+}
+
+// WithSmoothing 对最近 frames 帧识别出的交叉点做多数表决（见 Detector.SmoothFrames），0 或 1 为不表决
+func WithSmoothing(frames int) Option {
+	return func(d *Detector) { d.SmoothFrames = frames }
+}
+
+// WithDeadMark 设置死子标记的判断参数（见 Detector.DeadMark）
+func WithDeadMark(m DeadMark) Option {
+	return func(d *Detector) { d.DeadMark = m }
+}
+
+// WithDebugDetail 是否在 Result.Debug 中记录详细信息（见 Detector.DebugDetail）
+func WithDebugDetail(enabled bool) Option {
+	return func(d *Detector) { d.DebugDetail = enabled }
+}
+
+// WithInterval 设置 Watch 的截图间隔
+func WithInterval(interval time.Duration) Option {
+	return func(d *Detector) { d.WatchInterval = interval }
+}
+
+// WithMinConfidence 设置 Watch 发送结果的最低置信度
+func WithMinConfidence(confidence float64) Option {
+	return func(d *Detector) { d.MinConfidence = confidence }
+}
+
+// WithTuning 设置初始的可热更新参数
+func WithTuning(t Tuning) Option {
+	return func(d *Detector) { d.SetTuning(t) }
+}
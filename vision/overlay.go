@@ -0,0 +1,57 @@
+package vision
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"goboardsync/coords"
+
+	"gocv.io/x/gocv"
+)
+
+// gocv 的颜色按 BGR 顺序
+var (
+	overlayGrid   = color.RGBA{200, 200, 200, 0}
+	overlayMarker = color.RGBA{0, 255, 255, 0}
+	overlayCorner = color.RGBA{0, 255, 0, 0}
+	overlayStone  = color.RGBA{0, 0, 255, 0}
+	overlayText   = color.RGBA{255, 0, 255, 0}
+)
+
+// DrawOverlay 校正截图中的棋盘，画出网格、角标（黄框）、选中的交叉点（红圈）与手数、坐标、置信度，
+// 用于实时预览与识别报告。分辨率未配置棋盘角点或校正失败时返回错误；返回的 Mat 由调用方 Close
+func DrawOverlay(img gocv.Mat, r Result) (gocv.Mat, error) {
+	return (&Detector{}).DrawOverlay(img, r)
+}
+
+// DrawOverlay 同包级的 DrawOverlay，棋盘角点先查 d.BoardCorners
+func (d *Detector) DrawOverlay(img gocv.Mat, r Result) (gocv.Mat, error) {
+	_, corners, ok := d.boardCorners(img)
+	if !ok {
+		return gocv.NewMat(), fmt.Errorf("未配置棋盘角点: %dx%d", img.Cols(), img.Rows())
+	}
+	warped, err := WarpBoard(img, corners)
+	if err != nil {
+		return gocv.NewMat(), err
+	}
+
+	w, h := warped.Cols(), warped.Rows()
+	stepW, stepH := float64(w)/19.0, float64(h)/19.0
+	for i := 0; i < 19; i++ {
+		y := int(float64(i)*stepH + stepH/2)
+		gocv.Line(&warped, image.Pt(0, y), image.Pt(w, y), overlayGrid, 1)
+		x := int(float64(i)*stepW + stepW/2)
+		gocv.Line(&warped, image.Pt(x, 0), image.Pt(x, h), overlayGrid, 1)
+	}
+
+	label := "no move"
+	if r.X != 0 {
+		gocv.Rectangle(&warped, r.MarkerRect, overlayMarker, 2)
+		gocv.Circle(&warped, r.MarkerRect.Min, 5, overlayCorner, -1)
+		gocv.Circle(&warped, r.StoneCenter, 8, overlayStone, 2)
+		label = fmt.Sprintf("#%d %s %s %.2f", r.Move, r.Color, coords.FormatPhone(r.X, r.Y), r.Confidence)
+	}
+	gocv.PutText(&warped, label, image.Pt(20, 50), gocv.FontHersheySimplex, 1.2, overlayText, 3)
+	return warped, nil
+}
@@ -0,0 +1,33 @@
+package vision
+
+import (
+	"image"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+func TestDrawOverlay(t *testing.T) {
+	tests := []struct {
+		name    string
+		w, h    int
+		wantErr bool
+	}{
+		{"已配置角点", 1200, 2670, false},
+		{"未配置角点", 800, 600, true},
+	}
+
+	r := Result{Move: 37, Color: "B", X: 16, Y: 4, Confidence: 0.9, MarkerRect: image.Rect(100, 100, 120, 120), StoneCenter: image.Pt(110, 110)}
+	for _, tt := range tests {
+		img := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(90, 170, 220, 0), tt.h, tt.w, gocv.MatTypeCV8UC3)
+		overlay, err := DrawOverlay(img, r)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: DrawOverlay() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil && (overlay.Cols() != BoardWarpSize || overlay.Rows() != BoardWarpSize) {
+			t.Errorf("%s: 叠加图尺寸 = %dx%d, want %dx%d", tt.name, overlay.Cols(), overlay.Rows(), BoardWarpSize, BoardWarpSize)
+		}
+		overlay.Close()
+		img.Close()
+	}
+}
@@ -0,0 +1,69 @@
+package vision
+
+import (
+	"fmt"
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+// FixedPrivacyRegions 按分辨率配置的个人信息区域：顶部状态栏（通知可能带有联系人）与双方的头像、名字栏。
+// 导出问题报告时打上马赛克，棋盘、计时与手数仍然可见
+var FixedPrivacyRegions = map[string][]image.Rectangle{
+	"1200x2670": {
+		image.Rect(0, 0, 1200, 120),
+		image.Rect(0, 300, 1200, 400),
+	},
+	"2670x1200": {
+		image.Rect(1200, 0, 2670, 80),
+	},
+}
+
+// privacyBlock 马赛克的格子大小（像素），足以让名字与头像无法辨认
+const privacyBlock = 24
+
+// AnonymizeImage 给截图中的个人信息区域（见 FixedPrivacyRegions）打上马赛克，直接修改 img。
+// 分辨率没有配置时返回错误，调用方不应导出这张截图
+func AnonymizeImage(img gocv.Mat) error {
+	regions, ok := FixedPrivacyRegions[fmt.Sprintf("%dx%d", img.Cols(), img.Rows())]
+	if !ok {
+		return fmt.Errorf("未配置个人信息区域: %dx%d", img.Cols(), img.Rows())
+	}
+
+	for _, region := range regions {
+		region = region.Intersect(image.Rect(0, 0, img.Cols(), img.Rows()))
+		if region.Empty() {
+			continue
+		}
+		roi := img.Region(region)
+		small := gocv.NewMat()
+		gocv.Resize(roi, &small, image.Pt(max(1, region.Dx()/privacyBlock), max(1, region.Dy()/privacyBlock)), 0, 0, gocv.InterpolationArea)
+		// Region 与 img 共用像素，放大写回即修改原图
+		gocv.Resize(small, &roi, region.Size(), 0, 0, gocv.InterpolationNearestNeighbor)
+		small.Close()
+		roi.Close()
+	}
+	return nil
+}
+
+// AnonymizeJPEG 解码一张 JPEG 截图，打上马赛克（见 AnonymizeImage）后重新编码
+func AnonymizeJPEG(data []byte) ([]byte, error) {
+	img, err := gocv.IMDecode(data, gocv.IMReadColor)
+	if err != nil || img.Empty() {
+		if err == nil {
+			img.Close()
+		}
+		return nil, fmt.Errorf("无法解码截图")
+	}
+	defer img.Close()
+
+	if err := AnonymizeImage(img); err != nil {
+		return nil, err
+	}
+	buf, err := gocv.IMEncode(".jpg", img)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Close()
+	return append([]byte(nil), buf.GetBytes()...), nil
+}
@@ -0,0 +1,40 @@
+package vision
+
+import (
+	"fmt"
+	"image"
+	"testing"
+)
+
+func TestPrivacyRegions(t *testing.T) {
+	for size, regions := range FixedPrivacyRegions {
+		var w, h int
+		fmt.Sscanf(size, "%dx%d", &w, &h)
+		corners := FixedBoardCorners[size]
+		board := image.Rectangle{Min: corners[0], Max: corners[2]}
+
+		for _, r := range regions {
+			if !r.In(image.Rect(0, 0, w, h)) {
+				t.Errorf("%s: 区域 %v 超出截图", size, r)
+			}
+			// 棋盘、计时与名字之外的信息要留给排查
+			if r.Overlaps(board) {
+				t.Errorf("%s: 区域 %v 遮住了棋盘 %v", size, r, board)
+			}
+			for _, clock := range []image.Rectangle{FixedClockRegions[size].Black, FixedClockRegions[size].White} {
+				if r.Overlaps(clock) {
+					t.Errorf("%s: 区域 %v 遮住了计时 %v", size, r, clock)
+				}
+			}
+		}
+		for _, name := range []image.Rectangle{FixedPlayerRegions[size].Black, FixedPlayerRegions[size].White} {
+			covered := false
+			for _, r := range regions {
+				covered = covered || name.In(r)
+			}
+			if !covered {
+				t.Errorf("%s: 名字区域 %v 没有被遮住", size, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package vision
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"goboardsync/coords"
+)
+
+// ParseSampleFilename 从样本文件名解析手数、颜色和预期坐标（手机坐标，1-19）
+// 文件名格式: {move}-{coord}-{color}.jpg 或 {move}-{coord}-{color}.png
+// 例如: 1-P4-black.jpg, 2-Q5-white.png
+func ParseSampleFilename(filename string) (int, string, int, int, error) {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+
+	parts := strings.Split(base, "-")
+	if len(parts) < 3 {
+		return 0, "", 0, 0, fmt.Errorf("文件名格式不正确: %s", filename)
+	}
+
+	moveNumber, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", 0, 0, fmt.Errorf("手数解析失败: %v", err)
+	}
+
+	color := strings.ToUpper(string(parts[2][0]))
+	if color != "B" && color != "W" {
+		return 0, "", 0, 0, fmt.Errorf("颜色不正确: %s", parts[2])
+	}
+
+	coordX, coordY, err := coords.ParsePhone(parts[1])
+	if err != nil {
+		return 0, "", 0, 0, err
+	}
+
+	return moveNumber, color, coordX, coordY, nil
+}
+
+// SampleFilename 返回 ParseSampleFilename 能解析的样本文件名，x、y 为手机坐标，color 为 B 或 W。
+// 手数补足三位（如 037-J10-B.jpg），目录中的样本按手数排列
+func SampleFilename(moveNumber int, color string, x, y int, ext string) string {
+	return fmt.Sprintf("%03d-%s-%s%s", moveNumber, coords.FormatPhone(x, y), color, ext)
+}
@@ -0,0 +1,18 @@
+package vision
+
+import "testing"
+
+func TestSampleFilename(t *testing.T) {
+	name := SampleFilename(37, "B", 10, 10, ".jpg")
+	if name != "037-J10-B.jpg" {
+		t.Errorf("SampleFilename() = %q, want 037-J10-B.jpg", name)
+	}
+
+	move, color, x, y, err := ParseSampleFilename(name)
+	if err != nil || move != 37 || color != "B" || x != 10 || y != 10 {
+		t.Errorf("ParseSampleFilename(%q) = %d, %q, %d, %d, %v", name, move, color, x, y, err)
+	}
+	if _, _, x, y, _ := ParseSampleFilename(SampleFilename(120, "W", 1, 19, ".jpg")); x != 1 || y != 19 {
+		t.Errorf("角上的样本解析为 (%d, %d), want (1, 19)", x, y)
+	}
+}
@@ -0,0 +1,139 @@
+package vision
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"gocv.io/x/gocv"
+)
+
+// Skin 棋盘皮肤的检测参数
+type Skin struct {
+	Name string
+	// BoardColor 棋盘底色（BGR），用于自动识别皮肤
+	BoardColor gocv.Scalar
+	// BoardValue 标定 MarkerRanges 时棋盘底色的 V 值，亮度自适应以此为基准
+	BoardValue float64
+	// MarkerRanges 最后一手角标（红色/蓝色）的 HSV 阈值
+	MarkerRanges []HSVRange
+}
+
+// Skins 已知的棋盘皮肤，第一个为默认皮肤
+var Skins = []Skin{
+	{
+		Name:       "classic",
+		BoardColor: gocv.NewScalar(95, 175, 220, 0),
+		BoardValue: 200,
+		MarkerRanges: []HSVRange{
+			{gocv.NewScalar(0, 160, 100, 0), gocv.NewScalar(10, 255, 255, 0)},
+			{gocv.NewScalar(170, 160, 100, 0), gocv.NewScalar(180, 255, 255, 0)},
+			{gocv.NewScalar(100, 160, 100, 0), gocv.NewScalar(140, 255, 255, 0)},
+		},
+	},
+	{
+		Name:       "dark",
+		BoardColor: gocv.NewScalar(50, 52, 58, 0),
+		BoardValue: 60,
+		MarkerRanges: []HSVRange{
+			{gocv.NewScalar(0, 120, 70, 0), gocv.NewScalar(10, 255, 255, 0)},
+			{gocv.NewScalar(170, 120, 70, 0), gocv.NewScalar(180, 255, 255, 0)},
+			{gocv.NewScalar(95, 120, 70, 0), gocv.NewScalar(140, 255, 255, 0)},
+		},
+	},
+	{
+		Name:       "green",
+		BoardColor: gocv.NewScalar(120, 170, 130, 0),
+		BoardValue: 170,
+		MarkerRanges: []HSVRange{
+			{gocv.NewScalar(0, 150, 100, 0), gocv.NewScalar(10, 255, 255, 0)},
+			{gocv.NewScalar(170, 150, 100, 0), gocv.NewScalar(180, 255, 255, 0)},
+			{gocv.NewScalar(105, 150, 100, 0), gocv.NewScalar(140, 255, 255, 0)},
+		},
+	},
+}
+
+// ForcedSkin 指定皮肤名称；为空时按棋盘底色自动识别
+//
+// Deprecated: 使用 WithSkin 为每个 Detector 单独设置，此变量只作为 NewDetector 的默认值。
+var ForcedSkin = ""
+
+// SkinByName 按名称查找皮肤
+func SkinByName(name string) (Skin, bool) {
+	for _, s := range Skins {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Skin{}, false
+}
+
+// ClassifySkin 采样棋盘底色，返回颜色最接近的皮肤
+func ClassifySkin(boardImg gocv.Mat) Skin {
+	color := SampleBoardColor(boardImg)
+
+	best := Skins[0]
+	bestDist := math.MaxFloat64
+	for _, s := range Skins {
+		d := math.Sqrt(
+			math.Pow(color.Val1-s.BoardColor.Val1, 2) +
+				math.Pow(color.Val2-s.BoardColor.Val2, 2) +
+				math.Pow(color.Val3-s.BoardColor.Val3, 2))
+		if d < bestDist {
+			best, bestDist = s, d
+		}
+	}
+	return best
+}
+
+// selectSkin 优先使用指定的皮肤，否则自动识别
+func (d *Detector) selectSkin(boardImg gocv.Mat) Skin {
+	if d.Skin != "" {
+		if s, ok := SkinByName(d.Skin); ok {
+			return s
+		}
+	}
+	return ClassifySkin(boardImg)
+}
+
+// boardSamplePoints 相邻四个交叉点正中间的位置，棋子不会覆盖这些点
+func boardSamplePoints(w, h int) []image.Point {
+	cellW := float64(w) / 19.0
+	cellH := float64(h) / 19.0
+
+	var points []image.Point
+	for i := 1; i < 19; i++ {
+		for j := 1; j < 19; j++ {
+			x, y := int(float64(i)*cellW), int(float64(j)*cellH)
+			if x < w && y < h {
+				points = append(points, image.Pt(x, y))
+			}
+		}
+	}
+	return points
+}
+
+// SampleBoardColor 返回棋盘底色各通道的中位数
+func SampleBoardColor(img gocv.Mat) gocv.Scalar {
+	points := boardSamplePoints(img.Cols(), img.Rows())
+	if len(points) == 0 {
+		return gocv.Scalar{}
+	}
+
+	channels := make([][]int, 3)
+	for _, p := range points {
+		v := img.GetVecbAt(p.Y, p.X)
+		for c := 0; c < 3 && c < len(v); c++ {
+			channels[c] = append(channels[c], int(v[c]))
+		}
+	}
+
+	median := func(values []int) float64 {
+		if len(values) == 0 {
+			return 0
+		}
+		sort.Ints(values)
+		return float64(values[len(values)/2])
+	}
+	return gocv.NewScalar(median(channels[0]), median(channels[1]), median(channels[2]), 0)
+}
@@ -0,0 +1,51 @@
+package vision
+
+import (
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+func TestClassifySkin(t *testing.T) {
+	for _, skin := range Skins {
+		t.Run(skin.Name, func(t *testing.T) {
+			img := gocv.NewMatWithSizeFromScalar(skin.BoardColor, 190, 190, gocv.MatTypeCV8UC3)
+			defer img.Close()
+
+			if got := ClassifySkin(img); got.Name != skin.Name {
+				t.Errorf("ClassifySkin() = %s, want %s", got.Name, skin.Name)
+			}
+		})
+	}
+}
+
+func TestSelectSkinForced(t *testing.T) {
+	img := gocv.NewMatWithSizeFromScalar(Skins[0].BoardColor, 190, 190, gocv.MatTypeCV8UC3)
+	defer img.Close()
+
+	if got := NewDetector(WithSkin("dark")).selectSkin(img); got.Name != "dark" {
+		t.Errorf("selectSkin() = %s, want dark", got.Name)
+	}
+
+	if got := NewDetector(WithSkin("unknown")).selectSkin(img); got.Name != Skins[0].Name {
+		t.Errorf("未知皮肤应回退到自动识别, got %s", got.Name)
+	}
+}
+
+func TestDetectorsAreIndependent(t *testing.T) {
+	dark := NewDetector(WithSkin("dark"), WithTuning(Tuning{MarkerMinS: 100}))
+	plain := NewDetector()
+
+	if plain.Skin != "" || plain.Tuning() != (Tuning{}) {
+		t.Errorf("默认 Detector 受其他实例影响: skin=%q tuning=%+v", plain.Skin, plain.Tuning())
+	}
+	if dark.Tuning().MarkerMinS != 100 {
+		t.Errorf("Tuning() = %+v, want MarkerMinS 100", dark.Tuning())
+	}
+
+	ForcedSkin = "green"
+	defer func() { ForcedSkin = "" }()
+	if got := NewDetector().Skin; got != "green" {
+		t.Errorf("NewDetector().Skin = %q, want 已弃用的 ForcedSkin 作为默认值", got)
+	}
+}
@@ -0,0 +1,76 @@
+package vision
+
+import (
+	"image"
+	"sync"
+)
+
+// smoother 对最近几帧识别出的交叉点做多数表决，抑制角标在相邻交叉点之间闪烁
+type smoother struct {
+	mu     sync.Mutex
+	recent []Result
+	// reported 上次报告的交叉点，票数不低于其他交叉点时保持不变
+	reported *image.Point
+}
+
+// vote 记录一帧识别成功的结果，返回最近 frames 帧中票数最多的交叉点上最新的一帧。
+// 票数相同时保持上次报告的交叉点，其次取较新的；新的一手要在窗口中占多数后才会报告
+func (s *smoother) vote(r Result, frames int) (Result, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.recent = append(s.recent, r)
+	if len(s.recent) > frames {
+		s.recent = append(s.recent[:0], s.recent[len(s.recent)-frames:]...)
+	}
+
+	votes := make(map[image.Point]int)
+	for _, h := range s.recent {
+		votes[h.GridIndex]++
+	}
+	best := r.GridIndex
+	if s.reported != nil && votes[*s.reported] > 0 {
+		best = *s.reported
+	}
+	for i := len(s.recent) - 1; i >= 0; i-- {
+		if p := s.recent[i].GridIndex; votes[p] > votes[best] {
+			best = p
+		}
+	}
+	s.reported = &best
+
+	for i := len(s.recent) - 1; i >= 0; i-- {
+		if s.recent[i].GridIndex == best {
+			return s.recent[i], votes[best]
+		}
+	}
+	return r, votes[best]
+}
+
+func (s *smoother) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recent = nil
+	s.reported = nil
+}
+
+// smooth 开启 SmoothFrames 时把本帧结果换成最近几帧多数表决的结果；没识别出最后一手的帧不参与表决
+func (d *Detector) smooth(r Result) Result {
+	if d.SmoothFrames <= 1 || r.X == 0 {
+		return r
+	}
+
+	voted, votes := d.smoother.vote(r, d.SmoothFrames)
+	r.Debug.SmoothVotes = votes
+	if voted.GridIndex == r.GridIndex {
+		return r
+	}
+	r.Debug.SmoothedFrom = &[2]int{r.X, r.Y}
+	voted.Debug = r.Debug
+	return voted
+}
+
+// ResetSmoothing 清除多数表决的历史帧，换盘或重新同步时调用，避免上一盘的结果参与表决
+func (d *Detector) ResetSmoothing() {
+	d.smoother.reset()
+}
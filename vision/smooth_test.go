@@ -0,0 +1,43 @@
+package vision
+
+import (
+	"image"
+	"testing"
+)
+
+func TestSmooth(t *testing.T) {
+	d := NewDetector(WithSmoothing(3))
+	frame := func(x, y int) Result {
+		return Result{X: x + 1, Y: y + 1, GridIndex: image.Pt(x, y)}
+	}
+
+	tests := []struct {
+		name   string
+		in     Result
+		wantAt image.Point
+	}{
+		{"第一帧", frame(3, 3), image.Pt(3, 3)},
+		{"闪到相邻交叉点", frame(4, 3), image.Pt(3, 3)},
+		{"闪回", frame(3, 3), image.Pt(3, 3)},
+		{"没识别出的帧不参与", Result{}, image.Point{}},
+		{"新的一手第一帧", frame(10, 10), image.Pt(3, 3)},
+		{"新的一手占多数", frame(10, 10), image.Pt(10, 10)},
+		{"单帧闪动不影响", frame(4, 3), image.Pt(10, 10)},
+		{"票数相同时保持", frame(3, 3), image.Pt(10, 10)},
+	}
+	for _, tt := range tests {
+		got := d.smooth(tt.in)
+		if got.GridIndex != tt.wantAt {
+			t.Errorf("%s: smooth() = %v, want %v", tt.name, got.GridIndex, tt.wantAt)
+		}
+	}
+
+	d.ResetSmoothing()
+	if got := d.smooth(frame(4, 3)); got.GridIndex != image.Pt(4, 3) {
+		t.Errorf("ResetSmoothing 后 smooth() = %v, want 本帧结果", got.GridIndex)
+	}
+
+	if got := NewDetector().smooth(frame(4, 3)); got.Debug.SmoothVotes != 0 {
+		t.Errorf("未开启时不应表决: %v", got.Debug)
+	}
+}
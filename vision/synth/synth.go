@@ -0,0 +1,259 @@
+// Package synth 生成腾讯围棋风格的合成截图：任意局面、最后一手角标与手数文字。
+// 用于为识别流程批量生成带标注的测试数据，并通过噪声、亮度与皮肤颜色的变化检验识别的稳健性。
+package synth
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"math/rand"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+	"goboardsync/vision"
+
+	"gocv.io/x/gocv"
+)
+
+// Scene 一张截图的内容，Board 与 Last 使用 KaTrain 坐标
+type Scene struct {
+	Board board.Board
+	// Last 最后一手的位置，为 nil 时不画角标；角标颜色按该点棋子决定（黑子红色、白子蓝色）
+	Last *image.Point
+	// MoveNumber 显示为“第 N 手”，为 0 时不显示
+	MoveNumber int
+	// Indicator 落子指示标（点击落子点后、点确认前 App 显示的十字）的位置，为 nil 时不画
+	Indicator *image.Point
+	// Dead 数子时 App 标为死子的棋子，中央画与棋子反色的小方块（见 vision.DeadMark）
+	Dead []image.Point
+}
+
+// LastColor 返回最后一手的颜色，没有最后一手时返回 board.Empty
+func (s Scene) LastColor() board.Color {
+	if s.Last == nil {
+		return board.Empty
+	}
+	return s.Board.At(s.Last.X, s.Last.Y)
+}
+
+// Style 截图的外观
+type Style struct {
+	Width, Height int
+	// Board 棋盘在截图中的区域，19 路均分，交叉点位于每格中心
+	Board image.Rectangle
+
+	Background  color.RGBA
+	BoardColor  color.RGBA
+	LineColor   color.RGBA
+	TextColor   color.RGBA
+	BlackMarker color.RGBA
+	WhiteMarker color.RGBA
+
+	// Brightness 整体亮度倍数，1 为不变，用于模拟屏幕亮度与夜间模式
+	Brightness float64
+	// Noise 每个像素各通道随机加减的最大值（0-255），Seed 为随机数种子
+	Noise int
+	Seed  int64
+}
+
+// DefaultStyle 返回 1200x2670 竖屏、classic 皮肤的外观，棋盘位置与 vision.FixedBoardCorners 一致
+func DefaultStyle() Style {
+	style, _ := StyleFor("1200x2670")
+	return style
+}
+
+// StyleFor 返回某个分辨率（如 "1200x2670"、"2670x1200"）的 classic 皮肤外观，
+// 分辨率需在 vision.FixedBoardCorners 中配置过
+func StyleFor(resolution string) (Style, error) {
+	corners, ok := vision.FixedBoardCorners[resolution]
+	if !ok {
+		return Style{}, fmt.Errorf("不支持的分辨率: %s", resolution)
+	}
+	var w, h int
+	if _, err := fmt.Sscanf(resolution, "%dx%d", &w, &h); err != nil {
+		return Style{}, fmt.Errorf("无效的分辨率: %s", resolution)
+	}
+
+	style := Style{
+		Width:       w,
+		Height:      h,
+		Board:       image.Rectangle{Min: corners[0], Max: corners[2]},
+		Background:  color.RGBA{243, 238, 228, 255},
+		LineColor:   color.RGBA{60, 45, 30, 255},
+		TextColor:   color.RGBA{50, 50, 50, 255},
+		BlackMarker: color.RGBA{230, 40, 40, 255},
+		WhiteMarker: color.RGBA{40, 90, 230, 255},
+		Brightness:  1,
+	}
+	style.SetSkin(vision.Skins[0])
+	return style, nil
+}
+
+// SetSkin 按皮肤设置棋盘底色
+func (s *Style) SetSkin(skin vision.Skin) {
+	c := skin.BoardColor // BGR
+	s.BoardColor = color.RGBA{uint8(c.Val3), uint8(c.Val2), uint8(c.Val1), 255}
+}
+
+// CellSize 返回一格的宽高
+func (s Style) CellSize() (float64, float64) {
+	return float64(s.Board.Dx()) / coords.Size, float64(s.Board.Dy()) / coords.Size
+}
+
+// Center 返回 KaTrain 坐标 (x, y) 的交叉点中心在截图中的位置
+func (s Style) Center(x, y int) image.Point {
+	gx, gy := coords.ToPhone(x, y)
+	cw, ch := s.CellSize()
+	return image.Pt(
+		s.Board.Min.X+int((float64(gx-1)+0.5)*cw),
+		s.Board.Min.Y+int((float64(gy-1)+0.5)*ch),
+	)
+}
+
+// Render 按 style 画出 scene
+func Render(scene Scene, style Style) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, style.Width, style.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(style.Background), image.Point{}, draw.Src)
+	draw.Draw(img, style.Board, image.NewUniform(style.BoardColor), image.Point{}, draw.Src)
+
+	cw, ch := style.CellSize()
+	first, last := style.Center(0, coords.Size-1), style.Center(coords.Size-1, 0)
+	for i := 0; i < coords.Size; i++ {
+		p := style.Center(i, i)
+		fillRect(img, image.Rect(p.X-1, first.Y, p.X+1, last.Y+1), style.LineColor)
+		fillRect(img, image.Rect(first.X, p.Y-1, last.X+1, p.Y+1), style.LineColor)
+	}
+	for _, x := range []int{3, 9, 15} {
+		for _, y := range []int{3, 9, 15} {
+			fillCircle(img, style.Center(x, y), cw*0.09, style.LineColor)
+		}
+	}
+
+	radius := math.Min(cw, ch) * 0.47
+	for x := 0; x < coords.Size; x++ {
+		for y := 0; y < coords.Size; y++ {
+			switch scene.Board.At(x, y) {
+			case board.Black:
+				fillCircle(img, style.Center(x, y), radius, color.RGBA{25, 25, 25, 255})
+			case board.White:
+				fillCircle(img, style.Center(x, y), radius, color.RGBA{150, 150, 150, 255})
+				fillCircle(img, style.Center(x, y), radius-2, color.RGBA{240, 240, 238, 255})
+			}
+		}
+	}
+
+	markSize := int(cw * vision.DefaultDeadMark.Size / 2)
+	for _, p := range scene.Dead {
+		mark := color.RGBA{240, 240, 238, 255}
+		if scene.Board.At(p.X, p.Y) == board.White {
+			mark = color.RGBA{25, 25, 25, 255}
+		}
+		c := style.Center(p.X, p.Y)
+		fillRect(img, image.Rect(c.X-markSize, c.Y-markSize, c.X+markSize, c.Y+markSize), mark)
+	}
+
+	if c := scene.LastColor(); c != board.Empty {
+		marker := style.BlackMarker
+		if c == board.White {
+			marker = style.WhiteMarker
+		}
+		// 角标为格子左上角的直角三角形
+		p := style.Center(scene.Last.X, scene.Last.Y)
+		corner := image.Pt(p.X-int(cw*0.35), p.Y-int(ch*0.35))
+		fillTriangle(img, corner, int(cw*0.3), marker)
+	}
+
+	if scene.Indicator != nil {
+		p := style.Center(scene.Indicator.X, scene.Indicator.Y)
+		dx, dy := int(cw*0.4), int(ch*0.4)
+		fillRect(img, image.Rect(p.X-dx, p.Y-3, p.X+dx, p.Y+3), style.LineColor)
+		fillRect(img, image.Rect(p.X-3, p.Y-dy, p.X+3, p.Y+dy), style.LineColor)
+	}
+
+	if scene.MoveNumber > 0 {
+		text := fmt.Sprintf("第%d手", scene.MoveNumber)
+		drawText(img, text, image.Pt(style.Board.Min.X+style.Board.Dx()/2, style.Board.Max.Y+60), 4, style.TextColor)
+	}
+
+	adjust(img, style)
+	return img
+}
+
+// ToMat 把合成截图转换为识别流程使用的 BGR 图像，调用方负责 Close
+func ToMat(img image.Image) (gocv.Mat, error) {
+	return gocv.ImageToMatRGB(img)
+}
+
+// RandomScene 随机落下 moves 手（黑先，交替落子，不考虑提子），最后一手带角标
+func RandomScene(r *rand.Rand, moves int) Scene {
+	var scene Scene
+	stone := board.Black
+	for i := 0; i < moves; i++ {
+		x, y := r.Intn(coords.Size), r.Intn(coords.Size)
+		if scene.Board.At(x, y) != board.Empty {
+			i--
+			continue
+		}
+		scene.Board.Set(x, y, stone)
+		scene.Last = &image.Point{X: x, Y: y}
+		stone = stone.Opponent()
+	}
+	scene.MoveNumber = moves
+	return scene
+}
+
+// SampleFilename 返回 vision.ParseSampleFilename 能解析的样本文件名，没有最后一手时返回空字符串
+func SampleFilename(scene Scene, ext string) string {
+	c := scene.LastColor()
+	if c == board.Empty {
+		return ""
+	}
+	name := "black"
+	if c == board.White {
+		name = "white"
+	}
+	return fmt.Sprintf("%d-%s-%s%s", scene.MoveNumber, coords.Format(scene.Last.X, scene.Last.Y, coords.Tencent), name, ext)
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func fillCircle(img *image.RGBA, center image.Point, radius float64, c color.RGBA) {
+	r := int(math.Ceil(radius))
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if float64(dx*dx+dy*dy) <= radius*radius {
+				img.SetRGBA(center.X+dx, center.Y+dy, c)
+			}
+		}
+	}
+}
+
+// fillTriangle 画直角在 corner、两条直角边长为 size 的三角形（向右下展开）
+func fillTriangle(img *image.RGBA, corner image.Point, size int, c color.RGBA) {
+	for dy := 0; dy < size; dy++ {
+		for dx := 0; dx < size-dy; dx++ {
+			img.SetRGBA(corner.X+dx, corner.Y+dy, c)
+		}
+	}
+}
+
+// adjust 按 Brightness 与 Noise 调整每个像素
+func adjust(img *image.RGBA, style Style) {
+	if style.Brightness == 1 && style.Noise <= 0 {
+		return
+	}
+	r := rand.New(rand.NewSource(style.Seed))
+	for i := 0; i < len(img.Pix); i += 4 {
+		for c := 0; c < 3; c++ {
+			v := float64(img.Pix[i+c]) * style.Brightness
+			if style.Noise > 0 {
+				v += float64(r.Intn(2*style.Noise+1) - style.Noise)
+			}
+			img.Pix[i+c] = uint8(math.Max(0, math.Min(255, v)))
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package synth
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"goboardsync/board"
+	"goboardsync/coords"
+	"goboardsync/vision"
+)
+
+func TestRender(t *testing.T) {
+	var scene Scene
+	scene.Board.Set(3, 15, board.Black)
+	scene.Board.Set(15, 3, board.White)
+	scene.Last = &image.Point{X: 15, Y: 3}
+	scene.MoveNumber = 2
+	scene.Indicator = &image.Point{X: 9, Y: 3}
+
+	style := DefaultStyle()
+	img := Render(scene, style)
+	if got := img.Bounds().Size(); got != image.Pt(1200, 2670) {
+		t.Fatalf("Render() 尺寸 = %v, want 1200x2670", got)
+	}
+
+	cw, ch := style.CellSize()
+	tests := []struct {
+		name string
+		at   image.Point
+		want color.RGBA
+	}{
+		{"黑子", style.Center(3, 15).Add(image.Pt(int(cw/4), int(ch/4))), color.RGBA{25, 25, 25, 255}},
+		{"白子", style.Center(15, 3).Add(image.Pt(int(cw/4), int(ch/4))), color.RGBA{240, 240, 238, 255}},
+		{"角标", style.Center(15, 3).Sub(image.Pt(int(cw*0.3), int(ch*0.3))), style.WhiteMarker},
+		{"空点", style.Center(9, 9).Add(image.Pt(int(cw/4), int(ch/4))), style.BoardColor},
+		{"指示标", style.Center(9, 3).Add(image.Pt(int(cw/4), 2)), style.LineColor},
+		{"棋盘外", image.Pt(5, 5), style.Background},
+	}
+	for _, tt := range tests {
+		if got := img.RGBAAt(tt.at.X, tt.at.Y); got != tt.want {
+			t.Errorf("%s %v = %v, want %v", tt.name, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestNoiseAndBrightness(t *testing.T) {
+	style := DefaultStyle()
+	style.Brightness = 0.5
+	style.Noise = 10
+	style.Seed = 1
+
+	img := Render(Scene{}, style)
+	got := img.RGBAAt(5, 5)
+	want := float64(style.Background.R) * 0.5
+	if d := float64(got.R) - want; d < -10 || d > 10 {
+		t.Errorf("背景 R = %d, want %.0f±10", got.R, want)
+	}
+
+	again := Render(Scene{}, style)
+	if again.RGBAAt(5, 5) != got {
+		t.Error("相同 Seed 应生成相同的图像")
+	}
+}
+
+func TestRandomScene(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for moves := 1; moves <= 50; moves += 7 {
+		scene := RandomScene(r, moves)
+		black, white := scene.Board.Count(board.Black), scene.Board.Count(board.White)
+		if black+white != moves || black-white < 0 || black-white > 1 {
+			t.Errorf("RandomScene(%d) 黑 %d 白 %d", moves, black, white)
+		}
+
+		name := SampleFilename(scene, ".png")
+		move, color, x, y, err := vision.ParseSampleFilename(name)
+		if err != nil {
+			t.Fatalf("ParseSampleFilename(%q) error = %v", name, err)
+		}
+		wantX, wantY := coords.ToPhone(scene.Last.X, scene.Last.Y)
+		if move != moves || color != scene.LastColor().String() || x != wantX || y != wantY {
+			t.Errorf("SampleFilename() = %q, 解析为 %d %s %d-%d", name, move, color, x, y)
+		}
+	}
+}
+
+// TestDetectSynthetic 在不同皮肤、亮度与噪声下识别合成截图的最后一手（需要 OpenCV）
+func TestDetectSynthetic(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, skin := range vision.Skins {
+		for _, noise := range []int{0, 8, 20} {
+			scene := RandomScene(r, 20+r.Intn(100))
+			style := DefaultStyle()
+			style.SetSkin(skin)
+			style.Noise = noise
+			style.Seed = int64(noise)
+			if skin.Name == "dark" {
+				style.Brightness = 0.8
+			}
+
+			img, err := ToMat(Render(scene, style))
+			if err != nil || img.Empty() {
+				t.Skip("OpenCV 不可用")
+			}
+			result, err := vision.NewDetector(vision.WithSkin(skin.Name)).DetectLastMoveCoord(img, scene.MoveNumber)
+			img.Close()
+			if err != nil {
+				t.Fatalf("%s 噪声 %d: DetectLastMoveCoord() error = %v", skin.Name, noise, err)
+			}
+
+			wantX, wantY := coords.ToPhone(scene.Last.X, scene.Last.Y)
+			if result.X != wantX || result.Y != wantY || result.Color != scene.LastColor().String() {
+				t.Errorf("%s 噪声 %d: 识别为 %s %d-%d, want %s %d-%d", skin.Name, noise,
+					result.Color, result.X, result.Y, scene.LastColor(), wantX, wantY)
+			}
+		}
+	}
+}
+
+// TestDeadStones 识别合成截图中标为死子的棋子（需要 OpenCV）
+func TestDeadStones(t *testing.T) {
+	var scene Scene
+	for _, p := range []image.Point{{3, 3}, {3, 4}, {15, 15}} {
+		scene.Board.Set(p.X, p.Y, board.Black)
+	}
+	for _, p := range []image.Point{{16, 3}, {9, 9}} {
+		scene.Board.Set(p.X, p.Y, board.White)
+	}
+	scene.Last = &image.Point{X: 9, Y: 9}
+	scene.Dead = []image.Point{{3, 4}, {16, 3}}
+
+	for _, skin := range vision.Skins {
+		style := DefaultStyle()
+		style.SetSkin(skin)
+		style.Noise = 8
+
+		img, err := ToMat(Render(scene, style))
+		if err != nil || img.Empty() {
+			t.Skip("OpenCV 不可用")
+		}
+		dead, err := vision.NewDetector(vision.WithSkin(skin.Name)).ReadDeadStones(img, &scene.Board)
+		img.Close()
+		if err != nil {
+			t.Fatalf("%s: ReadDeadStones() error = %v", skin.Name, err)
+		}
+		if !slices.Equal(dead, scene.Dead) {
+			t.Errorf("%s: ReadDeadStones() = %v, want %v", skin.Name, dead, scene.Dead)
+		}
+	}
+}
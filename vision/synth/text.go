@@ -0,0 +1,77 @@
+package synth
+
+import (
+	"image"
+	"image/color"
+)
+
+// glyphs 手数文字用到的点阵字形，数字 5x7，汉字 11x11；
+// 只为让 OCR 有字可读，不追求与 App 字体一致
+var glyphs = map[rune][]string{
+	'0': {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9': {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'第': {
+		".#....#....",
+		"#####.####.",
+		"#.#..#.#...",
+		"...........",
+		".#########.",
+		".........#.",
+		".#########.",
+		".#...#.....",
+		".#########.",
+		"....##...#.",
+		"...#.#..##.",
+	},
+	'手': {
+		"........##.",
+		"..######...",
+		".....#.....",
+		"..#######..",
+		".....#.....",
+		".....#.....",
+		"##########.",
+		".....#.....",
+		".....#.....",
+		".....#.....",
+		"...###.....",
+	},
+}
+
+// drawText 以 center 为中心画一行文字，每个点放大为 scale 像素见方
+func drawText(img *image.RGBA, text string, center image.Point, scale int, c color.RGBA) {
+	const lineHeight = 11
+	width := 0
+	for _, r := range text {
+		if g, ok := glyphs[r]; ok {
+			width += (len(g[0]) + 1) * scale
+		}
+	}
+
+	x := center.X - width/2
+	top := center.Y - lineHeight*scale/2
+	for _, r := range text {
+		g, ok := glyphs[r]
+		if !ok {
+			continue
+		}
+		// 数字比汉字矮，底部对齐
+		y := top + (lineHeight-len(g))*scale
+		for row, line := range g {
+			for col, dot := range line {
+				if dot == '#' {
+					fillRect(img, image.Rect(x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale), c)
+				}
+			}
+		}
+		x += (len(g[0]) + 1) * scale
+	}
+}
@@ -0,0 +1,27 @@
+package vision
+
+// Tuning 运行中可热更新的识别参数，通过 Detector.SetTuning 整体替换，识别过程中读到的总是同一份
+type Tuning struct {
+	// MarkerMinS、MarkerMinV 覆盖角标 HSV 阈值的饱和度、亮度下限，为 0 时使用皮肤自带的阈值
+	MarkerMinS float64
+	MarkerMinV float64
+}
+
+// Apply 返回按 t 覆盖下限后的阈值副本
+func (t Tuning) Apply(ranges []HSVRange) []HSVRange {
+	if t.MarkerMinS == 0 && t.MarkerMinV == 0 {
+		return ranges
+	}
+
+	tuned := make([]HSVRange, len(ranges))
+	for i, r := range ranges {
+		tuned[i] = r
+		if t.MarkerMinS > 0 {
+			tuned[i].Lower.Val2 = t.MarkerMinS
+		}
+		if t.MarkerMinV > 0 {
+			tuned[i].Lower.Val3 = t.MarkerMinV
+		}
+	}
+	return tuned
+}
@@ -0,0 +1,84 @@
+package vision
+
+import (
+	"context"
+	"time"
+
+	"goboardsync/capture"
+)
+
+// DefaultWatchInterval Watch 默认的截图间隔
+const DefaultWatchInterval = 100 * time.Millisecond
+
+// Watch 每隔 WatchInterval 从 source 取一帧，完整执行识别流程（OCR 手数、定位棋盘、识别角标），
+// 只在识别出新的一手时发送结果：未找到最后一手、置信度低于 MinConfidence 或与上一次发送的结果
+// 相同（手数与坐标都相同）的帧都会被丢弃。
+//
+// 截图或识别出错时发送到错误通道；错误通道带缓冲，消费方来不及读取时丢弃新的错误，不会阻塞识别。
+// ctx 取消后两个通道都会关闭
+func (d *Detector) Watch(ctx context.Context, source capture.Source) (<-chan Result, <-chan error) {
+	results := make(chan Result)
+	errs := make(chan error, 8)
+
+	interval := d.WatchInterval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	go func() {
+		defer close(results)
+		defer close(errs)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var last Result
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			result, err := d.grabAndDetect(ctx, source)
+			if err != nil {
+				select {
+				case errs <- err:
+				default:
+				}
+				continue
+			}
+
+			if result.X == 0 || result.Confidence < d.MinConfidence {
+				continue
+			}
+			if result.Move == last.Move && result.X == last.X && result.Y == last.Y {
+				continue
+			}
+			last = result
+
+			select {
+			case results <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results, errs
+}
+
+// grabAndDetect 取一帧并识别最后一手，未配置 OCR 或 OCR 失败时手数按 0 处理
+func (d *Detector) grabAndDetect(ctx context.Context, source capture.Source) (Result, error) {
+	img, err := source.Grab(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	defer img.Close()
+
+	moveNumber := 0
+	if d.OCREndpoint != "" || d.OCR != nil {
+		moveNumber, _ = d.FetchMoveNumberFromOCR(img)
+	}
+	return d.DetectLastMoveCoord(img, moveNumber)
+}
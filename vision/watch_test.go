@@ -0,0 +1,44 @@
+package vision
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+// failingSource 每次截图都失败的画面来源
+type failingSource struct{}
+
+func (failingSource) Grab(context.Context) (gocv.Mat, error) {
+	return gocv.Mat{}, errors.New("设备已断开")
+}
+func (failingSource) Close() error { return nil }
+
+func TestWatchReportsErrorsAndCloses(t *testing.T) {
+	d := NewDetector(WithOCREndpoint(""), WithInterval(5*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results, errs := d.Watch(ctx, failingSource{})
+
+	select {
+	case err := <-errs:
+		if err == nil || err.Error() != "设备已断开" {
+			t.Errorf("err = %v, want 设备已断开", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("截图失败后未收到错误")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-results:
+		if ok {
+			t.Errorf("截图一直失败时不应发送结果")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ctx 取消后结果通道未关闭")
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package workdir
+
+import (
+	"os"
+	"syscall"
+)
+
+// ProcessAlive 进程是否存在：发送 0 号信号探测
+func ProcessAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
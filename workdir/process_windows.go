@@ -0,0 +1,17 @@
+//go:build windows
+
+package workdir
+
+import (
+	"os"
+)
+
+// ProcessAlive 进程是否存在：Windows 上 FindProcess 会打开进程句柄，进程不存在时返回错误
+func ProcessAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
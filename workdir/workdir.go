@@ -0,0 +1,127 @@
+// Package workdir 管理每次运行的临时工作目录：截图等临时文件都写在其中，退出时整体删除；
+// 启动时清理上次崩溃遗留的目录与截图。
+package workdir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Prefix 工作目录名前缀，Sweep 只清理带此前缀的目录
+const Prefix = "goboardsync-"
+
+const pidFile = "pid"
+
+// Dir 一次运行的工作目录
+type Dir struct {
+	Path string
+}
+
+// New 在 base（为空时为系统临时目录）下创建工作目录，并写入当前进程号供 Sweep 判断是否仍在使用
+func New(base string) (*Dir, error) {
+	path, err := os.MkdirTemp(base, Prefix+"*")
+	if err != nil {
+		return nil, fmt.Errorf("创建工作目录失败: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(path, pidFile), []byte(strconv.Itoa(os.Getpid())), 0o644); err != nil {
+		os.RemoveAll(path)
+		return nil, fmt.Errorf("写入进程号失败: %v", err)
+	}
+	return &Dir{Path: path}, nil
+}
+
+// Join 返回工作目录下的文件路径
+func (d *Dir) Join(name string) string {
+	return filepath.Join(d.Path, name)
+}
+
+// Remove 删除工作目录及其中所有文件
+func (d *Dir) Remove() error {
+	return os.RemoveAll(d.Path)
+}
+
+// Sweep 删除 base（为空时为系统临时目录）下遗留的工作目录：进程号文件缺失、
+// 对应进程已不存在，或目录超过 maxAge 未修改。返回被删除的目录
+func Sweep(base string, maxAge time.Duration) ([]string, error) {
+	if base == "" {
+		base = os.TempDir()
+	}
+
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), Prefix) {
+			continue
+		}
+
+		path := filepath.Join(base, e.Name())
+		if !stale(path, maxAge) {
+			continue
+		}
+		if err := os.RemoveAll(path); err == nil {
+			removed = append(removed, path)
+		}
+	}
+	return removed, nil
+}
+
+func stale(path string, maxAge time.Duration) bool {
+	if info, err := os.Stat(path); err == nil && maxAge > 0 && time.Since(info.ModTime()) > maxAge {
+		return true
+	}
+
+	data, err := os.ReadFile(filepath.Join(path, pidFile))
+	if err != nil {
+		return true
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return true
+	}
+	return pid != os.Getpid() && !ProcessAlive(pid)
+}
+
+// SweepFiles 删除 dir 中匹配 patterns（filepath.Match 语法）且超过 minAge 未修改的文件，
+// 用于清理旧版本直接写在截图目录中的 temp_*.png 等文件
+func SweepFiles(dir string, minAge time.Duration, patterns ...string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, e := range entries {
+		if e.IsDir() || !matchAny(e.Name(), patterns) {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < minAge {
+			continue
+		}
+
+		path := filepath.Join(dir, e.Name())
+		if err := os.Remove(path); err == nil {
+			removed = append(removed, path)
+		}
+	}
+	return removed, nil
+}
+
+func matchAny(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package workdir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewAndRemove(t *testing.T) {
+	base := t.TempDir()
+
+	d, err := New(base)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	if filepath.Dir(d.Path) != base || filepath.Base(d.Path)[:len(Prefix)] != Prefix {
+		t.Errorf("Path = %s, want %s/%s*", d.Path, base, Prefix)
+	}
+
+	os.WriteFile(d.Join("screenshot.jpg"), []byte("x"), 0o644)
+	if err := d.Remove(); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(d.Path); !os.IsNotExist(err) {
+		t.Errorf("Remove() 后目录仍存在")
+	}
+}
+
+func TestSweep(t *testing.T) {
+	base := t.TempDir()
+
+	current, _ := New(base)
+
+	// 已退出的进程留下的目录（进程号取一个几乎不可能存在的值）
+	dead := filepath.Join(base, Prefix+"dead")
+	os.Mkdir(dead, 0o755)
+	os.WriteFile(filepath.Join(dead, pidFile), []byte("999999999"), 0o644)
+
+	// 没有进程号文件的目录
+	noPid := filepath.Join(base, Prefix+"nopid")
+	os.Mkdir(noPid, 0o755)
+
+	// 其它程序的目录不动
+	other := filepath.Join(base, "other-tool")
+	os.Mkdir(other, 0o755)
+
+	removed, err := Sweep(base, 0)
+	if err != nil {
+		t.Fatalf("Sweep() unexpected error: %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("Sweep() 删除 %v, want 2 个遗留目录", removed)
+	}
+
+	for _, path := range []string{current.Path, other} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s 不应被删除", path)
+		}
+	}
+	for _, path := range []string{dead, noPid} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s 应被删除", path)
+		}
+	}
+}
+
+func TestSweepFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	old := time.Now().Add(-time.Hour)
+	files := map[string]bool{
+		"temp_123.png":   true,
+		"screen_456.png": true,
+		"game_1.sgf":     false,
+		"037-K10-B.jpg":  false,
+	}
+	for name := range files {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte("x"), 0o644)
+		os.Chtimes(path, old, old)
+	}
+	fresh := filepath.Join(dir, "temp_999.png")
+	os.WriteFile(fresh, []byte("x"), 0o644)
+
+	removed, err := SweepFiles(dir, time.Minute, "temp_*.png", "screen_*.png")
+	if err != nil {
+		t.Fatalf("SweepFiles() unexpected error: %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("SweepFiles() 删除 %v, want 2 个文件", removed)
+	}
+
+	for name, wantRemoved := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if gone := os.IsNotExist(err); gone != wantRemoved {
+			t.Errorf("%s 删除 = %v, want %v", name, gone, wantRemoved)
+		}
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("刚写入的 temp_999.png 不应被删除")
+	}
+}